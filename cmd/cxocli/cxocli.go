@@ -16,6 +16,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
@@ -69,10 +70,18 @@ var (
 		"connections ",
 		"connections of feed ",
 
+		"external addresses ",
+		"onion address ",
+		"listening addresses ",
+
+		"rate limits ",
+		"set rate limits ",
+
 		// root objects
 
 		"root info ",
 		"root tree ",
+		"root source ",
 		"last root ",
 
 		// stat
@@ -280,9 +289,17 @@ func (c *client) mapping() map[string]func(in []string) (err error) {
 		"connections":         c.connections,
 		"connections of feed": c.connectionsOfFeed,
 
-		"root info": c.rootInfo,
-		"root tree": c.rootTree,
-		"last root": c.lastRoot,
+		"external addresses":  c.externalAddresses,
+		"onion address":       c.onionAddress,
+		"listening addresses": c.listeningAddresses,
+
+		"rate limits":     c.rateLimits,
+		"set rate limits": c.setRateLimits,
+
+		"root info":   c.rootInfo,
+		"root tree":   c.rootTree,
+		"root source": c.rootSource,
+		"last root":   c.lastRoot,
 
 		"stat": c.stat,
 
@@ -634,6 +651,100 @@ func (c *client) connectionsOfFeed(in []string) (err error) {
 	return
 }
 
+func (c *client) externalAddresses(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var as []string
+	if as, err = c.r.Node().ExternalAddresses(); err != nil {
+		return
+	}
+	if len(as) == 0 {
+		fmt.Fprintln(out, "  no external addresses")
+		return
+	}
+	for _, a := range as {
+		fmt.Fprintln(out, " ", a)
+	}
+	return
+}
+
+func (c *client) listeningAddresses(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var as []string
+	if as, err = c.r.Node().ListeningAddresses(); err != nil {
+		return
+	}
+	if len(as) == 0 {
+		fmt.Fprintln(out, "  not listening")
+		return
+	}
+	for _, a := range as {
+		fmt.Fprintln(out, " ", a)
+	}
+	return
+}
+
+func (c *client) onionAddress(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var address string
+	if address, err = c.r.Node().OnionAddress(); err != nil {
+		return
+	}
+	if address == "" {
+		fmt.Fprintln(out, "  not an onion service")
+		return
+	}
+	fmt.Fprintln(out, " ", address)
+	return
+}
+
+//
+// rate limits
+//
+
+func (c *client) rateLimits(in []string) (err error) {
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+	var rls node.RateLimits
+	if rls, err = c.r.Node().RateLimits(); err != nil {
+		return
+	}
+	fmt.Fprintf(out, "  global: up %d, down %d\n", rls.Global.Up,
+		rls.Global.Down)
+	fmt.Fprintf(out, "  peer:   up %d, down %d\n", rls.Peer.Up, rls.Peer.Down)
+	return
+}
+
+func (c *client) setRateLimits(in []string) (err error) {
+
+	const expected = "expected up, down, peer up and peer down"
+
+	switch {
+	case len(in) < 4:
+		return errors.New("missing arguments: " + expected)
+	case len(in) > 4:
+		return errors.New("too many arguments: " + expected)
+	}
+
+	var ls [4]int64
+	for i := range ls {
+		if ls[i], err = strconv.ParseInt(in[i], 10, 64); err != nil {
+			return
+		}
+	}
+
+	return c.r.Node().SetRateLimits(node.RateLimits{
+		Global: node.RateLimit{Up: ls[0], Down: ls[1]},
+		Peer:   node.RateLimit{Up: ls[2], Down: ls[3]},
+	})
+}
+
 //
 // root objects
 //
@@ -701,6 +812,19 @@ func (c *client) rootTree(in []string) (err error) {
 	return
 }
 
+func (c *client) rootSource(in []string) (err error) {
+	var sl node.RootSelector
+	if sl, err = c.argsRoot(in); err != nil {
+		return
+	}
+	var src string
+	if src, err = c.r.Root().Source(sl.Feed, sl.Nonce, sl.Seq); err != nil {
+		return
+	}
+	fmt.Fprintln(out, src)
+	return
+}
+
 func (c *client) lastRoot(in []string) (err error) {
 	var pk cipher.PubKey
 	if pk, err = c.argsFeed(in); err != nil {
@@ -764,6 +888,10 @@ func (c *client) stat(in []string) (err error) {
 
 	fmt.Fprintln(out, "  new Root objects per second:    ", s.RootsPerSecond)
 
+	if s.IO != nil {
+		c.printIOStat(s.IO)
+	}
+
 	if len(s.Feeds) == 0 {
 		fmt.Fprintln(out, "  no feeds")
 		return
@@ -800,6 +928,27 @@ func (c *client) stat(in []string) (err error) {
 	return
 }
 
+func (c *client) printIOStat(is *data.IOStat) {
+	fmt.Fprintln(out, "  I/O")
+	fmt.Fprintln(out, "    commits:            ", is.Commits)
+	fmt.Fprintln(out, "    writes:             ", is.Writes)
+	fmt.Fprintln(out, "    logical bytes:      ", is.LogicalBytes)
+	fmt.Fprintln(out, "    written bytes:      ", is.WrittenBytes)
+	fmt.Fprintln(out, "    write amplification:", round(is.WriteAmplification()))
+	fmt.Fprintln(out, "    fsync latency")
+
+	var prev time.Duration
+	for i, cnt := range is.SyncLatency.Counts {
+		if i < len(is.SyncLatency.Bounds) {
+			fmt.Fprintf(out, "      %s - %s: %d\n", prev,
+				is.SyncLatency.Bounds[i], cnt)
+			prev = is.SyncLatency.Bounds[i]
+			continue
+		}
+		fmt.Fprintf(out, "      > %s: %d\n", prev, cnt)
+	}
+}
+
 func (c *client) help(in []string) (err error) {
 	fmt.Fprint(out, `
 
@@ -837,6 +986,18 @@ func (c *client) help(in []string) (err error) {
     show all connections
   connections of feed <public key>
     show connections of given feed
+  external addresses
+    show addresses learned from NAT gateway
+  onion address
+    show address of onion service of the node
+  listening addresses
+    show all listening addresses of the node
+
+  rate limits
+    show bandwidth limits in bytes per second
+  set rate limits <up> <down> <peer up> <peer down>
+    change global and per-connection bandwidth limits,
+    zero is no limit
 
 
   root info <public key> <nonce> <seq>
@@ -844,6 +1005,8 @@ func (c *client) help(in []string) (err error) {
 
   root tree <public key> <nonce> <seq>
     print tree of selected Root
+  root source <public key> <nonce> <seq>
+    print Go types of registry of selected Root
 
   last root <public key>
     show info about last Root of given feed
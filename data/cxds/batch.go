@@ -0,0 +1,183 @@
+package cxds
+
+import (
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// limits of adaptive batching
+const (
+	MaxBatchSize  int           = 1000                  // max writes per commit
+	MaxBatchDelay time.Duration = 10 * time.Millisecond // max wait for a batch
+	MinBatchDelay time.Duration = 100 * time.Microsecond
+)
+
+// a write to be committed by a batch
+type batchCall struct {
+	fn  func(tx *bolt.Tx) error // the write
+	err chan error              // result
+}
+
+// A batcher joins concurrent writes to boltdb in
+// batches. A commit of boltdb is expensive (fsync),
+// thus it's better to commit many objects at once.
+// But a batch has a delay. The batcher tunes size
+// and delay of a batch by observed commit latency
+// and incoming writes rate. For a single writer
+// (or for slow writes) the size is 1 and every write
+// commits immediately without a delay. For many
+// concurrent writers the size is number of writes
+// expected during a commit, and the delay is the
+// commit latency. Thus, it's fast on SSD and on
+// slow SD cards both
+//
+// Unlike bolt.Batch, a function of the batcher is
+// called once. A function must not change DB if it
+// returns an error, since the error doesn't rollback
+// other writes of the batch
+type batcher struct {
+	b  *bolt.DB
+	io *ioStat // I/O statistic or nil
+
+	mx    sync.Mutex
+	calls []batchCall // pending writes
+	timer *time.Timer // delayed commit
+
+	size  int           // current batch size
+	delay time.Duration // current batch delay
+
+	latency  time.Duration // average commit latency
+	interval time.Duration // average interval between writes
+	last     time.Time     // last write
+}
+
+func newBatcher(b *bolt.DB, io *ioStat) (bt *batcher) {
+	bt = new(batcher)
+	bt.b = b
+	bt.io = io
+	bt.size = 1
+	bt.delay = MinBatchDelay
+	return
+}
+
+// exponential moving average
+func ewma(avg, x time.Duration) time.Duration {
+	if avg == 0 {
+		return x
+	}
+	return avg + (x-avg)/8
+}
+
+// update executes given function inside
+// a read-write transaction (may be batched)
+func (b *batcher) update(fn func(tx *bolt.Tx) error) (err error) {
+
+	var call = batchCall{fn, make(chan error, 1)}
+
+	b.mx.Lock()
+
+	var now = time.Now()
+	if b.last.IsZero() == false {
+		b.interval = ewma(b.interval, now.Sub(b.last))
+	}
+	b.last = now
+
+	b.calls = append(b.calls, call)
+
+	if len(b.calls) >= b.size {
+		var calls = b.take()
+		b.mx.Unlock()
+		b.commit(calls)
+	} else {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.delay, b.trigger)
+		}
+		b.mx.Unlock()
+	}
+
+	return <-call.err
+}
+
+// take pending calls, the b.mx must be locked
+func (b *batcher) take() (calls []batchCall) {
+	calls, b.calls = b.calls, nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return
+}
+
+// commit by timer
+func (b *batcher) trigger() {
+
+	b.mx.Lock()
+	var calls = b.take()
+	b.mx.Unlock()
+
+	if len(calls) > 0 {
+		b.commit(calls)
+	}
+}
+
+func (b *batcher) commit(calls []batchCall) {
+
+	var (
+		errs  = make([]error, len(calls))
+		start = time.Now()
+	)
+
+	var err = b.io.update(b.b, len(calls), func(tx *bolt.Tx) (_ error) {
+		for i, c := range calls {
+			errs[i] = c.fn(tx)
+		}
+		return
+	})
+
+	b.tune(time.Since(start))
+
+	for i, c := range calls {
+		if err != nil {
+			c.err <- err // commit error
+			continue
+		}
+		c.err <- errs[i]
+	}
+
+}
+
+// tune size and delay of batch
+func (b *batcher) tune(latency time.Duration) {
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.latency = ewma(b.latency, latency)
+
+	// number of writes expected during a commit
+
+	var size = 1
+	if b.interval > 0 {
+		size = int(b.latency / b.interval)
+	}
+
+	switch {
+	case size < 1:
+		size = 1
+	case size > MaxBatchSize:
+		size = MaxBatchSize
+	}
+
+	var delay = b.latency
+
+	switch {
+	case delay < MinBatchDelay:
+		delay = MinBatchDelay
+	case delay > MaxBatchDelay:
+		delay = MaxBatchDelay
+	}
+
+	b.size, b.delay = size, delay
+}
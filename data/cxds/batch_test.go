@@ -0,0 +1,109 @@
+package cxds
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_batcher_tune(t *testing.T) {
+
+	var b = newBatcher(nil, nil)
+
+	// single writer: a write per commit
+
+	b.interval = 10 * time.Millisecond
+	b.tune(5 * time.Millisecond)
+
+	if b.size != 1 {
+		t.Error("wrong size", b.size)
+	}
+
+	// many writers: writes expected during a commit
+
+	b = newBatcher(nil, nil)
+	b.interval = time.Millisecond
+	b.tune(50 * time.Millisecond)
+
+	if b.size != 50 {
+		t.Error("wrong size", b.size)
+	}
+
+	if b.delay != MaxBatchDelay {
+		t.Error("wrong delay", b.delay)
+	}
+
+	// limits
+
+	b = newBatcher(nil, nil)
+	b.interval = time.Nanosecond
+	b.tune(time.Second)
+
+	if b.size != MaxBatchSize {
+		t.Error("wrong size", b.size)
+	}
+
+}
+
+func TestDriveCXDS_concurrentSet(t *testing.T) {
+
+	const n, m = 20, 50 // writers, objects per writer
+
+	var ds = testDriveDS(t)
+	defer os.Remove(testFileName)
+	defer ds.Close()
+
+	var (
+		wg  sync.WaitGroup
+		vol int
+	)
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			vol += len(testValue(i, j))
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < m; j++ {
+				var val = testValue(i, j)
+				if _, err := ds.Set(cipher.SumSHA256(val), val, 1); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if all, used := ds.Amount(); all != n*m || used != n*m {
+		t.Errorf("wrong amount: %d, %d", all, used)
+	}
+
+	if all, used := ds.Volume(); all != vol || used != vol {
+		t.Errorf("wrong volume: %d, %d, want %d", all, used, vol)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			var val = testValue(i, j)
+			if _, rc, err := ds.Get(cipher.SumSHA256(val), 0); err != nil {
+				t.Fatal(err)
+			} else if rc != 1 {
+				t.Error("wrong rc", rc)
+			}
+		}
+	}
+
+}
+
+func testValue(i, j int) []byte {
+	return []byte{'v', byte(i), byte(j)}
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/skycoin/skycoin/src/cipher"
+
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/data/tests"
 )
@@ -97,3 +99,61 @@ func TestCXDS_Close(t *testing.T) {
 		tests.CXDSClose(t, ds)
 	})
 }
+
+func TestDriveCXDS_IOStat(t *testing.T) {
+	// IOStat() (is data.IOStat, ok bool)
+
+	t.Run("disabled", func(t *testing.T) {
+		ds := testDriveDS(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+
+		if _, ok := ds.(data.IOStater).IOStat(); ok == true {
+			t.Error("unexpected I/O statistic")
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		ds, err := NewDriveCXDSWithIOStat(testFileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(testFileName)
+		defer ds.Close()
+
+		var val = []byte("value")
+
+		if _, err = ds.Set(cipher.SumSHA256(val), val, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		// the same object, no new logical bytes
+		if _, err = ds.Set(cipher.SumSHA256(val), val, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		is, ok := ds.(data.IOStater).IOStat()
+		if ok == false {
+			t.Fatal("missing I/O statistic")
+		}
+
+		if is.Commits != 2 {
+			t.Error("wrong number of commits:", is.Commits)
+		}
+		if is.Writes != 2 {
+			t.Error("wrong number of writes:", is.Writes)
+		}
+		if is.LogicalBytes != uint64(len(val)) {
+			t.Error("wrong logical bytes:", is.LogicalBytes)
+		}
+		if is.WrittenBytes <= is.LogicalBytes {
+			t.Error("wrong written bytes:", is.WrittenBytes)
+		}
+		if is.WriteAmplification() <= 1 {
+			t.Error("wrong write amplification:", is.WriteAmplification())
+		}
+		if total := is.SyncLatency.Total(); total != 2 {
+			t.Error("wrong number of latencies:", total)
+		}
+	})
+}
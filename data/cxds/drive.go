@@ -36,7 +36,9 @@ type driveCXDS struct {
 	volumeAll  int // volume of all objects
 	volumeUsed int // volume of used objects
 
-	b *bolt.DB
+	b  *bolt.DB
+	bt *batcher // adaptive batching of writes
+	io *ioStat  // I/O statistic or nil
 }
 
 // NewDriveCXDS opens existing CXDS-database
@@ -44,6 +46,18 @@ type driveCXDS struct {
 // database is boltdb (github.com/boltdb/bolt).
 // E.g. this stores data on disk
 func NewDriveCXDS(fileName string) (ds data.CXDS, err error) {
+	return newDriveCXDS(fileName, false)
+}
+
+// NewDriveCXDSWithIOStat is the same as the NewDriveCXDS
+// but the CXDS collects I/O statistic: bytes written vs
+// bytes of objects, number of commits and latency of
+// fsync. The CXDS implements data.IOStater interface
+func NewDriveCXDSWithIOStat(fileName string) (ds data.CXDS, err error) {
+	return newDriveCXDS(fileName, true)
+}
+
+func newDriveCXDS(fileName string, ioStat bool) (ds data.CXDS, err error) {
 
 	var created bool // true if the file does not exist
 
@@ -129,6 +143,12 @@ func NewDriveCXDS(fileName string) (ds data.CXDS, err error) {
 
 	var dr = &driveCXDS{b: b} // wrap
 
+	if ioStat == true {
+		dr.io = newIOStat(b)
+	}
+
+	dr.bt = newBatcher(b, dr.io)
+
 	// stat
 
 	if saveStat == true {
@@ -237,15 +257,21 @@ func (d *driveCXDS) saveStat() (err error) {
 
 }
 
-func (d *driveCXDS) av(rc, nrc uint32, vol int) {
+// changes of amounts and volumes made by a write,
+// the changes applied after successful commit
+type statDelta struct {
+	amountAll  int
+	amountUsed int
+	volumeAll  int
+	volumeUsed int
+}
 
-	d.mx.Lock()
-	defer d.mx.Unlock()
+func (s *statDelta) av(rc, nrc uint32, vol int) {
 
 	if rc == 0 { // was dead
 		if nrc > 0 { // an be resurrected
-			d.amountUsed++
-			d.volumeUsed += vol
+			s.amountUsed++
+			s.volumeUsed += vol
 		}
 		return // else -> as is
 	}
@@ -253,18 +279,37 @@ func (d *driveCXDS) av(rc, nrc uint32, vol int) {
 	// rc > 0 (was alive)
 
 	if nrc == 0 { // and be killed
-		d.amountUsed--
-		d.volumeUsed -= vol
+		s.amountUsed--
+		s.volumeUsed -= vol
 	}
 
 }
 
+func (s *statDelta) addAll(vol int) {
+	s.amountAll++
+	s.volumeAll += vol
+}
+
+func (d *driveCXDS) applyStat(s *statDelta) {
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	d.amountAll += s.amountAll
+	d.amountUsed += s.amountUsed
+	d.volumeAll += s.volumeAll
+	d.volumeUsed += s.volumeUsed
+
+	d.io.addLogical(s.volumeAll)
+}
+
 func (d *driveCXDS) incr(
 	o *bolt.Bucket, // : objects
 	key []byte, //     : key[:]
 	val []byte, //     : value without leading rc (4 bytes)
 	rc uint32, //      : existing rc
 	inc int, //        : change the rc
+	st *statDelta, //  : changes of stat
 ) (
 	nrc uint32, //     : new rc
 	err error, //      : an error
@@ -291,7 +336,7 @@ func (d *driveCXDS) incr(
 	err = o.Put(key[:], repl)
 
 	if rc != nrc {
-		d.av(rc, nrc, len(val))
+		st.av(rc, nrc, len(val))
 	}
 
 	return
@@ -308,6 +353,8 @@ func (d *driveCXDS) Get(
 	err error, //         :
 ) {
 
+	var st statDelta
+
 	var tx = func(tx *bolt.Tx) (err error) {
 
 		var (
@@ -323,14 +370,14 @@ func (d *driveCXDS) Get(
 		val = make([]byte, len(got)-4)
 		copy(val, got[4:])
 
-		rc, err = d.incr(o, key[:], val, rc, inc)
+		rc, err = d.incr(o, key[:], val, rc, inc, &st)
 		return
 	}
 
 	if inc == 0 {
 		err = d.b.View(tx) // lookup only
-	} else {
-		err = d.b.Update(tx) // some changes
+	} else if err = d.bt.update(tx); err == nil {
+		d.applyStat(&st) // some changes
 	}
 
 	return
@@ -340,14 +387,6 @@ func panicf(format string, args ...interface{}) {
 	panic(fmt.Sprintf(format, args...))
 }
 
-func (d *driveCXDS) addAll(vol int) {
-	d.mx.Lock()
-	defer d.mx.Unlock()
-
-	d.amountAll++
-	d.volumeAll += vol
-}
-
 // Set value and its references counter
 func (d *driveCXDS) Set(
 	key cipher.SHA256,
@@ -367,7 +406,9 @@ func (d *driveCXDS) Set(
 		return
 	}
 
-	err = d.b.Update(func(tx *bolt.Tx) (err error) {
+	var st statDelta
+
+	err = d.bt.update(func(tx *bolt.Tx) (err error) {
 
 		var (
 			o   = tx.Bucket(objsBucket)
@@ -377,16 +418,20 @@ func (d *driveCXDS) Set(
 		if len(got) == 0 {
 
 			// created
-			d.addAll(len(val))
+			st.addAll(len(val))
 
-			rc, err = d.incr(o, key[:], val, 0, 1)
+			rc, err = d.incr(o, key[:], val, 0, 1, &st)
 			return
 		}
 
-		rc, err = d.incr(o, key[:], got[4:], getRefsCount(got), inc)
+		rc, err = d.incr(o, key[:], got[4:], getRefsCount(got), inc, &st)
 		return
 	})
 
+	if err == nil {
+		d.applyStat(&st)
+	}
+
 	return
 }
 
@@ -399,7 +444,9 @@ func (d *driveCXDS) Inc(
 	err error,
 ) {
 
-	var tx = func(tx *bolt.Tx) (_ error) {
+	var st statDelta
+
+	var tx = func(tx *bolt.Tx) (err error) {
 
 		var (
 			o   = tx.Bucket(objsBucket)
@@ -416,14 +463,14 @@ func (d *driveCXDS) Inc(
 			return // done
 		}
 
-		rc, err = d.incr(o, key[:], got[4:], rc, inc)
+		rc, err = d.incr(o, key[:], got[4:], rc, inc, &st)
 		return
 	}
 
 	if inc == 0 {
 		err = d.b.View(tx) // lookup only
-	} else {
-		err = d.b.Update(tx) // changes required
+	} else if err = d.bt.update(tx); err == nil {
+		d.applyStat(&st) // changes required
 	}
 
 	return
@@ -450,7 +497,7 @@ func (d *driveCXDS) Del(
 	err error,
 ) {
 
-	err = d.b.Update(func(tx *bolt.Tx) (err error) {
+	err = d.io.update(d.b, 1, func(tx *bolt.Tx) (err error) {
 
 		var (
 			o   = tx.Bucket(objsBucket)
@@ -509,7 +556,7 @@ func (d *driveCXDS) IterateDel(
 	err error,
 ) {
 
-	err = d.b.Update(func(tx *bolt.Tx) (err error) {
+	err = d.io.update(d.b, 1, func(tx *bolt.Tx) (err error) {
 
 		var (
 			key cipher.SHA256
@@ -568,6 +615,11 @@ func (d *driveCXDS) Volume() (all, used int) {
 	return d.volumeAll, d.volumeUsed
 }
 
+// IOStat implements data.IOStater interface
+func (d *driveCXDS) IOStat() (is data.IOStat, ok bool) {
+	return d.io.get()
+}
+
 // Close DB
 func (d *driveCXDS) Close() (err error) {
 
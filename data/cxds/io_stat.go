@@ -0,0 +1,90 @@
+package cxds
+
+import (
+	"sync"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// collector of I/O statistic, a nil *ioStat
+// means that the collecting is disabled
+type ioStat struct {
+	mx       sync.Mutex
+	pageSize int
+	stat     data.IOStat
+}
+
+func newIOStat(b *bolt.DB) (i *ioStat) {
+	i = new(ioStat)
+	i.pageSize = b.Info().PageSize
+	i.stat.SyncLatency = data.NewHistogram(data.SyncLatencyBounds)
+	return
+}
+
+// update executes given function inside read-write
+// transaction collecting statistic of the commit
+func (i *ioStat) update(
+	b *bolt.DB, //                  : the DB
+	writes int, //                  : number of writes
+	fn func(tx *bolt.Tx) error, //  : the transaction
+) (
+	err error, //                   : an error
+) {
+
+	if i == nil {
+		return b.Update(fn)
+	}
+
+	var t *bolt.Tx
+
+	err = b.Update(func(tx *bolt.Tx) error {
+		t = tx
+		return fn(tx)
+	})
+
+	if err != nil {
+		return // rolled back
+	}
+
+	var ts = t.Stats() // stat of the committed transaction
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	i.stat.Commits++
+	i.stat.Writes += uint64(writes)
+	// dirty pages and meta page
+	i.stat.WrittenBytes += uint64(ts.PageAlloc + i.pageSize)
+	i.stat.SyncLatency.Add(ts.WriteTime)
+
+	return
+}
+
+// volume of new objects
+func (i *ioStat) addLogical(vol int) {
+
+	if i == nil || vol <= 0 {
+		return
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	i.stat.LogicalBytes += uint64(vol)
+}
+
+func (i *ioStat) get() (is data.IOStat, ok bool) {
+
+	if i == nil {
+		return
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	is = i.stat
+	is.SyncLatency = i.stat.SyncLatency.Copy()
+	return is, true
+}
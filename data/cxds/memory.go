@@ -188,6 +188,8 @@ func (m *memoryCXDS) Del(key cipher.SHA256) (_ error) {
 		return // not found
 	}
 
+	delete(m.kvs, key)
+
 	if mo.rc > 0 {
 		m.amountUsed--
 		m.volumeUsed -= len(mo.val)
@@ -67,8 +67,16 @@ type Root struct {
 
 	Hash cipher.SHA256 // hash of the Root
 	Sig  cipher.Sig    // signature of the Root
+
+	// Filter is schemas of partial Root, that filled
+	// with objects of the schemas only; it's nil for
+	// full Root (see skyobject.Filler.Filter)
+	Filter []string
 }
 
+// size of encoded Root saved without the Filter
+var rootSizeNoFilter = len(encoder.Serialize(Root{})) - 4
+
 // Validate the Root
 func (r *Root) Validate() (err error) {
 	if r.Seq == 0 {
@@ -99,5 +107,11 @@ func (r *Root) Encode() (p []byte) {
 
 // Decode given encoded Root to this one
 func (r *Root) Decode(p []byte) (err error) {
-	return encoder.DeserializeRaw(p, r)
+	if len(p) == rootSizeNoFilter {
+		p = append(p[:len(p):len(p)], 0, 0, 0, 0) // saved before the Filter
+	}
+	if err = encoder.DeserializeRaw(p, r); err == nil && len(r.Filter) == 0 {
+		r.Filter = nil // full
+	}
+	return
 }
@@ -2,6 +2,7 @@ package data
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -46,7 +47,7 @@ func TestRoot_Decode(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if *x != *r {
+	if reflect.DeepEqual(x, r) == false {
 		t.Error("wrong")
 	}
 
@@ -54,6 +55,34 @@ func TestRoot_Decode(t *testing.T) {
 		t.Error("missing error")
 	}
 
+	// partial
+
+	r.Filter = []string{"cxo.ThreadHead"}
+	p = encoder.Serialize(r)
+
+	x = new(Root)
+	if err := x.Decode(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if reflect.DeepEqual(x, r) == false {
+		t.Error("wrong")
+	}
+
+	// saved without the Filter
+
+	r.Filter = nil
+	p = encoder.Serialize(r)
+
+	x = new(Root)
+	if err := x.Decode(p[:len(p)-4]); err != nil {
+		t.Fatal(err)
+	}
+
+	if reflect.DeepEqual(x, r) == false {
+		t.Error("wrong")
+	}
+
 }
 
 func TestRoot_Validate(t *testing.T) {
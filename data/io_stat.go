@@ -0,0 +1,106 @@
+package data
+
+import (
+	"time"
+)
+
+// SyncLatencyBounds is upper bounds of buckets of
+// the IOStat.SyncLatency histogram. The last bucket
+// of the histogram is for latencies greater then
+// the last bound
+var SyncLatencyBounds = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	1 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+}
+
+// A Histogram represents latency histogram. The
+// Counts[i] is number of latencies less then or
+// equal to the Bounds[i] and greater then the
+// Bounds[i-1]. The Counts is one element longer
+// then the Bounds. The last element is number of
+// latencies greater then the last bound
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+}
+
+// NewHistogram creates Histogram
+// with given bounds of buckets
+func NewHistogram(bounds []time.Duration) (h Histogram) {
+	h.Bounds = bounds
+	h.Counts = make([]uint64, len(bounds)+1)
+	return
+}
+
+// Add given latency to the Histogram
+func (h *Histogram) Add(latency time.Duration) {
+	var i int
+	for ; i < len(h.Bounds); i++ {
+		if latency <= h.Bounds[i] {
+			break
+		}
+	}
+	h.Counts[i]++
+}
+
+// Total number of latencies
+func (h *Histogram) Total() (total uint64) {
+	for _, c := range h.Counts {
+		total += c
+	}
+	return
+}
+
+// Copy returns deep copy of the Histogram
+func (h *Histogram) Copy() (cp Histogram) {
+	cp.Bounds = h.Bounds
+	cp.Counts = append([]uint64{}, h.Counts...)
+	return
+}
+
+// An IOStat represents I/O statistic of a
+// CXDS. The statistic used to find out storage
+// performance issues on particular hardware
+type IOStat struct {
+	// Commits is number of commits
+	Commits uint64
+	// Writes is number of writes. A commit can
+	// join many writes (see data/cxds batching)
+	Writes uint64
+	// LogicalBytes is total volume of
+	// new objects stored
+	LogicalBytes uint64
+	// WrittenBytes is number of bytes written
+	// to disk by commits. Since the bytes are
+	// pages of underlying DB, the number is
+	// approximate
+	WrittenBytes uint64
+	// SyncLatency is histogram of time spent
+	// to write and fsync data of a commit
+	SyncLatency Histogram
+}
+
+// WriteAmplification returns ratio of written
+// bytes to logical bytes. It returns zero if
+// nothing has been stored yet
+func (i *IOStat) WriteAmplification() float64 {
+	if i.LogicalBytes == 0 {
+		return 0
+	}
+	return float64(i.WrittenBytes) / float64(i.LogicalBytes)
+}
+
+// An IOStater is optional interface a CXDS can
+// implement to report its I/O statistic. The
+// IOStat method returns false if collecting of
+// the statistic is not enabled
+type IOStater interface {
+	IOStat() (is IOStat, ok bool)
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -469,7 +470,7 @@ func RootsSet(t *testing.T, idx data.IdxDB) {
 			if x, err = rs.Get(r.Seq); err != nil {
 				return
 			}
-			if *x != *r {
+			if reflect.DeepEqual(x, r) == false {
 				t.Error("wrong")
 			}
 			return
@@ -497,7 +498,7 @@ func RootsSet(t *testing.T, idx data.IdxDB) {
 				return
 			}
 			r.Access = x.Access
-			if *x != *r {
+			if reflect.DeepEqual(x, r) == false {
 				t.Error("wrong")
 			}
 			return
@@ -0,0 +1,135 @@
+package appkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+type Item struct {
+	Name string
+}
+
+type List struct {
+	Items registry.Refs `skyobject:"schema=appkit.Item"`
+}
+
+var testRegistry = registry.NewRegistry(func(r *registry.Reg) {
+	r.Register("appkit.Item", Item{})
+	r.Register("appkit.List", List{})
+})
+
+func testNode(t *testing.T) (n *node.Node) {
+
+	t.Helper()
+
+	var conf = node.NewConfig()
+
+	conf.Config.InMemoryDB = true
+	conf.TCP.Listen = ""
+	conf.TCP.Discovery = node.Addresses{}
+	conf.UDP.Listen = ""
+	conf.UDP.Discovery = node.Addresses{}
+	conf.RPC = ""
+
+	var err error
+	if n, err = node.NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { n.Close() })
+
+	return
+}
+
+func TestFeed_Update(t *testing.T) {
+
+	var (
+		n     = testNode(t)
+		_, sk = cipher.GenerateKeyPair()
+	)
+
+	var feed, err = NewFeed(n, sk, testRegistry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		loop  = NewLoop(n.Container())
+		names = make(chan []string, 10)
+	)
+	defer loop.Close()
+
+	go loop.Run(func(pack registry.Pack, r *registry.Root) (err error) {
+
+		var list List
+		if err = r.Refs[0].Value(pack, &list); err != nil {
+			return
+		}
+
+		var ns []string
+		err = NewCollection(&list.Items, Item{}).Each(pack, 0,
+			func(_ int, obj interface{}) (_ error) {
+				ns = append(ns, obj.(*Item).Name)
+				return
+			})
+
+		names <- ns
+		return
+	})
+
+	err = feed.Update(func(up *skyobject.Unpack, r *registry.Root) (err error) {
+
+		var list List
+		var items = NewCollection(&list.Items, Item{})
+
+		if err = items.Append(up, Item{"one"}, &Item{"two"}); err != nil {
+			return
+		}
+
+		if err = items.Append(up, "three"); err == nil {
+			t.Error("missing error")
+		}
+
+		var dr registry.Dynamic
+		if dr, err = NewDynamic(up, &list); err != nil {
+			return
+		}
+
+		r.Refs = []registry.Dynamic{dr}
+		return
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := feed.Root(); r.Seq != 0 || len(r.Refs) != 1 || r.IsFull == false {
+		t.Error("wrong Root of the Feed")
+	}
+
+	select {
+	case ns := <-names:
+		if len(ns) != 2 || ns[0] != "one" || ns[1] != "two" {
+			t.Error("wrong items:", ns)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow")
+	}
+
+	// reload
+
+	var reloaded *Feed
+	if reloaded, err = NewFeed(n, sk, testRegistry); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded.Root().Hash != feed.Root().Hash {
+		t.Error("last Root is not loaded")
+	}
+
+}
@@ -0,0 +1,240 @@
+// The board is simple P2P chat built using the appkit.
+// Every user has own feed with a Board. A user reads
+// lines from stdin and appends them to the Board as
+// messages, and the board shows messages of all boards
+// the node has
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+
+	"github.com/skycoin/cxo/intro/appkit"
+)
+
+// A Message of a Board
+type Message struct {
+	Author string // name of author
+	Text   string // the message
+	Time   int64  // unix nano
+}
+
+// A Board is list of messages of a user
+type Board struct {
+	Messages registry.Refs `skyobject:"schema=board.Message"`
+}
+
+// Registry of the board
+var Registry = registry.NewRegistry(func(r *registry.Reg) {
+	r.Register("board.Message", Message{})
+	r.Register("board.Board", Board{})
+})
+
+func main() {
+
+	var (
+		c = node.NewConfig()
+
+		name    = "anonymous" // name of user
+		seed    = ""          // seed of feed of the user
+		follow  = ""          // feeds to follow
+		connect = ""          // addresses to connect to
+	)
+
+	// use DB in memory for the example
+	c.Config.InMemoryDB = true
+	c.Logger.Prefix = "[board] "
+
+	flag.StringVar(&name, "name", name, "name of user")
+	flag.StringVar(&seed, "key-seed", seed,
+		"seed of secret key of feed of the user (required)")
+	flag.StringVar(&follow, "follow", follow,
+		"comma separated list of feeds to follow")
+	flag.StringVar(&connect, "connect", connect,
+		"comma separated list of addresses to connect to")
+
+	c.FromFlags()
+	flag.Parse()
+
+	if seed == "" {
+		log.Fatal("-key-seed is required")
+	}
+
+	var n, err = node.NewNode(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer n.Close()
+
+	// own feed
+
+	var _, sk = cipher.GenerateDeterministicKeyPair([]byte(seed))
+
+	var feed *appkit.Feed
+	if feed, err = appkit.NewFeed(n, sk, Registry); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("feed of the board:", feed.PubKey().Hex())
+
+	if len(feed.Root().Refs) == 0 {
+		err = feed.Update(func(up *skyobject.Unpack, r *registry.Root) (
+			err error) {
+
+			var dr registry.Dynamic
+			if dr, err = appkit.NewDynamic(up, &Board{}); err != nil {
+				return
+			}
+
+			r.Refs = []registry.Dynamic{dr}
+			return
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// show messages
+
+	var loop = appkit.NewLoop(n.Container())
+	defer loop.Close()
+
+	go func() {
+		if err := loop.Run(newViewer().show); err != nil {
+			log.Print("[ERR] ", err)
+		}
+	}()
+
+	// follow others
+
+	for _, address := range split(connect) {
+
+		var conn *node.Conn
+		if conn, err = n.TCP().Connect(address); err != nil {
+			log.Print("[ERR] connect ", address, ": ", err)
+			continue
+		}
+
+		for _, hex := range split(follow) {
+
+			var pk cipher.PubKey
+			if pk, err = cipher.PubKeyFromHex(hex); err != nil {
+				log.Fatal(err)
+			}
+
+			if err = conn.Subscribe(pk); err != nil {
+				log.Print("[ERR] subscribe ", hex[:7], ": ", err)
+			}
+
+		}
+
+	}
+
+	// post messages
+
+	var sc = bufio.NewScanner(os.Stdin)
+
+	for sc.Scan() {
+
+		var text = strings.TrimSpace(sc.Text())
+
+		if text == "" {
+			continue
+		}
+
+		if err = post(feed, name, text); err != nil {
+			log.Print("[ERR] ", err)
+		}
+
+	}
+
+}
+
+// append message to the Board
+func post(feed *appkit.Feed, name, text string) (err error) {
+	return feed.Update(func(up *skyobject.Unpack, r *registry.Root) (
+		err error) {
+
+		var board Board
+		if err = r.Refs[0].Value(up, &board); err != nil {
+			return
+		}
+
+		var msgs = appkit.NewCollection(&board.Messages, Message{})
+
+		err = msgs.Append(up, Message{
+			Author: name,
+			Text:   text,
+			Time:   time.Now().UnixNano(),
+		})
+
+		if err != nil {
+			return
+		}
+
+		return r.Refs[0].SetValue(up, &board)
+	})
+}
+
+// shows new messages of boards
+type viewer struct {
+	shown map[cipher.PubKey]int // feed -> number of shown messages
+}
+
+func newViewer() (v *viewer) {
+	v = new(viewer)
+	v.shown = make(map[cipher.PubKey]int)
+	return
+}
+
+// the appkit.Handler
+func (v *viewer) show(pack registry.Pack, r *registry.Root) (err error) {
+
+	if len(r.Refs) == 0 {
+		return // blank Root
+	}
+
+	var board Board
+	if err = r.Refs[0].Value(pack, &board); err != nil {
+		return
+	}
+
+	var msgs = appkit.NewCollection(&board.Messages, Message{})
+
+	err = msgs.Each(pack, v.shown[r.Pub],
+		func(i int, obj interface{}) (_ error) {
+
+			var msg = obj.(*Message)
+
+			fmt.Printf("[%s] %s: %s\n",
+				time.Unix(0, msg.Time).Format("15:04:05"),
+				msg.Author,
+				msg.Text)
+
+			v.shown[r.Pub] = i + 1
+			return
+		})
+
+	return
+}
+
+// split comma separated list
+func split(list string) (ss []string) {
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			ss = append(ss, s)
+		}
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package appkit
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A Collection represents typed list of objects over
+// registry.Refs. The Collection checks types of objects
+// appended and decoded. The Collection doesn't keep
+// objects, it works with given Refs. For example
+//
+//     var posts = appkit.NewCollection(&board.Posts, Post{})
+//
+//     err = posts.Append(up, Post{Text: "hello"})
+//
+//     err = posts.Each(pack, 0, func(i int, obj interface{}) error {
+//         fmt.Println(obj.(*Post).Text)
+//         return nil
+//     })
+//
+type Collection struct {
+	refs *registry.Refs
+	typ  reflect.Type
+}
+
+// NewCollection creates Collection over given Refs.
+// The elem is an object of the Collection (value or
+// pointer) used to get type of elements
+func NewCollection(refs *registry.Refs, elem interface{}) (c *Collection) {
+	c = new(Collection)
+	c.refs = refs
+	c.typ = reflect.Indirect(reflect.ValueOf(elem)).Type()
+	return
+}
+
+// check type of given object
+func (c *Collection) check(obj interface{}) (err error) {
+	if typ := reflect.Indirect(reflect.ValueOf(obj)).Type(); typ != c.typ {
+		err = fmt.Errorf("wrong type of object: want %s, got %s", c.typ, typ)
+	}
+	return
+}
+
+// Append objects to the Collection. Use *skyobject.Unpack
+// as the pack
+func (c *Collection) Append(pack registry.Pack, objs ...interface{}) (
+	err error) {
+
+	for _, obj := range objs {
+		if err = c.check(obj); err != nil {
+			return
+		}
+	}
+
+	return c.refs.AppendValues(pack, objs...)
+}
+
+// Len returns length of the Collection
+func (c *Collection) Len(pack registry.Pack) (ln int, err error) {
+	return c.refs.Len(pack)
+}
+
+// Get element by index. The obj should be pointer to
+// object of type of the Collection
+func (c *Collection) Get(pack registry.Pack, i int, obj interface{}) (
+	err error) {
+
+	if err = c.check(obj); err != nil {
+		return
+	}
+
+	_, err = c.refs.ValueByIndex(pack, i, obj)
+	return
+}
+
+// Each calls given function for every element of the
+// Collection from given index in ascending order. The
+// obj is pointer to decoded element. The Each does
+// nothing if the from is out of range. Use
+// registry.ErrStopIteration to stop the iteration.
+// Nil elements are skipped
+func (c *Collection) Each(
+	pack registry.Pack, //                          : pack to get
+	from int, //                                    : start from
+	eachFunc func(i int, obj interface{}) error, // : the function
+) (
+	err error, //                                   : an error
+) {
+
+	var ln int
+	if ln, err = c.refs.Len(pack); err != nil || from >= ln {
+		return // nothing to iterate
+	}
+
+	return c.refs.AscendFrom(pack, from,
+		func(i int, hash cipher.SHA256) (err error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // skip nil
+			}
+
+			var obj = reflect.New(c.typ).Interface()
+
+			if _, err = c.refs.ValueByIndex(pack, i, obj); err != nil {
+				return
+			}
+
+			return eachFunc(i, obj)
+		})
+
+}
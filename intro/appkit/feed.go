@@ -0,0 +1,169 @@
+// Package appkit implements reusable building blocks
+// of CXO applications: a Feed that keeps and updates
+// own Root of an application, a Collection that is typed
+// list of objects over registry.Refs and a Loop that
+// delivers new Root objects of feeds to the application.
+// See the board example for details
+package appkit
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/node"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A Feed represents feed owned by an application. The
+// Feed keeps last Root of the feed and used to update
+// the Root. The Feed is safe for concurrent use
+type Feed struct {
+	mx sync.Mutex
+
+	n  *node.Node
+	up *skyobject.Unpack
+	r  *registry.Root
+}
+
+// NewFeed creates Feed of given Node. The sk is secret
+// key of the feed. The reg is Registry of the application.
+// The NewFeed shares the feed (see (*node.Node).Share) and
+// loads last Root of the feed, if the Node has the Root.
+// Otherwise, the NewFeed creates new blank Root with random
+// nonce that will be saved by first Update
+func NewFeed(
+	n *node.Node, //              : the Node
+	sk cipher.SecKey, //          : secret key of the feed
+	reg *registry.Registry, //    : registry of the application
+) (
+	f *Feed, //                   : the Feed
+	err error, //                 : an error
+) {
+
+	if err = sk.Verify(); err != nil {
+		return
+	}
+
+	var pk = cipher.PubKeyFromSecKey(sk)
+
+	if err = n.Share(pk); err != nil {
+		return
+	}
+
+	var c = n.Container()
+
+	f = new(Feed)
+	f.n = n
+
+	if f.up, err = c.Unpack(sk, reg); err != nil {
+		return nil, err
+	}
+
+	var nonce = c.ActiveHead(pk)
+
+	if nonce == 0 {
+		f.r = &registry.Root{Pub: pk, Nonce: rand.Uint64()}
+		return
+	}
+
+	if f.r, err = c.LastRoot(pk, nonce); err == data.ErrNotFound {
+		f.r, err = &registry.Root{Pub: pk, Nonce: nonce}, nil
+	} else if err != nil {
+		return nil, err
+	} else if f.r.Reg != reg.Reference() {
+		return nil, errors.New("last Root of the feed has another Registry")
+	}
+
+	return
+}
+
+// PubKey returns public key of the Feed
+func (f *Feed) PubKey() cipher.PubKey {
+	return f.r.Pub
+}
+
+// Root returns copy of last Root of the Feed. The Root
+// is blank if the Feed has not been updated yet
+func (f *Feed) Root() (r *registry.Root) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	r = new(registry.Root)
+	*r = *f.r
+	r.Refs = append([]registry.Dynamic{}, f.r.Refs...)
+
+	return
+}
+
+// An UpdateFunc is used to change Root of a Feed. The
+// up is used to save objects and to get them. The
+// function can change Refs and Descriptor fields of
+// the Root only
+type UpdateFunc func(up *skyobject.Unpack, r *registry.Root) (err error)
+
+// Update Root of the Feed calling given function. If the
+// function returns an error, then the Root is not saved.
+// Otherwise, the Update saves and publishes the Root
+func (f *Feed) Update(updateFunc UpdateFunc) (err error) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var r = new(registry.Root)
+	*r = *f.r
+	r.Refs = append([]registry.Dynamic{}, f.r.Refs...)
+
+	if err = updateFunc(f.up, r); err != nil {
+		return
+	}
+
+	if err = f.n.Container().Save(f.up, r); err != nil {
+		return
+	}
+
+	f.n.Publish(r)
+	f.r = r
+
+	return
+}
+
+// NewDynamic creates Dynamic reference to given object
+// saving the object. Type of the object should be
+// registered in Registry of the pack
+func NewDynamic(
+	pack registry.Pack, //    : pack to save
+	obj interface{}, //       : an object
+) (
+	dr registry.Dynamic, //   : reference
+	err error, //             : an error
+) {
+
+	var (
+		reg  = pack.Registry()
+		name string
+		sch  registry.Schema
+	)
+
+	if reg.Types() == nil {
+		err = errors.New("Registry has no types (received Registry)")
+		return
+	}
+
+	if name, err = reg.Types().SchemaName(obj); err != nil {
+		return
+	}
+
+	if sch, err = reg.SchemaByName(name); err != nil {
+		return
+	}
+
+	dr.Schema = sch.Reference()
+	err = dr.SetValue(pack, obj)
+	return
+}
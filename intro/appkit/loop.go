@@ -0,0 +1,134 @@
+package appkit
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A Handler used by Loop to update state of an
+// application. The pack is used to get objects
+// of the Root
+type Handler func(pack registry.Pack, r *registry.Root) (err error)
+
+// A Loop delivers new Root objects of a Container to
+// an application. The Loop receives every Root saved
+// or filled by the Container (see (*skyobject.Container).
+// AddRootHook) and calls a Handler in its own goroutine.
+// If the Handler is slow, then the Loop skips
+// intermediate Root objects of a head and calls the
+// Handler with latest one. Thus, the Loop never blocks
+// the Container
+type Loop struct {
+	c *skyobject.Container
+
+	mx      sync.Mutex
+	pending map[loopHead]*registry.Root // latest not handled
+	order   []loopHead                  // order of the pending
+
+	notify chan struct{}
+
+	del    func() // remove the hook
+	closeo sync.Once
+	closeq chan struct{}
+}
+
+type loopHead struct {
+	pk    cipher.PubKey
+	nonce uint64
+}
+
+// NewLoop creates Loop of given Container. Use Run
+// to handle Root objects and Close to stop the Loop
+func NewLoop(c *skyobject.Container) (l *Loop) {
+
+	l = new(Loop)
+
+	l.c = c
+	l.pending = make(map[loopHead]*registry.Root)
+	l.notify = make(chan struct{}, 1)
+	l.closeq = make(chan struct{})
+
+	l.del = c.AddRootHook(l.rootHook)
+
+	return
+}
+
+func (l *Loop) rootHook(r *registry.Root) {
+
+	var lh = loopHead{r.Pub, r.Nonce}
+
+	l.mx.Lock()
+
+	if last, ok := l.pending[lh]; ok == false {
+		l.order = append(l.order, lh)
+	} else if last.Seq > r.Seq {
+		l.mx.Unlock()
+		return // keep latest
+	}
+
+	l.pending[lh] = r
+	l.mx.Unlock()
+
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+
+}
+
+// take pending Root objects
+func (l *Loop) take() (rs []*registry.Root) {
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	for _, lh := range l.order {
+		rs = append(rs, l.pending[lh])
+		delete(l.pending, lh)
+	}
+
+	l.order = l.order[:0]
+	return
+}
+
+// Run the Loop calling given Handler for new Root
+// objects until the Loop closed or the Handler
+// returns an error. The Run returns the error
+func (l *Loop) Run(handler Handler) (err error) {
+
+	for {
+
+		select {
+		case <-l.notify:
+		case <-l.closeq:
+			return
+		}
+
+		for _, r := range l.take() {
+
+			var pack *skyobject.Pack
+			if pack, err = l.c.Pack(r, nil); err != nil {
+				return
+			}
+
+			if err = handler(pack, r); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+}
+
+// Close the Loop. The Close stops the Run
+func (l *Loop) Close() {
+	l.closeo.Do(func() {
+		l.del()
+		close(l.closeq)
+	})
+}
@@ -0,0 +1,147 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// NormalizeAddress returns canonical form of given address
+// to compare addresses. The address can have a scheme
+// (tcp://, udp://, ws://, kcp:// or unix://). IPv6 literals
+// are compressed and bracketed, IPv4-mapped IPv6 addresses
+// converted to IPv4, zone IDs are kept, host names are
+// lower-cased and leading zeros of ports removed. For
+// example, tcp://[0:0::1]:08870 is tcp://[::1]:8870
+func NormalizeAddress(address string) (normalized string, err error) {
+
+	var scheme, rest string
+
+	if i := strings.Index(address, "://"); i >= 0 {
+		scheme, rest = strings.ToLower(address[:i]), address[i+3:]
+	} else {
+		return normalizeHostPort(address)
+	}
+
+	switch scheme {
+	case "tcp", "udp", "kcp":
+		if rest, err = normalizeHostPort(rest); err != nil {
+			return
+		}
+	case "ws":
+		if rest, err = normalizeWSHost(rest); err != nil {
+			return
+		}
+	case "unix":
+		if rest == "" {
+			return "", fmt.Errorf("blank path of address %q", address)
+		}
+		rest = filepath.Clean(rest)
+	default:
+		return "", fmt.Errorf("unknown scheme of address %q", address)
+	}
+
+	return scheme + "://" + rest, nil
+}
+
+// host:port
+func normalizeHostPort(address string) (normalized string, err error) {
+
+	var host, sport string
+	if host, sport, err = net.SplitHostPort(address); err != nil {
+		return
+	}
+
+	var port int
+	if port, err = strconv.Atoi(sport); err != nil || port < 0 ||
+		port > 0xffff {
+
+		return "", fmt.Errorf("invalid port of address %q", address)
+	}
+
+	var zone string
+	if i := strings.LastIndexByte(host, '%'); i >= 0 {
+		host, zone = host[:i], host[i+1:]
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			host, zone = ip4.String(), "" // IPv4 has no zones
+		} else {
+			host = ip.String()
+		}
+	} else if zone != "" {
+		return "", fmt.Errorf("zone of not an IPv6 address %q", address)
+	} else {
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+	}
+
+	if zone != "" {
+		host += "%" + zone
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+// host:port/path of WebSocket URL, the zone
+// of the host can be escaped (%25) or not
+func normalizeWSHost(rest string) (normalized string, err error) {
+
+	var path string
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		rest, path = rest[:i], rest[i:]
+	}
+
+	rest = strings.Replace(rest, "%25", "%", 1)
+
+	if rest, err = normalizeHostPort(rest); err != nil {
+		return
+	}
+
+	return strings.Replace(rest, "%", "%25", 1) + path, nil
+}
+
+// the TCP and the net.Listen listen on first IPv4
+// address of a host name; the dualStack returns
+// rest addresses of given listening address to
+// listen on them too; an empty host and IP literals
+// are not resolved, and wildcard addresses (empty
+// host, 0.0.0.0 and [::]) are dual-stack already
+func dualStack(address string) (others []string) {
+
+	var host, port, err = net.SplitHostPort(address)
+
+	if err != nil || host == "" || port == "0" ||
+		net.ParseIP(host) != nil {
+
+		return
+	}
+
+	var ips []net.IP
+	if ips, err = net.LookupIP(host); err != nil || len(ips) < 2 {
+		return
+	}
+
+	var first = ips[0]
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			first = ip
+			break
+		}
+	}
+
+	var seen = map[string]struct{}{first.String(): {}}
+
+	for _, ip := range ips {
+		var s = ip.String()
+		if _, ok := seen[s]; ok == true {
+			continue
+		}
+		seen[s] = struct{}{}
+		others = append(others, net.JoinHostPort(s, port))
+	}
+
+	return
+}
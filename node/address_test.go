@@ -0,0 +1,105 @@
+package node
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+
+	for address, want := range map[string]string{
+		"127.0.0.1:8870":               "127.0.0.1:8870",
+		"Example.COM.:08870":           "example.com:8870",
+		"[0:0::1]:8870":                "[::1]:8870",
+		"[::ffff:127.0.0.1]:8870":      "127.0.0.1:8870",
+		"[FE80::1%eth0]:8870":          "[fe80::1%eth0]:8870",
+		"tcp://[2001:DB8:0::1]:8870":   "tcp://[2001:db8::1]:8870",
+		"KCP://127.0.0.1:8870":         "kcp://127.0.0.1:8870",
+		"ws://[fe80::1%25eth0]:80/cxo": "ws://[fe80::1%25eth0]:80/cxo",
+		"ws://[fe80::1%eth0]:80":       "ws://[fe80::1%25eth0]:80",
+		"unix:///tmp/../tmp/cxo.sock":  "unix:///tmp/cxo.sock",
+	} {
+		var got, err = NormalizeAddress(address)
+		if err != nil {
+			t.Error(address, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: want %s, got %s", address, want, got)
+		}
+	}
+
+	for _, address := range []string{
+		"::1:8870",
+		"127.0.0.1",
+		"127.0.0.1:65536",
+		"example.com%eth0:8870",
+		"http://127.0.0.1:8870",
+	} {
+		if _, err := NormalizeAddress(address); err == nil {
+			t.Error("missing error:", address)
+		}
+	}
+
+}
+
+func Test_dualStack(t *testing.T) {
+
+	assertTrue(t, len(dualStack("127.0.0.1:8087")) == 0, "IP resolved")
+	assertTrue(t, len(dualStack(":8087")) == 0, "wildcard resolved")
+	assertTrue(t, len(dualStack("localhost:0")) == 0, "OS-choosed port")
+
+	var ips, err = net.LookupIP("localhost")
+	if err != nil || len(ips) < 2 {
+		t.Skip("localhost has no IPv6 address")
+	}
+
+	var others = dualStack("localhost:8087")
+	assertTrue(t, len(others) > 0, "not resolved")
+
+	for _, other := range others {
+		var host, _, _ = net.SplitHostPort(other)
+		assertTrue(t, net.ParseIP(host).To4() == nil, "IPv4 address: "+other)
+	}
+
+}
+
+func TestNode_ipv6(t *testing.T) {
+
+	var l, err = net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 is not available")
+	}
+	l.Close()
+
+	var conf = getTestConfig("server")
+	conf.TCP.Listen = "[::1]:8087"
+	conf.UDP.Listen = ""
+
+	var s *Node
+	if s, err = NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.Connect("tcp://[0:0::1]:8087"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, dialAddress(c) == "tcp://[::1]:8087",
+		"wrong address: "+dialAddress(c))
+
+	// the same connection
+
+	var same *Conn
+	if same, err = cn.Connect("[::1]:08087"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, same == c, "duplicate connection")
+
+}
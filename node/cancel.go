@@ -0,0 +1,54 @@
+package node
+
+import (
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// notify remote peer that request with given
+// seq is canceled and reply is not needed
+func (c *Conn) sendCancel(seq uint32) {
+
+	if c.HasFeature(msg.FeatureCancel) == false {
+		return // the peer will reply or timeout
+	}
+
+	c.sendMsg(c.nextSeq(), 0, &msg.Cancel{Seq: seq})
+}
+
+// register received request that can be canceled,
+// the channel is closed if the request canceled
+func (c *Conn) addCancelable(seq uint32) (canceled <-chan struct{}) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.cancels == nil {
+		c.cancels = make(map[uint32]chan struct{})
+	}
+
+	var cq = make(chan struct{})
+	c.cancels[seq] = cq
+
+	return cq
+}
+
+// the request has been replied or canceled
+func (c *Conn) delCancelable(seq uint32) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	delete(c.cancels, seq)
+}
+
+// <- Cancel (seq)
+func (c *Conn) handleCancel(cl *msg.Cancel) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleCancel %d", c.String(), cl.Seq)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if cq, ok := c.cancels[cl.Seq]; ok == true {
+		close(cq)
+		delete(c.cancels, cl.Seq)
+	}
+}
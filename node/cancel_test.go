@@ -0,0 +1,75 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+)
+
+func TestConn_sendCancel(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		sc *Conn
+		ok bool
+	)
+
+	for i := 0; i < 100 && ok == false; i++ {
+		if sc, ok = sn.hasPeer(cn.ID()); ok == false {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	assertTrue(t, ok == true, "missing incoming connection")
+
+	var (
+		cancel = make(chan struct{})
+		key    = cipher.SumSHA256([]byte("missing object"))
+	)
+
+	// the server doesn't have the object, but it's wanted
+	// by a filler; thus the server waits for it
+	var gc = make(chan skyobject.Object, 1)
+	if err = sn.c.Want(key, gc, 1); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.c.Unwant(key, gc)
+
+	time.AfterFunc(50*time.Millisecond, func() { close(cancel) })
+
+	_, err = c.sendFeedRequest(pk, &msg.RqObject{Key: key}, cancel)
+
+	if err != ErrCanceled {
+		t.Fatalf("wrong error: want %v, got %v", ErrCanceled, err)
+	}
+
+	var pending = 1
+
+	// the response timeout is one second
+	for i := 0; i < 50 && pending > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+
+		sc.mx.Lock()
+		pending = len(sc.cancels)
+		sc.mx.Unlock()
+	}
+
+	if pending > 0 {
+		t.Error("request is not canceled by remote peer")
+	}
+
+}
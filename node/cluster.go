@@ -0,0 +1,200 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A Cluster coordinates many Node instances that share
+// one Container to scale a popular public seed. Members
+// of a Cluster are identified by ID of a Node. Only a
+// member that holds lease of a feed fills Root objects
+// of the feed received from peers. Other members drop
+// them, since filled Root objects are saved to the
+// shared Container by the holder. Other members announce
+// every new Root saved to the shared Container to their
+// peers and serve its objects. A lease is extended
+// every time the holder receives a Root of the feed,
+// and if the holder goes down, then another member
+// takes the lease after the lease time (see Config.
+// ClusterLease).
+//
+// Members must be created by NewNodeContainer with the
+// same Container. A Container keeps index of Root objects
+// and reference counters of objects in memory, thus many
+// Containers (and many processes) can't share one DB.
+// A member never closes the shared Container, close it
+// after all members. The MemoryCluster is implementation
+// of the Cluster for members of one process
+type Cluster interface {
+	// Lease given feed for given member for given time.
+	// The Lease returns false if another member holds
+	// the lease. The Lease extends the lease if the
+	// member already holds it
+	Lease(feed, member cipher.PubKey, ttl time.Duration) (ok bool, err error)
+	// Release lease of given feed if given
+	// member holds it
+	Release(feed, member cipher.PubKey) (err error)
+}
+
+// a lease of a feed
+type lease struct {
+	member cipher.PubKey // holder
+	till   time.Time     // expiration time
+}
+
+// A MemoryCluster is in-memory Cluster
+// for Node instances of one process
+type MemoryCluster struct {
+	mx     sync.Mutex
+	leases map[cipher.PubKey]lease // feed -> lease
+}
+
+// NewMemoryCluster creates new MemoryCluster
+func NewMemoryCluster() (m *MemoryCluster) {
+	m = new(MemoryCluster)
+	m.leases = make(map[cipher.PubKey]lease)
+	return
+}
+
+// Lease implements Cluster interface
+func (m *MemoryCluster) Lease(
+	feed cipher.PubKey, //   : feed to lease
+	member cipher.PubKey, // : member
+	ttl time.Duration, //    : lease time
+) (
+	ok bool, //              : holds
+	err error, //            : never returned
+) {
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	var (
+		now    = time.Now()
+		l, has = m.leases[feed]
+	)
+
+	if has == true && l.member != member && now.Before(l.till) == true {
+		return // held by another member
+	}
+
+	m.leases[feed] = lease{member, now.Add(ttl)}
+	return true, nil
+}
+
+// Release implements Cluster interface
+func (m *MemoryCluster) Release(feed, member cipher.PubKey) (_ error) {
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if l, ok := m.leases[feed]; ok == true && l.member == member {
+		delete(m.leases, feed)
+	}
+
+	return
+}
+
+// leases held by a member of a Cluster
+type heldLeases struct {
+	mx    sync.Mutex
+	feeds map[cipher.PubKey]time.Time // feed -> expiration time
+}
+
+func newHeldLeases() (h *heldLeases) {
+	h = new(heldLeases)
+	h.feeds = make(map[cipher.PubKey]time.Time)
+	return
+}
+
+func (h *heldLeases) set(feed cipher.PubKey, ttl time.Duration) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	h.feeds[feed] = time.Now().Add(ttl)
+}
+
+func (h *heldLeases) del(feed cipher.PubKey) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	delete(h.feeds, feed)
+}
+
+func (h *heldLeases) has(feed cipher.PubKey) (ok bool) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	var till time.Time
+	if till, ok = h.feeds[feed]; ok == true && time.Now().After(till) {
+		delete(h.feeds, feed)
+		ok = false
+	}
+	return
+}
+
+// holds lease of given feed (or the Node is not a
+// member of a Cluster)
+func (n *Node) holdsLease(feed cipher.PubKey) (ok bool) {
+
+	if n.config.Cluster == nil {
+		return true
+	}
+
+	var (
+		ttl = n.config.ClusterLease
+		err error
+	)
+
+	ok, err = n.config.Cluster.Lease(feed, n.ID(), ttl)
+
+	if err != nil {
+		n.Printf("[ERR] lease of %s: %s", feed.Hex()[:7], err)
+		ok = false
+	}
+
+	if ok == true {
+		n.hl.set(feed, ttl)
+	} else {
+		n.hl.del(feed)
+	}
+
+	return
+}
+
+// release lease of given feeds
+func (n *Node) releaseLeases(feeds ...cipher.PubKey) {
+
+	if n.config.Cluster == nil {
+		return
+	}
+
+	for _, feed := range feeds {
+		n.hl.del(feed)
+		if err := n.config.Cluster.Release(feed, n.ID()); err != nil {
+			n.Printf("[ERR] release lease of %s: %s", feed.Hex()[:7], err)
+		}
+	}
+
+}
+
+// (see skyobject.RootHook) a Root saved to the shared
+// Container by another member of the Cluster is
+// announced to peers of the Node
+func (n *Node) clusterRootHook(r *registry.Root) {
+
+	if r.IsPartial == true {
+		return // never announced (see SetFilter)
+	}
+
+	if n.hl.has(r.Pub) == true {
+		return // filled and announced by the Node
+	}
+
+	n.fs.broadcastRoot(connRoot{r: r})
+}
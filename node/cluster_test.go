@@ -0,0 +1,219 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestMemoryCluster_Lease(t *testing.T) {
+
+	var (
+		mc = NewMemoryCluster()
+
+		feed, _  = cipher.GenerateKeyPair()
+		alice, _ = cipher.GenerateKeyPair()
+		bob, _   = cipher.GenerateKeyPair()
+	)
+
+	var lease = func(member cipher.PubKey, ttl time.Duration) bool {
+		t.Helper()
+		var ok, err = mc.Lease(feed, member, ttl)
+		assertNil(t, err)
+		return ok
+	}
+
+	assertTrue(t, lease(alice, time.Minute) == true, "can't lease")
+	assertTrue(t, lease(bob, time.Minute) == false, "leased twice")
+	assertTrue(t, lease(alice, time.Minute) == true, "can't extend")
+
+	assertNil(t, mc.Release(feed, bob)) // not a holder
+	assertTrue(t, lease(bob, time.Minute) == false, "released by bob")
+
+	assertNil(t, mc.Release(feed, alice))
+	assertTrue(t, lease(bob, time.Millisecond) == true, "not released")
+
+	time.Sleep(10 * time.Millisecond)
+	assertTrue(t, lease(alice, time.Minute) == true, "not expired")
+
+}
+
+// members of a Cluster sharing one Container
+func getTestClusterMembers(
+	t *testing.T,
+	mc Cluster,
+	confs ...*Config,
+) (
+	c *skyobject.Container,
+	members []*Node,
+) {
+
+	var sc = skyobject.NewConfig()
+	sc.InMemoryDB = true
+
+	var err error
+	if c, err = skyobject.NewContainer(sc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, conf := range confs {
+
+		conf.Cluster = mc
+
+		var n *Node
+		if n, err = NewNodeContainer(conf, c); err != nil {
+			t.Fatal(err)
+		}
+
+		members = append(members, n)
+	}
+
+	return
+}
+
+func TestNode_holdsLease(t *testing.T) {
+
+	var (
+		mc = NewMemoryCluster()
+
+		feed, _ = cipher.GenerateKeyPair()
+
+		c, nodes = getTestClusterMembers(t, mc,
+			getTestConfigNotListen("member"),
+			getTestConfigNotListen("member"))
+	)
+
+	defer c.Close()
+
+	for _, n := range nodes {
+		defer n.Close()
+		assertNil(t, n.Share(feed))
+	}
+
+	assertTrue(t, nodes[0].holdsLease(feed) == true, "can't lease")
+	assertTrue(t, nodes[1].holdsLease(feed) == false, "leased twice")
+
+	assertNil(t, nodes[0].Close()) // releases
+	assertTrue(t, nodes[1].holdsLease(feed) == true, "not released")
+
+	// the shared Container is not closed by a member
+	if _, err := c.Heads(feed); err != nil {
+		t.Error("shared Container closed:", err)
+	}
+
+	// not a member
+
+	var n = getTestNodeNotListen("alone")
+	defer n.Close()
+
+	assertTrue(t, n.holdsLease(feed) == true, "not a member")
+
+	// a member with its own Container
+
+	var conf = getTestConfigNotListen("member")
+	conf.Cluster = mc
+
+	if _, err := NewNode(conf); err != ErrClusterContainer {
+		t.Error("missing ErrClusterContainer:", err)
+	}
+
+}
+
+func TestNode_clusterRootHook(t *testing.T) {
+
+	var (
+		mc = NewMemoryCluster()
+
+		fr = make(chan *registry.Root, 10)
+
+		pconf = getTestConfig("publisher")
+		sconf = getTestConfigNotListen("subscriber")
+		hconf = getTestConfigNotListen("holder")
+		mconf = getTestConfigNotListen("member")
+	)
+
+	mconf.TCP.Listen = "127.0.0.1:8088"
+	sconf.OnRootFilled = func(_ *Node, r *registry.Root) { fr <- r }
+
+	var c, members = getTestClusterMembers(t, mc, hconf, mconf)
+	defer c.Close()
+
+	var (
+		holder, member = members[0], members[1]
+		pk, sk         = cipher.GenerateKeyPair()
+	)
+
+	defer holder.Close()
+	defer member.Close()
+
+	var pn, err = NewNode(pconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pn.Close()
+
+	var sn *Node
+	if sn, err = NewNode(sconf); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	for _, n := range []*Node{pn, sn, holder, member} {
+		assertNil(t, n.Share(pk))
+	}
+
+	assertTrue(t, holder.holdsLease(pk) == true, "can't lease")
+
+	// the subscriber is connected to the member only
+
+	var sc *Conn
+	if sc, err = sn.TCP().Connect(member.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+	assertNil(t, sc.Subscribe(pk))
+
+	// the publisher is connected to the holder only
+
+	var up *skyobject.Unpack
+	if up, err = pn.Container().Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		r    = &registry.Root{Pub: pk, Nonce: 1}
+		user = dynamicByValue(t, up, "test.User", User{"Alice", 19, nil})
+	)
+
+	r.Refs = append(r.Refs, user)
+	assertNil(t, pn.Container().Save(up, r))
+
+	var hc *Conn
+	if hc, err = holder.TCP().Connect(pn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+	assertNil(t, hc.Subscribe(pk))
+
+	var cs = pn.ConnectionsOfFeed(pk)
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+	cs[0].sendLastRoot(pk) // the Root can be received before the reply
+
+	// filled by the holder, announced and served by the member
+
+	select {
+	case rr := <-fr:
+		assertTrue(t, rr.Hash == r.Hash, "wrong Root")
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow")
+	}
+
+	if _, _, err = sn.Container().Get(user.Hash, 0); err != nil {
+		t.Error("missing object:", err)
+	}
+
+}
@@ -0,0 +1,194 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compression algorithms
+const (
+	CompressionSnappy string = "snappy" // fast, moderate ratio
+	CompressionZstd   string = "zstd"   // slower, better ratio
+)
+
+// max size of decompressed message, protects against
+// decompression bombs; a Conn uses the Config.MaxMessageSize
+// if it's less (see maxDecompressed)
+const maxDecompressedSize = 128 * 1024 * 1024
+
+// Compressions is list of compression algorithms
+// in order of preference
+type Compressions []string
+
+// String implements flag.Value interface
+func (c *Compressions) String() string {
+	return strings.Join(*c, ",")
+}
+
+// Set implements flag.Value interface. The Set
+// accepts comma separated list of algorithms
+func (c *Compressions) Set(list string) error {
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*c = append(*c, name)
+		}
+	}
+	return nil
+}
+
+// Validate the Compressions
+func (c Compressions) Validate() (err error) {
+	for _, name := range c {
+		if _, err = compressorByName(name); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// has the Compressions given algorithm
+func (c Compressions) has(name string) bool {
+	for _, n := range c {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// choose first algorithm of the remote peer list
+// the Compressions has, blank string if no one
+func (c Compressions) choose(remote []string) (name string) {
+	for _, name = range remote {
+		if c.has(name) == true {
+			return
+		}
+	}
+	return ""
+}
+
+// a compressor compresses bodies of messages
+// of a connection; it's safe for concurrent use;
+// the decompress rejects a message decompressed
+// size of which is greater than given max before
+// allocating memory for it
+type compressor interface {
+	compress(p []byte) []byte
+	decompress(p []byte, max int) ([]byte, error)
+}
+
+func compressorByName(name string) (cmp compressor, err error) {
+	switch name {
+	case "":
+		return // no compression
+	case CompressionSnappy:
+		return snappyCompressor{}, nil
+	case CompressionZstd:
+		return getZstdCompressor()
+	}
+	return nil, fmt.Errorf("unknown compression algorithm: %q", name)
+}
+
+type snappyCompressor struct{}
+
+func (snappyCompressor) compress(p []byte) []byte {
+	return snappy.Encode(nil, p)
+}
+
+func (snappyCompressor) decompress(p []byte, max int) (dp []byte, err error) {
+
+	var ln int
+	if ln, err = snappy.DecodedLen(p); err != nil {
+		return
+	}
+
+	if ln > max {
+		return nil, ErrDecompressedTooLarge
+	}
+
+	return snappy.Decode(nil, p)
+}
+
+// the zstd.Encoder and zstd.Decoder are heavy and
+// safe for concurrent use, thus they are shared
+// between all connections; a zstd.Decoder limits
+// decompressed size, thus there is a decoder per
+// limit (see decoder)
+var (
+	zstdOnce sync.Once
+	zstdCmp  *zstdCompressor
+	zstdErr  error
+)
+
+type zstdCompressor struct {
+	enc *zstd.Encoder
+
+	mx   sync.Mutex
+	decs map[int]*zstd.Decoder // by max decompressed size
+}
+
+func getZstdCompressor() (cmp compressor, err error) {
+
+	zstdOnce.Do(func() {
+
+		var z = new(zstdCompressor)
+
+		if z.enc, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+
+		z.decs = make(map[int]*zstd.Decoder)
+		zstdCmp = z
+	})
+
+	if zstdErr != nil {
+		return nil, zstdErr
+	}
+
+	return zstdCmp, nil
+}
+
+func (z *zstdCompressor) compress(p []byte) []byte {
+	return z.enc.EncodeAll(p, nil)
+}
+
+// decoder that limits decompressed size by given max,
+// it rejects a frame with greater declared size before
+// allocating memory for it
+func (z *zstdCompressor) decoder(max int) (dec *zstd.Decoder, err error) {
+
+	z.mx.Lock()
+	defer z.mx.Unlock()
+
+	if dec = z.decs[max]; dec != nil {
+		return
+	}
+
+	dec, err = zstd.NewReader(nil,
+		zstd.WithDecoderMaxMemory(uint64(max)),
+		zstd.WithDecoderConcurrency(0))
+
+	if err != nil {
+		return
+	}
+
+	z.decs[max] = dec
+	return
+}
+
+func (z *zstdCompressor) decompress(p []byte, max int) (dp []byte, err error) {
+
+	var dec *zstd.Decoder
+	if dec, err = z.decoder(max); err != nil {
+		return
+	}
+
+	if dp, err = dec.DecodeAll(p, nil); err == zstd.ErrDecoderSizeExceeded {
+		err = ErrDecompressedTooLarge
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestConn_Compression(t *testing.T) {
+
+	var sconf = getTestConfig("server")
+	sconf.Compression = Compressions{CompressionZstd, CompressionSnappy}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var feed, _ = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(feed))
+
+	for _, tc := range []struct {
+		client Compressions
+		want   string
+	}{
+		{nil, ""},
+		{Compressions{CompressionSnappy, CompressionZstd}, CompressionSnappy},
+		{Compressions{CompressionZstd}, CompressionZstd},
+	} {
+
+		var cconf = getTestConfigNotListen("client")
+		cconf.Compression = tc.client
+
+		var cn *Node
+		if cn, err = NewNode(cconf); err != nil {
+			t.Fatal(err)
+		}
+
+		var c *Conn
+		if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+			cn.Close()
+			t.Fatal(err)
+		}
+
+		if got := c.Compression(); got != tc.want {
+			t.Errorf("wrong compression: want %q, got %q", tc.want, got)
+		}
+
+		// messages after handshake
+		if err = c.Subscribe(feed); err != nil {
+			t.Error(err)
+		}
+
+		cn.Close()
+	}
+
+	// unknown
+
+	var conf = getTestConfigNotListen("unknown")
+	conf.Compression = Compressions{"lzma"}
+
+	if _, err = NewNode(conf); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func Test_compressor_decompressLimit(t *testing.T) {
+
+	var body = make([]byte, 4096) // well compressed
+
+	for _, name := range []string{CompressionSnappy, CompressionZstd} {
+
+		var cmp, err = compressorByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var p = cmp.compress(body)
+
+		if _, err = cmp.decompress(p, len(body)-1); err != ErrDecompressedTooLarge {
+			t.Errorf("%s: wrong error: want %v, got %v", name,
+				ErrDecompressedTooLarge, err)
+		}
+
+		var dp []byte
+		if dp, err = cmp.decompress(p, len(body)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if len(dp) != len(body) {
+			t.Errorf("%s: wrong length: %d", name, len(dp))
+		}
+
+	}
+
+}
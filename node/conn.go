@@ -16,10 +16,13 @@ import (
 	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
+	"github.com/skycoin/cxo/skyobject/statutil"
 )
 
 // A Conn represent connection of the Node
 type Conn struct {
+	lastRead int64 // unix nano, atomic, first for alignment
+
 	*factory.Connection
 
 	// lock
@@ -34,17 +37,95 @@ type Conn struct {
 	seq  uint32                    // messege seq number (for request-response)
 	reqs map[uint32]chan<- msg.Msg // requests
 
+	// trace IDs (see trace.go)
+	rqTraces map[uint32]TraceID // own requests
+	rpTraces map[uint32]TraceID // received requests to echo
+
+	// ownership proofs (see ownership.go)
+	chl map[cipher.PubKey]cipher.SHA256 // sent challenges
+	own map[cipher.PubKey]struct{}      // proved feeds of remote peer
+
+	// shared feeds (see shared_feeds.go)
+	want   map[cipher.PubKey]struct{} // feeds the remote peer is ready to serve
+	shared []cipher.PubKey            // last known mutually shared feeds
+
+	// subscription leases (see sub_lease.go)
+	leases map[cipher.PubKey]*time.Timer // leases of remote peer
+	renew  map[cipher.PubKey]*time.Timer // renewal of own leases
+
+	// schema filters of remote peer (see sub_filter.go)
+	filters map[cipher.PubKey][]string
+
+	// key of reputation (see reputation.go)
+	repKey string
+
+	// have-lists of remote peer (see have_list.go)
+	have map[cipher.PubKey]*HaveList
+
+	// negotiated protocol version and features,
+	// set by handshake before the Conn used
+	version  uint32
+	features uint64
+
+	// keep-alive (see keep_alive.go)
+	rtt *statutil.Duration // average RTT or nil
+
+	// missing objects (see not_found.go)
+	miss *statutil.Float // rolling miss rate of object requests or nil
+
+	// request routing (see latency.go)
+	svc *statutil.Duration // average service time of object requests or nil
+
+	// flow control (see credit.go)
+	credits map[cipher.PubKey]*credit // credits by feed
+
+	// batched requests of registries (see registries.go)
+	regs []*rqRegistry
+
+	// chunked objects (see data_chunk.go)
+	chunks map[uint32]*chunked // objects being received by rseq
+
+	// canceled requests (see cancel.go)
+	cancels map[uint32]chan struct{} // incoming requests can be canceled
+
+	// encryption (see encryption.go)
+	key    *[32]byte           // key of sent messages or nil
+	rkey   *[32]byte           // key of received messages
+	sealed map[uint32]struct{} // received encrypted requests
+
+	// the peerID verified by Noise handshake (see noise.go)
+	authenticated bool
+
+	// last forwarded or handled punch request (see rendezvous.go)
+	punched time.Time
+
+	// compression (see compression.go), set
+	// by handshake before the Conn used
+	cmp     compressor
+	cmpName string
+
 	// # stat
 	//
 	// TODO (kostyarin): stat without mutexes to do not slow down the connection
 	//
 	// ------
 
-	sendq chan<- []byte // channel from factory.Connection
+	stats map[msg.Type]*msgStat // traffic by message type (see msg_stats.go)
+
+	// connection limits (see peer_limits.go)
+	established time.Time   // added to the Node
+	served      servedBytes // recent bytes served by remote peer
+
+	bw bandwidth // rate limits (see rate_limit.go)
+
+	manual bool // closed by Close (see reconnect.go)
+
+	sq *sendQueue // by class (see send_queue.go)
 
 	await  sync.WaitGroup // wait for receiving loop
 	closeq chan struct{}  //
 	closeo sync.Once      // close once
+	sendoq chan struct{}  // closed by sending loop
 }
 
 func (n *Node) newConnection(
@@ -63,8 +144,11 @@ func (n *Node) newConnection(
 
 	c.reqs = make(map[uint32]chan<- msg.Msg)
 
-	c.sendq = fc.GetChanOut()
+	c.sq = newSendQueue()
+	c.bw.set(n.PeerRateLimit())
+
 	c.closeq = make(chan struct{})
+	c.sendoq = make(chan struct{})
 
 	n.addPendingConn(c)
 
@@ -77,8 +161,16 @@ func (n *Node) newConnection(
 
 // start handling
 func (c *Conn) run() {
-	c.await.Add(1)
+	c.await.Add(2)
 	go c.receiving()
+	go c.sending()
+
+	c.touch()
+
+	if c.pings() > 0 && c.HasFeature(msg.FeaturePing) == true {
+		c.await.Add(1)
+		go c.pinging()
+	}
 }
 
 func (c *Conn) decodeRaw(raw []byte) (seq, rseq uint32, m msg.Msg, err error) {
@@ -94,10 +186,84 @@ func (c *Conn) decodeRaw(raw []byte) (seq, rseq uint32, m msg.Msg, err error) {
 	rseq = binary.LittleEndian.Uint32(raw)
 	raw = raw[4:]
 
-	m, err = msg.Decode(raw)
+	if _, raw, err = c.untrace(raw); err != nil {
+		return
+	}
+
+	if raw, err = c.decompress(raw); err != nil {
+		return
+	}
+
+	m, err = c.decode(raw)
+	return
+}
+
+// unwrap received message if the FeatureFrames is used;
+// otherwise, the unframe returns Frame with given message
+// as the Body, without the Type
+func (c *Conn) unframe(raw []byte) (
+	fr msg.Frame, //   : received frame
+	framed bool, //    : the FeatureFrames is used
+	err error, //      : malformed frame
+) {
+
+	if c.HasFeature(msg.FeatureFrames) == false {
+		fr.Body = raw
+		if c.cmp != nil {
+			fr.Flags = msg.FrameCompressed
+		}
+		return
+	}
+
+	if fr, err = msg.DecodeFrame(raw); err != nil {
+		return
+	}
+
+	if fr.IsKeepAlive() == true && len(fr.Body) != 0 {
+		err = msg.ErrMalformed
+	}
+
+	return fr, true, err
+}
+
+// decompress body of received message
+func (c *Conn) decompress(raw []byte) ([]byte, error) {
+	if c.cmp == nil {
+		return raw, nil
+	}
+	return c.cmp.decompress(raw, c.maxDecompressed())
+}
+
+// max size of decompressed message, the Config.MaxMessageSize
+// if it's set, since greater messages are rejected anyway
+func (c *Conn) maxDecompressed() (max int) {
+	max = maxDecompressedSize
+	if ms := c.n.config.MaxMessageSize; ms > 0 && ms < max {
+		max = ms
+	}
 	return
 }
 
+// decode received message using limits of the Node
+// (see Config.MaxMessageSize and MaxMessageLength)
+func (c *Conn) decode(raw []byte) (m msg.Msg, err error) {
+
+	var l = msg.Limits{
+		MaxSize:   c.n.config.MaxMessageSize,
+		MaxLength: c.n.config.MaxMessageLength,
+	}
+
+	return c.codec().Decode(raw, &l)
+}
+
+// codec of messages (see Config.Codec)
+func (c *Conn) codec() msg.Codec {
+	if c.n.config.Codec == nil {
+		return msg.DefaultCodec
+	}
+	return c.n.config.Codec
+}
+
 //
 // info
 //
@@ -108,6 +274,28 @@ func (c *Conn) PeerID() (id cipher.PubKey) {
 	return c.peerID
 }
 
+// Compression returns compression algorithm
+// of the Conn or blank string if messages are
+// not compressed (see Config.Compression)
+func (c *Conn) Compression() string {
+	return c.cmpName
+}
+
+// ProtocolVersion returns protocol version negotiated
+// with remote peer, it's the lowest of versions of this
+// Node and the peer
+func (c *Conn) ProtocolVersion() uint32 {
+	return c.version
+}
+
+// HasFeature returns true if given optional feature
+// of the protocol (msg.FeatureX) is supported by both,
+// this Node and remote peer. Features used by Conn
+// only if the HasFeature
+func (c *Conn) HasFeature(feature uint64) bool {
+	return c.features&feature == feature
+}
+
 // IsIncoming returns true if this Conn is
 // incoming and accepted by listener
 func (c *Conn) IsIncoming() (ok bool) {
@@ -137,7 +325,7 @@ func (c *Conn) Feeds() (feeds []cipher.PubKey) {
 
 }
 
-func connString(isIncoming, isTCP bool, addr string) (s string) {
+func connString(isIncoming bool, network, addr string) (s string) {
 
 	if isIncoming == true {
 		s = "↓ "
@@ -145,13 +333,7 @@ func connString(isIncoming, isTCP bool, addr string) (s string) {
 		s = "↑ "
 	}
 
-	if isTCP == true {
-		s += "tcp://"
-	} else {
-		s += "udp://"
-	}
-
-	return s + addr
+	return s + network + "://" + addr
 }
 
 // String returns string "-> network://remote_address"
@@ -159,7 +341,7 @@ func connString(isIncoming, isTCP bool, addr string) (s string) {
 // arrow is "->" for incoming connections and is "<-"
 // for outgoing
 func (c *Conn) String() (s string) {
-	return connString(c.incoming, c.IsTCP(), c.Address())
+	return connString(c.incoming, network(c.Connection), c.Address())
 }
 
 //
@@ -168,8 +350,11 @@ func (c *Conn) String() (s string) {
 
 // RemoteFeeds requests list of feeds that remote peer share.
 // It's possible if the remote peer is public server, otherwise
-// it returns "not a public server" error. The request has
-// timeout configured by Config
+// it returns "not a public server" error. The list can be used
+// to discover feeds and subscribe to them. The list doesn't
+// contain feeds the Conn can't subscribe to (see
+// Config.EncryptFeeds). The request has timeout configured
+// by Config
 func (c *Conn) RemoteFeeds() (feeds []cipher.PubKey, err error) {
 
 	var reply msg.Msg
@@ -186,7 +371,7 @@ func (c *Conn) RemoteFeeds() (feeds []cipher.PubKey, err error) {
 
 	case *msg.Err:
 
-		err = errors.New(x.Err)
+		err = remoteError(x.Err)
 
 	default:
 
@@ -197,13 +382,21 @@ func (c *Conn) RemoteFeeds() (feeds []cipher.PubKey, err error) {
 	return
 }
 
+// send Root, sealed by key of its feed if the feed is encrypted
 func (c *Conn) sendRoot(r *registry.Root) {
-	c.sendMsg(c.nextSeq(), 0, &msg.Root{
+
+	var val, ok = c.n.sealValue(r.Pub, r.Encode())
+
+	if ok == false {
+		return // relay never sends plain Root objects
+	}
+
+	c.sendFeedMsg(r.Pub, &msg.Root{
 		Feed:  r.Pub,
 		Nonce: r.Nonce,
 		Seq:   r.Seq,
 
-		Value: r.Encode(),
+		Value: val,
 
 		Sig: r.Sig,
 	})
@@ -212,13 +405,23 @@ func (c *Conn) sendRoot(r *registry.Root) {
 // send last Root to peer
 func (c *Conn) sendLastRoot(pk cipher.PubKey) {
 
+	if c.n.isRelayed(pk) == true {
+		if root, _ := c.n.rl.get(pk); root != nil {
+			c.sendFeedMsg(pk, root)
+		}
+		return
+	}
+
 	var (
-		activeHead = c.n.c.ActiveHead(pk)
-		r, err     = c.n.c.LastRoot(pk, activeHead)
+		cc         = c.n.container(pk)
+		activeHead = cc.ActiveHead(pk)
+		r, err     = cc.LastRoot(pk, activeHead)
 	)
 
 	if err == nil {
-		c.sendRoot(r)
+		if r.IsPartial == false {
+			c.sendRoot(r) // a partial Root is never sent
+		}
 		return
 	}
 
@@ -241,9 +444,17 @@ func (c *Conn) Subscribe(feed cipher.PubKey) (err error) {
 		return
 	}
 
+	if c.n.isEncrypted(feed) == true && c.canSeal() == false {
+		return ErrEncryptionRequired
+	}
+
+	if err = c.n.roomForFeed(c, feed); err != nil {
+		return
+	}
+
 	var reply msg.Msg
 
-	if reply, err = c.sendRequest(&msg.Sub{Feed: feed}); err != nil {
+	if reply, err = c.sendRequest(c.subRequest(feed)); err != nil {
 		return
 	}
 
@@ -253,7 +464,7 @@ func (c *Conn) Subscribe(feed cipher.PubKey) (err error) {
 	// success
 
 	case *msg.Err:
-		err = errors.New(x.Err)
+		err = remoteError(x.Err)
 
 	default:
 		err = fmt.Errorf("invalid response type %T", reply)
@@ -265,6 +476,13 @@ func (c *Conn) Subscribe(feed cipher.PubKey) (err error) {
 	}
 
 	c.n.fs.addConnFeed(c, feed)
+
+	if c.incoming == false {
+		c.n.addKnownFeed(c, feed)
+	}
+
+	c.scheduleRenewal(feed)
+	c.grantCredit(feed)
 	c.sendLastRoot(feed)
 	return
 }
@@ -278,7 +496,16 @@ func (c *Conn) unsubscribe(pk cipher.PubKey) {
 
 // Unsubscribe from given feed of remote peer
 func (c *Conn) Unsubscribe(feed cipher.PubKey) {
+	c.stopRenewal(feed)
+	c.delLease(feed)
 	c.n.fs.delConnFeed(c, feed)
+	c.setRemoteFilter(feed, nil)
+
+	if c.incoming == false {
+		c.n.delKnownFeed(c, feed)
+	}
+
+	c.delCredit(feed)
 	c.unsubscribe(feed) // notify peer
 	return
 }
@@ -303,17 +530,22 @@ func (c *Conn) Preview(
 ) {
 
 	var reply msg.Msg
-	if reply, err = c.sendRequest(&msg.RqPreview{Feed: feed}); err != nil {
+	reply, err = c.sendFeedRequest(feed, &msg.RqPreview{Feed: feed}, nil)
+
+	if err != nil {
 		return
 	}
 
-	var r *registry.Root
+	var (
+		cc = c.n.container(feed)
+		r  *registry.Root
+	)
 
 	switch x := reply.(type) {
 	case *msg.Err:
-		return errors.New("error: " + x.Err)
+		return remoteError(x.Err)
 	case *msg.Root:
-		if r, err = c.n.c.PreviewRoot(x.Feed, x.Sig, x.Value); err != nil {
+		if r, err = cc.PreviewRoot(x.Feed, x.Sig, x.Value); err != nil {
 			return
 		}
 	default:
@@ -321,7 +553,7 @@ func (c *Conn) Preview(
 	}
 
 	var p *skyobject.Preview
-	if p, err = c.n.c.Preview(r, c.getter()); err != nil {
+	if p, err = cc.Preview(r, c.getter(feed)); err != nil {
 		return
 	}
 
@@ -332,27 +564,88 @@ func (c *Conn) Preview(
 	return
 }
 
+// RemoteSchemas requests schemas with given references
+// of Registry with given reference from remote peer. It's
+// useful for very large registries, when only a couple of
+// types is required. Registered types used by a received
+// Schema (types of fields and elements of references) are
+// not included, and a received Schema keeps them by name
+// (see registry.DecodeSchema). The request has timeout
+// configured by Config
+func (c *Conn) RemoteSchemas(
+	rr registry.RegistryRef, //      : the Registry
+	refs ...registry.SchemaRef, //   : the schemas
+) (
+	ss []registry.Schema, //         : received schemas
+	err error, //                    : an error
+) {
+
+	var rq = &msg.RqSchemas{
+		Registry: cipher.SHA256(rr),
+		Schemas:  make([]cipher.SHA256, 0, len(refs)),
+	}
+
+	for _, sr := range refs {
+		rq.Schemas = append(rq.Schemas, cipher.SHA256(sr))
+	}
+
+	var reply msg.Msg
+	if reply, err = c.sendRequest(rq); err != nil {
+		return
+	}
+
+	switch x := reply.(type) {
+	case *msg.Err:
+		return nil, remoteError(x.Err)
+	case *msg.Schemas:
+		if len(x.Schemas) != len(refs) {
+			return nil, errors.New("wrong number of schemas received")
+		}
+		ss = make([]registry.Schema, 0, len(refs))
+		for i, es := range x.Schemas {
+			var s registry.Schema
+			if s, err = registry.DecodeSchema(es); err != nil {
+				return nil, err
+			}
+			if s.Reference() != refs[i] {
+				return nil, errors.New(
+					"wrong schema received (different reference)")
+			}
+			ss = append(ss, s)
+		}
+	default:
+		return nil, fmt.Errorf("invalid msg type received: %T", reply)
+	}
+
+	return
+}
+
 // implements skyobject.Getter
 // wrapping the Conn
 type cget struct {
-	c *Conn
+	c    *Conn
+	feed cipher.PubKey
 }
 
 func (c *cget) Get(key cipher.SHA256) (val []byte, err error) {
 
 	var reply msg.Msg
-	if reply, err = c.c.sendRequest(&msg.RqObject{Key: key}); err != nil {
+	reply, err = c.c.sendFeedRequest(c.feed, &msg.RqObject{Key: key}, nil)
+
+	if err != nil {
 		return
 	}
 
 	switch x := reply.(type) {
 	case *msg.Object:
-		if cipher.SumSHA256(x.Value) != key {
+		if x.Key != key || cipher.SumSHA256(x.Value) != key {
 			return nil, errors.New("wrong object received (different hash)")
 		}
 		val = x.Value
+	case *msg.NotFound:
+		return nil, data.ErrNotFound
 	case *msg.Err:
-		return nil, errors.New("error: " + x.Err)
+		return nil, remoteError(x.Err)
 	default:
 		return nil, fmt.Errorf("invalid msg type received: %T", reply)
 	}
@@ -360,29 +653,56 @@ func (c *cget) Get(key cipher.SHA256) (val []byte, err error) {
 	return
 }
 
-func (c *Conn) getter() (cg skyobject.Getter) {
-	return &cget{c}
+func (c *Conn) getter(feed cipher.PubKey) (cg skyobject.Getter) {
+	return &cget{c, feed}
 }
 
 //
 // terminate
 //
 
+// closed connections are kept by transports
+// until replaced by a new one
+func (c *Conn) isClosed() bool {
+	select {
+	case <-c.closeq:
+		return true
+	default:
+	}
+	return false
+}
+
 // close and release
 func (c *Conn) close(reason error) error {
 	c.closeo.Do(func() {
 		c.n.delConnection(c)
 		close(c.closeq)      // close the channel
+		c.stopLeases()       // stop timers
+		c.waitSending()      // stop writing
 		c.Connection.Close() // close
 		c.await.Wait()       // wait for goroutines
 
 		c.n.onDisconenct(c, reason) // callback
+
+		if c.incoming == false {
+			c.mx.Lock()
+			var manual = c.manual
+			c.mx.Unlock()
+
+			c.n.lostConn(c, reason, manual) // reconnect (see reconnect.go)
+		}
 	})
 	return reason
 }
 
-// Close the Conn
+// Close the Conn. The Node doesn't reconnect to
+// peer of closed outgoing connection and removes
+// it from known peers (see Config.Dialer)
 func (c *Conn) Close() (err error) {
+	c.mx.Lock()
+	c.manual = true
+	c.mx.Unlock()
+
 	return c.close(nil)
 }
 
@@ -391,34 +711,85 @@ func (c *Conn) nextSeq() uint32 {
 }
 
 func (c *Conn) encodeMsg(seq, rseq uint32, m msg.Msg) (raw []byte) {
+	return c.encodeTracedMsg(seq, rseq, c.takeTrace(seq, rseq), m)
+}
+
+// encode message with given trace ID (see trace.go)
+func (c *Conn) encodeTracedMsg(
+	seq uint32, //    : seq of the message
+	rseq uint32, //   : seq of replied request or zero
+	trace TraceID, // : trace ID or zero
+	m msg.Msg, //     : the message
+) (
+	raw []byte, //    : encoded message
+) {
 
-	var em = m.Encode()
+	// reply for encrypted request
+	if rseq != 0 && c.takeSealedRequest(rseq) == true {
+		m = c.seal(m)
+	}
+
+	var (
+		em    = c.codec().Encode(m)
+		flags uint8
+	)
+
+	if c.cmp != nil {
+		em = c.cmp.compress(em)
+		flags |= msg.FrameCompressed
+	}
 
-	raw = make([]byte, 8, 8+len(em))
+	raw = make([]byte, 8, 8+TraceIDSize+len(em))
 
 	binary.LittleEndian.PutUint32(raw, seq)
 	binary.LittleEndian.PutUint32(raw[4:], rseq)
 
+	raw = c.appendTrace(raw, trace)
 	raw = append(raw, em...)
 
+	if c.HasFeature(msg.FeatureFrames) == true {
+		raw = (&msg.Frame{Type: m.Type(), Flags: flags, Body: raw}).Encode()
+	}
+
+	c.statSent(m.Type(), len(raw))
 	return
 
 }
 
 func (c *Conn) sendMsg(seq, rseq uint32, m msg.Msg) {
 
-	c.n.Debugf(MsgSendPin, "[%s] send %d %T", c.String(), rseq, m)
+	if m = c.hookSend(m); m == nil {
+		return // dropped
+	}
 
-	c.sendRaw(c.encodeMsg(seq, rseq, m))
+	c.send(seq, rseq, m)
 }
 
-func (c *Conn) sendRaw(raw []byte) {
+// send message that already hooked
+func (c *Conn) send(seq, rseq uint32, m msg.Msg) {
 
-	select {
-	case c.sendq <- raw:
-	case <-c.closeq:
+	// too big objects sent by chunks (see data_chunk.go)
+	if obj, ok := m.(*msg.Object); ok == true && rseq != 0 &&
+		len(obj.Value) > maxChunkSize &&
+		c.HasFeature(msg.FeatureDataChunk) == true {
+
+		c.sendChunks(rseq, obj.Key, obj.Value)
+		return
 	}
 
+	var trace = c.takeTrace(seq, rseq)
+
+	c.n.Debugf(MsgSendPin, "[%s] send %d %T%s", c.String(), rseq, m,
+		traceSuffix(trace))
+
+	c.enqueue(c.sendClass(m), c.encodeTracedMsg(seq, rseq, trace, m))
+}
+
+// send requested object; small objects sent as control
+// messages, but big objects are bulk data (see
+// Config.SmallObjectSize and SendWeights)
+func (c *Conn) sendObject(rseq uint32, key cipher.SHA256, val []byte) {
+	c.sendMsg(c.nextSeq(), rseq, &msg.Object{Key: key, Value: val})
 }
 
 func (c *Conn) fatality(args ...interface{}) {
@@ -426,6 +797,15 @@ func (c *Conn) fatality(args ...interface{}) {
 	var err = errors.New(fmt.Sprint(args...))
 
 	c.n.Print("[ERR] ", err)
+	c.addScore(scoreProtocolError)
+	c.close(err)
+}
+
+// like the fatality, but the peer has sent invalid
+// data, that is worse than a protocol error
+func (c *Conn) invalidData(err error) {
+	c.n.Print("[ERR] ", err)
+	c.addScore(scoreInvalidData)
 	c.close(err)
 }
 
@@ -433,7 +813,7 @@ func (c *Conn) receiving() {
 
 	c.n.Debugf(ConnPin, "[%s] receiving", c.String())
 
-	defer c.Close()      //
+	defer c.close(nil)   //
 	defer c.await.Done() //
 
 	var (
@@ -441,11 +821,17 @@ func (c *Conn) receiving() {
 		closeq   = c.closeq
 
 		seq, rseq uint32
+		trace     TraceID
 		m         msg.Msg
 		err       error
 
-		raw []byte
-		ok  bool
+		raw  []byte
+		ok   bool
+		size int      // size of received message
+		mt   msg.Type // type of received message
+
+		fr     msg.Frame // received frame
+		framed bool      // is the FeatureFrames used
 	)
 
 	for {
@@ -460,6 +846,26 @@ func (c *Conn) receiving() {
 
 			// [ 4 seq ][ 4 rseq ][ 1 msg type ]
 
+			size = len(raw)
+
+			if c.throttle(false, size) == false {
+				return // closed
+			}
+
+			// [ frame header ][ 4 seq ][ 4 rseq ][ 1 msg type ]
+
+			if fr, framed, err = c.unframe(raw); err != nil {
+				c.fatality("invalid frame received: ", err)
+				return
+			}
+
+			if framed == true && fr.IsKeepAlive() == true {
+				c.touch() // keep-alive frame
+				continue
+			}
+
+			raw = fr.Body
+
 			if len(raw) < 9 {
 				c.fatality("invalid messege received: samll size")
 				return
@@ -473,12 +879,90 @@ func (c *Conn) receiving() {
 			rseq = binary.LittleEndian.Uint32(raw)
 			raw = raw[4:]
 
-			if m, err = msg.Decode(raw); err != nil {
+			// [ 8 trace ID ] if the peer can trace
+
+			if trace, raw, err = c.untrace(raw); err != nil {
+				c.fatality(err)
+				return
+			}
+
+			if fr.Flags&msg.FrameCompressed != 0 {
+				if raw, err = c.decompress(raw); err != nil {
+					c.fatality("can't decompress received messege: ", err)
+					return
+				}
+			}
+
+			if m, err = c.decode(raw); err != nil {
+
+				// application-defined messages of unknown types
+				if ite, ok := err.(msg.InvalidTypeError); ok == true &&
+					ite.Type().IsUser() == true {
+
+					c.n.Debugf(MsgReceivePin, "[%s] ignore unknown %s",
+						c.String(), ite.Type().String())
+					continue
+				}
+
 				c.fatality("can't decode received messege: ", err)
 				return
 			}
 
-			c.n.Debugf(MsgReceivePin, "[%s] receive %T", c.String(), m)
+			mt = m.Type()
+			c.statRecv(mt, size)
+
+			if framed == true && fr.Type != mt {
+				c.fatality("frame type mismatch: ", fr.Type.String(), ", ",
+					mt.String())
+				return
+			}
+
+			if sm, ok := m.(*msg.Sealed); ok == true {
+
+				if m, err = c.open(sm); err != nil {
+					c.fatality("can't decrypt received messege: ", err)
+					return
+				}
+
+				if rseq == 0 && isPush(m) == false {
+					c.addSealedRequest(seq) // encrypt reply
+				}
+
+			}
+
+			if trace != 0 && rseq == 0 {
+				c.addReplyTrace(seq, trace) // echo
+			}
+
+			c.n.Debugf(MsgReceivePin, "[%s] receive %T%s", c.String(), m,
+				traceSuffix(trace))
+
+			if isServed(m) == true {
+				c.served.add(size)
+			}
+
+			c.touch() // keep-alive
+
+			if dc, ok := m.(*msg.DataChunk); ok == true {
+
+				var obj *msg.Object
+				if obj, err = c.reassemble(rseq, dc); err != nil {
+					c.fatality("invalid chunk received: ", err)
+					return
+				}
+
+				if obj == nil {
+					continue // not yet
+				}
+
+				m = obj
+			}
+
+			if m = c.hookRecv(m); m == nil {
+				c.takeSealedRequest(seq) // not going to reply
+				c.takeTrace(0, seq)      // not going to echo
+				continue                 // dropped
+			}
 
 			// the messege can be a response for a request
 			if rq, ok := c.isResponse(rseq); ok == true {
@@ -487,6 +971,7 @@ func (c *Conn) receiving() {
 			}
 
 			if err = c.handle(seq, m); err != nil {
+				c.statError(mt)
 				c.fatality("error handling messege: ", err)
 				return
 			}
@@ -520,6 +1005,8 @@ func (c *Conn) delRequest(seq uint32) {
 	defer c.mx.Unlock()
 
 	delete(c.reqs, seq)
+	delete(c.chunks, seq) // partially received object (see data_chunk.go)
+	delete(c.rqTraces, seq)
 }
 
 func (c *Conn) responseTimeout() (rt time.Duration) {
@@ -532,15 +1019,33 @@ func (c *Conn) responseTimeout() (rt time.Duration) {
 }
 
 func (c *Conn) sendRequest(m msg.Msg) (reply msg.Msg, err error) {
+	return c.sendRequestCancel(m, c.responseTimeout(), nil)
+}
+
+// send request with given timeout, zero is no timeout;
+// the request can be canceled closing given channel,
+// that can be nil (see cancel.go)
+func (c *Conn) sendRequestCancel(
+	m msg.Msg, //              : the request
+	rt time.Duration, //       : timeout
+	cancel <-chan struct{}, // : cancel the request
+) (
+	reply msg.Msg, //          : reply
+	err error, //              : an error
+) {
 
 	c.n.Debugf(MsgSendPin, "[%s] sendRequest %T", c.String(), m)
 
+	if m = c.hookSend(m); m == nil {
+		return nil, ErrDropped
+	}
+
 	var (
 		tr *time.Timer
 		tc <-chan time.Time
 	)
 
-	if rt := c.responseTimeout(); rt > 0 {
+	if rt > 0 {
 		tr = time.NewTimer(rt)
 		tc = tr.C
 
@@ -548,22 +1053,37 @@ func (c *Conn) sendRequest(m msg.Msg) (reply msg.Msg, err error) {
 	}
 
 	var (
-		rq  = make(chan msg.Msg, 1)
-		seq = c.nextSeq()
+		rq    = make(chan msg.Msg, 1)
+		seq   = c.nextSeq()
+		tp    = time.Now()
+		trace = c.addRequestTrace(seq)
 	)
 
 	c.addRequest(seq, rq)
 	defer c.delRequest(seq)
 
-	c.sendMsg(seq, 0, m)
+	c.send(seq, 0, m)
 
 	select {
 	case reply = <-rq:
+		c.statLatency(m.Type(), time.Now().Sub(tp))
+		if er, ok := reply.(*msg.Err); ok == true {
+			c.statFailure(m.Type(), trace)
+			c.n.Debugf(MsgReceivePin, "[%s] %T failed%s: %s", c.String(), m,
+				traceSuffix(trace), er.Err)
+		}
 		return
 
 	case <-tc:
+		c.statFailure(m.Type(), trace)
+		c.n.Debugf(MsgSendPin, "[%s] %T timed out%s", c.String(), m,
+			traceSuffix(trace))
 		return nil, ErrTimeout
 
+	case <-cancel:
+		c.sendCancel(seq)
+		return nil, ErrCanceled
+
 	case <-c.closeq:
 		return nil, ErrClosed
 	}
@@ -583,6 +1103,12 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 
 	switch x := m.(type) {
 
+	// pings
+
+	case *msg.Ping: // <- Ping ()
+		c.sendMsg(c.nextSeq(), seq, &msg.Pong{})
+		return
+
 	// subscriptions
 
 	case *msg.Sub: // <- Sub (feed)
@@ -591,6 +1117,12 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	case *msg.Unsub: // <- Unsub (feed)
 		return c.handleUnsub(seq, x)
 
+	case *msg.SubLease: // <- SubLease (feed, lease)
+		return c.handleSubLease(seq, x)
+
+	case *msg.SubFilter: // <- SubFilter (feed, lease, schemas)
+		return c.handleSubFilter(seq, x)
+
 	// public server features
 
 	case *msg.RqList: // <- RqList ()
@@ -603,11 +1135,35 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	case *msg.Root: // <- Root (feed, nonce, seq, sig, val)
 		return c.handleRoot(x)
 
+	case *msg.RootDelta: // <- RootDelta (feed, nonce, seq, sig, val, objects)
+		return c.handleRootDelta(x)
+
+	case *msg.RootDone: // -> RootDone (feed, nonce, seq)
+		return c.handleRootDone(x)
+
+	case *msg.Credit: // -> Credit (feed, msgs, bytes)
+		return c.handleCredit(x)
+
+	case *msg.Announce: // <- Announce (feed, nonce, seq, hash)
+		return c.handleAnnounce(x)
+
+	case *msg.RootReject: // -> RootReject (feed, nonce, seq, reason, err)
+		return c.handleRootReject(x)
+
 	// objects
 
 	case *msg.RqObject: // <- RqO (key, prefetch)
 		c.await.Add(1)
-		go c.handleRqObject(seq, x)
+		go c.handleRqObject(seq, x, c.addCancelable(seq))
+		return
+
+	case *msg.RqSealedObject: // <- RqSealedObject (feed, key)
+		c.await.Add(1)
+		go c.handleRqSealedObject(seq, x, c.addCancelable(seq))
+		return
+
+	case *msg.Cancel: // <- Cancel (seq)
+		c.handleCancel(x)
 		return
 
 	// preview
@@ -615,6 +1171,44 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	case *msg.RqPreview: // -> RqPreview (feed)
 		return c.handleRqPreview(seq, x)
 
+	// schemas
+
+	case *msg.RqSchemas: // <- RqSchemas (registry, schemas)
+		return c.handleRqSchemas(seq, x)
+
+	case *msg.RqRegistries: // <- RqRegistries (registries)
+		return c.handleRqRegistries(seq, x)
+
+	// hole punching
+
+	case *msg.RqRendezvous: // <- RqRendezvous (peer, port)
+		c.await.Add(1)
+		go c.handleRqRendezvous(seq, x)
+		return
+
+	case *msg.RqPunch: // <- RqPunch (peer, address)
+		return c.handleRqPunch(seq, x)
+
+	// ownership proof
+
+	case *msg.RqChallenge: // <- RqChallenge (feed)
+		return c.handleRqChallenge(seq, x)
+
+	case *msg.Proof: // <- Proof (feed, sig)
+		return c.handleProof(seq, x)
+
+	// shared feeds
+
+	case *msg.WantFeeds: // <- WantFeeds (feeds)
+		return c.handleWantFeeds(x)
+
+	// have-lists
+
+	case *msg.RqHave: // <- RqHave (feed)
+		c.await.Add(1)
+		go c.handleRqHave(seq, x)
+		return
+
 	//
 	// delayed messeges (ignore them)
 	//
@@ -623,12 +1217,25 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	// ErrTimeout and noone waits them
 
 	case *msg.Object: // -> O (delayed)
+		c.handleDelayedObject(x)
 	case *msg.Err: // -> Err (delayed)
 	case *msg.Ok: // -> Ok (delayed)
 	case *msg.List: // -> List (delayed)
+	case *msg.Schemas: // -> Schemas (delayed)
+	case *msg.Challenge: // -> Challenge (delayed)
+	case *msg.Have: // -> Have (delayed)
+	case *msg.NotFound: // -> NotFound (delayed)
+	case *msg.Pong: // -> Pong (delayed)
+	case *msg.Rendezvous: // -> Rendezvous (delayed)
+	case *msg.Punch: // -> Punch (delayed)
 
 	default:
 
+		if m.Type().IsUser() == true {
+			c.handleUserMsg(m) // application-defined
+			return
+		}
+
 		return fmt.Errorf("invalid messege type %T", m)
 
 	}
@@ -649,14 +1256,44 @@ func (c *Conn) handleSub(seq uint32, sub *msg.Sub) (_ error) {
 		return errors.New("blank public key") // fatal (invalid request)
 	}
 
+	// a subscription without lease never expires
+	if c.n.config.RequireSubLease == true {
+		c.sendErr(seq, ErrLeaseRequired)
+		return
+	}
+
+	if c.subscribeRemote(seq, sub.Feed) == true {
+		c.setRemoteFilter(sub.Feed, nil) // full
+		c.delLease(sub.Feed)             // never expires
+	}
+	return
+}
+
+// subscribe remote peer to given feed and send
+// reply; it returns true if the peer subscribed
+func (c *Conn) subscribeRemote(seq uint32, feed cipher.PubKey) (ok bool) {
+
+	// never serve a local-only feed, even
+	// if the callback wants to share it
+	if c.n.lf.has(feed) == true {
+		c.sendErr(seq, ErrFeedNotServed)
+		return
+	}
+
+	// don't send messages of encrypted feed unencrypted
+	if c.n.isEncrypted(feed) == true && c.canSeal() == false {
+		c.sendErr(seq, ErrEncryptionRequired)
+		return
+	}
+
 	// check first
-	if c.n.fs.hasConnFeed(c, sub.Feed) == true {
+	if c.n.fs.hasConnFeed(c, feed) == true {
 		c.sendOk(seq) // already subscribed
-		return
+		return true
 	}
 
 	// callback
-	var reject = c.n.onSubscribeRemote(c, sub.Feed)
+	var reject = c.n.onSubscribeRemote(c, feed)
 
 	// reject subscription by callback
 	if reject != nil {
@@ -668,19 +1305,20 @@ func (c *Conn) handleSub(seq uint32, sub *msg.Sub) (_ error) {
 	// and anyway we can't subscribe to a feed we don't
 	// share
 
-	if c.n.fs.hasFeed(sub.Feed) == false {
-		c.sendErr(seq, errors.New("do not share the feed"))
+	if c.n.fs.hasFeed(feed) == false {
+		c.sendErr(seq, ErrFeedNotServed)
 		return
 	}
 
 	// ok
 
-	c.n.fs.addConnFeed(c, sub.Feed)
+	c.n.fs.addConnFeed(c, feed)
 	c.sendOk(seq)
+	c.grantCredit(feed)
 
-	c.sendLastRoot(sub.Feed) // and push last Root
+	c.sendLastRoot(feed) // and push last Root
 
-	return
+	return true
 }
 
 // unsubscribe (no reply)
@@ -693,7 +1331,11 @@ func (c *Conn) handleUnsub(seq uint32, unsub *msg.Unsub) (err error) {
 		return errors.New("invalid request Unsub blank feed") // fatal
 	}
 
+	c.stopRenewal(unsub.Feed)
+	c.delLease(unsub.Feed)
 	c.n.fs.delConnFeed(c, unsub.Feed) // delete
+	c.setRemoteFilter(unsub.Feed, nil)
+	c.delCredit(unsub.Feed)
 	return
 }
 
@@ -708,21 +1350,68 @@ func (c *Conn) handleRqList(seq uint32, rq *msg.RqList) (_ error) {
 	}
 
 	c.sendMsg(c.nextSeq(), seq, &msg.List{
-		Feeds: c.n.Feeds(),
+		Feeds: c.listFeeds(),
 	})
 
 	return
 }
 
+// feeds the peer can subscribe to, e.g. without
+// encrypted feeds if the peer can't decrypt them
+func (c *Conn) listFeeds() (feeds []cipher.PubKey) {
+
+	var all = c.n.Feeds()
+
+	if c.canSeal() == true {
+		return all
+	}
+
+	// the list of the Node is clear-on-write, thus
+	// it can't be filtered in place
+
+	for _, pk := range all {
+		if c.n.isEncrypted(pk) == false {
+			feeds = append(feeds, pk)
+		}
+	}
+
+	return
+}
+
 // got Root (preview Root objects are handled by request-responnse, not here)
-func (c *Conn) handleRoot(root *msg.Root) (_ error) {
+func (c *Conn) handleRoot(root *msg.Root) (err error) {
 
 	c.n.Debugf(MsgReceivePin, "[%s] handleRoot %s/%d/%d",
 		c.String(), root.Feed.Hex()[:7], root.Nonce, root.Seq)
 
+	defer c.usedCredit(root.Feed, 0) // after the Root is handled
+
+	if c.n.isRelayed(root.Feed) == true {
+		c.relayRoot(root, root)
+		return
+	}
+
+	if err = c.n.openRoot(root, nil); err != nil {
+		return // fatal
+	}
+
+	c.receivedRoot(root, nil)
+	return
+}
+
+// received Root with new objects (see RootDelta) or without
+func (c *Conn) receivedRoot(root *msg.Root, delta [][]byte) {
+
+	if c.n.lf.has(root.Feed) == true {
+		return // local-only feed
+	}
+
 	// check seq first (avoid verify-signature for old unwanted Root objects)
 
-	var last, err = c.n.c.LastRootSeq(root.Feed, root.Nonce) // last is full
+	var (
+		cc        = c.n.container(root.Feed)
+		last, err = cc.LastRootSeq(root.Feed, root.Nonce) // last is full
+	)
 
 	switch err {
 	case data.ErrNoSuchFeed:
@@ -735,39 +1424,115 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 
 	default: // nil (found)
 
-		if last >= root.Seq {
+		if last > root.Seq {
+			c.sendRootReject(root.Feed, root.Nonce, root.Seq, msg.RejectOldSeq,
+				fmt.Errorf("have Root with seq %d", last))
 			return // we have newer one
 		}
 
+		if last == root.Seq {
+			return // we have this one
+		}
+
 	}
 
 	var r *registry.Root
 
-	r, err = c.n.c.ReceivedRoot(root.Feed, root.Sig, root.Value)
+	// the same Root received from many peers is verified once,
+	// and the duplicate only tells filler about one more peer
+	// that has the Root; objects sent with it are dropped
 
-	if err != nil {
-		c.n.Printf("[ERR] [%s] received Root error: %s", c.String(), err)
-		return // keep connection ?
+	if r = c.n.rs.get(root); r != nil {
+
+		c.n.Debugf(MsgReceivePin, "[%s] duplicate Root %s", c.String(),
+			r.Short())
+
+		delta = nil
+
+	} else {
+
+		r, err = cc.ReceivedRoot(root.Feed, root.Sig, root.Value)
+
+		if err != nil {
+			c.n.Printf("[ERR] [%s] received Root error: %s", c.String(), err)
+			c.addScore(scoreInvalidData)
+			c.sendRootReject(root.Feed, root.Nonce, root.Seq,
+				msg.RejectBadSignature, err)
+			return // keep connection ?
+		}
+
+		// do nothing, because the Node already have this
+		// Root, filled fully or partially (see SetFilter)
+		if r.IsFull == true || r.IsPartial == true {
+			return
+		}
+
+		c.n.rs.add(r)
+	}
+
+	// another member of the Cluster fills the feed
+	if c.n.holdsLease(r.Pub) == false {
+		return
 	}
 
-	// do nothing, because the Node already have this Root
-	if r.IsFull == true {
+	// don't fill Root objects of headers-only feed
+	if c.n.hf.has(r.Pub) == true {
+		c.receivedHeader(r)
 		return
 	}
 
 	// fill the Root only if the node and the connection
 	// subscribed to feed of the Root
-	c.n.fs.receivedRoot(c, r)
-	return
+	c.n.fs.receivedRoot(connRoot{c: c, r: r, delta: objectsByKey(delta)})
+}
+
+// an Object received after timeout of its request
+// can be wanted by a filler yet, and the Key is
+// used to deliver the object, that is not lost
+func (c *Conn) handleDelayedObject(obj *msg.Object) {
+
+	if cipher.SumSHA256(obj.Value) != obj.Key {
+		return // ignore
+	}
+
+	for _, cc := range c.n.containers() {
+		if _, err := cc.SetWanted(obj.Key, obj.Value); err != nil {
+			c.n.Fatal("DB failure:", err)
+		}
+	}
+
 }
 
 // async
-func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
+func (c *Conn) handleRqObject(
+	seq uint32, //               : seq of the request
+	rq *msg.RqObject, //         : the request
+	canceled <-chan struct{}, // : see cancel.go
+) {
 	defer c.await.Done()
+	defer c.delCancelable(seq)
 
 	c.n.Debugf(MsgReceivePin, "[%s] handleRqObject %s", c.String(),
 		rq.Key.Hex()[:7])
 
+	// objects of encrypted feeds are sent
+	// sealed only (see RqSealedObject)
+	if c.n.isSealedObject(rq.Key) == true {
+		c.sendErr(seq, ErrEncryptionRequired)
+		return
+	}
+
+	// objects of partial Root objects are
+	// never sent (see SetFilter)
+	if c.n.isPartialObject(rq.Key) == true {
+		if c.HasFeature(msg.FeatureNotFound) == true {
+			c.sendNotFound(seq, rq.Key)
+			return
+		}
+		c.sendErr(seq, data.ErrNotFound)
+		return
+	}
+
 	var (
 		gc = make(chan skyobject.Object, 1)
 
@@ -782,20 +1547,40 @@ func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 	// TODO (kostyarin): get the object or subscribe for the object
 	//                   only if it is wanted (to think)
 
-	if err := c.n.c.Want(rq.Key, gc, 0); err != nil {
-		c.n.Fatal("DB failure: ", err)
+	// the object can be stored in a Container of a Tenant
+	// (see Tenant.DataDir); the Want never blocks and
+	// the first found object is sent
+
+	var (
+		cs     = c.n.containers()
+		wanted bool
+	)
+
+	for _, cc := range cs {
+		if err := cc.Want(rq.Key, gc, 0); err != nil {
+			c.n.Fatal("DB failure: ", err)
+		}
+		defer cc.Unwant(rq.Key, gc) // to be memory safe
+		wanted = wanted || cc.IsWanted(rq.Key)
 	}
-	defer c.n.c.Unwant(rq.Key, gc) // to be memory safe
 
 	select {
 	case obj := <-gc:
 		// got
-		c.sendMsg(c.nextSeq(), seq, &msg.Object{Value: obj.Val})
+		c.sendObject(seq, rq.Key, obj.Val)
 		return
 	default:
 		// wait
 	}
 
+	// wait only if the object is being received by a filler
+	if wanted == false {
+		if c.HasFeature(msg.FeatureNotFound) == true {
+			c.sendNotFound(seq, rq.Key)
+			return
+		}
+	}
+
 	if rt := c.responseTimeout(); rt > 0 {
 		tm = time.NewTimer(rt)
 		tc = tm.C
@@ -805,9 +1590,15 @@ func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 
 	select {
 	case obj := <-gc:
-		c.sendMsg(c.nextSeq(), seq, &msg.Object{Value: obj.Val})
+		c.sendObject(seq, rq.Key, obj.Val)
 	case <-tc:
+		if c.HasFeature(msg.FeatureNotFound) == true {
+			c.sendNotFound(seq, rq.Key)
+			break
+		}
 		c.sendMsg(c.nextSeq(), seq, &msg.Err{}) // timeout
+	case <-canceled:
+		// no reply
 	case <-c.closeq:
 		// closed
 	}
@@ -820,22 +1611,81 @@ func (c *Conn) handleRqPreview(seq uint32, rqp *msg.RqPreview) (_ error) {
 	c.n.Debugf(MsgReceivePin, "[%s] handleRqPreview %s", c.String(),
 		rqp.Feed.Hex()[:7])
 
-	var r, err = c.n.c.LastRoot(rqp.Feed, c.n.c.ActiveHead(rqp.Feed))
+	if c.n.lf.has(rqp.Feed) == true {
+		c.sendErr(seq, ErrFeedNotServed)
+		return
+	}
+
+	if c.n.isEncrypted(rqp.Feed) == true && c.canSeal() == false {
+		c.sendErr(seq, ErrEncryptionRequired)
+		return
+	}
+
+	if c.n.isRelayed(rqp.Feed) == true {
+		if root, _ := c.n.rl.get(rqp.Feed); root != nil {
+			c.sendMsg(c.nextSeq(), seq, root) // sealed
+			return
+		}
+		c.sendErr(seq, data.ErrNotFound)
+		return
+	}
+
+	var (
+		cc     = c.n.container(rqp.Feed)
+		r, err = cc.LastRoot(rqp.Feed, cc.ActiveHead(rqp.Feed))
+	)
+
+	if err == nil && r.IsPartial == true {
+		err = data.ErrNotFound // never served (see SetFilter)
+	}
 
 	if err != nil {
 		c.sendMsg(c.nextSeq(), seq, &msg.Err{Err: err.Error()})
 		return
 	}
 
+	var val, _ = c.n.sealValue(r.Pub, r.Encode()) // not relayed
+
 	c.sendMsg(c.nextSeq(), seq, &msg.Root{
 		Feed:  r.Pub,
 		Nonce: r.Nonce,
 		Seq:   r.Seq,
 
-		Value: r.Encode(),
+		Value: val,
 
 		Sig: r.Sig,
 	})
 
 	return
 }
+
+func (c *Conn) handleRqSchemas(seq uint32, rqs *msg.RqSchemas) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqSchemas %s (%d)", c.String(),
+		rqs.Registry.Hex()[:7], len(rqs.Schemas))
+
+	var reg, err = c.n.registry(registry.RegistryRef(rqs.Registry))
+
+	if err != nil {
+		c.sendMsg(c.nextSeq(), seq, &msg.Err{Err: err.Error()})
+		return
+	}
+
+	var reply = &msg.Schemas{
+		Schemas: make([][]byte, 0, len(rqs.Schemas)),
+	}
+
+	for _, sr := range rqs.Schemas {
+
+		var s registry.Schema
+		if s, err = reg.SchemaByReference(registry.SchemaRef(sr)); err != nil {
+			c.sendMsg(c.nextSeq(), seq, &msg.Err{Err: err.Error()})
+			return
+		}
+
+		reply.Schemas = append(reply.Schemas, s.Encode())
+	}
+
+	c.sendMsg(c.nextSeq(), seq, reply)
+	return
+}
@@ -0,0 +1,367 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestConn_Subscribe(t *testing.T) {
+
+	var sn = getTestNode("server")
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	err = c.Subscribe(pk)
+	assertTrue(t, errors.Is(err, ErrFeedNotServed), "not ErrFeedNotServed")
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, c.Subscribe(pk))
+
+	assertTrue(t, len(c.Feeds()) == 1, "wrong number of feeds")
+
+	// not public
+
+	_, err = c.RemoteFeeds()
+	assertTrue(t, errors.Is(err, ErrNotPublic), "not ErrNotPublic")
+
+}
+
+func TestConn_RemoteFeeds(t *testing.T) {
+
+	var (
+		plain, _     = cipher.GenerateKeyPair()
+		encrypted, _ = cipher.GenerateKeyPair()
+
+		sconf = getTestConfig("server")
+	)
+
+	sconf.Public = true
+	sconf.EncryptFeeds = Feeds{encrypted}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	assertNil(t, sn.Share(plain))
+	assertNil(t, sn.Share(encrypted))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var feeds []cipher.PubKey
+	if feeds, err = c.RemoteFeeds(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, len(feeds) == 2, "wrong number of feeds")
+
+	// a peer that can't decrypt messages
+
+	var nc = &Conn{n: sn}
+
+	feeds = nc.listFeeds()
+
+	assertTrue(t, len(feeds) == 1 && feeds[0] == plain, "wrong list")
+	assertTrue(t, len(sn.Feeds()) == 2, "list of the Node changed")
+
+}
+
+func TestConn_sendObject(t *testing.T) {
+
+	var sconf = getTestConfig("server")
+	sconf.SmallObjectSize = 16 // the User is small, the Post is not
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var up, _ = sn.Container().Unpack(sk, getTestRegistry())
+
+	var (
+		body = strings.Repeat("big ", 1024)
+		r    = &registry.Root{Pub: pk, Nonce: 1}
+	)
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}),
+		dynamicByValue(t, up, "test.Post", Post{"Head", body, 0}),
+	)
+
+	assertNil(t, sn.Container().Save(up, r))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Preview(pk, func(pp registry.Pack, r *registry.Root) bool {
+
+		var (
+			usr  User
+			post Post
+		)
+
+		assertNil(t, r.Refs[0].Value(pp, &usr))
+		assertTrue(t, usr.Name == "Alice", "wrong user name")
+
+		assertNil(t, r.Refs[1].Value(pp, &post))
+		assertTrue(t, post.Body == body, "wrong body of the post")
+
+		return false
+	})
+
+	assertNil(t, err)
+
+}
+
+func TestConn_handleDelayedObject(t *testing.T) {
+
+	var sn = getTestNode("server")
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		val = []byte("delayed")
+		key = cipher.SumSHA256(val)
+		gc  = make(chan skyobject.Object, 1)
+	)
+
+	assertNil(t, cn.Container().Want(key, gc, 1))
+	defer cn.Container().Unwant(key, gc)
+
+	// wrong key
+	c.handleDelayedObject(&msg.Object{Key: cipher.SHA256{1}, Value: val})
+
+	select {
+	case <-gc:
+		t.Fatal("object with wrong key delivered")
+	default:
+	}
+
+	c.handleDelayedObject(&msg.Object{Key: key, Value: val})
+
+	select {
+	case obj := <-gc:
+		assertTrue(t, string(obj.Val) == "delayed", "wrong object")
+	default:
+		t.Error("wanted object is not delivered")
+	}
+
+}
+
+func TestConn_decode(t *testing.T) {
+
+	var (
+		c    = &Conn{n: &Node{config: NewConfig()}}
+		list = &msg.List{Feeds: make([]cipher.PubKey, 3)}
+		raw  = list.Encode()
+	)
+
+	if m, err := c.decode(raw); err != nil {
+		t.Fatal(err)
+	} else if l, ok := m.(*msg.List); ok == false || len(l.Feeds) != 3 {
+		t.Fatalf("wrong message decoded: %#v", m)
+	}
+
+	// length
+	c.n.config.MaxMessageLength = 2
+	if _, err := c.decode(raw); err != msg.ErrLengthLimit {
+		t.Errorf("wrong error: want %v, got %v", msg.ErrLengthLimit, err)
+	}
+	c.n.config.MaxMessageLength = 0
+
+	// size
+	c.n.config.MaxMessageSize = len(raw) - 1
+	if _, err := c.decode(raw); err != msg.ErrSizeLimit {
+		t.Errorf("wrong error: want %v, got %v", msg.ErrSizeLimit, err)
+	}
+	c.n.config.MaxMessageSize = 0
+
+	// hostile length prefix: 4G elements in a short message
+	var hostile = append([]byte{byte(msg.ListType)}, 0xff, 0xff, 0xff, 0xff)
+	if _, err := c.decode(hostile); err != msg.ErrMalformed {
+		t.Errorf("wrong error: want %v, got %v", msg.ErrMalformed, err)
+	}
+
+	// truncated
+	if _, err := c.decode(raw[:len(raw)-1]); err != msg.ErrMalformed {
+		t.Errorf("wrong error: want %v, got %v", msg.ErrMalformed, err)
+	}
+
+}
+
+func TestConn_unframe(t *testing.T) {
+
+	var c = &Conn{
+		n:        &Node{config: NewConfig()},
+		features: msg.FeatureFrames,
+	}
+
+	var raw = c.encodeMsg(1, 2, &msg.Ok{})
+
+	var fr, framed, err = c.unframe(raw)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if framed == false || fr.Type != msg.OkType || fr.Flags != 0 {
+		t.Errorf("wrong frame: %t, %s, %b", framed, fr.Type, fr.Flags)
+	}
+
+	if len(fr.Body) != 9 || fr.Body[8] != byte(msg.OkType) {
+		t.Errorf("wrong body: %v", fr.Body)
+	}
+
+	// corrupted
+	raw[len(raw)-1] ^= 0xff
+	if _, _, err = c.unframe(raw); err != msg.ErrFrameChecksum {
+		t.Errorf("wrong error: want %v, got %v", msg.ErrFrameChecksum, err)
+	}
+
+	// truncated
+	if _, _, err = c.unframe(raw[:len(raw)-1]); err != msg.ErrFrameLength {
+		t.Errorf("wrong error: want %v, got %v", msg.ErrFrameLength, err)
+	}
+
+	// stream of frames
+	var (
+		ka  = (&msg.Frame{Type: msg.KeepAliveType}).Encode()
+		ok  = c.encodeMsg(3, 0, &msg.Ok{})
+		buf = bytes.NewReader(append(append(ka, ok...), ok[:5]...))
+		fr2 = msg.NewFrameReader(buf, 0)
+	)
+
+	if fr, err = fr2.ReadFrame(); err != nil {
+		t.Fatal(err)
+	} else if fr.IsKeepAlive() == false {
+		t.Error("not keep-alive")
+	}
+
+	if fr, err = fr2.ReadFrame(); err != nil {
+		t.Fatal(err)
+	} else if fr.Type != msg.OkType {
+		t.Error("wrong type:", fr.Type)
+	}
+
+	if _, err = fr2.ReadFrame(); err != io.ErrUnexpectedEOF {
+		t.Errorf("wrong error: want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+
+	// without the feature
+	c.features = 0
+
+	if fr, framed, err = c.unframe(ok); err != nil || framed == true ||
+		len(fr.Body) != len(ok) {
+
+		t.Error("framed without the feature")
+	}
+
+}
+
+// a codec that breaks the skycoin encoding
+type reverseCodec struct{}
+
+func (reverseCodec) reverse(p []byte) (r []byte) {
+	r = make([]byte, len(p))
+	r[0] = p[0] // type
+	for i, j := 1, len(p)-1; j > 0; i, j = i+1, j-1 {
+		r[i] = p[j]
+	}
+	return
+}
+
+func (rc reverseCodec) Encode(m msg.Msg) []byte {
+	return rc.reverse(m.Encode())
+}
+
+func (rc reverseCodec) Decode(p []byte, l *msg.Limits) (msg.Msg, error) {
+	if len(p) == 0 {
+		return nil, msg.ErrEmptyMessage
+	}
+	return msg.DefaultCodec.Decode(rc.reverse(p), l)
+}
+
+func TestConn_codec(t *testing.T) {
+
+	var sconf = getTestConfig("server")
+	sconf.Codec = reverseCodec{}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cconf = getTestConfigNotListen("client")
+	cconf.Codec = reverseCodec{}
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	// default codec
+	var dn = getTestNodeNotListen("default")
+	defer dn.Close()
+
+	if _, err = dn.TCP().Connect(sn.TCP().Address()); err == nil {
+		t.Error("connected using another codec")
+	}
+
+}
@@ -0,0 +1,260 @@
+package node
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// flow control of pushed Root objects of a feed
+// of a Conn (see Config.CreditMsgs); a Conn is
+// sender and receiver at the same time
+type credit struct {
+
+	// sender
+
+	limited bool               // the peer grants credits
+	msgs    int64              // messages can be sent
+	bytes   int64              // objects of RootDelta can be sent
+	pending map[uint64]msg.Msg // latest Root by head waiting for credits
+
+	// receiver
+
+	granted   bool  // credits granted to the peer
+	usedMsgs  int64 // handled, not granted back yet
+	usedBytes int64 // handled, not granted back yet
+}
+
+// size of objects of a RootDelta
+func deltaSize(rd *msg.RootDelta) (size int64) {
+	for _, val := range rd.Objects {
+		size += int64(len(val))
+	}
+	return
+}
+
+// returns given message if it can be sent, or the
+// message converted to Root if objects of RootDelta
+// exceed credits, or nil if there are no credits;
+// it takes credits for returned message
+func (cr *credit) take(m msg.Msg) (send msg.Msg) {
+
+	if cr.msgs <= 0 {
+		return
+	}
+
+	switch x := m.(type) {
+	case *msg.Root:
+		send = x
+	case *msg.RootDelta:
+		if size := deltaSize(x); size <= cr.bytes {
+			cr.bytes -= size
+			send = x
+			break
+		}
+		send = &msg.Root{ // objects are requested by the peer
+			Feed:  x.Feed,
+			Nonce: x.Nonce,
+			Seq:   x.Seq,
+			Value: x.Value,
+			Sig:   x.Sig,
+		}
+	}
+
+	cr.msgs--
+	return
+}
+
+// returns message that can be sent, or nil if the
+// message is waiting for credits of given feed
+func (c *Conn) takeCredit(feed cipher.PubKey, m msg.Msg) (send msg.Msg) {
+
+	var nonce uint64
+
+	switch x := m.(type) {
+	case *msg.Root:
+		nonce = x.Nonce
+	case *msg.RootDelta:
+		nonce = x.Nonce
+	default:
+		return m // not limited
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var cr, ok = c.credits[feed]
+
+	if ok == false || cr.limited == false {
+		return m
+	}
+
+	if _, ok = cr.pending[nonce]; ok == false {
+		if send = cr.take(m); send != nil {
+			return
+		}
+	}
+
+	// a newer Root replaces waiting one
+
+	if cr.pending == nil {
+		cr.pending = make(map[uint64]msg.Msg)
+	}
+
+	cr.pending[nonce] = m
+	return
+}
+
+// <- Credit (feed, msgs, bytes)
+func (c *Conn) handleCredit(cm *msg.Credit) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleCredit %s %d/%d", c.String(),
+		cm.Feed.Hex()[:7], cm.Msgs, cm.Bytes)
+
+	if c.n.fs.hasFeed(cm.Feed) == false {
+		return // ignore
+	}
+
+	var send []msg.Msg
+
+	c.mx.Lock()
+
+	var cr = c.getCredit(cm.Feed)
+
+	cr.limited = true
+	cr.msgs += int64(cm.Msgs)
+	cr.bytes += int64(cm.Bytes)
+
+	for nonce, m := range cr.pending {
+		var sm = cr.take(m)
+		if sm == nil {
+			break // no more credits
+		}
+		delete(cr.pending, nonce)
+		send = append(send, sm)
+	}
+
+	c.mx.Unlock()
+
+	for _, m := range send {
+		c.sendFeed(cm.Feed, m)
+	}
+
+	return
+}
+
+// get or create credit of given feed, the
+// c.mx must be locked
+func (c *Conn) getCredit(feed cipher.PubKey) (cr *credit) {
+
+	if cr = c.credits[feed]; cr != nil {
+		return
+	}
+
+	if c.credits == nil {
+		c.credits = make(map[cipher.PubKey]*credit)
+	}
+
+	cr = new(credit)
+	c.credits[feed] = cr
+	return
+}
+
+// grant initial credits to the peer for given
+// feed, if flow control is enabled
+func (c *Conn) grantCredit(feed cipher.PubKey) {
+
+	var msgs, bytes = c.n.config.CreditMsgs, c.n.config.CreditBytes
+
+	if msgs <= 0 || c.HasFeature(msg.FeatureCredit) == false {
+		return
+	}
+
+	c.mx.Lock()
+	var cr = c.getCredit(feed)
+	var granted = cr.granted
+	cr.granted = true
+	c.mx.Unlock()
+
+	if granted == true {
+		return // already granted
+	}
+
+	c.sendCredit(feed, int64(msgs), int64(bytes))
+}
+
+// a pushed Root or RootDelta of given feed handled,
+// grant credits back if half of window is used
+func (c *Conn) usedCredit(feed cipher.PubKey, size int64) {
+
+	var msgs, bytes = c.usedCreditGrant(feed, size)
+
+	if msgs == 0 && bytes == 0 {
+		return
+	}
+
+	c.sendCredit(feed, msgs, bytes)
+}
+
+// used credits to grant back, or zeroes
+func (c *Conn) usedCreditGrant(
+	feed cipher.PubKey, // : the feed
+	size int64, //         : size of objects
+) (
+	msgs int64, //         : messages to grant back
+	bytes int64, //        : bytes to grant back
+) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var cr, ok = c.credits[feed]
+
+	if ok == false || cr.granted == false {
+		return // not limited
+	}
+
+	cr.usedMsgs++
+	cr.usedBytes += size
+
+	var (
+		halfMsgs  = int64(c.n.config.CreditMsgs+1) / 2
+		halfBytes = int64(c.n.config.CreditBytes+1) / 2
+	)
+
+	if cr.usedMsgs < halfMsgs && (halfBytes == 0 || cr.usedBytes < halfBytes) {
+		return // not yet
+	}
+
+	msgs, bytes = cr.usedMsgs, cr.usedBytes
+	cr.usedMsgs, cr.usedBytes = 0, 0
+
+	// a misbehaving peer can send more than granted,
+	// but it never gets back more than the window
+
+	if max := int64(c.n.config.CreditMsgs); msgs > max {
+		msgs = max
+	}
+
+	if max := int64(c.n.config.CreditBytes); bytes > max {
+		bytes = max
+	}
+
+	return
+}
+
+func (c *Conn) sendCredit(feed cipher.PubKey, msgs, bytes int64) {
+	c.sendFeedMsg(feed, &msg.Credit{
+		Feed:  feed,
+		Msgs:  uint32(msgs),
+		Bytes: uint32(bytes),
+	})
+}
+
+// forget credits of given feed (unsubscribed)
+func (c *Conn) delCredit(feed cipher.PubKey) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	delete(c.credits, feed)
+}
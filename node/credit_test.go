@@ -0,0 +1,165 @@
+package node
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_takeCredit(t *testing.T) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		c     = new(Conn)
+	)
+
+	// not limited
+	if c.takeCredit(pk, &msg.Root{Feed: pk}) == nil {
+		t.Fatal("not sent")
+	}
+
+	c.credits = map[cipher.PubKey]*credit{
+		pk: {limited: true, msgs: 2, bytes: 10},
+	}
+
+	// objects exceed credits
+	var rd = &msg.RootDelta{Feed: pk, Seq: 1, Objects: [][]byte{
+		make([]byte, 20),
+	}}
+
+	if sm, ok := c.takeCredit(pk, rd).(*msg.Root); ok == false {
+		t.Error("RootDelta is not sent as Root")
+	} else if sm.Seq != 1 {
+		t.Error("wrong Root sent")
+	}
+
+	if c.takeCredit(pk, &msg.Root{Feed: pk, Seq: 2}) == nil {
+		t.Fatal("not sent")
+	}
+
+	// no credits
+	if c.takeCredit(pk, &msg.Root{Feed: pk, Seq: 3}) != nil {
+		t.Error("sent without credits")
+	}
+
+	// newer Root replaces waiting one
+	if c.takeCredit(pk, &msg.Root{Feed: pk, Seq: 4}) != nil {
+		t.Error("sent without credits")
+	}
+
+	var cr = c.credits[pk]
+
+	if len(cr.pending) != 1 || cr.pending[0].(*msg.Root).Seq != 4 {
+		t.Error("wrong pending Root")
+	}
+
+	// a credit for waiting Root
+	cr.msgs++
+
+	if sm := cr.take(cr.pending[0]); sm == nil {
+		t.Error("not sent")
+	}
+
+}
+
+func TestConn_grantCredit(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	if err := sn.Share(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Subscribe(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	var sc, ok = sn.hasPeer(cn.ID())
+	assertTrue(t, ok == true, "missing incoming connection")
+
+	// both peers grant credits each other
+	for _, conn := range []*Conn{c, sc} {
+
+		var limited bool
+
+		for i := 0; i < 100 && limited == false; i++ {
+			conn.mx.Lock()
+			if cr, ok := conn.credits[pk]; ok == true {
+				limited = cr.limited && cr.granted &&
+					cr.msgs == int64(CreditMsgs) &&
+					cr.bytes == int64(CreditBytes)
+			}
+			conn.mx.Unlock()
+
+			if limited == false {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+
+		if limited == false {
+			t.Errorf("[%s] credits are not granted", conn.String())
+		}
+
+	}
+
+	c.Unsubscribe(pk)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if _, ok := c.credits[pk]; ok == true {
+		t.Error("credits are not deleted")
+	}
+
+}
+
+func TestConn_usedCreditGrant(t *testing.T) {
+
+	var (
+		c       = &Conn{n: &Node{config: NewConfig()}}
+		pk, _   = cipher.GenerateKeyPair()
+		cr      = c.getCredit(pk)
+		window  = int64(c.n.config.CreditBytes)
+		tooMany = 2*window + 1 // misbehaving peer
+	)
+
+	cr.granted = true
+
+	var msgs, bytes = c.usedCreditGrant(pk, tooMany)
+
+	if msgs != 1 || bytes != window {
+		t.Errorf("wrong credits granted back: %d/%d", msgs, bytes)
+	}
+
+}
+
+func TestConfig_CreditBytes(t *testing.T) {
+
+	var (
+		conf = NewConfig()
+		big  = int64(math.MaxUint32) + 1
+	)
+
+	conf.CreditBytes = int(big)
+
+	if big == int64(conf.CreditBytes) && conf.Validate() == nil {
+		t.Error("missing error")
+	}
+
+	conf.CreditBytes = CreditBytes
+	assertNil(t, conf.Validate())
+
+}
@@ -0,0 +1,176 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// max size of payload of a DataChunk; the skycoin/net
+// drops connection that sends a message bigger then
+// 10240 bytes (including headers, encryption and
+// compression overhead); thus, requested objects bigger
+// then the maxChunkSize sent as number of DataChunk
+const maxChunkSize = 8192
+
+// max size of reassembled object, protects against
+// malformed DataChunk
+const maxChunkedSize = maxDecompressedSize
+
+// max number and max total size (declared by first
+// chunks) of objects being received by chunks by a
+// connection at the same time
+const (
+	maxChunkedObjects = 64
+	maxChunkedVolume  = 256 * 1024 * 1024
+)
+
+// an object being received by chunks
+type chunked struct {
+	key   cipher.SHA256 // key of the object
+	total uint32        // size of the object
+	val   []byte        // received part
+}
+
+// send requested object as number of DataChunk; every
+// chunk is reply for the request, and if the request is
+// encrypted then every chunk is encrypted
+func (c *Conn) sendChunks(rseq uint32, key cipher.SHA256, val []byte) {
+
+	var (
+		sealed = c.takeSealedRequest(rseq)
+		trace  = c.takeTrace(0, rseq)
+		total  = uint32(len(val))
+	)
+
+	c.sq.startChunks(rseq)
+	defer c.sq.stopChunks(rseq)
+
+	for offset := 0; offset < len(val); offset += maxChunkSize {
+
+		var end = offset + maxChunkSize
+		if end > len(val) {
+			end = len(val)
+		}
+
+		var m msg.Msg = &msg.DataChunk{
+			Key:     key,
+			Offset:  uint32(offset),
+			Total:   total,
+			Payload: val[offset:end],
+		}
+
+		c.n.Debugf(MsgSendPin, "[%s] send %d %T%s", c.String(), rseq, m,
+			traceSuffix(trace))
+
+		if sealed == true {
+			m = c.seal(m)
+		}
+
+		var raw = c.encodeTracedMsg(c.nextSeq(), rseq, trace, m)
+
+		if c.push(sendData, rseq, raw) == false {
+			return // dropped (see Config.SendQueuePolicy)
+		}
+	}
+
+}
+
+// reassemble received DataChunk, the reassemble returns
+// an Object if all chunks of the Object received, or nil
+// if not yet; the error means that the chunk is malformed.
+// A DataChunk that is not a reply for a pending request
+// is dropped. If a first chunk exceeds limits of objects
+// being received, then the request fails with
+// ErrChunksLimit and the rest of the object is dropped
+func (c *Conn) reassemble(
+	rseq uint32, //          : reply for
+	dc *msg.DataChunk, //    : received chunk
+) (
+	obj *msg.Object, //      : reassembled object or nil
+	err error, //            : malformed chunk
+) {
+
+	if dc.Total > maxChunkedSize {
+		return nil, fmt.Errorf("DataChunk of too big object: %d", dc.Total)
+	}
+
+	if max := c.n.config.MaxMessageSize; max > 0 && int64(dc.Total) > int64(max) {
+		return nil, msg.ErrSizeLimit
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var rq, ok = c.reqs[rseq]
+
+	if ok == false {
+		return // not requested or timed out (drop)
+	}
+
+	var ch *chunked
+
+	if ch, ok = c.chunks[rseq]; ok == false {
+
+		if dc.Offset != 0 {
+			return nil, errors.New("DataChunk: missing first chunk")
+		}
+
+		if len(c.chunks) >= maxChunkedObjects ||
+			c.chunkedVolume()+int64(dc.Total) > maxChunkedVolume {
+
+			delete(c.reqs, rseq) // drop the rest
+
+			select {
+			case rq <- &msg.Err{Err: ErrChunksLimit.Error()}:
+			default:
+			}
+
+			return
+		}
+
+		if c.chunks == nil {
+			c.chunks = make(map[uint32]*chunked)
+		}
+
+		ch = &chunked{key: dc.Key, total: dc.Total}
+		c.chunks[rseq] = ch
+	}
+
+	switch {
+	case ch.key != dc.Key:
+		err = errors.New("DataChunk: key mismatch")
+	case ch.total != dc.Total:
+		err = errors.New("DataChunk: total mismatch")
+	case int(dc.Offset) != len(ch.val):
+		err = errors.New("DataChunk: out of order")
+	case len(ch.val)+len(dc.Payload) > int(ch.total):
+		err = errors.New("DataChunk: payload overflows total")
+	}
+
+	if err != nil {
+		delete(c.chunks, rseq)
+		return
+	}
+
+	ch.val = append(ch.val, dc.Payload...)
+
+	if len(ch.val) < int(ch.total) {
+		return // not yet
+	}
+
+	delete(c.chunks, rseq)
+
+	return &msg.Object{Key: ch.key, Value: ch.val}, nil
+}
+
+// total size of objects being received (under lock)
+func (c *Conn) chunkedVolume() (vol int64) {
+	for _, ch := range c.chunks {
+		vol += int64(ch.total)
+	}
+	return
+}
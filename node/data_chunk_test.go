@@ -0,0 +1,227 @@
+package node
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_sendChunks(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bigger then max message size of the transport
+	var (
+		val = bytes.Repeat([]byte("big object "), 3*maxChunkSize/10)
+		key = cipher.SumSHA256(val)
+	)
+
+	if _, err = sn.c.Set(key, val, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var reply msg.Msg
+	if reply, err = c.sendFeedRequest(pk, &msg.RqObject{Key: key}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if obj, ok := reply.(*msg.Object); ok == false {
+		t.Fatalf("wrong reply type: %T", reply)
+	} else if obj.Key != key || bytes.Equal(obj.Value, val) == false {
+		t.Error("wrong object reassembled")
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if len(c.chunks) != 0 {
+		t.Error("chunks are not released")
+	}
+
+}
+
+func TestConn_reassemble(t *testing.T) {
+
+	var c = &Conn{n: &Node{config: NewConfig()}}
+
+	c.reqs = map[uint32]chan<- msg.Msg{1: make(chan msg.Msg, 1)}
+
+	var (
+		key = cipher.SumSHA256([]byte("key"))
+		obj *msg.Object
+		err error
+	)
+
+	// not requested
+	obj, err = c.reassemble(2, &msg.DataChunk{Key: key, Total: 2,
+		Payload: []byte{1}})
+	if err != nil || obj != nil {
+		t.Errorf("unexpected result: %v, %v", obj, err)
+	} else if len(c.chunks) != 0 {
+		t.Error("unsolicited chunk buffered")
+	}
+
+	// first chunk is missing
+	_, err = c.reassemble(1, &msg.DataChunk{Key: key, Offset: 1, Total: 2,
+		Payload: []byte{1}})
+	if err == nil {
+		t.Error("missing error")
+	}
+
+	// too big
+	_, err = c.reassemble(1, &msg.DataChunk{Key: key, Total: maxChunkedSize + 1})
+	if err == nil {
+		t.Error("missing error")
+	}
+
+	// limited by Config.MaxMessageSize
+	c.n.config.MaxMessageSize = 1
+	_, err = c.reassemble(1, &msg.DataChunk{Key: key, Total: 2})
+	if err != msg.ErrSizeLimit {
+		t.Errorf("wrong error: want %v, got %v", msg.ErrSizeLimit, err)
+	}
+	c.n.config.MaxMessageSize = MaxMessageSize
+
+	// overflow
+	_, err = c.reassemble(1, &msg.DataChunk{Key: key, Total: 1,
+		Payload: []byte{1, 2}})
+	if err == nil {
+		t.Error("missing error")
+	}
+
+	obj, err = c.reassemble(1, &msg.DataChunk{Key: key, Total: 2,
+		Payload: []byte{1}})
+	if err != nil {
+		t.Fatal(err)
+	} else if obj != nil {
+		t.Fatal("unexpected object")
+	}
+
+	obj, err = c.reassemble(1, &msg.DataChunk{Key: key, Offset: 1, Total: 2,
+		Payload: []byte{2}})
+	if err != nil {
+		t.Fatal(err)
+	} else if obj == nil {
+		t.Fatal("missing object")
+	}
+
+	if obj.Key != key || bytes.Equal(obj.Value, []byte{1, 2}) == false {
+		t.Error("wrong object reassembled")
+	}
+
+}
+
+func TestConn_reassemble_limits(t *testing.T) {
+
+	var (
+		c   = &Conn{n: &Node{config: NewConfig()}}
+		key = cipher.SumSHA256([]byte("key"))
+		rq  = make(chan msg.Msg, 1)
+	)
+
+	c.reqs = make(map[uint32]chan<- msg.Msg)
+
+	for seq := uint32(1); seq <= maxChunkedObjects+1; seq++ {
+		c.reqs[seq] = rq
+	}
+
+	for seq := uint32(1); seq <= maxChunkedObjects; seq++ {
+		var _, err = c.reassemble(seq, &msg.DataChunk{Key: key, Total: 2,
+			Payload: []byte{1}})
+		assertNil(t, err)
+	}
+
+	// too many
+	var obj, err = c.reassemble(maxChunkedObjects+1,
+		&msg.DataChunk{Key: key, Total: 2, Payload: []byte{1}})
+	assertNil(t, err)
+	assertTrue(t, obj == nil, "unexpected object")
+
+	select {
+	case reply := <-rq:
+		if er, ok := reply.(*msg.Err); ok == false ||
+			er.Err != ErrChunksLimit.Error() {
+
+			t.Errorf("wrong reply: %v", reply)
+		}
+	default:
+		t.Error("request is not failed")
+	}
+
+	if _, ok := c.reqs[maxChunkedObjects+1]; ok == true {
+		t.Error("request is not removed")
+	}
+
+	// too big
+	c.chunks = nil
+
+	for seq := uint32(1); seq <= 2; seq++ {
+		c.reqs[seq] = rq
+		_, err = c.reassemble(seq, &msg.DataChunk{Key: key,
+			Total: maxChunkedVolume / 2, Payload: []byte{1}})
+		assertNil(t, err)
+	}
+
+	c.reqs[3] = rq
+	_, err = c.reassemble(3, &msg.DataChunk{Key: key, Total: 1,
+		Payload: []byte{1}})
+	assertNil(t, err)
+
+	if _, ok := c.chunks[3]; ok == true {
+		t.Error("object over volume limit buffered")
+	}
+
+}
+
+func TestConn_reassemble_unsolicited(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cs = sn.Connections()
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+
+	// first chunk of an object that is not requested
+	cs[0].sendMsg(cs[0].nextSeq(), c.nextSeq()+100, &msg.DataChunk{
+		Key:     cipher.SumSHA256([]byte("big object")),
+		Total:   2 * maxChunkSize,
+		Payload: make([]byte, maxChunkSize),
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	select {
+	case <-c.closeq:
+		t.Fatal("connection closed")
+	default:
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if len(c.chunks) != 0 {
+		t.Error("unsolicited chunks buffered")
+	}
+
+}
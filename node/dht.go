@@ -0,0 +1,138 @@
+package node
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/dht"
+)
+
+// interval of announcements and lookups,
+// the announcements expire after the TTL
+const dhtInterval = dht.ProviderTTL / 2
+
+// DHT returns DHT node of the Node or nil
+// if the Node doesn't join DHT (see Config.DHT)
+func (n *Node) DHT() *dht.DHT {
+	return n.dht
+}
+
+// join the DHT, announce feeds and look up providers
+// periodically and after Share (see Config.DHT)
+func (n *Node) runDHT() {
+
+	if err := n.dht.Bootstrap(); err != nil {
+		n.Printf("[ERR] [dht] bootstrap: %v", err)
+	}
+
+	n.updateDHT()
+
+	var tk = time.NewTicker(dhtInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			if n.dht.Len() == 0 {
+				if err := n.dht.Bootstrap(); err != nil {
+					n.Printf("[ERR] [dht] bootstrap: %v", err)
+				}
+			}
+			n.updateDHT()
+		case <-n.dhtq:
+			n.updateDHT()
+		case <-n.closeq:
+			return
+		}
+	}
+
+}
+
+// trigger updateDHT after Share
+func (n *Node) triggerDHT() {
+	if n.dht == nil {
+		return
+	}
+	select {
+	case n.dhtq <- struct{}{}:
+	default:
+	}
+}
+
+// TCP port to announce or zero
+func (n *Node) dhtPort() (port int) {
+	var _, p, err = net.SplitHostPort(n.TCP().Address())
+	if err != nil {
+		return
+	}
+	port, _ = strconv.Atoi(p)
+	return
+}
+
+// announce feeds and connect to providers of them
+func (n *Node) updateDHT() {
+
+	var port = n.dhtPort()
+
+	for _, feed := range n.fs.list() {
+
+		select {
+		case <-n.closeq:
+			return
+		default:
+		}
+
+		if port != 0 {
+			if err := n.dht.Announce(feed, port); err != nil {
+				n.Printf("[ERR] [dht] announce %s: %v", feed.Hex()[:7], err)
+			}
+		}
+
+		var ps, err = n.dht.Providers(feed)
+		if err != nil {
+			n.Printf("[ERR] [dht] providers of %s: %v", feed.Hex()[:7], err)
+			continue
+		}
+
+		n.Debugf(DiscoveryPin, "[dht] %d providers of %s", len(ps),
+			feed.Hex()[:7])
+
+		for _, p := range ps {
+			n.connectDHTProvider(feed, p)
+		}
+
+	}
+
+}
+
+// connect to a provider and subscribe to the feed
+func (n *Node) connectDHTProvider(feed cipher.PubKey, p dht.Provider) {
+
+	var (
+		c, ok = n.hasPeer(p.Key)
+		err   error
+	)
+
+	if ok == false {
+		if c, err = n.TCP().Connect(p.Address); err != nil {
+			n.Debugf(DiscoveryPin, "[dht] can't connect to %s: %v",
+				p.Address, err)
+			return
+		}
+	}
+
+	for _, f := range c.Feeds() {
+		if f == feed {
+			return // already subscribed
+		}
+	}
+
+	if err = c.Subscribe(feed); err != nil {
+		n.Debugf(DiscoveryPin, "[dht] [%s] can't subscribe to %s: %v",
+			c.Address(), feed.Hex()[:7], err)
+	}
+
+}
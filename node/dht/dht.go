@@ -0,0 +1,521 @@
+// Package dht implements Kademlia DHT used to find
+// providers of feeds without discovery server. Nodes
+// of the DHT announce feeds they serve and look up
+// providers (CXO nodes and their TCP addresses) by
+// public key of a feed. The DHT works over UDP. The
+// node package uses it (see node.Config.DHT)
+package dht
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// constants
+const (
+	K     int = 8 // size of a bucket and number of closest nodes
+	Alpha int = 3 // parallel requests of a lookup
+
+	// ProviderTTL is lifetime of an announcement,
+	// a provider should announce its feeds again
+	// before the TTL expires
+	ProviderTTL time.Duration = 30 * time.Minute
+
+	maxFails        = 3               // remove a node from routing table
+	maxPacketSize   = 8192            // max size of UDP packet
+	maxProviders    = 32              // providers per reply
+	maxFeedStored   = 64              // providers of a feed stored
+	maxFeedsStored  = 4096            // feeds stored
+	tokenInterval   = 5 * time.Minute // secret rotation
+	cleanupInterval = time.Minute     // remove expired providers
+)
+
+// defaults
+const (
+	Timeout time.Duration = 2 * time.Second // request timeout
+)
+
+// errors
+var (
+	ErrClosed       = errors.New("dht: closed")
+	ErrTimeout      = errors.New("dht: request timeout")
+	ErrNoNodes      = errors.New("dht: no nodes")
+	ErrInvalidToken = errors.New("dht: invalid token")
+)
+
+// An ID of DHT node or feed
+type ID [32]byte
+
+// NodeID returns ID of DHT node by public key of CXO node
+func NodeID(pk cipher.PubKey) ID {
+	return ID(cipher.SumSHA256(pk[:]))
+}
+
+// FeedID returns ID of given feed
+func FeedID(feed cipher.PubKey) ID {
+	return ID(cipher.SumSHA256(feed[:]))
+}
+
+// String implements fmt.Stringer interface
+// and returns short hex encoded ID
+func (id ID) String() string {
+	return hex.EncodeToString(id[:4])
+}
+
+// xor distance
+func (id ID) distance(x ID) (d ID) {
+	for i := range d {
+		d[i] = id[i] ^ x[i]
+	}
+	return
+}
+
+// is a distance less then given one
+func (id ID) less(x ID) bool {
+	for i := range id {
+		if id[i] != x[i] {
+			return id[i] < x[i]
+		}
+	}
+	return false
+}
+
+// A Provider of a feed
+type Provider struct {
+	Key     cipher.PubKey // public key of CXO node
+	Address string        // TCP address
+}
+
+// A Config represents configurations of DHT
+type Config struct {
+	// Listen is UDP address to listen on
+	Listen string
+	// Bootstrap is list of UDP addresses of known
+	// DHT nodes used to join the DHT
+	Bootstrap []string
+	// Timeout of a request
+	Timeout time.Duration
+}
+
+// NewConfig returns Config with defaults
+func NewConfig() (c Config) {
+	c.Timeout = Timeout
+	return
+}
+
+// packet types
+const (
+	typePing uint8 = 1 + iota
+	typePong
+	typeFindNode
+	typeNodes
+	typeGetProviders
+	typeProviders
+	typeAnnounce
+	typeAnnounced
+	typeError
+)
+
+// a DHT node
+type contact struct {
+	ID      ID
+	Address string // UDP address
+}
+
+// a request or a response
+type packet struct {
+	Type      uint8
+	TID       uint32        // transaction id
+	From      ID            // sender
+	Target    ID            // node or feed
+	Key       cipher.PubKey // announce: CXO node
+	Port      uint16        // announce: TCP port
+	Token     []byte        // get_providers reply, announce
+	Nodes     []contact     // closest nodes
+	Providers []Provider    // providers of the Target
+	Err       string        // error reply
+}
+
+// is the packet a response
+func (p *packet) isResponse() bool {
+	return p.Type%2 == 0 || p.Type == typeError
+}
+
+// a pending request
+type pending struct {
+	address string
+	reply   chan *packet
+}
+
+// A DHT represents node of Kademlia DHT
+type DHT struct {
+	id   ID
+	key  cipher.PubKey // CXO node
+	conf Config
+	conn net.PacketConn
+
+	tb *table
+	st *store
+	tk *tokens
+
+	rmx sync.Mutex
+	tid uint32
+	rqs map[uint32]*pending
+
+	closeq chan struct{}
+	closeo sync.Once
+	await  sync.WaitGroup
+}
+
+// New creates DHT node for CXO node with given public
+// key and starts listening. Call Bootstrap to join
+// the DHT
+func New(key cipher.PubKey, conf Config) (d *DHT, err error) {
+
+	if conf.Timeout <= 0 {
+		conf.Timeout = Timeout
+	}
+
+	var conn net.PacketConn
+	if conn, err = net.ListenPacket("udp", conf.Listen); err != nil {
+		return
+	}
+
+	d = &DHT{
+		id:     NodeID(key),
+		key:    key,
+		conf:   conf,
+		conn:   conn,
+		st:     newStore(),
+		tk:     newTokens(),
+		rqs:    make(map[uint32]*pending),
+		closeq: make(chan struct{}),
+	}
+	d.tb = newTable(d.id)
+
+	d.await.Add(2)
+	go d.receive()
+	go d.cleanup()
+
+	return
+}
+
+// ID of the DHT node
+func (d *DHT) ID() ID {
+	return d.id
+}
+
+// Addr returns listening address
+func (d *DHT) Addr() net.Addr {
+	return d.conn.LocalAddr()
+}
+
+// Len returns number of nodes in routing table
+func (d *DHT) Len() int {
+	return d.tb.len()
+}
+
+// Bootstrap joins the DHT using Config.Bootstrap
+// nodes and nodes of routing table. It returns
+// ErrNoNodes if there are no nodes to join
+func (d *DHT) Bootstrap() (err error) {
+
+	var wg sync.WaitGroup
+
+	for _, address := range d.conf.Bootstrap {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			d.request(address, &packet{Type: typePing})
+		}(address)
+	}
+
+	wg.Wait()
+
+	if d.tb.len() == 0 {
+		return ErrNoNodes
+	}
+
+	_, err = d.lookup(d.id, typeFindNode)
+	return
+}
+
+// Announce that the CXO node serves given feed
+// on given TCP port. Announcements expire after
+// the ProviderTTL
+func (d *DHT) Announce(feed cipher.PubKey, port int) (err error) {
+
+	var target = FeedID(feed)
+
+	var lr *lookupResult
+	if lr, err = d.lookup(target, typeGetProviders); err != nil {
+		return
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mx  sync.Mutex
+		got int
+	)
+
+	for _, c := range lr.closest {
+
+		var token, ok = lr.tokens[c.Address]
+		if ok == false {
+			continue
+		}
+
+		wg.Add(1)
+		go func(address string, token []byte) {
+			defer wg.Done()
+
+			var reply, err = d.request(address, &packet{
+				Type:   typeAnnounce,
+				Target: target,
+				Key:    d.key,
+				Port:   uint16(port),
+				Token:  token,
+			})
+
+			if err == nil && reply.Type == typeAnnounced {
+				mx.Lock()
+				got++
+				mx.Unlock()
+			}
+		}(c.Address, token)
+
+	}
+
+	wg.Wait()
+
+	if got == 0 {
+		return ErrNoNodes
+	}
+
+	return
+}
+
+// Providers returns providers of given feed
+// excluding the CXO node of the DHT
+func (d *DHT) Providers(feed cipher.PubKey) (ps []Provider, err error) {
+
+	var target = FeedID(feed)
+
+	var lr *lookupResult
+	if lr, err = d.lookup(target, typeGetProviders); err != nil {
+		return
+	}
+
+	var seen = make(map[Provider]struct{})
+
+	for _, p := range append(d.st.get(target), lr.providers...) {
+		if p.Key == d.key {
+			continue
+		}
+		if _, ok := seen[p]; ok == true {
+			continue
+		}
+		seen[p] = struct{}{}
+		ps = append(ps, p)
+	}
+
+	return
+}
+
+// Close the DHT
+func (d *DHT) Close() (err error) {
+	d.closeo.Do(func() {
+		close(d.closeq)
+		err = d.conn.Close()
+		d.await.Wait()
+	})
+	return
+}
+
+// send request and wait for response
+func (d *DHT) request(address string, p *packet) (reply *packet, err error) {
+
+	var ua *net.UDPAddr
+	if ua, err = net.ResolveUDPAddr("udp", address); err != nil {
+		return
+	}
+
+	var pn = &pending{address: ua.String(), reply: make(chan *packet, 1)}
+
+	d.rmx.Lock()
+	d.tid++
+	p.TID = d.tid
+	d.rqs[p.TID] = pn
+	d.rmx.Unlock()
+
+	defer func() {
+		d.rmx.Lock()
+		delete(d.rqs, p.TID)
+		d.rmx.Unlock()
+	}()
+
+	if err = d.send(ua, p); err != nil {
+		return
+	}
+
+	var tm = time.NewTimer(d.conf.Timeout)
+	defer tm.Stop()
+
+	select {
+	case reply = <-pn.reply:
+		if reply.Type == typeError {
+			return nil, remoteError(reply.Err)
+		}
+	case <-tm.C:
+		d.tb.fail(pn.address)
+		err = ErrTimeout
+	case <-d.closeq:
+		err = ErrClosed
+	}
+
+	return
+}
+
+func (d *DHT) send(addr net.Addr, p *packet) (err error) {
+	p.From = d.id
+	_, err = d.conn.WriteTo(encoder.Serialize(p), addr)
+	return
+}
+
+// read packets
+func (d *DHT) receive() {
+	defer d.await.Done()
+
+	var buf = make([]byte, maxPacketSize)
+
+	for {
+
+		var n, addr, err = d.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-d.closeq:
+				return
+			default:
+			}
+			continue
+		}
+
+		var p = new(packet)
+		if err = encoder.DeserializeRaw(buf[:n], p); err != nil {
+			continue // malformed
+		}
+
+		if p.From == d.id {
+			continue
+		}
+
+		if p.isResponse() == true {
+			d.handleResponse(p, addr)
+			continue
+		}
+
+		d.tb.add(contact{ID: p.From, Address: addr.String()})
+		d.handleRequest(p, addr)
+
+	}
+}
+
+func (d *DHT) handleResponse(p *packet, addr net.Addr) {
+
+	d.rmx.Lock()
+	var pn, ok = d.rqs[p.TID]
+	if ok == true && pn.address == addr.String() {
+		delete(d.rqs, p.TID)
+	} else {
+		ok = false
+	}
+	d.rmx.Unlock()
+
+	if ok == false {
+		return // unexpected or spoofed
+	}
+
+	d.tb.add(contact{ID: p.From, Address: pn.address})
+	pn.reply <- p
+}
+
+func (d *DHT) handleRequest(p *packet, addr net.Addr) {
+
+	var reply = &packet{TID: p.TID, Target: p.Target}
+
+	switch p.Type {
+
+	case typePing:
+		reply.Type = typePong
+
+	case typeFindNode:
+		reply.Type = typeNodes
+		reply.Nodes = d.tb.closest(p.Target, K)
+
+	case typeGetProviders:
+		reply.Type = typeProviders
+		reply.Nodes = d.tb.closest(p.Target, K)
+		reply.Providers = d.st.get(p.Target)
+		reply.Token = d.tk.token(host(addr))
+
+	case typeAnnounce:
+		if d.tk.valid(host(addr), p.Token) == false {
+			reply.Type, reply.Err = typeError, ErrInvalidToken.Error()
+			break
+		}
+		d.st.add(p.Target, Provider{
+			Key:     p.Key,
+			Address: net.JoinHostPort(host(addr), strconv.Itoa(int(p.Port))),
+		})
+		reply.Type = typeAnnounced
+
+	default:
+		return // unknown
+	}
+
+	d.send(addr, reply)
+}
+
+// remove expired providers periodically
+func (d *DHT) cleanup() {
+	defer d.await.Done()
+
+	var tk = time.NewTicker(cleanupInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			d.st.cleanup()
+		case <-d.closeq:
+			return
+		}
+	}
+}
+
+// error of error reply
+func remoteError(e string) error {
+	if e == ErrInvalidToken.Error() {
+		return ErrInvalidToken
+	}
+	return errors.New(e)
+}
+
+// IP of UDP address with zone, if any
+func host(addr net.Addr) string {
+	if ua, ok := addr.(*net.UDPAddr); ok == true {
+		if ua.Zone != "" {
+			return ua.IP.String() + "%" + ua.Zone
+		}
+		return ua.IP.String()
+	}
+	var h, _, _ = net.SplitHostPort(addr.String())
+	return h
+}
@@ -0,0 +1,176 @@
+package dht
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func testNetwork(t *testing.T, n int) (ds []*DHT) {
+
+	for i := 0; i < n; i++ {
+
+		var conf = NewConfig()
+		conf.Listen = "127.0.0.1:0"
+		conf.Timeout = time.Second
+
+		if i > 0 {
+			conf.Bootstrap = []string{ds[0].Addr().String()}
+		}
+
+		var pk, _ = cipher.GenerateKeyPair()
+
+		var d, err = New(pk, conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds = append(ds, d)
+
+		if i > 0 {
+			if err = d.Bootstrap(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+	}
+
+	return
+}
+
+func closeNetwork(ds []*DHT) {
+	for _, d := range ds {
+		d.Close()
+	}
+}
+
+func TestDHT_Providers(t *testing.T) {
+
+	var ds = testNetwork(t, 12)
+	defer closeNetwork(ds)
+
+	for i, d := range ds[1:] {
+		if d.Len() == 0 {
+			t.Fatal("empty routing table of", i+1)
+		}
+	}
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	if ps, err := ds[7].Providers(feed); err != nil {
+		t.Fatal(err)
+	} else if len(ps) != 0 {
+		t.Error("unexpected providers", ps)
+	}
+
+	if err := ds[3].Announce(feed, 8870); err != nil {
+		t.Fatal(err)
+	}
+
+	var ps, err = ds[7].Providers(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ps) != 1 {
+		t.Fatal("wrong number of providers", len(ps))
+	}
+
+	if ps[0].Key != ds[3].key || ps[0].Address != "127.0.0.1:8870" {
+		t.Error("wrong provider", ps[0])
+	}
+
+	// excluding own
+	if ps, err = ds[3].Providers(feed); err != nil {
+		t.Fatal(err)
+	} else if len(ps) != 0 {
+		t.Error("own provider returned", ps)
+	}
+
+}
+
+func TestDHT_Bootstrap(t *testing.T) {
+
+	var conf = NewConfig()
+	conf.Listen = "127.0.0.1:0"
+	conf.Timeout = 100 * time.Millisecond
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	var d, err = New(pk, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if err = d.Bootstrap(); err != ErrNoNodes {
+		t.Error("wrong error:", err)
+	}
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	if err = d.Announce(feed, 8870); err != ErrNoNodes {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestDHT_invalidToken(t *testing.T) {
+
+	var ds = testNetwork(t, 2)
+	defer closeNetwork(ds)
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	var _, err = ds[1].request(ds[0].Addr().String(), &packet{
+		Type:   typeAnnounce,
+		Target: FeedID(feed),
+		Key:    ds[1].key,
+		Port:   8870,
+		Token:  []byte("invalid"),
+	})
+
+	if err != ErrInvalidToken {
+		t.Error("wrong error:", err)
+	}
+
+	if ps := ds[0].st.get(FeedID(feed)); len(ps) != 0 {
+		t.Error("provider stored")
+	}
+
+}
+
+func Test_table(t *testing.T) {
+
+	var tb = newTable(ID{})
+
+	// all in the same (first) bucket
+	for i := 0; i < K+1; i++ {
+		tb.add(contact{ID: ID{0x80, byte(i)}, Address: string(rune('a' + i))})
+	}
+
+	if tb.len() != K {
+		t.Fatal("wrong length", tb.len())
+	}
+
+	// the oldest failed and is replaced
+	tb.fail("a")
+	tb.add(contact{ID: ID{0x80, 0xff}, Address: "z"})
+
+	var cs = tb.closest(ID{0x80}, K)
+	if cs[0].Address != "b" {
+		t.Error("wrong order", cs)
+	}
+
+	for _, c := range cs {
+		if c.Address == "a" {
+			t.Error("failed node is not replaced")
+		}
+	}
+
+	tb.add(contact{ID: ID{}, Address: "self"})
+	if tb.len() != K {
+		t.Error("own ID added")
+	}
+
+}
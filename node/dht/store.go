@@ -0,0 +1,139 @@
+package dht
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync"
+	"time"
+)
+
+// announced providers
+type store struct {
+	mx    sync.Mutex
+	feeds map[ID]map[Provider]time.Time // expiration
+}
+
+func newStore() *store {
+	return &store{feeds: make(map[ID]map[Provider]time.Time)}
+}
+
+// add or refresh a provider
+func (s *store) add(feed ID, p Provider) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var ps, ok = s.feeds[feed]
+
+	if ok == false {
+		if len(s.feeds) >= maxFeedsStored {
+			return
+		}
+		ps = make(map[Provider]time.Time)
+		s.feeds[feed] = ps
+	}
+
+	if _, ok = ps[p]; ok == false && len(ps) >= maxFeedStored {
+		return
+	}
+
+	ps[p] = time.Now().Add(ProviderTTL)
+}
+
+// not expired providers of a feed
+func (s *store) get(feed ID) (ps []Provider) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var now = time.Now()
+
+	for p, exp := range s.feeds[feed] {
+		if len(ps) == maxProviders {
+			break
+		}
+		if exp.After(now) {
+			ps = append(ps, p)
+		}
+	}
+
+	return
+}
+
+// remove expired providers
+func (s *store) cleanup() {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var now = time.Now()
+
+	for feed, ps := range s.feeds {
+		for p, exp := range ps {
+			if exp.After(now) == false {
+				delete(ps, p)
+			}
+		}
+		if len(ps) == 0 {
+			delete(s.feeds, feed)
+		}
+	}
+}
+
+// tokens of get_providers replies; an announce
+// requires token received from the same node
+// for the same IP, that prevents announcing
+// on behalf of another host. The secret is
+// rotated and previous one is accepted too
+type tokens struct {
+	mx      sync.Mutex
+	secret  [32]byte
+	prev    [32]byte
+	rotated time.Time
+}
+
+func newTokens() (t *tokens) {
+	t = new(tokens)
+	t.rotate()
+	t.prev = t.secret
+	return
+}
+
+func (t *tokens) rotate() {
+	t.prev = t.secret
+	rand.Read(t.secret[:])
+	t.rotated = time.Now()
+}
+
+func tokenOf(secret [32]byte, ip string) []byte {
+	var sum = sha256.Sum256(append(secret[:], ip...))
+	return sum[:8]
+}
+
+// token for given IP
+func (t *tokens) token(ip string) []byte {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if time.Since(t.rotated) >= tokenInterval {
+		t.rotate()
+	}
+
+	return tokenOf(t.secret, ip)
+}
+
+// is given token valid for given IP
+func (t *tokens) valid(ip string, token []byte) bool {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if time.Since(t.rotated) >= tokenInterval {
+		t.rotate()
+	}
+
+	return subtle.ConstantTimeCompare(tokenOf(t.secret, ip), token) == 1 ||
+		subtle.ConstantTimeCompare(tokenOf(t.prev, ip), token) == 1
+}
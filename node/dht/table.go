@@ -0,0 +1,240 @@
+package dht
+
+import (
+	"sort"
+	"sync"
+)
+
+// a node of routing table
+type entry struct {
+	contact
+	fails int // failed requests in a row
+}
+
+// routing table, a bucket per bit of
+// common prefix with own ID
+type table struct {
+	mx      sync.Mutex
+	self    ID
+	buckets [len(ID{}) * 8][]*entry // oldest first
+}
+
+func newTable(self ID) *table {
+	return &table{self: self}
+}
+
+// index of bucket for given ID or -1 for own ID
+func (t *table) bucket(id ID) int {
+	var d = t.self.distance(id)
+	for i, b := range d {
+		for j := 0; j < 8; j++ {
+			if b&(0x80>>uint(j)) != 0 {
+				return i*8 + j
+			}
+		}
+	}
+	return -1
+}
+
+// add seen node or move it to the end of its bucket;
+// if the bucket is full, then the oldest node is
+// replaced if it fails to respond, otherwise the
+// new node is dropped
+func (t *table) add(c contact) {
+
+	var i = t.bucket(c.ID)
+	if i < 0 {
+		return
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var b = t.buckets[i]
+
+	for k, e := range b {
+		if e.ID == c.ID {
+			e.Address, e.fails = c.Address, 0
+			b = append(b[:k], b[k+1:]...)
+			t.buckets[i] = append(b, e)
+			return
+		}
+	}
+
+	if len(b) >= K {
+		if b[0].fails == 0 {
+			return // keep old nodes
+		}
+		b = b[1:]
+	}
+
+	t.buckets[i] = append(b, &entry{contact: c})
+}
+
+// a request to node with given address failed
+func (t *table) fail(address string) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	for i, b := range t.buckets {
+		for k, e := range b {
+			if e.Address != address {
+				continue
+			}
+			if e.fails++; e.fails >= maxFails {
+				t.buckets[i] = append(b[:k:k], b[k+1:]...)
+			}
+			return
+		}
+	}
+}
+
+// n closest nodes to given target
+func (t *table) closest(target ID, n int) (cs []contact) {
+
+	t.mx.Lock()
+	for _, b := range t.buckets {
+		for _, e := range b {
+			cs = append(cs, e.contact)
+		}
+	}
+	t.mx.Unlock()
+
+	sortByDistance(cs, target)
+
+	if len(cs) > n {
+		cs = cs[:n]
+	}
+
+	return
+}
+
+func (t *table) len() (l int) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	for _, b := range t.buckets {
+		l += len(b)
+	}
+	return
+}
+
+func sortByDistance(cs []contact, target ID) {
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].ID.distance(target).less(cs[j].ID.distance(target))
+	})
+}
+
+// result of iterative lookup
+type lookupResult struct {
+	closest   []contact         // responded nodes
+	providers []Provider        // found providers
+	tokens    map[string][]byte // address -> token
+}
+
+// iterative lookup of given target using find_node
+// or get_providers requests
+func (d *DHT) lookup(target ID, typ uint8) (lr *lookupResult, err error) {
+
+	var short = d.tb.closest(target, K)
+
+	if len(short) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	lr = &lookupResult{tokens: make(map[string][]byte)}
+
+	var (
+		seen    = make(map[ID]struct{})
+		queried = make(map[ID]struct{})
+		found   = make(map[Provider]struct{})
+	)
+
+	for _, c := range short {
+		seen[c.ID] = struct{}{}
+	}
+
+	type response struct {
+		c     contact
+		reply *packet
+	}
+
+	for {
+
+		sortByDistance(short, target)
+
+		var batch []contact
+		for i := 0; i < len(short) && i < K && len(batch) < Alpha; i++ {
+			if _, ok := queried[short[i].ID]; ok == false {
+				queried[short[i].ID] = struct{}{}
+				batch = append(batch, short[i])
+			}
+		}
+
+		if len(batch) == 0 {
+			break // the K closest are queried
+		}
+
+		var rs = make(chan response, len(batch))
+
+		for _, c := range batch {
+			go func(c contact) {
+				var reply, err = d.request(c.Address, &packet{
+					Type:   typ,
+					Target: target,
+				})
+				if err != nil || reply.Type != typ+1 {
+					reply = nil
+				}
+				rs <- response{c, reply}
+			}(c)
+		}
+
+		for range batch {
+
+			var r = <-rs
+			if r.reply == nil {
+				continue
+			}
+
+			r.c.ID = r.reply.From
+			lr.closest = append(lr.closest, r.c)
+
+			if r.reply.Token != nil {
+				lr.tokens[r.c.Address] = r.reply.Token
+			}
+
+			for _, c := range r.reply.Nodes {
+				if _, ok := seen[c.ID]; ok == true || c.ID == d.id {
+					continue
+				}
+				seen[c.ID] = struct{}{}
+				short = append(short, c)
+			}
+
+			for _, p := range r.reply.Providers {
+				if _, ok := found[p]; ok == false {
+					found[p] = struct{}{}
+					lr.providers = append(lr.providers, p)
+				}
+			}
+
+		}
+
+		select {
+		case <-d.closeq:
+			return nil, ErrClosed
+		default:
+		}
+
+	}
+
+	sortByDistance(lr.closest, target)
+
+	if len(lr.closest) > K {
+		lr.closest = lr.closest[:K]
+	}
+
+	return
+}
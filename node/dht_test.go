@@ -0,0 +1,98 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/dht"
+)
+
+func TestNode_DHT(t *testing.T) {
+
+	// bootstrap node
+
+	var rconf = dht.NewConfig()
+	rconf.Listen = "127.0.0.1:0"
+
+	var rk, _ = cipher.GenerateKeyPair()
+
+	var r, err = dht.New(rk, rconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	// provider
+
+	var aconf = getTestConfig("a")
+	aconf.UDP.Listen = ""
+	aconf.DHT.Listen = "127.0.0.1:0"
+	aconf.DHT.Bootstrap = []string{r.Addr().String()}
+
+	var a *Node
+	if a, err = NewNode(aconf); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	assertTrue(t, a.DHT() != nil, "missing DHT")
+	assertNil(t, a.Share(feed))
+
+	var ps []dht.Provider
+	for i := 0; len(ps) == 0 && i < 100; i++ {
+		time.Sleep(50 * time.Millisecond)
+		ps, _ = r.Providers(feed)
+	}
+
+	assertTrue(t, len(ps) == 1, "feed is not announced")
+	assertTrue(t, ps[0].Key == a.ID(), "wrong provider")
+
+	// looks up the provider
+
+	var bconf = getTestConfigNotListen("b")
+	bconf.DHT.Listen = "127.0.0.1:0"
+	bconf.DHT.Bootstrap = []string{r.Addr().String()}
+
+	var b *Node
+	if b, err = NewNode(bconf); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	assertNil(t, b.Share(feed))
+
+	var (
+		c  *Conn
+		ok bool
+	)
+	for i := 0; ok == false && i < 100; i++ {
+		time.Sleep(50 * time.Millisecond)
+		c, ok = b.hasPeer(a.ID())
+	}
+
+	if ok == false {
+		t.Fatal("not connected to the provider")
+	}
+
+	for i := 0; len(c.Feeds()) == 0 && i < 100; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	assertTrue(t, len(c.Feeds()) == 1 && c.Feeds()[0] == feed,
+		"not subscribed")
+
+}
+
+func TestNode_DHT_disabled(t *testing.T) {
+
+	var n = getTestNodeNotListen("node")
+	defer n.Close()
+
+	assertTrue(t, n.DHT() == nil, "unexpected DHT")
+	assertNil(t, n.Share(cipher.PubKey{1}))
+
+}
@@ -0,0 +1,195 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// prefix of TXT records of DNS seeds
+const dnsSeedPrefix = "cxo-seed="
+
+// DNS lookups (replaced by tests)
+var (
+	lookupTXT = net.LookupTXT
+	lookupSRV = net.LookupSRV
+)
+
+// a seed node published by DNS
+type dnsSeed struct {
+	address string          // TCP address
+	feeds   []cipher.PubKey // feeds the seed expected to serve
+}
+
+// parse TXT record, the ok is false if the record
+// is not a record of a seed (another TXT record)
+func parseDNSSeed(txt string) (ds dnsSeed, ok bool, err error) {
+
+	if strings.HasPrefix(txt, dnsSeedPrefix) == false {
+		return // not a seed
+	}
+
+	var fields = strings.Fields(strings.TrimPrefix(txt, dnsSeedPrefix))
+
+	if len(fields) == 0 {
+		err = fmt.Errorf("missing address in %q", txt)
+		return
+	}
+
+	if ds.address, err = normalizeHostPort(fields[0]); err != nil {
+		err = fmt.Errorf("invalid address in %q: %v", txt, err)
+		return
+	}
+
+	for _, hex := range fields[1:] {
+
+		var pk cipher.PubKey
+		if pk, err = cipher.PubKeyFromHex(hex); err != nil {
+			err = fmt.Errorf("invalid feed %q in %q: %v", hex, txt, err)
+			return
+		}
+
+		ds.feeds = append(ds.feeds, pk)
+	}
+
+	return ds, true, nil
+}
+
+// lookup seeds of given domain using TXT records
+// and SRV records of the _cxo._tcp service
+func lookupDNSSeeds(domain string) (seeds []dnsSeed, err error) {
+
+	var txts, txtErr = lookupTXT(domain)
+
+	for _, txt := range txts {
+
+		var (
+			ds dnsSeed
+			ok bool
+		)
+
+		if ds, ok, err = parseDNSSeed(txt); err != nil {
+			return
+		} else if ok == true {
+			seeds = append(seeds, ds)
+		}
+
+	}
+
+	var _, srvs, srvErr = lookupSRV("cxo", "tcp", domain)
+
+	for _, srv := range srvs {
+		seeds = append(seeds, dnsSeed{
+			address: net.JoinHostPort(strings.TrimSuffix(srv.Target, "."),
+				strconv.Itoa(int(srv.Port))),
+		})
+	}
+
+	if len(seeds) == 0 {
+		if err = txtErr; err == nil {
+			err = srvErr
+		}
+		if err == nil {
+			err = fmt.Errorf("no seeds published by %q", domain)
+		}
+	}
+
+	return
+}
+
+// connect to seeds published by domains of the
+// Config.DNSSeeds and refresh them periodically
+// (see Config.DNSSeedsRefresh)
+func (n *Node) dnsSeeds() {
+
+	if n.connectDNSSeeds() == false || n.config.DNSSeedsRefresh == 0 {
+		return
+	}
+
+	var tk = time.NewTicker(n.config.DNSSeedsRefresh)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			n.Debug(DiscoveryPin, "[dns-seed] refresh")
+			if n.connectDNSSeeds() == false {
+				return
+			}
+		case <-n.closeq:
+			return
+		}
+	}
+
+}
+
+// connect to seeds published by domains of the
+// Config.DNSSeeds, errors are logged; the ok is
+// false if the Node has been closed
+func (n *Node) connectDNSSeeds() (ok bool) {
+
+	for _, domain := range n.config.DNSSeeds {
+
+		var seeds, err = lookupDNSSeeds(domain)
+
+		if err != nil {
+			n.Printf("[ERR] [dns-seed] %s: %v", domain, err)
+			continue
+		}
+
+		for _, ds := range seeds {
+
+			select {
+			case <-n.closeq:
+				return false
+			default:
+			}
+
+			if err = n.connectDNSSeed(ds); err != nil {
+				n.Printf("[ERR] [dns-seed] %s: %s: %v", domain, ds.address, err)
+			}
+
+		}
+
+	}
+
+	return true
+}
+
+// connect to the seed and subscribe to
+// feeds the seed expected to serve; if
+// the Node is already connected to the
+// seed, then only new feeds subscribed
+func (n *Node) connectDNSSeed(ds dnsSeed) (err error) {
+
+	var c *Conn
+	if c, err = n.TCP().Connect(ds.address); err != nil {
+		return
+	}
+
+	n.Debugf(DiscoveryPin, "[dns-seed] connected to %s", ds.address)
+
+	var has = make(map[cipher.PubKey]struct{})
+
+	for _, feed := range c.Feeds() {
+		has[feed] = struct{}{}
+	}
+
+	for _, feed := range ds.feeds {
+
+		if _, ok := has[feed]; ok == true {
+			continue // already subscribed
+		}
+
+		if err = c.Subscribe(feed); err != nil {
+			return fmt.Errorf("can't subscribe to %s: %w", feed.Hex()[:7], err)
+		}
+
+	}
+
+	return
+}
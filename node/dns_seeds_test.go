@@ -0,0 +1,136 @@
+package node
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_parseDNSSeed(t *testing.T) {
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	var ds, ok, err = parseDNSSeed("v=spf1 -all")
+	assertNil(t, err)
+	assertTrue(t, ok == false, "not a seed")
+
+	ds, ok, err = parseDNSSeed("cxo-seed=127.0.0.1:8870 " + pk.Hex())
+	assertNil(t, err)
+	assertTrue(t, ok == true, "a seed")
+	assertTrue(t, ds.address == "127.0.0.1:8870", "wrong address")
+	assertTrue(t, len(ds.feeds) == 1 && ds.feeds[0] == pk, "wrong feeds")
+
+	_, _, err = parseDNSSeed("cxo-seed=")
+	assertTrue(t, err != nil, "missing error")
+
+	_, _, err = parseDNSSeed("cxo-seed=127.0.0.1:8870 xyz")
+	assertTrue(t, err != nil, "missing error")
+
+}
+
+func TestNode_dnsSeeds(t *testing.T) {
+
+	var sn = getTestNode("seed")
+	defer sn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(pk))
+
+	var seedTXT, seedSRV = lookupTXT, lookupSRV
+	defer func() { lookupTXT, lookupSRV = seedTXT, seedSRV }()
+
+	lookupTXT = func(domain string) ([]string, error) {
+		if domain != "seeds.example.com" {
+			return nil, errors.New("no such host")
+		}
+		return []string{
+			"v=spf1 -all",
+			"cxo-seed=" + sn.TCP().Address() + " " + pk.Hex(),
+		}, nil
+	}
+
+	lookupSRV = func(_, _, _ string) (string, []*net.SRV, error) {
+		return "", nil, errors.New("no such host")
+	}
+
+	var conf = getTestConfigNotListen("client")
+	conf.DNSSeeds = Addresses{"seeds.example.com"}
+
+	var cn, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	for i := 0; i < 100; i++ {
+		if cs := cn.ConnectionsOfFeed(pk); len(cs) == 1 {
+			assertTrue(t, cn.IsSharing(pk) == true, "not sharing the feed")
+			return // ok
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Error("not subscribed to the feed")
+
+}
+
+func TestNode_dnsSeedsRefresh(t *testing.T) {
+
+	var sn = getTestNode("seed")
+	defer sn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(pk))
+
+	var seedTXT, seedSRV = lookupTXT, lookupSRV
+	defer func() { lookupTXT, lookupSRV = seedTXT, seedSRV }()
+
+	var (
+		mx        sync.Mutex
+		published bool // not published on start
+	)
+
+	lookupTXT = func(domain string) ([]string, error) {
+		mx.Lock()
+		defer mx.Unlock()
+		if published == false {
+			return nil, errors.New("no such host")
+		}
+		return []string{"cxo-seed=" + sn.TCP().Address() + " " + pk.Hex()}, nil
+	}
+
+	lookupSRV = func(_, _, _ string) (string, []*net.SRV, error) {
+		return "", nil, errors.New("no such host")
+	}
+
+	var conf = getTestConfigNotListen("client")
+	conf.DNSSeeds = Addresses{"seeds.example.com"}
+	conf.DNSSeedsRefresh = 50 * time.Millisecond
+
+	var cn, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	assertTrue(t, len(cn.Connections()) == 0, "unexpected connection")
+
+	mx.Lock()
+	published = true
+	mx.Unlock()
+
+	for i := 0; i < 100; i++ {
+		if cs := cn.ConnectionsOfFeed(pk); len(cs) == 1 {
+			return // ok
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Error("not connected to the seed after refresh")
+
+}
@@ -0,0 +1,611 @@
+package node
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"golang.org/x/crypto/nacl/secretbox"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A FeedKey is secret key of an encrypted feed
+// (see Config.FeedKeys). Members of the feed share
+// the key out of band
+type FeedKey [32]byte
+
+// NewFeedKey creates random FeedKey
+func NewFeedKey() (fk FeedKey) {
+	if _, err := io.ReadFull(rand.Reader, fk[:]); err != nil {
+		panic("can't read random key: " + err.Error())
+	}
+	return
+}
+
+// Hex encoded FeedKey
+func (f FeedKey) Hex() string {
+	return hex.EncodeToString(f[:])
+}
+
+// FeedKeyFromHex decodes hex-encoded FeedKey
+func FeedKeyFromHex(h string) (fk FeedKey, err error) {
+	var p []byte
+	if p, err = hex.DecodeString(h); err != nil {
+		return
+	}
+	if len(p) != len(fk) {
+		return fk, fmt.Errorf("invalid feed key length %d", len(p))
+	}
+	copy(fk[:], p)
+	return
+}
+
+// is messages of given feed encrypted (see
+// Config.EncryptFeeds and Config.FeedKeys)
+func (n *Node) isEncrypted(feed cipher.PubKey) (ok bool) {
+	for _, pk := range n.config.EncryptFeeds {
+		if pk == feed {
+			return true
+		}
+	}
+	_, ok = n.config.FeedKeys[feed]
+	return
+}
+
+// key of given encrypted feed, or nil
+func (n *Node) feedKey(feed cipher.PubKey) (key *[32]byte) {
+	if fk, ok := n.config.FeedKeys[feed]; ok == true {
+		key = new([32]byte)
+		*key = fk
+	}
+	return
+}
+
+// is the Node relay of given feed: the feed is
+// encrypted, but the Node doesn't have its key
+func (n *Node) isRelayed(feed cipher.PubKey) bool {
+	return n.isEncrypted(feed) == true && n.feedKey(feed) == nil
+}
+
+// seal given value of given feed by key of the feed:
+// [24 nonce][secretbox]; values of not encrypted feeds
+// are not changed; it returns false if the feed is
+// relayed, since the relay must not send plain values
+func (n *Node) sealValue(
+	feed cipher.PubKey, // : the feed
+	val []byte, //         : the value
+) (
+	sealed []byte, //      : sealed value
+	ok bool, //            : false if relayed
+) {
+
+	if n.isEncrypted(feed) == false {
+		return val, true
+	}
+
+	var key = n.feedKey(feed)
+
+	if key == nil {
+		return // relay
+	}
+
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		panic("can't read random nonce: " + err.Error())
+	}
+
+	return secretbox.Seal(nonce[:], val, &nonce, key), true
+}
+
+// open value sealed by the sealValue
+func (n *Node) openValue(
+	feed cipher.PubKey, // : the feed
+	sealed []byte, //      : sealed value
+) (
+	val []byte, //         : the value
+	err error, //          : an error
+) {
+
+	var key = n.feedKey(feed)
+
+	if key == nil {
+		return nil, ErrNoFeedKey
+	}
+
+	if len(sealed) < 24+secretbox.Overhead {
+		return nil, errors.New("sealed value is too short")
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], sealed)
+
+	var ok bool
+	if val, ok = secretbox.Open(nil, sealed[24:], &nonce, key); ok == false {
+		return nil, errors.New("can't decrypt sealed value")
+	}
+
+	return
+}
+
+// open received Root and objects of RootDelta in
+// place, if the feed of the Root is encrypted
+func (n *Node) openRoot(root *msg.Root, objects [][]byte) (err error) {
+
+	if n.isEncrypted(root.Feed) == false {
+		return
+	}
+
+	if root.Value, err = n.openValue(root.Feed, root.Value); err != nil {
+		return fmt.Errorf("sealed Root %s/%d/%d: %v", root.Feed.Hex()[:7],
+			root.Nonce, root.Seq, err)
+	}
+
+	for i, val := range objects {
+		if objects[i], err = n.openValue(root.Feed, val); err != nil {
+			return fmt.Errorf("sealed object of RootDelta %s/%d/%d: %v",
+				root.Feed.Hex()[:7], root.Nonce, root.Seq, err)
+		}
+	}
+
+	return
+}
+
+// a head of a feed
+type feedHead struct {
+	pk    cipher.PubKey
+	nonce uint64
+}
+
+// objects of a Root
+type headObjects struct {
+	root    cipher.SHA256              // hash of the Root
+	partial bool                       // the Root is partial (see SetFilter)
+	set     map[cipher.SHA256]struct{} // objects of the Root
+}
+
+// objects of last Root objects of heads (see isFeedObject)
+type feedObjects struct {
+	mx   sync.Mutex
+	objs map[feedHead]*headObjects
+}
+
+func newFeedObjects() (f *feedObjects) {
+	f = new(feedObjects)
+	f.objs = make(map[feedHead]*headObjects)
+	return
+}
+
+// objects of given last Root of given head,
+// the f.mx must be locked
+func (f *feedObjects) objects(
+	n *Node, //             :
+	fh feedHead, //         :
+	r *registry.Root, //    :
+) (
+	objs *headObjects, //   :
+	err error, //           :
+) {
+
+	if objs = f.objs[fh]; objs != nil && objs.root == r.Hash {
+		return
+	}
+
+	objs = &headObjects{
+		root:    r.Hash,
+		partial: r.IsPartial,
+		set:     make(map[cipher.SHA256]struct{}),
+	}
+
+	var walk = func(key cipher.SHA256, _ int) (bool, error) {
+		if key != (cipher.SHA256{}) {
+			objs.set[key] = struct{}{}
+		}
+		return true, nil
+	}
+
+	if err = n.container(r.Pub).Walk(r, walk); err != nil {
+		return nil, err
+	}
+
+	f.objs[fh] = objs
+	return
+}
+
+// is given object reachable from last Root of
+// a head of given feed; older Root objects and
+// partial Root objects are not checked
+func (n *Node) isFeedObject(
+	pk cipher.PubKey, //   : the feed
+	key cipher.SHA256, //  : the object
+) (
+	ok bool, //            : found
+	err error, //          : an error
+) {
+	return n.hasFeedObject(pk, key, false)
+}
+
+// is given object reachable from last Root of a
+// head of given feed, if the Root is partial or
+// is not (see isFeedObject and isPartialObject)
+func (n *Node) hasFeedObject(
+	pk cipher.PubKey, //   : the feed
+	key cipher.SHA256, //  : the object
+	partial bool, //       : partial or full Root objects
+) (
+	ok bool, //            : found
+	err error, //          : an error
+) {
+
+	var (
+		cc    = n.container(pk)
+		heads []uint64
+	)
+
+	if heads, err = cc.Heads(pk); err != nil {
+		if err == data.ErrNoSuchFeed {
+			err = nil
+		}
+		return
+	}
+
+	n.fo.mx.Lock()
+	defer n.fo.mx.Unlock()
+
+	// forget removed heads
+
+	for fh := range n.fo.objs {
+		if fh.pk != pk {
+			continue
+		}
+		var found bool
+		for _, nonce := range heads {
+			if found = nonce == fh.nonce; found == true {
+				break
+			}
+		}
+		if found == false {
+			delete(n.fo.objs, fh)
+		}
+	}
+
+	for _, nonce := range heads {
+
+		var r *registry.Root
+		if r, err = cc.LastRoot(pk, nonce); err != nil {
+			err = nil
+			continue // blank head
+		}
+
+		if r.IsPartial != partial {
+			continue
+		}
+
+		var objs *headObjects
+		if objs, err = n.fo.objects(n, feedHead{pk, nonce}, r); err != nil {
+			return
+		}
+
+		if _, ok = objs.set[key]; ok == true {
+			return
+		}
+
+	}
+
+	return
+}
+
+// is given object of an encrypted feed and not of
+// a feed that isn't; such objects are never sent
+// unsealed (see msg.RqSealedObject)
+func (n *Node) isSealedObject(key cipher.SHA256) (sealed bool) {
+
+	if len(n.config.FeedKeys) == 0 {
+		return // relays never have objects of encrypted feeds
+	}
+
+	var feeds = n.Feeds()
+
+	for _, pk := range feeds {
+
+		if n.isEncrypted(pk) == false {
+			continue
+		}
+
+		var ok, err = n.isFeedObject(pk, key)
+
+		if err != nil {
+			n.Printf("[ERR] looking for %s in %s: %v", key.Hex()[:7],
+				pk.Hex()[:7], err)
+			return true // never send unsealed
+		}
+
+		if sealed = ok; sealed == true {
+			break
+		}
+
+	}
+
+	if sealed == false {
+		return
+	}
+
+	// the same object of a feed that isn't encrypted
+
+	for _, pk := range feeds {
+
+		if n.isEncrypted(pk) == true || n.lf.has(pk) == true {
+			continue
+		}
+
+		if ok, err := n.isFeedObject(pk, key); err == nil && ok == true {
+			return false
+		}
+
+	}
+
+	return
+}
+
+// derive key shared with remote peer, the peer can
+// decrypt messages only if it has secret key of its
+// id; the deriveKey called by handshake
+func (c *Conn) deriveKey() {
+
+	if c.HasFeature(msg.FeatureEncryption) == false {
+		return
+	}
+
+	if c.peerID.Verify() != nil {
+		c.features &^= msg.FeatureEncryption // can't encrypt
+		return
+	}
+
+	var shared = cipher.ECDH(c.peerID, c.n.idsk) // hashed
+
+	c.key = new([32]byte)
+	copy(c.key[:], shared)
+	c.rkey = c.key // the same for both directions
+}
+
+// can the Conn encrypt messages
+func (c *Conn) canSeal() bool {
+	return c.key != nil
+}
+
+// encrypt given message, the Conn must be able to encrypt
+func (c *Conn) seal(m msg.Msg) (s *msg.Sealed) {
+
+	s = new(msg.Sealed)
+
+	if _, err := io.ReadFull(rand.Reader, s.Nonce[:]); err != nil {
+		panic("can't read random nonce: " + err.Error())
+	}
+
+	s.Box = secretbox.Seal(nil, c.codec().Encode(m), &s.Nonce, c.key)
+	return
+}
+
+// decrypt received message
+func (c *Conn) open(s *msg.Sealed) (m msg.Msg, err error) {
+
+	if c.canSeal() == false {
+		return nil, errors.New("unexpected Sealed message")
+	}
+
+	var p, ok = secretbox.Open(nil, s.Box, &s.Nonce, c.rkey)
+
+	if ok == false {
+		return nil, errors.New("can't decrypt Sealed message")
+	}
+
+	if m, err = c.decode(p); err != nil {
+		return
+	}
+
+	if _, ok = m.(*msg.Sealed); ok == true {
+		return nil, errors.New("Sealed message in Sealed message")
+	}
+
+	return
+}
+
+// remember received encrypted request, to
+// encrypt reply for it
+func (c *Conn) addSealedRequest(seq uint32) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.sealed == nil {
+		c.sealed = make(map[uint32]struct{})
+	}
+
+	c.sealed[seq] = struct{}{}
+}
+
+// is given request received encrypted, the
+// method forgets the request
+func (c *Conn) takeSealedRequest(seq uint32) (ok bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if _, ok = c.sealed[seq]; ok == true {
+		delete(c.sealed, seq)
+	}
+
+	return
+}
+
+// pushed messages have no replies
+func isPush(m msg.Msg) bool {
+	switch m.(type) {
+	case *msg.Root, *msg.RootDelta, *msg.RootDone, *msg.WantFeeds,
+		*msg.Unsub, *msg.Credit, *msg.RootReject:
+		return true
+	}
+	return false
+}
+
+// send pushed message of given feed, encrypted
+// if messages of the feed are; if the Conn can't
+// encrypt, then the message is not sent; Root
+// objects are sent if the peer grants credits
+// (see credit.go)
+func (c *Conn) sendFeedMsg(feed cipher.PubKey, m msg.Msg) {
+
+	if m = c.takeCredit(feed, m); m == nil {
+		return // waits for credits
+	}
+
+	c.sendFeed(feed, m)
+}
+
+// send pushed message regardless credits
+func (c *Conn) sendFeed(feed cipher.PubKey, m msg.Msg) {
+
+	if c.n.isEncrypted(feed) == false {
+		c.sendMsg(c.nextSeq(), 0, m)
+		return
+	}
+
+	if c.canSeal() == false {
+		return // never send unencrypted
+	}
+
+	if m = c.hookSend(m); m == nil {
+		return // dropped
+	}
+
+	c.n.Debugf(MsgSendPin, "[%s] send %T (encrypted)", c.String(), m)
+
+	// class of the message, not the Sealed
+	c.enqueue(c.sendClass(m), c.encodeMsg(c.nextSeq(), 0, c.seal(m)))
+}
+
+// sendSealedRequest is like the sendRequest,
+// but the request and the reply are encrypted
+func (c *Conn) sendSealedRequest(
+	m msg.Msg, //              : the request
+	cancel <-chan struct{}, // : cancel the request
+) (
+	reply msg.Msg, //          : reply
+	err error, //              : an error
+) {
+
+	if c.canSeal() == false {
+		return nil, ErrNotSupported
+	}
+
+	if m = c.hookSend(m); m == nil {
+		return nil, ErrDropped
+	}
+
+	return c.sendRequestCancel(c.seal(m), c.responseTimeout(), cancel)
+}
+
+// send request for objects of given feed,
+// encrypted if messages of the feed are;
+// objects of encrypted feed are requested
+// by the RqSealedObject and replies are
+// opened by key of the feed; the cancel
+// can be nil (see cancel.go)
+func (c *Conn) sendFeedRequest(
+	feed cipher.PubKey, //     : the feed
+	m msg.Msg, //              : the request
+	cancel <-chan struct{}, // : cancel the request
+) (
+	reply msg.Msg, //          : reply
+	err error, //              : an error
+) {
+
+	if c.n.isEncrypted(feed) == false {
+		return c.sendRequestCancel(m, c.responseTimeout(), cancel)
+	}
+
+	if c.n.feedKey(feed) == nil {
+		return nil, ErrNoFeedKey // relay can't read the feed
+	}
+
+	if rq, ok := m.(*msg.RqObject); ok == true {
+		m = &msg.RqSealedObject{Feed: feed, Key: rq.Key}
+	}
+
+	if reply, err = c.sendSealedRequest(m, cancel); err != nil {
+		return
+	}
+
+	switch x := reply.(type) {
+	case *msg.Object:
+		x.Value, err = c.n.openValue(feed, x.Value)
+	case *msg.Root:
+		err = c.n.openRoot(x, nil)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// <- RqSealedObject (feed, key), async
+func (c *Conn) handleRqSealedObject(
+	seq uint32, //               : seq of the request
+	rq *msg.RqSealedObject, //   : the request
+	canceled <-chan struct{}, // : see cancel.go
+) {
+	defer c.await.Done()
+	defer c.delCancelable(seq)
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqSealedObject %s %s", c.String(),
+		rq.Feed.Hex()[:7], rq.Key.Hex()[:7])
+
+	switch {
+	case c.canSeal() == false:
+		c.sendErr(seq, ErrEncryptionRequired)
+		return
+	case c.n.isEncrypted(rq.Feed) == false,
+		c.n.lf.has(rq.Feed) == true,
+		c.n.fs.hasFeed(rq.Feed) == false:
+
+		c.sendErr(seq, ErrFeedNotServed)
+		return
+	case c.n.isRelayed(rq.Feed) == true:
+		c.relayObject(seq, rq, canceled)
+		return
+	}
+
+	// the object must be of the feed, otherwise a member
+	// of the feed can read objects of another feed
+
+	var ok, err = c.n.isFeedObject(rq.Feed, rq.Key)
+
+	if err != nil {
+		c.n.Printf("[ERR] [%s] looking for %s in %s: %v", c.String(),
+			rq.Key.Hex()[:7], rq.Feed.Hex()[:7], err)
+	}
+
+	var val []byte
+
+	if ok == true {
+		if val, _, err = c.n.container(rq.Feed).Get(rq.Key, 0); err != nil &&
+			err != data.ErrNotFound {
+
+			c.n.Fatal("DB failure: ", err)
+		}
+	}
+
+	if val == nil {
+		c.replyNotFound(seq, rq.Key)
+		return
+	}
+
+	val, _ = c.n.sealValue(rq.Feed, val) // not relayed
+	c.sendObject(seq, rq.Key, val)
+}
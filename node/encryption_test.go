@@ -0,0 +1,194 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestConn_encryption(t *testing.T) {
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+	)
+
+	var fk = NewFeedKey()
+
+	sconf.FeedKeys = FeedKeys{pk: fk}
+	cconf.FeedKeys = FeedKeys{pk: fk}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	assertNil(t, sn.Share(pk))
+
+	var up *skyobject.Unpack
+	if up, err = sn.Container().Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}))
+
+	assertNil(t, sn.Container().Save(up, r))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, c.canSeal() == true, "can't encrypt")
+
+	// the same session keys on both sides
+
+	var sc *Conn
+	var ok bool
+	for i := 0; i < 100 && ok == false; i++ {
+		if sc, ok = sn.hasPeer(cn.ID()); ok == false {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	assertTrue(t, ok == true, "missing incoming connection")
+	assertTrue(t, *sc.rkey == *c.key, "different keys")
+
+	// seal and open
+
+	var sm = c.seal(&msg.RqObject{Key: r.Hash})
+
+	var m msg.Msg
+	m, err = sc.open(sm)
+	assertNil(t, err)
+
+	if rq, ok := m.(*msg.RqObject); ok == false || rq.Key != r.Hash {
+		t.Error("wrong decrypted message")
+	}
+
+	sm.Box[0]++ // corrupt
+
+	if _, err = sc.open(sm); err == nil {
+		t.Error("missing error")
+	}
+
+	// encrypted request and reply
+
+	var key = r.Refs[0].Hash
+	var reply msg.Msg
+	reply, err = c.sendFeedRequest(pk, &msg.RqObject{Key: key}, nil)
+	assertNil(t, err)
+
+	if obj, ok := reply.(*msg.Object); ok == false || obj.Key != key ||
+		cipher.SumSHA256(obj.Value) != key {
+
+		t.Errorf("wrong reply %T", reply)
+	}
+
+	sc.mx.Lock()
+	assertTrue(t, len(sc.sealed) == 0, "encrypted request is not forgotten")
+	sc.mx.Unlock()
+
+	// never sent unsealed
+
+	reply, err = c.sendRequest(&msg.RqObject{Key: key})
+	assertNil(t, err)
+
+	if er, ok := reply.(*msg.Err); ok == false ||
+		er.Err != ErrEncryptionRequired.Error() {
+
+		t.Errorf("wrong reply %T", reply)
+	}
+
+	// an object of another feed
+
+	reply, err = c.sendFeedRequest(pk, &msg.RqObject{
+		Key: cipher.SumSHA256([]byte("another")),
+	}, nil)
+	assertNil(t, err)
+
+	if _, ok := reply.(*msg.NotFound); ok == false {
+		t.Errorf("wrong reply %T", reply)
+	}
+
+	// subscription
+
+	assertNil(t, c.Subscribe(pk))
+
+}
+
+func TestFeedKeys_Set(t *testing.T) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		fk    = NewFeedKey()
+
+		fks FeedKeys
+	)
+
+	assertNil(t, fks.Set(pk.Hex()+":"+fk.Hex()))
+	assertTrue(t, fks[pk] == fk, "wrong key")
+
+	assertTrue(t, fks.Set(pk.Hex()) != nil, "missing error")
+	assertTrue(t, fks.Set(pk.Hex()+":00") != nil, "missing error")
+
+}
+
+func TestNode_sealValue(t *testing.T) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		conf  = NewConfig()
+		n     = &Node{config: conf}
+		val   = []byte("value")
+	)
+
+	// not encrypted
+
+	var sealed, ok = n.sealValue(pk, val)
+	assertTrue(t, ok == true && string(sealed) == string(val), "sealed")
+
+	// relay
+
+	conf.EncryptFeeds = Feeds{pk}
+
+	_, ok = n.sealValue(pk, val)
+	assertTrue(t, ok == false, "relay seals")
+
+	var err error
+	_, err = n.openValue(pk, val)
+	assertTrue(t, err == ErrNoFeedKey, "wrong error")
+
+	// member
+
+	conf.FeedKeys = FeedKeys{pk: NewFeedKey()}
+
+	sealed, ok = n.sealValue(pk, val)
+	assertTrue(t, ok == true && string(sealed) != string(val), "not sealed")
+
+	var opened []byte
+	opened, err = n.openValue(pk, sealed)
+	assertNil(t, err)
+	assertTrue(t, string(opened) == string(val), "wrong value")
+
+	sealed[len(sealed)-1]++ // corrupt
+
+	_, err = n.openValue(pk, sealed)
+	assertTrue(t, err != nil, "missing error")
+
+}
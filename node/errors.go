@@ -2,6 +2,7 @@ package node
 
 import (
 	"errors"
+	"fmt"
 )
 
 // common errors
@@ -9,6 +10,7 @@ var (
 	ErrAlreadyListen           = errors.New("already listen")
 	ErrTimeout                 = errors.New("timeout")
 	ErrClosed                  = errors.New("closed")
+	ErrCanceled                = errors.New("canceled")
 	ErrNotPublic               = errors.New("not a public server")
 	ErrAlreadyHaveConnection   = errors.New("already have connection")
 	ErrInvalidResponse         = errors.New("invalid response")
@@ -16,4 +18,83 @@ var (
 	ErrMaxHeadsLimit           = errors.New("max heads limit")
 	ErrUnsubscribe             = errors.New("unsubscribe")
 	ErrBlankFeed               = errors.New("blank feed")
+	ErrFeedNotServed           = errors.New("feed is not served")
+	ErrLeaseTooLong            = errors.New("subscription lease is too long")
+	ErrLeaseRequired           = errors.New("subscription lease required")
+	ErrLocalFeed               = errors.New("feed is local-only")
+	ErrDecompressedTooLarge    = errors.New("decompressed message is too large")
+	ErrIncompatibleVersion     = errors.New("incompatible protocol version")
+	ErrNotSupported            = errors.New("not supported by remote peer")
+	ErrEncryptionRequired      = errors.New("feed requires encryption")
+	ErrNoFeedKey               = errors.New("no key of encrypted feed")
+	ErrDropped                 = errors.New("message dropped by hook")
+	ErrSendQueueFull           = errors.New("send queue is full")
+	ErrUnknownRoot             = errors.New("unknown Root")
+	ErrTLSPin                  = errors.New("TLS certificate doesn't match pin")
+	ErrNoiseAuth               = errors.New("Noise handshake authentication failed")
+	ErrNoiseRequired           = errors.New("Noise handshake required")
+	ErrProxyScheme             = errors.New("unsupported proxy scheme")
+	ErrProxy                   = errors.New("proxy refused connection")
+	ErrNotRendezvous           = errors.New("not a rendezvous")
+	ErrPunchNotAllowed         = errors.New("punching is not allowed")
+	ErrRendezvousRate          = errors.New("too many rendezvous requests")
+	ErrNoSuchPeer              = errors.New("no such peer")
+	ErrKCPNotListening         = errors.New("KCP is not listening")
+	ErrUnexpectedPeer          = errors.New("unexpected peer")
+	ErrNoTor                   = errors.New("no Tor to connect to .onion address")
+	ErrMaxConnections          = errors.New("max connections limit")
+	ErrMaxInbound              = errors.New("max inbound connections limit")
+	ErrMaxOutboundPerFeed      = errors.New("max outbound connections per feed limit")
+	ErrEvicted                 = errors.New("evicted by connection limits")
+	ErrBanned                  = errors.New("banned")
+	ErrNotAllowed              = errors.New("peer is not allowed")
+	ErrClusterContainer        = errors.New("member of Cluster requires NewNodeContainer")
+	ErrChunksLimit             = errors.New("too many objects being received by chunks")
+
+	ErrNoSuchTenant        = errors.New("no such tenant")
+	ErrTenantExists        = errors.New("tenant already exists")
+	ErrTenantQuota         = errors.New("tenant quota exceeded")
+	ErrTenantACL           = errors.New("not allowed by tenant ACL")
+	ErrFeedOfAnotherTenant = errors.New("feed belongs to another tenant")
+	ErrTenantStorage       = errors.New("feed is shared outside of tenant storage")
+
+	ErrInvalidSecretKey = errors.New("secret key doesn't match the feed")
+	ErrNoChallenge      = errors.New("no challenge for the proof")
+	ErrInvalidProof     = errors.New("invalid ownership proof")
 )
+
+// errors that can be sent to remote peer
+// and restored by the remoteError
+var remoteErrors = []error{
+	ErrIncompatibleVersion,
+	ErrEncryptionRequired,
+	ErrNotPublic,
+	ErrFeedNotServed,
+	ErrLeaseTooLong,
+	ErrLeaseRequired,
+	ErrTenantACL,
+	ErrNoChallenge,
+	ErrInvalidProof,
+	ErrTLSPin,
+	ErrNoiseAuth,
+	ErrNoiseRequired,
+	ErrNotRendezvous,
+	ErrPunchNotAllowed,
+	ErrRendezvousRate,
+	ErrNoSuchPeer,
+	ErrKCPNotListening,
+	ErrNotSupported,
+	ErrChunksLimit,
+}
+
+// remoteError returns error received from remote peer
+// wrapping known error, thus errors.Is can be used
+// to check it out
+func remoteError(s string) (err error) {
+	for _, known := range remoteErrors {
+		if known.Error() == s {
+			return fmt.Errorf("remote: %w", known)
+		}
+	}
+	return errors.New("remote: " + s)
+}
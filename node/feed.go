@@ -2,6 +2,8 @@ package node
 
 import (
 	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
 )
 
 // feed of the Node
@@ -111,6 +113,14 @@ func (n *nodeFeed) broadcastRoot(cr connRoot) {
 			continue
 		}
 
+		// a filtered peer never gets objects it doesn't want
+		if cr.delta != nil && c.HasFeature(msg.FeatureRootDelta) == true &&
+			c.isRemoteFiltered(cr.r.Pub) == false {
+
+			c.sendRootDelta(cr.r, cr.delta)
+			continue
+		}
+
 		c.sendRoot(cr.r)
 	}
 
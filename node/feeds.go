@@ -12,6 +12,8 @@ import (
 type connRoot struct {
 	c *Conn
 	r *registry.Root
+
+	delta map[cipher.SHA256][]byte // new objects (see RootDelta)
 }
 
 // connection and feed
@@ -37,7 +39,8 @@ type nodeFeeds struct {
 
 	delcq chan *Conn // delete connection (closed connection and similar)
 
-	brorq chan connRoot // broadcast root to feed (triggered by head)
+	brorq chan connRoot       // broadcast root to feed (triggered by head)
+	brodq chan *registry.Root // broadcast RootDone (triggered by head)
 
 	// info api
 
@@ -87,6 +90,7 @@ func newNodeFeeds(node *Node) (n *nodeFeeds) {
 	n.delcq = make(chan *Conn)
 
 	n.brorq = make(chan connRoot)
+	n.brodq = make(chan *registry.Root)
 
 	// info api
 
@@ -145,6 +149,7 @@ func (n *nodeFeeds) handle() {
 		delcfq = n.delcfq
 		delcq  = n.delcq
 		broq   = n.brorq
+		brodq  = n.brodq
 
 		listrq  = n.listrq
 		fcrq    = n.fcrq
@@ -158,6 +163,7 @@ func (n *nodeFeeds) handle() {
 
 		pk cipher.PubKey
 		cr connRoot
+		r  *registry.Root
 		cf connFeed
 		c  *Conn
 	)
@@ -178,6 +184,9 @@ func (n *nodeFeeds) handle() {
 		case cr = <-broq:
 			n.handleBroadcastRoot(cr)
 
+		case r = <-brodq:
+			n.handleBroadcastRootDone(r)
+
 		case pk = <-addq:
 			n.handleAddFeed(pk)
 
@@ -386,6 +395,33 @@ func (n *nodeFeeds) handleBroadcastRoot(cr connRoot) {
 	}
 
 	nf.broadcastRoot(cr)
+
+	if cr.c == nil {
+		n.n.gossip(cr.r, nf) // published
+	}
+}
+
+// (bubbling api)
+func (n *nodeFeeds) broadcastRootDone(r *registry.Root) {
+
+	select {
+	case n.brodq <- r:
+	case <-n.closeq:
+	}
+
+}
+
+// (handler)
+func (n *nodeFeeds) handleBroadcastRootDone(r *registry.Root) {
+
+	var nf, ok = n.fs[r.Pub]
+
+	if ok == false {
+		return
+	}
+
+	nf.broadcastRootDone(r)
+	n.n.gossip(r, nf) // filled
 }
 
 // (api)
@@ -406,10 +442,10 @@ func (n *nodeFeeds) handleDelConn(c *Conn) {
 }
 
 // (api)
-func (n *nodeFeeds) receivedRoot(c *Conn, r *registry.Root) {
+func (n *nodeFeeds) receivedRoot(cr connRoot) {
 
 	select {
-	case n.rrq <- connRoot{c, r}:
+	case n.rrq <- cr:
 	case <-n.closeq:
 	}
 
@@ -0,0 +1,191 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// schema filters of feeds (see SetFilter)
+type feedFilters struct {
+	mx sync.Mutex
+	fs map[cipher.PubKey][]string
+}
+
+func newFeedFilters() (f *feedFilters) {
+	f = new(feedFilters)
+	f.fs = make(map[cipher.PubKey][]string)
+	return
+}
+
+// set filter of given feed, it returns false
+// if the filter is the same
+func (f *feedFilters) set(
+	feed cipher.PubKey, // : the feed
+	schemas []string, //   : the filter
+) (
+	changed bool, //       : the filter has been changed
+) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if changed = sameSchemas(f.fs[feed], schemas) == false; changed == false {
+		return
+	}
+
+	if len(schemas) == 0 {
+		delete(f.fs, feed)
+		return
+	}
+
+	f.fs[feed] = append([]string{}, schemas...) // copy
+	return
+}
+
+func (f *feedFilters) get(feed cipher.PubKey) (schemas []string) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return f.fs[feed]
+}
+
+// filtered feeds
+func (f *feedFilters) feeds() (feeds []cipher.PubKey) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	for pk := range f.fs {
+		feeds = append(feeds, pk)
+	}
+
+	return
+}
+
+// are given filters the same, the order matters not
+func sameSchemas(a, b []string) bool {
+
+	var as, bs = make(map[string]struct{}), make(map[string]struct{})
+
+	for _, name := range a {
+		as[name] = struct{}{}
+	}
+
+	for _, name := range b {
+		if _, ok := as[name]; ok == false {
+			return false
+		}
+		bs[name] = struct{}{}
+	}
+
+	return len(as) == len(bs)
+}
+
+// SetFilter makes the Node a light subscriber of given
+// feed. The Node fills Root objects of the feed requesting
+// objects of given schemas only. Objects of other registered
+// schemas and their subtrees are not requested. For example,
+// a forum client can fill thread heads without bodies of
+// posts. The schemas must include schemas of objects on
+// the way to wanted ones. Call the SetFilter without schemas
+// to remove the filter.
+//
+// Filled Root objects of a filtered feed are partial (see
+// registry.Root.IsPartial). The Node never announces them,
+// never sends them to other peers and never sends objects
+// of them. Subscribers of the feed get new Root objects
+// from other peers.
+//
+// If the filter is changed, then the Node removes partial
+// Root objects of the feed and fills last of them again
+// using new filter. Subscription requests carry the filter,
+// and remote peers, that support it, never send RootDelta
+// messages to the Node (see msg.SubFilter)
+func (n *Node) SetFilter(feed cipher.PubKey, schemas ...string) (err error) {
+
+	if feed == (cipher.PubKey{}) {
+		return ErrBlankFeed
+	}
+
+	if n.ff.set(feed, schemas) == false {
+		return // the same
+	}
+
+	var rs []*registry.Root
+	if rs, err = n.container(feed).DelPartialRoots(feed); err != nil {
+		if err != data.ErrNoSuchFeed {
+			return
+		}
+		err = nil
+	}
+
+	var cs = n.fs.connectionsOfFeed(feed)
+
+	for _, c := range cs {
+		c.await.Add(1)
+		go c.resubscribe(feed) // send the filter
+	}
+
+	for _, r := range rs {
+		for _, c := range cs {
+			n.fs.receivedRoot(connRoot{c: c, r: r}) // fill again
+		}
+	}
+
+	return
+}
+
+// Filter returns schema filter of given feed,
+// or nil if the feed is not filtered (see SetFilter)
+func (n *Node) Filter(feed cipher.PubKey) (schemas []string) {
+	return append([]string(nil), n.ff.get(feed)...)
+}
+
+// is given object of partial Root objects only, such
+// objects are never sent to peers (see SetFilter)
+func (n *Node) isPartialObject(key cipher.SHA256) (partial bool) {
+
+	var filtered = n.ff.feeds()
+
+	if len(filtered) == 0 {
+		return // no partial Root objects
+	}
+
+	for _, pk := range filtered {
+
+		var ok, err = n.hasFeedObject(pk, key, true)
+
+		if err != nil {
+			n.Printf("[ERR] looking for %s in %s: %v", key.Hex()[:7],
+				pk.Hex()[:7], err)
+			return true // never send
+		}
+
+		if partial = ok; partial == true {
+			break
+		}
+
+	}
+
+	if partial == false {
+		return
+	}
+
+	// the same object of a full Root
+
+	for _, pk := range n.Feeds() {
+
+		if n.isEncrypted(pk) == true || n.lf.has(pk) == true {
+			continue
+		}
+
+		if ok, err := n.isFeedObject(pk, key); err == nil && ok == true {
+			return false
+		}
+
+	}
+
+	return
+}
@@ -0,0 +1,176 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_SetFilter(t *testing.T) {
+
+	var n = getTestNodeNotListen("light")
+	defer n.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	if err := n.SetFilter(cipher.PubKey{}, "test.User"); err != ErrBlankFeed {
+		t.Errorf("wrong error: want %v, got %v", ErrBlankFeed, err)
+	}
+
+	if n.Filter(pk) != nil {
+		t.Error("unexpected filter")
+	}
+
+	assertNil(t, n.SetFilter(pk, "test.Feed", "test.User"))
+
+	var schemas = n.Filter(pk)
+
+	if len(schemas) != 2 || schemas[0] != "test.Feed" ||
+		schemas[1] != "test.User" {
+
+		t.Errorf("wrong filter: %v", schemas)
+	}
+
+	schemas[0] = "test.Post" // copy
+
+	if n.Filter(pk)[0] != "test.Feed" {
+		t.Error("filter is not copied")
+	}
+
+	assertNil(t, n.SetFilter(pk)) // remove
+
+	if n.Filter(pk) != nil {
+		t.Error("filter is not removed")
+	}
+
+}
+
+func TestNode_SetFilter_partial(t *testing.T) {
+
+	var (
+		fr    = make(chan *registry.Root, 10)
+		done  = make(chan uint64, 10)
+		sconf = getTestConfig("publisher")
+		rconf = getTestConfigNotListen("light")
+	)
+
+	sconf.OnRootFilledRemote = func(_ *Conn, _ cipher.PubKey, _, seq uint64) {
+		done <- seq
+	}
+	rconf.OnRootFilled = func(_ *Node, r *registry.Root) { fr <- r }
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var rn *Node
+	if rn, err = NewNode(rconf); err != nil {
+		t.Fatal(err)
+	}
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.Share(pk))
+	assertNil(t, rn.SetFilter(pk, "test.Feed"))
+
+	var up *skyobject.Unpack
+	if up, err = sn.Container().Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		r    = &registry.Root{Pub: pk, Nonce: 1}
+		user = dynamicByValue(t, up, "test.User", User{"Alice", 19, nil})
+	)
+
+	r.Refs = append(r.Refs, user, dynamicByValue(t, up, "test.Feed", Feed{}))
+	assertNil(t, sn.Container().Save(up, r))
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+	assertNil(t, c.Subscribe(pk))
+
+	var cs = sn.ConnectionsOfFeed(pk)
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+
+	if schemas := cs[0].RemoteFilter(pk); len(schemas) != 1 ||
+		schemas[0] != "test.Feed" {
+
+		t.Errorf("wrong remote filter: %v", schemas)
+	}
+
+	cs[0].sendLastRoot(pk) // the Root can be received before the reply
+
+	var fill = func() (rr *registry.Root) {
+		t.Helper()
+		select {
+		case rr = <-fr:
+		case <-time.After(5 * time.Second):
+			t.Fatal("slow")
+		}
+		return
+	}
+
+	if rr := fill(); rr.IsPartial == false || rr.IsFull == true {
+		t.Error("not partial")
+	}
+
+	if _, _, err = rn.Container().Get(user.Hash, 0); err == nil {
+		t.Error("filtered out object received")
+	}
+
+	// partial Root is not reported and not served
+	select {
+	case seq := <-done:
+		t.Errorf("partial Root %d reported", seq)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if rn.isPartialObject(r.Refs[1].Hash) == false {
+		t.Error("object of partial Root can be sent")
+	}
+
+	// change the filter, the Root should be filled again
+	assertNil(t, rn.SetFilter(pk))
+
+	if rr := fill(); rr.IsFull == false || rr.IsPartial == true {
+		t.Error("not full")
+	}
+
+	if _, _, err = rn.Container().Get(user.Hash, 0); err != nil {
+		t.Error(err)
+	}
+
+	select {
+	case seq := <-done:
+		if seq != r.Seq {
+			t.Errorf("wrong Root reported: %d", seq)
+		}
+	case <-time.After(time.Second):
+		t.Error("full Root not reported")
+	}
+
+	if rn.isPartialObject(r.Refs[1].Hash) == true {
+		t.Error("object of full Root can't be sent")
+	}
+
+	for i := 0; cs[0].RemoteFilter(pk) != nil; i++ {
+		if i == 100 {
+			t.Fatal("remote filter is not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+}
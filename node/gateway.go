@@ -0,0 +1,404 @@
+package node
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// cache headers of the gateway
+const (
+	// an object never changes, since key of an object
+	// is hash of the object
+	gatewayObjectCacheControl = "public, max-age=31536000, immutable"
+	// the latest Root can be replaced with a newer one,
+	// thus it should be revalidated every time
+	gatewayRootCacheControl = "public, no-cache"
+)
+
+// read-only HTTP gateway that serves objects and
+// latest Root objects of public feeds the Node shares:
+//
+//     GET /objects/{hash}     - encoded object
+//     GET /feeds/{pk}/latest  - JSON with latest Root
+//     GET /feeds/{pk}/archive - archive of latest Root
+//
+// A feed is public if it's not encrypted and is not
+// local-only. The gateway serves only objects of latest
+// Root objects of public feeds
+type gatewayServer struct {
+	l net.Listener // underlying listener
+	s *http.Server //
+	n *Node        // back reference
+
+	mx   sync.Mutex                        // lock objs
+	objs map[cipher.PubKey]*gatewayObjects // objects of public feeds
+}
+
+// objects of a Root
+type gatewayObjects struct {
+	root cipher.SHA256              // hash of the Root
+	set  map[cipher.SHA256]struct{} // objects of the Root
+}
+
+// create the gateway
+func (n *Node) newGateway() (g *gatewayServer) {
+	g = new(gatewayServer)
+	g.n = n
+	g.objs = make(map[cipher.PubKey]*gatewayObjects)
+
+	var mux = http.NewServeMux()
+
+	mux.HandleFunc("/objects/", g.object)
+	mux.HandleFunc("/feeds/", g.feed)
+
+	g.s = &http.Server{Handler: mux}
+	return
+}
+
+func (g *gatewayServer) Listen(address string) (err error) {
+
+	if g.l, err = net.Listen("tcp", address); err != nil {
+		return
+	}
+
+	g.n.await.Add(1)
+	go g.run()
+
+	return
+}
+
+func (g *gatewayServer) run() {
+	defer g.n.await.Done()
+	g.s.Serve(g.l)
+}
+
+func (g *gatewayServer) Address() (address string) {
+	if g.l != nil {
+		address = g.l.Addr().String()
+	}
+	return
+}
+
+func (g *gatewayServer) Close() (err error) {
+	if g.l != nil {
+		err = g.s.Close()
+	}
+	return
+}
+
+// only GET and HEAD are allowed
+func gatewayMethodAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// set ETag and check If-None-Match, returns true
+// if the request is not modified
+func gatewayNotModified(
+	w http.ResponseWriter, // :
+	r *http.Request, //       :
+	hash cipher.SHA256, //    :
+) bool {
+
+	var etag = `"` + hash.Hex() + `"`
+
+	w.Header().Set("ETag", etag)
+
+	for _, inm := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if inm = strings.TrimSpace(inm); inm == etag || inm == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// GET /objects/{hash}
+func (g *gatewayServer) object(w http.ResponseWriter, r *http.Request) {
+
+	if gatewayMethodAllowed(w, r) == false {
+		return
+	}
+
+	var hash, err = cipher.SHA256FromHex(
+		strings.TrimPrefix(r.URL.Path, "/objects/"),
+	)
+
+	if err != nil {
+		http.Error(w, "invalid hash", http.StatusBadRequest)
+		return
+	}
+
+	var ok bool
+	if ok, err = g.isPublicObject(hash); err != nil {
+		g.n.Printf("[ERR] [gateway] looking for %s: %v", hash.Hex()[:7], err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	} else if ok == false {
+		http.NotFound(w, r)
+		return
+	}
+
+	var val []byte
+	if val, err = g.n.getObject(hash); err != nil {
+		if err == data.ErrNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		g.n.Printf("[ERR] [gateway] getting %s: %v", hash.Hex()[:7], err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", gatewayObjectCacheControl)
+
+	if gatewayNotModified(w, r, hash) == true {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(val)
+}
+
+// a gatewayRoot represents JSON of a Root
+type gatewayRoot struct {
+	Hash  string `json:"hash"`
+	Sig   string `json:"sig"`
+	Pub   string `json:"pub"`
+	Nonce uint64 `json:"nonce"`
+	Seq   uint64 `json:"seq"`
+	Time  int64  `json:"time"`
+	Prev  string `json:"prev"`
+	Reg   string `json:"reg"`
+}
+
+// GET /feeds/{pk}/latest and /feeds/{pk}/archive
+func (g *gatewayServer) feed(w http.ResponseWriter, r *http.Request) {
+
+	if gatewayMethodAllowed(w, r) == false {
+		return
+	}
+
+	var ss = strings.Split(strings.TrimPrefix(r.URL.Path, "/feeds/"), "/")
+
+	if len(ss) != 2 || (ss[1] != "latest" && ss[1] != "archive") {
+		http.NotFound(w, r)
+		return
+	}
+
+	var pk, err = cipher.PubKeyFromHex(ss[0])
+
+	if err != nil {
+		http.Error(w, "invalid public key", http.StatusBadRequest)
+		return
+	}
+
+	var root, rerr = g.lastRoot(pk)
+
+	if rerr != nil {
+		g.n.Printf("[ERR] [gateway] last Root of %s: %v", pk.Hex()[:7], rerr)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if root == nil {
+		http.NotFound(w, r) // not public or no Root objects
+		return
+	}
+
+	w.Header().Set("Cache-Control", gatewayRootCacheControl)
+
+	if ss[1] == "archive" {
+		g.archive(w, r, root)
+		return
+	}
+
+	g.latest(w, r, root)
+}
+
+// is given feed public
+func (g *gatewayServer) isPublic(pk cipher.PubKey) bool {
+	return g.n.IsSharing(pk) == true &&
+		g.n.isEncrypted(pk) == false &&
+		g.n.lf.has(pk) == false
+}
+
+// latest Root of given public feed, it returns nil
+// if the feed is not public or has no Root objects
+func (g *gatewayServer) lastRoot(
+	pk cipher.PubKey, //       :
+) (
+	root *registry.Root, //    :
+	err error, //              :
+) {
+
+	if g.isPublic(pk) == false {
+		return
+	}
+
+	var (
+		c     = g.n.container(pk)
+		nonce = c.ActiveHead(pk)
+	)
+
+	if nonce == 0 {
+		return // no Root objects
+	}
+
+	if root, err = c.LastRoot(pk, nonce); err != nil {
+		if err == data.ErrNotFound ||
+			err == data.ErrNoSuchFeed ||
+			err == data.ErrNoSuchHead {
+
+			return nil, nil
+		}
+	}
+
+	return
+}
+
+// is given object reachable from latest Root of a public feed
+func (g *gatewayServer) isPublicObject(
+	hash cipher.SHA256, // :
+) (
+	ok bool, //            :
+	err error, //          :
+) {
+
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	for pk := range g.objs {
+		if g.isPublic(pk) == false {
+			delete(g.objs, pk) // forget
+		}
+	}
+
+	for _, pk := range g.n.Feeds() {
+
+		var root *registry.Root
+		if root, err = g.lastRoot(pk); err != nil {
+			return
+		}
+
+		if root == nil {
+			delete(g.objs, pk)
+			continue
+		}
+
+		var objs *gatewayObjects
+		if objs, err = g.objects(pk, root); err != nil {
+			return
+		}
+
+		if _, ok = objs.set[hash]; ok == true {
+			return
+		}
+
+	}
+
+	return
+}
+
+// objects of given Root, the g.mx must be locked
+func (g *gatewayServer) objects(
+	pk cipher.PubKey, //        :
+	root *registry.Root, //     :
+) (
+	objs *gatewayObjects, //    :
+	err error, //               :
+) {
+
+	if objs = g.objs[pk]; objs != nil && objs.root == root.Hash {
+		return
+	}
+
+	objs = &gatewayObjects{
+		root: root.Hash,
+		set:  make(map[cipher.SHA256]struct{}),
+	}
+
+	var walk = func(hash cipher.SHA256, _ int) (bool, error) {
+		if hash != (cipher.SHA256{}) {
+			objs.set[hash] = struct{}{}
+		}
+		return true, nil
+	}
+
+	if err = g.n.container(root.Pub).Walk(root, walk); err != nil {
+		return nil, err
+	}
+
+	g.objs[pk] = objs
+	return
+}
+
+// GET /feeds/{pk}/archive
+func (g *gatewayServer) archive(
+	w http.ResponseWriter, // :
+	r *http.Request, //       :
+	root *registry.Root, //   :
+) {
+
+	if gatewayNotModified(w, r, root.Hash) == true {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if err := g.n.container(root.Pub).ExportRoot(w, root); err != nil {
+		g.n.Printf("[ERR] [gateway] archive of %s: %v", root.Short(), err)
+	}
+}
+
+// GET /feeds/{pk}/latest
+func (g *gatewayServer) latest(
+	w http.ResponseWriter, // :
+	r *http.Request, //       :
+	root *registry.Root, //   :
+) {
+
+	if gatewayNotModified(w, r, root.Hash) == true {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(gatewayRoot{
+		Hash:  root.Hash.Hex(),
+		Sig:   root.Sig.Hex(),
+		Pub:   root.Pub.Hex(),
+		Nonce: root.Nonce,
+		Seq:   root.Seq,
+		Time:  root.Time,
+		Prev:  root.Prev.Hex(),
+		Reg:   root.Reg.String(),
+	})
+}
+
+// GatewayAddress returns listening address of
+// the HTTP gateway. It returns empty string if
+// the gateway disabled (see Config.Gateway)
+func (n *Node) GatewayAddress() (address string) {
+	if n.gw != nil {
+		address = n.gw.Address()
+	}
+	return
+}
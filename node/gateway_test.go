@@ -0,0 +1,153 @@
+package node
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func gatewayGet(
+	t *testing.T,
+	url string,
+	etag string,
+) (
+	resp *http.Response,
+	body []byte,
+) {
+	t.Helper()
+
+	var req, err = http.NewRequest(http.MethodGet, url, nil)
+	assertNil(t, err)
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err = http.DefaultClient.Do(req)
+	assertNil(t, err)
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	assertNil(t, err)
+	return
+}
+
+func TestNode_GatewayAddress(t *testing.T) {
+
+	var (
+		conf     = getTestConfigNotListen("test")
+		epk, esk = cipher.GenerateKeyPair() // encrypted
+	)
+
+	conf.Gateway = "127.0.0.1:0"
+	conf.EncryptFeeds = Feeds{epk}
+
+	var n, err = NewNode(conf)
+	assertNil(t, err)
+	defer n.Close()
+
+	var address = "http://" + n.GatewayAddress()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, n.Share(pk))
+
+	// no Root objects yet
+
+	var resp, body = gatewayGet(t, address+"/feeds/"+pk.Hex()+"/latest", "")
+	assertTrue(t, resp.StatusCode == http.StatusNotFound, "wrong status")
+
+	// invalid hash
+
+	resp, _ = gatewayGet(t, address+"/objects/xxx", "")
+	assertTrue(t, resp.StatusCode == http.StatusBadRequest, "wrong status")
+
+	// publish
+
+	var (
+		c  = n.Container()
+		up *skyobject.Unpack
+	)
+
+	up, err = c.Unpack(sk, getTestRegistry())
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021
+	r.Pub = pk
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}))
+
+	assertNil(t, c.Save(up, r))
+
+	// object
+
+	var hash = r.Refs[0].Hash
+
+	resp, body = gatewayGet(t, address+"/objects/"+hash.Hex(), "")
+	assertTrue(t, resp.StatusCode == http.StatusOK, "wrong status")
+	assertTrue(t, cipher.SumSHA256(body) == hash, "wrong object")
+	assertTrue(t, resp.Header.Get("Cache-Control") == gatewayObjectCacheControl,
+		"wrong Cache-Control")
+
+	resp, _ = gatewayGet(t, address+"/objects/"+hash.Hex(),
+		resp.Header.Get("ETag"))
+	assertTrue(t, resp.StatusCode == http.StatusNotModified, "wrong status")
+
+	resp, _ = gatewayGet(t, address+"/objects/"+cipher.SHA256{}.Hex(), "")
+	assertTrue(t, resp.StatusCode == http.StatusNotFound, "wrong status")
+
+	// latest Root
+
+	resp, body = gatewayGet(t, address+"/feeds/"+pk.Hex()+"/latest", "")
+	assertTrue(t, resp.StatusCode == http.StatusOK, "wrong status")
+
+	var gr gatewayRoot
+	assertNil(t, json.Unmarshal(body, &gr))
+	assertTrue(t, gr.Hash == r.Hash.Hex(), "wrong hash")
+	assertTrue(t, gr.Seq == r.Seq, "wrong seq")
+
+	resp, _ = gatewayGet(t, address+"/feeds/"+pk.Hex()+"/latest",
+		resp.Header.Get("ETag"))
+	assertTrue(t, resp.StatusCode == http.StatusNotModified, "wrong status")
+
+	// encrypted
+
+	assertNil(t, n.Share(epk))
+
+	up, err = c.Unpack(esk, getTestRegistry())
+	assertNil(t, err)
+
+	var er = new(registry.Root)
+
+	er.Nonce = 9021
+	er.Pub = epk
+	er.Refs = append(er.Refs,
+		dynamicByValue(t, up, "test.User", User{"Eva", 21, nil}))
+
+	assertNil(t, c.Save(up, er))
+
+	resp, _ = gatewayGet(t, address+"/feeds/"+epk.Hex()+"/latest", "")
+	assertTrue(t, resp.StatusCode == http.StatusNotFound, "wrong status")
+
+	resp, _ = gatewayGet(t, address+"/feeds/"+epk.Hex()+"/archive", "")
+	assertTrue(t, resp.StatusCode == http.StatusNotFound, "wrong status")
+
+	resp, _ = gatewayGet(t, address+"/objects/"+er.Refs[0].Hash.Hex(), "")
+	assertTrue(t, resp.StatusCode == http.StatusNotFound, "wrong status")
+
+	// not allowed
+
+	resp, err = http.Post(address+"/objects/"+hash.Hex(), "", nil)
+	assertNil(t, err)
+	resp.Body.Close()
+	assertTrue(t, resp.StatusCode == http.StatusMethodNotAllowed,
+		"wrong status")
+
+}
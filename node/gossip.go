@@ -0,0 +1,174 @@
+package node
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// number of recent announcements the Node remembers
+// to don't forward the same announcement twice
+const gossipSeenSize = 1024
+
+// recent announcements (duplicate suppression)
+type gossipSeen struct {
+	mx   sync.Mutex
+	set  map[cipher.SHA256]struct{}
+	ring [gossipSeenSize]cipher.SHA256
+	i    int
+}
+
+func newGossipSeen() (g *gossipSeen) {
+	g = new(gossipSeen)
+	g.set = make(map[cipher.SHA256]struct{}, gossipSeenSize)
+	return
+}
+
+// see returns true if given Root has not been seen
+// before; the oldest remembered Root is forgotten
+func (g *gossipSeen) see(hash cipher.SHA256) (first bool) {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	if _, ok := g.set[hash]; ok == true {
+		return
+	}
+
+	delete(g.set, g.ring[g.i]) // forget the oldest
+	g.ring[g.i] = hash
+	g.i = (g.i + 1) % gossipSeenSize
+
+	g.set[hash] = struct{}{}
+	return true
+}
+
+// announce given full Root to random peers that are
+// not subscribed to feed of the Root (handler of the
+// nodeFeeds, the nf is feed of the Root or nil)
+func (n *Node) gossip(r *registry.Root, nf *nodeFeed) {
+
+	if n.config.Gossip <= 0 {
+		return
+	}
+
+	if n.lf.has(r.Pub) == true || n.isEncrypted(r.Pub) == true {
+		return // never announce
+	}
+
+	if n.gs.see(r.Hash) == false {
+		return // already announced
+	}
+
+	// subscribers receive the Root
+	var subs = make(map[*Conn]struct{})
+
+	if nf != nil {
+		for c := range nf.cs {
+			subs[c] = struct{}{}
+		}
+	}
+
+	// the announce locks the Node, that can wait
+	// for the nodeFeeds (not tracked by the await)
+	go n.announce(&msg.Announce{
+		Feed:  r.Pub,
+		Nonce: r.Nonce,
+		Seq:   r.Seq,
+		Hash:  r.Hash,
+	}, func(c *Conn) (skip bool) {
+		_, skip = subs[c]
+		return
+	})
+}
+
+// send the Announce to random peers, except skipped
+func (n *Node) announce(a *msg.Announce, skip func(c *Conn) bool) {
+
+	var cs = n.Connections()
+
+	rand.Shuffle(len(cs), func(i, j int) { cs[i], cs[j] = cs[j], cs[i] })
+
+	var sent int
+
+	for _, c := range cs {
+
+		if sent >= n.config.Gossip {
+			break
+		}
+
+		if c.HasFeature(msg.FeatureGossip) == false || skip(c) == true {
+			continue
+		}
+
+		c.sendMsg(c.nextSeq(), 0, a)
+		sent++
+	}
+
+}
+
+// <- Announce (feed, nonce, seq, hash)
+func (c *Conn) handleAnnounce(a *msg.Announce) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleAnnounce %s/%d/%d",
+		c.String(), a.Feed.Hex()[:7], a.Nonce, a.Seq)
+
+	if c.n.config.Gossip <= 0 {
+		return // gossip disabled
+	}
+
+	if c.n.gs.see(a.Hash) == false {
+		return // already seen
+	}
+
+	if c.wantAnnounced(a) == true {
+		c.await.Add(1)
+		go c.subscribeAnnounced(a.Feed)
+	}
+
+	// forward
+	c.n.announce(a, func(pc *Conn) (skip bool) {
+		return pc == c || c.n.fs.hasConnFeed(pc, a.Feed) == true
+	})
+
+	return
+}
+
+// is the announced Root newer then Root the Node has
+func (c *Conn) wantAnnounced(a *msg.Announce) (want bool) {
+
+	if c.n.lf.has(a.Feed) == true || c.n.fs.hasFeed(a.Feed) == false {
+		return // don't share the feed
+	}
+
+	if c.n.fs.hasConnFeed(c, a.Feed) == true {
+		return // the Root is being received
+	}
+
+	var last, err = c.n.container(a.Feed).LastRootSeq(a.Feed, a.Nonce)
+
+	switch err {
+	case nil:
+		return last < a.Seq
+	case data.ErrNoSuchHead, data.ErrNotFound:
+		return true
+	default:
+		return // no such feed
+	}
+
+}
+
+// subscribe to feed of announced Root, the peer
+// sends the Root if it has the feed
+func (c *Conn) subscribeAnnounced(feed cipher.PubKey) {
+	defer c.await.Done()
+
+	if err := c.Subscribe(feed); err != nil {
+		c.n.Debugf(MsgReceivePin, "[%s] can't subscribe to announced %s: %v",
+			c.String(), feed.Hex()[:7], err)
+	}
+}
@@ -0,0 +1,121 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func Test_gossipSeen(t *testing.T) {
+
+	var g = newGossipSeen()
+
+	var first = cipher.SumSHA256([]byte("first"))
+
+	assertTrue(t, g.see(first) == true, "not seen yet")
+	assertTrue(t, g.see(first) == false, "seen")
+
+	for i := 0; i < gossipSeenSize; i++ {
+		g.see(cipher.SumSHA256([]byte{byte(i), byte(i >> 8)}))
+	}
+
+	assertTrue(t, len(g.set) == gossipSeenSize, "wrong size")
+	assertTrue(t, g.see(first) == true, "not forgotten")
+
+}
+
+func TestNode_gossip(t *testing.T) {
+
+	// the publisher (a) is connected to the relay (b)
+	// that doesn't share the feed, and to the subscriber
+	// (c) that is not subscribed to the feed yet
+
+	var (
+		aconf = getTestConfig("publisher")
+		bconf = getTestConfigNotListen("relay")
+		cconf = getTestConfigNotListen("subscriber")
+
+		announced = make(chan *msg.Announce, 10)
+	)
+
+	aconf.Gossip, bconf.Gossip, cconf.Gossip = 2, 2, 2
+
+	bconf.OnRecvMsg = func(_ *Conn, m msg.Msg) msg.Msg {
+		if a, ok := m.(*msg.Announce); ok == true {
+			announced <- a
+		}
+		return m
+	}
+
+	var an, err = NewNode(aconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer an.Close()
+
+	var bn, cn *Node
+
+	if bn, err = NewNode(bconf); err != nil {
+		t.Fatal(err)
+	}
+	defer bn.Close()
+
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, an.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	if _, err = bn.TCP().Connect(an.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var ca *Conn
+	if ca, err = cn.TCP().Connect(an.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 1, Seq: 1,
+		Hash: cipher.SumSHA256([]byte("root"))}
+
+	// the publisher has filled the Root
+	an.fs.broadcastRootDone(r)
+
+	select {
+	case a := <-announced:
+		if a.Feed != pk || a.Seq != 1 || a.Hash != r.Hash {
+			t.Error("wrong Announce")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow")
+	}
+
+	// the subscriber subscribes to the feed
+	var subscribed bool
+
+	for i := 0; i < 100 && subscribed == false; i++ {
+		if subscribed = cn.fs.hasConnFeed(ca, pk); subscribed == false {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	assertTrue(t, subscribed == true, "not subscribed to announced feed")
+
+	// duplicate
+	an.fs.broadcastRootDone(r)
+
+	select {
+	case <-announced:
+		t.Error("announced twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+}
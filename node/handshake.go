@@ -27,12 +27,12 @@ func (c *Conn) sendNodeCloseq(
 ) {
 
 	select {
-	case c.sendq <- raw:
 	case <-nodeCloseq:
-		err = ErrClosed
+		return ErrClosed
+	default:
 	}
 
-	return
+	return c.Connection.Write(raw) // see (*Conn).write
 
 }
 
@@ -43,16 +43,18 @@ func (c *Conn) performHandshake(nodeCloseq <-chan struct{}) (err error) {
 	// (1) send Syn
 	// (2) receive Ack or Err
 
-	var seq = c.nextSeq()
-
-	err = c.sendNodeCloseq(
-		c.encodeMsg(seq, 0, &msg.Syn{
-			Protocol: msg.Version,
-			NodeID:   c.n.idpk,
-		}),
-		nodeCloseq,
+	var (
+		seq = c.nextSeq()
+		syn = c.encodeMsg(seq, 0, &msg.Syn{
+			Version:     msg.Version,
+			Features:    msg.Features,
+			NodeID:      c.n.idpk,
+			Compression: c.n.config.Compression,
+		})
 	)
 
+	err = c.sendNodeCloseq(syn, nodeCloseq)
+
 	if err != nil {
 		return
 	}
@@ -102,13 +104,41 @@ func (c *Conn) performHandshake(nodeCloseq <-chan struct{}) (err error) {
 
 		case *msg.Ack:
 
+			if x.Version < msg.MinVersion || x.Version > msg.Version {
+				return fmt.Errorf("%w: %d chosen by peer", ErrIncompatibleVersion,
+					x.Version)
+			}
+
+			if x.Features&^msg.Features != 0 {
+				return fmt.Errorf("unexpected features chosen by peer: %b",
+					x.Features)
+			}
+
+			if x.Compression != "" &&
+				c.n.config.Compression.has(x.Compression) == false {
+
+				return fmt.Errorf("unexpected compression chosen by peer: %q",
+					x.Compression)
+			}
+
 			c.peerID = x.NodeID
 
-			return // ok
+			// certificate of the peer (see Config.TLSPins)
+			if err = c.verifyTLSPin(); err != nil {
+				return
+			}
+
+			c.setProtocol(x.Version, x.Features)
+
+			if err = c.setCompression(x.Compression); err != nil {
+				return
+			}
+
+			return c.noiseHandshake(syn, raw, nodeCloseq)
 
 		case *msg.Err:
 
-			return errors.New(x.Err)
+			return remoteError(x.Err)
 
 		default:
 
@@ -159,6 +189,17 @@ func (c *Conn) acceptHandshake(nodeCloseq <-chan struct{}) (err error) {
 	)
 
 	if seq, _, m, err = c.decodeRaw(raw); err != nil {
+
+		// a Syn of another version can't be decoded,
+		// but the peer is told why it's rejected
+
+		if len(raw) > 8 {
+			var version, verr = msg.SynVersion(raw[8:])
+			if verr == nil && version != msg.Version {
+				return c.rejectHandshake(seq, version, nodeCloseq)
+			}
+		}
+
 		return
 	}
 
@@ -166,35 +207,64 @@ func (c *Conn) acceptHandshake(nodeCloseq <-chan struct{}) (err error) {
 
 	case *msg.Syn:
 
-		if x.Protocol != msg.Version {
+		var (
+			version  uint32
+			features uint64
+		)
 
-			err = fmt.Errorf("incompatible protocol version: %d, want %d",
-				x.Protocol,
-				msg.Version)
+		if version, features, err = negotiate(x.Version,
+			x.Features); err != nil {
 
-			// send Err back
+			return c.rejectHandshake(seq, x.Version, nodeCloseq)
+		}
+
+		c.peerID = x.NodeID
+
+		// the peer can't be authenticated (see Config.RequireNoise)
+		if c.n.config.RequireNoise == true &&
+			features&msg.FeatureNoise == 0 {
+
+			err = ErrNoiseRequired
+		}
+
+		// certificate of the peer (see Config.TLSPins)
+		if err == nil {
+			err = c.verifyTLSPin()
+		}
 
-			c.sendNodeCloseq(
+		if err != nil {
+			// write it directly, since the connection
+			// is closed just after
+			c.Connection.Write(
 				c.encodeMsg(c.nextSeq(), seq, &msg.Err{Err: err.Error()}),
-				nodeCloseq,
 			)
-
 			return
-
 		}
 
-		c.peerID = x.NodeID
+		// (2) send Ack back, choosing compression
 
-		// (2) send Ack back
+		var cmp = c.n.config.Compression.choose(x.Compression)
 
-		err = c.sendNodeCloseq(
-			c.encodeMsg(c.nextSeq(), seq, &msg.Ack{
-				NodeID: c.n.idpk,
-			}),
-			nodeCloseq,
-		)
+		var ack = c.encodeMsg(c.nextSeq(), seq, &msg.Ack{
+			Version:     version,
+			Features:    features,
+			NodeID:      c.n.idpk,
+			Compression: cmp,
+		})
 
-		return
+		if err = c.sendNodeCloseq(ack, nodeCloseq); err != nil {
+			return
+		}
+
+		c.setProtocol(version, features)
+
+		// the Ack is not compressed, but
+		// all messages after are compressed
+		if err = c.setCompression(cmp); err != nil {
+			return
+		}
+
+		return c.noiseHandshake(raw, ack, nodeCloseq)
 
 	default:
 
@@ -206,3 +276,88 @@ func (c *Conn) acceptHandshake(nodeCloseq <-chan struct{}) (err error) {
 	}
 
 }
+
+// negotiate protocol version and features
+// with remote peer that has given ones
+func negotiate(
+	peerVersion uint32, //  : protocol version of the peer
+	peerFeatures uint64, // : features of the peer
+) (
+	version uint32, //      : negotiated version
+	features uint64, //     : features both peers have
+	err error, //           : incompatible version
+) {
+
+	if peerVersion < msg.MinVersion {
+		err = fmt.Errorf("%w: %d, want %d or newer", ErrIncompatibleVersion,
+			peerVersion, msg.MinVersion)
+		return
+	}
+
+	if version = msg.Version; peerVersion < version {
+		version = peerVersion // the peer is older
+	}
+
+	features = peerFeatures & msg.Features
+	return
+}
+
+// reject handshake of a peer with incompatible protocol
+// version sending Err back, the rejectHandshake returns
+// reason of the rejection
+func (c *Conn) rejectHandshake(
+	seq uint32, //                 : seq of the Syn
+	version uint32, //             : version of the peer
+	nodeCloseq <-chan struct{}, // : closing
+) (
+	err error, //                  : the reason
+) {
+
+	_, _, err = negotiate(version, 0)
+
+	if err == nil {
+		// the Syn of compatible version can't be decoded
+		err = fmt.Errorf("%w: %d, can't decode Syn", ErrIncompatibleVersion,
+			version)
+	}
+
+	c.n.Debugf(ConnHskPin, "[%s] reject handshake: %v", c.String(), err)
+
+	// send the ErrIncompatibleVersion that can be
+	// restored by the peer (see remoteError)
+
+	c.sendNodeCloseq(
+		c.encodeMsg(c.nextSeq(), seq, &msg.Err{
+			Err: ErrIncompatibleVersion.Error(),
+		}),
+		nodeCloseq,
+	)
+
+	return
+}
+
+// set negotiated protocol version and features
+func (c *Conn) setProtocol(version uint32, features uint64) {
+
+	c.version, c.features = version, features
+	c.deriveKey()
+
+	c.n.Debugf(ConnHskPin, "[%s] protocol version %d, features %b",
+		c.String(), version, features)
+}
+
+// use given compression for next messages
+func (c *Conn) setCompression(name string) (err error) {
+
+	if c.cmp, err = compressorByName(name); err != nil {
+		return
+	}
+
+	c.cmpName = name
+
+	if name != "" {
+		c.n.Debugf(ConnHskPin, "[%s] compression: %s", c.String(), name)
+	}
+
+	return
+}
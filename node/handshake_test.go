@@ -0,0 +1,141 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func Test_negotiate(t *testing.T) {
+
+	for _, tc := range []struct {
+		version  uint32
+		features uint64
+		want     uint32
+		wantf    uint64
+		err      error
+	}{
+		{msg.Version, msg.Features, msg.Version, msg.Features, nil},
+		{msg.Version + 1, msg.Features | 1<<63, msg.Version, msg.Features, nil},
+		{msg.Version, msg.FeatureOwnership, msg.Version, msg.FeatureOwnership,
+			nil},
+		{msg.MinVersion - 1, msg.Features, 0, 0, ErrIncompatibleVersion},
+	} {
+
+		var version, features, err = negotiate(tc.version, tc.features)
+
+		if errors.Is(err, tc.err) == false {
+			t.Errorf("wrong error: want %v, got %v", tc.err, err)
+			continue
+		}
+
+		if tc.err != nil {
+			continue
+		}
+
+		if version != tc.want {
+			t.Errorf("wrong version: want %d, got %d", tc.want, version)
+		}
+
+		if features != tc.wantf {
+			t.Errorf("wrong features: want %b, got %b", tc.wantf, features)
+		}
+
+	}
+
+}
+
+func TestConn_ProtocolVersion(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.ProtocolVersion(); got != msg.Version {
+		t.Errorf("wrong protocol version: want %d, got %d", msg.Version, got)
+	}
+
+	for _, f := range []uint64{
+		msg.FeatureSubLease,
+		msg.FeatureOwnership,
+		msg.FeatureSharedFeeds,
+	} {
+		if c.HasFeature(f) == false {
+			t.Errorf("missing feature %b", f)
+		}
+	}
+
+	if c.HasFeature(1<<63) == true {
+		t.Error("unexpected feature")
+	}
+
+	// the other side
+
+	var (
+		sc *Conn
+		ok bool
+	)
+
+	for i := 0; i < 100 && ok == false; i++ {
+		if sc, ok = sn.hasPeer(cn.ID()); ok == false {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	if ok == false {
+		t.Fatal("missing incoming connection")
+	}
+
+	if got := sc.ProtocolVersion(); got != msg.Version {
+		t.Errorf("wrong protocol version: want %d, got %d", msg.Version, got)
+	}
+
+}
+
+func Test_SynVersion(t *testing.T) {
+
+	var syn = &msg.Syn{Version: msg.Version + 1}
+
+	// Syn of newer version with unknown fields
+	var p = append(syn.Encode(), 1, 2, 3)
+
+	if _, err := msg.Decode(p); err == nil {
+		t.Fatal("missing decoding error")
+	}
+
+	var version, err = msg.SynVersion(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != msg.Version+1 {
+		t.Errorf("wrong version: want %d, got %d", msg.Version+1, version)
+	}
+
+	// old Syn: [type][2 version][33 NodeID]
+	var pk, _ = cipher.GenerateKeyPair()
+
+	p = append([]byte{byte(msg.SynType), 3, 0}, pk[:]...)
+
+	if version, err = msg.SynVersion(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if version != 3 {
+		t.Errorf("wrong old version: want 3, got %d", version)
+	}
+
+	if _, err = msg.SynVersion((&msg.Ping{}).Encode()); err == nil {
+		t.Error("missing error")
+	}
+
+}
@@ -0,0 +1,279 @@
+package node
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// have-lists limits
+const (
+	haveListFalsePositive = 0.01    // desired false positive rate
+	maxHaveListHashes     = 32      // max number of hash functions
+	maxHaveListSize       = 1 << 20 // max size of filter in bytes
+)
+
+// A HaveList is Bloom filter of objects of a feed a
+// remote peer holds. The filter contains objects of
+// last Root objects of all heads of the feed. Since
+// a Node broadcasts a Root before it has been filled,
+// the Node prefers peers which have-lists have an
+// object to request the object from. Thus, filling
+// doesn't wait for peers that don't have the object.
+// See also (*Conn).RemoteHaveList
+type HaveList struct {
+	hashes uint32 // number of hash functions
+	bits   []byte // the filter
+}
+
+// create HaveList for given number of objects
+func newHaveList(n int) (h *HaveList) {
+
+	if n < 1 {
+		n = 1
+	}
+
+	// m = -n*ln(p) / ln(2)^2, k = m/n * ln(2)
+
+	var m = math.Ceil(-float64(n) * math.Log(haveListFalsePositive) /
+		(math.Ln2 * math.Ln2))
+
+	if m > maxHaveListSize*8 {
+		m = maxHaveListSize * 8
+	}
+
+	var k = math.Round(m / float64(n) * math.Ln2)
+
+	if k < 1 {
+		k = 1
+	} else if k > maxHaveListHashes {
+		k = maxHaveListHashes
+	}
+
+	h = new(HaveList)
+	h.hashes = uint32(k)
+	h.bits = make([]byte, (int(m)+7)/8)
+	return
+}
+
+// HaveList from received Have message
+func haveListFromMsg(hv *msg.Have) (h *HaveList, err error) {
+
+	if hv.Hashes < 1 || hv.Hashes > maxHaveListHashes {
+		return nil, fmt.Errorf("invalid number of hashes of have-list: %d",
+			hv.Hashes)
+	}
+
+	if len(hv.Bits) == 0 || len(hv.Bits) > maxHaveListSize {
+		return nil, fmt.Errorf("invalid size of have-list: %d", len(hv.Bits))
+	}
+
+	return &HaveList{hashes: hv.Hashes, bits: hv.Bits}, nil
+}
+
+// call given function for every bit of given key,
+// the SHA256 is uniform enough to use double
+// hashing with parts of the key
+func (h *HaveList) locations(key cipher.SHA256, fn func(i, mask int) bool) {
+
+	var (
+		m  = uint64(len(h.bits)) * 8
+		h1 = binary.LittleEndian.Uint64(key[0:])
+		h2 = binary.LittleEndian.Uint64(key[8:]) | 1
+	)
+
+	for i := uint64(0); i < uint64(h.hashes); i++ {
+		var bit = (h1 + i*h2) % m
+		if fn(int(bit/8), 1<<(bit%8)) == false {
+			return
+		}
+	}
+}
+
+func (h *HaveList) add(key cipher.SHA256) {
+	h.locations(key, func(i, mask int) bool {
+		h.bits[i] |= byte(mask)
+		return true
+	})
+}
+
+// Has returns true if the remote peer probably has
+// object with given key. It can return true for an
+// object the peer doesn't have (false positive), but
+// it never returns false for an object the peer had
+// when the HaveList was created
+func (h *HaveList) Has(key cipher.SHA256) (ok bool) {
+	ok = true
+	h.locations(key, func(i, mask int) bool {
+		ok = h.bits[i]&byte(mask) != 0
+		return ok
+	})
+	return
+}
+
+// Size returns size of the HaveList in bytes
+func (h *HaveList) Size() int {
+	return len(h.bits)
+}
+
+// create HaveList of given feed
+func (n *Node) haveList(feed cipher.PubKey) (h *HaveList, err error) {
+
+	var (
+		cc    = n.container(feed)
+		heads []uint64
+	)
+
+	if heads, err = cc.Heads(feed); err != nil {
+		return
+	}
+
+	var keys []cipher.SHA256
+
+	for _, nonce := range heads {
+
+		var r *registry.Root
+		if r, err = cc.LastRoot(feed, nonce); err != nil {
+			err = nil
+			continue // blank head
+		}
+
+		if r.IsPartial == true {
+			continue // never served (see SetFilter)
+		}
+
+		err = cc.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
+			keys = append(keys, key)
+			return true, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	h = newHaveList(len(keys))
+
+	for _, key := range keys {
+		h.add(key)
+	}
+
+	return
+}
+
+// RemoteHaveList requests have-list of given feed from
+// remote peer. The HaveList is kept by the Conn and used
+// to fill Root objects of the feed. A HaveList requested
+// automatically when the Conn used to fill a Root of the
+// feed first time. The RemoteHaveList used to refresh the
+// list. It returns ErrNotSupported if the peer doesn't
+// support have-lists (see msg.FeatureHaveList)
+func (c *Conn) RemoteHaveList(feed cipher.PubKey) (h *HaveList, err error) {
+
+	if c.HasFeature(msg.FeatureHaveList) == false {
+		return nil, ErrNotSupported
+	}
+
+	var reply msg.Msg
+	if reply, err = c.sendRequest(&msg.RqHave{Feed: feed}); err != nil {
+		return
+	}
+
+	switch x := reply.(type) {
+	case *msg.Have:
+		if x.Feed != feed {
+			return nil, ErrInvalidResponse
+		}
+		if h, err = haveListFromMsg(x); err != nil {
+			return
+		}
+	case *msg.Err:
+		return nil, remoteError(x.Err)
+	default:
+		return nil, fmt.Errorf("invalid response type %T", reply)
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.have == nil {
+		c.have = make(map[cipher.PubKey]*HaveList)
+	}
+
+	c.have[feed] = h
+	return
+}
+
+// HaveList returns last HaveList of given feed received
+// from remote peer, or nil. See also RemoteHaveList
+func (c *Conn) HaveList(feed cipher.PubKey) (h *HaveList) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.have[feed]
+}
+
+// (async) request have-list of given feed if the
+// Conn doesn't have it, and the peer supports them
+func (c *Conn) requestHaveList(feed cipher.PubKey) {
+	defer c.await.Done()
+
+	if c.HasFeature(msg.FeatureHaveList) == false ||
+		c.HaveList(feed) != nil {
+
+		return
+	}
+
+	if _, err := c.RemoteHaveList(feed); err != nil {
+		c.n.Debugf(FillPin, "[%s] can't get have-list of %s: %v", c.String(),
+			feed.Hex()[:7], err)
+	}
+}
+
+// mayHave returns false if the remote peer
+// definitely doesn't have object of given feed
+func (c *Conn) mayHave(feed cipher.PubKey, key cipher.SHA256) bool {
+
+	var h = c.HaveList(feed)
+
+	if h == nil {
+		return true // unknown
+	}
+
+	return h.Has(key)
+}
+
+// (async) <- RqHave (feed)
+func (c *Conn) handleRqHave(seq uint32, rq *msg.RqHave) {
+	defer c.await.Done()
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqHave %s", c.String(),
+		rq.Feed.Hex()[:7])
+
+	if c.n.lf.has(rq.Feed) == true || c.n.IsSharing(rq.Feed) == false {
+		c.sendErr(seq, ErrFeedNotServed)
+		return
+	}
+
+	// walking can take time, thus the
+	// handleRqHave is asynchronous
+
+	var h, err = c.n.haveList(rq.Feed)
+
+	if err != nil {
+		c.sendErr(seq, err)
+		return
+	}
+
+	c.sendMsg(c.nextSeq(), seq, &msg.Have{
+		Feed:   rq.Feed,
+		Hashes: h.hashes,
+		Bits:   h.bits,
+	})
+}
@@ -0,0 +1,115 @@
+package node
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestHaveList_Has(t *testing.T) {
+
+	const n = 1000
+
+	var h = newHaveList(n)
+
+	for i := 0; i < n; i++ {
+		h.add(cipher.SumSHA256([]byte(strconv.Itoa(i))))
+	}
+
+	for i := 0; i < n; i++ {
+		if h.Has(cipher.SumSHA256([]byte(strconv.Itoa(i)))) == false {
+			t.Fatal("false negative")
+		}
+	}
+
+	var fp int
+
+	for i := n; i < 2*n; i++ {
+		if h.Has(cipher.SumSHA256([]byte(strconv.Itoa(i)))) == true {
+			fp++
+		}
+	}
+
+	if fp > n/20 {
+		t.Errorf("too many false positives: %d of %d", fp, n)
+	}
+
+}
+
+func TestConn_RemoteHaveList(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+
+	var (
+		sc = sn.Container()
+
+		up  *skyobject.Unpack
+		err error
+	)
+
+	if up, err = sc.Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021
+	r.Pub = pk
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}))
+
+	if err = sc.Save(up, r); err != nil {
+		t.Fatal(err)
+	}
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.HaveList(pk) != nil {
+		t.Error("unexpected have-list")
+	}
+
+	var h *HaveList
+	if h, err = c.RemoteHaveList(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.HaveList(pk) != h {
+		t.Error("have-list is not kept")
+	}
+
+	for _, key := range []cipher.SHA256{
+		r.Hash,
+		cipher.SHA256(r.Reg),
+		r.Refs[0].Hash,
+	} {
+		if h.Has(key) == false {
+			t.Error("missing object", key.Hex()[:7])
+		}
+	}
+
+	// not shared feed
+
+	var unknown, _ = cipher.GenerateKeyPair()
+
+	_, err = c.RemoteHaveList(unknown)
+
+	if errors.Is(err, ErrFeedNotServed) == false {
+		t.Errorf("wrong error: want %v, got %v", ErrFeedNotServed, err)
+	}
+
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/statutil"
@@ -122,9 +123,19 @@ type fillHead struct {
 	failureq chan failedRequest // failed requests
 
 	rqo *list.List // request objects (cipher.SHA256)
-	fc  *list.List // connections to fill from (*Conn)
+
+	// connections to fill from -> number of running
+	// requests to the connection (see Config.MaxInFlight)
+	fc map[*Conn]int
+
+	// failed requests of objects, an object is never
+	// requested from the same connection twice, and
+	// it's retried up to Config.FillRetries times
+	failed map[cipher.SHA256][]*Conn
 
 	requesting int // number of running requests
+
+	fillq chan struct{} // closed when filling of the r is over
 }
 
 func (n *nodeHead) handle() {
@@ -217,6 +228,14 @@ func (n *nodeHead) handle() {
 func (f *fillHead) handleRequest(key cipher.SHA256) {
 	f.node().Debugln(FillPin, "[fill] handleRequest", key.Hex()[:7])
 
+	// received with the Root (see RootDelta)
+	if val, ok := f.r.delta[key]; ok == true {
+		if err := f.setObject(key, val); err != nil && f.f != nil {
+			f.f.Fail(err)
+		}
+		return
+	}
+
 	f.rqo.PushBack(key)
 	f.triggerRequest()
 }
@@ -224,33 +243,80 @@ func (f *fillHead) handleRequest(key cipher.SHA256) {
 func (f *fillHead) handleSuccess(c *Conn) {
 	f.node().Debugln(FillPin, "[fill] handleSuccess", c.String())
 
+	if f.f == nil {
+		return // the filling is over
+	}
+
 	f.requesting--
-	f.fc.PushBack(c) // push
+	f.release(c)
 	f.triggerRequest()
 }
 
+// a request to given connection is over
+func (f *fillHead) release(c *Conn) {
+	if inFlight, ok := f.fc[c]; ok == true && inFlight > 0 {
+		f.fc[c] = inFlight - 1
+	}
+}
+
 func (f *fillHead) handleRequestFailure(fr failedRequest) {
 	f.node().Debugln(FillPin, "[fill] handleRequestFailure", fr.c.String(),
 		fr.key.Hex()[:7])
 
+	if f.f == nil {
+		if fr.err == ErrInvalidResponse {
+			go fr.c.invalidData(fr.err)
+		}
+		return // the filling is over
+	}
+
 	f.requesting--
+	f.release(fr.c)
 
 	switch fr.err {
 	case ErrInvalidResponse:
 
 		// close connections that sends invalid responses
-		go fr.c.fatality(fr.err)
+		go fr.c.invalidData(fr.err)
 		delete(f.cs, fr.c) // remove connection
+		delete(f.fc, fr.c) // don't fill from
+
+		if f.retry(fr) == false {
+			return
+		}
 
 	case ErrClosed:
 
 		// closed
 		delete(f.cs, fr.c) // remove connection
+		delete(f.fc, fr.c) // don't fill from
 
 	case ErrTimeout:
 
-		// probably don't have object we're requesting anymore
+		// probably don't have Root we're filling anymore,
+		// or too slow; other objects are requested from
+		// other connections
 		f.cs.removeKnown(fr.c, fr.seq)
+		delete(f.fc, fr.c)
+
+		if f.retry(fr) == false {
+			return
+		}
+
+	case data.ErrNotFound:
+
+		// don't have the object, but can have others
+		if f.retry(fr) == false {
+			return
+		}
+
+	case ErrTenantQuota:
+
+		// break the filling
+		if f.f != nil {
+			f.f.Fail(fr.err)
+		}
+		return
 
 	default:
 
@@ -263,6 +329,24 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 
 }
 
+// remember failed request, the retry returns false if
+// the object can't be retried (see Config.FillRetries)
+// and the filling fails
+func (f *fillHead) retry(fr failedRequest) (ok bool) {
+
+	var failed = append(f.failed[fr.key], fr.c)
+
+	if len(failed) > f.node().config.FillRetries {
+		if f.f != nil {
+			f.f.Fail(fr.err)
+		}
+		return
+	}
+
+	f.failed[fr.key] = failed
+	return true
+}
+
 func (f *fillHead) handleReceivedRoot(cr connRoot) {
 	f.node().Debugln(FillPin, "[fill] handleReceivedRoot",
 		cr.c.String(), cr.r.Short())
@@ -278,7 +362,9 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 		f.cs.addKnown(cr.c, cr.r.Seq) // add to known
 
 		if cr.r.Seq == f.r.r.Seq {
-			f.fc.PushBack(cr.c) // add to filling connections
+			if _, ok := f.fc[cr.c]; ok == false {
+				f.fc[cr.c] = 0 // add to filling connections
+			}
 			f.triggerRequest()
 			return
 		}
@@ -287,6 +373,7 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 
 			// callback
 			if reject := f.node().onRootReceived(cr.c, cr.r); reject != nil {
+				f.rejectRoot(cr, msg.RejectPolicy, reject)
 				return // rejected
 			}
 
@@ -300,6 +387,7 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 
 			// callback
 			if reject := f.node().onRootReceived(cr.c, cr.r); reject != nil {
+				f.rejectRoot(cr, msg.RejectPolicy, reject)
 				return // rejected
 			}
 
@@ -315,6 +403,7 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 
 	// callback
 	if reject := f.node().onRootReceived(cr.c, cr.r); reject != nil {
+		f.rejectRoot(cr, msg.RejectPolicy, reject)
 		return // rejected
 	}
 
@@ -333,12 +422,25 @@ func (f *fillHead) maxParallel() (mp int) {
 	return
 }
 
+// is the Container validates filled Root objects
+// (see skyobject.Config.ValidateFilled)
+func (f *fillHead) validate() bool {
+	return f.container().Config().ValidateFilled
+}
+
 func (f *fillHead) createFiller(cr connRoot) {
 	f.node().Debugln(FillPin, "[fill] createFiller", cr.c.String(),
 		cr.r.Short())
 
-	// broadcast the Root we are going to fill
-	f.nodeHead.n.fs.broadcastRoot(cr)
+	// light subscription, a partial Root
+	// is never announced (see SetFilter)
+	var filter = f.node().ff.get(cr.r.Pub)
+
+	// broadcast the Root we are going to fill,
+	// if it should be validated, then after
+	if f.validate() == false && filter == nil {
+		f.nodeHead.n.fs.broadcastRoot(cr)
+	}
 
 	f.tp = time.Now() // time point
 
@@ -348,11 +450,20 @@ func (f *fillHead) createFiller(cr connRoot) {
 	}
 
 	f.r = cr
+	f.fillq = make(chan struct{})
 	f.rq = make(chan cipher.SHA256, f.maxParallel())
-	f.f = f.node().c.Fill(cr.r, f.rq, f.maxParallel())
+	f.f = f.container().Fill(cr.r, f.rq, f.maxParallel())
+	f.f.Filter(filter...)
 
-	f.rqo = list.New()                   // create list of keys
-	f.fc = f.cs.buildConnsList(cr.r.Seq) // create list of connections
+	f.rqo = list.New()                  // create list of keys
+	f.fc = f.cs.buildConnsMap(cr.r.Seq) // create connections to fill from
+	f.failed = make(map[cipher.SHA256][]*Conn)
+
+	// have-lists of the connections
+	for c := range f.fc {
+		c.await.Add(1)
+		go c.requestHaveList(cr.r.Pub)
+	}
 
 	f.await.Add(1)
 	go f.runFiller(f.f)
@@ -381,11 +492,15 @@ func (f *fillHead) closeFiller() {
 
 	f.f.Close()
 
-	f.rqo, f.fc, f.rq = nil, nil, nil
+	close(f.fillq) // cancel requests
+
+	f.f, f.rqo, f.fc, f.failed, f.rq, f.fillq = nil, nil, nil, nil, nil, nil
 
 	f.r = connRoot{}
 	f.requesting = 0
 
+	f.reportQueue()
+
 }
 
 func (f *fillHead) handleFillingResult(err error) {
@@ -393,11 +508,24 @@ func (f *fillHead) handleFillingResult(err error) {
 	f.node().Debugf(FillPin, "handleFillingResult %s: %v", f.r.r.Short(), err)
 
 	if err == nil {
+		if f.r.c != nil {
+			f.r.c.addScore(scoreValidRoot) // delivered
+		}
+		if f.r.r.IsPartial == false {
+			if f.validate() == true {
+				f.nodeHead.n.fs.broadcastRoot(f.r) // validated
+			}
+			f.nodeHead.n.fs.broadcastRootDone(f.r.r) // report
+		}
 		f.node().onRootFilled(f.r.r)     // callback
 		f.favg.Add(time.Now().Sub(f.tp)) // average time
 		f.cs.moveForward(f.r.r.Seq + 1)  // move forward
 	} else {
 		f.node().onFillingBreaks(f.r.r, err) // callback
+
+		if reason, ok := f.node().fillingRejectReason(f.r.r, err); ok == true {
+			f.rejectRoot(f.r, reason, err) // report
+		}
 	}
 
 	f.closeFiller() // close the filler and wait it's goroutines
@@ -406,7 +534,7 @@ func (f *fillHead) handleFillingResult(err error) {
 
 	// no
 
-	if f.p == (connRoot{}) {
+	if f.p.r == nil {
 		return
 	}
 
@@ -417,6 +545,22 @@ func (f *fillHead) handleFillingResult(err error) {
 
 }
 
+// report rejected Root to connection it has been
+// received from (see msg.RootReject)
+func (f *fillHead) rejectRoot(
+	cr connRoot, //             : the Root
+	reason msg.RejectReason, // : reason
+	err error, //               : details
+) {
+
+	if cr.c == nil {
+		return // closed
+	}
+
+	cr.c.await.Add(1)
+	go cr.c.rejectRoot(cr.r, reason, err)
+}
+
 func (f *fillHead) triggerRequest() {
 
 	if fatal := f.tryRequest(); fatal == true {
@@ -425,44 +569,147 @@ func (f *fillHead) triggerRequest() {
 		}
 	}
 
+	f.reportQueue()
+
+}
+
+// report queue depths for SyncEvents
+func (f *fillHead) reportQueue() {
+
+	var queued int
+
+	if f.rqo != nil {
+		queued = f.rqo.Len()
+	}
+
+	f.node().se.setQueue(f.n.this, f.nodeHead, queued, f.requesting)
 }
 
 // the fatal means that we haven't connections to
-// request objects from anymore, neither busy nor idle
+// request objects from anymore, neither busy nor idle;
+// the tryRequest requests as many objects as possible,
+// objects are requested from many connections in
+// parallel, up to Config.MaxInFlight per connection
 func (f *fillHead) tryRequest() (fatal bool) {
 
-	if f.rqo.Len() == 0 {
-		return // no objects to request
-	}
+	for e := f.rqo.Front(); e != nil && f.hasFreeConn() == true; {
+
+		var (
+			key = e.Value.(cipher.SHA256)
+			c   = f.chooseConn(key)
+		)
+
+		if c == nil {
+			e = e.Next() // no connections for the object
+			continue
+		}
+
+		var next = e.Next()
+		f.rqo.Remove(e) // unshift
+		e = next
+
+		// do the request
 
-	if f.fc.Len() == 0 {
-		fatal = (f.requesting == 0)
-		return // no connections to request from
+		f.requesting++
+		f.fc[c]++
+
+		f.await.Add(1) // nodeHead.await
+		go f.request(c, f.r.r.Seq, key, key == cipher.SHA256(f.r.r.Reg),
+			f.fillq)
 	}
 
-	var c = f.fc.Remove(f.fc.Front()).(*Conn) // unshift
+	fatal = (f.rqo.Len() > 0 && f.requesting == 0)
+	return
+}
 
-	// the c can be removed from the head, let's check it out
+// is there a connection that can request an object
+func (f *fillHead) hasFreeConn() bool {
 
-	for _, ok := f.cs[c]; ok == false; _, ok = f.cs[c] {
+	var max = f.node().config.MaxInFlight
 
-		if f.fc.Len() == 0 {
-			fatal = (f.requesting == 0)
-			return // no connections
+	for c, inFlight := range f.fc {
+		if _, ok := f.cs[c]; ok == false {
+			delete(f.fc, c) // removed from the head
+			continue
+		}
+		if max <= 0 || inFlight < max {
+			return true
 		}
+	}
 
-		c = f.fc.Remove(f.fc.Front()).(*Conn) // unshift next
+	return false
+}
 
+// is given object failed by given connection
+func (f *fillHead) isFailed(key cipher.SHA256, c *Conn) bool {
+	for _, fc := range f.failed[key] {
+		if fc == c {
+			return true
+		}
 	}
+	return false
+}
 
-	var key = f.rqo.Remove(f.rqo.Front()).(cipher.SHA256) // unshift
+// choose connection to request given object; a connection
+// which have-list has the object is preferred, and then a
+// connection with lower expected cost of the request, that
+// is the service time (see (*Conn).ServiceTime) multiplied
+// by requests in flight and divided by hit rate (see
+// (*Conn).MissRate), and then a connection with less
+// requests in flight, and then a connection with higher
+// score (see (*Conn).Score), and then a connection with
+// lower miss rate; thus faster peers are preferred, but
+// a peer that misses falls back; the chooseConn returns
+// nil if there are no connections that can request the
+// object
+func (f *fillHead) chooseConn(key cipher.SHA256) (c *Conn) {
 
-	// do the request
+	var (
+		max = f.node().config.MaxInFlight
 
-	f.requesting++
+		bestIn    int     // requests in flight of the best
+		bestHas   bool    // have-list of the best has the object
+		bestCost  float64 // expected cost of the request to the best
+		bestScore int64   // score of the best
+	)
 
-	f.await.Add(1) // nodeHead.await
-	go f.request(c, f.r.r.Seq, key)
+	for ec, inFlight := range f.fc {
+
+		if _, ok := f.cs[ec]; ok == false {
+			delete(f.fc, ec) // removed from the head
+			continue
+		}
+
+		if max > 0 && inFlight >= max {
+			continue // busy
+		}
+
+		if f.isFailed(key, ec) == true {
+			continue // already requested
+		}
+
+		// have-list can be out of date
+		var (
+			has   = ec.mayHave(f.r.r.Pub, key)
+			cost  = ec.requestCost(inFlight)
+			score = ec.Score()
+		)
+
+		switch {
+		case c == nil,
+			has == true && bestHas == false,
+			has == bestHas && cost < bestCost,
+			has == bestHas && cost == bestCost && inFlight < bestIn,
+			has == bestHas && cost == bestCost && inFlight == bestIn &&
+				score > bestScore,
+			has == bestHas && cost == bestCost && inFlight == bestIn &&
+				score == bestScore && ec.MissRate() < c.MissRate():
+
+			c, bestIn, bestHas, bestCost, bestScore = ec, inFlight, has,
+				cost, score
+		}
+
+	}
 
 	return
 }
@@ -472,43 +719,154 @@ func (f *fillHead) node() *Node {
 	return f.n.fs.n
 }
 
-// (async) request object
-func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
+// Container of the feed
+func (f *fillHead) container() *skyobject.Container {
+	return f.node().container(f.n.this)
+}
+
+// (async) request object; the request is canceled if
+// the filling is over or if the object has been received
+// another way (e.g. delayed reply of another connection),
+// thus the remote peer doesn't send it for nothing
+func (f *fillHead) request(
+	c *Conn, //               : request from
+	seq uint64, //            : seq of the filling Root
+	key cipher.SHA256, //     : the object
+	reg bool, //              : the object is Registry of the Root
+	fillq <-chan struct{}, // : closed when filling is over
+) {
 	defer f.await.Done()
 
 	f.node().Debugf(FillPin, "[fill] request from [%s] %d %s", c.String(), seq,
 		key.Hex()[:7])
 
-	var reply, err = c.sendRequest(&msg.RqObject{Key: key})
+	var (
+		gc     = make(chan skyobject.Object, 1)
+		cancel = make(chan struct{})
+		done   = make(chan struct{})
+	)
+
+	if err := f.container().Want(key, gc, 0); err != nil {
+		f.node().Fatal("DB failure:", err)
+	}
+	defer f.container().Unwant(key, gc)
+
+	go func() {
+		defer close(cancel)
+
+		select {
+		case <-gc: // received another way
+		case <-fillq:
+		case <-done:
+		}
+	}()
+	defer close(done)
+
+	var (
+		start      = time.Now()
+		reply, err = f.requestObject(c, key, reg, cancel)
+	)
+
+	if err == ErrCanceled {
+
+		select {
+		case <-fillq:
+			return // nobody waits the result
+		default:
+		}
+
+		f.successq <- c // the connection is free
+		return
+	}
 
 	if err != nil {
+		if err == ErrTimeout {
+			c.addServiceTime(time.Now().Sub(start))
+			c.addRequestResult(true) // missed
+		}
 		f.failureq <- failedRequest{c, seq, key, err}
 		return
 	}
 
 	switch x := reply.(type) {
 	case *msg.Object:
-		var rk = cipher.SumSHA256(x.Value)
-
-		if rk != key {
+		if x.Key != key || cipher.SumSHA256(x.Value) != key {
 			f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
 			return
 		}
 
-		// incremented by the Want call(s)
-		if _, err := f.node().c.SetWanted(key, x.Value); err != nil {
-			f.node().Fatal("DB failure:", err)
+		c.addServiceTime(time.Now().Sub(start))
+		c.addRequestResult(false)
+
+		if err = f.setObject(key, x.Value); err != nil {
+			f.failureq <- failedRequest{c, seq, key, err}
 			return
 		}
 
 		f.successq <- c
 
+	case *msg.NotFound:
+		if x.Key != key {
+			f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
+			return
+		}
+
+		c.addRequestResult(true) // missed
+		f.failureq <- failedRequest{c, seq, key, data.ErrNotFound}
+
 	default:
 		f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
 	}
 
 }
 
+// request object; registries of many Root objects
+// are requested by one RqRegistries if possible
+func (f *fillHead) requestObject(
+	c *Conn, //                : request from
+	key cipher.SHA256, //      : the object
+	reg bool, //               : the object is Registry
+	cancel <-chan struct{}, // : cancel the request
+) (
+	reply msg.Msg, //          : reply
+	err error, //              : an error
+) {
+
+	if reg == true && c.canBatchRegistries(f.n.this) == true {
+
+		var val []byte
+		if val, err = c.requestRegistry(key, cancel); err != nil {
+			return
+		}
+
+		if val != nil {
+			return &msg.Object{Key: key, Value: val}, nil
+		}
+
+		// not found or too large, request as object
+	}
+
+	return c.sendFeedRequest(f.n.this, &msg.RqObject{Key: key}, cancel)
+}
+
+// save wanted object, it returns quota error
+func (f *fillHead) setObject(key cipher.SHA256, val []byte) (err error) {
+
+	// quota of a tenant
+	if err = f.node().tn.addObject(f.n.this, len(val)); err != nil {
+		return
+	}
+
+	// incremented by the Want call(s)
+	if _, err = f.container().SetWanted(key, val); err != nil {
+		f.node().Fatal("DB failure:", err)
+		return
+	}
+
+	f.node().se.addObject(f.n.this, len(val))
+	return
+}
+
 func (f *fillHead) handleDelConn(c *Conn) {
 	delete(f.cs, c) // just remove it from list of known
 
@@ -604,17 +962,17 @@ func (k knownRoots) moveForward(seq uint64) {
 
 }
 
-// build list of connections to fill Root with given seq
-func (k knownRoots) buildConnsList(seq uint64) (l *list.List) {
+// build connections to fill Root with given seq
+func (k knownRoots) buildConnsMap(seq uint64) (cs map[*Conn]int) {
 
-	l = list.New()
+	cs = make(map[*Conn]int)
 
 	for c, known := range k {
 
 		for _, ks := range known {
 
 			if ks == seq {
-				l.PushBack(c)
+				cs[c] = 0 // no requests in flight
 				break
 			}
 
@@ -0,0 +1,161 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// fillHead with given connections to fill from
+func getTestFillHead(conf *Config, cs ...*Conn) (f *fillHead) {
+
+	var n = &Node{config: conf, rep: newReputation()}
+
+	f = &fillHead{
+		nodeHead: &nodeHead{n: &nodeFeed{fs: &nodeFeeds{n: n}}},
+		r:        connRoot{r: &registry.Root{}},
+		cs:       make(knownRoots),
+		fc:       make(map[*Conn]int),
+		failed:   make(map[cipher.SHA256][]*Conn),
+	}
+
+	for _, c := range cs {
+		c.n = n
+		f.cs.addKnown(c, 0)
+		f.fc[c] = 0
+	}
+
+	return
+}
+
+func TestFillHead_chooseConn(t *testing.T) {
+
+	var (
+		conf = NewConfig()
+
+		c1, c2 = new(Conn), new(Conn)
+		f      = getTestFillHead(conf, c1, c2)
+
+		key = cipher.SumSHA256([]byte("key"))
+	)
+
+	conf.MaxInFlight = 2
+
+	// less requests in flight
+	f.fc[c1] = 1
+	if c := f.chooseConn(key); c != c2 {
+		t.Error("busy connection chosen")
+	}
+
+	// limit
+	f.fc[c1], f.fc[c2] = 2, 2
+	if c := f.chooseConn(key); c != nil {
+		t.Error("connection over the limit chosen")
+	}
+
+	if f.hasFreeConn() == true {
+		t.Error("unexpected free connection")
+	}
+
+	// failed
+	f.fc[c1], f.fc[c2] = 0, 1
+	f.failed[key] = []*Conn{c1}
+	if c := f.chooseConn(key); c != c2 {
+		t.Error("failed connection chosen")
+	}
+
+	// no limit
+	conf.MaxInFlight = 0
+	f.fc[c2] = 100
+	if c := f.chooseConn(key); c != c2 || f.hasFreeConn() == false {
+		t.Error("limited")
+	}
+
+	// removed from the head
+	delete(f.cs, c2)
+	if c := f.chooseConn(cipher.SHA256{}); c != c1 {
+		t.Error("removed connection chosen")
+	}
+	if _, ok := f.fc[c2]; ok == true {
+		t.Error("removed connection is not released")
+	}
+
+}
+
+func TestFillHead_chooseConn_latency(t *testing.T) {
+
+	var (
+		conf = NewConfig()
+
+		fast, slow = new(Conn), new(Conn)
+		f          = getTestFillHead(conf, fast, slow)
+
+		key = cipher.SumSHA256([]byte("key"))
+	)
+
+	fast.addServiceTime(10 * time.Millisecond)
+	slow.addServiceTime(100 * time.Millisecond)
+
+	// faster, even if busy
+	f.fc[fast] = 2
+	if c := f.chooseConn(key); c != fast {
+		t.Error("slow connection chosen")
+	}
+
+	// too busy
+	f.fc[fast] = 10
+	if c := f.chooseConn(key); c != slow {
+		t.Error("overloaded connection chosen")
+	}
+
+	// faster, but misses
+	f.fc[fast] = 0
+	for i := 0; i < conf.Config.RollAvgSamples; i++ {
+		fast.addRequestResult(true)
+	}
+	if c := f.chooseConn(key); c != slow {
+		t.Error("missing connection chosen")
+	}
+
+	// unknown is tried first
+	var unknown = new(Conn)
+	unknown.n = fast.n
+	f.cs.addKnown(unknown, 0)
+	f.fc[unknown] = 0
+	if c := f.chooseConn(key); c != unknown {
+		t.Error("new connection is not tried")
+	}
+
+}
+
+func TestFillHead_retry(t *testing.T) {
+
+	var (
+		conf = NewConfig()
+
+		c1, c2 = new(Conn), new(Conn)
+		f      = getTestFillHead(conf, c1, c2)
+
+		key = cipher.SumSHA256([]byte("key"))
+		err = errors.New("test")
+	)
+
+	conf.FillRetries = 1
+
+	if f.retry(failedRequest{c: c1, key: key, err: err}) == false {
+		t.Fatal("not retried")
+	}
+
+	if f.isFailed(key, c1) == false || f.isFailed(key, c2) == true {
+		t.Error("wrong failed connections")
+	}
+
+	if f.retry(failedRequest{c: c2, key: key, err: err}) == true {
+		t.Error("too many retries")
+	}
+
+}
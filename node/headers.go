@@ -0,0 +1,184 @@
+package node
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// headers-only feeds of the Node (see SetHeadersOnly);
+// the feed -> nonce -> last received Root
+type headerFeeds struct {
+	mx sync.Mutex
+	fs map[cipher.PubKey]map[uint64]connRoot
+}
+
+func newHeaderFeeds() (h *headerFeeds) {
+	h = new(headerFeeds)
+	h.fs = make(map[cipher.PubKey]map[uint64]connRoot)
+	return
+}
+
+func (h *headerFeeds) set(feed cipher.PubKey, headersOnly bool) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	if headersOnly == false {
+		delete(h.fs, feed)
+		return
+	}
+
+	if _, ok := h.fs[feed]; ok == false {
+		h.fs[feed] = make(map[uint64]connRoot)
+	}
+}
+
+func (h *headerFeeds) has(feed cipher.PubKey) (ok bool) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	_, ok = h.fs[feed]
+	return
+}
+
+// remember received Root, the add returns false
+// if the Root is not newer than known one or the
+// feed is not headers-only
+func (h *headerFeeds) add(cr connRoot) (ok bool) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	var hs, has = h.fs[cr.r.Pub]
+
+	if has == false {
+		return
+	}
+
+	if last, known := hs[cr.r.Nonce]; known == true && last.r.Seq >= cr.r.Seq {
+		return
+	}
+
+	hs[cr.r.Nonce] = connRoot{c: cr.c, r: cr.r} // drop delta
+	return true
+}
+
+// find received Root by seq
+func (h *headerFeeds) get(feed cipher.PubKey, seq uint64) (cr connRoot, ok bool) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	for _, cr = range h.fs[feed] {
+		if cr.r.Seq == seq {
+			return cr, true
+		}
+	}
+
+	return connRoot{}, false
+}
+
+func (h *headerFeeds) list(feed cipher.PubKey) (rs []*registry.Root) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	var hs = h.fs[feed]
+
+	if len(hs) == 0 {
+		return
+	}
+
+	rs = make([]*registry.Root, 0, len(hs))
+	for _, cr := range hs {
+		rs = append(rs, cr.r)
+	}
+
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].Nonce < rs[j].Nonce
+	})
+
+	return
+}
+
+// a Root of a headers-only feed received
+func (c *Conn) receivedHeader(r *registry.Root) {
+
+	// the connection should be subscribed to the feed
+	if c.n.fs.hasConnFeed(c, r.Pub) == false {
+		return
+	}
+
+	if reject := c.n.onRootReceived(c, r); reject != nil {
+		c.sendRootReject(r.Pub, r.Nonce, r.Seq, msg.RejectPolicy, reject)
+		return
+	}
+
+	c.n.hf.add(connRoot{c: c, r: r})
+}
+
+// SetHeadersOnly makes the Node an observer of given
+// feed. The Node receives Root objects of a headers-only
+// feed and calls the OnRootReceived callback, but it
+// doesn't fill them. Objects sent with a Root (see
+// Config.RootDelta) are dropped. Use Headers to get last
+// received Root objects, and Fill to fill one of them
+// on demand. Call the SetHeadersOnly with false to fill
+// received Root objects automatically again. Remote
+// peers are not informed about the mode
+func (n *Node) SetHeadersOnly(
+	feed cipher.PubKey, // : the feed
+	headersOnly bool, //   : the mode
+) (
+	err error, //          : ErrBlankFeed
+) {
+
+	if feed == (cipher.PubKey{}) {
+		return ErrBlankFeed
+	}
+
+	n.hf.set(feed, headersOnly)
+	return
+}
+
+// IsHeadersOnly returns true if given feed
+// is headers-only (see SetHeadersOnly)
+func (n *Node) IsHeadersOnly(feed cipher.PubKey) (ok bool) {
+	return n.hf.has(feed)
+}
+
+// Headers returns last received Root of every head
+// of given headers-only feed, ordered by nonce. The
+// Root objects are read-only
+func (n *Node) Headers(feed cipher.PubKey) (rs []*registry.Root) {
+	return n.hf.list(feed)
+}
+
+// Fill fills Root of given headers-only feed with given
+// seq. The Root should be received (see Headers). The Fill
+// doesn't wait the filling; use the OnRootFilled and the
+// OnFillingBreaks callbacks to get result. The Root is
+// filled from connection it has been received from and
+// from other peers that have it
+func (n *Node) Fill(feed cipher.PubKey, seq uint64) (err error) {
+
+	if feed == (cipher.PubKey{}) {
+		return ErrBlankFeed
+	}
+
+	var cr, ok = n.hf.get(feed, seq)
+
+	if ok == false {
+		return ErrUnknownRoot
+	}
+
+	select {
+	case <-cr.c.closeq:
+		return ErrClosed
+	default:
+	}
+
+	n.fs.receivedRoot(cr)
+	return
+}
@@ -0,0 +1,68 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_SetHeadersOnly(t *testing.T) {
+
+	var n = getTestNodeNotListen("observer")
+	defer n.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	if err := n.SetHeadersOnly(cipher.PubKey{}, true); err != ErrBlankFeed {
+		t.Error("wrong error:", err)
+	}
+
+	assertNil(t, n.SetHeadersOnly(pk, true))
+
+	if n.IsHeadersOnly(pk) == false {
+		t.Error("not headers-only")
+	}
+
+	var c = &Conn{n: n, closeq: make(chan struct{})}
+
+	for _, r := range []*registry.Root{
+		{Pub: pk, Nonce: 2, Seq: 1},
+		{Pub: pk, Nonce: 1, Seq: 5},
+		{Pub: pk, Nonce: 1, Seq: 3}, // old
+	} {
+		n.hf.add(connRoot{c: c, r: r})
+	}
+
+	var rs = n.Headers(pk)
+
+	if len(rs) != 2 {
+		t.Fatal("wrong number of headers:", len(rs))
+	}
+
+	if rs[0].Nonce != 1 || rs[0].Seq != 5 || rs[1].Nonce != 2 || rs[1].Seq != 1 {
+		t.Error("wrong headers")
+	}
+
+	if err := n.Fill(pk, 3); err != ErrUnknownRoot {
+		t.Error("wrong error:", err)
+	}
+
+	close(c.closeq)
+
+	if err := n.Fill(pk, 5); err != ErrClosed {
+		t.Error("wrong error:", err)
+	}
+
+	assertNil(t, n.SetHeadersOnly(pk, false))
+
+	if n.IsHeadersOnly(pk) == true {
+		t.Error("headers-only")
+	}
+
+	if len(n.Headers(pk)) != 0 {
+		t.Error("headers of regular feed")
+	}
+
+}
@@ -0,0 +1,249 @@
+package node
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skycoin/net/factory"
+
+	"github.com/skycoin/cxo/node/kcp"
+)
+
+// hole punching
+const (
+	punchInterval = 100 * time.Millisecond
+	punchAttempts = 30
+)
+
+// A KCP represents KCP transport of the Node (see
+// kcp package). The KCP works over UDP, and it's
+// better for high-latency or lossy networks, where
+// throughput of TCP collapses. KCP connections use
+// the UDP configurations (response timeout and pings)
+// and the Config.KCP
+type KCP struct {
+	// back reference
+	n *Node
+
+	mx sync.Mutex
+
+	l *kcp.Listener // listener or nil
+
+	address string // listening address
+
+	cs  map[string]*Conn                 // address -> conn
+	fcs map[*factory.Connection]struct{} // underlying connections
+}
+
+func newKCP(n *Node) (k *KCP) {
+
+	k = new(KCP)
+
+	k.n = n
+	k.cs = make(map[string]*Conn)
+	k.fcs = make(map[*factory.Connection]struct{})
+
+	return
+}
+
+// Listen on given UDP address. It's possible
+// to listen only once
+func (k *KCP) Listen(address string) (err error) {
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	if k.l != nil {
+		return ErrAlreadyListen
+	}
+
+	if k.l, err = kcp.Listen(address, &k.n.config.KCP); err != nil {
+		return
+	}
+
+	k.address = address
+
+	k.n.await.Add(1)
+	go k.accept(k.l)
+
+	return
+}
+
+func (k *KCP) accept(l *kcp.Listener) {
+	defer k.n.await.Done()
+
+	for {
+
+		var s, err = l.Accept()
+		if err != nil {
+			return
+		}
+
+		var fc = k.newConnection(s)
+
+		if fc == nil {
+			return // closed
+		}
+
+		go k.n.acceptConnection(fc)
+	}
+}
+
+// Address returns listening address as it
+// passed to the Listen method. The address
+// is blank string if the KCP is not listening
+func (k *KCP) Address() string {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	return k.address
+}
+
+// listening port or zero if the KCP is not listening
+func (k *KCP) port() uint16 {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	if k.l == nil {
+		return 0
+	}
+
+	return uint16(k.l.Addr().(*net.UDPAddr).Port)
+}
+
+// (async) send punches to given address to open NAT
+// for incoming connection from the address
+func (k *KCP) punch(address string) {
+	defer k.n.await.Done()
+
+	k.mx.Lock()
+	var l = k.l
+	k.mx.Unlock()
+
+	if l == nil {
+		return
+	}
+
+	var tk = time.NewTicker(punchInterval)
+	defer tk.Stop()
+
+	for i := 0; i < punchAttempts; i++ {
+
+		if err := l.Punch(address); err != nil {
+			k.n.Debugf(ConnPin, "[kcp] punch %s: %v", address, err)
+			return
+		}
+
+		select {
+		case <-tk.C:
+		case <-k.n.closeq:
+			return
+		}
+	}
+}
+
+// wrap given session
+func (k *KCP) newConnection(s *kcp.Session) (fc *factory.Connection) {
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	if k.fcs == nil {
+		s.Close()
+		return // closed
+	}
+
+	fc = newFramedConn(s, "kcp", false)
+	k.fcs[fc] = struct{}{}
+
+	go k.waitDisconnected(fc)
+	return
+}
+
+func (k *KCP) waitDisconnected(fc *factory.Connection) {
+
+	fc.WaitForDisconnected()
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	delete(k.fcs, fc)
+}
+
+func (k *KCP) addAcceptedConnection(c *Conn) {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	k.cs[c.Address()] = c
+}
+
+// Connect to given address. The address is host:port
+// or kcp://host:port. The method blocks. If connection
+// with given address already exists, then the Connect
+// returns this existing connection. If the KCP is
+// listening, then it connects from the listening port
+// (see Rendezvous)
+func (k *KCP) Connect(address string) (c *Conn, err error) {
+
+	address = strings.TrimPrefix(address, "kcp://")
+
+	if address, err = normalizeHostPort(address); err != nil {
+		return
+	}
+
+	k.mx.Lock()
+	var ok bool
+	if c, ok = k.cs[address]; ok == true && c.isClosed() == false {
+		k.mx.Unlock()
+		return // already have
+	}
+	var l = k.l
+	k.mx.Unlock()
+
+	var s *kcp.Session
+	if l != nil {
+		s, err = l.Dial(address)
+	} else {
+		s, err = kcp.Dial(address, &k.n.config.KCP)
+	}
+
+	if err != nil {
+		return
+	}
+
+	var fc = k.newConnection(s)
+
+	if fc == nil {
+		return nil, ErrClosed
+	}
+
+	if c, err = k.n.wrapConnection(fc, false); err != nil {
+		return
+	}
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	k.cs[address] = c
+	return
+}
+
+// Close the KCP
+func (k *KCP) Close() (err error) {
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	if k.l != nil {
+		err = k.l.Close()
+	}
+
+	for fc := range k.fcs {
+		fc.Close()
+	}
+
+	k.fcs = nil
+	return
+}
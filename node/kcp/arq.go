@@ -0,0 +1,524 @@
+package kcp
+
+import (
+	"encoding/binary"
+)
+
+// commands
+const (
+	cmdPush  byte = 81 // data
+	cmdAck   byte = 82 // acknowledgement
+	cmdClose byte = 85 // the session closed (not a part of KCP)
+)
+
+const (
+	overhead = 24 // header of a segment
+	deadLink = 20 // max transmissions of a segment
+
+	rtoNoDelay = 30    // min RTO with NoDelay, ms
+	rtoMin     = 100   // min RTO, ms
+	rtoDefault = 200   // initial RTO, ms
+	rtoMax     = 60000 // max RTO, ms
+)
+
+// a segment of KCP
+//
+//	[4 conv][1 cmd][1 frg][2 wnd][4 ts][4 sn][4 una][4 len][data]
+//
+// all numbers are little-endian
+type segment struct {
+	conv uint32 // conversation id
+	cmd  byte   // command
+	frg  byte   // number of next fragments of a message
+	wnd  uint16 // free receive window of the sender
+	ts   uint32 // timestamp
+	sn   uint32 // serial number
+	una  uint32 // all segments before are received
+	data []byte // payload
+
+	resendts uint32 // retransmission time
+	rto      uint32 // retransmission timeout
+	fastack  uint32 // number of acks of segments after
+	xmit     uint32 // number of transmissions
+}
+
+func (s *segment) encode(p []byte) []byte {
+
+	var hdr [overhead]byte
+
+	binary.LittleEndian.PutUint32(hdr[0:], s.conv)
+	hdr[4] = s.cmd
+	hdr[5] = s.frg
+	binary.LittleEndian.PutUint16(hdr[6:], s.wnd)
+	binary.LittleEndian.PutUint32(hdr[8:], s.ts)
+	binary.LittleEndian.PutUint32(hdr[12:], s.sn)
+	binary.LittleEndian.PutUint32(hdr[16:], s.una)
+	binary.LittleEndian.PutUint32(hdr[20:], uint32(len(s.data)))
+
+	p = append(p, hdr[:]...)
+	return append(p, s.data...)
+}
+
+// an ack to send
+type ack struct {
+	sn, ts uint32
+}
+
+func umin(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func umax(a, b uint32) uint32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// signed difference of two timestamps or serial numbers
+func diff(later, earlier uint32) int32 {
+	return int32(later - earlier)
+}
+
+// the ARQ of KCP without congestion control, it's
+// not thread-safe, the Session locks it
+type arq struct {
+	conv uint32
+	mtu  uint32 // max size of packet
+	mss  uint32 // max size of segment payload
+
+	sndUna uint32 // first unacknowledged
+	sndNxt uint32 // next to send
+	rcvNxt uint32 // next to receive
+
+	sndWnd uint32 // send window
+	rcvWnd uint32 // receive window
+	rmtWnd uint32 // remote receive window
+
+	srtt   int32  // smoothed RTT
+	rttval int32  // RTT variation
+	rto    uint32 // retransmission timeout
+	minrto uint32 // min RTO
+
+	interval uint32 // update interval, ms
+	nodelay  bool   // don't double RTO
+	resend   uint32 // fast retransmission, 0 disables
+
+	current uint32 // current time, ms
+
+	sndQueue []*segment // waiting for window
+	sndBuf   []*segment // sent, not acknowledged
+	rcvBuf   []*segment // received out of order
+	rcvQueue []*segment // ready to be read
+
+	acks []ack // acks to send
+
+	dead   bool // a segment is not delivered after deadLink tries
+	closed bool // remote peer has sent the cmdClose
+
+	buf    []byte       // output buffer
+	output func([]byte) // send packet
+}
+
+func newARQ(conv uint32, c *Config, output func([]byte)) (a *arq) {
+
+	a = new(arq)
+
+	a.conv = conv
+	a.mtu = uint32(c.MTU)
+	a.mss = a.mtu - overhead
+
+	a.sndWnd = uint32(c.Window)
+	a.rcvWnd = uint32(c.Window)
+	a.rmtWnd = uint32(c.Window)
+
+	a.rto = rtoDefault
+	a.minrto = rtoMin
+	if c.NoDelay == true {
+		a.minrto = rtoNoDelay
+	}
+
+	a.interval = uint32(c.Interval.Nanoseconds() / 1e6)
+	a.nodelay = c.NoDelay
+	a.resend = uint32(c.Resend)
+
+	a.buf = make([]byte, 0, a.mtu)
+	a.output = output
+	return
+}
+
+// number of segments waiting for sending and acks
+func (a *arq) waitSnd() int {
+	return len(a.sndQueue) + len(a.sndBuf)
+}
+
+// free space of the receive queue
+func (a *arq) wndUnused() uint16 {
+	if n := uint32(len(a.rcvQueue)); n < a.rcvWnd {
+		return uint16(a.rcvWnd - n)
+	}
+	return 0
+}
+
+// send given message fragmenting it
+func (a *arq) send(p []byte) (err error) {
+
+	var count = (uint32(len(p)) + a.mss - 1) / a.mss
+
+	if count == 0 {
+		count = 1 // empty message
+	}
+
+	if count > 255 || count >= a.rcvWnd {
+		return ErrMessageSize
+	}
+
+	for i := uint32(0); i < count; i++ {
+
+		var size = uint32(len(p))
+		if size > a.mss {
+			size = a.mss
+		}
+
+		var seg = &segment{
+			frg:  byte(count - i - 1),
+			data: append([]byte{}, p[:size]...),
+		}
+
+		a.sndQueue = append(a.sndQueue, seg)
+		p = p[size:]
+	}
+
+	return
+}
+
+// receive next message or nil
+func (a *arq) recv() (p []byte) {
+
+	if len(a.rcvQueue) == 0 {
+		return
+	}
+
+	var count = int(a.rcvQueue[0].frg) + 1
+
+	if len(a.rcvQueue) < count {
+		return // not all fragments received
+	}
+
+	for _, seg := range a.rcvQueue[:count] {
+		p = append(p, seg.data...)
+	}
+
+	if p == nil {
+		p = []byte{} // empty message
+	}
+
+	a.rcvQueue = append(a.rcvQueue[:0], a.rcvQueue[count:]...)
+	a.moveReceived()
+	return
+}
+
+// move in-order segments from the rcvBuf to the rcvQueue
+func (a *arq) moveReceived() {
+
+	var i int
+	for ; i < len(a.rcvBuf); i++ {
+		var seg = a.rcvBuf[i]
+		if seg.sn != a.rcvNxt || uint32(len(a.rcvQueue)) >= a.rcvWnd {
+			break
+		}
+		a.rcvQueue = append(a.rcvQueue, seg)
+		a.rcvNxt++
+	}
+
+	a.rcvBuf = append(a.rcvBuf[:0], a.rcvBuf[i:]...)
+}
+
+func (a *arq) updateRTT(rtt int32) {
+
+	if a.srtt == 0 {
+		a.srtt, a.rttval = rtt, rtt/2
+	} else {
+		var delta = rtt - a.srtt
+		if delta < 0 {
+			delta = -delta
+		}
+		a.rttval = (3*a.rttval + delta) / 4
+		if a.srtt = (7*a.srtt + rtt) / 8; a.srtt < 1 {
+			a.srtt = 1
+		}
+	}
+
+	var rto = uint32(a.srtt) + umax(a.interval, uint32(4*a.rttval))
+
+	a.rto = umin(umax(rto, a.minrto), rtoMax)
+}
+
+// remove all acknowledged before the una
+func (a *arq) parseUna(una uint32) {
+
+	var i int
+	for ; i < len(a.sndBuf); i++ {
+		if diff(una, a.sndBuf[i].sn) <= 0 {
+			break
+		}
+	}
+
+	a.sndBuf = append(a.sndBuf[:0], a.sndBuf[i:]...)
+	a.shrinkBuf()
+}
+
+// remove acknowledged segment
+func (a *arq) parseAck(sn uint32) {
+
+	if diff(sn, a.sndUna) < 0 || diff(sn, a.sndNxt) >= 0 {
+		return
+	}
+
+	for i, seg := range a.sndBuf {
+		if seg.sn == sn {
+			a.sndBuf = append(a.sndBuf[:i], a.sndBuf[i+1:]...)
+			break
+		}
+		if diff(sn, seg.sn) < 0 {
+			break
+		}
+	}
+
+	a.shrinkBuf()
+}
+
+// count acks of segments after the sent ones
+func (a *arq) parseFastack(sn uint32) {
+
+	if diff(sn, a.sndUna) < 0 || diff(sn, a.sndNxt) >= 0 {
+		return
+	}
+
+	for _, seg := range a.sndBuf {
+		if diff(sn, seg.sn) <= 0 {
+			break
+		}
+		seg.fastack++
+	}
+}
+
+func (a *arq) shrinkBuf() {
+	if len(a.sndBuf) > 0 {
+		a.sndUna = a.sndBuf[0].sn
+	} else {
+		a.sndUna = a.sndNxt
+	}
+}
+
+// put received segment to the rcvBuf
+func (a *arq) parseData(seg *segment) {
+
+	if diff(seg.sn, a.rcvNxt+a.rcvWnd) >= 0 || diff(seg.sn, a.rcvNxt) < 0 {
+		return
+	}
+
+	var i = len(a.rcvBuf)
+	for ; i > 0; i-- {
+		var prev = a.rcvBuf[i-1]
+		if prev.sn == seg.sn {
+			return // duplicate
+		}
+		if diff(seg.sn, prev.sn) > 0 {
+			break
+		}
+	}
+
+	a.rcvBuf = append(a.rcvBuf, nil)
+	copy(a.rcvBuf[i+1:], a.rcvBuf[i:])
+	a.rcvBuf[i] = seg
+
+	a.moveReceived()
+}
+
+// process received packet
+func (a *arq) input(p []byte) (err error) {
+
+	var (
+		maxack uint32
+		acked  bool
+	)
+
+	for len(p) > 0 {
+
+		if len(p) < overhead {
+			return ErrMalformed
+		}
+
+		var seg = new(segment)
+
+		seg.conv = binary.LittleEndian.Uint32(p[0:])
+		seg.cmd = p[4]
+		seg.frg = p[5]
+		seg.wnd = binary.LittleEndian.Uint16(p[6:])
+		seg.ts = binary.LittleEndian.Uint32(p[8:])
+		seg.sn = binary.LittleEndian.Uint32(p[12:])
+		seg.una = binary.LittleEndian.Uint32(p[16:])
+
+		var ln = binary.LittleEndian.Uint32(p[20:])
+		p = p[overhead:]
+
+		if seg.conv != a.conv {
+			return ErrMalformed
+		}
+
+		if uint32(len(p)) < ln {
+			return ErrMalformed
+		}
+
+		a.rmtWnd = uint32(seg.wnd)
+		a.parseUna(seg.una)
+
+		switch seg.cmd {
+
+		case cmdAck:
+
+			if rtt := diff(a.current, seg.ts); rtt >= 0 {
+				a.updateRTT(rtt)
+			}
+
+			a.parseAck(seg.sn)
+
+			if acked == false || diff(seg.sn, maxack) > 0 {
+				acked, maxack = true, seg.sn
+			}
+
+		case cmdPush:
+
+			if diff(seg.sn, a.rcvNxt+a.rcvWnd) < 0 {
+				a.acks = append(a.acks, ack{seg.sn, seg.ts})
+				if diff(seg.sn, a.rcvNxt) >= 0 {
+					seg.data = append([]byte{}, p[:ln]...)
+					a.parseData(seg)
+				}
+			}
+
+		case cmdClose:
+
+			a.closed = true
+
+		default:
+
+			return ErrMalformed
+
+		}
+
+		p = p[ln:]
+	}
+
+	if acked == true {
+		a.parseFastack(maxack)
+	}
+
+	return
+}
+
+// add segment to the output buffer
+func (a *arq) emit(seg *segment) {
+	if uint32(len(a.buf)+overhead+len(seg.data)) > a.mtu {
+		a.output(a.buf)
+		a.buf = a.buf[:0]
+	}
+	a.buf = seg.encode(a.buf)
+}
+
+// send acks, new segments and retransmissions
+func (a *arq) flush() {
+
+	var (
+		wnd = a.wndUnused()
+		seg = segment{conv: a.conv, cmd: cmdAck, wnd: wnd, una: a.rcvNxt}
+	)
+
+	for _, ak := range a.acks {
+		seg.sn, seg.ts = ak.sn, ak.ts
+		a.emit(&seg)
+	}
+
+	a.acks = a.acks[:0]
+
+	// the remote window is zero, a segment
+	// is sent anyway to find out its window
+
+	var cwnd = umax(umin(a.sndWnd, a.rmtWnd), 1)
+
+	for len(a.sndQueue) > 0 && diff(a.sndNxt, a.sndUna+cwnd) < 0 {
+
+		var s = a.sndQueue[0]
+		a.sndQueue = a.sndQueue[1:]
+
+		s.conv, s.cmd, s.sn = a.conv, cmdPush, a.sndNxt
+		a.sndNxt++
+
+		a.sndBuf = append(a.sndBuf, s)
+	}
+
+	for _, s := range a.sndBuf {
+
+		var send bool
+
+		switch {
+
+		case s.xmit == 0:
+
+			send = true
+			s.rto = a.rto
+			s.resendts = a.current + s.rto
+
+		case diff(a.current, s.resendts) >= 0:
+
+			send = true
+			if a.nodelay == true {
+				s.rto += a.rto / 2
+			} else {
+				s.rto += umax(s.rto, a.rto)
+			}
+			s.rto = umin(s.rto, rtoMax)
+			s.resendts = a.current + s.rto
+
+		case a.resend > 0 && s.fastack >= a.resend:
+
+			send = true
+			s.fastack = 0
+			s.resendts = a.current + s.rto
+
+		}
+
+		if send == false {
+			continue
+		}
+
+		s.xmit++
+		s.ts, s.wnd, s.una = a.current, wnd, a.rcvNxt
+		a.emit(s)
+
+		if s.xmit >= deadLink {
+			a.dead = true
+		}
+	}
+
+	if len(a.buf) > 0 {
+		a.output(a.buf)
+		a.buf = a.buf[:0]
+	}
+}
+
+// send the cmdClose
+func (a *arq) close() {
+	a.emit(&segment{
+		conv: a.conv,
+		cmd:  cmdClose,
+		wnd:  a.wndUnused(),
+		una:  a.rcvNxt,
+	})
+	a.output(a.buf)
+	a.buf = a.buf[:0]
+}
@@ -0,0 +1,212 @@
+package kcp
+
+import (
+	"encoding/binary"
+)
+
+// FEC packet
+//
+//     [4 seqid][1 kind][payload]
+//
+// packets are grouped by the Config.FEC data packets
+// and one parity packet; the parity packet is XOR of
+// payloads of the data packets, thus any one lost
+// packet of a group can be recovered; payload of a
+// data packet is
+//
+//     [2 size][KCP packet]
+//
+// to restore size of recovered packet
+
+const (
+	fecData   byte = 0xf1
+	fecParity byte = 0xf2
+
+	fecHeader   = 5             // seqid and kind
+	fecOverhead = fecHeader + 2 // with size of data
+	fecGroups   = 64            // groups kept by decoder
+)
+
+func fecPacket(seqid uint32, kind byte, payload []byte) (p []byte) {
+	p = make([]byte, fecHeader, fecHeader+len(payload))
+	binary.LittleEndian.PutUint32(p, seqid)
+	p[4] = kind
+	return append(p, payload...)
+}
+
+// p ^= q, the p extended if it's shorter
+func xorInto(p, q []byte) []byte {
+	for len(p) < len(q) {
+		p = append(p, 0)
+	}
+	for i, b := range q {
+		p[i] ^= b
+	}
+	return p
+}
+
+type fecEncoder struct {
+	shards int    // data packets in group
+	next   uint32 // next seqid
+	count  int    // data packets of current group
+	parity []byte // XOR of the data packets
+}
+
+func newFECEncoder(shards int) *fecEncoder {
+	return &fecEncoder{shards: shards}
+}
+
+// wrap given KCP packet, the encode returns
+// the data packet and parity packet if the
+// group is complete
+func (f *fecEncoder) encode(kp []byte) (ps [][]byte) {
+
+	var payload = make([]byte, 2, 2+len(kp))
+	binary.LittleEndian.PutUint16(payload, uint16(len(kp)))
+	payload = append(payload, kp...)
+
+	ps = append(ps, fecPacket(f.next, fecData, payload))
+	f.next++
+
+	f.parity = xorInto(f.parity, payload)
+
+	if f.count++; f.count == f.shards {
+		ps = append(ps, fecPacket(f.next, fecParity, f.parity))
+		f.next++
+
+		f.count, f.parity = 0, f.parity[:0]
+	}
+
+	return
+}
+
+type fecGroup struct {
+	shards [][]byte // received payloads, the last is parity
+	count  int      // received
+	done   bool     // all data packets delivered
+}
+
+type fecDecoder struct {
+	shards int                  // data packets in group
+	groups map[uint32]*fecGroup // by group id
+	newest uint32               // newest group id
+}
+
+func newFECDecoder(shards int) *fecDecoder {
+	return &fecDecoder{
+		shards: shards,
+		groups: make(map[uint32]*fecGroup),
+	}
+}
+
+// unwrap payload of data packet
+func fecUnwrap(payload []byte) []byte {
+
+	if len(payload) < 2 {
+		return nil
+	}
+
+	var size = int(binary.LittleEndian.Uint16(payload))
+
+	if size > len(payload)-2 {
+		return nil
+	}
+
+	return payload[2 : 2+size]
+}
+
+// decode received packet, the decode returns
+// KCP packets received and recovered
+func (f *fecDecoder) decode(p []byte) (kps [][]byte) {
+
+	if len(p) < fecHeader {
+		return
+	}
+
+	var (
+		seqid = binary.LittleEndian.Uint32(p)
+		kind  = p[4]
+
+		size = uint32(f.shards + 1)
+		gid  = seqid / size
+		idx  = int(seqid % size)
+	)
+
+	if kind != fecData && kind != fecParity ||
+		(kind == fecParity) != (idx == f.shards) {
+
+		return // malformed
+	}
+
+	if diff(f.newest, gid) >= fecGroups {
+		return // too old
+	}
+
+	var g, ok = f.groups[gid]
+
+	if ok == false {
+		g = &fecGroup{shards: make([][]byte, size)}
+		f.groups[gid] = g
+		f.evict(gid)
+	}
+
+	if g.done == true || g.shards[idx] != nil {
+		return // duplicate
+	}
+
+	var payload = append([]byte{}, p[fecHeader:]...)
+	g.shards[idx] = payload
+	g.count++
+
+	if kind == fecData {
+		if kp := fecUnwrap(payload); kp != nil {
+			kps = append(kps, kp)
+		}
+	}
+
+	if g.count < f.shards {
+		return // can't recover
+	}
+
+	g.done = true
+
+	if g.shards[f.shards] == nil {
+		return // all data packets received
+	}
+
+	// recover the lost one
+
+	var lost []byte
+	for _, shard := range g.shards {
+		if shard != nil {
+			lost = xorInto(lost, shard)
+		}
+	}
+
+	for _, shard := range g.shards[:f.shards] {
+		if shard == nil {
+			if kp := fecUnwrap(lost); kp != nil {
+				kps = append(kps, kp)
+			}
+			break
+		}
+	}
+
+	return
+}
+
+// remove old groups
+func (f *fecDecoder) evict(gid uint32) {
+
+	if diff(gid, f.newest) <= 0 {
+		return
+	}
+
+	f.newest = gid
+
+	for id := range f.groups {
+		if diff(f.newest, id) >= fecGroups {
+			delete(f.groups, id)
+		}
+	}
+}
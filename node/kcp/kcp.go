@@ -0,0 +1,585 @@
+// Package kcp implements KCP protocol (reliable ARQ
+// over UDP) used by KCP transport of the node package.
+// The KCP retransmits lost packets faster than TCP, and
+// it doesn't reduce throughput on packet loss. Thus it
+// works better for high-latency or lossy networks. The
+// package implements message mode of the KCP without
+// congestion control. Optional FEC (forward error
+// correction) adds a parity packet to every group of
+// data packets (see Config.FEC). Both sides must use
+// the same FEC configurations
+package kcp
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// defaults
+const (
+	Window   int           = 128                   // segments
+	MTU      int           = 1400                  // bytes
+	Interval time.Duration = 10 * time.Millisecond // update interval
+	NoDelay  bool          = true                  // fast RTO
+	Resend   int           = 2                     // fast retransmission
+	FEC      int           = 0                     // no FEC
+)
+
+// errors
+var (
+	ErrMessageSize = errors.New("kcp: message is too large")
+	ErrMalformed   = errors.New("kcp: malformed packet")
+	ErrClosed      = errors.New("kcp: closed")
+	ErrDeadLink    = errors.New("kcp: dead link")
+
+	ErrSessionExists = errors.New("kcp: session with the address exists")
+)
+
+// A Config represents configurations of the KCP
+type Config struct {
+	// Window is size of send and receive windows
+	// in segments. A message can't be fragmented
+	// to Window segments or more
+	Window int
+	// MTU is max size of UDP packet
+	MTU int
+	// Interval of retransmissions
+	Interval time.Duration
+	// NoDelay reduces min retransmission timeout
+	// and its growth
+	NoDelay bool
+	// Resend is number of acks of segments after
+	// a segment to retransmit it, zero disables
+	// the fast retransmission
+	Resend int
+	// FEC is number of data packets for one parity
+	// packet, zero disables the FEC. The FEC can
+	// recover one lost packet of a group, but the
+	// traffic grows by 1/FEC
+	FEC int
+}
+
+// NewConfig returns Config with defaults
+func NewConfig() (c Config) {
+	c.Window = Window
+	c.MTU = MTU
+	c.Interval = Interval
+	c.NoDelay = NoDelay
+	c.Resend = Resend
+	c.FEC = FEC
+	return
+}
+
+// Validate the Config
+func (c *Config) Validate() (err error) {
+
+	if c.Window < 2 || c.Window > 0xffff {
+		return fmt.Errorf("kcp: invalid Window %d, must be in [2, %d]",
+			c.Window, 0xffff)
+	}
+
+	if c.MTU < 64 || c.MTU > 0xffff {
+		return fmt.Errorf("kcp: invalid MTU %d, must be in [64, %d]",
+			c.MTU, 0xffff)
+	}
+
+	if c.Interval < time.Millisecond {
+		return fmt.Errorf("kcp: invalid Interval %s, must be 1ms or more",
+			c.Interval)
+	}
+
+	if c.Resend < 0 {
+		return fmt.Errorf("kcp: negative Resend %d", c.Resend)
+	}
+
+	if c.FEC < 0 {
+		return fmt.Errorf("kcp: negative FEC %d", c.FEC)
+	}
+
+	return
+}
+
+// mtu of KCP packets
+func (c *Config) mtu() int {
+	if c.FEC > 0 {
+		return c.MTU - fecOverhead
+	}
+	return c.MTU
+}
+
+// A Session represents KCP connection
+type Session struct {
+	mx sync.Mutex
+	rc *sync.Cond // received
+	wc *sync.Cond // sent
+
+	arq *arq
+	enc *fecEncoder // or nil
+	dec *fecDecoder // or nil
+
+	conn   net.PacketConn // UDP
+	remote net.Addr       // remote address
+	l      *Listener      // or nil for client
+
+	start time.Time // for timestamps
+
+	err    error         // closed by
+	closeq chan struct{} //
+}
+
+func newSession(
+	conv uint32, //        : conversation id
+	conf *Config, //       : configurations
+	conn net.PacketConn, // : UDP
+	remote net.Addr, //    : remote address
+	l *Listener, //        : listener or nil
+) (
+	s *Session, //         : new session
+) {
+
+	s = new(Session)
+
+	s.rc = sync.NewCond(&s.mx)
+	s.wc = sync.NewCond(&s.mx)
+
+	var c = *conf
+	c.MTU = conf.mtu()
+	s.arq = newARQ(conv, &c, s.output)
+
+	if conf.FEC > 0 {
+		s.enc = newFECEncoder(conf.FEC)
+		s.dec = newFECDecoder(conf.FEC)
+	}
+
+	s.conn = conn
+	s.remote = remote
+	s.l = l
+
+	s.start = time.Now()
+	s.closeq = make(chan struct{})
+
+	go s.update(conf.Interval)
+	return
+}
+
+// Dial KCP server, the address is host:port
+func Dial(address string, conf *Config) (s *Session, err error) {
+
+	var ra *net.UDPAddr
+	if ra, err = net.ResolveUDPAddr("udp", address); err != nil {
+		return
+	}
+
+	var conn *net.UDPConn
+	if conn, err = net.ListenUDP("udp", nil); err != nil {
+		return
+	}
+
+	var conv [4]byte
+	if _, err = io.ReadFull(rand.Reader, conv[:]); err != nil {
+		conn.Close()
+		return
+	}
+
+	s = newSession(binary.LittleEndian.Uint32(conv[:]), conf, conn, ra, nil)
+
+	go s.receive()
+	return
+}
+
+// (client) receive packets
+func (s *Session) receive() {
+
+	var buf = make([]byte, 0xffff)
+
+	for {
+		var n, addr, err = s.conn.ReadFrom(buf)
+		if err != nil {
+			s.closeWith(err)
+			return
+		}
+		if addr.String() != s.remote.String() {
+			continue // ignore
+		}
+		s.input(buf[:n])
+	}
+}
+
+// process received UDP packet
+func (s *Session) input(p []byte) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.err != nil {
+		return
+	}
+
+	s.arq.current = s.now()
+
+	var err error
+
+	if s.dec == nil {
+		err = s.arq.input(p)
+	} else {
+		for _, kp := range s.dec.decode(p) {
+			if err = s.arq.input(kp); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		return // ignore malformed packet
+	}
+
+	if s.arq.closed == true {
+		s.closeLocked(io.EOF, false)
+		return
+	}
+
+	s.arq.flush() // send acks
+
+	s.rc.Broadcast()
+	s.wc.Broadcast()
+}
+
+// send KCP packet, call under lock
+func (s *Session) output(p []byte) {
+
+	if s.enc == nil {
+		s.conn.WriteTo(p, s.remote)
+		return
+	}
+
+	for _, fp := range s.enc.encode(p) {
+		s.conn.WriteTo(fp, s.remote)
+	}
+}
+
+// ms since start
+func (s *Session) now() uint32 {
+	return uint32(time.Since(s.start) / time.Millisecond)
+}
+
+// retransmissions
+func (s *Session) update(interval time.Duration) {
+
+	var tk = time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+		case <-s.closeq:
+			return
+		}
+
+		s.mx.Lock()
+
+		if s.err == nil {
+
+			s.arq.current = s.now()
+			s.arq.flush()
+
+			if s.arq.dead == true {
+				s.closeLocked(ErrDeadLink, false)
+			}
+
+			s.wc.Broadcast()
+		}
+
+		s.mx.Unlock()
+	}
+}
+
+// ReadMessage returns next received message. It
+// returns io.EOF if remote peer closes the Session
+func (s *Session) ReadMessage() (p []byte, err error) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for {
+		if p = s.arq.recv(); p != nil {
+			s.arq.current = s.now()
+			s.arq.flush() // the window is free
+			return
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+		s.rc.Wait()
+	}
+}
+
+// WriteMessage sends given message. It blocks
+// while too many messages waiting for sending
+func (s *Session) WriteMessage(p []byte) (err error) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for s.err == nil && s.arq.waitSnd() >= 2*int(s.arq.sndWnd) {
+		s.wc.Wait()
+	}
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if err = s.arq.send(p); err != nil {
+		return
+	}
+
+	s.arq.current = s.now()
+	s.arq.flush()
+	return
+}
+
+// RemoteAddr returns remote address
+func (s *Session) RemoteAddr() net.Addr {
+	return s.remote
+}
+
+// LocalAddr returns local address
+func (s *Session) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Close the Session
+func (s *Session) Close() (err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.closeLocked(ErrClosed, true)
+	return
+}
+
+func (s *Session) closeWith(err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.closeLocked(err, false)
+}
+
+func (s *Session) closeLocked(err error, notify bool) {
+
+	if s.err != nil {
+		return // already closed
+	}
+
+	s.err = err
+	close(s.closeq)
+
+	if notify == true {
+		s.arq.close() // best effort
+	}
+
+	s.rc.Broadcast()
+	s.wc.Broadcast()
+
+	if s.l == nil {
+		s.conn.Close()
+	} else {
+		go s.l.remove(s)
+	}
+}
+
+// A Listener accepts KCP sessions
+type Listener struct {
+	conf Config
+	conn net.PacketConn
+
+	mx sync.Mutex
+	ss map[string]*Session // remote address -> session
+
+	acceptq chan *Session
+	closeq  chan struct{}
+	closeo  sync.Once
+}
+
+// Listen on given UDP address
+func Listen(address string, conf *Config) (l *Listener, err error) {
+
+	var conn net.PacketConn
+	if conn, err = net.ListenPacket("udp", address); err != nil {
+		return
+	}
+
+	l = new(Listener)
+
+	l.conf = *conf
+	l.conn = conn
+	l.ss = make(map[string]*Session)
+	l.acceptq = make(chan *Session, 128)
+	l.closeq = make(chan struct{})
+
+	go l.receive()
+	return
+}
+
+// Addr returns listening address
+func (l *Listener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+func (l *Listener) receive() {
+
+	defer l.Close()
+
+	var buf = make([]byte, 0xffff)
+
+	for {
+
+		var n, addr, err = l.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		var p = buf[:n]
+
+		l.mx.Lock()
+		var s, ok = l.ss[addr.String()]
+		if ok == false {
+			if s = l.newSession(p, addr); s == nil {
+				l.mx.Unlock()
+				continue
+			}
+		}
+		l.mx.Unlock()
+
+		s.input(p)
+	}
+}
+
+// create session if the packet starts a new one,
+// call under lock
+func (l *Listener) newSession(p []byte, addr net.Addr) (s *Session) {
+
+	if l.conf.FEC > 0 {
+		// data packet of the FEC
+		if len(p) < fecOverhead || p[4] != fecData {
+			return
+		}
+		p = p[fecOverhead:]
+	}
+
+	if len(p) < overhead || p[4] != cmdPush {
+		return // not a session
+	}
+
+	select {
+	case <-l.closeq:
+		return
+	default:
+	}
+
+	s = newSession(binary.LittleEndian.Uint32(p), &l.conf, l.conn, addr, l)
+
+	select {
+	case l.acceptq <- s:
+	default:
+		s.closeLocked(ErrClosed, false) // too many
+		return nil
+	}
+
+	l.ss[addr.String()] = s
+	return
+}
+
+func (l *Listener) remove(s *Session) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if l.ss[s.remote.String()] == s {
+		delete(l.ss, s.remote.String())
+	}
+}
+
+// Dial KCP server using socket of the Listener, thus
+// the server (and NAT) sees the listening port. It's
+// required for hole punching. The address is host:port
+func (l *Listener) Dial(address string) (s *Session, err error) {
+
+	var ra *net.UDPAddr
+	if ra, err = net.ResolveUDPAddr("udp", address); err != nil {
+		return
+	}
+
+	var conv [4]byte
+	if _, err = io.ReadFull(rand.Reader, conv[:]); err != nil {
+		return
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	select {
+	case <-l.closeq:
+		return nil, ErrClosed
+	default:
+	}
+
+	if _, ok := l.ss[ra.String()]; ok == true {
+		return nil, ErrSessionExists
+	}
+
+	s = newSession(binary.LittleEndian.Uint32(conv[:]), &l.conf, l.conn, ra, l)
+	l.ss[ra.String()] = s
+	return
+}
+
+// Punch sends a dummy packet to given address from
+// socket of the Listener. The packet opens NAT for
+// incoming packets from the address (hole punching).
+// KCP peers ignore the packet
+func (l *Listener) Punch(address string) (err error) {
+
+	var ra *net.UDPAddr
+	if ra, err = net.ResolveUDPAddr("udp", address); err != nil {
+		return
+	}
+
+	_, err = l.conn.WriteTo([]byte{0}, ra)
+	return
+}
+
+// Accept next session
+func (l *Listener) Accept() (s *Session, err error) {
+	select {
+	case s = <-l.acceptq:
+		return
+	case <-l.closeq:
+		return nil, ErrClosed
+	}
+}
+
+// Close the Listener and all its sessions
+func (l *Listener) Close() (err error) {
+
+	l.closeo.Do(func() {
+		close(l.closeq)
+
+		l.mx.Lock()
+		var ss = make([]*Session, 0, len(l.ss))
+		for _, s := range l.ss {
+			ss = append(ss, s)
+		}
+		l.mx.Unlock()
+
+		for _, s := range ss {
+			s.Close() // notify remote peers
+		}
+
+		err = l.conn.Close()
+	})
+
+	return
+}
@@ -0,0 +1,272 @@
+package kcp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func testMessage(i, size int) []byte {
+	return bytes.Repeat([]byte{byte(i)}, size)
+}
+
+func TestARQ_lossy(t *testing.T) {
+
+	var (
+		conf = NewConfig()
+
+		atob, btoa [][]byte
+		dropped    int
+
+		a = newARQ(1, &conf, func(p []byte) {
+			atob = append(atob, append([]byte{}, p...))
+		})
+		b = newARQ(1, &conf, func(p []byte) {
+			btoa = append(btoa, append([]byte{}, p...))
+		})
+	)
+
+	// deliver packets dropping every third one
+	var deliver = func(to *arq, ps [][]byte) {
+		for _, p := range ps {
+			if dropped++; dropped%3 == 0 {
+				continue
+			}
+			if err := to.input(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	const n = 50
+
+	for i := 0; i < n; i++ {
+		if err := a.send(testMessage(i, 100+i*97)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var received int
+
+	for tick := 0; tick < 1000 && received < n; tick++ {
+
+		a.current += 10
+		b.current += 10
+
+		a.flush()
+		var ps = atob
+		atob = nil
+		deliver(b, ps)
+
+		b.flush()
+		ps = btoa
+		btoa = nil
+		deliver(a, ps)
+
+		for p := b.recv(); p != nil; p = b.recv() {
+			if want := testMessage(received, 100+received*97); !bytes.Equal(p, want) {
+				t.Fatalf("wrong message %d: %d bytes", received, len(p))
+			}
+			received++
+		}
+	}
+
+	if received != n {
+		t.Fatalf("received %d messages of %d", received, n)
+	}
+
+	if a.dead == true {
+		t.Error("dead link")
+	}
+
+}
+
+func TestFEC(t *testing.T) {
+
+	var (
+		enc = newFECEncoder(3)
+		dec = newFECDecoder(3)
+
+		ps [][]byte
+	)
+
+	for i := 0; i < 3; i++ {
+		ps = append(ps, enc.encode(testMessage(i, 10+i))...)
+	}
+
+	if len(ps) != 4 {
+		t.Fatal("wrong number of packets:", len(ps))
+	}
+
+	// lose the second one
+
+	var got [][]byte
+	for i, p := range ps {
+		if i == 1 {
+			continue
+		}
+		got = append(got, dec.decode(p)...)
+	}
+
+	if len(got) != 3 {
+		t.Fatal("not recovered:", len(got))
+	}
+
+	if !bytes.Equal(got[2], testMessage(1, 11)) {
+		t.Error("wrong recovered packet")
+	}
+
+	// duplicate
+	if len(dec.decode(ps[0])) != 0 {
+		t.Error("duplicate delivered")
+	}
+
+}
+
+func TestSession(t *testing.T) {
+
+	for _, fec := range []int{0, 4} {
+
+		t.Run(fmt.Sprintf("fec %d", fec), func(t *testing.T) {
+
+			var conf = NewConfig()
+			conf.FEC = fec
+
+			var l, err = Listen("127.0.0.1:0", &conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+
+			var c *Session
+			if c, err = Dial(l.Addr().String(), &conf); err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			const n = 20
+
+			go func() {
+				for i := 0; i < n; i++ {
+					c.WriteMessage(testMessage(i, i*1000))
+				}
+			}()
+
+			var s *Session
+			if s, err = l.Accept(); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < n; i++ {
+				var p []byte
+				if p, err = s.ReadMessage(); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(p, testMessage(i, i*1000)) {
+					t.Fatalf("wrong message %d", i)
+				}
+			}
+
+			// reply
+			assertNil(t, s.WriteMessage([]byte("ok")))
+
+			var p []byte
+			if p, err = c.ReadMessage(); err != nil {
+				t.Fatal(err)
+			} else if string(p) != "ok" {
+				t.Error("wrong reply")
+			}
+
+			// close
+			c.Close()
+
+			var done = make(chan error, 1)
+			go func() {
+				var _, err = s.ReadMessage()
+				done <- err
+			}()
+
+			select {
+			case err = <-done:
+				if err != io.EOF {
+					t.Error("wrong error:", err)
+				}
+			case <-time.After(time.Second):
+				t.Error("not closed")
+			}
+
+		})
+
+	}
+
+}
+
+func TestListener_Dial(t *testing.T) {
+
+	var conf = NewConfig()
+
+	var a, err = Listen("127.0.0.1:0", &conf)
+	assertNil(t, err)
+	defer a.Close()
+
+	var b *Listener
+	b, err = Listen("127.0.0.1:0", &conf)
+	assertNil(t, err)
+	defer b.Close()
+
+	// the punch doesn't start a session
+	assertNil(t, b.Punch(a.Addr().String()))
+
+	var c *Session
+	c, err = a.Dial(b.Addr().String())
+	assertNil(t, err)
+
+	if _, err = a.Dial(b.Addr().String()); err != ErrSessionExists {
+		t.Error("wrong error:", err)
+	}
+
+	assertNil(t, c.WriteMessage([]byte("hello")))
+
+	var s *Session
+	if s, err = b.Accept(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.RemoteAddr().String() != a.Addr().String() {
+		t.Error("not dialed from the listening port:", s.RemoteAddr())
+	}
+
+	// the punch is ignored by the session
+	assertNil(t, b.Punch(a.Addr().String()))
+
+	var p []byte
+	if p, err = s.ReadMessage(); err != nil {
+		t.Fatal(err)
+	} else if string(p) != "hello" {
+		t.Error("wrong message")
+	}
+
+	assertNil(t, s.WriteMessage([]byte("ok")))
+
+	if p, err = c.ReadMessage(); err != nil {
+		t.Fatal(err)
+	} else if string(p) != "ok" {
+		t.Error("wrong reply")
+	}
+
+	select {
+	case s = <-b.acceptq:
+		t.Error("unexpected session from", s.RemoteAddr())
+	default:
+	}
+
+}
+
+func assertNil(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,91 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestKCP_Connect(t *testing.T) {
+
+	var (
+		sconf = getTestConfigNotListen("server")
+		cconf = getTestConfigNotListen("client")
+
+		closed = make(chan struct{})
+	)
+
+	sconf.KCPAddress = "127.0.0.1:0"
+	sconf.Public = true
+	sconf.KCP.FEC = 3
+
+	cconf.KCP.FEC = 3
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var address = sn.KCP().l.Addr().String()
+
+	cconf.OnDisconnect = func(*Conn, error) {
+		close(closed)
+	}
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var c *Conn
+	if c, err = cn.Connect("kcp://" + address); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := c.String(); s != "↑ kcp://"+address {
+		t.Error("wrong string:", s)
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	if feeds := c.Feeds(); len(feeds) != 1 || feeds[0] != pk {
+		t.Error("not subscribed")
+	}
+
+	if _, err = c.RemoteFeeds(); err != nil {
+		t.Error(err)
+	}
+
+	sn.Close() // close server
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Error("connection is not closed")
+	}
+
+}
+
+func TestNode_Connect(t *testing.T) {
+
+	var conf = getTestConfigNotListen("client")
+
+	var n, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	if _, err = n.Connect("sctp://127.0.0.1:8870"); err == nil {
+		t.Error("missing error")
+	}
+
+}
@@ -0,0 +1,168 @@
+package node
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/statutil"
+)
+
+// ping timeout limits
+const (
+	pingRTTFactor  = 4               // timeout is RTT * factor
+	minPingTimeout = 1 * time.Second // but not less
+)
+
+// Ping remote peer. It returns round-trip time. The
+// Ping uses ResponseTimeout of the transport. It
+// returns ErrNotSupported if the peer doesn't
+// support pings (see msg.FeaturePing)
+func (c *Conn) Ping() (rtt time.Duration, err error) {
+	return c.ping(c.responseTimeout())
+}
+
+// RTT returns average round-trip time measured by
+// pings, or zero if the Conn has not been pinged yet
+func (c *Conn) RTT() (rtt time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.rtt == nil {
+		return
+	}
+
+	return c.rtt.Value()
+}
+
+func (c *Conn) ping(timeout time.Duration) (rtt time.Duration, err error) {
+
+	if c.HasFeature(msg.FeaturePing) == false {
+		return 0, ErrNotSupported
+	}
+
+	var (
+		tp    = time.Now()
+		reply msg.Msg
+	)
+
+	if reply, err = c.sendRequestCancel(&msg.Ping{}, timeout, nil); err != nil {
+		return
+	}
+
+	if _, ok := reply.(*msg.Pong); ok == false {
+		return 0, ErrInvalidResponse
+	}
+
+	rtt = time.Now().Sub(tp)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.rtt == nil {
+		c.rtt = statutil.NewDuration(c.n.config.Config.RollAvgSamples)
+	}
+
+	c.rtt.Add(rtt)
+	return
+}
+
+// timeout of automatic pings adapted to RTT
+func (c *Conn) pingTimeout() (timeout time.Duration) {
+
+	timeout = c.responseTimeout()
+
+	var rtt = c.RTT()
+
+	if rtt == 0 {
+		return // not measured yet
+	}
+
+	var adapted = rtt * pingRTTFactor
+
+	if adapted < minPingTimeout {
+		adapted = minPingTimeout
+	}
+
+	if timeout == 0 || adapted < timeout {
+		timeout = adapted
+	}
+
+	return
+}
+
+// pings interval of the Conn, or zero
+func (c *Conn) pings() (pings time.Duration) {
+	if c.IsTCP() == true {
+		return c.n.config.TCP.Pings
+	}
+	return c.n.config.UDP.Pings
+}
+
+// time since last received message
+func (c *Conn) idle() time.Duration {
+	return time.Now().Sub(time.Unix(0, atomic.LoadInt64(&c.lastRead)))
+}
+
+// mark the Conn used for reading
+func (c *Conn) touch() {
+	atomic.StoreInt64(&c.lastRead, time.Now().UnixNano())
+}
+
+// (async) ping the Conn if it's not used for reading Pings,
+// and close the Conn if remote peer doesn't respond
+func (c *Conn) pinging() {
+
+	var err = c.keepAlive()
+
+	c.await.Done() // the close waits the pinging
+
+	if err == nil {
+		return
+	}
+
+	c.n.Printf("[%s] dead peer: %v", c.String(), err)
+
+	c.close(err) // reconnected if it's outgoing (see reconnect.go)
+}
+
+// keepAlive returns ErrTimeout if remote peer is dead,
+// or nil if the Conn has been closed
+func (c *Conn) keepAlive() (err error) {
+
+	var (
+		pings = c.pings()
+		tm    = time.NewTimer(pings)
+	)
+
+	defer tm.Stop()
+
+	for {
+
+		select {
+		case <-tm.C:
+		case <-c.closeq:
+			return
+		}
+
+		// read deadline
+
+		if idle := c.idle(); idle < pings {
+			tm.Reset(pings - idle)
+			continue
+		}
+
+		switch _, err = c.ping(c.pingTimeout()); err {
+		case nil:
+		case ErrTimeout:
+			return // dead
+		case ErrClosed:
+			return nil
+		default:
+			c.n.Printf("[ERR] [%s] ping: %v", c.String(), err)
+		}
+
+		tm.Reset(pings)
+	}
+
+}
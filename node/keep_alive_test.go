@@ -0,0 +1,84 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConn_Ping(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.RTT() != 0 {
+		t.Error("unexpected RTT")
+	}
+
+	if c.pingTimeout() != c.responseTimeout() {
+		t.Error("wrong ping timeout")
+	}
+
+	var rtt time.Duration
+	if rtt, err = c.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rtt <= 0 || c.RTT() <= 0 {
+		t.Errorf("wrong RTT: %v, %v", rtt, c.RTT())
+	}
+
+	// RTT is less then a millisecond, and response timeout is one second
+	if c.pingTimeout() != minPingTimeout {
+		t.Errorf("wrong ping timeout: %v", c.pingTimeout())
+	}
+
+}
+
+func TestConn_pinging(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+	)
+
+	sconf.TCP.Pings = 50 * time.Millisecond
+	cconf.TCP.Pings = 50 * time.Millisecond
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(300 * time.Millisecond) // idle
+
+	if c.RTT() == 0 {
+		t.Error("not pinged")
+	}
+
+	if c.idle() > 200*time.Millisecond {
+		t.Error("pongs are not received")
+	}
+
+	if _, ok := cn.hasPeer(sn.ID()); ok == false {
+		t.Error("alive connection closed")
+	}
+
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"math"
+	"time"
+
+	"github.com/skycoin/cxo/skyobject/statutil"
+)
+
+// minimal success rate used to estimate cost
+// of a request, to avoid division by zero
+const minHitRate = 0.01
+
+// ServiceTime returns rolling average time the peer
+// takes to reply an object request (from sending the
+// request to receiving the object, timeouts included);
+// it's zero if nothing has been requested yet
+func (c *Conn) ServiceTime() (st time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.svc == nil {
+		return
+	}
+
+	return c.svc.Value()
+}
+
+// add service time of an object request
+func (c *Conn) addServiceTime(st time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.svc == nil {
+		c.svc = statutil.NewDuration(c.n.config.Config.RollAvgSamples)
+	}
+
+	c.svc.Add(st)
+}
+
+// expected latency of an object request; it's the
+// service time, or the RTT if nothing has been
+// requested yet, or zero if both are unknown; thus
+// new peers are tried first
+func (c *Conn) latency() (lat time.Duration) {
+	if lat = c.ServiceTime(); lat == 0 {
+		lat = c.RTT()
+	}
+	return
+}
+
+// expected cost of new object request to the peer with
+// given number of requests in flight; requests to a
+// peer wait for each other, and missed requests are
+// requested again from another peer
+func (c *Conn) requestCost(inFlight int) float64 {
+	return float64(c.latency()) * float64(inFlight+1) /
+		math.Max(1-c.MissRate(), minHitRate)
+}
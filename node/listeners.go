@@ -0,0 +1,258 @@
+package node
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/skycoin/net/client"
+	"github.com/skycoin/net/conn"
+	"github.com/skycoin/net/factory"
+	"github.com/skycoin/net/server"
+)
+
+// an additional listener (see Config.Listen)
+type listener struct {
+	network string       // tcp, ws or unix
+	address string       // as passed to the Listen
+	l       net.Listener // underlying listener
+	s       *http.Server // HTTP server of WebSocket or nil
+}
+
+func (l *listener) close() {
+	if l.s != nil {
+		l.s.Close()
+		return
+	}
+	l.l.Close()
+}
+
+// split an address of the Config.Listen
+func listenAddress(
+	address string, //                : the address
+	custom map[string]Transport, //  : see Config.Transports
+) (
+	network string, //                : scheme
+	addr string, //                   : address without scheme
+	err error, //                     : an error
+) {
+
+	var i = strings.Index(address, "://")
+
+	if i < 0 {
+		err = fmt.Errorf("missing scheme of listening address %q", address)
+		return
+	}
+
+	network, addr = address[:i], address[i+3:]
+
+	switch network {
+	case "tcp", "ws", "unix":
+	default:
+		if _, ok := custom[network]; ok == false {
+			err = fmt.Errorf("unknown scheme of listening address %q", address)
+			return
+		}
+	}
+
+	if addr == "" {
+		err = fmt.Errorf("blank listening address %q", address)
+	}
+
+	return
+}
+
+// listen on an address of the Config.Listen
+func (n *Node) listen(address string) (err error) {
+
+	var network, addr string
+	if network, addr, err = listenAddress(address, n.config.Transports); err != nil {
+		return
+	}
+
+	if ct, ok := n.ts[network]; ok == true {
+		return ct.listen(addr)
+	}
+
+	if err = n.listenOn(network, addr); err != nil {
+		return
+	}
+
+	if network != "unix" {
+		n.listenDualStack(network, addr)
+	}
+
+	return
+}
+
+// listen on rest addresses of a host name (see dualStack),
+// errors are logged, since IPv6 can be unavailable
+func (n *Node) listenDualStack(network, address string) {
+	for _, other := range dualStack(address) {
+		if err := n.listenOn(network, other); err != nil {
+			n.Printf("[ERR] [listen] %s://%s: %v", network, other, err)
+		}
+	}
+}
+
+func (n *Node) listenOn(network, addr string) (err error) {
+
+	var l = &listener{network: network, address: addr}
+
+	if network == "unix" {
+		l.l, err = net.Listen("unix", addr)
+	} else {
+		l.l, err = net.Listen("tcp", addr)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if network == "ws" {
+		var w = n.WS()
+		l.s = &http.Server{Handler: http.HandlerFunc(w.accept)}
+		n.await.Add(1)
+		go w.run(l.s, l.l)
+	} else {
+		go n.TCP().acceptNet(l.l, network) // like the TCPFactory, not tracked by the await
+	}
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.ls = append(n.ls, l)
+	return
+}
+
+// ListeningAddresses returns all listening addresses
+// of the Node with schemes: the TCP, the UDP, the WS,
+// the KCP, the Config.Listen and listeners of custom
+// transports. The addresses are as they passed to
+// listeners
+func (n *Node) ListeningAddresses() (addresses []string) {
+
+	n.mx.Lock()
+	var (
+		tcp, udp, ws, kcp = n.tcp, n.udp, n.ws, n.kcp
+		ls                = n.ls
+	)
+	n.mx.Unlock()
+
+	if tcp != nil && tcp.Address() != "" {
+		addresses = append(addresses, "tcp://"+tcp.Address())
+	}
+
+	if udp != nil && udp.Address() != "" {
+		addresses = append(addresses, "udp://"+udp.Address())
+	}
+
+	if ws != nil && ws.Address() != "" {
+		addresses = append(addresses, "ws://"+ws.Address())
+	}
+
+	if kcp != nil && kcp.Address() != "" {
+		addresses = append(addresses, "kcp://"+kcp.Address())
+	}
+
+	for _, l := range ls {
+		addresses = append(addresses, l.network+"://"+l.address)
+	}
+
+	for _, scheme := range n.transportSchemes() {
+		addresses = append(addresses, n.ts[scheme].addresses()...)
+	}
+
+	return
+}
+
+// accept connections of an additional TCP
+// or Unix socket listener
+func (t *TCP) acceptNet(l net.Listener, network string) {
+	for {
+		var nc, err = l.Accept()
+		if err != nil {
+			return
+		}
+		if tc := t.n.config.TLS; tc != nil && network == "tcp" {
+			go t.handshakeTLS(tls.Server(nc, tc))
+			continue
+		}
+		go t.acceptNetConn(nc, network)
+	}
+}
+
+// wrap and accept given connection, the typ
+// is type of the connection for logs
+func (t *TCP) acceptNetConn(nc net.Conn, typ string) {
+
+	var sc = &server.ServerTCPConn{
+		TCPConn: conn.TCPConn{
+			TcpConn:          nc,
+			ConnCommonFields: conn.NewConnCommonFileds(),
+			PendingMap:       conn.NewPendingMap(),
+		},
+	}
+	sc.SetStatusToConnected()
+
+	var fc = &factory.Connection{Connection: sc}
+	fc.SetContextLogger(fc.GetContextLogger().WithField("type", typ))
+
+	t.AddAcceptedConn(fc)
+	t.n.acceptConnection(fc)
+}
+
+// connect to Unix socket, the Unix sockets
+// use the TCP configurations
+func (t *TCP) connectUnix(path string) (c *Conn, err error) {
+
+	path = filepath.Clean(path)
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var ok bool
+	if c, ok = t.cs[path]; ok == true && c.isClosed() == false {
+		return // already have
+	}
+
+	var nc net.Conn
+	nc, err = net.DialTimeout("unix", path, t.n.config.TCP.ResponseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var cc = client.NewClientTCPConn(nc)
+	cc.SetStatusToConnected()
+
+	var fc = &factory.Connection{Connection: cc}
+	fc.SetContextLogger(fc.GetContextLogger().WithField("type", "unix"))
+
+	t.AddConn(fc)
+
+	if c, err = t.n.wrapConnection(fc, false); err != nil {
+		return
+	}
+
+	t.cs[path] = c // put to the map
+	return
+}
+
+// is given connection a Unix socket
+func isUnix(fc *factory.Connection) (ok bool) {
+
+	var nc net.Conn
+
+	switch x := fc.Connection.(type) {
+	case *server.ServerTCPConn:
+		nc = x.TcpConn
+	case *client.ClientTCPConn:
+		nc = x.TcpConn
+	}
+
+	_, ok = nc.(*net.UnixConn)
+	return
+}
@@ -0,0 +1,65 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNode_ListeningAddresses(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "listen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		sock = filepath.Join(dir, "cxo.sock")
+		conf = getTestConfig("server")
+	)
+
+	conf.UDP.Listen = ""
+	conf.Listen = Addresses{
+		"tcp://127.0.0.1:8089",
+		"ws://127.0.0.1:8090",
+		"unix://" + sock,
+	}
+
+	var s *Node
+	if s, err = NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var as = s.ListeningAddresses()
+	assertTrue(t, len(as) == 4, "wrong number of addresses")
+	assertTrue(t, as[0] == "tcp://127.0.0.1:8087", "wrong TCP address")
+
+	for i, address := range conf.Listen {
+		assertTrue(t, as[i+1] == address, "wrong address: "+as[i+1])
+	}
+
+	for _, address := range conf.Listen {
+
+		var cn = getTestNodeNotListen("client")
+
+		var c *Conn
+		if c, err = cn.Connect(address); err != nil {
+			t.Fatal(address, err)
+		}
+
+		assertTrue(t, dialAddress(c) == address,
+			"wrong address of connection: "+dialAddress(c))
+		assertTrue(t, waitPeers(s, 1) == 1, "not accepted: "+address)
+
+		assertNil(t, cn.Close())
+		assertTrue(t, waitPeers(s, 0) == 0, "not closed: "+address)
+
+	}
+
+	conf.Listen = Addresses{"http://127.0.0.1:8091"}
+	assertTrue(t, conf.Validate() != nil, "missing error")
+
+}
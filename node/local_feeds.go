@@ -0,0 +1,106 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// local-only feeds of the Node
+type localFeeds struct {
+	mx sync.Mutex
+	fs map[cipher.PubKey]struct{}
+}
+
+func newLocalFeeds() (l *localFeeds) {
+	l = new(localFeeds)
+	l.fs = make(map[cipher.PubKey]struct{})
+	return
+}
+
+func (l *localFeeds) add(feed cipher.PubKey) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	l.fs[feed] = struct{}{}
+}
+
+func (l *localFeeds) del(feed cipher.PubKey) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	delete(l.fs, feed)
+}
+
+func (l *localFeeds) has(feed cipher.PubKey) (ok bool) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	_, ok = l.fs[feed]
+	return
+}
+
+func (l *localFeeds) list() (feeds []cipher.PubKey) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if len(l.fs) == 0 {
+		return
+	}
+
+	feeds = make([]cipher.PubKey, 0, len(l.fs))
+	for pk := range l.fs {
+		feeds = append(feeds, pk)
+	}
+
+	sortFeeds(feeds)
+	return
+}
+
+// KeepLocal marks given feed as local-only. The Node
+// never announces, shares or serves a local-only feed,
+// regardless of subscription requests of remote peers
+// and of the OnSubscribeRemote callback. Thus, the
+// underlying Container can be used to keep private
+// state alongside shared feeds. The KeepLocal adds the
+// feed to the Container and, if the Node shares the
+// feed, stops sharing (see DontShare). The Share method
+// returns ErrLocalFeed for a local-only feed. Objects
+// are still requested by hash and the Node can send an
+// object of a local-only feed if a remote peer knows
+// its hash
+func (n *Node) KeepLocal(feed cipher.PubKey) (err error) {
+
+	if feed == (cipher.PubKey{}) {
+		return ErrBlankFeed
+	}
+
+	if err = n.container(feed).AddFeed(feed); err != nil {
+		return
+	}
+
+	n.lf.add(feed)
+
+	if n.fs.hasFeed(feed) == true {
+		err = n.DontShare(feed)
+	}
+
+	return
+}
+
+// DontKeepLocal removes the local-only mark of given
+// feed. The method doesn't share the feed, use Share
+// to do that
+func (n *Node) DontKeepLocal(feed cipher.PubKey) {
+	n.lf.del(feed)
+}
+
+// IsLocal returns true if given feed is local-only
+func (n *Node) IsLocal(feed cipher.PubKey) (ok bool) {
+	return n.lf.has(feed)
+}
+
+// LocalFeeds returns sorted list of local-only feeds
+func (n *Node) LocalFeeds() (feeds []cipher.PubKey) {
+	return n.lf.list()
+}
@@ -0,0 +1,67 @@
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_KeepLocal(t *testing.T) {
+
+	var sconf = getTestConfig("server")
+
+	// share everything requested
+	sconf.OnSubscribeRemote = func(c *Conn, feed cipher.PubKey) error {
+		return c.Node().Share(feed)
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(feed))
+	assertNil(t, sn.KeepLocal(feed))
+
+	assertTrue(t, sn.IsLocal(feed) == true, "not local")
+	assertTrue(t, sn.IsSharing(feed) == false, "still shared")
+	assertTrue(t, len(sn.LocalFeeds()) == 1, "wrong list of local feeds")
+
+	if err = sn.Share(feed); err != ErrLocalFeed {
+		t.Error("wrong error:", err)
+	}
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Subscribe(feed); errors.Is(err, ErrFeedNotServed) == false {
+		t.Error("wrong error:", err)
+	}
+
+	err = c.Preview(feed, func(registry.Pack, *registry.Root) bool {
+		return false
+	})
+	if errors.Is(err, ErrFeedNotServed) == false {
+		t.Error("wrong error:", err)
+	}
+
+	assertTrue(t, len(sn.ConnectionsOfFeed(feed)) == 0, "served")
+
+	// unmark
+
+	sn.DontKeepLocal(feed)
+	assertTrue(t, sn.IsLocal(feed) == false, "still local")
+	assertNil(t, c.Subscribe(feed))
+
+}
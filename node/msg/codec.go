@@ -0,0 +1,36 @@
+package msg
+
+// A Codec encodes and decodes messages on the wire.
+// Encoded message starts with its Type, thus a Codec
+// encodes body of a message only. Values carried by
+// messages (objects, Root objects, registries) are
+// opaque for a Codec and stay encoded by the skycoin
+// encoder. A Codec can be used to talk to peers that
+// can't implement the skycoin encoder. All peers must
+// use the same Codec, since it's not negotiated
+type Codec interface {
+	// Encode given message
+	Encode(m Msg) (p []byte)
+	// Decode given message, the Decode must check
+	// the message using given Limits before decoding
+	// and must return InvalidTypeError for a message
+	// of unknown type
+	Decode(p []byte, l *Limits) (m Msg, err error)
+}
+
+// DefaultCodec is the skycoin encoder
+var DefaultCodec Codec = skyCodec{}
+
+// the skycoin encoder
+type skyCodec struct{}
+
+func (skyCodec) Encode(m Msg) (p []byte) {
+	return m.Encode()
+}
+
+func (skyCodec) Decode(p []byte, l *Limits) (m Msg, err error) {
+	if l == nil {
+		return Decode(p)
+	}
+	return l.Decode(p)
+}
@@ -0,0 +1,132 @@
+package msg
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// frames (see FeatureFrames)
+//
+// [ 4 length ][ 1 type ][ 1 flags ][ 4 crc32 ][ body ]
+//
+// the length is length of the body, and the crc32
+// is IEEE checksum of the body
+//
+
+// FrameHeaderSize is size of header of a frame
+const FrameHeaderSize = 10
+
+// KeepAliveType is type of frame without body that
+// only keeps connection alive and never decoded as
+// a message
+const KeepAliveType Type = 0
+
+// flags of a frame
+const (
+	FrameCompressed uint8 = 1 << iota // the body is compressed
+)
+
+// frame errors
+var (
+	ErrFrameChecksum = errors.New("frame checksum mismatch")
+	ErrFrameLength   = errors.New("frame length mismatch")
+)
+
+// A Frame represents framed message
+type Frame struct {
+	Type  Type   // type of the message
+	Flags uint8  // flags
+	Body  []byte // body
+}
+
+// IsKeepAlive returns true if the Frame
+// is keep-alive frame
+func (f *Frame) IsKeepAlive() bool {
+	return f.Type == KeepAliveType
+}
+
+// Encode the Frame
+func (f *Frame) Encode() (p []byte) {
+
+	p = make([]byte, FrameHeaderSize, FrameHeaderSize+len(f.Body))
+
+	binary.LittleEndian.PutUint32(p, uint32(len(f.Body)))
+	p[4] = byte(f.Type)
+	p[5] = f.Flags
+	binary.LittleEndian.PutUint32(p[6:], crc32.ChecksumIEEE(f.Body))
+
+	return append(p, f.Body...)
+}
+
+// DecodeFrame decodes entire frame. It returns
+// ErrFrameLength if given frame is truncated or has
+// trailing bytes, and ErrFrameChecksum if the body
+// is corrupted. The Body of the Frame refers to
+// given slice
+func DecodeFrame(p []byte) (f Frame, err error) {
+
+	if len(p) < FrameHeaderSize {
+		return f, ErrFrameLength
+	}
+
+	var ln = binary.LittleEndian.Uint32(p)
+
+	if uint64(ln) != uint64(len(p)-FrameHeaderSize) {
+		return f, ErrFrameLength
+	}
+
+	f.Type, f.Flags = Type(p[4]), p[5]
+	f.Body = p[FrameHeaderSize:]
+
+	if crc32.ChecksumIEEE(f.Body) != binary.LittleEndian.Uint32(p[6:]) {
+		return Frame{}, ErrFrameChecksum
+	}
+
+	return
+}
+
+// A FrameReader reads frames from a stream
+type FrameReader struct {
+	r       io.Reader
+	maxSize int
+	hdr     [FrameHeaderSize]byte
+}
+
+// NewFrameReader creates FrameReader that reads
+// frames from given io.Reader. The maxSize is max
+// length of body of a frame, zero means no limit
+func NewFrameReader(r io.Reader, maxSize int) (f *FrameReader) {
+	return &FrameReader{r: r, maxSize: maxSize}
+}
+
+// ReadFrame reads next frame. It returns io.EOF if
+// the stream closed between frames, and
+// io.ErrUnexpectedEOF if a frame is truncated.
+// A body bigger than max size is not read and the
+// ReadFrame returns ErrSizeLimit
+func (f *FrameReader) ReadFrame() (fr Frame, err error) {
+
+	if _, err = io.ReadFull(f.r, f.hdr[:]); err != nil {
+		return
+	}
+
+	var ln = binary.LittleEndian.Uint32(f.hdr[:])
+
+	if f.maxSize > 0 && uint64(ln) > uint64(f.maxSize) {
+		return fr, ErrSizeLimit
+	}
+
+	var p = make([]byte, FrameHeaderSize+int(ln))
+	copy(p, f.hdr[:])
+
+	if _, err = io.ReadFull(f.r, p[FrameHeaderSize:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+
+	return DecodeFrame(p)
+}
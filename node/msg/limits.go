@@ -0,0 +1,188 @@
+package msg
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+)
+
+// limits errors
+var (
+	ErrSizeLimit   = errors.New("message size limit reached (see Limits)")
+	ErrLengthLimit = errors.New("message length limit reached (see Limits)")
+	ErrMalformed   = errors.New("malformed message")
+)
+
+// A Limits represents hard caps of received messages.
+// An encoded message is checked before decoding, thus
+// a crafted length prefix can't trigger huge allocation
+type Limits struct {
+	// MaxSize is max size of encoded message.
+	// Zero means no limit
+	MaxSize int
+
+	// MaxLength is max number of elements of a slice
+	// or max length of a string of a message. Slices
+	// of bytes are limited by the MaxSize only. Zero
+	// means no limit
+	MaxLength int
+}
+
+// Decode is like the Decode function, but it checks
+// given encoded message using the Limits first. It
+// returns ErrSizeLimit, ErrLengthLimit or ErrMalformed
+// if the message doesn't fit the Limits
+func (l *Limits) Decode(p []byte) (msg Msg, err error) {
+
+	if l.MaxSize > 0 && len(p) > l.MaxSize {
+		return nil, ErrSizeLimit
+	}
+
+	if len(p) < 1 {
+		return nil, ErrEmptyMessage
+	}
+
+	var typ = msgType(Type(p[0]))
+
+	if typ == nil {
+		return nil, InvalidTypeError{Type(p[0])}
+	}
+
+	if _, err = l.check(typ, p[1:]); err != nil {
+		return
+	}
+
+	return Decode(p)
+}
+
+// registered type of a message or nil
+func msgType(mt Type) (typ reflect.Type) {
+	switch {
+	case mt.IsUser() == true:
+		typ = userType(mt) // or nil
+	case mt > 0 && int(mt) < len(forwardRegistry):
+		typ = forwardRegistry[mt]
+	}
+	return
+}
+
+// encoded length of a slice or a string
+func (l *Limits) length(p []byte) (ln int, err error) {
+
+	if len(p) < 4 {
+		return 0, ErrMalformed
+	}
+
+	if ln = int(binary.LittleEndian.Uint32(p)); ln > len(p)-4 {
+		return 0, ErrMalformed
+	}
+
+	return
+}
+
+// check encoded value of given type returning its size
+func (l *Limits) check(typ reflect.Type, p []byte) (n int, err error) {
+
+	switch typ.Kind() {
+
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		n = 1
+	case reflect.Int16, reflect.Uint16:
+		n = 2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		n = 4
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		n = 8
+
+	case reflect.String:
+
+		var ln int
+		if ln, err = l.length(p); err != nil {
+			return
+		}
+
+		if l.MaxLength > 0 && ln > l.MaxLength {
+			return 0, ErrLengthLimit
+		}
+
+		n = 4 + ln
+
+	case reflect.Slice:
+
+		var ln int
+		if ln, err = l.length(p); err != nil {
+			return
+		}
+
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return 4 + ln, nil // limited by the MaxSize
+		}
+
+		if l.MaxLength > 0 && ln > l.MaxLength {
+			return 0, ErrLengthLimit
+		}
+
+		n, err = l.checkElems(typ.Elem(), ln, p[4:])
+		n += 4
+
+	case reflect.Array:
+
+		n, err = l.checkElems(typ.Elem(), typ.Len(), p)
+
+	case reflect.Struct:
+
+		var m int
+		for i := 0; i < typ.NumField(); i++ {
+			var sf = typ.Field(i)
+			if sf.Tag.Get("enc") == "-" || sf.PkgPath != "" || sf.Name == "_" {
+				continue // skipped by encoder
+			}
+			if n > len(p) {
+				return 0, ErrMalformed
+			}
+			if m, err = l.check(sf.Type, p[n:]); err != nil {
+				return
+			}
+			n += m
+		}
+
+	default:
+
+		return 0, ErrMalformed
+
+	}
+
+	if err == nil && n > len(p) {
+		err = ErrMalformed
+	}
+
+	return
+}
+
+// check encoded elements of an array or a slice
+func (l *Limits) checkElems(
+	el reflect.Type, // : type of element
+	ln int, //          : number of elements
+	p []byte, //        : encoded elements
+) (
+	n int, //           : size of the elements
+	err error, //       : error
+) {
+
+	if el.Kind() == reflect.Uint8 {
+		return ln, nil
+	}
+
+	var m int
+	for i := 0; i < ln; i++ {
+		if n > len(p) {
+			return 0, ErrMalformed
+		}
+		if m, err = l.check(el, p[n:]); err != nil {
+			return
+		}
+		n += m
+	}
+
+	return
+}
@@ -4,6 +4,7 @@ package msg
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -15,9 +16,52 @@ import (
 // [1 byte] - type
 // [ .... ] - encoded message
 //
+// with the FeatureFrames, every message after
+// handshake is wrapped by a Frame (see frame.go)
+//
 
 // Version is current protocol version
-const Version uint16 = 3
+const Version uint32 = 7
+
+// MinVersion is the oldest protocol version
+// compatible with the current one. Peers negotiate
+// the lowest of their versions during handshake
+const MinVersion uint32 = 7
+
+// optional features of the protocol, a
+// feature is used only if both peers have it
+const (
+	FeatureTrace       uint64 = 1 << iota // trace IDs of requests
+	FeatureSubLease                       // SubLease
+	FeatureOwnership                      // RqChallenge, Challenge, Proof
+	FeatureSharedFeeds                    // WantFeeds
+	FeatureHaveList                       // RqHave, Have
+	FeatureRootDelta                      // RootDelta
+	FeatureEncryption                     // Sealed, RqSealedObject
+	FeatureRootDone                       // RootDone
+	FeaturePing                           // Ping, Pong
+	FeatureCancel                         // Cancel
+	FeatureNotFound                       // NotFound
+	FeatureDataChunk                      // DataChunk
+	FeatureUserMsg                        // application-defined messages
+	FeatureCredit                         // Credit
+	FeatureGossip                         // Announce
+	FeatureRootReject                     // RootReject
+	FeatureRegistries                     // RqRegistries, Registries
+	FeatureFrames                         // Frame (see frame.go)
+	FeatureNoise                          // Noise
+	FeatureRendezvous                     // RqRendezvous, Rendezvous, RqPunch, Punch
+	FeatureFilter                         // SubFilter
+)
+
+// Features is set of optional features of the protocol
+// this implementation has
+const Features = FeatureTrace | FeatureSubLease | FeatureOwnership |
+	FeatureSharedFeeds | FeatureHaveList | FeatureRootDelta |
+	FeatureEncryption | FeatureRootDone | FeaturePing | FeatureCancel |
+	FeatureNotFound | FeatureDataChunk | FeatureUserMsg | FeatureCredit |
+	FeatureGossip | FeatureRootReject | FeatureRegistries | FeatureFrames |
+	FeatureNoise | FeatureRendezvous | FeatureFilter
 
 // be sure that all messages implements Msg interface compiler time
 var (
@@ -29,18 +73,24 @@ var (
 
 	// handshake
 
-	_ Msg = &Syn{} // <- Syn (node id, protocol version)
-	_ Msg = &Ack{} // -> Ack (peer id)
+	_ Msg = &Syn{} // <- Syn (protocol version, features, node id)
+	_ Msg = &Ack{} // -> Ack (protocol version, features, peer id)
+
+	_ Msg = &Noise{} // <-> Noise (handshake message)
 
 	// common replies
 
 	_ Msg = &Ok{}  // -> Ok ()
 	_ Msg = &Err{} // -> Err (error message)
 
+	_ Msg = &Cancel{} // <- Cancel (seq of request)
+
 	// subscriptions
 
-	_ Msg = &Sub{}   // <- Sub (feed)
-	_ Msg = &Unsub{} // <- Unsub (feed)
+	_ Msg = &Sub{}       // <- Sub (feed)
+	_ Msg = &Unsub{}     // <- Unsub (feed)
+	_ Msg = &SubLease{}  // <- SubLease (feed, lease)
+	_ Msg = &SubFilter{} // <- SubFilter (feed, lease, schemas)
 
 	// public server features
 
@@ -49,16 +99,60 @@ var (
 
 	// root (push and done)
 
-	_ Msg = &Root{} // <- Root (feed, nonce, seq, sig, val)
+	_ Msg = &Root{}      // <- Root (feed, nonce, seq, sig, val)
+	_ Msg = &RootDelta{} // <- RootDelta (feed, nonce, seq, sig, val, objects)
+	_ Msg = &RootDone{}  // -> RootDone (feed, nonce, seq)
+	_ Msg = &Credit{}    // -> Credit (feed, msgs, bytes)
+	_ Msg = &Announce{}  // <- Announce (feed, nonce, seq, hash)
+
+	_ Msg = &RootReject{} // -> RootReject (feed, nonce, seq, reason, err)
 
 	// objects
 
-	_ Msg = &RqObject{} // <- RqO (key, prefetch)
-	_ Msg = &Object{}   // -> O   (val, vals)
+	_ Msg = &RqObject{}  // <- RqO (key, prefetch)
+	_ Msg = &Object{}    // -> O   (val, vals)
+	_ Msg = &NotFound{}  // -> NotFound (key)
+	_ Msg = &DataChunk{} // -> DataChunk (key, offset, total, payload)
 
 	// preview
 
 	_ Msg = &RqPreview{} // -> RqPreview (feed)
+
+	// schemas
+
+	_ Msg = &RqSchemas{} // <- RqSchemas (registry, schemas)
+	_ Msg = &Schemas{}   // -> Schemas (encoded schemas)
+
+	// registries
+
+	_ Msg = &RqRegistries{} // <- RqRegistries (registries)
+	_ Msg = &Registries{}   // -> Registries (encoded registries)
+
+	// hole punching
+
+	_ Msg = &RqRendezvous{} // <- RqRendezvous (peer, port)
+	_ Msg = &Rendezvous{}   // -> Rendezvous (peer, address)
+	_ Msg = &RqPunch{}      // <- RqPunch (peer, address)
+	_ Msg = &Punch{}        // -> Punch (port)
+
+	// ownership proof
+
+	_ Msg = &RqChallenge{} // <- RqChallenge (feed)
+	_ Msg = &Challenge{}   // -> Challenge (nonce)
+	_ Msg = &Proof{}       // <- Proof (feed, sig)
+
+	// shared feeds
+
+	_ Msg = &WantFeeds{} // <- WantFeeds (feeds)
+
+	// have-lists
+
+	_ Msg = &RqHave{} // <- RqHave (feed)
+	_ Msg = &Have{}   // -> Have (feed, hashes, bits)
+
+	// encryption
+
+	_ Msg = &Sealed{} // <-> Sealed (nonce, box)
 )
 
 //
@@ -105,10 +199,14 @@ func (*Pong) Encode() []byte {
 // handshake
 //
 
-// A Syn is handshake initiator message
+// A Syn is handshake initiator message. The Version
+// must be first field for all versions, thus it can be
+// read from a Syn that can't be decoded (see SynVersion)
 type Syn struct {
-	Protocol uint16
-	NodeID   cipher.PubKey // node id
+	Version     uint32        // protocol version
+	Features    uint64        // supported features
+	NodeID      cipher.PubKey // node id
+	Compression []string      // supported compressions, preferred first
 }
 
 // Type implements Msg interface
@@ -117,11 +215,45 @@ func (*Syn) Type() Type { return SynType }
 // Encode the Syn
 func (s *Syn) Encode() []byte { return encode(s) }
 
+// SynVersion returns protocol version of encoded Syn
+// without decoding the Syn. It used to reject a peer
+// gracefully if its Syn can't be decoded. The method
+// understands old Syn with uint16 version (versions
+// before 4) followed by NodeID
+func SynVersion(p []byte) (version uint32, err error) {
+
+	if len(p) < 5 || Type(p[0]) != SynType {
+		err = errors.New("not a Syn")
+		return
+	}
+
+	if err = encoder.DeserializeRaw(p[1:5], &version); err != nil {
+		return
+	}
+
+	// the old Syn is [type][2 version][33 NodeID], first
+	// byte of a compressed public key is never zero, thus
+	// the uint32 is out of range of uint16
+
+	if version > math.MaxUint16 {
+		var old uint16
+		if err = encoder.DeserializeRaw(p[1:3], &old); err != nil {
+			return
+		}
+		version = uint32(old)
+	}
+
+	return
+}
+
 // An Ack is response for the Syn
 // if handshake has been accepted.
 // Otherwise, the Err returned
 type Ack struct {
-	NodeID cipher.PubKey // node id
+	Version     uint32        // negotiated protocol version
+	Features    uint64        // features both peers have
+	NodeID      cipher.PubKey // node id
+	Compression string        // chosen compression or blank for none
 }
 
 // Type implements Msg interface
@@ -130,6 +262,20 @@ func (*Ack) Type() Type { return AckType }
 // Encode the Ack
 func (a *Ack) Encode() []byte { return encode(a) }
 
+// A Noise is message of Noise_XX handshake performed
+// after the Syn and Ack (see FeatureNoise). There are
+// three such messages: initiator -> responder -> initiator,
+// then the responder confirms the handshake by the Ok
+type Noise struct {
+	Payload []byte // handshake message
+}
+
+// Type implements Msg interface
+func (*Noise) Type() Type { return NoiseType }
+
+// Encode the Noise
+func (n *Noise) Encode() []byte { return encode(n) }
+
 //
 // common
 //
@@ -158,6 +304,19 @@ func (*Err) Type() Type { return ErrType }
 // Encode the Err
 func (e *Err) Encode() []byte { return encode(e) }
 
+// A Cancel cancels request with given seq. The Cancel
+// has no reply, the canceled request has no reply too.
+// A Cancel of a request that has been replied ignored
+type Cancel struct {
+	Seq uint32 // seq of the request
+}
+
+// Type implements Msg interface
+func (*Cancel) Type() Type { return CancelType }
+
+// Encode the Cancel
+func (c *Cancel) Encode() []byte { return encode(c) }
+
 //
 // subscriptions
 //
@@ -199,6 +358,40 @@ func (u *Unsub) Encode() []byte {
 	)
 }
 
+// A SubLease is request for subscription that expires
+// after given lease (in nanoseconds). A subscriber must
+// send the SubLease again (renew it) before the lease
+// expires, otherwise remote peer unsubscribes it. Reply
+// is Ok or Err
+type SubLease struct {
+	Feed  cipher.PubKey
+	Lease int64 // time.Duration
+}
+
+// Type implements Msg interface
+func (*SubLease) Type() Type { return SubLeaseType }
+
+// Encode the SubLease
+func (s *SubLease) Encode() []byte { return encode(s) }
+
+// A SubFilter is request for subscription of a peer
+// that fills Root objects partially (see Node.SetFilter).
+// The Schemas is names of schemas the peer fills. Root
+// objects sent to the peer are never deltas. The Lease
+// is the same as the SubLease has, and zero means no
+// lease. Reply is Ok or Err
+type SubFilter struct {
+	Feed    cipher.PubKey
+	Lease   int64 // time.Duration, zero means no lease
+	Schemas []string
+}
+
+// Type implements Msg interface
+func (*SubFilter) Type() Type { return SubFilterType }
+
+// Encode the SubFilter
+func (s *SubFilter) Encode() []byte { return encode(s) }
+
 //
 // list of feeds
 //
@@ -250,6 +443,165 @@ func (*Root) Type() Type { return RootType }
 // Encode the Root
 func (r *Root) Encode() []byte { return encode(r) }
 
+// A RootDelta is a Root with objects of the Root
+// previous Root of the same head doesn't have. A
+// node sends the RootDelta instead of Root, thus
+// a subscriber that has the previous Root doesn't
+// need to request objects of the Root
+type RootDelta struct {
+	Feed  cipher.PubKey // feed }
+	Nonce uint64        // head } Root selector
+	Seq   uint64        // seq  }
+
+	Value []byte // encoded Root in person
+
+	Sig cipher.Sig // signature
+
+	Objects [][]byte // new objects
+}
+
+// Type implements Msg interface
+func (*RootDelta) Type() Type { return RootDeltaType }
+
+// Encode the RootDelta
+func (r *RootDelta) Encode() []byte { return encode(r) }
+
+// A RootDone is sent by a subscriber when it has
+// filled a Root received from a feed. Thus, a publisher
+// knows that the Root has been replicated. The RootDone
+// has no reply
+type RootDone struct {
+	Feed  cipher.PubKey // feed }
+	Nonce uint64        // head } Root selector
+	Seq   uint64        // seq  }
+}
+
+// Type implements Msg interface
+func (*RootDone) Type() Type { return RootDoneType }
+
+// Encode the RootDone
+func (r *RootDone) Encode() []byte { return encode(r) }
+
+// A Credit grants a peer right to push more Root
+// and RootDelta messages of a feed. The Msgs is number
+// of the messages, and the Bytes is total size of
+// objects of RootDelta messages. Credits are added to
+// credits granted before. A peer that never received
+// a Credit of a feed pushes messages of the feed
+// without limits
+type Credit struct {
+	Feed  cipher.PubKey
+	Msgs  uint32 // number of Root and RootDelta messages
+	Bytes uint32 // size of objects of RootDelta messages
+}
+
+// Type implements Msg interface
+func (*Credit) Type() Type { return CreditType }
+
+// Encode the Credit
+func (c *Credit) Encode() []byte { return encode(c) }
+
+// An Announce is gossip about new Root. Peers that
+// are not subscribed to the feed receive it instead
+// of the Root, and forward it to other peers. Thus,
+// a peer that shares the feed finds out that a newer
+// Root exists, and subscribes to the feed
+type Announce struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+	Seq   uint64
+	Hash  cipher.SHA256 // hash of the Root
+}
+
+// Type implements Msg interface
+func (*Announce) Type() Type { return AnnounceType }
+
+// Encode the Announce
+func (a *Announce) Encode() []byte { return encode(a) }
+
+// A RejectReason is reason of RootReject
+type RejectReason uint32
+
+// reasons of RootReject
+const (
+	RejectBadSignature    RejectReason = iota + 1 // or malformed Root
+	RejectOldSeq                                  // peer has newer Root
+	RejectUnknownRegistry                         // can't get Registry
+	RejectTooLarge                                // limits or quotas
+	RejectPolicy                                  // rejected by application
+)
+
+// String implements fmt.Stringer interface
+func (r RejectReason) String() string {
+	switch r {
+	case RejectBadSignature:
+		return "bad signature"
+	case RejectOldSeq:
+		return "old seq"
+	case RejectUnknownRegistry:
+		return "unknown registry"
+	case RejectTooLarge:
+		return "too large"
+	case RejectPolicy:
+		return "policy"
+	}
+	return fmt.Sprintf("RejectReason<%d>", r)
+}
+
+// A RootReject is sent by a subscriber that refuses
+// a Root pushed by a peer, with reason. Thus, a
+// publisher knows why its Root objects are not
+// replicated. The Err is human readable details.
+// The RootReject has no reply
+type RootReject struct {
+	Feed   cipher.PubKey // feed }
+	Nonce  uint64        // head } Root selector
+	Seq    uint64        // seq  }
+	Reason RejectReason
+	Err    string
+}
+
+// Type implements Msg interface
+func (*RootReject) Type() Type { return RootRejectType }
+
+// Encode the RootReject
+func (r *RootReject) Encode() []byte { return encode(r) }
+
+//
+// encryption
+//
+
+// A Sealed is encrypted message. The Box is encoded
+// message sealed by NaCl secretbox with key shared by
+// two nodes (ECDH of their keys). A reply for Sealed
+// request is Sealed too
+type Sealed struct {
+	Nonce [24]byte
+	Box   []byte
+}
+
+// Type implements Msg interface
+func (*Sealed) Type() Type { return SealedType }
+
+// Encode the Sealed
+func (s *Sealed) Encode() []byte { return encode(s) }
+
+// A RqSealedObject is request for an object of an
+// encrypted feed. The reply is Object with the Value
+// sealed by key of the feed (nonce followed by NaCl
+// secretbox), thus a relay that doesn't have the key
+// can forward the object, but can't read it
+type RqSealedObject struct {
+	Feed cipher.PubKey // encrypted feed
+	Key  cipher.SHA256 // request
+}
+
+// Type implements Msg interface
+func (*RqSealedObject) Type() Type { return RqSealedObjectType }
+
+// Encode the RqSealedObject
+func (r *RqSealedObject) Encode() []byte { return encode(r) }
+
 //
 // objects
 //
@@ -265,9 +617,11 @@ func (*RqObject) Type() Type { return RqObjectType }
 // Encode the RqObject
 func (r *RqObject) Encode() []byte { return encode(r) }
 
-// An Object reperesents encoded object
+// An Object reperesents encoded object. The Key is
+// key of the RqObject the Object is response for
 type Object struct {
-	Value []byte // encoded object in person
+	Key   cipher.SHA256 // requested key
+	Value []byte        // encoded object in person
 }
 
 // Type implements Msg interface
@@ -276,6 +630,38 @@ func (*Object) Type() Type { return ObjectType }
 // Encode the Object
 func (o *Object) Encode() []byte { return encode(o) }
 
+// A NotFound is reply for the RqObject if the
+// peer doesn't have requested object, and it
+// is not going to receive it
+type NotFound struct {
+	Key cipher.SHA256 // requested key
+}
+
+// Type implements Msg interface
+func (*NotFound) Type() Type { return NotFoundType }
+
+// Encode the NotFound
+func (n *NotFound) Encode() []byte { return encode(n) }
+
+// A DataChunk is a part of requested object that is
+// too big to be sent as one Object. A peer sends the
+// object as number of DataChunk in order, the Offset
+// is offset of the Payload in the object, and the
+// Total is size of the object. Receiver reassembles
+// the Object
+type DataChunk struct {
+	Key     cipher.SHA256 // requested key
+	Offset  uint32        // offset of the payload
+	Total   uint32        // size of the object
+	Payload []byte        // part of the object
+}
+
+// Type implements Msg interface
+func (*DataChunk) Type() Type { return DataChunkType }
+
+// Encode the DataChunk
+func (d *DataChunk) Encode() []byte { return encode(d) }
+
 //
 // preview
 //
@@ -291,6 +677,224 @@ func (*RqPreview) Type() Type { return RqPreviewType }
 // Encode the RqPreview
 func (r *RqPreview) Encode() []byte { return encode(r) }
 
+//
+// schemas
+//
+
+// A RqSchemas is request of particular schemas of a
+// Registry. It's useful for very large registries,
+// when only few types of a Registry are required
+type RqSchemas struct {
+	Registry cipher.SHA256   // registry.RegistryRef
+	Schemas  []cipher.SHA256 // []registry.SchemaRef
+}
+
+// Type implements Msg interface
+func (*RqSchemas) Type() Type { return RqSchemasType }
+
+// Encode the RqSchemas
+func (r *RqSchemas) Encode() []byte { return encode(r) }
+
+// A Schemas is reply for the RqSchemas. It contains
+// encoded schemas in order of the request
+type Schemas struct {
+	Schemas [][]byte
+}
+
+// Type implements Msg interface
+func (*Schemas) Type() Type { return SchemasType }
+
+// Encode the Schemas
+func (s *Schemas) Encode() []byte { return encode(s) }
+
+//
+// registries
+//
+
+// A RqRegistries is request of many registries by
+// one round trip. A Registry is an object, and the
+// RqRegistries is like many RqObject
+type RqRegistries struct {
+	Registries []cipher.SHA256 // []registry.RegistryRef
+}
+
+// Type implements Msg interface
+func (*RqRegistries) Type() Type { return RqRegistriesType }
+
+// Encode the RqRegistries
+func (r *RqRegistries) Encode() []byte { return encode(r) }
+
+// A Registries is reply for the RqRegistries. It
+// contains encoded registries in order of the request.
+// A registry is empty if the peer doesn't have it, or
+// if the reply can't contain it (too large); in this
+// case the registry should be requested as object
+type Registries struct {
+	Registries [][]byte
+}
+
+// Type implements Msg interface
+func (*Registries) Type() Type { return RegistriesType }
+
+// Encode the Registries
+func (r *Registries) Encode() []byte { return encode(r) }
+
+//
+// hole punching
+//
+
+// A RqRendezvous is request to a peer (rendezvous)
+// connected to this node and to the Peer. The
+// rendezvous introduces this node to the Peer for
+// UDP hole punching. The Port is KCP listening
+// port of the requester
+type RqRendezvous struct {
+	Peer cipher.PubKey // node id of the peer
+	Port uint16        // KCP listening port of the requester
+}
+
+// Type implements Msg interface
+func (*RqRendezvous) Type() Type { return RqRendezvousType }
+
+// Encode the RqRendezvous
+func (r *RqRendezvous) Encode() []byte { return encode(r) }
+
+// A Rendezvous is reply for the RqRendezvous. It
+// contains UDP address of the Peer as the rendezvous
+// sees it. The Peer is punching NAT to the requester
+// already, and the requester should connect to the
+// Address using KCP
+type Rendezvous struct {
+	Peer    cipher.PubKey // node id of the peer
+	Address string        // UDP address of the peer
+}
+
+// Type implements Msg interface
+func (*Rendezvous) Type() Type { return RendezvousType }
+
+// Encode the Rendezvous
+func (r *Rendezvous) Encode() []byte { return encode(r) }
+
+// A RqPunch is sent by rendezvous to the Peer requested
+// by the RqRendezvous. The Address is UDP address of
+// the requester (the Peer field). A receiver replies
+// with Punch and sends packets to the Address to open
+// its NAT for incoming KCP connection
+type RqPunch struct {
+	Peer    cipher.PubKey // node id of the requester
+	Address string        // UDP address of the requester
+}
+
+// Type implements Msg interface
+func (*RqPunch) Type() Type { return RqPunchType }
+
+// Encode the RqPunch
+func (r *RqPunch) Encode() []byte { return encode(r) }
+
+// A Punch is reply for the RqPunch
+type Punch struct {
+	Port uint16 // KCP listening port
+}
+
+// Type implements Msg interface
+func (*Punch) Type() Type { return PunchType }
+
+// Encode the Punch
+func (p *Punch) Encode() []byte { return encode(p) }
+
+//
+// ownership proof
+//
+
+// A RqChallenge is request for a challenge to prove
+// ownership of a feed (possession of secret key of
+// the feed)
+type RqChallenge struct {
+	Feed cipher.PubKey
+}
+
+// Type implements Msg interface
+func (*RqChallenge) Type() Type { return RqChallengeType }
+
+// Encode the RqChallenge
+func (r *RqChallenge) Encode() []byte { return encode(r) }
+
+// A Challenge is reply for the RqChallenge
+type Challenge struct {
+	Nonce cipher.SHA256 // random
+}
+
+// Type implements Msg interface
+func (*Challenge) Type() Type { return ChallengeType }
+
+// Encode the Challenge
+func (c *Challenge) Encode() []byte { return encode(c) }
+
+// A Proof is response for the Challenge. The Sig is
+// signature of SHA256(nonce + feed + id), where the
+// nonce is nonce of the Challenge, and the id is ID
+// of node that sends the Challenge. The Sig signed by
+// secret key of the feed
+type Proof struct {
+	Feed cipher.PubKey
+	Sig  cipher.Sig
+}
+
+// Type implements Msg interface
+func (*Proof) Type() Type { return ProofType }
+
+// Encode the Proof
+func (p *Proof) Encode() []byte { return encode(p) }
+
+//
+// shared feeds
+//
+
+// A WantFeeds is list of feeds a node is ready to
+// serve. A node sends the WantFeeds after handshake
+// and every time the list changes. The WantFeeds
+// has no reply
+type WantFeeds struct {
+	Feeds []cipher.PubKey
+}
+
+// Type implements Msg interface
+func (*WantFeeds) Type() Type { return WantFeedsType }
+
+// Encode the WantFeeds
+func (w *WantFeeds) Encode() []byte { return encode(w) }
+
+//
+// have-lists
+//
+
+// A RqHave requests have-list of a feed,
+// the reply is Have or Err
+type RqHave struct {
+	Feed cipher.PubKey
+}
+
+// Type implements Msg interface
+func (*RqHave) Type() Type { return RqHaveType }
+
+// Encode the RqHave
+func (r *RqHave) Encode() []byte { return encode(r) }
+
+// A Have is have-list of a feed. It's Bloom filter
+// of objects of last Root objects of the feed the
+// peer holds
+type Have struct {
+	Feed   cipher.PubKey
+	Hashes uint32 // number of hash functions
+	Bits   []byte // the filter
+}
+
+// Type implements Msg interface
+func (*Have) Type() Type { return HaveType }
+
+// Encode the Have
+func (h *Have) Encode() []byte { return encode(h) }
+
 //
 // Type / Encode / Deocode / String()
 //
@@ -321,6 +925,52 @@ const (
 	ObjectType   // 13
 
 	RqPreviewType // 14
+
+	RqSchemasType // 15
+	SchemasType   // 16
+
+	RqChallengeType // 17
+	ChallengeType   // 18
+	ProofType       // 19
+
+	WantFeedsType // 20
+
+	SubLeaseType // 21
+
+	RqHaveType // 22
+	HaveType   // 23
+
+	RootDeltaType // 24
+
+	SealedType // 25
+
+	RootDoneType // 26
+
+	CancelType // 27
+
+	NotFoundType // 28
+
+	DataChunkType // 29
+
+	CreditType // 30
+
+	AnnounceType // 31
+
+	RootRejectType // 32
+
+	RqRegistriesType // 33
+	RegistriesType   // 34
+
+	NoiseType // 35
+
+	RqRendezvousType // 36
+	RendezvousType   // 37
+	RqPunchType      // 38
+	PunchType        // 39
+
+	RqSealedObjectType // 40
+
+	SubFilterType // 41
 )
 
 // Type to string mapping
@@ -346,6 +996,52 @@ var msgTypeString = [...]string{
 	ObjectType:   "Object",
 
 	RqPreviewType: "RqPreview",
+
+	RqSchemasType: "RqSchemas",
+	SchemasType:   "Schemas",
+
+	RqChallengeType: "RqChallenge",
+	ChallengeType:   "Challenge",
+	ProofType:       "Proof",
+
+	WantFeedsType: "WantFeeds",
+
+	SubLeaseType: "SubLease",
+
+	RqHaveType: "RqHave",
+	HaveType:   "Have",
+
+	RootDeltaType: "RootDelta",
+
+	SealedType: "Sealed",
+
+	RootDoneType: "RootDone",
+
+	CancelType: "Cancel",
+
+	NotFoundType: "NotFound",
+
+	DataChunkType: "DataChunk",
+
+	CreditType: "Credit",
+
+	AnnounceType: "Announce",
+
+	RootRejectType: "RootReject",
+
+	RqRegistriesType: "RqRegistries",
+	RegistriesType:   "Registries",
+
+	NoiseType: "Noise",
+
+	RqRendezvousType: "RqRendezvous",
+	RendezvousType:   "Rendezvous",
+	RqPunchType:      "RqPunch",
+	PunchType:        "Punch",
+
+	RqSealedObjectType: "RqSealedObject",
+
+	SubFilterType: "SubFilter",
 }
 
 // String implements fmt.Stringer interface
@@ -353,6 +1049,9 @@ func (m Type) String() string {
 	if im := int(m); im > 0 && im < len(msgTypeString) {
 		return msgTypeString[im]
 	}
+	if m.IsUser() == true {
+		return fmt.Sprintf("User<%d>", m)
+	}
 	return fmt.Sprintf("Type<%d>", m)
 }
 
@@ -378,6 +1077,52 @@ var forwardRegistry = [...]reflect.Type{
 	ObjectType:   reflect.TypeOf(Object{}),
 
 	RqPreviewType: reflect.TypeOf(RqPreview{}),
+
+	RqSchemasType: reflect.TypeOf(RqSchemas{}),
+	SchemasType:   reflect.TypeOf(Schemas{}),
+
+	RqChallengeType: reflect.TypeOf(RqChallenge{}),
+	ChallengeType:   reflect.TypeOf(Challenge{}),
+	ProofType:       reflect.TypeOf(Proof{}),
+
+	WantFeedsType: reflect.TypeOf(WantFeeds{}),
+
+	SubLeaseType: reflect.TypeOf(SubLease{}),
+
+	RqHaveType: reflect.TypeOf(RqHave{}),
+	HaveType:   reflect.TypeOf(Have{}),
+
+	RootDeltaType: reflect.TypeOf(RootDelta{}),
+
+	SealedType: reflect.TypeOf(Sealed{}),
+
+	RootDoneType: reflect.TypeOf(RootDone{}),
+
+	CancelType: reflect.TypeOf(Cancel{}),
+
+	NotFoundType: reflect.TypeOf(NotFound{}),
+
+	DataChunkType: reflect.TypeOf(DataChunk{}),
+
+	CreditType: reflect.TypeOf(Credit{}),
+
+	AnnounceType: reflect.TypeOf(Announce{}),
+
+	RootRejectType: reflect.TypeOf(RootReject{}),
+
+	RqRegistriesType: reflect.TypeOf(RqRegistries{}),
+	RegistriesType:   reflect.TypeOf(Registries{}),
+
+	NoiseType: reflect.TypeOf(Noise{}),
+
+	RqRendezvousType: reflect.TypeOf(RqRendezvous{}),
+	RendezvousType:   reflect.TypeOf(Rendezvous{}),
+	RqPunchType:      reflect.TypeOf(RqPunch{}),
+	PunchType:        reflect.TypeOf(Punch{}),
+
+	RqSealedObjectType: reflect.TypeOf(RqSealedObject{}),
+
+	SubFilterType: reflect.TypeOf(SubFilter{}),
 }
 
 // An InvalidTypeError represents decoding error when
@@ -426,15 +1171,17 @@ func Decode(p []byte) (msg Msg, err error) {
 		return
 	}
 
-	var mt = Type(p[0])
+	var (
+		mt  = Type(p[0])
+		typ = msgType(mt)
+	)
 
-	if mt <= 0 || int(mt) >= len(forwardRegistry) {
+	if typ == nil {
 		err = InvalidTypeError{mt}
 		return
 	}
 
 	var (
-		typ = forwardRegistry[mt]
 		val = reflect.New(typ)
 
 		n int
@@ -0,0 +1,84 @@
+package msg
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// UserType is the first application-defined Type.
+// Types from the UserType to 255 are reserved for
+// applications (see RegisterUser)
+const UserType Type = 128
+
+// registered application-defined messages
+var userRegistry struct {
+	sync.RWMutex
+	types [256 - int(UserType)]reflect.Type
+}
+
+// RegisterUser registers application-defined message
+// with given Type that must be UserType or greater.
+// The prototype is pointer to struct that used to
+// decode received messages of the Type. The Encode
+// method of the message should use the Encode function.
+// The RegisterUser returns error if the Type is invalid,
+// or already registered, or the prototype doesn't
+// match the Type
+func RegisterUser(t Type, prototype Msg) (err error) {
+
+	if t < UserType {
+		return fmt.Errorf("type %d is reserved by CXO, use %d-255",
+			t, UserType)
+	}
+
+	if prototype == nil {
+		return fmt.Errorf("nil prototype of type %d", t)
+	}
+
+	if pt := prototype.Type(); pt != t {
+		return fmt.Errorf("prototype of type %d has type %d", t, pt)
+	}
+
+	var typ = reflect.TypeOf(prototype)
+
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("prototype of type %d is not pointer to struct: %T",
+			t, prototype)
+	}
+
+	userRegistry.Lock()
+	defer userRegistry.Unlock()
+
+	var i = int(t - UserType)
+
+	if userRegistry.types[i] != nil {
+		return fmt.Errorf("type %d already registered for %s", t,
+			userRegistry.types[i].String())
+	}
+
+	userRegistry.types[i] = typ.Elem()
+	return
+}
+
+// registered application-defined message or nil
+func userType(t Type) (typ reflect.Type) {
+
+	userRegistry.RLock()
+	defer userRegistry.RUnlock()
+
+	return userRegistry.types[int(t-UserType)]
+}
+
+// IsUser returns true if given Type is
+// application-defined (see UserType)
+func (m Type) IsUser() bool {
+	return m >= UserType
+}
+
+// Encode given message to []byte prefixed by
+// its Type. Use it to implement Encode method
+// of application-defined messages
+func Encode(m Msg) []byte {
+	return encode(m)
+}
@@ -0,0 +1,40 @@
+package node
+
+import (
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// apply Config.OnSendMsg, the hookSend returns nil
+// if the message dropped; a Sealed is already hooked
+// before encryption
+func (c *Conn) hookSend(m msg.Msg) (pass msg.Msg) {
+
+	if _, ok := m.(*msg.Sealed); ok == true {
+		return m
+	}
+
+	if c.n.config.OnSendMsg == nil {
+		return m
+	}
+
+	if pass = c.n.config.OnSendMsg(c, m); pass == nil {
+		c.n.Debugf(MsgSendPin, "[%s] dropped %T", c.String(), m)
+	}
+
+	return
+}
+
+// apply Config.OnRecvMsg, the hookRecv returns nil
+// if the message dropped
+func (c *Conn) hookRecv(m msg.Msg) (pass msg.Msg) {
+
+	if c.n.config.OnRecvMsg == nil {
+		return m
+	}
+
+	if pass = c.n.config.OnRecvMsg(c, m); pass == nil {
+		c.n.Debugf(MsgReceivePin, "[%s] dropped %T", c.String(), m)
+	}
+
+	return
+}
@@ -0,0 +1,129 @@
+package node
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestChainMsgHooks(t *testing.T) {
+
+	var (
+		ping msg.Msg = &msg.Ping{}
+		pong msg.Msg = &msg.Pong{}
+
+		calls int
+	)
+
+	var hook = ChainMsgHooks(
+		func(c *Conn, m msg.Msg) msg.Msg {
+			calls++
+			if m == ping {
+				return pong // replace
+			}
+			return m
+		},
+		nil, // skipped
+		func(c *Conn, m msg.Msg) msg.Msg {
+			calls++
+			if m == pong {
+				return nil // drop
+			}
+			return m
+		},
+		func(c *Conn, m msg.Msg) msg.Msg {
+			calls++
+			return m
+		},
+	)
+
+	if hook(nil, ping) != nil {
+		t.Error("not dropped")
+	}
+
+	if calls != 2 {
+		t.Errorf("wrong number of calls: %d", calls)
+	}
+
+	calls = 0
+
+	if hook(nil, &msg.Ok{}) == nil {
+		t.Error("dropped")
+	}
+
+	if calls != 3 {
+		t.Errorf("wrong number of calls: %d", calls)
+	}
+
+}
+
+func TestConfig_OnSendMsg(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+
+		received  int32
+		dropPings int32
+	)
+
+	// drop Pong replies
+	sconf.OnSendMsg = func(c *Conn, m msg.Msg) msg.Msg {
+		if _, ok := m.(*msg.Pong); ok == true {
+			return nil
+		}
+		return m
+	}
+
+	// count received Pings
+	sconf.OnRecvMsg = func(c *Conn, m msg.Msg) msg.Msg {
+		if _, ok := m.(*msg.Ping); ok == true {
+			atomic.AddInt32(&received, 1)
+		}
+		return m
+	}
+
+	// drop sent Pings if set
+	cconf.OnSendMsg = func(c *Conn, m msg.Msg) msg.Msg {
+		if _, ok := m.(*msg.Ping); ok == true &&
+			atomic.LoadInt32(&dropPings) == 1 {
+
+			return nil
+		}
+		return m
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.ping(100 * time.Millisecond); err != ErrTimeout {
+		t.Errorf("wrong error: want %v, got %v", ErrTimeout, err)
+	}
+
+	if atomic.LoadInt32(&received) != 1 {
+		t.Error("Ping is not hooked")
+	}
+
+	atomic.StoreInt32(&dropPings, 1)
+
+	if _, err = c.Ping(); err != ErrDropped {
+		t.Errorf("wrong error: want %v, got %v", ErrDropped, err)
+	}
+
+}
@@ -0,0 +1,170 @@
+package node
+
+import (
+	"sort"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/statutil"
+)
+
+// A MsgStat represents traffic statistic of messages
+// of a msg.Type of a connection. Messages are counted
+// as they are on the wire, thus encrypted messages are
+// counted as msg.Sealed, and big objects are counted
+// as msg.DataChunk. Sizes include headers and are sizes
+// of compressed messages
+type MsgStat struct {
+	Sent      int64 // sent messages
+	SentBytes int64 // size of sent messages
+	Recv      int64 // received messages
+	RecvBytes int64 // size of received messages
+
+	// Errors is number of failed requests of the type
+	// (timeouts and error replies), and number of
+	// received messages of the type that can't be
+	// handled
+	Errors int64
+
+	// FailedTrace is trace ID of last failed request
+	// of the type, or zero (see TraceID)
+	FailedTrace TraceID
+
+	// Latency is average time between a request of
+	// the type and its reply, or zero
+	Latency time.Duration
+}
+
+// A ConnStat represents traffic statistic of a Conn
+type ConnStat struct {
+	Address  string               // address of the Conn
+	PeerID   cipher.PubKey        // id of the peer
+	Incoming bool                 // is incoming
+	Dropped  int64                // dropped by send queue
+	Msgs     map[msg.Type]MsgStat // by type
+}
+
+// statistic of a type
+type msgStat struct {
+	MsgStat
+	latency *statutil.Duration // or nil
+}
+
+// get or create stat of given type, the
+// c.mx must be locked
+func (c *Conn) getMsgStat(mt msg.Type) (ms *msgStat) {
+
+	if ms = c.stats[mt]; ms != nil {
+		return
+	}
+
+	if c.stats == nil {
+		c.stats = make(map[msg.Type]*msgStat)
+	}
+
+	ms = new(msgStat)
+	c.stats[mt] = ms
+	return
+}
+
+// a message of given type and size sent
+func (c *Conn) statSent(mt msg.Type, size int) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var ms = c.getMsgStat(mt)
+	ms.Sent++
+	ms.SentBytes += int64(size)
+}
+
+// a message of given type and size received
+func (c *Conn) statRecv(mt msg.Type, size int) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var ms = c.getMsgStat(mt)
+	ms.Recv++
+	ms.RecvBytes += int64(size)
+}
+
+// a request or a received message of given type failed
+func (c *Conn) statError(mt msg.Type) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.getMsgStat(mt).Errors++
+}
+
+// a request of given type with given trace ID failed
+func (c *Conn) statFailure(mt msg.Type, trace TraceID) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var ms = c.getMsgStat(mt)
+	ms.Errors++
+
+	if trace != 0 {
+		ms.FailedTrace = trace
+	}
+}
+
+// a request of given type replied
+func (c *Conn) statLatency(mt msg.Type, latency time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var ms = c.getMsgStat(mt)
+
+	if ms.latency == nil {
+		ms.latency = statutil.NewDuration(c.n.config.Config.RollAvgSamples)
+	}
+
+	ms.latency.Add(latency)
+}
+
+// MsgStats returns traffic statistic of the Conn
+// by message type (see MsgStat)
+func (c *Conn) MsgStats() (stats map[msg.Type]MsgStat) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	stats = make(map[msg.Type]MsgStat, len(c.stats))
+
+	for mt, ms := range c.stats {
+		var s = ms.MsgStat
+		if ms.latency != nil {
+			s.Latency = ms.latency.Value()
+		}
+		stats[mt] = s
+	}
+
+	return
+}
+
+// Stats returns traffic statistic of all established
+// connections of the Node ordered by address. See also
+// Stat for statistic of the Node
+func (n *Node) Stats() (stats []ConnStat) {
+
+	var cs = n.Connections()
+
+	stats = make([]ConnStat, 0, len(cs))
+
+	for _, c := range cs {
+		stats = append(stats, ConnStat{
+			Address:  c.Address(),
+			PeerID:   c.PeerID(),
+			Incoming: c.IsIncoming(),
+			Dropped:  c.QueueDrops(),
+			Msgs:     c.MsgStats(),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Address < stats[j].Address
+	})
+
+	return
+}
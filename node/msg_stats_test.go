@@ -0,0 +1,81 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestNode_Stats(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	var stats = cn.Stats()
+
+	if len(stats) != 1 {
+		t.Fatalf("wrong number of connections: %d", len(stats))
+	}
+
+	var cs = stats[0]
+
+	if cs.Address != c.Address() || cs.PeerID != sn.ID() || cs.Incoming {
+		t.Errorf("wrong ConnStat: %#v", cs)
+	}
+
+	var ping, pong = cs.Msgs[msg.PingType], cs.Msgs[msg.PongType]
+
+	if ping.Sent != 1 || ping.SentBytes <= 0 || ping.Errors != 0 {
+		t.Errorf("wrong Ping stat: %#v", ping)
+	}
+
+	if ping.Latency <= 0 {
+		t.Error("missing latency")
+	}
+
+	if pong.Recv != 1 || pong.RecvBytes <= 0 {
+		t.Errorf("wrong Pong stat: %#v", pong)
+	}
+
+	// the server side
+	time.Sleep(50 * time.Millisecond)
+
+	var sc = sn.Connections()
+	if len(sc) != 1 {
+		t.Fatalf("wrong number of connections: %d", len(sc))
+	}
+
+	var ss = sc[0].MsgStats()
+
+	if ss[msg.PingType].Recv != 1 || ss[msg.PongType].Sent != 1 {
+		t.Errorf("wrong server stat: %#v", ss)
+	}
+
+}
+
+func TestConn_statError(t *testing.T) {
+
+	var c = &Conn{n: &Node{config: NewConfig()}}
+
+	c.statError(msg.RqObjectType)
+	c.statFailure(msg.RqObjectType, 7)
+	c.statLatency(msg.RqObjectType, time.Second)
+
+	var ms = c.MsgStats()[msg.RqObjectType]
+
+	if ms.Errors != 2 || ms.FailedTrace != 7 || ms.Latency != time.Second {
+		t.Errorf("wrong stat: %#v", ms)
+	}
+
+}
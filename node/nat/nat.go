@@ -0,0 +1,86 @@
+// Package nat implements port mapping on NAT gateway
+// using NAT-PMP (RFC 6886) or UPnP IGD (Internet
+// Gateway Device). The node package uses it to accept
+// inbound connections behind home routers without
+// manual configuration (see node.Config.PortMapping)
+package nat
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// errors
+var (
+	ErrNotFound = errors.New("nat: gateway not found")
+)
+
+// An Interface represents NAT gateway
+type Interface interface {
+	// ExternalIP returns external IP address of the gateway
+	ExternalIP() (ip net.IP, err error)
+	// AddMapping maps external port of the gateway to
+	// internal port of this host for given lifetime. The
+	// protocol is "tcp" or "udp". The gateway can choose
+	// another external port, and the AddMapping returns
+	// the port mapped
+	AddMapping(
+		protocol string, //        : tcp or udp
+		extPort int, //            : desired external port
+		intPort int, //            : internal port
+		desc string, //            : description of the mapping
+		lifetime time.Duration, // : lifetime of the mapping
+	) (
+		mapped int, //             : external port mapped
+		err error, //              : an error
+	)
+	// DeleteMapping removes mapping
+	DeleteMapping(protocol string, extPort, intPort int) (err error)
+	// String returns name of the gateway
+	String() string
+}
+
+// Discover NAT gateway using NAT-PMP and UPnP at the
+// same time. The Discover returns gateway found first
+// or ErrNotFound
+func Discover(timeout time.Duration) (gw Interface, err error) {
+
+	var (
+		found = make(chan Interface, 2)
+		tm    = time.NewTimer(timeout)
+	)
+
+	defer tm.Stop()
+
+	go func() {
+		var pmp, err = DiscoverPMP(timeout)
+		if err != nil {
+			found <- nil
+			return
+		}
+		found <- pmp
+	}()
+
+	go func() {
+		var upnp, err = DiscoverUPnP(timeout)
+		if err != nil {
+			found <- nil
+			return
+		}
+		found <- upnp
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case gw = <-found:
+			if gw != nil {
+				return
+			}
+		case <-tm.C:
+			return nil, ErrNotFound
+		}
+	}
+
+	return nil, ErrNotFound
+}
@@ -0,0 +1,242 @@
+package nat
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fake NAT-PMP gateway, it maps
+// requested port + 1000
+func testPMPServer(t *testing.T) (gw string, stop func()) {
+
+	var conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		var buf = make([]byte, 16)
+		for {
+			var n, addr, err = conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 2 {
+				continue
+			}
+			var resp = make([]byte, 16)
+			resp[1] = buf[1] + 128
+			switch buf[1] {
+			case 0:
+				copy(resp[8:], []byte{1, 2, 3, 4})
+				conn.WriteTo(resp[:12], addr)
+			case 1, 2:
+				copy(resp[8:], buf[4:6]) // internal port
+				var ext = binary.BigEndian.Uint16(buf[6:])
+				if ext != 0 {
+					ext += 1000
+				}
+				binary.BigEndian.PutUint16(resp[10:], ext)
+				copy(resp[12:], buf[8:12]) // lifetime
+				conn.WriteTo(resp, addr)
+			default:
+				binary.BigEndian.PutUint16(resp[2:], 5) // unsupported
+				conn.WriteTo(resp[:8], addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String(), func() { conn.Close() }
+}
+
+func TestPMP(t *testing.T) {
+
+	var gw, stop = testPMPServer(t)
+	defer stop()
+
+	var p = NewPMP(gw, time.Second)
+
+	var ip, err = p.ExternalIP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip.String() != "1.2.3.4" {
+		t.Error("wrong external IP:", ip)
+	}
+
+	var mapped int
+	if mapped, err = p.AddMapping("tcp", 8870, 8870, "cxo", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if mapped != 9870 {
+		t.Error("wrong port mapped:", mapped)
+	}
+
+	if err = p.DeleteMapping("tcp", mapped, 8870); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = p.AddMapping("sctp", 1, 1, "", time.Minute); err == nil {
+		t.Error("missing error")
+	}
+}
+
+func TestPMP_timeout(t *testing.T) {
+
+	var conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var p = NewPMP(conn.LocalAddr().String(), 300*time.Millisecond)
+
+	if _, err = p.ExternalIP(); err == nil {
+		t.Error("missing error")
+	}
+}
+
+const testDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+<device>
+  <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+  <deviceList><device>
+    <deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+    <deviceList><device>
+      <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+      <serviceList><service>
+        <serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+        <controlURL>/ctl/IPConn</controlURL>
+      </service></serviceList>
+    </device></deviceList>
+  </device></deviceList>
+</device>
+</root>`
+
+const testEnvelope = `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>%s</s:Body></s:Envelope>`
+
+// fake UPnP IGD
+func testUPnPServer(t *testing.T, actions chan<- string) *httptest.Server {
+
+	var mux = http.NewServeMux()
+
+	mux.HandleFunc("/desc.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testDescription)
+	})
+
+	mux.HandleFunc("/ctl/IPConn", func(w http.ResponseWriter, r *http.Request) {
+
+		var action = r.Header.Get("SOAPAction")
+		var body, _ = ioutil.ReadAll(r.Body)
+
+		action = action[strings.Index(action, "#")+1 : len(action)-1]
+		actions <- action
+
+		switch action {
+		case "GetExternalIPAddress":
+			fmt.Fprintf(w, testEnvelope, `<u:GetExternalIPAddressResponse>`+
+				`<NewExternalIPAddress>5.6.7.8</NewExternalIPAddress>`+
+				`</u:GetExternalIPAddressResponse>`)
+		case "AddPortMapping":
+			if strings.Contains(string(body),
+				"<NewExternalPort>1</NewExternalPort>") {
+
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, testEnvelope, `<s:Fault><detail><UPnPError>`+
+					`<errorCode>718</errorCode>`+
+					`<errorDescription>ConflictInMappingEntry</errorDescription>`+
+					`</UPnPError></detail></s:Fault>`)
+				return
+			}
+			fmt.Fprintf(w, testEnvelope, `<u:AddPortMappingResponse/>`)
+		case "DeletePortMapping":
+			fmt.Fprintf(w, testEnvelope, `<u:DeletePortMappingResponse/>`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestUPnP(t *testing.T) {
+
+	var actions = make(chan string, 10)
+
+	var ts = testUPnPServer(t, actions)
+	defer ts.Close()
+
+	var u, err = NewUPnP(ts.URL+"/desc.xml", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.control != ts.URL+"/ctl/IPConn" {
+		t.Error("wrong control URL:", u.control)
+	}
+
+	var ip net.IP
+	if ip, err = u.ExternalIP(); err != nil {
+		t.Fatal(err)
+	}
+	if ip.String() != "5.6.7.8" {
+		t.Error("wrong external IP:", ip)
+	}
+
+	var mapped int
+	if mapped, err = u.AddMapping("udp", 8870, 8870, "cxo", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if mapped != 8870 {
+		t.Error("wrong port mapped:", mapped)
+	}
+
+	if err = u.DeleteMapping("udp", mapped, 8870); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = u.AddMapping("tcp", 1, 1, "", time.Minute); err == nil {
+		t.Error("missing error")
+	} else if strings.Contains(err.Error(), "718") == false {
+		t.Error("unexpected error:", err)
+	}
+
+	close(actions)
+
+	var want = []string{
+		"GetExternalIPAddress",
+		"AddPortMapping",
+		"DeletePortMapping",
+		"AddPortMapping",
+	}
+
+	var i int
+	for action := range actions {
+		if i >= len(want) || action != want[i] {
+			t.Error("unexpected action:", action)
+		}
+		i++
+	}
+}
+
+func TestNewUPnP_notGateway(t *testing.T) {
+
+	var ts = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `<root><device></device></root>`)
+		}))
+	defer ts.Close()
+
+	if _, err := NewUPnP(ts.URL, time.Second); err == nil {
+		t.Error("missing error")
+	}
+}
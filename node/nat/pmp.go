@@ -0,0 +1,268 @@
+package nat
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// NAT-PMP port of gateway
+const pmpPort = 5351
+
+// first retransmission timeout of NAT-PMP request,
+// it doubles for every next retransmission
+const pmpRetryTimeout = 250 * time.Millisecond
+
+// result codes of NAT-PMP
+var pmpResults = [...]string{
+	0: "success",
+	1: "unsupported version",
+	2: "not authorized or refused",
+	3: "network failure",
+	4: "out of resources",
+	5: "unsupported opcode",
+}
+
+// A PMP represents NAT-PMP gateway
+type PMP struct {
+	gateway string        // host:port
+	timeout time.Duration // request timeout
+}
+
+// NewPMP returns NAT-PMP gateway with given address.
+// The address is IP or host:port
+func NewPMP(gateway string, timeout time.Duration) (p *PMP) {
+
+	if _, _, err := net.SplitHostPort(gateway); err != nil {
+		gateway = net.JoinHostPort(gateway, fmt.Sprint(pmpPort))
+	}
+
+	return &PMP{gateway: gateway, timeout: timeout}
+}
+
+// DiscoverPMP finds NAT-PMP gateway, it tries default
+// gateway of this host, or .1 address of every local
+// IPv4 network, if the default gateway is unknown
+func DiscoverPMP(timeout time.Duration) (p *PMP, err error) {
+
+	var gws = gateways()
+
+	if len(gws) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var found = make(chan *PMP, len(gws))
+
+	for _, gw := range gws {
+		go func(gw net.IP) {
+			var p = NewPMP(gw.String(), timeout)
+			if _, err := p.ExternalIP(); err != nil {
+				found <- nil
+				return
+			}
+			found <- p
+		}(gw)
+	}
+
+	for range gws {
+		if p = <-found; p != nil {
+			return
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// String implements Interface
+func (p *PMP) String() string {
+	return "NAT-PMP(" + p.gateway + ")"
+}
+
+// send request and receive response retransmitting
+// the request (see RFC 6886, 3.1)
+func (p *PMP) request(rq []byte, size int) (resp []byte, err error) {
+
+	var conn net.Conn
+	if conn, err = net.Dial("udp", p.gateway); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var (
+		deadline = time.Now().Add(p.timeout)
+		retry    = pmpRetryTimeout
+		buf      = make([]byte, 16)
+	)
+
+	for time.Now().Before(deadline) {
+
+		if _, err = conn.Write(rq); err != nil {
+			return
+		}
+
+		var next = time.Now().Add(retry)
+		if next.After(deadline) {
+			next = deadline
+		}
+		conn.SetReadDeadline(next)
+
+		retry *= 2
+
+		for {
+
+			var n int
+			if n, err = conn.Read(buf); err != nil {
+				break // retry or timeout
+			}
+
+			// ignore unexpected packets
+			if n < size || buf[0] != 0 || buf[1] != rq[1]+128 {
+				continue
+			}
+
+			var code = binary.BigEndian.Uint16(buf[2:])
+
+			if code != 0 {
+				var reason = "unknown"
+				if int(code) < len(pmpResults) {
+					reason = pmpResults[code]
+				}
+				return nil, fmt.Errorf("nat: NAT-PMP error %d: %s", code,
+					reason)
+			}
+
+			return buf[:n], nil
+		}
+
+		if ne, ok := err.(net.Error); ok == false || ne.Timeout() == false {
+			return
+		}
+	}
+
+	return nil, errors.New("nat: NAT-PMP timeout")
+}
+
+// ExternalIP implements Interface
+func (p *PMP) ExternalIP() (ip net.IP, err error) {
+
+	var resp []byte
+	if resp, err = p.request([]byte{0, 0}, 12); err != nil {
+		return
+	}
+
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+// AddMapping implements Interface
+func (p *PMP) AddMapping(
+	protocol string,
+	extPort int,
+	intPort int,
+	_ string,
+	lifetime time.Duration,
+) (
+	mapped int,
+	err error,
+) {
+
+	var rq = make([]byte, 12)
+
+	switch protocol {
+	case "udp":
+		rq[1] = 1
+	case "tcp":
+		rq[1] = 2
+	default:
+		return 0, fmt.Errorf("nat: unknown protocol %q", protocol)
+	}
+
+	binary.BigEndian.PutUint16(rq[4:], uint16(intPort))
+	binary.BigEndian.PutUint16(rq[6:], uint16(extPort))
+	binary.BigEndian.PutUint32(rq[8:], uint32(lifetime/time.Second))
+
+	var resp []byte
+	if resp, err = p.request(rq, 16); err != nil {
+		return
+	}
+
+	return int(binary.BigEndian.Uint16(resp[10:])), nil
+}
+
+// DeleteMapping implements Interface
+func (p *PMP) DeleteMapping(protocol string, _, intPort int) (err error) {
+	_, err = p.AddMapping(protocol, 0, intPort, "", 0)
+	return
+}
+
+// default gateway (Linux) or guessed gateways
+func gateways() (gws []net.IP) {
+
+	if gw := defaultGateway(); gw != nil {
+		return []net.IP{gw}
+	}
+
+	var addrs, err = net.InterfaceAddrs()
+	if err != nil {
+		return
+	}
+
+	for _, addr := range addrs {
+
+		var ipnet, ok = addr.(*net.IPNet)
+		if ok == false || ipnet.IP.IsLoopback() == true {
+			continue
+		}
+
+		var ip4 = ipnet.IP.To4()
+		if ip4 == nil {
+			continue
+		}
+
+		var gw = ip4.Mask(ipnet.Mask)
+		gw[3] |= 1
+
+		if gw.Equal(ip4) == false {
+			gws = append(gws, gw)
+		}
+	}
+
+	return
+}
+
+// default gateway from /proc/net/route or nil
+func defaultGateway() net.IP {
+
+	var f, err = os.Open("/proc/net/route")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var sc = bufio.NewScanner(f)
+
+	for sc.Scan() {
+
+		// Iface Destination Gateway ...
+		var fields = strings.Fields(sc.Text())
+
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+
+		var gw []byte
+		if gw, err = hex.DecodeString(fields[2]); err != nil || len(gw) != 4 {
+			continue
+		}
+
+		// little-endian
+		return net.IPv4(gw[3], gw[2], gw[1], gw[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package nat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SSDP multicast address
+const ssdpAddress = "239.255.255.250:1900"
+
+// services of WAN connection, preferred first
+var upnpServices = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// max size of description of a device
+const upnpMaxDescription = 1 << 20
+
+// A UPnP represents UPnP IGD
+type UPnP struct {
+	control string       // control URL
+	service string       // service type
+	local   net.IP       // IP of this host seen by the gateway
+	client  *http.Client // with timeout
+}
+
+// DiscoverUPnP finds UPnP IGD using SSDP
+func DiscoverUPnP(timeout time.Duration) (u *UPnP, err error) {
+
+	var conn net.PacketConn
+	if conn, err = net.ListenPacket("udp4", ":0"); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var addr *net.UDPAddr
+	if addr, err = net.ResolveUDPAddr("udp4", ssdpAddress); err != nil {
+		return
+	}
+
+	var rq = "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddress + "\r\n" +
+		"ST: urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n\r\n"
+
+	if _, err = conn.WriteTo([]byte(rq), addr); err != nil {
+		return
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	var (
+		buf  = make([]byte, 2048)
+		seen = make(map[string]struct{})
+	)
+
+	for {
+
+		var n int
+		if n, _, err = conn.ReadFrom(buf); err != nil {
+			return nil, ErrNotFound
+		}
+
+		var resp *http.Response
+		resp, err = http.ReadResponse(
+			bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+
+		var location = resp.Header.Get("Location")
+
+		if _, ok := seen[location]; ok == true || location == "" {
+			continue
+		}
+		seen[location] = struct{}{}
+
+		if u, err = NewUPnP(location, timeout); err == nil {
+			return
+		}
+	}
+}
+
+// description of a device
+type upnpDevice struct {
+	Services []struct {
+		ServiceType string `xml:"serviceType"`
+		ControlURL  string `xml:"controlURL"`
+	} `xml:"serviceList>service"`
+	Devices []upnpDevice `xml:"deviceList>device"`
+}
+
+// find service of WAN connection
+func (d *upnpDevice) find(service string) (control string, ok bool) {
+
+	for _, s := range d.Services {
+		if s.ServiceType == service {
+			return s.ControlURL, true
+		}
+	}
+
+	for i := range d.Devices {
+		if control, ok = d.Devices[i].find(service); ok == true {
+			return
+		}
+	}
+
+	return
+}
+
+// NewUPnP creates UPnP IGD using URL of its description
+func NewUPnP(location string, timeout time.Duration) (u *UPnP, err error) {
+
+	var base *url.URL
+	if base, err = url.Parse(location); err != nil {
+		return
+	}
+
+	var client = &http.Client{Timeout: timeout}
+
+	var resp *http.Response
+	if resp, err = client.Get(location); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nat: UPnP description: %s", resp.Status)
+	}
+
+	var desc struct {
+		URLBase string     `xml:"URLBase"`
+		Device  upnpDevice `xml:"device"`
+	}
+
+	var dec = xml.NewDecoder(io.LimitReader(resp.Body, upnpMaxDescription))
+	if err = dec.Decode(&desc); err != nil {
+		return
+	}
+
+	if desc.URLBase != "" {
+		if base, err = url.Parse(desc.URLBase); err != nil {
+			return
+		}
+	}
+
+	for _, service := range upnpServices {
+
+		var control, ok = desc.Device.find(service)
+		if ok == false {
+			continue
+		}
+
+		var cu *url.URL
+		if cu, err = base.Parse(control); err != nil {
+			return
+		}
+
+		u = &UPnP{control: cu.String(), service: service, client: client}
+
+		if u.local, err = localIP(cu.Host); err != nil {
+			return nil, err
+		}
+
+		return
+	}
+
+	return nil, errors.New("nat: not an Internet gateway device")
+}
+
+// IP of this host used to connect to given host
+func localIP(host string) (ip net.IP, err error) {
+
+	if _, _, err = net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "80")
+	}
+
+	var conn net.Conn
+	if conn, err = net.Dial("udp", host); err != nil {
+		return // no packets sent
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+// String implements Interface
+func (u *UPnP) String() string {
+	return "UPnP(" + u.control + ")"
+}
+
+// perform SOAP action
+func (u *UPnP) action(name, args string, reply interface{}) (err error) {
+
+	var body = `<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" ` +
+		`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">` +
+		`<s:Body><u:` + name + ` xmlns:u="` + u.service + `">` + args +
+		`</u:` + name + `></s:Body></s:Envelope>`
+
+	var rq *http.Request
+	if rq, err = http.NewRequest(http.MethodPost, u.control,
+		strings.NewReader(body)); err != nil {
+
+		return
+	}
+
+	rq.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	rq.Header.Set("SOAPAction", `"`+u.service+`#`+name+`"`)
+
+	var resp *http.Response
+	if resp, err = u.client.Do(rq); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Body struct {
+			Inner []byte `xml:",innerxml"`
+			Fault *struct {
+				Code   int    `xml:"detail>UPnPError>errorCode"`
+				Reason string `xml:"detail>UPnPError>errorDescription"`
+			} `xml:"Fault"`
+		} `xml:"Body"`
+	}
+
+	var dec = xml.NewDecoder(io.LimitReader(resp.Body, upnpMaxDescription))
+	if err = dec.Decode(&envelope); err != nil {
+		return fmt.Errorf("nat: UPnP %s: %s", name, resp.Status)
+	}
+
+	if f := envelope.Body.Fault; f != nil || resp.StatusCode != http.StatusOK {
+		if f == nil {
+			return fmt.Errorf("nat: UPnP %s: %s", name, resp.Status)
+		}
+		return fmt.Errorf("nat: UPnP %s error %d: %s", name, f.Code, f.Reason)
+	}
+
+	if reply != nil {
+		return xml.Unmarshal(envelope.Body.Inner, reply)
+	}
+
+	return
+}
+
+// ExternalIP implements Interface
+func (u *UPnP) ExternalIP() (ip net.IP, err error) {
+
+	var reply struct {
+		IP string `xml:"NewExternalIPAddress"`
+	}
+
+	if err = u.action("GetExternalIPAddress", "", &reply); err != nil {
+		return
+	}
+
+	if ip = net.ParseIP(strings.TrimSpace(reply.IP)); ip == nil {
+		return nil, fmt.Errorf("nat: invalid external IP %q", reply.IP)
+	}
+
+	return
+}
+
+// AddMapping implements Interface. UPnP maps
+// requested external port or fails
+func (u *UPnP) AddMapping(
+	protocol string,
+	extPort int,
+	intPort int,
+	desc string,
+	lifetime time.Duration,
+) (
+	mapped int,
+	err error,
+) {
+
+	var args = fmt.Sprintf("<NewRemoteHost></NewRemoteHost>"+
+		"<NewExternalPort>%d</NewExternalPort>"+
+		"<NewProtocol>%s</NewProtocol>"+
+		"<NewInternalPort>%d</NewInternalPort>"+
+		"<NewInternalClient>%s</NewInternalClient>"+
+		"<NewEnabled>1</NewEnabled>"+
+		"<NewPortMappingDescription>%s</NewPortMappingDescription>"+
+		"<NewLeaseDuration>%d</NewLeaseDuration>",
+		extPort, strings.ToUpper(protocol), intPort, u.local,
+		xmlEscape(desc), int(lifetime/time.Second))
+
+	if err = u.action("AddPortMapping", args, nil); err != nil {
+		return
+	}
+
+	return extPort, nil
+}
+
+// DeleteMapping implements Interface
+func (u *UPnP) DeleteMapping(protocol string, extPort, _ int) (err error) {
+
+	var args = fmt.Sprintf("<NewRemoteHost></NewRemoteHost>"+
+		"<NewExternalPort>%d</NewExternalPort>"+
+		"<NewProtocol>%s</NewProtocol>",
+		extPort, strings.ToUpper(protocol))
+
+	return u.action("DeletePortMapping", args, nil)
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -1,6 +1,8 @@
 package node
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/skycoin/net/factory"
 	discovery "github.com/skycoin/net/skycoin-messenger/factory"
 
+	"github.com/skycoin/cxo/node/dht"
 	"github.com/skycoin/cxo/node/log"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
@@ -31,6 +34,7 @@ type Node struct {
 	c          *skyobject.Container  // related Container
 
 	idpk cipher.PubKey // id.PublicKey (string -> pk)
+	idsk cipher.SecKey // id.SecKey (string -> sk)
 
 	//
 	// feeds and connections
@@ -40,6 +44,15 @@ type Node struct {
 	ic map[cipher.PubKey]*Conn // node id (pk) -> connection
 	pc map[*Conn]struct{}      // pending connections
 
+	tn *tenants     // tenants
+	lf *localFeeds  // local-only feeds
+	hf *headerFeeds // headers-only feeds
+	ff *feedFilters // schema filters of feeds
+	gs *gossipSeen  // recent announcements
+	rs *rootsSeen   // recently received Root objects
+	rl *relayRoots  // sealed Root objects of relayed feeds
+	fo *feedObjects // objects of last Root objects of feeds
+
 	//
 	// transports
 	//
@@ -47,6 +60,12 @@ type Node struct {
 	// listen and connect
 	tcp *TCP
 	udp *UDP
+	ws  *WS
+	kcp *KCP
+
+	ls []*listener // additional listeners (see Config.Listen)
+
+	ts map[string]*customTransport // (see Config.Transports)
 
 	//
 	// other
@@ -61,6 +80,48 @@ type Node struct {
 	//
 
 	fillavg *statutil.Duration // filling average
+	se      *syncEvents        // sync events or nil
+
+	//
+	// port mapping
+	//
+
+	pm portMappings // see Config.PortMapping
+
+	//
+	// Tor
+	//
+
+	onion *onionService // or nil (see Config.Tor)
+
+	//
+	// DHT
+	//
+
+	dht  *dht.DHT      // or nil (see Config.DHT)
+	dhtq chan struct{} // trigger announcements
+
+	//
+	// rate limits (see rate_limit.go)
+	//
+
+	bw  bandwidth // global
+	prl RateLimit // of new connections
+
+	//
+	// reconnections (see reconnect.go)
+	//
+
+	kp *knownPeers
+
+	rep *reputation // scores and bans of peers
+
+	//
+	// cluster (see cluster.go)
+	//
+
+	hl          *heldLeases // leases of the Node
+	delRootHook func()      // removes clusterRootHook or nil
 
 	//
 	// rpc
@@ -68,6 +129,12 @@ type Node struct {
 
 	rpc *rpcServer
 
+	//
+	// gateway
+	//
+
+	gw *gatewayServer
+
 	//
 	//  closing
 	//
@@ -79,7 +146,8 @@ type Node struct {
 
 // NewNode creates new Node instance using provided
 // Config. If the Config is nil, then default is used
-// (see NewConfig for defaults)
+// (see NewConfig for defaults). Members of a Cluster
+// must be created by the NewNodeContainer
 func NewNode(conf *Config) (n *Node, err error) {
 
 	if conf == nil {
@@ -94,6 +162,10 @@ func NewNode(conf *Config) (n *Node, err error) {
 		return // invalid
 	}
 
+	if conf.Cluster != nil {
+		return nil, ErrClusterContainer
+	}
+
 	var c *skyobject.Container
 
 	if c, err = skyobject.NewContainer(conf.Config); err != nil {
@@ -125,17 +197,42 @@ func NewNodeContainer(
 
 	n.id = discovery.NewSeedConfig()
 	n.idpk, _ = cipher.PubKeyFromHex(n.id.PublicKey)
+	n.idsk, _ = cipher.SecKeyFromHex(n.id.SecKey)
 	n.c = c
 	n.fs = newNodeFeeds(n)
 	n.ic = make(map[cipher.PubKey]*Conn)
 	n.pc = make(map[*Conn]struct{})
+	n.tn = newTenants()
+	n.lf = newLocalFeeds()
+	n.rs = newRootsSeen()
+	n.rl = newRelayRoots()
+	n.fo = newFeedObjects()
+	n.hf = newHeaderFeeds()
+	n.ff = newFeedFilters()
+	n.gs = newGossipSeen()
+	n.kp = newKnownPeers()
+	n.rep = newReputation()
+	n.hl = newHeldLeases()
+
+	n.ts = make(map[string]*customTransport, len(conf.Transports))
+	for scheme, t := range conf.Transports {
+		n.ts[scheme] = newCustomTransport(n, scheme, t)
+	}
 
 	n.config = conf
 	n.config.Config = c.Config() // actual
 
 	n.fillavg = statutil.NewDuration(conf.Config.RollAvgSamples)
+	n.bw.set(conf.RateLimit)
+	n.prl = conf.PeerRateLimit
 	n.closeq = make(chan struct{})
 
+	c.AddDelHook(n.tn.delObject) // stored volume of tenants
+
+	if conf.Cluster != nil {
+		n.delRootHook = c.AddRootHook(n.clusterRootHook)
+	}
+
 	//
 	// create
 	//
@@ -144,6 +241,17 @@ func NewNodeContainer(
 
 	n.Logger = log.NewLogger(conf.Logger) // logger
 
+	// reputation
+
+	n.await.Add(1)
+	go n.pruneReputation()
+
+	// seeds (before joining)
+
+	for _, url := range conf.Seeds {
+		n.seed(url)
+	}
+
 	// listen
 
 	if conf.TCP.Listen != "" {
@@ -160,6 +268,90 @@ func NewNodeContainer(
 		}
 	}
 
+	if conf.WSAddress != "" {
+		if err = n.WS().Listen(conf.WSAddress); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	if conf.KCPAddress != "" {
+		if err = n.KCP().Listen(conf.KCPAddress); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	// dual-stack (after the TCP and the WS)
+
+	if conf.TCP.Listen != "" {
+		n.listenDualStack("tcp", conf.TCP.Listen)
+	}
+
+	if conf.WSAddress != "" {
+		n.listenDualStack("ws", conf.WSAddress)
+	}
+
+	for _, address := range conf.Listen {
+		if err = n.listen(address); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	// DNS seeds (after listening)
+
+	if len(conf.DNSSeeds) > 0 {
+		go n.dnsSeeds() // like discovery, not tracked by the await
+	}
+
+	// onion service (after listening)
+
+	if conf.Tor.Control != "" {
+		if err = n.publishOnion(); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	// port mapping (after listening)
+
+	if conf.PortMapping == true {
+		n.await.Add(1)
+		go n.mapPorts()
+	}
+
+	// known peers (after listening)
+
+	if conf.KnownPeers != "" {
+		if err = n.loadKnownPeers(); err != nil {
+			n.Close()
+			return
+		}
+	}
+
+	// DHT (after listening)
+
+	if conf.DHT.Listen != "" {
+		if n.dht, err = dht.New(n.idpk, conf.DHT); err != nil {
+			n.Close()
+			return
+		}
+		n.dhtq = make(chan struct{}, 1)
+		go n.runDHT() // like DNS seeds, not tracked by the await
+	}
+
+	// sync events
+
+	if conf.SyncEvents > 0 {
+		n.se = newSyncEvents(conf.SyncEvents)
+		n.await.Add(1)
+		go func() {
+			defer n.await.Done()
+			n.se.run(n.closeq)
+		}()
+	}
+
 	// rpc
 
 	if conf.RPC != "" {
@@ -173,6 +365,19 @@ func NewNodeContainer(
 
 	}
 
+	// gateway
+
+	if conf.Gateway != "" {
+
+		n.gw = n.newGateway()
+
+		if err = n.gw.Listen(conf.Gateway); err != nil {
+			n.Close()
+			return
+		}
+
+	}
+
 	// discoveries
 
 	for _, address := range conf.TCP.Discovery {
@@ -204,7 +409,9 @@ func (n *Node) Config() (conf *Config) {
 	return n.config // copy
 }
 
-// Container returns related Container instance
+// Container returns related Container instance.
+// Feeds of a Tenant with its own storage are kept
+// in Container of the Tenant (see TenantContainer)
 func (n *Node) Container() (c *skyobject.Container) {
 	return n.c
 }
@@ -218,7 +425,7 @@ func (n *Node) Container() (c *skyobject.Container) {
 // And don't call the publish for Root objects that
 // alredy saved (that saved before subscription)
 func (n *Node) Publish(r *registry.Root) {
-	n.fs.broadcastRoot(connRoot{nil, r})
+	n.fs.broadcastRoot(connRoot{r: r, delta: n.rootDelta(r)})
 }
 
 // ConnectionsOfFeed returns list of connections of given
@@ -285,6 +492,63 @@ func (n *Node) UDP() (tcp *UDP) {
 	return n.udp
 }
 
+// WS returns WebSocket transport of the Node
+func (n *Node) WS() (w *WS) {
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.createWS()
+
+	return n.ws
+}
+
+// KCP returns KCP transport of the Node
+func (n *Node) KCP() (k *KCP) {
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.createKCP()
+
+	return n.kcp
+}
+
+// Connect to given address choosing transport by
+// scheme of the address: tcp://, udp://, ws://,
+// kcp:// or unix:// (path of Unix socket, see
+// Config.Listen), or scheme of a custom transport
+// (see Config.Transports). An address without
+// scheme is TCP address.
+// For example, kcp://127.0.0.1:8870
+func (n *Node) Connect(address string) (c *Conn, err error) {
+
+	var scheme, hostPort = "tcp", address
+
+	if i := strings.Index(address, "://"); i >= 0 {
+		scheme, hostPort = address[:i], address[i+3:]
+	}
+
+	switch scheme {
+	case "tcp":
+		return n.TCP().Connect(hostPort)
+	case "udp":
+		return n.UDP().Connect(hostPort)
+	case "ws":
+		return n.WS().Connect(address) // URL
+	case "kcp":
+		return n.KCP().Connect(hostPort)
+	case "unix":
+		return n.TCP().connectUnix(hostPort) // path
+	}
+
+	if ct, ok := n.ts[scheme]; ok == true {
+		return ct.Connect(hostPort)
+	}
+
+	return nil, fmt.Errorf("unknown scheme of address %q", address)
+}
+
 // add to pending
 func (n *Node) addPendingConn(c *Conn) {
 	n.mx.Lock()
@@ -293,9 +557,49 @@ func (n *Node) addPendingConn(c *Conn) {
 	n.pc[c] = struct{}{}
 }
 
+// is the peer allowed (see Config.AllowPeer); a peer
+// that is not authenticated can claim any public key,
+// thus it's not allowed if there is the AllowPeer
+func (n *Node) allowPeer(c *Conn) bool {
+
+	var ap = n.config.AllowPeer
+
+	if ap == nil {
+		return true
+	}
+
+	if c.IsAuthenticated() == false {
+		return false
+	}
+
+	return ap(c.peerID, dialAddress(c))
+}
+
 // without lock
 func (n *Node) addConnection(c *Conn) (err error) {
 
+	c.setReputationKey()
+
+	if n.rep.isBanned(c.repKey) == true {
+		return ErrBanned
+	}
+
+	if n.allowPeer(c) == false {
+		return ErrNotAllowed
+	}
+
+	// connection limits (see peer_limits.go)
+	var victim *Conn
+	if victim, err = n.checkLimits(c); err != nil {
+		return
+	}
+
+	if victim != nil {
+		n.Debugf(ConnPin, "[%s] evict (score %d)", victim.String(),
+			victim.Score())
+		victim.close(ErrEvicted)
+	}
+
 	n.mx.Lock()
 	defer n.mx.Unlock()
 
@@ -303,6 +607,7 @@ func (n *Node) addConnection(c *Conn) (err error) {
 		return ErrAlreadyHaveConnection
 	}
 
+	c.established = time.Now()
 	n.ic[c.peerID] = c                   // add
 	delete(n.pc, c)                      // remove from pending
 	n.fs.addConnFeed(c, cipher.PubKey{}) // add to blank feed
@@ -316,6 +621,7 @@ func (n *Node) delConnection(c *Conn) {
 
 	delete(n.ic, c.peerID)
 	n.fs.delConn(c)
+	n.rep.forget(c.repKey)
 }
 
 // call under lock of the mx
@@ -340,6 +646,28 @@ func (n *Node) createUDP() {
 
 }
 
+// call under lock of the mx
+func (n *Node) createWS() {
+
+	if n.ws != nil {
+		return // already created
+	}
+
+	n.ws = newWS(n)
+
+}
+
+// call under lock of the mx
+func (n *Node) createKCP() {
+
+	if n.kcp != nil {
+		return // already created
+	}
+
+	n.kcp = newKCP(n)
+
+}
+
 func (n *Node) onConnect(c *Conn) {
 
 	if occ := n.config.OnConnect; occ != nil {
@@ -371,13 +699,20 @@ func (n *Node) onDisconenct(c *Conn, reason error) {
 
 func (n *Node) acceptConnection(fc *factory.Connection) {
 
+	if ip := hostIP(fc.GetRemoteAddr().String()); n.rep.isBanned(ip) == true {
+		n.Debugf(NewInConnPin, "[%s] reject banned",
+			connString(true, network(fc), fc.GetRemoteAddr().String()))
+		fc.Close()
+		return
+	}
+
 	n.Debugf(NewInConnPin, "[%s] accept",
-		connString(true, fc.IsTCP(), fc.GetRemoteAddr().String()))
+		connString(true, network(fc), fc.GetRemoteAddr().String()))
 
 	if _, err := n.wrapConnection(fc, true); err != nil {
 
 		n.Printf("[ERR] [%s] handshake error: %v",
-			connString(true, fc.IsTCP(), fc.GetRemoteAddr().String()),
+			connString(true, network(fc), fc.GetRemoteAddr().String()),
 			err)
 
 	}
@@ -405,7 +740,7 @@ func (n *Node) wrapConnection(
 ) {
 
 	n.Debugf(ConnHskPin, "[%s] wrapConnection",
-		connString(isIncoming, fc.IsTCP(), fc.GetRemoteAddr().String()))
+		connString(isIncoming, network(fc), fc.GetRemoteAddr().String()))
 
 	c = n.newConnection(fc, isIncoming) // adds to pending
 
@@ -424,14 +759,24 @@ func (n *Node) wrapConnection(
 
 	// add to transport if the connection is incoming
 	if isIncoming == true {
-		if c.IsTCP() == true {
+		switch network(fc) {
+		case "tcp":
 			n.TCP().addAcceptedConnection(c)
-		} else {
+		case "ws":
+			n.WS().addAcceptedConnection(c)
+		case "kcp":
+			n.KCP().addAcceptedConnection(c)
+		case "unix":
+			// remote address of accepted Unix socket is blank
+		case "udp":
 			n.UDP().addAcceptedConnection(c)
+		default:
+			n.ts[network(fc)].addAcceptedConnection(c)
 		}
 	}
 
 	c.run()
+	c.sendWantFeeds(n.Feeds())
 	n.onConnect(c)
 
 	return
@@ -440,11 +785,16 @@ func (n *Node) wrapConnection(
 
 func (n *Node) updateServiceDiscovery() {
 
+	// don't hold the mx calling methods of the TCP and
+	// the UDP, since their Connect methods lock the mx
+	// (adding new connection) under their own locks
+
 	n.mx.Lock()
-	defer n.mx.Unlock()
+	var tcp, udp = n.tcp, n.udp
+	n.mx.Unlock()
 
-	var notUsed = (n.tcp == nil || n.tcp.Discovery() == nil) &&
-		(n.udp == nil || n.udp.Discovery() == nil)
+	var notUsed = (tcp == nil || tcp.Discovery() == nil) &&
+		(udp == nil || udp.Discovery() == nil)
 
 	if notUsed == true {
 		return
@@ -454,12 +804,12 @@ func (n *Node) updateServiceDiscovery() {
 
 	n.Debug(DiscoveryPin, "(Node) updateServiceDiscovery ", len(feeds))
 
-	if n.tcp != nil && n.tcp.Discovery() != nil {
-		n.tcp.updateServiceDiscovery(feeds)
+	if tcp != nil && tcp.Discovery() != nil {
+		tcp.updateServiceDiscovery(feeds)
 	}
 
-	if n.udp != nil && n.udp.Discovery() != nil {
-		n.udp.updateServiceDiscovery(feeds)
+	if udp != nil && udp.Discovery() != nil {
+		udp.updateServiceDiscovery(feeds)
 	}
 
 }
@@ -479,15 +829,20 @@ func (n *Node) updateServiceDiscovery() {
 // method. The method never return an error if given
 // feed is already shared. The share never associate
 // the feed with a connection. You should to call
-// (*Conn).Subscribe to do that.
+// (*Conn).Subscribe to do that. The Share returns
+// ErrLocalFeed if given feed is local-only (see KeepLocal)
 func (n *Node) Share(feed cipher.PubKey) (err error) {
 
 	if feed == (cipher.PubKey{}) {
 		return ErrBlankFeed
 	}
 
+	if n.lf.has(feed) == true {
+		return ErrLocalFeed
+	}
+
 	// add to the Container
-	if err = n.c.AddFeed(feed); err != nil {
+	if err = n.container(feed).AddFeed(feed); err != nil {
 		return
 	}
 
@@ -500,6 +855,8 @@ func (n *Node) Share(feed cipher.PubKey) (err error) {
 
 	if n.fs.addFeed(feed) == true {
 		n.updateServiceDiscovery()
+		n.announceFeeds()
+		n.triggerDHT()
 	}
 
 	return
@@ -515,13 +872,22 @@ func (n *Node) Share(feed cipher.PubKey) (err error) {
 func (n *Node) DontShare(feed cipher.PubKey) (err error) {
 
 	n.fs.delFeed(feed)
+	n.tn.delFeed(feed)
+	n.rl.del(feed)
+	n.releaseLeases(feed)
 	n.updateServiceDiscovery()
+	n.announceFeeds()
 
 	return
 }
 
 func (n *Node) onSubscribeRemote(c *Conn, feed cipher.PubKey) (reject error) {
 
+	// ACL of a tenant first
+	if reject = n.tn.allowSubscription(c.PeerID(), feed); reject != nil {
+		return
+	}
+
 	if osr := n.config.OnSubscribeRemote; osr != nil {
 		reject = osr(c, feed)
 	}
@@ -600,17 +966,19 @@ func (n *Node) Stat() (s *Stat) {
 }
 
 // Close the Node. The Close returns error
-// of (skyobject.Container).Close once.
+// of (skyobject.Container).Close once. A
+// member of a Cluster doesn't close the
+// shared Container (see Cluster)
 func (n *Node) Close() (err error) {
 	n.closeo.Do(func() {
 
+		n.releaseLeases(n.Feeds()...)
+
 		close(n.closeq)
 
 		n.mx.Lock()
 		defer n.mx.Unlock()
 
-		err = n.c.Close()
-
 		if n.tcp != nil {
 			n.tcp.Close()
 		}
@@ -619,12 +987,53 @@ func (n *Node) Close() (err error) {
 			n.udp.Close()
 		}
 
+		if n.ws != nil {
+			n.ws.Close()
+		}
+
+		if n.kcp != nil {
+			n.kcp.Close()
+		}
+
+		for _, l := range n.ls {
+			l.close()
+		}
+
+		for _, ct := range n.ts {
+			ct.Close()
+		}
+
 		if n.rpc != nil {
 			n.rpc.Close()
 		}
 
+		if n.gw != nil {
+			n.gw.Close()
+		}
+
+		if n.onion != nil {
+			n.onion.ctrl.Close()
+		}
+
+		if n.dht != nil {
+			n.dht.Close()
+		}
+
 		n.await.Wait()
 
+		n.fs.close() // stop fillers that write to the Container
+
+		// the Container is shared by members of a Cluster
+		if n.delRootHook != nil {
+			n.delRootHook()
+		} else {
+			err = n.c.Close() // and close it
+		}
+
+		if terr := n.tn.close(); err == nil {
+			err = terr // Containers of Tenants
+		}
+
 	})
 
 	return
@@ -1,6 +1,8 @@
 package node
 
 import (
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -503,3 +505,54 @@ func TestNode_Close(t *testing.T) {
 	// TODO (kostyarin): the lowest priority
 
 }
+
+func TestConfig_AllowPeer(t *testing.T) {
+
+	var (
+		a = getTestNodeNotListen("a")
+		b = getTestNodeNotListen("b")
+
+		conf = getTestConfig("server")
+
+		mx      sync.Mutex
+		address string
+	)
+	defer a.Close()
+	defer b.Close()
+
+	conf.UDP.Listen = ""
+	conf.AllowPeer = func(pk cipher.PubKey, addr string) bool {
+		mx.Lock()
+		defer mx.Unlock()
+		address = addr
+		return pk == a.ID() // allowlist
+	}
+
+	var s, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if _, err = a.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, waitPeers(s, 1) == 1, "not connected")
+
+	mx.Lock()
+	assertTrue(t, strings.HasPrefix(address, "tcp://") == true,
+		"wrong address: "+address)
+	mx.Unlock()
+
+	b.Connect(conf.TCP.Listen)
+
+	assertTrue(t, waitPeers(s, 1) == 1, "wrong number of peers")
+	_, ok := s.hasPeer(b.ID())
+	assertTrue(t, ok == false, "not allowed peer connected")
+
+	// a peer that is not authenticated can claim the key
+	var c = &Conn{n: s, peerID: a.ID()}
+	assertTrue(t, s.allowPeer(c) == false, "not authenticated peer allowed")
+
+}
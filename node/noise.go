@@ -0,0 +1,451 @@
+package node
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	sky "github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// Noise_XX handshake (see msg.FeatureNoise); the Noise
+// uses secp256k1 keys of the nodes (see Node.ID),
+// thus the DH is the cipher.ECDH, and the public
+// keys are 33 bytes long
+//
+//     -> e
+//     <- e, ee, s, es
+//     -> s, se
+//     <- Ok
+//
+// since the prologue contains the Syn and the Ack
+// as they have been sent, the Noise protects them
+// too (version, features, node IDs, compression)
+
+// name of the protocol, exactly 32 bytes
+const noiseProtocolName = "Noise_XX_secp256k1_AESGCM_SHA256"
+
+// size of AES-GCM tag
+const noiseTagSize = 16
+
+// a Noise key pair
+type noiseKeys struct {
+	pk sky.PubKey
+	sk sky.SecKey
+}
+
+// state of Noise_XX handshake of one side
+type noiseState struct {
+	initiator bool
+	step      int // number of messages processed
+
+	ck [32]byte // chaining key
+	h  [32]byte // handshake hash
+
+	k cipher.AEAD // key or nil
+	n uint64      // nonce
+
+	s, e   noiseKeys  // local static and ephemeral keys
+	rs, re sky.PubKey // remote static and ephemeral keys
+}
+
+func newNoiseState(
+	initiator bool, //  : initiator of the handshake
+	s noiseKeys, //     : static keys
+	prologue []byte, // : data both sides have
+) (
+	ns *noiseState, //  : the state
+) {
+
+	ns = new(noiseState)
+	ns.initiator = initiator
+	ns.s = s
+
+	copy(ns.h[:], noiseProtocolName)
+	ns.ck = ns.h
+
+	ns.mixHash(prologue)
+	return
+}
+
+// HKDF with HMAC-SHA256 producing two outputs
+func noiseHKDF(ck []byte, ikm []byte) (out1, out2 [32]byte) {
+
+	var mac = hmac.New(sha256.New, ck)
+	mac.Write(ikm)
+	var temp = mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, temp)
+	mac.Write([]byte{0x01})
+	copy(out1[:], mac.Sum(nil))
+
+	mac = hmac.New(sha256.New, temp)
+	mac.Write(out1[:])
+	mac.Write([]byte{0x02})
+	copy(out2[:], mac.Sum(nil))
+
+	return
+}
+
+func (ns *noiseState) mixHash(data []byte) {
+	var h = sha256.New()
+	h.Write(ns.h[:])
+	h.Write(data)
+	copy(ns.h[:], h.Sum(nil))
+}
+
+func (ns *noiseState) mixKey(ikm []byte) {
+
+	var temp [32]byte
+	ns.ck, temp = noiseHKDF(ns.ck[:], ikm)
+
+	var block, _ = aes.NewCipher(temp[:]) // can't fail
+	ns.k, _ = cipher.NewGCM(block)
+	ns.n = 0
+}
+
+// the nonce is 4 zero bytes and big-endian n
+func (ns *noiseState) nonce() (nonce []byte) {
+	nonce = make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], ns.n)
+	ns.n++
+	return
+}
+
+func (ns *noiseState) encryptAndHash(p []byte) (c []byte) {
+
+	if ns.k == nil {
+		c = p
+	} else {
+		c = ns.k.Seal(nil, ns.nonce(), p, ns.h[:])
+	}
+
+	ns.mixHash(c)
+	return
+}
+
+func (ns *noiseState) decryptAndHash(c []byte) (p []byte, err error) {
+
+	if ns.k == nil {
+		p = c
+	} else if p, err = ns.k.Open(nil, ns.nonce(), c, ns.h[:]); err != nil {
+		return nil, ErrNoiseAuth
+	}
+
+	ns.mixHash(c)
+	return
+}
+
+// DH of local secret key and remote public key
+func noiseDH(sk sky.SecKey, pk sky.PubKey) []byte {
+	return sky.ECDH(pk, sk)
+}
+
+// read public key from given message
+func (ns *noiseState) readKey(p []byte, size int) (
+	pk sky.PubKey,
+	rest []byte,
+	err error,
+) {
+
+	if len(p) < size {
+		return pk, nil, ErrNoiseAuth
+	}
+
+	var key []byte
+	if key, err = ns.decryptAndHash(p[:size]); err != nil {
+		return
+	}
+
+	copy(pk[:], key)
+
+	if pk.Verify() != nil {
+		return pk, nil, ErrNoiseAuth
+	}
+
+	return pk, p[size:], nil
+}
+
+// is it turn of this side to write message
+func (ns *noiseState) isWriting() bool {
+	return (ns.step%2 == 0) == ns.initiator
+}
+
+// is the handshake done
+func (ns *noiseState) isDone() bool {
+	return ns.step == 3
+}
+
+// create next handshake message
+func (ns *noiseState) writeMessage() (p []byte) {
+
+	switch ns.step {
+
+	case 0: // -> e
+
+		ns.e.pk, ns.e.sk = sky.GenerateKeyPair()
+		p = append(p, ns.e.pk[:]...)
+		ns.mixHash(ns.e.pk[:])
+
+	case 1: // <- e, ee, s, es
+
+		ns.e.pk, ns.e.sk = sky.GenerateKeyPair()
+		p = append(p, ns.e.pk[:]...)
+		ns.mixHash(ns.e.pk[:])
+
+		ns.mixKey(noiseDH(ns.e.sk, ns.re))
+		p = append(p, ns.encryptAndHash(ns.s.pk[:])...)
+		ns.mixKey(noiseDH(ns.s.sk, ns.re))
+
+	case 2: // -> s, se
+
+		p = append(p, ns.encryptAndHash(ns.s.pk[:])...)
+		ns.mixKey(noiseDH(ns.s.sk, ns.re))
+
+	}
+
+	p = append(p, ns.encryptAndHash(nil)...) // empty payload
+	ns.step++
+	return
+}
+
+// process received handshake message
+func (ns *noiseState) readMessage(p []byte) (err error) {
+
+	switch ns.step {
+
+	case 0: // -> e
+
+		if ns.re, p, err = ns.readKey(p, len(sky.PubKey{})); err != nil {
+			return
+		}
+
+	case 1: // <- e, ee, s, es
+
+		if ns.re, p, err = ns.readKey(p, len(sky.PubKey{})); err != nil {
+			return
+		}
+
+		ns.mixKey(noiseDH(ns.e.sk, ns.re))
+
+		if ns.rs, p, err = ns.readKey(p,
+			len(sky.PubKey{})+noiseTagSize); err != nil {
+
+			return
+		}
+
+		ns.mixKey(noiseDH(ns.e.sk, ns.rs))
+
+	case 2: // -> s, se
+
+		if ns.rs, p, err = ns.readKey(p,
+			len(sky.PubKey{})+noiseTagSize); err != nil {
+
+			return
+		}
+
+		ns.mixKey(noiseDH(ns.e.sk, ns.rs))
+
+	}
+
+	var payload []byte
+	if payload, err = ns.decryptAndHash(p); err != nil {
+		return
+	}
+
+	if len(payload) != 0 {
+		return ErrNoiseAuth // no payload expected
+	}
+
+	ns.step++
+	return
+}
+
+// session keys after the handshake
+func (ns *noiseState) split() (send, recv *[32]byte) {
+
+	var c1, c2 = noiseHKDF(ns.ck[:], nil)
+
+	if ns.initiator == true {
+		return &c1, &c2
+	}
+
+	return &c2, &c1
+}
+
+// perform the Noise_XX after the Syn and the Ack,
+// if both peers have the FeatureNoise; the Noise
+// verifies the peerID and replaces shared key with
+// session keys (see encryption.go); if the peer
+// doesn't support the Noise, then the connection
+// is rejected if the Node requires it (see
+// Config.RequireNoise)
+func (c *Conn) noiseHandshake(
+	syn []byte, //                 : encoded Syn
+	ack []byte, //                 : encoded Ack
+	nodeCloseq <-chan struct{}, // : closing
+) (
+	err error, //                  : an error
+) {
+
+	if c.HasFeature(msg.FeatureNoise) == false {
+		if c.n.config.RequireNoise == true {
+			err = ErrNoiseRequired
+			// write it directly, since the
+			// connection is closed just after
+			c.Connection.Write(
+				c.encodeMsg(c.nextSeq(), 0, &msg.Err{Err: err.Error()}),
+			)
+		}
+		return
+	}
+
+	c.n.Debugf(ConnHskPin, "[%s] noiseHandshake", c.String())
+
+	var prologue = noisePrologue(syn, ack)
+
+	var ns = newNoiseState(c.incoming == false,
+		noiseKeys{c.n.idpk, c.n.idsk}, prologue)
+
+	for ns.isDone() == false {
+
+		if ns.isWriting() == true {
+			err = c.sendNodeCloseq(
+				c.encodeMsg(c.nextSeq(), 0, &msg.Noise{
+					Payload: ns.writeMessage(),
+				}),
+				nodeCloseq,
+			)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		var m msg.Msg
+		if m, err = c.receiveNoise(msg.NoiseType, nodeCloseq); err != nil {
+			return
+		}
+
+		if err = ns.readMessage(m.(*msg.Noise).Payload); err == nil && ns.rs != (sky.PubKey{}) &&
+			ns.rs != c.peerID {
+
+			err = ErrNoiseAuth // not the key of the Syn or Ack
+		}
+
+		if err != nil {
+			// write it directly, since the
+			// connection is closed just after
+			c.Connection.Write(
+				c.encodeMsg(c.nextSeq(), 0, &msg.Err{Err: err.Error()}),
+			)
+			return
+		}
+
+	}
+
+	// the responder confirms the handshake, thus the
+	// initiator returns after the peer has accepted it
+	if c.incoming == true {
+		err = c.sendNodeCloseq(c.encodeMsg(c.nextSeq(), 0, &msg.Ok{}),
+			nodeCloseq)
+	} else {
+		_, err = c.receiveNoise(msg.OkType, nodeCloseq)
+	}
+
+	if err != nil {
+		return
+	}
+
+	c.key, c.rkey = ns.split()
+	c.authenticated = true
+
+	return
+}
+
+// the prologue is the Syn and the Ack exactly as they
+// have been sent; the Syn is prefixed with its length
+func noisePrologue(syn, ack []byte) (prologue []byte) {
+	prologue = make([]byte, 4, 4+len(syn)+len(ack))
+	binary.LittleEndian.PutUint32(prologue, uint32(len(syn)))
+	prologue = append(prologue, syn...)
+	return append(prologue, ack...)
+}
+
+// receive next message of the Noise handshake,
+// an Err received is returned as error
+func (c *Conn) receiveNoise(
+	want msg.Type, //              : expected type
+	nodeCloseq <-chan struct{}, // : closing
+) (
+	m msg.Msg, //                  : received message
+	err error, //                  : an error
+) {
+
+	var tc <-chan time.Time
+
+	if rt := c.responseTimeout(); rt > 0 {
+		var tm = time.NewTimer(rt)
+		tc = tm.C
+
+		defer tm.Stop()
+	}
+
+	var raw []byte
+
+	select {
+
+	case r, ok := <-c.GetChanIn():
+
+		if ok == false {
+			return nil, ErrClosed
+		}
+
+		raw = r
+
+	case <-tc:
+
+		return nil, ErrTimeout
+
+	case <-nodeCloseq:
+
+		return nil, ErrClosed
+
+	}
+
+	var fr msg.Frame
+	if fr, _, err = c.unframe(raw); err != nil {
+		return
+	}
+
+	if _, _, m, err = c.decodeRaw(fr.Body); err != nil {
+		return
+	}
+
+	if x, ok := m.(*msg.Err); ok == true {
+		return nil, remoteError(x.Err)
+	}
+
+	if m.Type() != want {
+		return nil, fmt.Errorf("invalid message type for Noise handshake: %T",
+			m)
+	}
+
+	return
+}
+
+// IsAuthenticated returns true if the remote peer
+// has proved possession of secret key of its ID
+// (see PeerID) during the handshake. It's false
+// if the peer doesn't support the Noise handshake.
+// The IsAuthenticated can be used by the
+// Config.OnConnect callback to reject peers
+func (c *Conn) IsAuthenticated() bool {
+	return c.authenticated
+}
@@ -0,0 +1,194 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/skycoin/net/factory"
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func getTestNoiseStates() (i, r *noiseState) {
+
+	var (
+		ipk, isk = cipher.GenerateKeyPair()
+		rpk, rsk = cipher.GenerateKeyPair()
+
+		prologue = []byte("prologue")
+	)
+
+	i = newNoiseState(true, noiseKeys{ipk, isk}, prologue)
+	r = newNoiseState(false, noiseKeys{rpk, rsk}, prologue)
+	return
+}
+
+func TestNoiseState(t *testing.T) {
+
+	t.Run("handshake", func(t *testing.T) {
+
+		var i, r = getTestNoiseStates()
+
+		for i.isDone() == false {
+
+			var from, to = r, i
+			if i.isWriting() == true {
+				from, to = i, r
+			}
+
+			if err := to.readMessage(from.writeMessage()); err != nil {
+				t.Fatal(err)
+			}
+
+		}
+
+		if r.isDone() == false {
+			t.Fatal("responder is not done")
+		}
+
+		if i.rs != r.s.pk || r.rs != i.s.pk {
+			t.Error("wrong remote static keys")
+		}
+
+		var (
+			isend, irecv = i.split()
+			rsend, rrecv = r.split()
+		)
+
+		if *isend != *rrecv || *irecv != *rsend || *isend == *irecv {
+			t.Error("wrong session keys")
+		}
+
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+
+		var i, r = getTestNoiseStates()
+
+		assertNil(t, r.readMessage(i.writeMessage()))
+
+		var p = r.writeMessage()
+		p[len(p)-1] ^= 0xff
+
+		if err := i.readMessage(p); err != ErrNoiseAuth {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+	t.Run("prologue", func(t *testing.T) {
+
+		var i, r = getTestNoiseStates()
+		r.mixHash([]byte("another"))
+
+		assertNil(t, r.readMessage(i.writeMessage()))
+
+		if err := i.readMessage(r.writeMessage()); err != ErrNoiseAuth {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+}
+
+func TestConn_IsAuthenticated(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+
+		connected = make(chan *Conn, 1)
+	)
+
+	sconf.UDP.Listen = ""
+	sconf.OnConnect = func(c *Conn) (_ error) {
+		connected <- c
+		return
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.IsAuthenticated() == false || c.PeerID() != sn.ID() {
+		t.Error("server is not authenticated")
+	}
+
+	var sc = <-connected
+
+	if sc.IsAuthenticated() == false || sc.PeerID() != cn.ID() {
+		t.Error("client is not authenticated")
+	}
+
+	if *c.key != *sc.rkey || *c.rkey != *sc.key {
+		t.Error("wrong session keys")
+	}
+
+}
+
+func Test_noisePrologue(t *testing.T) {
+
+	var syn, ack = []byte("syn"), []byte("ack")
+
+	if bytes.Equal(noisePrologue(syn, ack), noisePrologue(ack, syn)) == true {
+		t.Error("the Syn and the Ack are not distinguished")
+	}
+
+	if bytes.Equal(noisePrologue([]byte("sy"), []byte("nack")),
+		noisePrologue(syn, ack)) == true {
+
+		t.Error("the Syn is not length prefixed")
+	}
+
+}
+
+func TestConfig_RequireNoise(t *testing.T) {
+
+	var conf = NewConfig()
+	conf.RequireNoise = true
+
+	var (
+		out = make(chan []byte, 1)
+		c   = &Conn{n: &Node{config: conf}}
+	)
+
+	c.Connection = &factory.Connection{Connection: &chanConn{out: out}}
+	c.features = 0 // an old peer
+
+	var err = c.noiseHandshake(nil, nil, nil)
+
+	if err != ErrNoiseRequired {
+		t.Fatalf("wrong error: want %v, got %v", ErrNoiseRequired, err)
+	}
+
+	var m msg.Msg
+	if _, _, m, err = c.decodeRaw(<-out); err != nil {
+		t.Fatal(err)
+	}
+
+	if x, ok := m.(*msg.Err); ok == false {
+		t.Errorf("wrong message: %T", m)
+	} else if errors.Is(remoteError(x.Err), ErrNoiseRequired) == false {
+		t.Errorf("wrong error sent: %s", x.Err)
+	}
+
+	if c.IsAuthenticated() == true {
+		t.Error("authenticated")
+	}
+
+}
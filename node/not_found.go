@@ -0,0 +1,53 @@
+package node
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/statutil"
+)
+
+// reply NotFound to request with given seq
+func (c *Conn) sendNotFound(rseq uint32, key cipher.SHA256) {
+	c.sendMsg(c.nextSeq(), rseq, &msg.NotFound{Key: key})
+}
+
+// reply NotFound if the peer supports it, or empty Err
+func (c *Conn) replyNotFound(rseq uint32, key cipher.SHA256) {
+	if c.HasFeature(msg.FeatureNotFound) == true {
+		c.sendNotFound(rseq, key)
+		return
+	}
+	c.sendMsg(c.nextSeq(), rseq, &msg.Err{})
+}
+
+// MissRate returns rolling average rate of object
+// requests to the peer that failed (NotFound or
+// timeout); it's zero if nothing has been requested
+// yet; the rate is in [0, 1] range
+func (c *Conn) MissRate() (rate float64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.miss == nil {
+		return
+	}
+
+	return c.miss.Value()
+}
+
+// add result of object request to the miss rate
+func (c *Conn) addRequestResult(missed bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.miss == nil {
+		c.miss = statutil.NewFloat(c.n.config.Config.RollAvgSamples)
+	}
+
+	if missed == true {
+		c.miss.Add(1)
+	} else {
+		c.miss.Add(0)
+	}
+}
@@ -0,0 +1,57 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_sendNotFound(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.MissRate() != 0 {
+		t.Error("unexpected miss rate")
+	}
+
+	var (
+		key   = cipher.SumSHA256([]byte("missing object"))
+		tp    = time.Now()
+		reply msg.Msg
+	)
+
+	// the server doesn't have the object and doesn't wait for it
+	if reply, err = c.sendFeedRequest(pk, &msg.RqObject{Key: key}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the response timeout is one second
+	if time.Now().Sub(tp) > 500*time.Millisecond {
+		t.Error("slow NotFound")
+	}
+
+	if nf, ok := reply.(*msg.NotFound); ok == false {
+		t.Fatalf("wrong reply type: %T", reply)
+	} else if nf.Key != key {
+		t.Error("wrong key of NotFound")
+	}
+
+	c.addRequestResult(true) // missed
+
+	if mr := c.MissRate(); mr != 1 {
+		t.Errorf("wrong miss rate: %v", mr)
+	}
+
+}
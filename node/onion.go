@@ -0,0 +1,120 @@
+package node
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/cxo/node/tor"
+)
+
+// an onion service of the Node (see Config.Tor)
+type onionService struct {
+	ctrl    *tor.Controller // Tor control connection
+	address string          // <service id>.onion:port
+}
+
+// is given host:port an .onion address
+func isOnion(address string) bool {
+	var host, _, err = net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(host), ".onion")
+}
+
+// read private key of onion service from
+// given file, or return tor.KeyNew
+func readOnionKey(keyFile string) (key string, err error) {
+
+	if keyFile == "" {
+		return tor.KeyNew, nil
+	}
+
+	var data []byte
+	if data, err = ioutil.ReadFile(keyFile); err != nil {
+		if os.IsNotExist(err) == true {
+			return tor.KeyNew, nil
+		}
+		return
+	}
+
+	if key = strings.TrimSpace(string(data)); key == "" {
+		return "", errors.New("empty key file of onion service " + keyFile)
+	}
+
+	return
+}
+
+// publish onion service for TCP listener
+// using Tor control port (see Config.Tor)
+func (n *Node) publishOnion() (err error) {
+
+	var conf = &n.config.Tor
+
+	var host, port string
+	if host, port, err = net.SplitHostPort(n.TCP().Address()); err != nil {
+		return
+	}
+
+	var vport int
+	if vport, err = strconv.Atoi(port); err != nil || vport == 0 {
+		return errors.New("onion service requires fixed TCP listening port")
+	}
+
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+
+	var key string
+	if key, err = readOnionKey(conf.KeyFile); err != nil {
+		return
+	}
+
+	var ctrl *tor.Controller
+	ctrl, err = tor.Dial(conf.Control, conf.Password,
+		n.config.TCP.ResponseTimeout)
+	if err != nil {
+		return
+	}
+
+	var id, pk string
+	if id, pk, err = ctrl.AddOnion(key, vport,
+		net.JoinHostPort(host, port)); err != nil {
+
+		ctrl.Close()
+		return
+	}
+
+	if pk != "" && conf.KeyFile != "" {
+		if err = ioutil.WriteFile(conf.KeyFile, []byte(pk+"\n"),
+			0600); err != nil {
+
+			ctrl.Close()
+			return
+		}
+	}
+
+	n.onion = &onionService{
+		ctrl:    ctrl,
+		address: net.JoinHostPort(id+".onion", port),
+	}
+
+	n.Debugf(ConnPin, "[tor] onion service %s", n.onion.address)
+	return
+}
+
+// OnionAddress returns address of onion service of
+// the Node, like xyz.onion:8870 (see Config.Tor).
+// Other nodes can connect to the address through
+// Tor. The OnionAddress returns blank string if
+// the onion service is not published
+func (n *Node) OnionAddress() string {
+	if n.onion == nil {
+		return ""
+	}
+	return n.onion.address
+}
@@ -0,0 +1,223 @@
+package node
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fake Tor with control port and SOCKS5 port, the
+// SOCKS5 connects testonion.onion to the target
+// of the last ADD_ONION
+type testTor struct {
+	control, socks net.Listener
+
+	mx     sync.Mutex
+	target string   // target of the onion service
+	cmds   []string // ADD_ONION commands
+}
+
+func runTestTor(t *testing.T) (tt *testTor) {
+
+	tt = new(testTor)
+
+	var err error
+	if tt.control, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if tt.socks, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+
+	go tt.accept(tt.control, tt.serveControl)
+	go tt.accept(tt.socks, tt.serveSOCKS)
+
+	return
+}
+
+func (tt *testTor) accept(l net.Listener, serve func(net.Conn)) {
+	for {
+		var c, err = l.Accept()
+		if err != nil {
+			return
+		}
+		go serve(c)
+	}
+}
+
+func (tt *testTor) serveControl(c net.Conn) {
+	defer c.Close()
+
+	var r = bufio.NewReader(c)
+
+	for {
+
+		var line, err = r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		var cmd = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case cmd == "PROTOCOLINFO 1":
+			io.WriteString(c, "250-PROTOCOLINFO 1\r\n"+
+				"250-AUTH METHODS=NULL\r\n250 OK\r\n")
+		case cmd == "AUTHENTICATE":
+			io.WriteString(c, "250 OK\r\n")
+		case strings.HasPrefix(cmd, "ADD_ONION "):
+			var fields = strings.Fields(cmd)
+			tt.mx.Lock()
+			tt.cmds = append(tt.cmds, cmd)
+			tt.target = fields[2][strings.Index(fields[2], ",")+1:]
+			tt.mx.Unlock()
+			io.WriteString(c, "250-ServiceID=testonion\r\n")
+			if fields[1] == "NEW:ED25519-V3" {
+				io.WriteString(c, "250-PrivateKey=ED25519-V3:dGVzdA==\r\n")
+			}
+			io.WriteString(c, "250 OK\r\n")
+		default:
+			io.WriteString(c, "510 Unrecognized command\r\n")
+		}
+
+	}
+}
+
+func (tt *testTor) serveSOCKS(c net.Conn) {
+	defer c.Close()
+
+	var hdr = make([]byte, 2)
+	if _, err := io.ReadFull(c, hdr); err != nil {
+		return
+	}
+	io.ReadFull(c, make([]byte, hdr[1])) // methods
+	c.Write([]byte{0x05, 0x00})          // no authentication
+
+	var rq = make([]byte, 5)
+	if _, err := io.ReadFull(c, rq); err != nil || rq[3] != 0x03 {
+		return // only domain names
+	}
+
+	var host = make([]byte, rq[4])
+	io.ReadFull(c, host)
+
+	var port = make([]byte, 2)
+	io.ReadFull(c, port)
+
+	tt.mx.Lock()
+	var target = tt.target
+	tt.mx.Unlock()
+
+	_, tport, _ := net.SplitHostPort(target)
+
+	if string(host) != "testonion.onion" ||
+		strconv.Itoa(int(binary.BigEndian.Uint16(port))) != tport {
+
+		c.Write([]byte{0x05, 0x04, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return
+	}
+
+	var tc, err = net.Dial("tcp", target)
+	if err != nil {
+		c.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return
+	}
+
+	c.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+	relay(c, tc)
+}
+
+func (tt *testTor) Close() {
+	tt.control.Close()
+	tt.socks.Close()
+}
+
+func TestNode_OnionAddress(t *testing.T) {
+
+	var tt = runTestTor(t)
+	defer tt.Close()
+
+	var dir, err = ioutil.TempDir("", "onion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var sconf = getTestConfig("server")
+	sconf.UDP.Listen = ""
+	sconf.Public = true
+	sconf.Tor.Control = tt.control.Addr().String()
+	sconf.Tor.KeyFile = filepath.Join(dir, "onion.key")
+
+	var sn *Node
+	if sn, err = NewNode(sconf); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, sn.OnionAddress() == "testonion.onion:8087",
+		"wrong onion address: "+sn.OnionAddress())
+
+	var key []byte
+	if key, err = ioutil.ReadFile(sconf.Tor.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	assertTrue(t, string(key) == "ED25519-V3:dGVzdA==\n", "wrong key saved")
+
+	// connect through Tor
+
+	var cconf = getTestConfigNotListen("client")
+	cconf.Tor.SOCKS = tt.socks.Addr().String()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.Connect("tcp://" + sn.OnionAddress()); err != nil {
+		t.Fatal(err)
+	}
+	assertTrue(t, c.PeerID() == sn.ID(), "wrong peer")
+
+	c.Close()
+	assertNil(t, sn.Close())
+
+	// restart with the same key
+
+	if sn, err = NewNode(sconf); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	tt.mx.Lock()
+	defer tt.mx.Unlock()
+
+	assertTrue(t, len(tt.cmds) == 2, "wrong number of ADD_ONION")
+	assertTrue(t, strings.HasPrefix(tt.cmds[1], "ADD_ONION ED25519-V3:dGVzdA== "),
+		"not the same key: "+tt.cmds[1])
+
+}
+
+func TestNode_onionWithoutTor(t *testing.T) {
+
+	var n = getTestNodeNotListen("client")
+	defer n.Close()
+
+	var _, err = n.Connect("testonion.onion:8870")
+	assertTrue(t, errors.Is(err, ErrNoTor), "wrong error")
+
+	var conf = getTestConfigNotListen("server")
+	conf.Tor.Control = "127.0.0.1:9051"
+	assertTrue(t, conf.Validate() != nil, "missing error")
+
+}
@@ -0,0 +1,166 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// hash to sign to prove ownership of the feed, the id
+// is ID of node that checks the proof (that sends the
+// challenge), thus a proof can't be reused with
+// another node
+func ownershipHash(nonce cipher.SHA256, feed, id cipher.PubKey) cipher.SHA256 {
+	var p = make([]byte, 0, len(nonce)+len(feed)+len(id))
+	p = append(p, nonce[:]...)
+	p = append(p, feed[:]...)
+	p = append(p, id[:]...)
+	return cipher.SumSHA256(p)
+}
+
+// ProveOwnership proves to remote peer that this node owns
+// given feed, e.g. the node has secret key of the feed. The
+// remote peer can apply publisher-specific policies (higher
+// priority, relaxed rate limits, etc) for owners. The secret
+// key is not sent, the ProveOwnership signs random challenge
+// of the remote peer instead. It returns ErrNotSupported
+// if the peer doesn't support the ownership proofs (see
+// msg.FeatureOwnership). See also SubscribeOwner
+func (c *Conn) ProveOwnership(
+	feed cipher.PubKey, // : the feed
+	sk cipher.SecKey, //   : secret key of the feed
+) (
+	err error, //          : an error
+) {
+
+	if cipher.PubKeyFromSecKey(sk) != feed {
+		return ErrInvalidSecretKey
+	}
+
+	if c.HasFeature(msg.FeatureOwnership) == false {
+		return ErrNotSupported
+	}
+
+	var reply msg.Msg
+	if reply, err = c.sendRequest(&msg.RqChallenge{Feed: feed}); err != nil {
+		return
+	}
+
+	var chl *msg.Challenge
+
+	switch x := reply.(type) {
+	case *msg.Challenge:
+		chl = x
+	case *msg.Err:
+		return remoteError(x.Err)
+	default:
+		return fmt.Errorf("invalid response type %T", reply)
+	}
+
+	var proof = &msg.Proof{
+		Feed: feed,
+		Sig: cipher.SignHash(
+			ownershipHash(chl.Nonce, feed, c.PeerID()),
+			sk,
+		),
+	}
+
+	if reply, err = c.sendRequest(proof); err != nil {
+		return
+	}
+
+	switch x := reply.(type) {
+	case *msg.Ok:
+	case *msg.Err:
+		err = remoteError(x.Err)
+	default:
+		err = fmt.Errorf("invalid response type %T", reply)
+	}
+
+	return
+}
+
+// SubscribeOwner proves ownership of given feed (see
+// ProveOwnership) and subscribes to the feed of remote
+// peer. Thus, the remote peer knows that the subscriber
+// is owner of the feed
+func (c *Conn) SubscribeOwner(
+	feed cipher.PubKey, // : the feed
+	sk cipher.SecKey, //   : secret key of the feed
+) (
+	err error, //          : an error
+) {
+
+	if err = c.ProveOwnership(feed, sk); err != nil {
+		return
+	}
+
+	return c.Subscribe(feed)
+}
+
+// IsOwner returns true if remote peer has proved
+// that it owns given feed (see ProveOwnership)
+func (c *Conn) IsOwner(feed cipher.PubKey) (ok bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	_, ok = c.own[feed]
+	return
+}
+
+func (c *Conn) handleRqChallenge(seq uint32, rq *msg.RqChallenge) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqChallenge %s", c.String(),
+		rq.Feed.Hex()[:7])
+
+	var nonce = cipher.SumSHA256(cipher.RandByte(32))
+
+	c.mx.Lock()
+	if c.chl == nil {
+		c.chl = make(map[cipher.PubKey]cipher.SHA256)
+	}
+	c.chl[rq.Feed] = nonce // replace previous, if any
+	c.mx.Unlock()
+
+	c.sendMsg(c.nextSeq(), seq, &msg.Challenge{Nonce: nonce})
+	return
+}
+
+func (c *Conn) handleProof(seq uint32, proof *msg.Proof) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleProof %s", c.String(),
+		proof.Feed.Hex()[:7])
+
+	c.mx.Lock()
+	var nonce, ok = c.chl[proof.Feed]
+	delete(c.chl, proof.Feed) // a challenge can be used once
+	c.mx.Unlock()
+
+	if ok == false {
+		c.sendErr(seq, ErrNoChallenge)
+		return
+	}
+
+	var hash = ownershipHash(nonce, proof.Feed, c.n.ID())
+
+	if err := cipher.VerifySignature(proof.Feed, proof.Sig, hash); err != nil {
+		c.sendErr(seq, ErrInvalidProof)
+		return
+	}
+
+	c.mx.Lock()
+	if c.own == nil {
+		c.own = make(map[cipher.PubKey]struct{})
+	}
+	c.own[proof.Feed] = struct{}{}
+	c.mx.Unlock()
+
+	if oop := c.n.config.OnOwnerProved; oop != nil {
+		oop(c, proof.Feed)
+	}
+
+	c.sendOk(seq)
+	return
+}
@@ -0,0 +1,76 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_ProveOwnership(t *testing.T) {
+
+	var (
+		sconf  = getTestConfig("server")
+		proved = make(chan cipher.PubKey, 1)
+	)
+
+	sconf.OnOwnerProved = func(_ *Conn, feed cipher.PubKey) {
+		proved <- feed
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		_, ak  = cipher.GenerateKeyPair() // another key
+	)
+
+	assertNil(t, sn.Share(pk))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, c.ProveOwnership(pk, ak) == ErrInvalidSecretKey,
+		"missing ErrInvalidSecretKey")
+
+	assertNil(t, c.SubscribeOwner(pk, sk))
+
+	select {
+	case feed := <-proved:
+		assertTrue(t, feed == pk, "wrong feed proved")
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+	var sc = sn.Connections()
+	if len(sc) != 1 {
+		t.Fatal("wrong number of connections", len(sc))
+	}
+
+	assertTrue(t, sc[0].IsOwner(pk), "not owner")
+	assertTrue(t, c.IsOwner(pk) == false, "unexpected owner")
+
+	// proof without a challenge
+
+	var reply msg.Msg
+	reply, err = c.sendRequest(&msg.Proof{Feed: pk})
+	assertNil(t, err)
+
+	if x, ok := reply.(*msg.Err); ok == false {
+		t.Errorf("wrong reply %T", reply)
+	} else {
+		assertTrue(t, x.Err == ErrNoChallenge.Error(), "wrong error")
+	}
+
+}
@@ -0,0 +1,183 @@
+package node
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// eviction of peers (see Config.MaxConnections,
+// Config.MaxInbound, Config.MaxOutboundPerFeed
+// and Config.EvictScore)
+const (
+	servedHalfLife = 10 * time.Minute // of recent served bytes
+	evictGrace     = 1 * time.Minute  // new peers are not evicted
+)
+
+// recent bytes served by remote peer,
+// the value decays exponentially
+type servedBytes struct {
+	mx    sync.Mutex
+	bytes float64
+	at    time.Time
+}
+
+// decayed value, the mx must be locked
+func (s *servedBytes) decayed(now time.Time) float64 {
+	if s.bytes == 0 {
+		return 0
+	}
+	var hl = float64(now.Sub(s.at)) / float64(servedHalfLife)
+	return s.bytes * math.Exp2(-hl)
+}
+
+func (s *servedBytes) add(size int) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var now = time.Now()
+	s.bytes = s.decayed(now) + float64(size)
+	s.at = now
+}
+
+func (s *servedBytes) value() float64 {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.decayed(time.Now())
+}
+
+// is the message served data (Root objects and objects)
+func isServed(m msg.Msg) bool {
+	switch m.(type) {
+	case *msg.Root, *msg.RootDelta, *msg.Object, *msg.DataChunk:
+		return true
+	}
+	return false
+}
+
+// ServedBytes returns recent number of bytes of Root
+// objects and objects received from remote peer. The
+// number decays with half-life of 10 minutes. It's
+// the base of the score of the peer (see Score)
+func (c *Conn) ServedBytes() int64 {
+	return int64(c.served.value())
+}
+
+// limit of connections, the MaxPeers is
+// alias of the MaxConnections
+func (c *Config) maxConnections() int {
+	if c.MaxPeers > 0 {
+		return c.MaxPeers
+	}
+	return c.MaxConnections
+}
+
+// the least useful of given connections, excluding
+// connections established recently, or nil; the
+// victim scores below given one, otherwise it's nil
+func leastUseful(cs []*Conn, below int64) (victim *Conn) {
+
+	var (
+		now = time.Now()
+		min = below
+	)
+
+	for _, c := range cs {
+
+		if now.Sub(c.established) < evictGrace {
+			continue
+		}
+
+		if score := c.Score(); score < min {
+			victim, min = c, score
+		}
+
+	}
+
+	return
+}
+
+// check limits for new established connection; the
+// victim is the least useful peer to evict to make
+// room for the new one, the err is not nil if there
+// is no room
+func (n *Node) checkLimits(c *Conn) (victim *Conn, err error) {
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	var conf = n.config
+
+	if c.incoming == true && conf.MaxInbound > 0 {
+
+		var in []*Conn
+		for _, x := range n.ic {
+			if x.incoming == true {
+				in = append(in, x)
+			}
+		}
+
+		if len(in) >= conf.MaxInbound {
+			if victim = leastUseful(in, conf.EvictScore); victim == nil {
+				return nil, ErrMaxInbound
+			}
+			return
+		}
+
+	}
+
+	if max := conf.maxConnections(); max > 0 && len(n.ic) >= max {
+
+		var all = make([]*Conn, 0, len(n.ic))
+		for _, x := range n.ic {
+			all = append(all, x)
+		}
+
+		if victim = leastUseful(all, conf.EvictScore); victim == nil {
+			return nil, ErrMaxConnections
+		}
+
+	}
+
+	return
+}
+
+// make room for outgoing subscription of given
+// connection to given feed, unsubscribing the
+// least useful peer if it's necessary
+func (n *Node) roomForFeed(c *Conn, feed cipher.PubKey) (err error) {
+
+	var max = n.config.MaxOutboundPerFeed
+
+	if max <= 0 || c.incoming == true {
+		return
+	}
+
+	var out []*Conn
+	for _, x := range n.fs.connectionsOfFeed(feed) {
+		if x != c && x.incoming == false {
+			out = append(out, x)
+		}
+	}
+
+	if len(out) < max {
+		return
+	}
+
+	var victim = leastUseful(out, n.config.EvictScore)
+
+	if victim == nil {
+		return ErrMaxOutboundPerFeed
+	}
+
+	n.Debugf(ConnPin, "[%s] evict from %s (score %d)", victim.String(),
+		feed.Hex()[:7], victim.Score())
+
+	victim.Unsubscribe(feed)
+	return
+}
@@ -0,0 +1,180 @@
+package node
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// make the Conn evictable
+func ageConn(c *Conn) {
+	c.n.mx.Lock()
+	defer c.n.mx.Unlock()
+
+	c.established = time.Now().Add(-2 * evictGrace)
+}
+
+func waitPeers(n *Node, want int) (got int) {
+	for i := 0; i < 100; i++ {
+		if got = len(n.Connections()); got == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return
+}
+
+func Test_servedBytes(t *testing.T) {
+
+	var s servedBytes
+
+	assertTrue(t, s.value() == 0, "not zero")
+
+	s.add(1000)
+	s.at = s.at.Add(-servedHalfLife)
+
+	assertTrue(t, math.Abs(s.value()-500) < 1, "wrong decay")
+
+	s.add(500)
+	assertTrue(t, math.Abs(s.value()-1000) < 1, "wrong value")
+
+}
+
+func TestNode_MaxInbound(t *testing.T) {
+
+	var conf = getTestConfig("server")
+	conf.UDP.Listen = ""
+	conf.MaxInbound = 1
+
+	var s, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var a, b = getTestNodeNotListen("a"), getTestNodeNotListen("b")
+	defer a.Close()
+	defer b.Close()
+
+	if _, err = a.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	// a is connected recently and is not evicted
+
+	b.Connect(conf.TCP.Listen)
+
+	assertTrue(t, waitPeers(s, 1) == 1, "wrong number of peers")
+	assertTrue(t, waitPeers(b, 0) == 0, "b is not rejected")
+	_, ok := s.hasPeer(a.ID())
+	assertTrue(t, ok == true, "a evicted")
+
+	// the least useful peer evicted
+
+	var sa, _ = s.hasPeer(a.ID())
+	ageConn(sa)
+
+	if _, err = b.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, waitPeers(s, 1) == 1, "wrong number of peers")
+	_, ok = s.hasPeer(b.ID())
+	assertTrue(t, ok == true, "b is not connected")
+
+}
+
+func TestConn_MaxOutboundPerFeed(t *testing.T) {
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	var servers []*Node
+
+	for _, address := range []string{"127.0.0.1:8087", "127.0.0.1:8089"} {
+
+		var conf = getTestConfig(address)
+		conf.TCP.Listen = address
+		conf.UDP.Listen = ""
+
+		var s, err = NewNode(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		assertNil(t, s.Share(feed))
+		servers = append(servers, s)
+
+	}
+
+	var conf = getTestConfigNotListen("client")
+	conf.MaxOutboundPerFeed = 1
+
+	var c, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var cs []*Conn
+	for _, s := range servers {
+		var sc *Conn
+		if sc, err = c.Connect(s.TCP().Address()); err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, sc)
+	}
+
+	assertNil(t, cs[0].Subscribe(feed))
+
+	err = cs[1].Subscribe(feed)
+	assertTrue(t, err == ErrMaxOutboundPerFeed, "wrong error")
+
+	// evict the first one
+	ageConn(cs[0])
+
+	assertNil(t, cs[1].Subscribe(feed))
+
+	assertTrue(t, len(cs[0].Feeds()) == 0, "not unsubscribed")
+	assertTrue(t, len(cs[1].Feeds()) == 1, "not subscribed")
+	_, ok := c.hasPeer(servers[0].ID())
+	assertTrue(t, ok == true, "closed")
+
+}
+
+func TestConfig_EvictScore(t *testing.T) {
+
+	var conf = getTestConfig("server")
+	conf.UDP.Listen = ""
+	conf.MaxPeers = 1 // alias of the MaxConnections
+
+	var s, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var a, b = getTestNodeNotListen("a"), getTestNodeNotListen("b")
+	defer a.Close()
+	defer b.Close()
+
+	if _, err = a.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	// a has served data and is not evicted
+
+	var sa, _ = s.hasPeer(a.ID())
+	ageConn(sa)
+	sa.served.add(int(conf.EvictScore) * 1024)
+
+	b.Connect(conf.TCP.Listen)
+
+	assertTrue(t, waitPeers(s, 1) == 1, "wrong number of peers")
+	assertTrue(t, waitPeers(b, 0) == 0, "b is not rejected")
+	_, ok := s.hasPeer(a.ID())
+	assertTrue(t, ok == true, "a evicted")
+
+}
@@ -0,0 +1,211 @@
+package node
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/skycoin/cxo/node/nat"
+)
+
+// port mapping
+const (
+	natTimeout  = 5 * time.Second  // discovery and requests
+	natLifetime = 20 * time.Minute // renewed at half
+)
+
+// NAT gateway discovery (replaced by tests)
+var natDiscover = nat.Discover
+
+// a mapped listening port
+type portMapping struct {
+	scheme   string // tcp, udp, ws or kcp
+	protocol string // tcp or udp
+	intPort  int    // listening port
+	extPort  int    // external port or zero if not mapped
+}
+
+// port mappings of the Node
+type portMappings struct {
+	mx sync.Mutex
+	gw nat.Interface // or nil
+	ip net.IP        // external IP
+	ms []*portMapping
+}
+
+// listening ports of the Node
+func (n *Node) listeningPorts() (ms []*portMapping) {
+
+	var add = func(scheme, protocol, address string) {
+		if address == "" {
+			return
+		}
+		var _, port, err = net.SplitHostPort(address)
+		if err != nil {
+			return
+		}
+		var p int
+		if p, err = strconv.Atoi(port); err != nil || p == 0 {
+			return // arbitrary port can't be mapped
+		}
+		ms = append(ms, &portMapping{
+			scheme:   scheme,
+			protocol: protocol,
+			intPort:  p,
+		})
+	}
+
+	add("tcp", "tcp", n.TCP().Address())
+	add("udp", "udp", n.UDP().Address())
+	add("ws", "tcp", n.WS().Address())
+	add("kcp", "udp", n.KCP().Address())
+
+	return
+}
+
+// map listening ports, renew the mappings and
+// remove them on close
+func (n *Node) mapPorts() {
+	defer n.await.Done()
+
+	var ms = n.listeningPorts()
+
+	if len(ms) == 0 {
+		return
+	}
+
+	var found = make(chan nat.Interface, 1)
+
+	go func() {
+		var gw, err = natDiscover(natTimeout)
+		if err != nil {
+			n.Printf("[ERR] [nat] %v", err)
+			found <- nil
+			return
+		}
+		found <- gw
+	}()
+
+	var gw nat.Interface
+
+	select {
+	case gw = <-found:
+		if gw == nil {
+			return
+		}
+	case <-n.closeq:
+		return
+	}
+
+	n.Debugf(DiscoveryPin, "[nat] found %s", gw)
+
+	n.pm.mx.Lock()
+	n.pm.gw = gw
+	n.pm.ms = ms
+	n.pm.mx.Unlock()
+
+	n.renewMappings()
+
+	var tk = time.NewTicker(natLifetime / 2)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			n.renewMappings()
+		case <-n.closeq:
+			n.deleteMappings()
+			return
+		}
+	}
+}
+
+// add or renew the mappings
+func (n *Node) renewMappings() {
+
+	n.pm.mx.Lock()
+	defer n.pm.mx.Unlock()
+
+	var ip, err = n.pm.gw.ExternalIP()
+	if err != nil {
+		n.Printf("[ERR] [nat] %s: external IP: %v", n.pm.gw, err)
+	} else {
+		n.pm.ip = ip
+	}
+
+	for _, m := range n.pm.ms {
+
+		var ext = m.extPort
+		if ext == 0 {
+			ext = m.intPort // try the same port
+		}
+
+		ext, err = n.pm.gw.AddMapping(m.protocol, ext, m.intPort, "cxo",
+			natLifetime)
+
+		if err != nil {
+			n.Printf("[ERR] [nat] %s: map %s port %d: %v", n.pm.gw,
+				m.scheme, m.intPort, err)
+			m.extPort = 0
+			continue
+		}
+
+		n.Debugf(DiscoveryPin, "[nat] %s port %d mapped to %d", m.scheme,
+			m.intPort, ext)
+
+		m.extPort = ext
+	}
+
+}
+
+// remove the mappings
+func (n *Node) deleteMappings() {
+
+	n.pm.mx.Lock()
+	defer n.pm.mx.Unlock()
+
+	for _, m := range n.pm.ms {
+
+		if m.extPort == 0 {
+			continue
+		}
+
+		var err = n.pm.gw.DeleteMapping(m.protocol, m.extPort, m.intPort)
+
+		if err != nil {
+			n.Printf("[ERR] [nat] %s: unmap %s port %d: %v", n.pm.gw,
+				m.scheme, m.extPort, err)
+		}
+
+		m.extPort = 0
+	}
+
+}
+
+// ExternalAddresses returns addresses of the Node
+// learned from NAT gateway (see Config.PortMapping).
+// The addresses are URLs like tcp://1.2.3.4:8870
+// and can be passed to the Connect method of a Node.
+// The list is empty if ports are not mapped (yet)
+func (n *Node) ExternalAddresses() (addresses []string) {
+
+	n.pm.mx.Lock()
+	defer n.pm.mx.Unlock()
+
+	if n.pm.ip == nil {
+		return
+	}
+
+	var ip = n.pm.ip.String()
+
+	for _, m := range n.pm.ms {
+		if m.extPort == 0 {
+			continue
+		}
+		addresses = append(addresses, m.scheme+"://"+
+			net.JoinHostPort(ip, strconv.Itoa(m.extPort)))
+	}
+
+	return
+}
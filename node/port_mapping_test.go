@@ -0,0 +1,129 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/cxo/node/nat"
+)
+
+// fake NAT gateway, it maps port + 1000
+type testGateway struct {
+	mx      sync.Mutex
+	mapped  map[string]int // "tcp:8087" -> lifetime (seconds)
+	deleted []string
+}
+
+func (g *testGateway) ExternalIP() (net.IP, error) {
+	return net.IPv4(1, 2, 3, 4), nil
+}
+
+func (g *testGateway) AddMapping(
+	protocol string,
+	extPort int,
+	intPort int,
+	_ string,
+	lifetime time.Duration,
+) (
+	mapped int,
+	err error,
+) {
+
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	mapped = intPort + 1000
+	g.mapped[fmt.Sprintf("%s:%d", protocol, mapped)] = int(lifetime.Seconds())
+	return
+}
+
+func (g *testGateway) DeleteMapping(protocol string, extPort, _ int) error {
+
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	var key = fmt.Sprintf("%s:%d", protocol, extPort)
+	delete(g.mapped, key)
+	g.deleted = append(g.deleted, key)
+	return nil
+}
+
+func (g *testGateway) String() string {
+	return "test"
+}
+
+func TestNode_ExternalAddresses(t *testing.T) {
+
+	var gw = &testGateway{mapped: make(map[string]int)}
+
+	var seedDiscover = natDiscover
+	defer func() { natDiscover = seedDiscover }()
+
+	natDiscover = func(time.Duration) (nat.Interface, error) {
+		return gw, nil
+	}
+
+	var conf = getTestConfig("nat")
+	conf.KCPAddress = "127.0.0.1:8088"
+	conf.PortMapping = true
+
+	var n, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = []string{
+		"kcp://1.2.3.4:9088",
+		"tcp://1.2.3.4:9087",
+		"udp://1.2.3.4:9087",
+	}
+
+	var as []string
+	for i := 0; i < 100; i++ {
+		if as = n.ExternalAddresses(); len(as) == len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sort.Strings(as)
+	assertTrue(t, fmt.Sprint(as) == fmt.Sprint(want),
+		fmt.Sprint("wrong addresses: ", as))
+
+	gw.mx.Lock()
+	for _, key := range []string{"tcp:9087", "udp:9087", "udp:9088"} {
+		assertTrue(t, gw.mapped[key] == int(natLifetime.Seconds()),
+			"not mapped "+key)
+	}
+	gw.mx.Unlock()
+
+	assertNil(t, n.Close())
+
+	gw.mx.Lock()
+	defer gw.mx.Unlock()
+
+	assertTrue(t, len(gw.mapped) == 0, "mappings not removed")
+	assertTrue(t, len(gw.deleted) == 3, "wrong number of deleted mappings")
+
+}
+
+func TestNode_ExternalAddresses_disabled(t *testing.T) {
+
+	var seedDiscover = natDiscover
+	defer func() { natDiscover = seedDiscover }()
+
+	natDiscover = func(time.Duration) (nat.Interface, error) {
+		t.Error("unexpected discovery")
+		return nil, nat.ErrNotFound
+	}
+
+	var n = getTestNode("nat")
+	defer n.Close()
+
+	assertTrue(t, len(n.ExternalAddresses()) == 0, "unexpected addresses")
+
+}
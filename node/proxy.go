@@ -0,0 +1,322 @@
+package node
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skycoin/net/client"
+	"github.com/skycoin/net/factory"
+)
+
+// parse and check out proxy URL (see Config.ProxyURL)
+func parseProxyURL(proxyURL string) (u *url.URL, err error) {
+
+	if u, err = url.Parse(proxyURL); err != nil {
+		return
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h", "http":
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrProxyScheme, u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host of proxy URL %q", proxyURL)
+	}
+
+	return
+}
+
+// dial given host:port directly or through
+// the Config.ProxyURL if it's set; .onion
+// addresses are dialed through Tor SOCKS
+// port (see Config.Tor) or the ProxyURL
+func (n *Node) dial(address string, timeout time.Duration) (
+	nc net.Conn,
+	err error,
+) {
+
+	var proxyURL = n.config.ProxyURL
+
+	if isOnion(address) == true {
+		if n.config.Tor.SOCKS != "" {
+			proxyURL = "socks5h://" + n.config.Tor.SOCKS
+		} else if proxyURL == "" {
+			return nil, ErrNoTor
+		}
+	}
+
+	if proxyURL == "" {
+		return net.DialTimeout("tcp", address, timeout)
+	}
+
+	var u *url.URL
+	if u, err = parseProxyURL(proxyURL); err != nil {
+		return
+	}
+
+	var pc net.Conn
+	if pc, err = net.DialTimeout("tcp", u.Host, timeout); err != nil {
+		return
+	}
+
+	if timeout > 0 {
+		pc.SetDeadline(time.Now().Add(timeout))
+	}
+
+	if u.Scheme == "http" {
+		nc, err = httpConnect(pc, u, address)
+	} else {
+		nc, err = pc, socks5Connect(pc, u, address)
+	}
+
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+
+	pc.SetDeadline(time.Time{})
+	return
+}
+
+// connect through the Config.ProxyURL
+// or Tor (see TCP.Connect)
+func (t *TCP) dialProxy(address string) (fc *factory.Connection, err error) {
+
+	var nc net.Conn
+	if nc, err = t.n.dial(address, t.n.config.TCP.ResponseTimeout); err != nil {
+		return
+	}
+
+	var cc = client.NewClientTCPConn(nc)
+	cc.SetStatusToConnected()
+
+	fc = &factory.Connection{Connection: cc}
+	fc.SetContextLogger(fc.GetContextLogger().WithField("type", "tcp"))
+
+	t.AddConn(fc)
+	return
+}
+
+//
+// SOCKS5 (RFC 1928, RFC 1929)
+//
+
+// SOCKS5 CONNECT, the address is resolved by proxy
+// if it's not an IP, that is required by Tor
+func socks5Connect(nc net.Conn, u *url.URL, address string) (err error) {
+
+	var host, sport string
+	if host, sport, err = net.SplitHostPort(address); err != nil {
+		return
+	}
+
+	var port int
+	if port, err = strconv.Atoi(sport); err != nil || port < 0 ||
+		port > 0xffff {
+
+		return fmt.Errorf("invalid port %q", sport)
+	}
+
+	// methods: no authentication, username/password
+
+	var methods = []byte{0x00}
+	if u.User != nil {
+		methods = append(methods, 0x02)
+	}
+
+	if _, err = nc.Write(append([]byte{0x05, byte(len(methods))},
+		methods...)); err != nil {
+
+		return
+	}
+
+	var reply [2]byte
+	if _, err = io.ReadFull(nc, reply[:]); err != nil {
+		return
+	}
+
+	if reply[0] != 0x05 {
+		return fmt.Errorf("%w: not a SOCKS5 proxy", ErrProxy)
+	}
+
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		if u.User == nil {
+			return fmt.Errorf("%w: authentication required", ErrProxy)
+		}
+		if err = socks5Auth(nc, u.User); err != nil {
+			return
+		}
+	default:
+		return fmt.Errorf("%w: no acceptable authentication methods",
+			ErrProxy)
+	}
+
+	// the request
+
+	var rq = []byte{0x05, 0x01, 0x00} // CONNECT
+
+	// a proxy knows nothing about zones of the Node
+	if i := strings.LastIndexByte(host, '%'); i >= 0 &&
+		net.ParseIP(host[:i]) != nil {
+
+		host = host[:i]
+	}
+
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("too long host name %q", host)
+		}
+		rq = append(rq, 0x03, byte(len(host)))
+		rq = append(rq, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		rq = append(rq, 0x01)
+		rq = append(rq, ip4...)
+	} else {
+		rq = append(rq, 0x04)
+		rq = append(rq, ip.To16()...)
+	}
+
+	rq = append(rq, 0, 0)
+	binary.BigEndian.PutUint16(rq[len(rq)-2:], uint16(port))
+
+	if _, err = nc.Write(rq); err != nil {
+		return
+	}
+
+	// the reply
+
+	var hdr [4]byte
+	if _, err = io.ReadFull(nc, hdr[:]); err != nil {
+		return
+	}
+
+	if hdr[1] != 0x00 {
+		return fmt.Errorf("%w: SOCKS5 reply %d", ErrProxy, hdr[1])
+	}
+
+	var skip int // bound address and port
+
+	switch hdr[3] {
+	case 0x01:
+		skip = net.IPv4len + 2
+	case 0x04:
+		skip = net.IPv6len + 2
+	case 0x03:
+		var ln [1]byte
+		if _, err = io.ReadFull(nc, ln[:]); err != nil {
+			return
+		}
+		skip = int(ln[0]) + 2
+	default:
+		return fmt.Errorf("%w: unknown address type %d", ErrProxy, hdr[3])
+	}
+
+	_, err = io.ReadFull(nc, make([]byte, skip))
+	return
+}
+
+// username/password authentication
+func socks5Auth(nc net.Conn, user *url.Userinfo) (err error) {
+
+	var (
+		name    = user.Username()
+		pass, _ = user.Password()
+	)
+
+	if len(name) > 255 || len(pass) > 255 {
+		return errors.New("too long SOCKS5 username or password")
+	}
+
+	var rq = []byte{0x01, byte(len(name))}
+	rq = append(rq, name...)
+	rq = append(rq, byte(len(pass)))
+	rq = append(rq, pass...)
+
+	if _, err = nc.Write(rq); err != nil {
+		return
+	}
+
+	var reply [2]byte
+	if _, err = io.ReadFull(nc, reply[:]); err != nil {
+		return
+	}
+
+	if reply[1] != 0x00 {
+		return fmt.Errorf("%w: authentication failed", ErrProxy)
+	}
+
+	return
+}
+
+//
+// HTTP CONNECT
+//
+
+// a connection with data buffered by reader
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
+
+func httpConnect(nc net.Conn, u *url.URL, address string) (
+	conn net.Conn,
+	err error,
+) {
+
+	var rq = &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+
+	if u.User != nil {
+		var pass, _ = u.User.Password()
+		rq.Header.Set("Proxy-Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString(
+				[]byte(u.User.Username()+":"+pass),
+			))
+	}
+
+	if err = rq.Write(nc); err != nil {
+		return
+	}
+
+	var (
+		br   = bufio.NewReader(nc)
+		resp *http.Response
+	)
+
+	if resp, err = http.ReadResponse(br, rq); err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrProxy, resp.Status)
+	}
+
+	if br.Buffered() == 0 {
+		return nc, nil
+	}
+
+	return &bufferedConn{Conn: nc, r: br}, nil
+}
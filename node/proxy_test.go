@@ -0,0 +1,247 @@
+package node
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// relay data between two connections
+func relay(a, b net.Conn) {
+	go func() {
+		io.Copy(a, b)
+		a.Close()
+	}()
+	io.Copy(b, a)
+	b.Close()
+}
+
+// minimal SOCKS5 proxy with username/password
+// authentication, it counts connections
+func runTestSOCKS5(t *testing.T, user, pass string) (
+	address string,
+	count *int32,
+	closeFunc func(),
+) {
+
+	var l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count = new(int32)
+
+	var serve = func(c net.Conn) {
+		defer c.Close()
+
+		var hdr = make([]byte, 2)
+		if _, err := io.ReadFull(c, hdr); err != nil {
+			return
+		}
+		io.ReadFull(c, make([]byte, hdr[1])) // methods
+		c.Write([]byte{0x05, 0x02})          // username/password
+
+		// RFC 1929
+		var ver = make([]byte, 2)
+		io.ReadFull(c, ver)
+		var name = make([]byte, ver[1])
+		io.ReadFull(c, name)
+		io.ReadFull(c, ver[:1])
+		var pw = make([]byte, ver[0])
+		io.ReadFull(c, pw)
+
+		if string(name) != user || string(pw) != pass {
+			c.Write([]byte{0x01, 0x01})
+			return
+		}
+		c.Write([]byte{0x01, 0x00})
+
+		// request
+		var rq = make([]byte, 4)
+		if _, err := io.ReadFull(c, rq); err != nil {
+			return
+		}
+
+		var host string
+		switch rq[3] {
+		case 0x01:
+			var ip = make([]byte, 4)
+			io.ReadFull(c, ip)
+			host = net.IP(ip).String()
+		case 0x03:
+			var ln = make([]byte, 1)
+			io.ReadFull(c, ln)
+			var name = make([]byte, ln[0])
+			io.ReadFull(c, name)
+			host = string(name)
+		default:
+			return
+		}
+
+		var port = make([]byte, 2)
+		io.ReadFull(c, port)
+
+		var target, err = net.Dial("tcp", net.JoinHostPort(host,
+			strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+		if err != nil {
+			c.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return
+		}
+
+		atomic.AddInt32(count, 1)
+		c.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		relay(c, target)
+	}
+
+	go func() {
+		for {
+			var c, err = l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(c)
+		}
+	}()
+
+	return l.Addr().String(), count, func() { l.Close() }
+}
+
+// minimal HTTP CONNECT proxy, it counts connections
+func runTestHTTPProxy(t *testing.T) (
+	address string,
+	count *int32,
+	closeFunc func(),
+) {
+
+	var l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count = new(int32)
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT expected", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var target, err = net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		var c, _, _ = w.(http.Hijacker).Hijack()
+
+		atomic.AddInt32(count, 1)
+		io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n")
+		relay(c, target)
+	}
+
+	var s = &http.Server{Handler: http.HandlerFunc(handler)}
+	go s.Serve(l)
+
+	return l.Addr().String(), count, func() { s.Close() }
+}
+
+func testProxyConnect(t *testing.T, proxyURL string, count *int32) {
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+	)
+
+	sconf.UDP.Listen = ""
+	sconf.Public = true
+	cconf.ProxyURL = proxyURL
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.PeerID() != sn.ID() {
+		t.Error("wrong peer")
+	}
+
+	if _, err = c.RemoteFeeds(); err != nil {
+		t.Error(err)
+	}
+
+	if atomic.LoadInt32(count) != 1 {
+		t.Error("not proxied")
+	}
+
+}
+
+func TestNode_proxy(t *testing.T) {
+
+	t.Run("socks5", func(t *testing.T) {
+
+		var address, count, closeFunc = runTestSOCKS5(t, "user", "pass")
+		defer closeFunc()
+
+		testProxyConnect(t, "socks5://user:pass@"+address, count)
+
+	})
+
+	t.Run("socks5 auth", func(t *testing.T) {
+
+		var address, _, closeFunc = runTestSOCKS5(t, "user", "pass")
+		defer closeFunc()
+
+		var cconf = getTestConfigNotListen("client")
+		cconf.ProxyURL = "socks5://user:wrong@" + address
+
+		var cn, err = NewNode(cconf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cn.Close()
+
+		if _, err = cn.TCP().Connect("127.0.0.1:1"); !errors.Is(err, ErrProxy) {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+	t.Run("http", func(t *testing.T) {
+
+		var address, count, closeFunc = runTestHTTPProxy(t)
+		defer closeFunc()
+
+		testProxyConnect(t, "http://"+address, count)
+
+	})
+
+	t.Run("scheme", func(t *testing.T) {
+
+		var conf = getTestConfigNotListen("client")
+		conf.ProxyURL = "ftp://127.0.0.1:21"
+
+		if err := conf.Validate(); !errors.Is(err, ErrProxyScheme) {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+}
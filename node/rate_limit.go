@@ -0,0 +1,179 @@
+package node
+
+import (
+	"sync"
+	"time"
+)
+
+// A RateLimit represents bandwidth limits in bytes
+// per second. Zero is no limit. The limits are
+// applied to encoded messages as they are on the
+// wire (see Config.RateLimit)
+type RateLimit struct {
+	Up   int64 // sent bytes per second
+	Down int64 // received bytes per second
+}
+
+// RateLimits of the Node, used by RPC
+type RateLimits struct {
+	Global RateLimit // all connections
+	Peer   RateLimit // every connection
+}
+
+// token bucket, the burst is one second of the
+// rate; a message bigger then the burst is sent
+// in debt, that delays next messages
+type tokenBucket struct {
+	mx     sync.Mutex
+	rate   float64 // bytes per second, zero is no limit
+	tokens float64 // available bytes, can be negative
+	at     time.Time
+}
+
+func (b *tokenBucket) setRate(rate int64) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.refill(time.Now())
+
+	if b.rate == 0 {
+		b.tokens = float64(rate) // full
+	}
+
+	if b.rate = float64(rate); b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+}
+
+func (b *tokenBucket) getRate() int64 {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return int64(b.rate)
+}
+
+// the mx must be locked
+func (b *tokenBucket) refill(now time.Time) {
+	if b.rate > 0 && b.at.IsZero() == false {
+		b.tokens += now.Sub(b.at).Seconds() * b.rate
+		if b.tokens > b.rate {
+			b.tokens = b.rate
+		}
+	}
+	b.at = now
+}
+
+// take given number of bytes and return time
+// to wait before they can be transferred
+func (b *tokenBucket) take(size int) (delay time.Duration) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if b.rate == 0 {
+		return
+	}
+
+	b.refill(time.Now())
+
+	if b.tokens -= float64(size); b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+
+	return
+}
+
+// bandwidth of a connection or of the Node
+type bandwidth struct {
+	up, down tokenBucket
+}
+
+func (b *bandwidth) set(rl RateLimit) {
+	b.up.setRate(rl.Up)
+	b.down.setRate(rl.Down)
+}
+
+func (b *bandwidth) get() (rl RateLimit) {
+	rl.Up = b.up.getRate()
+	rl.Down = b.down.getRate()
+	return
+}
+
+// wait for global and own tokens of the Conn,
+// the ok is false if the Conn has been closed
+func (c *Conn) throttle(up bool, size int) (ok bool) {
+
+	var delay time.Duration
+
+	if up == true {
+		delay = c.n.bw.up.take(size)
+		if d := c.bw.up.take(size); d > delay {
+			delay = d
+		}
+	} else {
+		delay = c.n.bw.down.take(size)
+		if d := c.bw.down.take(size); d > delay {
+			delay = d
+		}
+	}
+
+	if delay == 0 {
+		return true
+	}
+
+	var tm = time.NewTimer(delay)
+	defer tm.Stop()
+
+	select {
+	case <-tm.C:
+		return true
+	case <-c.closeq:
+		return false
+	}
+}
+
+// SetRateLimit changes bandwidth limits of the Conn.
+// By default, a Conn uses limits of the Node (see
+// Config.PeerRateLimit and (*Node).SetPeerRateLimit)
+func (c *Conn) SetRateLimit(rl RateLimit) {
+	c.bw.set(rl)
+}
+
+// RateLimit returns bandwidth limits of the Conn
+func (c *Conn) RateLimit() RateLimit {
+	return c.bw.get()
+}
+
+// SetRateLimit changes global bandwidth limits
+// of the Node (see Config.RateLimit)
+func (n *Node) SetRateLimit(rl RateLimit) {
+	n.bw.set(rl)
+}
+
+// RateLimit returns global bandwidth
+// limits of the Node
+func (n *Node) RateLimit() RateLimit {
+	return n.bw.get()
+}
+
+// SetPeerRateLimit changes bandwidth limits of every
+// connection, including established connections (see
+// Config.PeerRateLimit)
+func (n *Node) SetPeerRateLimit(rl RateLimit) {
+
+	n.mx.Lock()
+	n.prl = rl
+	n.mx.Unlock()
+
+	for _, c := range n.Connections() {
+		c.SetRateLimit(rl)
+	}
+}
+
+// PeerRateLimit returns bandwidth limits
+// of new connections of the Node
+func (n *Node) PeerRateLimit() (rl RateLimit) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	return n.prl
+}
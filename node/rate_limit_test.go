@@ -0,0 +1,122 @@
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func Test_tokenBucket(t *testing.T) {
+
+	var b tokenBucket
+
+	assertTrue(t, b.take(1e9) == 0, "limited by default")
+
+	b.setRate(1000)
+
+	assertTrue(t, b.take(1000) == 0, "burst is not full")
+
+	var delay = b.take(500)
+	assertTrue(t, delay > 450*time.Millisecond && delay <= 500*time.Millisecond,
+		"wrong delay: "+delay.String())
+
+	b.setRate(0)
+	assertTrue(t, b.take(1e9) == 0, "limited")
+
+}
+
+func TestConn_SetRateLimit(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+
+		received = make(chan msg.Msg, 2)
+	)
+
+	sconf.OnUserMsg = func(c *Conn, m msg.Msg) {
+		received <- m
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var rl = RateLimit{Up: 8000}
+	c.SetRateLimit(rl)
+	assertTrue(t, c.RateLimit() == rl, "wrong rate limit")
+
+	var (
+		um    = &testUserMsg{Text: strings.Repeat("x", 8000)}
+		start = time.Now()
+	)
+
+	// the first is sent using the burst,
+	// and the second waits for a second
+
+	for i := 0; i < 2; i++ {
+		if err = c.SendUserMsg(um); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(3 * time.Second):
+			t.Fatal("slow")
+		}
+	}
+
+	var elapsed = time.Since(start)
+	assertTrue(t, elapsed > 800*time.Millisecond,
+		"not throttled: "+elapsed.String())
+
+}
+
+func TestNode_SetPeerRateLimit(t *testing.T) {
+
+	var conf = getTestConfig("server")
+	conf.RateLimit = RateLimit{Up: 1000, Down: 2000}
+
+	var sn, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	assertTrue(t, sn.RateLimit() == conf.RateLimit, "wrong global limit")
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var rl = RateLimit{Up: 3000, Down: 4000}
+	cn.SetPeerRateLimit(rl)
+
+	assertTrue(t, cn.PeerRateLimit() == rl, "wrong peer limit")
+	assertTrue(t, c.RateLimit() == rl, "limit of connection is not changed")
+
+	conf.RateLimit.Up = -1
+	assertTrue(t, conf.Validate() != nil, "missing error")
+
+}
@@ -0,0 +1,404 @@
+package node
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// default backoff
+const (
+	BackoffMin    time.Duration = 1 * time.Second
+	BackoffMax    time.Duration = 5 * time.Minute
+	BackoffFactor float64       = 2
+	BackoffJitter float64       = 0.2
+)
+
+func init() {
+	gob.Register(&ExponentialBackoff{}) // Config over RPC
+}
+
+// A Dialer is reconnection strategy of the Node. The Node
+// reconnects to lost outgoing connections that have
+// subscriptions and subscribes to the same feeds again
+// (see Config.Dialer). The first attempt is made
+// immediately, and the Dialer returns delay before
+// next attempts
+type Dialer interface {
+	// Backoff returns delay after given number of
+	// failed attempts in a row (starting from 1) to
+	// reconnect to given address, or false to give up
+	Backoff(address string, failures int) (delay time.Duration, retry bool)
+}
+
+// An ExponentialBackoff is default Dialer. The delay
+// is Min*Factor^(failures-1) but not greater then the
+// Max, and the delay is randomized by the Jitter
+type ExponentialBackoff struct {
+	Min         time.Duration // first delay
+	Max         time.Duration // max delay
+	Factor      float64       // multiplier
+	Jitter      float64       // random fraction of a delay [0, 1]
+	MaxAttempts int           // give up after, zero is no limit
+}
+
+// NewExponentialBackoff returns ExponentialBackoff
+// with defaults and without attempts limit
+func NewExponentialBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		Min:    BackoffMin,
+		Max:    BackoffMax,
+		Factor: BackoffFactor,
+		Jitter: BackoffJitter,
+	}
+}
+
+// Backoff implements Dialer interface
+func (e *ExponentialBackoff) Backoff(
+	address string, //      : address to reconnect to
+	failures int, //        : failed attempts, starting from 1
+) (
+	delay time.Duration, // : delay before next attempt
+	retry bool, //          : false to give up
+) {
+
+	if e.MaxAttempts > 0 && failures >= e.MaxAttempts {
+		return
+	}
+
+	var d = float64(e.Min) * math.Pow(e.Factor, float64(failures-1))
+
+	if e.Max > 0 && d > float64(e.Max) {
+		d = float64(e.Max)
+	}
+
+	if e.Jitter > 0 {
+		d += d * e.Jitter * (2*rand.Float64() - 1) // +/- jitter
+	}
+
+	return time.Duration(d), true
+}
+
+// known peers (see Config.KnownPeers)
+type knownPeers struct {
+	mx     sync.Mutex
+	peers  map[string]map[cipher.PubKey]struct{} // address -> feeds
+	active map[string]struct{}                   // reconnecting
+
+	fmx sync.Mutex // lock of the file
+}
+
+func newKnownPeers() (k *knownPeers) {
+	return &knownPeers{
+		peers:  make(map[string]map[cipher.PubKey]struct{}),
+		active: make(map[string]struct{}),
+	}
+}
+
+// the ok is false if the Node doesn't
+// track known peers
+func (n *Node) knownPeersFile() (file string, ok bool) {
+	return n.config.KnownPeers, n.config.KnownPeers != ""
+}
+
+// normalized address to reconnect to
+func dialAddress(c *Conn) (address string) {
+	address = network(c.Connection) + "://" + c.Address()
+	if normalized, err := NormalizeAddress(address); err == nil {
+		address = normalized
+	}
+	return
+}
+
+// KnownPeers returns addresses of outgoing connections
+// with subscriptions and subscribed feeds. The Node
+// reconnects to the peers if connection lost, and
+// after restart if Config.KnownPeers is set
+func (n *Node) KnownPeers() (peers map[string][]cipher.PubKey) {
+
+	n.kp.mx.Lock()
+	defer n.kp.mx.Unlock()
+
+	peers = make(map[string][]cipher.PubKey, len(n.kp.peers))
+
+	for address, fs := range n.kp.peers {
+		var feeds = make([]cipher.PubKey, 0, len(fs))
+		for feed := range fs {
+			feeds = append(feeds, feed)
+		}
+		sortFeeds(feeds)
+		peers[address] = feeds
+	}
+
+	return
+}
+
+// subscribed to a feed of outgoing connection
+func (n *Node) addKnownFeed(c *Conn, feed cipher.PubKey) {
+
+	var address = dialAddress(c)
+
+	n.kp.mx.Lock()
+	var fs, ok = n.kp.peers[address]
+	if ok == false {
+		fs = make(map[cipher.PubKey]struct{})
+		n.kp.peers[address] = fs
+	}
+	_, ok = fs[feed]
+	fs[feed] = struct{}{}
+	n.kp.mx.Unlock()
+
+	if ok == false {
+		n.saveKnownPeers()
+	}
+}
+
+// unsubscribed from a feed of outgoing connection
+func (n *Node) delKnownFeed(c *Conn, feed cipher.PubKey) {
+
+	var address = dialAddress(c)
+
+	n.kp.mx.Lock()
+	var fs, ok = n.kp.peers[address]
+	if ok == true {
+		if _, ok = fs[feed]; ok == true {
+			delete(fs, feed)
+			if len(fs) == 0 {
+				delete(n.kp.peers, address)
+			}
+		}
+	}
+	n.kp.mx.Unlock()
+
+	if ok == true {
+		n.saveKnownPeers()
+	}
+}
+
+// forget a peer
+func (n *Node) delKnownPeer(address string) {
+
+	n.kp.mx.Lock()
+	var _, ok = n.kp.peers[address]
+	delete(n.kp.peers, address)
+	n.kp.mx.Unlock()
+
+	if ok == true {
+		n.saveKnownPeers()
+	}
+}
+
+// a known peer in the file
+type knownPeerJSON struct {
+	Address string   `json:"address"`
+	Feeds   []string `json:"feeds"`
+}
+
+// save known peers to Config.KnownPeers file
+func (n *Node) saveKnownPeers() {
+
+	var file, ok = n.knownPeersFile()
+	if ok == false {
+		return
+	}
+
+	n.kp.fmx.Lock()
+	defer n.kp.fmx.Unlock()
+
+	var (
+		peers = n.KnownPeers()
+		list  = make([]knownPeerJSON, 0, len(peers))
+	)
+
+	for address, feeds := range peers {
+		var kp = knownPeerJSON{Address: address}
+		for _, feed := range feeds {
+			kp.Feeds = append(kp.Feeds, feed.Hex())
+		}
+		list = append(list, kp)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Address < list[j].Address
+	})
+
+	var data, err = json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		n.Printf("[ERR] [known peers] %v", err)
+		return
+	}
+
+	if err = ioutil.WriteFile(file+".tmp", data, 0600); err == nil {
+		err = os.Rename(file+".tmp", file)
+	}
+
+	if err != nil {
+		n.Printf("[ERR] [known peers] %v", err)
+	}
+}
+
+// load known peers from Config.KnownPeers
+// file and reconnect to them
+func (n *Node) loadKnownPeers() (err error) {
+
+	var file, _ = n.knownPeersFile()
+
+	var data []byte
+	if data, err = ioutil.ReadFile(file); err != nil {
+		if os.IsNotExist(err) == true {
+			return nil // first start
+		}
+		return
+	}
+
+	var list []knownPeerJSON
+	if err = json.Unmarshal(data, &list); err != nil {
+		return
+	}
+
+	for _, kp := range list {
+
+		if kp.Address, err = NormalizeAddress(kp.Address); err != nil {
+			return
+		}
+
+		var feeds = make([]cipher.PubKey, 0, len(kp.Feeds))
+
+		for _, hex := range kp.Feeds {
+			var pk cipher.PubKey
+			if pk, err = cipher.PubKeyFromHex(hex); err != nil {
+				return
+			}
+			feeds = append(feeds, pk)
+		}
+
+		n.kp.mx.Lock()
+		var fs = make(map[cipher.PubKey]struct{}, len(feeds))
+		for _, feed := range feeds {
+			fs[feed] = struct{}{}
+		}
+		n.kp.peers[kp.Address] = fs
+		n.kp.mx.Unlock()
+
+		n.reconnect(kp.Address, feeds)
+	}
+
+	return
+}
+
+func (n *Node) onDialFailure(address string, failures int, err error) {
+
+	n.Debugf(ConnPin, "[%s] can't reconnect (%d): %v", address, failures, err)
+
+	if odf := n.config.OnDialFailure; odf != nil {
+		odf(n, address, failures, err)
+	}
+}
+
+// called when outgoing connection closed
+func (n *Node) lostConn(c *Conn, reason error, manual bool) {
+
+	select {
+	case <-n.closeq:
+		return // keep known peers
+	default:
+	}
+
+	var address = dialAddress(c)
+
+	if manual == true || reason == ErrEvicted || reason == ErrBanned {
+		n.delKnownPeer(address)
+		return
+	}
+
+	n.reconnect(address, n.KnownPeers()[address])
+}
+
+// (async) reconnect to a known peer using
+// Config.Dialer and subscribe to given
+// feeds again
+func (n *Node) reconnect(address string, feeds []cipher.PubKey) {
+
+	var dialer = n.config.Dialer
+
+	if dialer == nil || len(feeds) == 0 {
+		return
+	}
+
+	n.kp.mx.Lock()
+	if _, ok := n.kp.active[address]; ok == true {
+		n.kp.mx.Unlock()
+		return // already reconnecting
+	}
+	n.kp.active[address] = struct{}{}
+	n.kp.mx.Unlock()
+
+	// like DNS seeds, not tracked by the await
+	go func() {
+
+		defer func() {
+			n.kp.mx.Lock()
+			delete(n.kp.active, address)
+			n.kp.mx.Unlock()
+		}()
+
+		var (
+			c   *Conn
+			err error
+		)
+
+		for failures := 0; ; failures++ {
+
+			if failures > 0 {
+
+				var delay, retry = dialer.Backoff(address, failures)
+
+				if retry == false {
+					n.Printf("[ERR] can't reconnect to %s: %v, give up",
+						address, err)
+					n.delKnownPeer(address)
+					return
+				}
+
+				var tm = time.NewTimer(delay)
+
+				select {
+				case <-tm.C:
+				case <-n.closeq:
+					tm.Stop()
+					return
+				}
+
+			}
+
+			select {
+			case <-n.closeq:
+				return
+			default:
+			}
+
+			if c, err = n.Connect(address); err == nil {
+				break
+			}
+
+			n.onDialFailure(address, failures+1, err)
+		}
+
+		for _, pk := range feeds {
+			if err = c.Subscribe(pk); err != nil {
+				n.Printf("[ERR] [%s] can't subscribe to %s again: %v",
+					c.String(), pk.Hex()[:7], err)
+			}
+		}
+
+	}()
+
+}
@@ -0,0 +1,180 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestExponentialBackoff_Backoff(t *testing.T) {
+
+	var e = &ExponentialBackoff{
+		Min:         time.Second,
+		Max:         5 * time.Second,
+		Factor:      2,
+		MaxAttempts: 5,
+	}
+
+	for failures, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+	} {
+		var delay, retry = e.Backoff("tcp://127.0.0.1:8087", failures)
+		assertTrue(t, retry == true, "gave up")
+		assertTrue(t, delay == want, "wrong delay: "+delay.String())
+	}
+
+	var _, retry = e.Backoff("tcp://127.0.0.1:8087", 5)
+	assertTrue(t, retry == false, "doesn't give up")
+
+	e.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		var delay, _ = e.Backoff("tcp://127.0.0.1:8087", 2)
+		assertTrue(t, delay >= time.Second && delay <= 3*time.Second,
+			"wrong jitter: "+delay.String())
+	}
+
+}
+
+// wait for connection to given node subscribed to the feed
+func waitSubscribed(n, to *Node, feed cipher.PubKey) (ok bool) {
+	for i := 0; i < 100; i++ {
+		if c, yep := n.hasPeer(to.ID()); yep == true {
+			for _, f := range c.Feeds() {
+				if f == feed {
+					return true
+				}
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return
+}
+
+func getTestReconnectServer(t *testing.T, feed cipher.PubKey) (s *Node) {
+
+	var conf = getTestConfig("server")
+	conf.UDP.Listen = ""
+
+	var err error
+	if s, err = NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, s.Share(feed))
+	return
+}
+
+func TestNode_reconnect(t *testing.T) {
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	var s = getTestReconnectServer(t, feed)
+
+	var (
+		mx       sync.Mutex
+		failures int
+	)
+
+	var conf = getTestConfigNotListen("client")
+	conf.Dialer = &ExponentialBackoff{
+		Min:    20 * time.Millisecond,
+		Max:    50 * time.Millisecond,
+		Factor: 2,
+	}
+	conf.OnDialFailure = func(n *Node, address string, f int, err error) {
+		mx.Lock()
+		defer mx.Unlock()
+		failures = f
+	}
+
+	var cn, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.Connect(s.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(feed))
+
+	var address = "tcp://" + s.TCP().Address()
+
+	var known = cn.KnownPeers()
+	assertTrue(t, len(known) == 1 && len(known[address]) == 1,
+		"wrong known peers")
+
+	// connection lost (the Close of the Node doesn't
+	// close accepted connections of TCP factory)
+	var sc, _ = s.hasPeer(cn.ID())
+	assertNil(t, s.Close())
+	sc.Close()
+	time.Sleep(200 * time.Millisecond)
+
+	mx.Lock()
+	assertTrue(t, failures > 1, "no reconnection attempts")
+	mx.Unlock()
+
+	s = getTestReconnectServer(t, feed)
+	defer s.Close()
+
+	assertTrue(t, waitSubscribed(cn, s, feed) == true, "not reconnected")
+
+	// closed manually
+	c, _ = cn.hasPeer(s.ID())
+	assertNil(t, c.Close())
+
+	assertTrue(t, len(cn.KnownPeers()) == 0, "not removed from known peers")
+
+}
+
+func TestConfig_KnownPeers(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "known-peers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var feed, _ = cipher.GenerateKeyPair()
+
+	var s = getTestReconnectServer(t, feed)
+	defer s.Close()
+
+	var conf = getTestConfigNotListen("client")
+	conf.KnownPeers = filepath.Join(dir, "known_peers.json")
+
+	var cn *Node
+	if cn, err = NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	var c *Conn
+	if c, err = cn.Connect(s.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(feed))
+	assertNil(t, cn.Close())
+
+	// restart
+
+	if cn, err = NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	assertTrue(t, waitSubscribed(cn, s, feed) == true, "not reconnected")
+
+}
@@ -0,0 +1,173 @@
+package node
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// registries requested by fillers of different feeds
+// at the same time (e.g. on start) are requested by
+// one RqRegistries
+const (
+	registryBatchDelay = 10 * time.Millisecond // wait for more requests
+	maxRegistryBatch   = 64                    // max registries per request
+)
+
+// a Registry requested by a filler
+type rqRegistry struct {
+	key   cipher.SHA256 // registry.RegistryRef
+	reply chan []byte   // encoded Registry or nil
+}
+
+// can registries of given feed be requested by
+// RqRegistries; encrypted feeds request them
+// as encrypted objects
+func (c *Conn) canBatchRegistries(feed cipher.PubKey) bool {
+	return c.HasFeature(msg.FeatureRegistries) == true &&
+		c.n.isEncrypted(feed) == false
+}
+
+// request Registry with other registries by one RqRegistries;
+// the requestRegistry returns nil if the peer doesn't have
+// the Registry or the Registry is too large for the batch,
+// and the Registry should be requested as object
+func (c *Conn) requestRegistry(
+	key cipher.SHA256, //      : the Registry
+	cancel <-chan struct{}, // : cancel the request
+) (
+	val []byte, //             : encoded Registry or nil
+	err error, //              : ErrCanceled or ErrClosed
+) {
+
+	var rr = &rqRegistry{key: key, reply: make(chan []byte, 1)}
+
+	c.mx.Lock()
+	c.regs = append(c.regs, rr)
+	if len(c.regs) == 1 {
+		c.await.Add(1)
+		go c.flushRegistries() // first in the batch
+	}
+	c.mx.Unlock()
+
+	select {
+	case val = <-rr.reply:
+	case <-cancel:
+		err = ErrCanceled
+	case <-c.closeq:
+		err = ErrClosed
+	}
+
+	return
+}
+
+// (async) send collected registry requests
+func (c *Conn) flushRegistries() {
+	defer c.await.Done()
+
+	var tm = time.NewTimer(registryBatchDelay)
+	defer tm.Stop()
+
+	select {
+	case <-tm.C:
+	case <-c.closeq:
+		return
+	}
+
+	c.mx.Lock()
+	var rrs = c.regs
+	c.regs = nil
+	c.mx.Unlock()
+
+	c.n.Debugf(MsgSendPin, "[%s] flushRegistries %d", c.String(), len(rrs))
+
+	for len(rrs) > 0 {
+		rrs = c.sendRqRegistries(rrs)
+	}
+}
+
+// request registries up to the maxRegistryBatch,
+// returning rest of given requests
+func (c *Conn) sendRqRegistries(rrs []*rqRegistry) (rest []*rqRegistry) {
+
+	var (
+		rq    = new(msg.RqRegistries)
+		idx   = make(map[cipher.SHA256]int) // the same Registry
+		batch []*rqRegistry
+	)
+
+	for rest = rrs; len(rest) > 0; rest = rest[1:] {
+		var rr = rest[0]
+		if _, ok := idx[rr.key]; ok == false {
+			if len(rq.Registries) == maxRegistryBatch {
+				break
+			}
+			idx[rr.key] = len(rq.Registries)
+			rq.Registries = append(rq.Registries, rr.key)
+		}
+		batch = append(batch, rr)
+	}
+
+	var regs [][]byte
+
+	if reply, err := c.sendRequest(rq); err == nil {
+		if x, ok := reply.(*msg.Registries); ok == true &&
+			len(x.Registries) == len(rq.Registries) {
+
+			regs = x.Registries
+		}
+	}
+
+	for _, rr := range batch {
+		var val []byte
+		if regs != nil {
+			if reg := regs[idx[rr.key]]; len(reg) > 0 &&
+				cipher.SumSHA256(reg) == rr.key {
+
+				val = reg
+			}
+		}
+		rr.reply <- val // buffered
+	}
+
+	return
+}
+
+// <- RqRegistries (registries)
+func (c *Conn) handleRqRegistries(seq uint32, rq *msg.RqRegistries) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqRegistries %d", c.String(),
+		len(rq.Registries))
+
+	if len(rq.Registries) > maxRegistryBatch {
+		c.sendMsg(c.nextSeq(), seq, &msg.Err{Err: "too many registries"})
+		return
+	}
+
+	var (
+		reply = &msg.Registries{Registries: make([][]byte, len(rq.Registries))}
+		size  int
+	)
+
+	for i, key := range rq.Registries {
+
+		var val, err = c.n.getObject(key)
+
+		if err != nil {
+			continue // not found
+		}
+
+		// the reply is not chunked (see data_chunk.go)
+		if size+len(val) > maxChunkSize {
+			continue // too large
+		}
+
+		size += len(val)
+		reply.Registries[i] = val
+	}
+
+	c.sendMsg(c.nextSeq(), seq, reply)
+	return
+}
@@ -0,0 +1,68 @@
+package node
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_requestRegistry(t *testing.T) {
+
+	var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+	defer sn.Close()
+	defer cn.Close()
+
+	var c, err = cn.TCP().Connect(sn.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		reg1 = []byte("registry 1")
+		reg2 = []byte("registry 2")
+		huge = bytes.Repeat([]byte("huge registry "), maxChunkSize/10)
+		miss = []byte("missing registry")
+
+		vals = [][]byte{reg1, reg2, reg1, huge, miss}
+		want = [][]byte{reg1, reg2, reg1, nil, nil}
+	)
+
+	for _, val := range [][]byte{reg1, reg2, huge} {
+		if _, err = sn.c.Set(cipher.SumSHA256(val), val, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		wg  sync.WaitGroup
+		got = make([][]byte, len(vals))
+	)
+
+	for i, val := range vals {
+		wg.Add(1)
+		go func(i int, key cipher.SHA256) {
+			defer wg.Done()
+			var err error
+			if got[i], err = c.requestRegistry(key, nil); err != nil {
+				t.Error(err)
+			}
+		}(i, cipher.SumSHA256(val))
+	}
+
+	wg.Wait()
+
+	for i := range want {
+		if bytes.Equal(got[i], want[i]) == false {
+			t.Errorf("%d: wrong registry received: %q", i, got[i])
+		}
+	}
+
+	if sent := c.MsgStats()[msg.RqRegistriesType].Sent; sent != 1 {
+		t.Errorf("wrong number of RqRegistries sent: %d", sent)
+	}
+
+}
@@ -0,0 +1,131 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// latest sealed Root of a relayed feed
+type relayRoot struct {
+	root *msg.Root // sealed Root
+	from *Conn     // received from
+}
+
+// sealed Root objects of feeds the Node relays; a
+// Node is relay of an encrypted feed if it doesn't
+// have key of the feed (see Config.FeedKeys); the
+// relay can't verify and fill the Root objects,
+// thus it keeps latest one in memory only
+type relayRoots struct {
+	mx    sync.Mutex
+	roots map[cipher.PubKey]*relayRoot
+}
+
+func newRelayRoots() (r *relayRoots) {
+	r = new(relayRoots)
+	r.roots = make(map[cipher.PubKey]*relayRoot)
+	return
+}
+
+// keep given sealed Root if it's newer than
+// kept one; a Root of another head replaces
+// kept one; it returns false if the Root is
+// not newer
+func (r *relayRoots) add(c *Conn, root *msg.Root) (ok bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	var rr = r.roots[root.Feed]
+
+	if rr != nil && rr.root.Nonce == root.Nonce && rr.root.Seq >= root.Seq {
+		return // not newer
+	}
+
+	r.roots[root.Feed] = &relayRoot{root: root, from: c}
+	return true
+}
+
+// latest sealed Root of given feed and connection
+// the Root received from, or nils
+func (r *relayRoots) get(feed cipher.PubKey) (root *msg.Root, from *Conn) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if rr := r.roots[feed]; rr != nil {
+		return rr.root, rr.from
+	}
+
+	return
+}
+
+// forget sealed Root of given feed
+func (r *relayRoots) del(feed cipher.PubKey) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	delete(r.roots, feed)
+}
+
+// received sealed Root or RootDelta of relayed feed,
+// the Root is kept and the message is forwarded to
+// other subscribers of the feed as is
+func (c *Conn) relayRoot(root *msg.Root, m msg.Msg) {
+
+	if c.n.fs.hasConnFeed(c, root.Feed) == false {
+		return // unexpected Root
+	}
+
+	if c.n.rl.add(c, root) == false {
+		return // old or the same
+	}
+
+	c.n.Debugf(MsgReceivePin, "[%s] relay Root %s/%d/%d", c.String(),
+		root.Feed.Hex()[:7], root.Nonce, root.Seq)
+
+	for _, sc := range c.n.fs.connectionsOfFeed(root.Feed) {
+		if sc != c {
+			sc.sendFeedMsg(root.Feed, m)
+		}
+	}
+
+}
+
+// request sealed object of relayed feed from the
+// peer latest Root of the feed received from, and
+// forward reply to the requester; since Root objects
+// are pushed from publisher, the request goes up
+// to the publisher and never loops
+func (c *Conn) relayObject(
+	seq uint32, //               : seq of the request
+	rq *msg.RqSealedObject, //   : the request
+	canceled <-chan struct{}, // : see cancel.go
+) {
+
+	var _, from = c.n.rl.get(rq.Feed)
+
+	if from == nil || from == c || from.isClosed() == true {
+		c.replyNotFound(seq, rq.Key)
+		return
+	}
+
+	var reply, err = from.sendSealedRequest(&msg.RqSealedObject{
+		Feed: rq.Feed,
+		Key:  rq.Key,
+	}, canceled)
+
+	if err == ErrCanceled {
+		return // no reply
+	}
+
+	if obj, ok := reply.(*msg.Object); ok == true && err == nil &&
+		obj.Key == rq.Key {
+
+		c.sendObject(seq, rq.Key, obj.Value) // sealed
+		return
+	}
+
+	c.replyNotFound(seq, rq.Key)
+}
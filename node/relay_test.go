@@ -0,0 +1,103 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestConn_relay(t *testing.T) {
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		fk     = NewFeedKey()
+
+		pconf = getTestConfigNotListen("publisher")
+		rconf = getTestConfig("relay")
+		mconf = getTestConfigNotListen("member")
+
+		filled = make(chan *registry.Root, 1)
+	)
+
+	pconf.FeedKeys = FeedKeys{pk: fk}
+	rconf.EncryptFeeds = Feeds{pk} // without the key
+	mconf.FeedKeys = FeedKeys{pk: fk}
+
+	mconf.OnRootFilled = func(_ *Node, r *registry.Root) {
+		filled <- r
+	}
+
+	var pn, rn, mn *Node
+	var err error
+
+	for _, x := range []struct {
+		n    **Node
+		conf *Config
+	}{
+		{&pn, pconf},
+		{&rn, rconf},
+		{&mn, mconf},
+	} {
+		if *x.n, err = NewNode(x.conf); err != nil {
+			t.Fatal(err)
+		}
+		defer (*x.n).Close()
+		assertNil(t, (*x.n).Share(pk))
+	}
+
+	var up *skyobject.Unpack
+	if up, err = pn.Container().Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}))
+
+	assertNil(t, pn.Container().Save(up, r))
+
+	var mc, pc *Conn
+
+	if mc, err = mn.TCP().Connect(rn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+	assertNil(t, mc.Subscribe(pk))
+
+	if pc, err = pn.TCP().Connect(rn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+	assertNil(t, pc.Subscribe(pk)) // push the Root
+
+	select {
+	case fr := <-filled:
+		assertTrue(t, fr.Hash == r.Hash, "wrong Root filled")
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+	// the relay keeps sealed Root in memory only
+
+	var root, from = rn.rl.get(pk)
+	assertTrue(t, root != nil && from != nil, "missing sealed Root")
+	assertTrue(t, string(root.Value) != string(r.Encode()), "not sealed")
+
+	_, err = rn.Container().LastRoot(pk, 1)
+	assertTrue(t, err != nil, "relay stores Root")
+
+	_, _, err = rn.Container().Get(r.Refs[0].Hash, 0)
+	assertTrue(t, err == data.ErrNotFound, "relay stores objects")
+
+	// the relay can't preview the feed
+
+	var rc = rn.Connections()[0]
+	err = rc.Preview(pk, func(registry.Pack, *registry.Root) bool {
+		return false
+	})
+	assertTrue(t, err == ErrNoFeedKey, "wrong error")
+
+}
@@ -0,0 +1,269 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// min interval between punch requests
+// forwarded or handled by a Conn
+const punchRequestInterval = time.Second
+
+// Rendezvous connects to given peer using the Conn as
+// rendezvous, for nodes behind NAT. The remote peer of
+// the Conn should be connected to the given peer and
+// should allow rendezvous (see Config.Rendezvous). The
+// given peer should be connected to the rendezvous and
+// should accept punch requests (see Config.AcceptPunch).
+// Both this Node and the peer should listen KCP. The
+// rendezvous sends UDP address of this Node to the peer,
+// and the peer starts punching its NAT. Then this Node
+// connects to the peer using KCP. The connection
+// succeeds if NAT of both sides maps UDP port to the
+// same external port regardless of destination (that
+// is common for home routers). If this Node is already
+// connected to the peer, then the existing connection
+// returned
+func (c *Conn) Rendezvous(peer cipher.PubKey) (pc *Conn, err error) {
+
+	if pc, ok := c.n.hasPeer(peer); ok == true {
+		return pc, nil // already connected
+	}
+
+	if c.HasFeature(msg.FeatureRendezvous) == false {
+		return nil, ErrNotSupported
+	}
+
+	var port = c.n.KCP().port()
+
+	if port == 0 {
+		return nil, ErrKCPNotListening
+	}
+
+	c.n.Debugf(ConnPin, "[%s] Rendezvous %s", c.String(), peer.Hex()[:7])
+
+	// the rendezvous requests the peer
+	var rt = 2 * c.responseTimeout()
+
+	var reply msg.Msg
+	reply, err = c.sendRequestCancel(&msg.RqRendezvous{
+		Peer: peer,
+		Port: port,
+	}, rt, nil)
+
+	if err != nil {
+		return
+	}
+
+	var address string
+
+	switch x := reply.(type) {
+
+	case *msg.Rendezvous:
+
+		if x.Peer != peer {
+			return nil, ErrInvalidResponse
+		}
+
+		address = x.Address
+
+	case *msg.Err:
+
+		return nil, remoteError(x.Err)
+
+	default:
+
+		return nil, fmt.Errorf("invalid response type %T", reply)
+
+	}
+
+	if pc, err = c.n.KCP().Connect(address); err != nil {
+		return
+	}
+
+	if pc.PeerID() != peer {
+		pc.Close()
+		return nil, ErrUnexpectedPeer
+	}
+
+	return
+}
+
+// Rendezvous connects to given peer using established
+// connections as rendezvous one by one (see
+// Conn.Rendezvous for details)
+func (n *Node) Rendezvous(peer cipher.PubKey) (c *Conn, err error) {
+
+	var ok bool
+	if c, ok = n.hasPeer(peer); ok == true {
+		return // already connected
+	}
+
+	err = ErrNoSuchPeer
+
+	for _, rc := range n.Connections() {
+
+		if rc.HasFeature(msg.FeatureRendezvous) == false {
+			continue
+		}
+
+		if c, err = rc.Rendezvous(peer); err == nil {
+			return
+		}
+
+		n.Debugf(ConnPin, "[%s] Rendezvous %s: %v", rc.String(),
+			peer.Hex()[:7], err)
+
+	}
+
+	return nil, err
+}
+
+// UDP address of remote peer of the Conn as this node
+// sees it; the port is KCP listening port of the peer
+func (c *Conn) observedAddress(port uint16) (address string, err error) {
+
+	if network(c.Connection) == "kcp" {
+		return c.Address(), nil // from the listening port
+	}
+
+	var host string
+	if host, _, err = net.SplitHostPort(c.Address()); err != nil {
+		return
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+// (async) <- RqRendezvous (peer, port)
+func (c *Conn) handleRqRendezvous(seq uint32, rq *msg.RqRendezvous) {
+	defer c.await.Done()
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqRendezvous %s", c.String(),
+		rq.Peer.Hex()[:7])
+
+	if c.n.config.Rendezvous == false {
+		c.sendErr(seq, ErrNotRendezvous)
+		return
+	}
+
+	var pc, ok = c.n.hasPeer(rq.Peer)
+
+	// only a peer connected to the rendezvous (registered)
+	// can be introduced, the rendezvous never connects to
+	if ok == false || pc == c || pc.IsIncoming() == false {
+		c.sendErr(seq, ErrNoSuchPeer)
+		return
+	}
+
+	if pc.HasFeature(msg.FeatureRendezvous) == false {
+		c.sendErr(seq, ErrNotSupported)
+		return
+	}
+
+	if c.allowPunchRequest() == false {
+		c.sendErr(seq, ErrRendezvousRate)
+		return
+	}
+
+	var address, err = c.observedAddress(rq.Port)
+
+	if err != nil {
+		c.sendErr(seq, err)
+		return
+	}
+
+	var reply msg.Msg
+	reply, err = pc.sendRequest(&msg.RqPunch{
+		Peer:    c.peerID,
+		Address: address,
+	})
+
+	if err != nil {
+		c.sendErr(seq, err)
+		return
+	}
+
+	switch x := reply.(type) {
+
+	case *msg.Punch:
+
+		if address, err = pc.observedAddress(x.Port); err != nil {
+			c.sendErr(seq, err)
+			return
+		}
+
+		c.sendMsg(c.nextSeq(), seq, &msg.Rendezvous{
+			Peer:    rq.Peer,
+			Address: address,
+		})
+
+	case *msg.Err:
+
+		c.sendMsg(c.nextSeq(), seq, x) // forward
+
+	default:
+
+		c.sendErr(seq, ErrInvalidResponse)
+
+	}
+
+}
+
+// <- RqPunch (peer, address)
+func (c *Conn) handleRqPunch(seq uint32, rq *msg.RqPunch) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqPunch %s %s", c.String(),
+		rq.Peer.Hex()[:7], rq.Address)
+
+	// the address is observed by a rendezvous this Node
+	// is connected to, any other peer can use this Node
+	// as a reflector sending RqPunch with any address
+	if c.n.config.AcceptPunch == false || c.IsIncoming() == true {
+		c.sendErr(seq, ErrPunchNotAllowed)
+		return
+	}
+
+	var port = c.n.KCP().port()
+
+	if port == 0 {
+		c.sendErr(seq, ErrKCPNotListening)
+		return
+	}
+
+	if c.allowPunchRequest() == false {
+		c.sendErr(seq, ErrRendezvousRate)
+		return
+	}
+
+	c.sendMsg(c.nextSeq(), seq, &msg.Punch{Port: port})
+
+	c.n.await.Add(1)
+	go c.n.KCP().punch(rq.Address)
+
+	return
+}
+
+// rate limit of punch requests of the Conn (see
+// punchRequestInterval), it returns false if the
+// limit is exceeded
+func (c *Conn) allowPunchRequest() (ok bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var now = time.Now()
+
+	if now.Sub(c.punched) < punchRequestInterval {
+		return false
+	}
+
+	c.punched = now
+	return true
+}
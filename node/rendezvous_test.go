@@ -0,0 +1,156 @@
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func getTestRendezvousPeer(t *testing.T, prefix string) (n *Node) {
+
+	var conf = getTestConfigNotListen(prefix)
+	conf.KCPAddress = "127.0.0.1:0"
+	conf.AcceptPunch = true
+
+	var err error
+	if n, err = NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestConn_Rendezvous(t *testing.T) {
+
+	var conf = getTestConfig("rendezvous")
+	conf.KCPAddress = "127.0.0.1:8088"
+	conf.Rendezvous = true
+
+	var r, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var a, b = getTestRendezvousPeer(t, "a"), getTestRendezvousPeer(t, "b")
+	defer a.Close()
+	defer b.Close()
+
+	// a -> r by KCP (the listening port is observed),
+	// b -> r by TCP (the port is reported by b)
+
+	var ar *Conn
+	if ar, err = a.Connect("kcp://" + conf.KCPAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = b.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	var ab *Conn
+	if ab, err = ar.Rendezvous(b.ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, ab.PeerID() == b.ID(), "wrong peer")
+	assertTrue(t, network(ab.Connection) == "kcp", "not a KCP connection")
+
+	var ba, ok = b.hasPeer(a.ID())
+	assertTrue(t, ok == true, "missing connection b -> a")
+	assertTrue(t, ba.IsIncoming() == true, "b -> a is not incoming")
+
+	// already connected
+	var same *Conn
+	if same, err = a.Rendezvous(b.ID()); err != nil {
+		t.Fatal(err)
+	}
+	assertTrue(t, same == ab, "new connection")
+
+}
+
+func TestConn_Rendezvous_errors(t *testing.T) {
+
+	var r = getTestNode("rendezvous") // not a rendezvous
+	defer r.Close()
+
+	var a, b = getTestRendezvousPeer(t, "a"), getTestRendezvousPeer(t, "b")
+	defer a.Close()
+	defer b.Close()
+
+	var ar, err = a.Connect(r.TCP().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = b.Connect(r.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ar.Rendezvous(b.ID())
+	assertTrue(t, errors.Is(err, ErrNotRendezvous), "wrong error")
+
+	r.config.Rendezvous = true
+
+	var unknown = getTestRendezvousPeer(t, "unknown")
+	defer unknown.Close()
+
+	_, err = ar.Rendezvous(unknown.ID())
+	assertTrue(t, errors.Is(err, ErrNoSuchPeer), "wrong error")
+
+	// not listening KCP
+	var c = getTestNodeNotListen("c")
+	defer c.Close()
+
+	c.config.AcceptPunch = true
+
+	var cr *Conn
+	if cr, err = c.Connect(r.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cr.Rendezvous(b.ID())
+	assertTrue(t, err == ErrKCPNotListening, "wrong error")
+
+	_, err = ar.Rendezvous(c.ID())
+	assertTrue(t, errors.Is(err, ErrKCPNotListening), "wrong error")
+
+	// one request per second
+	_, err = ar.Rendezvous(c.ID())
+	assertTrue(t, errors.Is(err, ErrRendezvousRate), "wrong error")
+
+	// doesn't accept punch requests
+	var d = getTestRendezvousPeer(t, "d")
+	defer d.Close()
+
+	d.config.AcceptPunch = false
+
+	if _, err = d.Connect(r.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var br, ok = b.hasPeer(r.ID())
+	assertTrue(t, ok == true, "missing connection b -> r")
+
+	_, err = br.Rendezvous(d.ID())
+	assertTrue(t, errors.Is(err, ErrPunchNotAllowed), "wrong error")
+
+	// a punch request from a peer that is not a rendezvous
+	// this Node connected to (can be any address)
+	r.config.AcceptPunch = true
+
+	var reply msg.Msg
+	reply, err = ar.sendRequest(&msg.RqPunch{
+		Peer:    b.ID(),
+		Address: "127.0.0.1:9",
+	})
+	assertNil(t, err)
+
+	if er, ok := reply.(*msg.Err); ok == false ||
+		er.Err != ErrPunchNotAllowed.Error() {
+
+		t.Errorf("wrong reply: %v", reply)
+	}
+
+}
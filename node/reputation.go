@@ -0,0 +1,226 @@
+package node
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// score of peers (see (*Conn).Score)
+const (
+	scoreHalfLife = 1 * time.Hour // of events
+
+	scoreServedKiB     float64 = 1   // per KiB of served data
+	scoreValidRoot     float64 = 10  // filled Root received from
+	scoreProtocolError float64 = -20 // invalid message, etc
+	scoreInvalidData   float64 = -50 // bad signature, wrong object
+
+	pruneInterval = 10 * time.Minute // of insignificant events and bans
+)
+
+// decaying sum of events of a peer
+type peerEvents struct {
+	score float64
+	at    time.Time
+}
+
+func (p peerEvents) decayed(now time.Time) float64 {
+	if p.score == 0 {
+		return 0
+	}
+	var hl = float64(now.Sub(p.at)) / float64(scoreHalfLife)
+	return p.score * math.Exp2(-hl)
+}
+
+// events and bans of peers; the events survive
+// reconnections, thus a hostile peer can't reset
+// its score reconnecting; a peer is public key
+// or IP (see (*Conn).setReputationKey)
+type reputation struct {
+	mx    sync.Mutex
+	peers map[string]peerEvents
+	bans  map[string]time.Time // public key or IP -> until
+}
+
+func newReputation() (r *reputation) {
+	return &reputation{
+		peers: make(map[string]peerEvents),
+		bans:  make(map[string]time.Time),
+	}
+}
+
+func (r *reputation) events(key string) float64 {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	return r.peers[key].decayed(time.Now())
+}
+
+func (r *reputation) add(key string, delta float64) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	var now = time.Now()
+	r.peers[key] = peerEvents{r.peers[key].decayed(now) + delta, now}
+}
+
+// forget events of a disconnected peer if
+// they are insignificant
+func (r *reputation) forget(key string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if math.Abs(r.peers[key].decayed(time.Now())) < 1 {
+		delete(r.peers, key)
+	}
+}
+
+// forget insignificant events and expired bans,
+// events of connected peers are forgotten too,
+// since they are insignificant anyway
+func (r *reputation) prune() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	var now = time.Now()
+
+	for key, pe := range r.peers {
+		if math.Abs(pe.decayed(now)) < 1 {
+			delete(r.peers, key)
+		}
+	}
+
+	for key, until := range r.bans {
+		if now.Before(until) == false {
+			delete(r.bans, key)
+		}
+	}
+}
+
+func (r *reputation) ban(until time.Time, keys ...string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	for _, key := range keys {
+		if key != "" {
+			r.bans[key] = until
+		}
+	}
+}
+
+func (r *reputation) isBanned(key string) (banned bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	var until, ok = r.bans[key]
+
+	if ok == false {
+		return
+	}
+
+	if time.Now().Before(until) == true {
+		return true
+	}
+
+	delete(r.bans, key) // expired
+	return
+}
+
+// IP of given address or blank string
+func hostIP(address string) string {
+	var host, _, err = net.SplitHostPort(address)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// prune the reputation periodically (async)
+func (n *Node) pruneReputation() {
+	defer n.await.Done()
+
+	var tk = time.NewTicker(pruneInterval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			n.rep.prune()
+		case <-n.closeq:
+			return
+		}
+	}
+}
+
+// key of reputation of the peer; the public key if
+// the peer is authenticated (see IsAuthenticated),
+// otherwise the peer can claim any public key, and
+// the key is IP address of the peer; the key is set
+// once the connection established
+func (c *Conn) setReputationKey() {
+	if c.IsAuthenticated() == true {
+		c.repKey = c.peerID.Hex()
+	} else if c.repKey = hostIP(c.Address()); c.repKey == "" {
+		c.repKey = c.Address()
+	}
+}
+
+// Score returns reputation of remote peer. The score
+// is KiB of Root objects and objects served recently
+// (see ServedBytes), plus valid Root objects received
+// from the peer, minus protocol errors and invalid
+// data. Events decay with half-life of an hour and
+// survive reconnections. Objects are requested from
+// peers with higher score first, and peers with lower
+// score are evicted first (see Config.MaxConnections
+// and Config.EvictScore). A peer is banned if its
+// score falls to the Config.BanScore
+func (c *Conn) Score() int64 {
+	return int64(math.Round(c.served.value()/1024*scoreServedKiB +
+		c.n.rep.events(c.repKey)))
+}
+
+// record an event, it can ban the peer
+func (c *Conn) addScore(delta float64) {
+
+	c.n.rep.add(c.repKey, delta)
+
+	var (
+		bs    = c.n.config.BanScore
+		score = c.Score()
+	)
+
+	c.n.Debugf(ConnPin, "[%s] score %+g: %d", c.String(), delta, score)
+
+	if bs < 0 && score <= bs {
+		c.n.Printf("[ERR] [%s] ban, score %d", c.String(), score)
+		c.n.ban(c)
+		go c.close(ErrBanned) // the method can be called by the Conn
+	}
+}
+
+// ban peer of given connection for Config.BanTime,
+// the public key is banned only if the peer is
+// authenticated, and the IP is banned anyway
+func (n *Node) ban(c *Conn) {
+	n.rep.ban(time.Now().Add(n.config.BanTime), c.repKey,
+		hostIP(c.Address()))
+}
+
+// Ban the peer for Config.BanTime and close the
+// Conn. Neither the public key nor the IP address
+// of the peer can connect to the Node during the
+// ban. The public key is banned only if the peer
+// is authenticated (see IsAuthenticated)
+func (c *Conn) Ban() {
+	c.n.ban(c)
+	c.close(ErrBanned)
+}
+
+// IsBanned returns true if given peer is banned
+func (n *Node) IsBanned(peer cipher.PubKey) bool {
+	return n.rep.isBanned(peer.Hex())
+}
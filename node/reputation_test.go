@@ -0,0 +1,156 @@
+package node
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_peerEvents(t *testing.T) {
+
+	var p = peerEvents{score: -100, at: time.Now().Add(-scoreHalfLife)}
+
+	assertTrue(t, math.Abs(p.decayed(time.Now())+50) < 1, "wrong decay")
+
+}
+
+func TestConn_Score(t *testing.T) {
+
+	var conf = getTestConfig("server")
+	conf.UDP.Listen = ""
+	conf.BanScore = -30
+
+	var s, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	if _, err = cn.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	var sc, _ = s.hasPeer(cn.ID())
+
+	sc.addScore(scoreValidRoot)
+	assertTrue(t, sc.Score() == 10, "wrong score")
+
+	sc.addScore(scoreProtocolError)
+	assertTrue(t, sc.Score() == -10, "wrong score")
+	assertTrue(t, s.IsBanned(cn.ID()) == false, "banned")
+
+	sc.addScore(scoreInvalidData)
+	assertTrue(t, s.IsBanned(cn.ID()) == true, "not banned")
+	assertTrue(t, waitPeers(s, 0) == 0, "not disconnected")
+
+	// the IP address is banned too
+
+	var other = getTestNodeNotListen("other")
+	defer other.Close()
+
+	_, err = other.Connect(conf.TCP.Listen)
+	assertTrue(t, err != nil, "connected")
+
+	conf.BanScore = 1
+	assertTrue(t, conf.Validate() != nil, "missing error")
+
+}
+
+func TestConn_Ban(t *testing.T) {
+
+	var conf = getTestConfig("server")
+	conf.UDP.Listen = ""
+	conf.BanScore = 0 // disabled, but manual bans work
+
+	var s, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	// the client bans the server
+	c.Ban()
+	assertTrue(t, cn.IsBanned(s.ID()) == true, "not banned")
+
+	_, err = cn.Connect(conf.TCP.Listen)
+	assertTrue(t, err == ErrBanned, "wrong error")
+
+}
+
+func Test_reputation_prune(t *testing.T) {
+
+	var (
+		r   = newReputation()
+		now = time.Now()
+	)
+
+	r.add("significant", scoreInvalidData)
+	r.peers["insignificant"] = peerEvents{
+		score: scoreValidRoot,
+		at:    now.Add(-10 * scoreHalfLife),
+	}
+
+	r.ban(now.Add(time.Hour), "banned")
+	r.ban(now.Add(-time.Second), "expired")
+
+	r.prune()
+
+	_, ok := r.peers["significant"]
+	assertTrue(t, ok == true, "significant events pruned")
+	_, ok = r.peers["insignificant"]
+	assertTrue(t, ok == false, "insignificant events not pruned")
+
+	_, ok = r.bans["banned"]
+	assertTrue(t, ok == true, "ban pruned")
+	_, ok = r.bans["expired"]
+	assertTrue(t, ok == false, "expired ban not pruned")
+
+}
+
+func TestConn_setReputationKey(t *testing.T) {
+
+	var conf = getTestConfig("server")
+	conf.UDP.Listen = ""
+
+	var s, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	if _, err = cn.Connect(conf.TCP.Listen); err != nil {
+		t.Fatal(err)
+	}
+
+	var sc, _ = s.hasPeer(cn.ID())
+
+	assertTrue(t, sc.repKey == cn.ID().Hex(), "wrong key")
+
+	// a peer that is not authenticated can claim any public
+	// key, thus its score and bans are bound to its IP
+
+	sc.authenticated = false
+	sc.setReputationKey()
+
+	assertTrue(t, sc.repKey == "127.0.0.1", "wrong key")
+
+	sc.Ban()
+
+	assertTrue(t, s.IsBanned(cn.ID()) == false, "the key is banned")
+	assertTrue(t, s.rep.isBanned("127.0.0.1") == true, "the IP is not banned")
+
+}
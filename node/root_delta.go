@@ -0,0 +1,133 @@
+package node
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// new objects of given full Root relative to previous Root
+// of the same head, nil if the Root can't be sent as the
+// RootDelta: there is no previous Root, or the objects are
+// too large (see Config.MaxRootDelta)
+func (n *Node) rootDelta(r *registry.Root) (delta map[cipher.SHA256][]byte) {
+
+	var max = n.config.MaxRootDelta
+
+	if max <= 0 || r.Seq == 0 {
+		return
+	}
+
+	var (
+		cc        = n.container(r.Pub)
+		prev, err = cc.Root(r.Pub, r.Nonce, r.Seq-1)
+	)
+
+	if err != nil {
+		return // no previous Root
+	}
+
+	var keys []cipher.SHA256
+	if keys, err = cc.Delta(prev, r); err != nil {
+		n.Printf("[ERR] can't get delta of %s: %v", r.Short(), err)
+		return
+	}
+
+	var size int
+
+	delta = make(map[cipher.SHA256][]byte, len(keys))
+
+	for _, key := range keys {
+
+		var val []byte
+		if val, _, err = cc.Get(key, 0); err != nil {
+			n.Printf("[ERR] can't get delta of %s: %v", r.Short(), err)
+			return nil
+		}
+
+		if size += len(val); size > max {
+			return nil // too large
+		}
+
+		delta[key] = val
+	}
+
+	return
+}
+
+// objects of received RootDelta by hash, or nil
+func objectsByKey(objects [][]byte) (delta map[cipher.SHA256][]byte) {
+
+	if len(objects) == 0 {
+		return
+	}
+
+	delta = make(map[cipher.SHA256][]byte, len(objects))
+
+	for _, val := range objects {
+		delta[cipher.SumSHA256(val)] = val
+	}
+
+	return
+}
+
+func (c *Conn) sendRootDelta(
+	r *registry.Root, //               : the Root
+	delta map[cipher.SHA256][]byte, // : new objects
+) {
+
+	var val, ok = c.n.sealValue(r.Pub, r.Encode())
+
+	if ok == false {
+		return // relay never sends plain Root objects
+	}
+
+	var objects = make([][]byte, 0, len(delta))
+
+	for _, obj := range delta {
+		obj, _ = c.n.sealValue(r.Pub, obj)
+		objects = append(objects, obj)
+	}
+
+	c.sendFeedMsg(r.Pub, &msg.RootDelta{
+		Feed:  r.Pub,
+		Nonce: r.Nonce,
+		Seq:   r.Seq,
+
+		Value: val,
+
+		Sig: r.Sig,
+
+		Objects: objects,
+	})
+}
+
+// <- RootDelta (feed, nonce, seq, sig, val, objects)
+func (c *Conn) handleRootDelta(rd *msg.RootDelta) (err error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRootDelta %s/%d/%d (%d objects)",
+		c.String(), rd.Feed.Hex()[:7], rd.Nonce, rd.Seq, len(rd.Objects))
+
+	defer c.usedCredit(rd.Feed, deltaSize(rd)) // sealed size, after handling
+
+	var root = &msg.Root{
+		Feed:  rd.Feed,
+		Nonce: rd.Nonce,
+		Seq:   rd.Seq,
+		Value: rd.Value,
+		Sig:   rd.Sig,
+	}
+
+	if c.n.isRelayed(rd.Feed) == true {
+		c.relayRoot(root, rd)
+		return
+	}
+
+	if err = c.n.openRoot(root, rd.Objects); err != nil {
+		return // fatal
+	}
+
+	c.receivedRoot(root, rd.Objects)
+	return
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_rootDelta(t *testing.T) {
+
+	var n = getTestNodeNotListen("publisher")
+	defer n.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, n.Share(pk))
+
+	var (
+		c = n.Container()
+
+		up  *skyobject.Unpack
+		err error
+	)
+
+	if up, err = c.Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var save = func(users ...User) (r *registry.Root) {
+		r = &registry.Root{Pub: pk, Nonce: 1}
+		for _, u := range users {
+			r.Refs = append(r.Refs, dynamicByValue(t, up, "test.User", u))
+		}
+		assertNil(t, c.Save(up, r))
+		return
+	}
+
+	var (
+		alice = User{"Alice", 19, nil}
+		eva   = User{"Eva", 21, nil}
+
+		r1 = save(alice)
+		r2 = save(alice, eva)
+	)
+
+	// no previous Root
+
+	assertTrue(t, n.rootDelta(r1) == nil, "unexpected delta")
+
+	// new objects
+
+	var delta = n.rootDelta(r2)
+
+	assertTrue(t, len(delta) == 1, "wrong delta length")
+
+	var val, ok = delta[cipher.SumSHA256(encoder.Serialize(eva))]
+	assertTrue(t, ok == true, "missing new object")
+
+	// received
+
+	var received = objectsByKey([][]byte{val})
+	assertTrue(t, len(received) == 1, "wrong number of received objects")
+	assertTrue(t, string(received[cipher.SumSHA256(val)]) == string(val),
+		"wrong received object")
+
+	// too large
+
+	n.config.MaxRootDelta = len(val) - 1
+	assertTrue(t, n.rootDelta(r2) == nil, "unexpected delta")
+
+	// disabled
+
+	n.config.MaxRootDelta = 0
+	assertTrue(t, n.rootDelta(r2) == nil, "unexpected delta")
+
+}
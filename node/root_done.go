@@ -0,0 +1,59 @@
+package node
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// report filled Root to connections of the feed, including
+// the connection the Root has been received from
+func (n *nodeFeed) broadcastRootDone(r *registry.Root) {
+
+	for c := range n.cs {
+
+		if c.HasFeature(msg.FeatureRootDone) == false {
+			continue
+		}
+
+		c.sendRootDone(r)
+	}
+
+}
+
+func (c *Conn) sendRootDone(r *registry.Root) {
+	c.sendFeedMsg(r.Pub, &msg.RootDone{
+		Feed:  r.Pub,
+		Nonce: r.Nonce,
+		Seq:   r.Seq,
+	})
+}
+
+// -> RootDone (feed, nonce, seq)
+func (c *Conn) handleRootDone(rd *msg.RootDone) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRootDone %s/%d/%d",
+		c.String(), rd.Feed.Hex()[:7], rd.Nonce, rd.Seq)
+
+	// the peer should be subscribed to the feed
+	if c.n.fs.hasConnFeed(c, rd.Feed) == false {
+		return // ignore
+	}
+
+	c.n.onRootFilledRemote(c, rd.Feed, rd.Nonce, rd.Seq)
+	return
+}
+
+func (n *Node) onRootFilledRemote(
+	c *Conn, //            : the connection
+	feed cipher.PubKey, // : feed of the Root
+	nonce uint64, //       : head of the Root
+	seq uint64, //         : seq of the Root
+) {
+
+	if orfr := n.config.OnRootFilledRemote; orfr != nil {
+		orfr(c, feed, nonce, seq)
+	}
+
+}
@@ -0,0 +1,69 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_onRootFilledRemote(t *testing.T) {
+
+	var (
+		done = make(chan *registry.Root, 10)
+
+		sconf = getTestConfig("publisher")
+	)
+
+	sconf.OnRootFilledRemote = func(_ *Conn, feed cipher.PubKey,
+		nonce, seq uint64) {
+
+		done <- &registry.Root{Pub: feed, Nonce: nonce, Seq: seq}
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("subscriber")
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// not subscribed yet, ignored
+	c.sendRootDone(&registry.Root{Pub: pk, Nonce: 1, Seq: 1})
+
+	assertNil(t, c.Subscribe(pk))
+
+	// the subscriber has filled a Root
+	cn.fs.broadcastRootDone(&registry.Root{Pub: pk, Nonce: 1, Seq: 2})
+
+	select {
+	case r := <-done:
+		if r.Pub != pk || r.Nonce != 1 || r.Seq != 2 {
+			t.Errorf("wrong Root reported: %s/%d/%d", r.Pub.Hex()[:7], r.Nonce,
+				r.Seq)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow")
+	}
+
+	select {
+	case r := <-done:
+		t.Errorf("unexpected report: %d", r.Seq)
+	default:
+	}
+
+}
@@ -0,0 +1,113 @@
+package node
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// report rejected Root to the peer it has been
+// received from, if the peer supports it
+func (c *Conn) sendRootReject(
+	feed cipher.PubKey, //      : feed of the Root
+	nonce uint64, //            : head of the Root
+	seq uint64, //              : seq of the Root
+	reason msg.RejectReason, // : reason
+	err error, //               : details
+) {
+
+	if c.HasFeature(msg.FeatureRootReject) == false {
+		return
+	}
+
+	c.n.Debugf(MsgSendPin, "[%s] reject Root %s/%d/%d: %s: %v", c.String(),
+		feed.Hex()[:7], nonce, seq, reason, err)
+
+	c.sendFeedMsg(feed, &msg.RootReject{
+		Feed:   feed,
+		Nonce:  nonce,
+		Seq:    seq,
+		Reason: reason,
+		Err:    err.Error(),
+	})
+}
+
+// (async) reject Root received by the nodeHead
+func (c *Conn) rejectRoot(
+	r *registry.Root, //        : the Root
+	reason msg.RejectReason, // : reason
+	err error, //               : details
+) {
+	defer c.await.Done()
+
+	c.sendRootReject(r.Pub, r.Nonce, r.Seq, reason, err)
+}
+
+// reason to reject a Root that can't be filled because
+// of given error; the ok is false if the error is not
+// a fault of the peer (e.g. timeout)
+func (n *Node) fillingRejectReason(
+	r *registry.Root, //           : the Root
+	err error, //                  : filling error
+) (
+	reason msg.RejectReason, //    : the reason
+	ok bool, //                    : reject or not
+) {
+
+	switch err {
+	case ErrTenantQuota, skyobject.ErrObjectIsTooLarge,
+		registry.ErrSizeLimit, registry.ErrLengthLimit,
+		registry.ErrDepthLimit:
+
+		return msg.RejectTooLarge, true
+
+	case skyobject.ErrBlankRegistryRef:
+
+		return msg.RejectUnknownRegistry, true
+
+	case ErrMaxHeadsLimit:
+
+		return msg.RejectPolicy, true
+
+	case ErrTimeout, ErrClosed, skyobject.ErrTerminated:
+
+		return // not a fault of the peer
+
+	}
+
+	if _, isTooLarge := err.(*skyobject.ObjectIsTooLargeError); isTooLarge {
+		return msg.RejectTooLarge, true
+	}
+
+	// filling breaks getting the Registry
+	if _, regErr := n.container(r.Pub).Registry(r.Reg); regErr != nil {
+		return msg.RejectUnknownRegistry, true
+	}
+
+	return
+}
+
+// -> RootReject (feed, nonce, seq, reason, err)
+func (c *Conn) handleRootReject(rr *msg.RootReject) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRootReject %s/%d/%d: %s: %s",
+		c.String(), rr.Feed.Hex()[:7], rr.Nonce, rr.Seq, rr.Reason, rr.Err)
+
+	// the peer should be subscribed to the feed
+	if c.n.fs.hasConnFeed(c, rr.Feed) == false {
+		return // ignore
+	}
+
+	c.n.onRootRejected(c, rr)
+	return
+}
+
+func (n *Node) onRootRejected(c *Conn, rr *msg.RootReject) {
+
+	if orr := n.config.OnRootRejected; orr != nil {
+		orr(c, rr)
+	}
+
+}
@@ -0,0 +1,101 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_onRootRejected(t *testing.T) {
+
+	var (
+		rejected = make(chan *msg.RootReject, 10)
+
+		sconf = getTestConfig("publisher")
+	)
+
+	sconf.OnRootRejected = func(_ *Conn, rr *msg.RootReject) {
+		rejected <- rr
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("subscriber")
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	// the subscriber can't verify signature
+	c.receivedRoot(&msg.Root{
+		Feed:  pk,
+		Nonce: 1,
+		Seq:   2,
+		Value: []byte("malformed"),
+	}, nil)
+
+	select {
+	case rr := <-rejected:
+		if rr.Feed != pk || rr.Nonce != 1 || rr.Seq != 2 {
+			t.Errorf("wrong Root rejected: %s/%d/%d", rr.Feed.Hex()[:7],
+				rr.Nonce, rr.Seq)
+		}
+		if rr.Reason != msg.RejectBadSignature || rr.Err == "" {
+			t.Errorf("wrong reason: %s: %q", rr.Reason, rr.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow")
+	}
+
+}
+
+func TestNode_fillingRejectReason(t *testing.T) {
+
+	var n = getTestNodeNotListen("subscriber")
+	defer n.Close()
+
+	var r = &registry.Root{Reg: registry.RegistryRef{1, 2, 3}}
+
+	for _, tt := range []struct {
+		err    error
+		reason msg.RejectReason
+		ok     bool
+	}{
+		{ErrTenantQuota, msg.RejectTooLarge, true},
+		{registry.ErrDepthLimit, msg.RejectTooLarge, true},
+		{skyobject.ErrBlankRegistryRef, msg.RejectUnknownRegistry, true},
+		{ErrMaxHeadsLimit, msg.RejectPolicy, true},
+		{ErrTimeout, 0, false},
+		{ErrNoConnectionsToFillFrom, msg.RejectUnknownRegistry, true},
+	} {
+		var reason, ok = n.fillingRejectReason(r, tt.err)
+		if reason != tt.reason || ok != tt.ok {
+			t.Errorf("%v: want %s/%t, got %s/%t", tt.err, tt.reason, tt.ok,
+				reason, ok)
+		}
+	}
+
+	if _, ok := n.fillingRejectReason(r, errors.New("test")); ok == false {
+		t.Error("missing Registry is not reported")
+	}
+
+}
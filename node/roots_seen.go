@@ -0,0 +1,82 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// number of recently received Root objects the Node
+// remembers to don't verify the same Root twice
+const rootsSeenSize = 1024
+
+// a received Root
+type seenRoot struct {
+	feed cipher.PubKey
+	seq  uint64
+	hash cipher.SHA256
+}
+
+// recently received and verified Root objects (in
+// meshy topologies the same Root is received from
+// many peers)
+type rootsSeen struct {
+	mx   sync.Mutex
+	set  map[seenRoot]*registry.Root
+	ring [rootsSeenSize]seenRoot
+	i    int
+}
+
+func newRootsSeen() (s *rootsSeen) {
+	s = new(rootsSeen)
+	s.set = make(map[seenRoot]*registry.Root, rootsSeenSize)
+	return
+}
+
+// remember verified Root; the oldest
+// remembered Root is forgotten
+func (s *rootsSeen) add(r *registry.Root) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var sr = seenRoot{feed: r.Pub, seq: r.Seq, hash: r.Hash}
+
+	if _, ok := s.set[sr]; ok == true {
+		return
+	}
+
+	var rc = *r // copy, the r can be changed by filler
+	rc.IsFull, rc.IsPartial = false, false
+
+	delete(s.set, s.ring[s.i]) // forget the oldest
+	s.ring[s.i] = sr
+	s.i = (s.i + 1) % rootsSeenSize
+
+	s.set[sr] = &rc
+}
+
+// copy of verified Root or nil if the Root
+// has not been received recently
+func (s *rootsSeen) get(root *msg.Root) (r *registry.Root) {
+
+	var sr = seenRoot{
+		feed: root.Feed,
+		seq:  root.Seq,
+		hash: cipher.SumSHA256(root.Value),
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var seen, ok = s.set[sr]
+
+	if ok == false {
+		return
+	}
+
+	var rc = *seen // copy
+	return &rc
+}
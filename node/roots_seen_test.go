@@ -0,0 +1,58 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestRootsSeen(t *testing.T) {
+
+	var (
+		rs     = newRootsSeen()
+		pk, sk = cipher.GenerateKeyPair()
+
+		r   = &registry.Root{Pub: pk, Seq: 1}
+		val = r.Encode()
+	)
+
+	r.Hash = cipher.SumSHA256(val)
+	r.Sig = cipher.SignHash(r.Hash, sk)
+
+	var root = &msg.Root{Feed: pk, Seq: 1, Sig: r.Sig, Value: val}
+
+	if rs.get(root) != nil {
+		t.Fatal("seen before added")
+	}
+
+	r.IsFull = true
+	rs.add(r)
+
+	var seen = rs.get(root)
+
+	if seen == nil {
+		t.Fatal("not seen")
+	}
+
+	if seen == r || seen.IsFull == true || seen.Hash != r.Hash {
+		t.Error("wrong copy")
+	}
+
+	// another seq
+	if rs.get(&msg.Root{Feed: pk, Seq: 2, Value: val}) != nil {
+		t.Error("seen Root with another seq")
+	}
+
+	// the oldest forgotten
+	for i := 0; i < rootsSeenSize; i++ {
+		rs.add(&registry.Root{Pub: pk, Seq: uint64(i + 2)})
+	}
+
+	if rs.get(root) != nil {
+		t.Error("the oldest is not forgotten")
+	}
+
+}
@@ -139,6 +139,43 @@ func (r *RPC) ConnectionsOfFeed(feed cipher.PubKey, cs *[]string) (_ error) {
 	return
 }
 
+// ExternalAddresses is RPC method
+func (r *RPC) ExternalAddresses(_ struct{}, addresses *[]string) (_ error) {
+	*addresses = r.n.ExternalAddresses()
+	return
+}
+
+// ListeningAddresses is RPC method
+func (r *RPC) ListeningAddresses(_ struct{}, addresses *[]string) (_ error) {
+	*addresses = r.n.ListeningAddresses()
+	return
+}
+
+// OnionAddress is RPC method
+func (r *RPC) OnionAddress(_ struct{}, address *string) (_ error) {
+	*address = r.n.OnionAddress()
+	return
+}
+
+// RateLimits is RPC method
+func (r *RPC) RateLimits(_ struct{}, rls *RateLimits) (_ error) {
+	rls.Global = r.n.RateLimit()
+	rls.Peer = r.n.PeerRateLimit()
+	return
+}
+
+// SetRateLimits is RPC method
+func (r *RPC) SetRateLimits(rls RateLimits, _ *struct{}) (err error) {
+	if rls.Global.Up < 0 || rls.Global.Down < 0 ||
+		rls.Peer.Up < 0 || rls.Peer.Down < 0 {
+
+		return errors.New("negative rate limits")
+	}
+	r.n.SetRateLimit(rls.Global)
+	r.n.SetPeerRateLimit(rls.Peer)
+	return
+}
+
 // Config is RPC method
 func (r *RPC) Config(_ struct{}, config *Config) (err error) {
 	*config = *r.n.config // copy
@@ -152,6 +189,12 @@ func (r *RPC) Stat(_ struct{}, stat *Stat) (err error) {
 	return
 }
 
+// Stats is RPC method
+func (r *RPC) Stats(_ struct{}, stats *[]ConnStat) (err error) {
+	*stats = r.n.Stats()
+	return
+}
+
 // A TCPRPC represents RPC object
 // of TCP transport of the Node
 type TCPRPC struct {
@@ -311,10 +354,15 @@ type RootSelector struct {
 	Seq   uint64
 }
 
+// Root by selector
+func (r *RootRPC) root(rs RootSelector) (x *registry.Root, err error) {
+	return r.n.container(rs.Feed).Root(rs.Feed, rs.Nonce, rs.Seq)
+}
+
 // Show Root (RPC method)
 func (r *RootRPC) Show(rs RootSelector, z *registry.Root) (err error) {
 	var x *registry.Root
-	if x, err = r.n.c.Root(rs.Feed, rs.Nonce, rs.Seq); err != nil {
+	if x, err = r.root(rs); err != nil {
 		return
 	}
 
@@ -326,12 +374,12 @@ func (r *RootRPC) Show(rs RootSelector, z *registry.Root) (err error) {
 func (r *RootRPC) Tree(rs RootSelector, tree *string) (err error) {
 
 	var x *registry.Root
-	if x, err = r.n.c.Root(rs.Feed, rs.Nonce, rs.Seq); err != nil {
+	if x, err = r.root(rs); err != nil {
 		return
 	}
 
 	var p registry.Pack
-	if p, err = r.n.c.Pack(x, nil); err != nil {
+	if p, err = r.n.container(x.Pub).Pack(x, nil); err != nil {
 		return
 	}
 
@@ -341,10 +389,32 @@ func (r *RootRPC) Tree(rs RootSelector, tree *string) (err error) {
 	return
 }
 
+// Source returns Go source of types of
+// Registry of the Root (RPC method)
+func (r *RootRPC) Source(rs RootSelector, src *string) (err error) {
+
+	var x *registry.Root
+	if x, err = r.root(rs); err != nil {
+		return
+	}
+
+	var p registry.Pack
+	if p, err = r.n.container(x.Pub).Pack(x, nil); err != nil {
+		return
+	}
+
+	*src = p.Registry().GoSource("main")
+	return
+}
+
 // Last Root of given Feed (RPC method)
 func (r *RootRPC) Last(feed cipher.PubKey, z *registry.Root) (err error) {
-	var x *registry.Root
-	if x, err = r.n.c.LastRoot(feed, r.n.c.ActiveHead(feed)); err != nil {
+	var (
+		cc = r.n.container(feed)
+		x  *registry.Root
+	)
+
+	if x, err = cc.LastRoot(feed, cc.ActiveHead(feed)); err != nil {
 		return
 	}
 	*z = *x
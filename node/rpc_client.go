@@ -97,6 +97,40 @@ func (r *RPCClientNode) ConnectionsOfFeed(
 	return
 }
 
+// ExternalAddresses of the Node learned
+// from NAT gateway
+func (r *RPCClientNode) ExternalAddresses() (addresses []string, err error) {
+	err = r.r.c.Call("node.ExternalAddresses", struct{}{}, &addresses)
+	return
+}
+
+// RateLimits returns global bandwidth limits of
+// the Node and limits of every connection
+func (r *RPCClientNode) RateLimits() (rls RateLimits, err error) {
+	err = r.r.c.Call("node.RateLimits", struct{}{}, &rls)
+	return
+}
+
+// SetRateLimits changes global bandwidth limits of
+// the Node and limits of every connection
+func (r *RPCClientNode) SetRateLimits(rls RateLimits) (err error) {
+	return r.r.c.Call("node.SetRateLimits", rls, &struct{}{})
+}
+
+// ListeningAddresses of the Node
+// with schemes
+func (r *RPCClientNode) ListeningAddresses() (addresses []string, err error) {
+	err = r.r.c.Call("node.ListeningAddresses", struct{}{}, &addresses)
+	return
+}
+
+// OnionAddress of the Node or blank
+// string if it's not an onion service
+func (r *RPCClientNode) OnionAddress() (address string, err error) {
+	err = r.r.c.Call("node.OnionAddress", struct{}{}, &address)
+	return
+}
+
 // Config of the Node
 func (r *RPCClientNode) Config() (config *Config, err error) {
 	var c Config
@@ -115,6 +149,12 @@ func (r *RPCClientNode) Stat() (stat *Stat, err error) {
 	return &s, nil
 }
 
+// Stats obtains traffic statistic of connections of the Node
+func (r *RPCClientNode) Stats() (stats []ConnStat, err error) {
+	err = r.r.c.Call("node.Stats", struct{}{}, &stats)
+	return
+}
+
 // A RPCClientTCP implements RPC
 // methods related to TCP transport
 type RPCClientTCP struct {
@@ -248,6 +288,20 @@ func (r *RPCClientRoot) Tree(
 	return
 }
 
+// Source returns Go source of types of Registry
+// of Root object (see (*registry.Registry).GoSource)
+func (r *RPCClientRoot) Source(
+	feed cipher.PubKey,
+	nonce uint64,
+	seq uint64,
+) (
+	src string,
+	err error,
+) {
+	err = r.r.c.Call("root.Source", RootSelector{feed, nonce, seq}, &src)
+	return
+}
+
 // Last Root object
 func (r *RPCClientRoot) Last(
 	feed cipher.PubKey,
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestConn_RemoteSchemas(t *testing.T) {
+
+	var (
+		sn = getTestNode("sender")
+		rn = getTestNodeNotListen("receiver")
+	)
+
+	defer sn.Close()
+	defer rn.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		reg    = getTestRegistry()
+		sc     = sn.Container()
+
+		up  *skyobject.Unpack
+		err error
+	)
+
+	assertNil(t, sn.Share(pk))
+
+	if up, err = sc.Unpack(sk, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	// save the Root to save the Registry
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	if err = sc.Save(up, r); err != nil {
+		t.Fatal(err)
+	}
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var us registry.Schema
+	if us, err = reg.SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	var ss []registry.Schema
+	ss, err = c.RemoteSchemas(reg.Reference(), us.Reference())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ss) != 1 {
+		t.Fatal("wrong number of schemas", len(ss))
+	}
+
+	if ss[0].Reference() != us.Reference() || ss[0].Name() != "test.User" {
+		t.Error("wrong schema received", ss[0])
+	}
+
+	// missing schema
+
+	_, err = c.RemoteSchemas(reg.Reference(), registry.SchemaRef{1, 2, 3})
+	assertTrue(t, err != nil, "missing error")
+
+	// missing registry
+
+	_, err = c.RemoteSchemas(registry.RegistryRef{1, 2, 3}, us.Reference())
+	assertTrue(t, err != nil, "missing error")
+
+}
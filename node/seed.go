@@ -0,0 +1,46 @@
+package node
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// seed downloads and imports archive of a feed
+// from given URL and shares the feed (see
+// Config.Seeds), errors are logged
+func (n *Node) seed(url string) {
+
+	var r, err = n.seedFrom(url)
+
+	if err != nil {
+		n.Printf("[ERR] [seed] %s: %v", url, err)
+		return
+	}
+
+	n.Debugf(FeedPin, "[seed] %s: %s", url, r.Short())
+}
+
+func (n *Node) seedFrom(url string) (r *registry.Root, err error) {
+
+	var client = http.Client{Timeout: n.config.SeedTimeout}
+
+	var resp *http.Response
+	if resp, err = client.Get(url); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status %q", resp.Status)
+		return
+	}
+
+	if r, err = n.c.Import(resp.Body); err != nil {
+		return
+	}
+
+	err = n.Share(r.Pub)
+	return
+}
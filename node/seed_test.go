@@ -0,0 +1,66 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_seed(t *testing.T) {
+
+	var conf = getTestConfigNotListen("source")
+	conf.Gateway = "127.0.0.1:0"
+
+	var sn, err = NewNode(conf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(pk))
+
+	var (
+		c  = sn.Container()
+		up *skyobject.Unpack
+	)
+
+	up, err = c.Unpack(sk, getTestRegistry())
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021
+	r.Pub = pk
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}))
+
+	assertNil(t, c.Save(up, r))
+
+	var url = "http://" + sn.GatewayAddress() + "/feeds/" + pk.Hex()
+
+	conf = getTestConfigNotListen("seeded")
+	conf.Seeds = Addresses{
+		url + "/latest",  // not an archive (logged and skipped)
+		url + "/archive", // the archive
+	}
+
+	var rn *Node
+	rn, err = NewNode(conf)
+	assertNil(t, err)
+	defer rn.Close()
+
+	assertTrue(t, rn.IsSharing(pk) == true, "not sharing")
+
+	var lr *registry.Root
+	lr, err = rn.Container().LastRoot(pk, rn.Container().ActiveHead(pk))
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "wrong Root")
+
+	var val []byte
+	val, _, err = rn.Container().Get(r.Refs[0].Hash, 0)
+	assertNil(t, err)
+	assertTrue(t, cipher.SumSHA256(val) == r.Refs[0].Hash, "wrong object")
+
+}
@@ -0,0 +1,354 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// classes of sent messages in order of priority
+const (
+	sendControl int = iota // requests and replies
+	sendRoot               // Root and RootDelta
+	sendData               // big objects (see Config.SmallObjectSize)
+
+	sendClasses // number of classes
+)
+
+// time to wait for sending loop of closed Conn,
+// before the connection is closed (see waitSending)
+const sendingStopTimeout = 5 * time.Second
+
+// A QueuePolicy is what a Conn does when a send
+// queue of the Conn is full (see Config.SendQueueLen)
+type QueuePolicy string
+
+// send queue policies
+const (
+	// QueueBlock is wait until there is room in the
+	// queue; a slow peer slows down the Node
+	QueueBlock QueuePolicy = "block"
+	// QueueDropOldestData is drop oldest bulk data
+	// (objects bigger then Config.SmallObjectSize);
+	// a chunked object (see msg.DataChunk) is dropped
+	// entirely, and the peer requests it again; other
+	// queues block
+	QueueDropOldestData QueuePolicy = "drop-oldest-data"
+	// QueueDisconnect is close the Conn with
+	// ErrSendQueueFull
+	QueueDisconnect QueuePolicy = "disconnect"
+)
+
+// Validate the QueuePolicy
+func (q QueuePolicy) Validate() (err error) {
+	switch q {
+	case QueueBlock, QueueDropOldestData, QueueDisconnect:
+	default:
+		err = fmt.Errorf("invalid QueuePolicy %q", string(q))
+	}
+	return
+}
+
+// SendWeights are weights of classes of sent messages.
+// A Conn sends up to Control messages, then up to Root
+// messages, and then up to Data messages, and repeats.
+// Empty queues are skipped. Thus, Root objects and
+// control messages preempt bulk data, but the bulk data
+// never starves
+type SendWeights struct {
+	Control int // requests and replies, including small objects
+	Root    int // Root objects
+	Data    int // objects bigger then Config.SmallObjectSize
+}
+
+// Validate the SendWeights
+func (s *SendWeights) Validate() (err error) {
+	if s.Control <= 0 || s.Root <= 0 || s.Data <= 0 {
+		err = fmt.Errorf("invalid SendWeights: %d/%d/%d, must be positive",
+			s.Control, s.Root, s.Data)
+	}
+	return
+}
+
+// weights by class
+func (s *SendWeights) weights() [sendClasses]int {
+	return [sendClasses]int{s.Control, s.Root, s.Data}
+}
+
+// class of given message
+func (c *Conn) sendClass(m msg.Msg) int {
+
+	switch x := m.(type) {
+	case *msg.Root, *msg.RootDelta:
+		return sendRoot
+	case *msg.Object:
+		if len(x.Value) > c.n.config.SmallObjectSize {
+			return sendData
+		}
+	}
+
+	return sendControl
+}
+
+// an encoded message in a send queue
+type queued struct {
+	raw  []byte // the message
+	rseq uint32 // request of chunked object or zero
+}
+
+// per-class send queues of a Conn
+type sendQueue struct {
+	mx sync.Mutex
+
+	qs [sendClasses][]queued // by class
+
+	ready chan struct{} // something queued
+	room  chan struct{} // closed when something taken, if full
+	full  bool          // a producer waits for the room
+
+	chunking map[uint32]bool // rseq -> dropped, objects being chunked
+	drops    int64           // dropped messages
+}
+
+func newSendQueue() (sq *sendQueue) {
+	sq = new(sendQueue)
+	sq.ready = make(chan struct{}, 1)
+	sq.room = make(chan struct{})
+	sq.chunking = make(map[uint32]bool)
+	return
+}
+
+// take next message of given class, if any
+func (sq *sendQueue) take(class int) (raw []byte, ok bool) {
+	sq.mx.Lock()
+	defer sq.mx.Unlock()
+
+	var q = sq.qs[class]
+
+	if len(q) == 0 {
+		return
+	}
+
+	raw, ok = q[0].raw, true
+	q[0] = queued{} // GC
+	sq.qs[class] = q[1:]
+
+	if sq.full == true {
+		close(sq.room)
+		sq.room = make(chan struct{})
+		sq.full = false
+	}
+
+	return
+}
+
+// drop oldest bulk data; if it's a chunk, then the
+// whole object is dropped; the sq.mx must be locked
+func (sq *sendQueue) dropOldest() {
+
+	var (
+		q    = sq.qs[sendData]
+		rseq = q[0].rseq
+		keep = q[:0]
+	)
+
+	sq.drops++
+
+	for i, qd := range q[1:] {
+		if rseq != 0 && qd.rseq == rseq {
+			sq.drops++
+			continue
+		}
+		keep = append(keep, q[1+i])
+	}
+
+	for i := len(keep); i < len(q); i++ {
+		q[i] = queued{} // GC
+	}
+
+	sq.qs[sendData] = keep
+
+	if _, ok := sq.chunking[rseq]; ok == true && rseq != 0 {
+		sq.chunking[rseq] = true // don't enqueue the rest
+	}
+
+}
+
+// chunks of object requested by given rseq
+// are being enqueued
+func (sq *sendQueue) startChunks(rseq uint32) {
+	sq.mx.Lock()
+	defer sq.mx.Unlock()
+
+	sq.chunking[rseq] = false
+}
+
+// all chunks of object requested by given
+// rseq enqueued or dropped
+func (sq *sendQueue) stopChunks(rseq uint32) {
+	sq.mx.Lock()
+	defer sq.mx.Unlock()
+
+	delete(sq.chunking, rseq)
+}
+
+// QueueDrops returns number of messages the Conn
+// dropped because of full send queue (see
+// Config.SendQueuePolicy)
+func (c *Conn) QueueDrops() int64 {
+	c.sq.mx.Lock()
+	defer c.sq.mx.Unlock()
+
+	return c.sq.drops
+}
+
+// put encoded message to queue of given class
+func (c *Conn) enqueue(class int, raw []byte) {
+	c.push(class, 0, raw)
+}
+
+// put encoded message to queue of given class
+// using Config.SendQueuePolicy if the queue is full;
+// the rseq is request of chunked object or zero; it
+// returns false if the message has been dropped
+func (c *Conn) push(class int, rseq uint32, raw []byte) (ok bool) {
+
+	var (
+		sq     = c.sq
+		max    = c.n.config.SendQueueLen
+		policy = c.n.config.SendQueuePolicy
+	)
+
+	for {
+
+		sq.mx.Lock()
+
+		if rseq != 0 && sq.chunking[rseq] == true {
+			sq.mx.Unlock()
+			return false // the object has been dropped
+		}
+
+		if len(sq.qs[class]) >= max {
+
+			switch {
+
+			case policy == QueueDisconnect:
+				sq.drops++
+				sq.mx.Unlock()
+				go c.close(ErrSendQueueFull) // can be called by receiving
+				return false
+
+			case policy == QueueDropOldestData && class == sendData:
+				sq.dropOldest()
+
+			default:
+				sq.full = true
+				var room = sq.room
+				sq.mx.Unlock()
+
+				select {
+				case <-room:
+					continue // try again
+				case <-c.closeq:
+					return false
+				}
+
+			}
+
+		}
+
+		sq.qs[class] = append(sq.qs[class], queued{raw: raw, rseq: rseq})
+		sq.mx.Unlock()
+
+		select {
+		case sq.ready <- struct{}{}:
+		default: // already signaled
+		}
+
+		return true
+	}
+
+}
+
+// write encoded message to the connection; the
+// Out channel of the connection is closed by the
+// connection itself, when it's closed by remote
+// peer or by the factory, thus the message is
+// written directly, and a closed connection
+// returns error
+func (c *Conn) write(raw []byte) (ok bool) {
+
+	if err := c.Connection.Write(raw); err != nil {
+		c.n.Debugf(ConnPin, "[%s] write: %v", c.String(), err)
+		return false // closed
+	}
+
+	return true
+}
+
+// send queued messages using weighted round-robin
+func (c *Conn) sending() {
+	defer c.await.Done()
+	defer close(c.sendoq)
+
+	var weights = c.n.config.SendWeights.weights()
+
+	for {
+
+		var sent bool
+
+		for class := 0; class < sendClasses; class++ {
+
+			for i := 0; i < weights[class]; i++ {
+
+				var raw, ok = c.sq.take(class)
+
+				if ok == false {
+					break // empty
+				}
+
+				if c.throttle(true, len(raw)) == false {
+					return // closed
+				}
+
+				if c.write(raw) == false {
+					return // closed
+				}
+
+				sent = true
+			}
+
+		}
+
+		if sent == true {
+			continue
+		}
+
+		// all queues are empty, wait any
+
+		select {
+		case <-c.sq.ready:
+		case <-c.closeq:
+			return
+		}
+
+	}
+
+}
+
+// wait for the sending loop, stopped by the closeq,
+// before the connection is closed; a write to stalled
+// peer can block, and closed connection unblocks it,
+// thus the wait is limited; a Conn closed before the
+// run waits the sendingStopTimeout
+func (c *Conn) waitSending() {
+	var tm = time.NewTimer(sendingStopTimeout)
+	defer tm.Stop()
+
+	select {
+	case <-c.sendoq:
+	case <-tm.C:
+	}
+}
@@ -0,0 +1,254 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/net/conn"
+	"github.com/skycoin/net/factory"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestSendWeights_Validate(t *testing.T) {
+
+	var s = SendWeights{1, 1, 1}
+
+	if err := s.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	for _, s = range []SendWeights{
+		{0, 1, 1},
+		{1, 0, 1},
+		{1, 1, -1},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("missing error for %v", s)
+		}
+	}
+
+}
+
+// connection that writes to a channel
+type chanConn struct {
+	conn.Connection
+	out chan []byte
+}
+
+func (c *chanConn) Write(p []byte) error {
+	c.out <- p
+	return nil
+}
+
+func TestConn_sending(t *testing.T) {
+
+	var conf = NewConfig()
+	conf.SendWeights = SendWeights{Control: 2, Root: 1, Data: 1}
+
+	var (
+		n     = &Node{config: conf}
+		c     = &Conn{n: n}
+		sendq = make(chan []byte, 16)
+	)
+
+	c.Connection = &factory.Connection{Connection: &chanConn{out: sendq}}
+	c.closeq = make(chan struct{})
+	c.sendoq = make(chan struct{})
+
+	c.sq = newSendQueue()
+
+	for _, s := range []string{"c1", "c2", "c3"} {
+		c.enqueue(sendControl, []byte(s))
+	}
+	for _, s := range []string{"r1", "r2"} {
+		c.enqueue(sendRoot, []byte(s))
+	}
+	for _, s := range []string{"d1", "d2"} {
+		c.enqueue(sendData, []byte(s))
+	}
+
+	c.await.Add(1)
+	go c.sending()
+
+	defer c.await.Wait()
+	defer close(c.closeq)
+
+	for _, want := range []string{"c1", "c2", "r1", "d1", "c3", "r2", "d2"} {
+		if got := string(<-sendq); got != want {
+			t.Errorf("wrong order: want %q, got %q", want, got)
+		}
+	}
+
+}
+
+func TestQueuePolicy_Validate(t *testing.T) {
+
+	for _, q := range []QueuePolicy{
+		QueueBlock,
+		QueueDropOldestData,
+		QueueDisconnect,
+	} {
+		if err := q.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := QueuePolicy("drop-all").Validate(); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+// Conn with send queue without the sending
+func getTestQueueConn(policy QueuePolicy) (c *Conn) {
+
+	var conf = NewConfig()
+	conf.SendQueueLen = 2
+	conf.SendQueuePolicy = policy
+
+	c = &Conn{n: &Node{config: conf}}
+	c.closeq = make(chan struct{})
+	c.sq = newSendQueue()
+	return
+}
+
+// queued messages of given class
+func queuedOf(c *Conn, class int) (got []string) {
+	for {
+		var raw, ok = c.sq.take(class)
+		if ok == false {
+			return
+		}
+		got = append(got, string(raw))
+	}
+}
+
+func TestConn_push(t *testing.T) {
+
+	t.Run("block", func(t *testing.T) {
+
+		var c = getTestQueueConn(QueueBlock)
+
+		c.enqueue(sendData, []byte("d1"))
+		c.enqueue(sendData, []byte("d2"))
+
+		var done = make(chan struct{})
+
+		go func() {
+			defer close(done)
+			c.enqueue(sendData, []byte("d3"))
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("not blocked")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		if raw, _ := c.sq.take(sendData); string(raw) != "d1" {
+			t.Errorf("wrong message taken: %q", raw)
+		}
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("still blocked")
+		}
+
+		if got := queuedOf(c, sendData); len(got) != 2 || got[1] != "d3" {
+			t.Errorf("wrong queue: %q", got)
+		}
+
+	})
+
+	t.Run("drop oldest data", func(t *testing.T) {
+
+		var c = getTestQueueConn(QueueDropOldestData)
+
+		c.enqueue(sendData, []byte("d1"))
+		c.enqueue(sendData, []byte("d2"))
+		c.enqueue(sendData, []byte("d3"))
+
+		if got := queuedOf(c, sendData); len(got) != 2 ||
+			got[0] != "d2" || got[1] != "d3" {
+
+			t.Errorf("wrong queue: %q", got)
+		}
+
+		if c.QueueDrops() != 1 {
+			t.Errorf("wrong number of drops: %d", c.QueueDrops())
+		}
+
+	})
+
+	t.Run("drop chunked object", func(t *testing.T) {
+
+		var c = getTestQueueConn(QueueDropOldestData)
+
+		c.sq.startChunks(1)
+
+		if c.push(sendData, 1, []byte("c1")) == false ||
+			c.push(sendData, 1, []byte("c2")) == false {
+
+			t.Fatal("dropped")
+		}
+
+		c.enqueue(sendData, []byte("d1")) // drops all chunks
+
+		if c.push(sendData, 1, []byte("c3")) == true {
+			t.Error("rest of dropped object enqueued")
+		}
+
+		c.sq.stopChunks(1)
+
+		if got := queuedOf(c, sendData); len(got) != 1 || got[0] != "d1" {
+			t.Errorf("wrong queue: %q", got)
+		}
+
+		if c.QueueDrops() != 2 {
+			t.Errorf("wrong number of drops: %d", c.QueueDrops())
+		}
+
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+
+		var sn, cn = getTestNode("server"), getTestNodeNotListen("client")
+		defer sn.Close()
+		defer cn.Close()
+
+		cn.config.SendQueueLen = 1
+		cn.config.SendQueuePolicy = QueueDisconnect
+
+		var c, err = cn.TCP().Connect(sn.TCP().Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ping = c.encodeMsg(c.nextSeq(), 0, &msg.Ping{})
+
+		// fill up the queue faster then it's sent
+	fill:
+		for i := 0; i < 1000000; i++ {
+			select {
+			case <-c.closeq:
+				break fill
+			default:
+				c.enqueue(sendControl, ping)
+			}
+		}
+
+		select {
+		case <-c.closeq:
+		case <-time.After(time.Second):
+			t.Fatal("not closed")
+		}
+
+		if c.QueueDrops() == 0 {
+			t.Error("closed, but not by the policy")
+		}
+
+	})
+
+}
@@ -0,0 +1,151 @@
+package node
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// sort feeds by bytes
+func sortFeeds(feeds []cipher.PubKey) {
+	sort.Slice(feeds, func(i, j int) bool {
+		return bytes.Compare(feeds[i][:], feeds[j][:]) < 0
+	})
+}
+
+func equalFeeds(a, b []cipher.PubKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RemoteWantFeeds returns feeds the remote peer of
+// the connection is ready to serve. The list is last
+// list announced by the peer using the WantFeeds
+// message. The result is sorted
+func (c *Conn) RemoteWantFeeds() (feeds []cipher.PubKey) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if len(c.want) == 0 {
+		return
+	}
+
+	feeds = make([]cipher.PubKey, 0, len(c.want))
+	for pk := range c.want {
+		feeds = append(feeds, pk)
+	}
+
+	sortFeeds(feeds)
+	return
+}
+
+// SharedFeeds returns feeds that both this Node and
+// the remote peer of the connection are ready to serve.
+// Unlike the Feeds method, the connection doesn't need
+// to be subscribed to the feeds. The result is sorted.
+// Use OnSharedFeeds callback of the Config to get
+// notifications when the set changes
+func (c *Conn) SharedFeeds() (feeds []cipher.PubKey) {
+
+	var local = c.n.Feeds()
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.sharedFeeds(local)
+}
+
+// intersection, c.mx should be locked
+func (c *Conn) sharedFeeds(local []cipher.PubKey) (feeds []cipher.PubKey) {
+
+	for _, pk := range local {
+		if _, ok := c.want[pk]; ok == true {
+			feeds = append(feeds, pk)
+		}
+	}
+
+	sortFeeds(feeds)
+	return
+}
+
+// update last known shared feeds calling the
+// OnSharedFeeds callback if the set changed
+func (c *Conn) updateSharedFeeds() {
+
+	var local = c.n.Feeds()
+
+	c.mx.Lock()
+
+	var feeds = c.sharedFeeds(local)
+
+	if equalFeeds(feeds, c.shared) == true {
+		c.mx.Unlock()
+		return
+	}
+
+	c.shared = feeds
+	c.mx.Unlock()
+
+	c.n.onSharedFeeds(c, feeds)
+}
+
+// send list of feeds the Node is ready to serve,
+// if the remote peer supports shared feeds
+func (c *Conn) sendWantFeeds(feeds []cipher.PubKey) {
+	if c.HasFeature(msg.FeatureSharedFeeds) == false {
+		return
+	}
+	c.sendMsg(c.nextSeq(), 0, &msg.WantFeeds{Feeds: feeds})
+}
+
+// <- WantFeeds (feeds)
+func (c *Conn) handleWantFeeds(wf *msg.WantFeeds) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleWantFeeds %d",
+		c.String(), len(wf.Feeds))
+
+	var want = make(map[cipher.PubKey]struct{}, len(wf.Feeds))
+
+	for _, pk := range wf.Feeds {
+		want[pk] = struct{}{}
+	}
+
+	c.mx.Lock()
+	c.want = want
+	c.mx.Unlock()
+
+	c.updateSharedFeeds()
+	return
+}
+
+// send WantFeeds to all connections
+// after Share or DontShare
+func (n *Node) announceFeeds() {
+
+	var feeds = n.Feeds()
+
+	for _, c := range n.Connections() {
+		c.sendWantFeeds(feeds)
+		c.updateSharedFeeds()
+	}
+
+}
+
+func (n *Node) onSharedFeeds(c *Conn, feeds []cipher.PubKey) {
+
+	if osf := n.config.OnSharedFeeds; osf != nil {
+		osf(c, feeds)
+	}
+
+}
@@ -0,0 +1,93 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func assertFeeds(t *testing.T, got []cipher.PubKey, want ...cipher.PubKey) {
+	t.Helper()
+
+	sortFeeds(want)
+
+	if equalFeeds(got, want) == false {
+		t.Errorf("wrong feeds: want %v, got %v", want, got)
+	}
+}
+
+func TestConn_SharedFeeds(t *testing.T) {
+
+	var (
+		sconf  = getTestConfig("server")
+		shared = make(chan []cipher.PubKey, 10)
+	)
+
+	sconf.OnSharedFeeds = func(_ *Conn, feeds []cipher.PubKey) {
+		shared <- feeds
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var (
+		pka, _ = cipher.GenerateKeyPair()
+		pkb, _ = cipher.GenerateKeyPair()
+		pkc, _ = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sn.Share(pka))
+	assertNil(t, sn.Share(pkb))
+
+	assertNil(t, cn.Share(pkb))
+	assertNil(t, cn.Share(pkc))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var wait = func(want ...cipher.PubKey) {
+		t.Helper()
+
+		select {
+		case feeds := <-shared:
+			assertFeeds(t, feeds, want...)
+		case <-time.After(TM):
+			t.Fatal("slow")
+		}
+	}
+
+	wait(pkb)
+
+	var sc = sn.Connections()
+	if len(sc) != 1 {
+		t.Fatal("wrong number of connections", len(sc))
+	}
+
+	assertFeeds(t, sc[0].SharedFeeds(), pkb)
+	assertFeeds(t, sc[0].RemoteWantFeeds(), pkb, pkc)
+
+	// share by server
+	assertNil(t, sn.Share(pkc))
+	wait(pkb, pkc)
+
+	// don't share by client
+	assertNil(t, cn.DontShare(pkb))
+	wait(pkc)
+
+	assertFeeds(t, sc[0].SharedFeeds(), pkc)
+
+	// the client has been notified too
+	time.Sleep(TM / 10)
+	assertFeeds(t, c.SharedFeeds(), pkc)
+	assertFeeds(t, c.RemoteWantFeeds(), pka, pkb, pkc)
+
+}
@@ -0,0 +1,128 @@
+package node
+
+import (
+	"errors"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// subscribe with filter (with reply)
+func (c *Conn) handleSubFilter(seq uint32, sf *msg.SubFilter) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleSubFilter %s %s %v",
+		c.String(), sf.Feed.Hex()[:7], time.Duration(sf.Lease), sf.Schemas)
+
+	if sf.Feed == (cipher.PubKey{}) {
+		return errors.New("blank public key") // fatal (invalid request)
+	}
+
+	if len(sf.Schemas) == 0 {
+		return errors.New("blank filter") // fatal (invalid request)
+	}
+
+	var lease = time.Duration(sf.Lease)
+
+	if lease < 0 {
+		return errors.New("invalid lease") // fatal (invalid request)
+	}
+
+	if max := c.n.config.MaxSubLease; max > 0 && lease > max {
+		c.sendErr(seq, ErrLeaseTooLong)
+		return
+	}
+
+	if lease == 0 && c.n.config.RequireSubLease == true {
+		c.sendErr(seq, ErrLeaseRequired)
+		return
+	}
+
+	// set the filter before, since
+	// a Root can be sent at the moment
+	c.setRemoteFilter(sf.Feed, sf.Schemas)
+
+	if c.subscribeRemote(seq, sf.Feed) == false {
+		c.setRemoteFilter(sf.Feed, nil)
+		return
+	}
+
+	if lease > 0 {
+		c.setLease(sf.Feed, lease)
+	} else {
+		c.delLease(sf.Feed) // never expires
+	}
+
+	return
+}
+
+// set or remove filter of remote peer
+func (c *Conn) setRemoteFilter(feed cipher.PubKey, schemas []string) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if len(schemas) == 0 {
+		delete(c.filters, feed)
+		return
+	}
+
+	if c.filters == nil {
+		c.filters = make(map[cipher.PubKey][]string)
+	}
+
+	c.filters[feed] = schemas
+}
+
+// RemoteFilter returns schema filter of given feed
+// of remote peer, or nil if the peer fills Root
+// objects of the feed fully (see msg.SubFilter)
+func (c *Conn) RemoteFilter(feed cipher.PubKey) (schemas []string) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return append([]string(nil), c.filters[feed]...)
+}
+
+// is remote peer fills Root objects
+// of given feed partially
+func (c *Conn) isRemoteFiltered(feed cipher.PubKey) (ok bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	_, ok = c.filters[feed]
+	return
+}
+
+// send subscription request again, since filter
+// of given feed has been changed (async)
+func (c *Conn) resubscribe(feed cipher.PubKey) {
+	defer c.await.Done()
+
+	if c.HasFeature(msg.FeatureFilter) == false {
+		return // the peer knows nothing about filters
+	}
+
+	var reply, err = c.sendRequest(c.subRequest(feed))
+
+	if err == nil {
+		switch x := reply.(type) {
+		case *msg.Ok:
+		case *msg.Err:
+			err = remoteError(x.Err)
+		default:
+			err = ErrInvalidResponse
+		}
+	}
+
+	if err != nil {
+		c.n.Printf("[ERR] [%s] can't send filter of %s: %v", c.String(),
+			feed.Hex()[:7], err)
+		return
+	}
+
+	c.n.Debugf(FeedPin, "[%s] sent filter of %s", c.String(), feed.Hex()[:7])
+}
@@ -0,0 +1,226 @@
+package node
+
+import (
+	"errors"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// lease of subscriptions to feeds of remote peer, zero
+// if this Node doesn't use leases (see Config.SubLease)
+// or the peer doesn't support them
+func (c *Conn) lease() time.Duration {
+	if c.HasFeature(msg.FeatureSubLease) == false {
+		return 0
+	}
+	return c.n.config.SubLease
+}
+
+// subscription request, the SubFilter if the feed
+// is filtered (see SetFilter), the SubLease if the
+// Conn uses leases, or the Sub
+func (c *Conn) subRequest(feed cipher.PubKey) msg.Msg {
+	if schemas := c.n.ff.get(feed); schemas != nil &&
+		c.HasFeature(msg.FeatureFilter) == true {
+
+		return &msg.SubFilter{
+			Feed:    feed,
+			Lease:   int64(c.lease()),
+			Schemas: schemas,
+		}
+	}
+	if lease := c.lease(); lease > 0 {
+		return &msg.SubLease{Feed: feed, Lease: int64(lease)}
+	}
+	return &msg.Sub{Feed: feed}
+}
+
+// schedule renewal of lease of given feed
+// if the Conn uses leases
+func (c *Conn) scheduleRenewal(feed cipher.PubKey) {
+
+	var lease = c.lease()
+
+	if lease <= 0 {
+		return
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.renew == nil {
+		c.renew = make(map[cipher.PubKey]*time.Timer)
+	}
+
+	if t, ok := c.renew[feed]; ok == true {
+		t.Reset(lease / 2)
+		return
+	}
+
+	c.renew[feed] = time.AfterFunc(lease/2, func() { c.renewLease(feed) })
+}
+
+// stop renewal of lease of given feed
+func (c *Conn) stopRenewal(feed cipher.PubKey) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if t, ok := c.renew[feed]; ok == true {
+		t.Stop()
+		delete(c.renew, feed)
+	}
+}
+
+// renew lease of given feed, if the renewal
+// fails, then the Conn unsubscribes from the
+// feed of remote peer
+func (c *Conn) renewLease(feed cipher.PubKey) {
+
+	select {
+	case <-c.closeq:
+		return
+	default:
+	}
+
+	if c.n.fs.hasConnFeed(c, feed) == false {
+		c.stopRenewal(feed)
+		return
+	}
+
+	var reply, err = c.sendRequest(c.subRequest(feed))
+
+	if err == nil {
+		switch x := reply.(type) {
+		case *msg.Ok:
+		case *msg.Err:
+			err = remoteError(x.Err)
+		default:
+			err = ErrInvalidResponse
+		}
+	}
+
+	if err != nil {
+
+		select {
+		case <-c.closeq:
+			return // closed
+		default:
+		}
+
+		c.n.Printf("[ERR] [%s] can't renew lease of %s: %v", c.String(),
+			feed.Hex()[:7], err)
+
+		c.Unsubscribe(feed)
+		return
+	}
+
+	c.n.Debugf(FeedPin, "[%s] renewed lease of %s", c.String(),
+		feed.Hex()[:7])
+
+	c.scheduleRenewal(feed)
+}
+
+// subscribe with lease (with reply)
+func (c *Conn) handleSubLease(seq uint32, sl *msg.SubLease) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleSubLease %s %s",
+		c.String(), sl.Feed.Hex()[:7], time.Duration(sl.Lease))
+
+	if sl.Feed == (cipher.PubKey{}) {
+		return errors.New("blank public key") // fatal (invalid request)
+	}
+
+	var lease = time.Duration(sl.Lease)
+
+	if lease <= 0 {
+		return errors.New("invalid lease") // fatal (invalid request)
+	}
+
+	if max := c.n.config.MaxSubLease; max > 0 && lease > max {
+		c.sendErr(seq, ErrLeaseTooLong)
+		return
+	}
+
+	if c.subscribeRemote(seq, sl.Feed) == true {
+		c.setRemoteFilter(sl.Feed, nil) // full
+		c.setLease(sl.Feed, lease)
+	}
+
+	return
+}
+
+// set or extend lease of subscription of remote peer
+func (c *Conn) setLease(feed cipher.PubKey, lease time.Duration) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.leases == nil {
+		c.leases = make(map[cipher.PubKey]*time.Timer)
+	}
+
+	if t, ok := c.leases[feed]; ok == true {
+		t.Reset(lease)
+		return
+	}
+
+	c.leases[feed] = time.AfterFunc(lease, func() { c.expireLease(feed) })
+}
+
+// remove lease of given feed
+func (c *Conn) delLease(feed cipher.PubKey) (ok bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var t *time.Timer
+	if t, ok = c.leases[feed]; ok == true {
+		t.Stop()
+		delete(c.leases, feed)
+	}
+
+	return
+}
+
+// the lease has not been renewed
+func (c *Conn) expireLease(feed cipher.PubKey) {
+
+	select {
+	case <-c.closeq:
+		return
+	default:
+	}
+
+	if c.delLease(feed) == false {
+		return // unsubscribed
+	}
+
+	c.n.Debugf(FeedPin, "[%s] lease of %s expired", c.String(),
+		feed.Hex()[:7])
+
+	c.n.fs.delConnFeed(c, feed)
+	c.setRemoteFilter(feed, nil)
+	c.delCredit(feed)
+	c.unsubscribe(feed) // notify peer
+}
+
+// stop all timers of leases
+func (c *Conn) stopLeases() {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, t := range c.leases {
+		t.Stop()
+	}
+
+	for _, t := range c.renew {
+		t.Stop()
+	}
+
+	c.leases, c.renew = nil, nil
+}
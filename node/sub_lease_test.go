@@ -0,0 +1,149 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestConn_SubLease(t *testing.T) {
+
+	const lease = 100 * time.Millisecond
+
+	var sconf = getTestConfig("server")
+	sconf.MaxSubLease = time.Second
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cconf = getTestConfigNotListen("client")
+	cconf.SubLease = lease
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var feed, _ = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(feed))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(feed))
+
+	var subscribed = func() bool {
+		return len(sn.ConnectionsOfFeed(feed)) == 1
+	}
+
+	assertTrue(t, subscribed() == true, "not subscribed")
+
+	time.Sleep(3 * lease) // renewed
+	assertTrue(t, subscribed() == true, "lease has not been renewed")
+
+	c.stopRenewal(feed) // zombie
+	time.Sleep(2 * lease)
+
+	assertTrue(t, subscribed() == false, "lease has not expired")
+	assertTrue(t, len(cn.ConnectionsOfFeed(feed)) == 0,
+		"the client is not notified")
+
+	// too long lease
+
+	cn.config.SubLease = 2 * time.Second
+
+	if err = c.Subscribe(feed); errors.Is(err, ErrLeaseTooLong) == false {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestConn_SubLease_Sub(t *testing.T) {
+
+	const lease = 100 * time.Millisecond
+
+	var sn, err = NewNode(getTestConfig("server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cconf = getTestConfigNotListen("client")
+	cconf.SubLease = lease
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var feed, _ = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(feed))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(feed)) // SubLease
+
+	var cs = sn.ConnectionsOfFeed(feed)
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+
+	c.stopRenewal(feed)
+	cn.config.SubLease = 0
+
+	assertNil(t, c.Subscribe(feed)) // Sub
+
+	if cs[0].delLease(feed) == true {
+		t.Error("lease has not been removed by Sub")
+	}
+
+	time.Sleep(2 * lease)
+	assertTrue(t, len(sn.ConnectionsOfFeed(feed)) == 1,
+		"subscription without lease expired")
+
+}
+
+func TestConfig_RequireSubLease(t *testing.T) {
+
+	var sconf = getTestConfig("server")
+	sconf.RequireSubLease = true
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn = getTestNodeNotListen("client")
+	defer cn.Close()
+
+	var feed, _ = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(feed))
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Subscribe(feed); errors.Is(err, ErrLeaseRequired) == false {
+		t.Error("wrong error:", err)
+	}
+
+	assertTrue(t, len(sn.ConnectionsOfFeed(feed)) == 0, "subscribed")
+
+	cn.config.SubLease = time.Second
+	assertNil(t, c.Subscribe(feed))
+
+}
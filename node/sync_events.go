@@ -0,0 +1,180 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// size of buffer of the SyncEvents channel
+const syncEventsBuffer = 128
+
+// A SyncEvent represents brief statistic of
+// filling of a feed. The SyncEvent is designed
+// for dashboards and similar. It is cheap to
+// collect and it is sent once per configured
+// interval (see Config.SyncEvents), instead of
+// per-message events
+type SyncEvent struct {
+	Feed cipher.PubKey // the feed
+
+	ObjectsPerSecond float64 // received objects per second
+	BytesPerSecond   float64 // received bytes per second
+
+	Queued     int // objects waiting to be requested
+	Requesting int // objects being requested right now
+}
+
+// queue depths of a head
+type syncQueue struct {
+	queued     int
+	requesting int
+}
+
+// statistic of a feed for current interval
+type syncFeed struct {
+	objects int
+	bytes   int
+
+	queues map[*nodeHead]syncQueue
+}
+
+// collects statistic and sends SyncEvents
+type syncEvents struct {
+	mx sync.Mutex
+
+	fs map[cipher.PubKey]*syncFeed
+
+	interval time.Duration
+	evq      chan SyncEvent
+}
+
+func newSyncEvents(interval time.Duration) (s *syncEvents) {
+	s = new(syncEvents)
+	s.fs = make(map[cipher.PubKey]*syncFeed)
+	s.interval = interval
+	s.evq = make(chan SyncEvent, syncEventsBuffer)
+	return
+}
+
+// call it under lock
+func (s *syncEvents) feed(pk cipher.PubKey) (sf *syncFeed) {
+	var ok bool
+	if sf, ok = s.fs[pk]; ok == false {
+		sf = &syncFeed{queues: make(map[*nodeHead]syncQueue)}
+		s.fs[pk] = sf
+	}
+	return
+}
+
+// received object (nil-safe)
+func (s *syncEvents) addObject(pk cipher.PubKey, size int) {
+
+	if s == nil {
+		return // disabled
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var sf = s.feed(pk)
+
+	sf.objects++
+	sf.bytes += size
+}
+
+// queue depths of a head (nil-safe)
+func (s *syncEvents) setQueue(
+	pk cipher.PubKey,
+	nh *nodeHead,
+	queued int,
+	requesting int,
+) {
+
+	if s == nil {
+		return // disabled
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var sf = s.feed(pk)
+
+	if queued == 0 && requesting == 0 {
+		delete(sf.queues, nh)
+		return
+	}
+
+	sf.queues[nh] = syncQueue{queued, requesting}
+}
+
+// collect events for current interval and reset counters;
+// idle feeds are not reported and removed
+func (s *syncEvents) collect() (evs []SyncEvent) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var secs = s.interval.Seconds()
+
+	for pk, sf := range s.fs {
+
+		if sf.objects == 0 && len(sf.queues) == 0 {
+			delete(s.fs, pk) // idle
+			continue
+		}
+
+		var ev = SyncEvent{
+			Feed:             pk,
+			ObjectsPerSecond: float64(sf.objects) / secs,
+			BytesPerSecond:   float64(sf.bytes) / secs,
+		}
+
+		for _, sq := range sf.queues {
+			ev.Queued += sq.queued
+			ev.Requesting += sq.requesting
+		}
+
+		evs = append(evs, ev)
+
+		sf.objects, sf.bytes = 0, 0
+	}
+
+	return
+}
+
+func (s *syncEvents) run(closeq <-chan struct{}) {
+
+	var tk = time.NewTicker(s.interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			for _, ev := range s.collect() {
+				select {
+				case s.evq <- ev:
+				default:
+					// drop the event, since nobody reads them
+				}
+			}
+		case <-closeq:
+			return
+		}
+	}
+
+}
+
+// SyncEvents returns channel of SyncEvents. The
+// channel is nil if the SyncEvents disabled by
+// configurations (see Config.SyncEvents). The
+// Node never blocks sending events, and if the
+// channel is full then new events are dropped.
+// The channel is never closed
+func (n *Node) SyncEvents() <-chan SyncEvent {
+	if n.se == nil {
+		return nil
+	}
+	return n.se.evq
+}
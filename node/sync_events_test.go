@@ -0,0 +1,76 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestNode_SyncEvents(t *testing.T) {
+	// (<-chan SyncEvent)
+
+	var n = getTestNodeNotListen("test")
+	defer n.Close()
+
+	assertTrue(t, n.SyncEvents() == nil, "SyncEvents not disabled by default")
+
+	var conf = getTestConfigNotListen("test")
+	conf.SyncEvents = 10 * time.Millisecond
+
+	var err error
+	n, err = NewNode(conf)
+	assertNil(t, err)
+	defer n.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	n.se.addObject(pk, 100)
+
+	select {
+	case ev := <-n.SyncEvents():
+		assertTrue(t, ev.Feed == pk, "wrong feed")
+		assertTrue(t, ev.ObjectsPerSecond > 0, "zero objects per second")
+		assertTrue(t, ev.BytesPerSecond > 0, "zero bytes per second")
+	case <-time.After(TM):
+		t.Fatal("slow")
+	}
+
+}
+
+func Test_syncEvents_collect(t *testing.T) {
+
+	var (
+		se     = newSyncEvents(time.Second)
+		pk, _  = cipher.GenerateKeyPair()
+		nh, mh = new(nodeHead), new(nodeHead)
+	)
+
+	se.addObject(pk, 10)
+	se.addObject(pk, 20)
+	se.setQueue(pk, nh, 5, 2)
+	se.setQueue(pk, mh, 1, 1)
+
+	var evs = se.collect()
+
+	assertTrue(t, len(evs) == 1, "wrong number of events")
+	assertTrue(t, evs[0].ObjectsPerSecond == 2, "wrong objects per second")
+	assertTrue(t, evs[0].BytesPerSecond == 30, "wrong bytes per second")
+	assertTrue(t, evs[0].Queued == 6, "wrong queued")
+	assertTrue(t, evs[0].Requesting == 3, "wrong requesting")
+
+	// queues are kept, but counters are reset
+
+	evs = se.collect()
+
+	assertTrue(t, len(evs) == 1, "wrong number of events")
+	assertTrue(t, evs[0].ObjectsPerSecond == 0, "counter not reset")
+
+	// idle feed is not reported
+
+	se.setQueue(pk, nh, 0, 0)
+	se.setQueue(pk, mh, 0, 0)
+
+	assertTrue(t, len(se.collect()) == 0, "idle feed reported")
+
+}
@@ -0,0 +1,596 @@
+package node
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A Tenant represents group of feeds of an independent
+// user of the Node. Every Tenant has its own quotas,
+// ACL domain and metrics. Thus, a single Node can host
+// feeds of many users. Feeds that don't belong to a
+// Tenant are not affected by any Tenant. A Tenant
+// uses the Container of the Node, or has its own
+// storage (see DataDir, DBPath and InMemoryDB)
+type Tenant struct {
+	// Name is unique name of the Tenant
+	Name string
+
+	// MaxFeeds is max number of feeds of the Tenant.
+	// Set it to zero to disable the limit
+	MaxFeeds int
+
+	// MaxVolume is max total size of stored objects
+	// received for feeds of the Tenant. Objects removed
+	// from DB deleting Root objects of the feeds free
+	// the volume. If the limit reached, then filling of
+	// a Root of the Tenant breaks with ErrTenantQuota.
+	// Set it to zero to disable the limit
+	MaxVolume int64
+
+	// Peers is ACL domain of the Tenant. If the Peers
+	// is not empty, then only peers with given IDs
+	// (see (*Conn).PeerID) can subscribe to feeds of
+	// the Tenant. Empty list means everyone
+	Peers []cipher.PubKey
+
+	// DataDir, DBPath and InMemoryDB are optional
+	// storage of the Tenant (see skyobject.Config for
+	// details). If all of them are blank, then the
+	// Tenant uses the Container of the Node. Otherwise,
+	// the Node creates separate Container for the
+	// Tenant (see TenantContainer) and keeps Root
+	// objects and objects of feeds of the Tenant there.
+	// The Container of the Tenant is closed with the
+	// Node or by the DelTenant
+	DataDir    string
+	DBPath     string
+	InMemoryDB bool
+}
+
+// HasStorage returns true if the Tenant
+// has its own storage
+func (t *Tenant) HasStorage() bool {
+	return t.DataDir != "" || t.DBPath != "" || t.InMemoryDB == true
+}
+
+// config of Container of the Tenant based
+// on configurations of the Container of the Node
+func (t *Tenant) storageConfig(
+	base *skyobject.Config, // : config of the Container of the Node
+) (
+	conf *skyobject.Config, // : config of the Container of the Tenant
+) {
+
+	var cp = *base
+	conf = &cp
+
+	conf.DB = nil // never share DB
+	conf.DataDir = t.DataDir
+	conf.DBPath = t.DBPath
+	conf.InMemoryDB = t.InMemoryDB
+
+	return
+}
+
+// Validate the Tenant
+func (t *Tenant) Validate() (err error) {
+	if t.Name == "" {
+		return errors.New("empty name of Tenant")
+	}
+	if t.MaxFeeds < 0 {
+		return errors.New("negative MaxFeeds of Tenant")
+	}
+	if t.MaxVolume < 0 {
+		return errors.New("negative MaxVolume of Tenant")
+	}
+	if t.InMemoryDB == true && (t.DataDir != "" || t.DBPath != "") {
+		return errors.New("InMemoryDB of Tenant is set with DataDir or DBPath")
+	}
+	return
+}
+
+// A TenantStat represents metrics of a Tenant
+type TenantStat struct {
+	Feeds    int   // number of feeds
+	Objects  int64 // received objects
+	Volume   int64 // stored bytes of received objects
+	Rejected int64 // subscriptions rejected by ACL
+}
+
+type tenant struct {
+	Tenant // configurations
+
+	peers map[cipher.PubKey]struct{} // ACL
+	feeds map[cipher.PubKey]int64    // feed -> stored volume
+
+	c *skyobject.Container // own storage or nil
+
+	stat TenantStat // metrics
+}
+
+// tenants of the Node
+type tenants struct {
+	mx sync.Mutex
+
+	ts map[string]*tenant        // name -> tenant
+	fs map[cipher.PubKey]*tenant // feed -> tenant
+}
+
+func newTenants() (t *tenants) {
+	t = new(tenants)
+	t.ts = make(map[string]*tenant)
+	t.fs = make(map[cipher.PubKey]*tenant)
+	return
+}
+
+func (t *tenants) has(name string) (ok bool) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	_, ok = t.ts[name]
+	return
+}
+
+func (t *tenants) add(
+	tc Tenant, //              : the Tenant
+	c *skyobject.Container, // : own storage or nil
+) (
+	err error, //              : validation error or ErrTenantExists
+) {
+
+	if err = tc.Validate(); err != nil {
+		return
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if _, ok := t.ts[tc.Name]; ok == true {
+		return ErrTenantExists
+	}
+
+	var tt = &tenant{
+		Tenant: tc,
+		peers:  make(map[cipher.PubKey]struct{}, len(tc.Peers)),
+		feeds:  make(map[cipher.PubKey]int64),
+		c:      c,
+	}
+
+	for _, pk := range tc.Peers {
+		tt.peers[pk] = struct{}{}
+	}
+
+	t.ts[tc.Name] = tt
+	return
+}
+
+// the del returns own storage of the tenant and
+// its feeds, if the tenant has own storage
+func (t *tenants) del(name string) (
+	c *skyobject.Container, // : own storage or nil
+	feeds []cipher.PubKey, //  : feeds stored in the c
+) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt, ok = t.ts[name]
+
+	if ok == false {
+		return
+	}
+
+	if tt.c != nil {
+		c = tt.c
+		feeds = make([]cipher.PubKey, 0, len(tt.feeds))
+	}
+
+	for pk := range tt.feeds {
+		delete(t.fs, pk)
+		if c != nil {
+			feeds = append(feeds, pk)
+		}
+	}
+
+	delete(t.ts, name)
+	return
+}
+
+// Container of given feed or nil
+// if the feed uses the Container of
+// the Node
+func (t *tenants) container(feed cipher.PubKey) (c *skyobject.Container) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if tt, ok := t.fs[feed]; ok == true {
+		c = tt.c
+	}
+	return
+}
+
+// Container of Tenant with given name
+func (t *tenants) containerOf(name string) (
+	c *skyobject.Container,
+	err error,
+) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt, ok = t.ts[name]
+
+	if ok == false {
+		return nil, ErrNoSuchTenant
+	}
+
+	return tt.c, nil
+}
+
+// own storages of all tenants
+func (t *tenants) containers() (cs []*skyobject.Container) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	for _, tt := range t.ts {
+		if tt.c != nil {
+			cs = append(cs, tt.c)
+		}
+	}
+	return
+}
+
+// close own storages of all tenants
+func (t *tenants) close() (err error) {
+
+	for _, c := range t.containers() {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return
+}
+
+func (t *tenants) list() (names []string) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if len(t.ts) == 0 {
+		return
+	}
+
+	names = make([]string, 0, len(t.ts))
+	for name := range t.ts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return
+}
+
+// check quota of the tenant and add the feed, it
+// returns added = false if the feed is already
+// feed of the tenant
+func (t *tenants) addFeed(
+	name string, //         : tenant
+	feed cipher.PubKey, //  : feed to add
+) (
+	added bool, //          : false if already added
+	err error, //           : quota or ownership error
+) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt, ok = t.ts[name]
+
+	if ok == false {
+		return false, ErrNoSuchTenant
+	}
+
+	if ft, ok := t.fs[feed]; ok == true {
+		if ft != tt {
+			return false, ErrFeedOfAnotherTenant
+		}
+		return // already
+	}
+
+	if tt.MaxFeeds > 0 && len(tt.feeds) >= tt.MaxFeeds {
+		return false, ErrTenantQuota
+	}
+
+	tt.feeds[feed] = 0
+	t.fs[feed] = tt
+	return true, nil
+}
+
+func (t *tenants) delFeed(feed cipher.PubKey) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if tt, ok := t.fs[feed]; ok == true {
+		tt.stat.Volume -= tt.feeds[feed]
+		delete(tt.feeds, feed)
+		delete(t.fs, feed)
+	}
+}
+
+func (t *tenants) tenantOf(feed cipher.PubKey) (name string, ok bool) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt *tenant
+	if tt, ok = t.fs[feed]; ok == true {
+		name = tt.Name
+	}
+	return
+}
+
+// check ACL of tenant of the feed
+func (t *tenants) allowSubscription(peer, feed cipher.PubKey) (err error) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt, ok = t.fs[feed]
+
+	if ok == false || len(tt.peers) == 0 {
+		return // not a tenant feed or everyone allowed
+	}
+
+	if _, ok = tt.peers[peer]; ok == false {
+		tt.stat.Rejected++
+		return ErrTenantACL
+	}
+
+	return
+}
+
+// received object of the feed, the addObject returns
+// ErrTenantQuota if the object exceeds the MaxVolume
+func (t *tenants) addObject(feed cipher.PubKey, size int) (err error) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt, ok = t.fs[feed]
+
+	if ok == false {
+		return // not a tenant feed
+	}
+
+	if tt.MaxVolume > 0 && tt.stat.Volume+int64(size) > tt.MaxVolume {
+		return ErrTenantQuota
+	}
+
+	tt.stat.Objects++
+	tt.stat.Volume += int64(size)
+	tt.feeds[feed] += int64(size)
+	return
+}
+
+// object of the feed removed from DB (see
+// (*skyobject.Container).AddDelHook)
+func (t *tenants) delObject(feed cipher.PubKey, _ cipher.SHA256, size int) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt, ok = t.fs[feed]
+
+	if ok == false {
+		return // not a tenant feed
+	}
+
+	// the object can be received for another
+	// feed, or before the feed added to the
+	// tenant, thus the volume can't be negative
+
+	var vol = int64(size)
+	if vol > tt.feeds[feed] {
+		vol = tt.feeds[feed]
+	}
+
+	tt.feeds[feed] -= vol
+	tt.stat.Volume -= vol
+}
+
+func (t *tenants) stat(name string) (ts TenantStat, err error) {
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	var tt, ok = t.ts[name]
+
+	if ok == false {
+		err = ErrNoSuchTenant
+		return
+	}
+
+	ts = tt.stat
+	ts.Feeds = len(tt.feeds)
+	return
+}
+
+// AddTenant adds new Tenant to the Node. Use ShareTenant
+// to share feeds of the Tenant. If the Tenant has its
+// own storage, then the AddTenant creates Container of
+// the Tenant using configurations of the Container of
+// the Node
+func (n *Node) AddTenant(t Tenant) (err error) {
+
+	if t.HasStorage() == false {
+		return n.tn.add(t, nil)
+	}
+
+	if err = t.Validate(); err != nil {
+		return
+	}
+
+	if n.tn.has(t.Name) == true {
+		return ErrTenantExists // don't open the DB
+	}
+
+	var (
+		conf = t.storageConfig(n.c.Config())
+		c    *skyobject.Container
+	)
+
+	if c, err = skyobject.NewContainer(conf); err != nil {
+		return
+	}
+
+	c.AddDelHook(n.tn.delObject) // stored volume of the tenant
+
+	if err = n.tn.add(t, c); err != nil {
+		c.Close() // ignore error
+	}
+
+	return
+}
+
+// DelTenant deletes Tenant from the Node. Feeds of the
+// Tenant are still shared, but they are not affected
+// by quotas and ACL of the Tenant anymore. If the Tenant
+// has its own storage, then the Node stops sharing feeds
+// of the Tenant and closes the Container of the Tenant.
+// The method does nothing if the Tenant doesn't exist
+func (n *Node) DelTenant(name string) {
+
+	var c, feeds = n.tn.del(name)
+
+	if c == nil {
+		return
+	}
+
+	for _, pk := range feeds {
+		n.DontShare(pk)
+	}
+
+	n.fs.list() // wait for fillers of the feeds
+
+	if err := c.Close(); err != nil {
+		n.Printf("[ERR] closing Container of tenant %q: %v", name, err)
+	}
+}
+
+// TenantContainer returns Container of Tenant with
+// given name. It returns the Container of the Node
+// if the Tenant has not its own storage. Use the
+// Container to create Root objects of feeds of the
+// Tenant
+func (n *Node) TenantContainer(name string) (
+	c *skyobject.Container,
+	err error,
+) {
+
+	if c, err = n.tn.containerOf(name); err == nil && c == nil {
+		c = n.c
+	}
+	return
+}
+
+// Tenants returns ordered list of names of Tenants
+func (n *Node) Tenants() (names []string) {
+	return n.tn.list()
+}
+
+// ShareTenant shares given feed (see Share) as feed of
+// Tenant with given name. It returns ErrTenantQuota if
+// the Tenant has MaxFeeds feeds already,
+// ErrFeedOfAnotherTenant if the feed belongs to
+// another Tenant, and ErrTenantStorage if the
+// Tenant has its own storage, but the feed is
+// already shared by the Node
+func (n *Node) ShareTenant(name string, feed cipher.PubKey) (err error) {
+
+	var added bool
+	if added, err = n.tn.addFeed(name, feed); err != nil {
+		return
+	}
+
+	// a feed shared before keeps its Root objects
+	// in the Container of the Node
+	if added == true && n.IsSharing(feed) == true &&
+		n.tn.container(feed) != nil {
+
+		n.tn.delFeed(feed) // rollback
+		return ErrTenantStorage
+	}
+
+	if err = n.Share(feed); err != nil && added == true {
+		n.tn.delFeed(feed) // rollback
+	}
+
+	return
+}
+
+// TenantOf returns name of Tenant of given feed
+func (n *Node) TenantOf(feed cipher.PubKey) (name string, ok bool) {
+	return n.tn.tenantOf(feed)
+}
+
+// TenantStat returns metrics of Tenant with given name
+func (n *Node) TenantStat(name string) (ts TenantStat, err error) {
+	return n.tn.stat(name)
+}
+
+// Container of given feed, the Container of the
+// Node or Container of Tenant of the feed
+func (n *Node) container(feed cipher.PubKey) (c *skyobject.Container) {
+	if c = n.tn.container(feed); c == nil {
+		c = n.c
+	}
+	return
+}
+
+// the Container of the Node and
+// Containers of Tenants
+func (n *Node) containers() (cs []*skyobject.Container) {
+	return append([]*skyobject.Container{n.c}, n.tn.containers()...)
+}
+
+// get object from the Container of the Node
+// or from a Container of a Tenant
+func (n *Node) getObject(key cipher.SHA256) (val []byte, err error) {
+
+	for _, c := range n.containers() {
+		if val, _, err = c.Get(key, 0); err != data.ErrNotFound {
+			return
+		}
+	}
+
+	return
+}
+
+// get Registry from the Container of the Node
+// or from a Container of a Tenant
+func (n *Node) registry(
+	rr registry.RegistryRef, // : the reference
+) (
+	reg *registry.Registry, //  : the Registry
+	err error, //               : an error
+) {
+
+	for _, c := range n.containers() {
+		if reg, err = c.Registry(rr); err != data.ErrNotFound {
+			return
+		}
+	}
+
+	return
+}
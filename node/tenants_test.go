@@ -0,0 +1,295 @@
+package node
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_ShareTenant(t *testing.T) {
+
+	var n = getTestNodeNotListen("test")
+	defer n.Close()
+
+	var (
+		pk1, _ = cipher.GenerateKeyPair()
+		pk2, _ = cipher.GenerateKeyPair()
+		err    error
+	)
+
+	assertTrue(t, n.ShareTenant("alice", pk1) == ErrNoSuchTenant,
+		"missing ErrNoSuchTenant")
+
+	assertNil(t, n.AddTenant(Tenant{Name: "alice", MaxFeeds: 1}))
+	assertNil(t, n.AddTenant(Tenant{Name: "eva"}))
+
+	assertTrue(t, n.AddTenant(Tenant{Name: "eva"}) == ErrTenantExists,
+		"missing ErrTenantExists")
+	assertTrue(t, n.AddTenant(Tenant{}) != nil, "missing error")
+
+	assertNil(t, n.ShareTenant("alice", pk1))
+	assertNil(t, n.ShareTenant("alice", pk1)) // already
+
+	assertTrue(t, n.IsSharing(pk1), "not sharing")
+
+	assertTrue(t, n.ShareTenant("alice", pk2) == ErrTenantQuota,
+		"missing ErrTenantQuota")
+	assertTrue(t, n.ShareTenant("eva", pk1) == ErrFeedOfAnotherTenant,
+		"missing ErrFeedOfAnotherTenant")
+
+	var name, ok = n.TenantOf(pk1)
+	assertTrue(t, ok && name == "alice", "wrong tenant")
+
+	_, ok = n.TenantOf(pk2)
+	assertTrue(t, ok == false, "unexpected tenant")
+
+	var ts TenantStat
+	ts, err = n.TenantStat("alice")
+	assertNil(t, err)
+	assertTrue(t, ts.Feeds == 1, "wrong number of feeds")
+
+	// release the feed
+
+	assertNil(t, n.DontShare(pk1))
+	_, ok = n.TenantOf(pk1)
+	assertTrue(t, ok == false, "feed of tenant after DontShare")
+
+	n.DelTenant("eva")
+
+	var names = n.Tenants()
+	assertTrue(t, len(names) == 1 && names[0] == "alice", "wrong tenants")
+
+}
+
+func TestNode_ShareTenant_concurrent(t *testing.T) {
+
+	var n = getTestNodeNotListen("test")
+	defer n.Close()
+
+	assertNil(t, n.AddTenant(Tenant{Name: "alice", MaxFeeds: 1}))
+
+	var (
+		errs = make(chan error, 10)
+		wg   sync.WaitGroup
+	)
+
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var pk, _ = cipher.GenerateKeyPair()
+			errs <- n.ShareTenant("alice", pk)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	var shared int
+	for err := range errs {
+		if err == nil {
+			shared++
+			continue
+		}
+		assertTrue(t, err == ErrTenantQuota, "unexpected error")
+	}
+
+	assertTrue(t, shared == 1, "MaxFeeds exceeded")
+
+	var ts, err = n.TenantStat("alice")
+	assertNil(t, err)
+	assertTrue(t, ts.Feeds == 1, "wrong number of feeds")
+
+}
+
+func Test_tenants_allowSubscription(t *testing.T) {
+
+	var (
+		tn = newTenants()
+
+		feed, _   = cipher.GenerateKeyPair()
+		peer, _   = cipher.GenerateKeyPair()
+		stranger  = cipher.PubKey{1}
+		notTenant = cipher.PubKey{2}
+	)
+
+	assertNil(t, tn.add(Tenant{Name: "alice", Peers: []cipher.PubKey{peer}}, nil))
+	_, err := tn.addFeed("alice", feed)
+	assertNil(t, err)
+
+	assertNil(t, tn.allowSubscription(peer, feed))
+	assertNil(t, tn.allowSubscription(stranger, notTenant))
+	assertTrue(t, tn.allowSubscription(stranger, feed) == ErrTenantACL,
+		"missing ErrTenantACL")
+
+	var ts TenantStat
+	ts, err = tn.stat("alice")
+	assertNil(t, err)
+	assertTrue(t, ts.Rejected == 1, "wrong number of rejected")
+
+}
+
+func Test_tenants_addObject(t *testing.T) {
+
+	var (
+		tn      = newTenants()
+		feed, _ = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, tn.add(Tenant{Name: "alice", MaxVolume: 100}, nil))
+
+	var added, err = tn.addFeed("alice", feed)
+	assertNil(t, err)
+	assertTrue(t, added, "not added")
+
+	assertNil(t, tn.addObject(feed, 60))
+	assertTrue(t, tn.addObject(feed, 60) == ErrTenantQuota,
+		"missing ErrTenantQuota")
+	assertNil(t, tn.addObject(feed, 40))
+
+	var ts TenantStat
+	ts, err = tn.stat("alice")
+	assertNil(t, err)
+	assertTrue(t, ts.Objects == 2 && ts.Volume == 100, "wrong stat")
+
+	// removed objects free the volume
+
+	tn.delObject(feed, cipher.SHA256{}, 60)
+	assertNil(t, tn.addObject(feed, 60))
+
+	tn.delObject(feed, cipher.SHA256{}, 1000) // can't be negative
+
+	ts, err = tn.stat("alice")
+	assertNil(t, err)
+	assertTrue(t, ts.Volume == 0, "wrong volume")
+
+	// the feed leaves the tenant with its volume
+
+	assertNil(t, tn.addObject(feed, 50))
+	tn.delFeed(feed)
+
+	ts, err = tn.stat("alice")
+	assertNil(t, err)
+	assertTrue(t, ts.Feeds == 0 && ts.Volume == 0, "wrong stat")
+
+}
+
+func TestNode_TenantContainer(t *testing.T) {
+
+	var (
+		fr    = make(chan *registry.Root, 10)
+		sconf = getTestConfig("publisher")
+		rconf = getTestConfigNotListen("tenants")
+	)
+
+	rconf.OnRootFilled = func(_ *Node, r *registry.Root) { fr <- r }
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var rn *Node
+	if rn, err = NewNode(rconf); err != nil {
+		t.Fatal(err)
+	}
+	defer rn.Close()
+
+	assertTrue(t, rn.AddTenant(Tenant{
+		Name:       "eva",
+		DataDir:    "/tmp",
+		InMemoryDB: true,
+	}) != nil, "missing error")
+
+	assertNil(t, rn.AddTenant(Tenant{Name: "alice", InMemoryDB: true}))
+	assertNil(t, rn.AddTenant(Tenant{Name: "bob"}))
+
+	var ac, bc *skyobject.Container
+	if ac, err = rn.TenantContainer("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if bc, err = rn.TenantContainer("bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, ac != rn.Container(), "shared Container")
+	assertTrue(t, bc == rn.Container(), "unexpected Container")
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		shared = cipher.PubKey{1}
+	)
+
+	// a feed shared before can't be moved to own storage
+	assertNil(t, rn.Share(shared))
+	assertTrue(t, rn.ShareTenant("alice", shared) == ErrTenantStorage,
+		"missing ErrTenantStorage")
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.ShareTenant("alice", pk))
+
+	var up *skyobject.Unpack
+	if up, err = sn.Container().Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		r    = &registry.Root{Pub: pk, Nonce: 1}
+		user = dynamicByValue(t, up, "test.User", User{"Alice", 19, nil})
+	)
+
+	r.Refs = append(r.Refs, user)
+	assertNil(t, sn.Container().Save(up, r))
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+	assertNil(t, c.Subscribe(pk))
+
+	var cs = sn.ConnectionsOfFeed(pk)
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+	cs[0].sendLastRoot(pk) // the Root can be received before the reply
+
+	select {
+	case <-fr:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow")
+	}
+
+	if _, err = ac.LastRoot(pk, r.Nonce); err != nil {
+		t.Error("missing Root in Container of the Tenant:", err)
+	}
+
+	if _, _, err = ac.Get(user.Hash, 0); err != nil {
+		t.Error("missing object in Container of the Tenant:", err)
+	}
+
+	if _, err = rn.Container().Heads(pk); err != data.ErrNoSuchFeed {
+		t.Error("feed of Tenant in Container of the Node:", err)
+	}
+
+	// objects of the Tenant are served
+	var val []byte
+	if val, err = rn.getObject(user.Hash); err != nil || len(val) == 0 {
+		t.Error("can't get object of the Tenant:", err)
+	}
+
+	rn.DelTenant("alice")
+
+	assertTrue(t, rn.IsSharing(pk) == false, "feed of deleted Tenant shared")
+
+	if _, err = rn.TenantContainer("alice"); err != ErrNoSuchTenant {
+		t.Error("missing ErrNoSuchTenant:", err)
+	}
+
+}
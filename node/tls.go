@@ -0,0 +1,157 @@
+package node
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/net/client"
+	"github.com/skycoin/net/factory"
+	"github.com/skycoin/net/server"
+)
+
+// TLSPin returns pin of given DER encoded certificate
+// (see Config.TLSPins). The pin is SHA256 of the
+// certificate
+func TLSPin(der []byte) cipher.SHA256 {
+	return cipher.SumSHA256(der)
+}
+
+// listen TLS (see Config.TLS), call under lock
+func (t *TCP) listenTLS(address string, tc *tls.Config) (err error) {
+
+	var l net.Listener
+	if l, err = tls.Listen("tcp", address, tc); err != nil {
+		return
+	}
+
+	t.tl = l
+
+	go t.acceptTLS(l) // like the TCPFactory, not tracked by the await
+	return
+}
+
+func (t *TCP) acceptTLS(l net.Listener) {
+	for {
+		var nc, err = l.Accept()
+		if err != nil {
+			return
+		}
+		go t.handshakeTLS(nc.(*tls.Conn))
+	}
+}
+
+// (async) perform TLS handshake of accepted connection
+func (t *TCP) handshakeTLS(tc *tls.Conn) {
+
+	if err := t.tlsHandshake(tc); err != nil {
+		t.n.Debugf(NewInConnPin, "TLS handshake with tcp://%s failed: %v",
+			tc.RemoteAddr().String(), err)
+		tc.Close()
+		return
+	}
+
+	t.acceptNetConn(tc, "tls")
+}
+
+// TLS handshake with the response timeout
+func (t *TCP) tlsHandshake(tc *tls.Conn) (err error) {
+
+	if rt := t.n.config.TCP.ResponseTimeout; rt > 0 {
+		tc.SetDeadline(time.Now().Add(rt))
+		defer tc.SetDeadline(time.Time{})
+	}
+
+	return tc.Handshake()
+}
+
+// dial TLS (see Config.TLS)
+func (t *TCP) dialTLS(address string, tc *tls.Config) (
+	fc *factory.Connection,
+	err error,
+) {
+
+	if tc.ServerName == "" && tc.InsecureSkipVerify == false {
+		var host string
+		if host, _, err = net.SplitHostPort(address); err != nil {
+			return
+		}
+		tc = tc.Clone()
+		tc.ServerName = host
+	}
+
+	var nc net.Conn
+	if nc, err = t.n.dial(address, t.n.config.TCP.ResponseTimeout); err != nil {
+		return
+	}
+
+	var tlc = tls.Client(nc, tc)
+
+	if err = t.tlsHandshake(tlc); err != nil {
+		nc.Close()
+		return
+	}
+
+	var cc = client.NewClientTCPConn(tlc)
+	cc.SetStatusToConnected()
+
+	fc = &factory.Connection{Connection: cc}
+	fc.SetContextLogger(fc.GetContextLogger().WithField("type", "tls"))
+
+	t.AddConn(fc)
+	return
+}
+
+// TLS state of given connection or nil
+func tlsState(fc *factory.Connection) (cs *tls.ConnectionState) {
+
+	var nc net.Conn
+
+	switch x := fc.Connection.(type) {
+	case *server.ServerTCPConn:
+		nc = x.TcpConn
+	case *client.ClientTCPConn:
+		nc = x.TcpConn
+	}
+
+	if tc, ok := nc.(*tls.Conn); ok == true {
+		var state = tc.ConnectionState()
+		cs = &state
+	}
+
+	return
+}
+
+// IsTLS returns true if the Conn uses TLS
+func (c *Conn) IsTLS() bool {
+	return tlsState(c.Connection) != nil
+}
+
+// check out certificate of remote peer using the
+// Config.TLSPins after handshake (the peerID is known)
+func (c *Conn) verifyTLSPin() (err error) {
+
+	var pins = c.n.config.TLSPins
+
+	if len(pins) == 0 {
+		return
+	}
+
+	var cs = tlsState(c.Connection)
+
+	if cs == nil {
+		return // not a TLS connection
+	}
+
+	var pin, ok = pins[c.peerID]
+
+	if ok == false || len(cs.PeerCertificates) == 0 ||
+		TLSPin(cs.PeerCertificates[0].Raw) != pin {
+
+		return ErrTLSPin
+	}
+
+	return
+}
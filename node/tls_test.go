@@ -0,0 +1,132 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func getTestCertificate(t *testing.T) (cert tls.Certificate) {
+
+	var key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tmpl = &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cxo"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	var der []byte
+	if der, err = x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey,
+		key); err != nil {
+
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestTCP_tls(t *testing.T) {
+
+	var (
+		scert = getTestCertificate(t)
+		ccert = getTestCertificate(t)
+
+		spins = make(map[cipher.PubKey]cipher.SHA256)
+		cpins = make(map[cipher.PubKey]cipher.SHA256)
+
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+	)
+
+	sconf.UDP.Listen = ""
+	sconf.TLS = &tls.Config{
+		Certificates:       []tls.Certificate{scert},
+		ClientAuth:         tls.RequireAnyClientCert,
+		InsecureSkipVerify: true,
+	}
+	sconf.TLSPins = spins
+
+	cconf.TLS = &tls.Config{
+		Certificates:       []tls.Certificate{ccert},
+		InsecureSkipVerify: true,
+	}
+	cconf.TLSPins = cpins
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	// not pinned by the server
+	var dconf = getTestConfigNotListen("unknown")
+	dconf.TLS = cconf.TLS
+	dconf.TLSPins = cpins
+
+	var dn *Node
+	if dn, err = NewNode(dconf); err != nil {
+		t.Fatal(err)
+	}
+	defer dn.Close()
+
+	// wrong certificate of the server
+	var wconf = getTestConfigNotListen("wrong")
+	wconf.TLS = cconf.TLS
+	wconf.TLSPins = map[cipher.PubKey]cipher.SHA256{
+		sn.ID(): TLSPin(ccert.Certificate[0]),
+	}
+
+	var wn *Node
+	if wn, err = NewNode(wconf); err != nil {
+		t.Fatal(err)
+	}
+	defer wn.Close()
+
+	spins[cn.ID()] = TLSPin(ccert.Certificate[0])
+	spins[wn.ID()] = TLSPin(ccert.Certificate[0])
+	cpins[sn.ID()] = TLSPin(scert.Certificate[0])
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.IsTLS() == false {
+		t.Error("not TLS")
+	}
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+	assertNil(t, c.Subscribe(pk))
+
+	if _, err = dn.TCP().Connect(sn.TCP().Address()); !errors.Is(err, ErrTLSPin) {
+		t.Error("wrong error:", err)
+	}
+
+	if _, err = wn.TCP().Connect(sn.TCP().Address()); err != ErrTLSPin {
+		t.Error("wrong error:", err)
+	}
+
+}
@@ -0,0 +1,247 @@
+// Package tor implements minimal client of Tor control
+// protocol, enough to publish v3 onion service. The node
+// package uses it to accept connections through Tor (see
+// node.Config.Tor). The onion service exists while the
+// Controller is not closed
+package tor
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errors
+var (
+	ErrAuthMethods = errors.New("tor: no supported authentication methods")
+	ErrNoServiceID = errors.New("tor: missing ServiceID in ADD_ONION reply")
+)
+
+// KeyNew is key of ADD_ONION, that creates
+// new v3 onion service
+const KeyNew = "NEW:ED25519-V3"
+
+// A Controller represents authenticated
+// connection to Tor control port
+type Controller struct {
+	mx sync.Mutex
+	tc *textproto.Conn
+}
+
+// Dial Tor control port and authenticate. The password
+// is used if Tor requires HASHEDPASSWORD authentication,
+// otherwise NULL or COOKIE authentication is used
+func Dial(
+	address string, //        : control port address
+	password string, //       : password or blank
+	timeout time.Duration, // : dial timeout
+) (
+	c *Controller, //         : the Controller
+	err error, //             : an error
+) {
+
+	var conn net.Conn
+	if conn, err = net.DialTimeout("tcp", address, timeout); err != nil {
+		return
+	}
+
+	c = &Controller{tc: textproto.NewConn(conn)}
+
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	if err = c.authenticate(password); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	return
+}
+
+// send command and read reply lines (without
+// status codes); a reply should be 250
+func (c *Controller) command(format string, args ...interface{}) (
+	lines []string,
+	err error,
+) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if err = c.tc.PrintfLine(format, args...); err != nil {
+		return
+	}
+
+	var msg string
+	if _, msg, err = c.tc.ReadResponse(250); err != nil {
+		if te, ok := err.(*textproto.Error); ok == true {
+			return nil, fmt.Errorf("tor: %d %s", te.Code, te.Msg)
+		}
+		return
+	}
+
+	return strings.Split(msg, "\n"), nil
+}
+
+// PROTOCOLINFO and AUTHENTICATE
+func (c *Controller) authenticate(password string) (err error) {
+
+	var lines []string
+	if lines, err = c.command("PROTOCOLINFO 1"); err != nil {
+		return
+	}
+
+	var (
+		methods    = make(map[string]bool)
+		cookieFile string
+	)
+
+	for _, line := range lines {
+
+		if strings.HasPrefix(line, "AUTH ") == false {
+			continue
+		}
+
+		for _, field := range splitQuoted(line[len("AUTH "):]) {
+
+			if strings.HasPrefix(field, "METHODS=") {
+				for _, m := range strings.Split(field[8:], ",") {
+					methods[m] = true
+				}
+			} else if strings.HasPrefix(field, "COOKIEFILE=") {
+				if cookieFile, err = unquote(field[11:]); err != nil {
+					return
+				}
+			}
+
+		}
+
+	}
+
+	switch {
+
+	case methods["NULL"] == true:
+		_, err = c.command("AUTHENTICATE")
+
+	case methods["HASHEDPASSWORD"] == true && password != "":
+		_, err = c.command("AUTHENTICATE %s", quote(password))
+
+	case methods["COOKIE"] == true && cookieFile != "":
+		var cookie []byte
+		if cookie, err = ioutil.ReadFile(cookieFile); err != nil {
+			return
+		}
+		_, err = c.command("AUTHENTICATE %s", hex.EncodeToString(cookie))
+
+	default:
+		err = ErrAuthMethods
+
+	}
+
+	return
+}
+
+// AddOnion creates v3 onion service that forwards given
+// virtual port to given target (host:port). The key is
+// KeyNew or private key returned by previous AddOnion
+// call, to keep the same onion address. The AddOnion
+// returns service id (the address without .onion) and
+// private key of the service (blank if the key is not
+// KeyNew)
+func (c *Controller) AddOnion(
+	key string, //    : private key or KeyNew
+	port int, //      : virtual port
+	target string, // : target host:port
+) (
+	id string, //     : service id
+	pk string, //     : private key of new service
+	err error, //     : an error
+) {
+
+	var lines []string
+	if lines, err = c.command("ADD_ONION %s Port=%d,%s", key, port,
+		target); err != nil {
+
+		return
+	}
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "ServiceID=") {
+			id = line[len("ServiceID="):]
+		} else if strings.HasPrefix(line, "PrivateKey=") {
+			pk = line[len("PrivateKey="):]
+		}
+	}
+
+	if id == "" {
+		return "", "", ErrNoServiceID
+	}
+
+	return
+}
+
+// DelOnion removes onion service with given service id
+func (c *Controller) DelOnion(id string) (err error) {
+	_, err = c.command("DEL_ONION %s", id)
+	return
+}
+
+// Close the Controller, Tor removes onion
+// services created by the Controller
+func (c *Controller) Close() (err error) {
+	return c.tc.Close()
+}
+
+// split by spaces out of quotes
+func splitQuoted(s string) (fields []string) {
+
+	var (
+		quoted, escaped bool
+		start           int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped == true:
+			escaped = false
+		case s[i] == '\\' && quoted == true:
+			escaped = true
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == ' ' && quoted == false:
+			if i > start {
+				fields = append(fields, s[start:i])
+			}
+			start = i + 1
+		}
+	}
+
+	if start < len(s) {
+		fields = append(fields, s[start:])
+	}
+
+	return
+}
+
+// QuotedString of the control protocol
+func quote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) == false {
+		return s, nil
+	}
+	return strconv.Unquote(s)
+}
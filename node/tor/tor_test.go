@@ -0,0 +1,185 @@
+package tor
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fake Tor control port, it calls
+// the handle for every command
+func testControlPort(
+	t *testing.T,
+	handle func(cmd string) (reply []string),
+) (
+	address string,
+	closeFunc func(),
+) {
+
+	var l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var serve = func(c net.Conn) {
+		defer c.Close()
+
+		var r = bufio.NewReader(c)
+		for {
+			var line, err = r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			for _, reply := range handle(strings.TrimRight(line, "\r\n")) {
+				c.Write([]byte(reply + "\r\n"))
+			}
+		}
+	}
+
+	go func() {
+		for {
+			var c, err = l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(c)
+		}
+	}()
+
+	return l.Addr().String(), func() { l.Close() }
+}
+
+func TestController(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "tor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var cookieFile = filepath.Join(dir, "control_auth_cookie")
+	if err = ioutil.WriteFile(cookieFile, []byte{0xca, 0xfe}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cmds = make(chan string, 10)
+
+	var address, closeFunc = testControlPort(t, func(cmd string) []string {
+		cmds <- cmd
+		switch {
+		case cmd == "PROTOCOLINFO 1":
+			return []string{
+				"250-PROTOCOLINFO 1",
+				`250-AUTH METHODS=COOKIE,SAFECOOKIE COOKIEFILE="` +
+					cookieFile + `"`,
+				`250-VERSION Tor="0.4.8.9"`,
+				"250 OK",
+			}
+		case cmd == "AUTHENTICATE cafe":
+			return []string{"250 OK"}
+		case strings.HasPrefix(cmd, "ADD_ONION NEW:ED25519-V3 "):
+			return []string{
+				"250-ServiceID=exampleonion",
+				"250-PrivateKey=ED25519-V3:c2VjcmV0",
+				"250 OK",
+			}
+		case strings.HasPrefix(cmd, "ADD_ONION ED25519-V3:"):
+			return []string{"250-ServiceID=exampleonion", "250 OK"}
+		case cmd == "DEL_ONION exampleonion":
+			return []string{"250 OK"}
+		}
+		return []string{"510 Unrecognized command"}
+	})
+	defer closeFunc()
+
+	var c *Controller
+	if c, err = Dial(address, "", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var id, pk string
+	if id, pk, err = c.AddOnion(KeyNew, 8870, "127.0.0.1:8870"); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "exampleonion" || pk != "ED25519-V3:c2VjcmV0" {
+		t.Error("wrong reply:", id, pk)
+	}
+
+	if id, pk, err = c.AddOnion(pk, 8870, "127.0.0.1:8870"); err != nil {
+		t.Fatal(err)
+	} else if id != "exampleonion" || pk != "" {
+		t.Error("wrong reply:", id, pk)
+	}
+
+	if err = c.DelOnion(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.command("GETINFO version"); err == nil {
+		t.Error("missing error")
+	} else if strings.Contains(err.Error(), "510") == false {
+		t.Error("unexpected error:", err)
+	}
+
+	var want = []string{
+		"PROTOCOLINFO 1",
+		"AUTHENTICATE cafe",
+		"ADD_ONION NEW:ED25519-V3 Port=8870,127.0.0.1:8870",
+		"ADD_ONION ED25519-V3:c2VjcmV0 Port=8870,127.0.0.1:8870",
+		"DEL_ONION exampleonion",
+		"GETINFO version",
+	}
+
+	for _, w := range want {
+		if cmd := <-cmds; cmd != w {
+			t.Errorf("unexpected command %q, want %q", cmd, w)
+		}
+	}
+
+}
+
+func TestDial_password(t *testing.T) {
+
+	var address, closeFunc = testControlPort(t, func(cmd string) []string {
+		switch cmd {
+		case "PROTOCOLINFO 1":
+			return []string{
+				"250-PROTOCOLINFO 1",
+				"250-AUTH METHODS=HASHEDPASSWORD",
+				"250 OK",
+			}
+		case `AUTHENTICATE "p\"w"`:
+			return []string{"250 OK"}
+		}
+		return []string{"515 Authentication failed"}
+	})
+	defer closeFunc()
+
+	var c, err = Dial(address, `p"w`, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	if _, err = Dial(address, "", time.Second); err != ErrAuthMethods {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func Test_splitQuoted(t *testing.T) {
+
+	var fields = splitQuoted(`METHODS=COOKIE COOKIEFILE="/a b/\"c\""`)
+
+	if len(fields) != 2 || fields[1] != `COOKIEFILE="/a b/\"c\""` {
+		t.Errorf("wrong fields %q", fields)
+	}
+
+}
@@ -0,0 +1,133 @@
+package node
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// TraceIDSize is size of encoded TraceID
+const TraceIDSize = 8
+
+// A TraceID identifies a request and its reply. If both
+// peers have the msg.FeatureTrace, every request carries
+// random trace ID and the reply echoes it. The trace ID
+// is in debug logs of both peers (see MsgSendPin and
+// MsgReceivePin), thus a slow or failing request can be
+// found in logs of the remote peer. Zero is no trace
+type TraceID uint64
+
+// String implements fmt.Stringer interface
+func (t TraceID) String() string {
+	return fmt.Sprintf("%016x", uint64(t))
+}
+
+// new random trace ID
+func newTraceID() (t TraceID) {
+	var p [TraceIDSize]byte
+	for t == 0 {
+		if _, err := io.ReadFull(rand.Reader, p[:]); err != nil {
+			panic(err)
+		}
+		t = TraceID(binary.LittleEndian.Uint64(p[:]))
+	}
+	return
+}
+
+// are trace IDs used
+func (c *Conn) canTrace() bool {
+	return c.HasFeature(msg.FeatureTrace)
+}
+
+// start trace of own request with given seq,
+// it returns zero if the peer can't trace
+func (c *Conn) addRequestTrace(seq uint32) (t TraceID) {
+
+	if c.canTrace() == false {
+		return
+	}
+
+	t = newTraceID()
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.rqTraces == nil {
+		c.rqTraces = make(map[uint32]TraceID)
+	}
+
+	c.rqTraces[seq] = t
+	return
+}
+
+// remember trace of received request to echo it
+func (c *Conn) addReplyTrace(seq uint32, t TraceID) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.rpTraces == nil {
+		c.rpTraces = make(map[uint32]TraceID)
+	}
+
+	c.rpTraces[seq] = t
+}
+
+// trace ID of message with given seq and rseq; the
+// method forgets trace of replied request (rseq)
+func (c *Conn) takeTrace(seq, rseq uint32) (t TraceID) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if rseq == 0 {
+		return c.rqTraces[seq] // own request, see delRequest
+	}
+
+	if t = c.rpTraces[rseq]; t != 0 {
+		delete(c.rpTraces, rseq)
+	}
+
+	return
+}
+
+// append trace ID to header of a message
+// if the peer can trace
+func (c *Conn) appendTrace(raw []byte, t TraceID) []byte {
+
+	if c.canTrace() == false {
+		return raw
+	}
+
+	var p [TraceIDSize]byte
+	binary.LittleEndian.PutUint64(p[:], uint64(t))
+
+	return append(raw, p[:]...)
+}
+
+// trace ID of received message, it returns the
+// message without the trace ID
+func (c *Conn) untrace(raw []byte) (t TraceID, m []byte, err error) {
+
+	if c.canTrace() == false {
+		return 0, raw, nil
+	}
+
+	if len(raw) < TraceIDSize+1 {
+		return 0, nil, errors.New("invalid messege received: no trace ID")
+	}
+
+	t = TraceID(binary.LittleEndian.Uint64(raw))
+	m = raw[TraceIDSize:]
+	return
+}
+
+// suffix of log messages
+func traceSuffix(t TraceID) string {
+	if t == 0 {
+		return ""
+	}
+	return " trace " + t.String()
+}
@@ -0,0 +1,58 @@
+package node
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConn_trace(t *testing.T) {
+
+	var (
+		conf = NewConfig()
+
+		c1 = &Conn{n: &Node{config: conf}, features: msg.FeatureTrace}
+		c2 = &Conn{n: &Node{config: conf}, features: msg.FeatureTrace}
+	)
+
+	// request
+
+	var trace = c1.addRequestTrace(1)
+	assertTrue(t, trace != 0, "no trace")
+
+	var raw = c1.encodeMsg(1, 0, &msg.Ping{})
+
+	var got, body, err = c2.untrace(raw[8:])
+	assertNil(t, err)
+	assertTrue(t, got == trace, "wrong trace")
+	assertTrue(t, len(body) == 1 && body[0] == byte(msg.PingType),
+		"wrong body")
+
+	// reply echoes the trace once
+
+	c2.addReplyTrace(1, got)
+
+	raw = c2.encodeMsg(1, 1, &msg.Pong{})
+	got, _, err = c1.untrace(raw[8:])
+	assertNil(t, err)
+	assertTrue(t, got == trace, "not echoed")
+
+	raw = c2.encodeMsg(2, 1, &msg.Pong{})
+	assertTrue(t, binary.LittleEndian.Uint64(raw[8:]) == 0, "echoed twice")
+
+	c1.delRequest(1)
+	assertTrue(t, c1.takeTrace(1, 0) == 0, "trace is not released")
+
+	// malformed
+
+	_, _, err = c1.untrace(raw[8:12])
+	assertTrue(t, err != nil, "missing error")
+
+	// without trace IDs
+
+	c1.features = 0
+	assertTrue(t, c1.addRequestTrace(2) == 0, "traced")
+	assertTrue(t, len(c1.encodeMsg(3, 0, &msg.Ping{})) == 9, "wrong size")
+
+}
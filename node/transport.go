@@ -0,0 +1,344 @@
+package node
+
+import (
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/skycoin/net/conn"
+	"github.com/skycoin/net/factory"
+)
+
+// A FramedConn is message-oriented connection of a
+// Transport. Every message written by the WriteMessage
+// should be read by the ReadMessage of the other side
+// as is. The ws.Conn and the kcp.Session are FramedConn
+type FramedConn interface {
+	ReadMessage() (p []byte, err error) // blocking read
+	WriteMessage(p []byte) (err error)  // blocking write
+	RemoteAddr() (addr net.Addr)        // remote address
+	Close() (err error)                 // close
+}
+
+// A Listener accepts connections of a Transport
+type Listener interface {
+	Accept() (fc FramedConn, err error) // blocking accept
+	Close() (err error)                 // unblock the Accept
+}
+
+// A Transport represents custom transport of the Node,
+// for example serial links, Bluetooth or in-process
+// pipes for tests (see Config.Transports). The
+// Transport must be reliable and ordered. Connections
+// of a custom transport use the TCP configurations
+// (response timeout and pings)
+type Transport interface {
+	// Listen on given address, the address
+	// is address without the scheme
+	Listen(address string) (l Listener, err error)
+	// Dial to given address, the address
+	// is address without the scheme
+	Dial(address string) (fc FramedConn, err error)
+}
+
+// sorted schemes of custom transports
+func (n *Node) transportSchemes() (schemes []string) {
+	for scheme := range n.ts {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return
+}
+
+// a custom transport of the Node
+type customTransport struct {
+	// back reference
+	n *Node
+
+	scheme string
+	t      Transport
+
+	mx  sync.Mutex
+	ls  []Listener                       // listeners
+	as  []string                         // listening addresses
+	cs  map[string]*Conn                 // address -> conn
+	fcs map[*factory.Connection]struct{} // underlying connections
+}
+
+func newCustomTransport(n *Node, scheme string, t Transport) (ct *customTransport) {
+
+	ct = new(customTransport)
+
+	ct.n = n
+	ct.scheme = scheme
+	ct.t = t
+	ct.cs = make(map[string]*Conn)
+	ct.fcs = make(map[*factory.Connection]struct{})
+
+	return
+}
+
+// listen on given address (without scheme)
+func (c *customTransport) listen(address string) (err error) {
+
+	var l Listener
+	if l, err = c.t.Listen(address); err != nil {
+		return
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.ls = append(c.ls, l)
+	c.as = append(c.as, address)
+
+	c.n.await.Add(1)
+	go c.accept(l)
+
+	return
+}
+
+func (c *customTransport) accept(l Listener) {
+	defer c.n.await.Done()
+
+	for {
+		var fc, err = l.Accept()
+		if err != nil {
+			return
+		}
+		go c.acceptConnection(fc)
+	}
+}
+
+func (c *customTransport) acceptConnection(f FramedConn) {
+
+	var fc = c.newConnection(f)
+
+	if fc == nil {
+		return // closed
+	}
+
+	c.n.acceptConnection(fc)
+}
+
+// wrap given connection
+func (c *customTransport) newConnection(f FramedConn) (fc *factory.Connection) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.fcs == nil {
+		f.Close()
+		return // closed
+	}
+
+	fc = newFramedConn(f, c.scheme, true)
+	c.fcs[fc] = struct{}{}
+
+	go c.waitDisconnected(fc)
+	return
+}
+
+func (c *customTransport) waitDisconnected(fc *factory.Connection) {
+
+	fc.WaitForDisconnected()
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	delete(c.fcs, fc)
+}
+
+func (c *customTransport) addAcceptedConnection(cn *Conn) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.cs[cn.Address()] = cn
+}
+
+// listening addresses with scheme
+func (c *customTransport) addresses() (as []string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, address := range c.as {
+		as = append(as, c.scheme+"://"+address)
+	}
+	return
+}
+
+// Connect to given address (without scheme)
+func (c *customTransport) Connect(address string) (cn *Conn, err error) {
+
+	c.mx.Lock()
+	var ok bool
+	if cn, ok = c.cs[address]; ok == true && cn.isClosed() == false {
+		c.mx.Unlock()
+		return // already have
+	}
+	c.mx.Unlock()
+
+	var f FramedConn
+	if f, err = c.t.Dial(address); err != nil {
+		return nil, err
+	}
+
+	var fc = c.newConnection(f)
+
+	if fc == nil {
+		return nil, ErrClosed
+	}
+
+	if cn, err = c.n.wrapConnection(fc, false); err != nil {
+		return
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.cs[address] = cn
+	return
+}
+
+// Close listeners and connections
+func (c *customTransport) Close() (err error) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, l := range c.ls {
+		if e := l.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	for fc := range c.fcs {
+		fc.Close()
+	}
+
+	c.fcs = nil
+	return
+}
+
+// a FramedConn that implements
+// connection of the skycoin/net
+type framedConn struct {
+	conn.ConnCommonFields
+	fc      FramedConn
+	network string // ws, kcp or scheme of custom transport
+	tcp     bool   // use TCP configurations
+}
+
+func newFramedConn(
+	f FramedConn, //   : the connection
+	network string, // : ws, kcp or a scheme
+	tcp bool, //       : use TCP configurations
+) (fc *factory.Connection) {
+
+	var c = &framedConn{
+		ConnCommonFields: conn.NewConnCommonFileds(),
+		fc:               f,
+		network:          network,
+		tcp:              tcp,
+	}
+	c.SetStatusToConnected()
+
+	fc = &factory.Connection{Connection: c}
+	fc.SetContextLogger(fc.GetContextLogger().WithField("type", network))
+
+	go c.WriteLoop()
+	go c.ReadLoop()
+
+	return
+}
+
+// ReadLoop implements conn.Connection interface
+func (c *framedConn) ReadLoop() (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			// the In closed
+			c.GetContextLogger().Debug(e)
+		}
+		if err != nil {
+			c.SetStatusToError(err)
+		}
+		c.Close()
+	}()
+
+	for {
+
+		var p []byte
+		if p, err = c.fc.ReadMessage(); err != nil {
+			return
+		}
+
+		c.AddReceivedBytes(len(p))
+		c.UpdateLastTime()
+
+		c.In <- p
+	}
+}
+
+// WriteLoop implements conn.Connection interface
+func (c *framedConn) WriteLoop() (err error) {
+	defer func() {
+		if err != nil {
+			c.SetStatusToError(err)
+			c.Close()
+		}
+	}()
+
+	for m := range c.Out {
+		if err = c.Write(m); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Write implements conn.Connection interface
+func (c *framedConn) Write(p []byte) (err error) {
+	if err = c.fc.WriteMessage(p); err == nil {
+		c.AddSentBytes(len(p))
+	}
+	return
+}
+
+// Close implements conn.Connection interface
+func (c *framedConn) Close() {
+	c.fc.Close()
+	c.ConnCommonFields.Close()
+}
+
+// GetRemoteAddr implements conn.Connection interface
+func (c *framedConn) GetRemoteAddr() net.Addr {
+	return c.fc.RemoteAddr()
+}
+
+// IsTCP implements conn.Connection interface, the
+// WebSocket and custom transports are like TCP
+func (c *framedConn) IsTCP() bool {
+	return c.tcp
+}
+
+// IsUDP implements conn.Connection interface,
+// the KCP works over UDP
+func (c *framedConn) IsUDP() bool {
+	return c.tcp == false
+}
+
+// network of given connection: tcp, udp, ws, kcp,
+// unix or scheme of a custom transport
+func network(fc *factory.Connection) string {
+	if c, ok := fc.Connection.(*framedConn); ok == true {
+		return c.network
+	}
+	if isUnix(fc) == true {
+		return "unix"
+	}
+	if fc.IsTCP() == true {
+		return "tcp"
+	}
+	return "udp"
+}
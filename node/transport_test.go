@@ -0,0 +1,185 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// in-process pipes
+
+type pipeAddr string
+
+func (p pipeAddr) Network() string { return "pipe" }
+func (p pipeAddr) String() string  { return string(p) }
+
+type pipeConn struct {
+	r      <-chan []byte
+	w      chan<- []byte
+	closeq chan struct{}
+	closeo sync.Once
+	peer   *pipeConn
+	addr   pipeAddr // remote
+}
+
+func newPipe(a, b pipeAddr) (x, y *pipeConn) {
+
+	var ab, ba = make(chan []byte, 16), make(chan []byte, 16)
+
+	x = &pipeConn{r: ba, w: ab, closeq: make(chan struct{}), addr: b}
+	y = &pipeConn{r: ab, w: ba, closeq: make(chan struct{}), addr: a}
+	x.peer, y.peer = y, x
+
+	return
+}
+
+func (p *pipeConn) ReadMessage() (m []byte, err error) {
+	select {
+	case m = <-p.r:
+		return
+	case <-p.closeq:
+	case <-p.peer.closeq:
+	}
+	return nil, errors.New("closed pipe")
+}
+
+func (p *pipeConn) WriteMessage(m []byte) (err error) {
+	select {
+	case p.w <- append([]byte{}, m...):
+		return
+	case <-p.closeq:
+	case <-p.peer.closeq:
+	}
+	return errors.New("closed pipe")
+}
+
+func (p *pipeConn) RemoteAddr() net.Addr {
+	return p.addr
+}
+
+func (p *pipeConn) Close() (err error) {
+	p.closeo.Do(func() { close(p.closeq) })
+	return
+}
+
+type pipeListener struct {
+	acceptq chan FramedConn
+	closeq  chan struct{}
+	closeo  sync.Once
+}
+
+func (p *pipeListener) Accept() (fc FramedConn, err error) {
+	select {
+	case fc = <-p.acceptq:
+		return
+	case <-p.closeq:
+	}
+	return nil, errors.New("closed listener")
+}
+
+func (p *pipeListener) Close() (err error) {
+	p.closeo.Do(func() { close(p.closeq) })
+	return
+}
+
+type pipeTransport struct {
+	mx sync.Mutex
+	ls map[string]*pipeListener
+	n  int
+}
+
+func (p *pipeTransport) Listen(address string) (l Listener, err error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.ls == nil {
+		p.ls = make(map[string]*pipeListener)
+	}
+
+	var pl = &pipeListener{
+		acceptq: make(chan FramedConn, 1),
+		closeq:  make(chan struct{}),
+	}
+	p.ls[address] = pl
+
+	return pl, nil
+}
+
+func (p *pipeTransport) Dial(address string) (fc FramedConn, err error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	var pl, ok = p.ls[address]
+	if ok == false {
+		return nil, fmt.Errorf("no such pipe %q", address)
+	}
+
+	p.n++
+
+	var x, y = newPipe(pipeAddr(fmt.Sprint("client-", p.n)),
+		pipeAddr(address))
+
+	select {
+	case pl.acceptq <- y:
+	case <-pl.closeq:
+		return nil, errors.New("closed listener")
+	}
+
+	return x, nil
+}
+
+func TestConfig_Transports(t *testing.T) {
+
+	var (
+		pt = new(pipeTransport)
+
+		sconf = getTestConfigNotListen("server")
+		cconf = getTestConfigNotListen("client")
+
+		feed, _ = cipher.GenerateKeyPair()
+	)
+
+	sconf.Transports = map[string]Transport{"pipe": pt}
+	sconf.Listen = Addresses{"pipe://server"}
+	cconf.Transports = map[string]Transport{"pipe": pt}
+
+	var s, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	assertNil(t, s.Share(feed))
+
+	var as = s.ListeningAddresses()
+	assertTrue(t, len(as) == 1 && as[0] == "pipe://server",
+		"wrong listening addresses")
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.Connect("pipe://server"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, network(c.Connection) == "pipe", "wrong network")
+	assertNil(t, c.Subscribe(feed))
+
+	var same *Conn
+	if same, err = cn.Connect("pipe://server"); err != nil {
+		t.Fatal(err)
+	}
+	assertTrue(t, same == c, "duplicate connection")
+
+	cconf.Transports = map[string]Transport{"tcp": pt}
+	assertTrue(t, cconf.Validate() != nil, "missing error")
+
+}
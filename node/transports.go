@@ -1,6 +1,7 @@
 package node
 
 import (
+	"net"
 	"sync"
 	"time"
 
@@ -28,6 +29,8 @@ type TCP struct {
 
 	d *discovery.MessengerFactory // underlying discovery server or nil
 
+	tl net.Listener // TLS listener or nil (see Config.TLS)
+
 	address     string // listening address
 	isListening bool
 
@@ -65,7 +68,13 @@ func (t *TCP) Listen(address string) (err error) {
 		return ErrAlreadyListen
 	}
 
-	if err = t.TCPFactory.Listen(address); err != nil {
+	if tc := t.n.config.TLS; tc != nil {
+		err = t.listenTLS(address, tc)
+	} else {
+		err = t.TCPFactory.Listen(address)
+	}
+
+	if err != nil {
 		return
 	}
 
@@ -96,17 +105,29 @@ func (t *TCP) addAcceptedConnection(c *Conn) {
 // existing connection.
 func (t *TCP) Connect(address string) (c *Conn, err error) {
 
+	if address, err = normalizeHostPort(address); err != nil {
+		return
+	}
+
 	t.mx.Lock()
 	defer t.mx.Unlock()
 
 	var ok bool
-	if c, ok = t.cs[address]; ok == true {
+	if c, ok = t.cs[address]; ok == true && c.isClosed() == false {
 		return // already have
 	}
 
 	var fc *factory.Connection
 
-	if fc, err = t.TCPFactory.Connect(address); err != nil {
+	if tc := t.n.config.TLS; tc != nil {
+		fc, err = t.dialTLS(address, tc)
+	} else if t.n.config.ProxyURL != "" || isOnion(address) == true {
+		fc, err = t.dialProxy(address)
+	} else {
+		fc, err = t.TCPFactory.Connect(address)
+	}
+
+	if err != nil {
 		return
 	}
 
@@ -118,6 +139,18 @@ func (t *TCP) Connect(address string) (c *Conn, err error) {
 	return
 }
 
+// Close the TCP
+func (t *TCP) Close() (err error) {
+
+	t.mx.Lock()
+	if t.tl != nil {
+		t.tl.Close()
+	}
+	t.mx.Unlock()
+
+	return t.TCPFactory.Close()
+}
+
 // Discovery returns underlying MessengerFactory that
 // can be nil, if feature disabled
 func (t *TCP) Discovery() (d *discovery.MessengerFactory) {
@@ -369,11 +402,15 @@ func (u *UDP) addAcceptedConnection(c *Conn) {
 // existing connection.
 func (u *UDP) Connect(address string) (c *Conn, err error) {
 
+	if address, err = normalizeHostPort(address); err != nil {
+		return
+	}
+
 	u.mx.Lock()
 	defer u.mx.Unlock()
 
 	var ok bool
-	if c, ok = u.cs[address]; ok == true {
+	if c, ok = u.cs[address]; ok == true && c.isClosed() == false {
 		return // already have
 	}
 
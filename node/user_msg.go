@@ -0,0 +1,54 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// RegisterUserMsg registers application-defined message
+// type. The t must be msg.UserType or greater. Received
+// messages of the type passed to Config.OnUserMsg. It's
+// possible to send them using (*Conn).SendUserMsg. See
+// msg.RegisterUser for details. The types are global
+// and should be registered before any Node created
+func RegisterUserMsg(t msg.Type, prototype msg.Msg) (err error) {
+	return msg.RegisterUser(t, prototype)
+}
+
+// SendUserMsg sends application-defined message to
+// remote peer (see RegisterUserMsg). The message is
+// not a request and there is no reply. The peer ignores
+// messages of types it doesn't know. The SendUserMsg
+// returns ErrNotSupported if the peer can't receive
+// application-defined messages
+func (c *Conn) SendUserMsg(m msg.Msg) (err error) {
+
+	if t := m.Type(); t.IsUser() == false {
+		return fmt.Errorf("not an application-defined message type %d", t)
+	}
+
+	if c.HasFeature(msg.FeatureUserMsg) == false {
+		return ErrNotSupported
+	}
+
+	c.sendMsg(c.nextSeq(), 0, m)
+	return
+}
+
+// <- application-defined message
+func (c *Conn) handleUserMsg(m msg.Msg) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleUserMsg %s", c.String(),
+		m.Type().String())
+
+	c.n.onUserMsg(c, m)
+}
+
+func (n *Node) onUserMsg(c *Conn, m msg.Msg) {
+
+	if oum := n.config.OnUserMsg; oum != nil {
+		oum(c, m)
+	}
+
+}
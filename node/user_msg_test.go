@@ -0,0 +1,111 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+const (
+	testUserMsgType     = msg.UserType + 1
+	testUnknownUserType = msg.UserType + 2
+)
+
+type testUserMsg struct {
+	Text string
+}
+
+func (*testUserMsg) Type() msg.Type   { return testUserMsgType }
+func (t *testUserMsg) Encode() []byte { return msg.Encode(t) }
+
+// not registered
+type testUnknownUserMsg struct{}
+
+func (*testUnknownUserMsg) Type() msg.Type   { return testUnknownUserType }
+func (t *testUnknownUserMsg) Encode() []byte { return msg.Encode(t) }
+
+func init() {
+	if err := RegisterUserMsg(testUserMsgType, &testUserMsg{}); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterUserMsg(t *testing.T) {
+
+	// reserved
+	if err := RegisterUserMsg(msg.PingType, &msg.Ping{}); err == nil {
+		t.Error("missing error")
+	}
+
+	// type mismatch
+	if err := RegisterUserMsg(msg.UserType, &testUserMsg{}); err == nil {
+		t.Error("missing error")
+	}
+
+	// already registered
+	if err := RegisterUserMsg(testUserMsgType, &testUserMsg{}); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestConn_SendUserMsg(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+
+		received = make(chan msg.Msg, 1)
+	)
+
+	sconf.OnUserMsg = func(c *Conn, m msg.Msg) {
+		received <- m
+	}
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var c *Conn
+	if c, err = cn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.SendUserMsg(&msg.Ping{}); err == nil {
+		t.Error("missing error")
+	}
+
+	// ignored by the server
+	if err = c.SendUserMsg(&testUnknownUserMsg{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.SendUserMsg(&testUserMsg{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-received:
+		if um, ok := m.(*testUserMsg); ok == false {
+			t.Errorf("wrong message type %T", m)
+		} else if um.Text != "hello" {
+			t.Error("wrong message received")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow")
+	}
+
+	if _, ok := sn.hasPeer(cn.ID()); ok == false {
+		t.Error("connection closed")
+	}
+
+}
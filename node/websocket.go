@@ -0,0 +1,210 @@
+package node
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/skycoin/net/factory"
+
+	"github.com/skycoin/cxo/node/ws"
+)
+
+// max size of a message received by WebSocket; objects
+// bigger than the maxChunkSize are sent by chunks, thus
+// messages are small and the limit protects against
+// hostile peers only
+const wsMaxMessageSize = 1 << 20
+
+// A WS represents WebSocket transport of the Node.
+// Every message is sent as a binary WebSocket message.
+// WebSocket connections use the TCP configurations
+// (response timeout and pings), since the WebSocket
+// works over TCP
+type WS struct {
+	// back reference
+	n *Node
+
+	mx sync.Mutex
+
+	l net.Listener // underlying listener or nil
+	s *http.Server // HTTP server
+
+	address string // listening address
+
+	cs  map[string]*Conn                 // address -> conn
+	fcs map[*factory.Connection]struct{} // underlying connections
+}
+
+func newWS(n *Node) (w *WS) {
+
+	w = new(WS)
+
+	w.n = n
+	w.cs = make(map[string]*Conn)
+	w.fcs = make(map[*factory.Connection]struct{})
+
+	return
+}
+
+// Listen on given address. It's possible to listen
+// only once. The WS accepts connections on any path
+func (w *WS) Listen(address string) (err error) {
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.l != nil {
+		return ErrAlreadyListen
+	}
+
+	if w.l, err = net.Listen("tcp", address); err != nil {
+		return
+	}
+
+	w.s = &http.Server{Handler: http.HandlerFunc(w.accept)}
+	w.address = address
+
+	w.n.await.Add(1)
+	go w.run(w.s, w.l)
+
+	return
+}
+
+func (w *WS) run(s *http.Server, l net.Listener) {
+	defer w.n.await.Done()
+	s.Serve(l)
+}
+
+// Address returns listening address as it
+// passed to the Listen method. The address
+// is blank string if the WS is not listening
+func (w *WS) Address() string {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	return w.address
+}
+
+// (HTTP handler)
+func (w *WS) accept(rw http.ResponseWriter, r *http.Request) {
+
+	var wc, err = ws.Upgrade(rw, r)
+
+	if err != nil {
+		w.n.Debugf(NewInConnPin, "can't accept ws://%s: %v", r.RemoteAddr, err)
+		return
+	}
+
+	var fc = w.newConnection(wc)
+
+	if fc == nil {
+		return // closed
+	}
+
+	w.n.acceptConnection(fc)
+}
+
+// wrap given WebSocket connection
+func (w *WS) newConnection(wc *ws.Conn) (fc *factory.Connection) {
+
+	wc.MaxSize = wsMaxMessageSize
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.fcs == nil {
+		wc.Close()
+		return // closed
+	}
+
+	fc = newFramedConn(wc, "ws", true)
+	w.fcs[fc] = struct{}{}
+
+	go w.waitDisconnected(fc)
+	return
+}
+
+func (w *WS) waitDisconnected(fc *factory.Connection) {
+
+	fc.WaitForDisconnected()
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	delete(w.fcs, fc)
+}
+
+func (w *WS) addAcceptedConnection(c *Conn) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.cs[c.Address()] = c
+}
+
+// Connect to given address. The address is ws://host:port/path
+// URL or host:port. The method blocks. If connection with given
+// address already exists, then the Connect returns this existing
+// connection
+func (w *WS) Connect(address string) (c *Conn, err error) {
+
+	if address, err = NormalizeAddress(address); err != nil {
+		return
+	}
+
+	w.mx.Lock()
+	var ok bool
+	if c, ok = w.cs[address]; ok == true && c.isClosed() == false {
+		w.mx.Unlock()
+		return // already have
+	}
+	w.mx.Unlock()
+
+	var (
+		rt = w.n.config.TCP.ResponseTimeout
+		wc *ws.Conn
+	)
+
+	wc, err = ws.DialFunc(address, rt, func(host string) (net.Conn, error) {
+		return w.n.dial(host, rt) // through Config.ProxyURL if set
+	})
+
+	if err != nil {
+		return
+	}
+
+	var fc = w.newConnection(wc)
+
+	if fc == nil {
+		return nil, ErrClosed
+	}
+
+	if c, err = w.n.wrapConnection(fc, false); err != nil {
+		return
+	}
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.cs[address] = c
+	return
+}
+
+// Close the WS
+func (w *WS) Close() (err error) {
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.s != nil {
+		err = w.s.Close()
+	}
+
+	// the HTTP server doesn't close hijacked connections
+	for fc := range w.fcs {
+		fc.Close()
+	}
+
+	w.fcs = nil
+	return
+}
@@ -0,0 +1,72 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestWS_Connect(t *testing.T) {
+
+	var (
+		sconf = getTestConfigNotListen("server")
+		cconf = getTestConfigNotListen("client")
+
+		closed = make(chan struct{})
+	)
+
+	sconf.WSAddress = "127.0.0.1:0"
+	sconf.Public = true
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var address = sn.WS().l.Addr().String()
+
+	cconf.OnDisconnect = func(*Conn, error) {
+		close(closed)
+	}
+
+	var cn *Node
+	if cn, err = NewNode(cconf); err != nil {
+		t.Fatal(err)
+	}
+	defer cn.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var c *Conn
+	if c, err = cn.WS().Connect("ws://" + address + "/cxo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := c.String(); s != "↑ ws://"+address {
+		t.Error("wrong string:", s)
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	if feeds := c.Feeds(); len(feeds) != 1 || feeds[0] != pk {
+		t.Error("not subscribed")
+	}
+
+	if _, err = c.RemoteFeeds(); err != nil {
+		t.Error(err)
+	}
+
+	sn.Close() // close server
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Error("connection is not closed")
+	}
+
+}
@@ -0,0 +1,454 @@
+// Package ws implements minimal WebSocket protocol
+// (RFC 6455) used by WebSocket transport of the node
+// package. Only binary messages are sent. Text messages
+// received are returned as binary. Control frames
+// (ping, pong and close) are handled by the ReadMessage
+package ws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// max size of a frame regardless of the MaxSize
+const maxFrameSize = 1 << 31
+
+// time to send close frame
+const closeTimeout = time.Second
+
+// the GUID of RFC 6455
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// opcodes
+const (
+	opContinuation byte = 0x0
+	opText         byte = 0x1
+	opBinary       byte = 0x2
+	opClose        byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xa
+)
+
+// errors
+var (
+	ErrHandshake   = errors.New("ws: bad handshake")
+	ErrProtocol    = errors.New("ws: protocol error")
+	ErrMessageSize = errors.New("ws: message is too large")
+)
+
+// A Conn represents WebSocket connection
+type Conn struct {
+	c net.Conn      // underlying connection
+	r *bufio.Reader // buffered reader
+
+	client bool // the Conn is client side (masks frames)
+
+	// MaxSize is max size of received message,
+	// zero means no limit. It must be set before
+	// the ReadMessage called
+	MaxSize int
+
+	wmx sync.Mutex // write lock
+}
+
+// compute the Sec-WebSocket-Accept
+func acceptKey(key string) string {
+	var h = sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// has comma separated header given token
+func headerHas(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) == true {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Upgrade given HTTP request to WebSocket connection.
+// The Upgrade replies with error to bad request
+func Upgrade(w http.ResponseWriter, r *http.Request) (c *Conn, err error) {
+
+	var key = r.Header.Get("Sec-WebSocket-Key")
+
+	if r.Method != http.MethodGet ||
+		headerHas(r.Header, "Connection", "upgrade") == false ||
+		headerHas(r.Header, "Upgrade", "websocket") == false ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		key == "" {
+
+		http.Error(w, "WebSocket handshake expected", http.StatusBadRequest)
+		return nil, ErrHandshake
+	}
+
+	var hj, ok = w.(http.Hijacker)
+
+	if ok == false {
+		http.Error(w, "can't hijack", http.StatusInternalServerError)
+		return nil, ErrHandshake
+	}
+
+	var (
+		nc  net.Conn
+		brw *bufio.ReadWriter
+	)
+
+	if nc, brw, err = hj.Hijack(); err != nil {
+		return
+	}
+
+	_, err = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+
+	if err == nil {
+		err = brw.Flush()
+	}
+
+	if err != nil {
+		nc.Close()
+		return
+	}
+
+	return &Conn{c: nc, r: brw.Reader}, nil
+}
+
+// Dial connects to WebSocket server. The address
+// is ws://host:port/path URL or host:port
+func Dial(address string, timeout time.Duration) (c *Conn, err error) {
+	return DialFunc(address, timeout, func(host string) (net.Conn, error) {
+		return net.DialTimeout("tcp", host, timeout)
+	})
+}
+
+// DialFunc is like the Dial, but the dial function
+// used to connect to host:port of the server. For
+// example, to connect through a proxy
+func DialFunc(
+	address string, //                          : URL or host:port
+	timeout time.Duration, //                   : handshake timeout
+	dial func(host string) (net.Conn, error), // : connect to host:port
+) (
+	c *Conn, //                                 : connection
+	err error, //                               : an error
+) {
+
+	if strings.Contains(address, "://") == false {
+		// escape zone of IPv6 address, e.g. [fe80::1%eth0]:80
+		if strings.Contains(address, "%25") == false {
+			address = strings.Replace(address, "%", "%25", 1)
+		}
+		address = "ws://" + address + "/"
+	}
+
+	var u *url.URL
+	if u, err = url.Parse(address); err != nil {
+		return
+	}
+
+	if u.Scheme != "ws" {
+		return nil, fmt.Errorf("ws: unsupported scheme %q", u.Scheme)
+	}
+
+	var nc net.Conn
+	if nc, err = dial(u.Host); err != nil {
+		return
+	}
+
+	if c, err = handshake(nc, u, timeout); err != nil {
+		nc.Close()
+	}
+
+	return
+}
+
+// client side handshake
+func handshake(nc net.Conn, u *url.URL, timeout time.Duration) (
+	c *Conn,
+	err error,
+) {
+
+	if timeout > 0 {
+		nc.SetDeadline(time.Now().Add(timeout))
+		defer nc.SetDeadline(time.Time{})
+	}
+
+	var nonce [16]byte
+	if _, err = io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return
+	}
+
+	var (
+		key  = base64.StdEncoding.EncodeToString(nonce[:])
+		path = u.RequestURI()
+	)
+
+	_, err = io.WriteString(nc, "GET "+path+" HTTP/1.1\r\n"+
+		"Host: "+u.Host+"\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+
+	if err != nil {
+		return
+	}
+
+	var (
+		br   = bufio.NewReader(nc)
+		resp *http.Response
+	)
+
+	if resp, err = http.ReadResponse(br, nil); err != nil {
+		return
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+
+		return nil, ErrHandshake
+	}
+
+	return &Conn{c: nc, r: br, client: true}, nil
+}
+
+// LocalAddr returns local address
+func (c *Conn) LocalAddr() net.Addr {
+	return c.c.LocalAddr()
+}
+
+// RemoteAddr returns remote address
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.c.RemoteAddr()
+}
+
+// SetReadDeadline sets read deadline of
+// underlying connection
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.c.SetReadDeadline(t)
+}
+
+// Close the Conn sending close frame
+func (c *Conn) Close() (err error) {
+	c.c.SetWriteDeadline(time.Now().Add(closeTimeout))
+	c.writeFrame(opClose, nil) // ignore error
+	return c.c.Close()
+}
+
+// WriteMessage sends given binary message
+func (c *Conn) WriteMessage(p []byte) (err error) {
+	return c.writeFrame(opBinary, p)
+}
+
+func (c *Conn) writeFrame(op byte, p []byte) (err error) {
+
+	var (
+		hdr = make([]byte, 2, 14+len(p))
+		ln  = len(p)
+	)
+
+	hdr[0] = 0x80 | op // FIN
+
+	switch {
+	case ln < 126:
+		hdr[1] = byte(ln)
+	case ln <= 0xffff:
+		hdr[1] = 126
+		hdr = hdr[:4]
+		binary.BigEndian.PutUint16(hdr[2:], uint16(ln))
+	default:
+		hdr[1] = 127
+		hdr = hdr[:10]
+		binary.BigEndian.PutUint64(hdr[2:], uint64(ln))
+	}
+
+	var frame = hdr
+
+	if c.client == true {
+
+		var mask [4]byte
+		if _, err = io.ReadFull(rand.Reader, mask[:]); err != nil {
+			return
+		}
+
+		frame[1] |= 0x80
+		frame = append(frame, mask[:]...)
+
+		var start = len(frame)
+		frame = append(frame, p...)
+
+		for i := range frame[start:] {
+			frame[start+i] ^= mask[i%4]
+		}
+
+	} else {
+		frame = append(frame, p...)
+	}
+
+	c.wmx.Lock()
+	defer c.wmx.Unlock()
+
+	_, err = c.c.Write(frame)
+	return
+}
+
+// read header and payload of a frame, the limit
+// is max size of payload or -1 for no limit
+func (c *Conn) readFrame(limit int) (
+	fin bool, //  : final frame
+	op byte, //   : opcode
+	p []byte, //  : unmasked payload
+	err error, // : error
+) {
+
+	var hdr [8]byte
+
+	if _, err = io.ReadFull(c.r, hdr[:2]); err != nil {
+		return
+	}
+
+	fin, op = hdr[0]&0x80 != 0, hdr[0]&0x0f
+
+	if hdr[0]&0x70 != 0 {
+		return false, 0, nil, ErrProtocol // no extensions
+	}
+
+	var (
+		masked = hdr[1]&0x80 != 0
+		ln     = uint64(hdr[1] & 0x7f)
+	)
+
+	// client must mask frames, server must not
+	if masked == c.client {
+		return false, 0, nil, ErrProtocol
+	}
+
+	switch ln {
+	case 126:
+		if _, err = io.ReadFull(c.r, hdr[:2]); err != nil {
+			return
+		}
+		ln = uint64(binary.BigEndian.Uint16(hdr[:2]))
+	case 127:
+		if _, err = io.ReadFull(c.r, hdr[:8]); err != nil {
+			return
+		}
+		ln = binary.BigEndian.Uint64(hdr[:8])
+	}
+
+	if op >= opClose && (ln > 125 || fin == false) {
+		return false, 0, nil, ErrProtocol // control frame
+	}
+
+	if ln > maxFrameSize || (limit >= 0 && ln > uint64(limit)) {
+		return false, 0, nil, ErrMessageSize
+	}
+
+	var mask [4]byte
+
+	if masked == true {
+		if _, err = io.ReadFull(c.r, mask[:]); err != nil {
+			return
+		}
+	}
+
+	p = make([]byte, int(ln))
+
+	if _, err = io.ReadFull(c.r, p); err != nil {
+		return
+	}
+
+	if masked == true {
+		for i := range p {
+			p[i] ^= mask[i%4]
+		}
+	}
+
+	return
+}
+
+// ReadMessage reads next data message. It returns
+// io.EOF if remote peer closes the connection
+func (c *Conn) ReadMessage() (p []byte, err error) {
+
+	var (
+		fin     bool
+		op      byte
+		payload []byte
+
+		started bool // fragmented message
+	)
+
+	for {
+
+		var limit = -1 // no limit
+		if c.MaxSize > 0 {
+			limit = c.MaxSize - len(p) // rest
+		}
+
+		if fin, op, payload, err = c.readFrame(limit); err != nil {
+			return nil, err
+		}
+
+		switch op {
+
+		case opPing:
+
+			if err = c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+
+		case opPong:
+
+			continue
+
+		case opClose:
+
+			c.writeFrame(opClose, nil) // reply
+			return nil, io.EOF
+
+		case opText, opBinary:
+
+			if started == true {
+				return nil, ErrProtocol
+			}
+			started = true
+
+		case opContinuation:
+
+			if started == false {
+				return nil, ErrProtocol
+			}
+
+		default:
+
+			return nil, ErrProtocol
+
+		}
+
+		p = append(p, payload...)
+
+		if fin == true {
+			return
+		}
+	}
+
+}
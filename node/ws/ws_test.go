@@ -0,0 +1,104 @@
+package ws
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func echoServer(t *testing.T) (s *httptest.Server) {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			var c, err = Upgrade(w, r)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			c.MaxSize = 1024
+
+			for {
+				var p []byte
+				if p, err = c.ReadMessage(); err != nil {
+					return
+				}
+				if err = c.WriteMessage(p); err != nil {
+					return
+				}
+			}
+
+		}))
+}
+
+func TestDial(t *testing.T) {
+
+	var s = echoServer(t)
+	defer s.Close()
+
+	var address = strings.TrimPrefix(s.URL, "http://")
+
+	var c, err = Dial(address, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, p := range [][]byte{
+		[]byte("hello"),
+		bytes.Repeat([]byte{1}, 200),  // 16 bit length
+		bytes.Repeat([]byte{2}, 1024), // max
+		{},
+	} {
+
+		if err = c.WriteMessage(p); err != nil {
+			t.Fatal(err)
+		}
+
+		var reply []byte
+		if reply, err = c.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Equal(p, reply) == false {
+			t.Errorf("wrong reply: %d bytes, want %d", len(reply), len(p))
+		}
+
+	}
+
+	// too large, the server closes connection
+	if err = c.WriteMessage(make([]byte, 1025)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.ReadMessage(); err != io.EOF {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestUpgrade(t *testing.T) {
+
+	var s = echoServer(t)
+	defer s.Close()
+
+	// not a WebSocket request
+	var resp, err = http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("wrong status:", resp.StatusCode)
+	}
+
+	if _, err = Dial("http"+strings.TrimPrefix(s.URL, "http"), time.Second); err == nil {
+		t.Error("missing error")
+	}
+
+}
@@ -0,0 +1,271 @@
+package skyobject
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// ArchiveMagic is first bytes of an archive
+// (see Export and Import)
+const ArchiveMagic = "cxoarch1"
+
+// an archiveRoot is first record of an archive
+type archiveRoot struct {
+	Feed  cipher.PubKey
+	Sig   cipher.Sig
+	Value []byte // encoded Root
+}
+
+// write length-prefixed record
+func writeArchiveRecord(w io.Writer, p []byte) (err error) {
+
+	var ln [4]byte
+	binary.LittleEndian.PutUint32(ln[:], uint32(len(p)))
+
+	if _, err = w.Write(ln[:]); err != nil {
+		return
+	}
+
+	_, err = w.Write(p)
+	return
+}
+
+// read length-prefixed record, it returns io.EOF
+// if there are not records anymore
+func (c *Container) readArchiveRecord(r io.Reader) (p []byte, err error) {
+
+	var ln [4]byte
+
+	if _, err = io.ReadFull(r, ln[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = ErrInvalidArchive
+		}
+		return
+	}
+
+	var l = binary.LittleEndian.Uint32(ln[:])
+
+	if int64(l) > int64(c.conf.MaxObjectSize) {
+		err = ErrInvalidArchive
+		return
+	}
+
+	p = make([]byte, l)
+
+	if _, err = io.ReadFull(r, p); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrInvalidArchive
+		}
+	}
+
+	return
+}
+
+// Export writes last Root of given head of given
+// feed with all its objects (including Registry)
+// to given io.Writer. The archive can be imported
+// by another Container (see Import). The Export
+// used to create snapshots of feeds for fast
+// bootstrapping of new nodes
+func (c *Container) Export(
+	w io.Writer, //      : write to
+	pk cipher.PubKey, // : feed
+	nonce uint64, //     : head
+) (
+	err error, //        : an error
+) {
+
+	var r *registry.Root
+	if r, err = c.LastRoot(pk, nonce); err != nil {
+		return
+	}
+
+	return c.ExportRoot(w, r)
+}
+
+// ExportRoot is like the Export, but it
+// exports given Root. The Root must be
+// full and must have signature. A partial
+// Root is not exported (ErrPartialRoot)
+func (c *Container) ExportRoot(
+	w io.Writer, //       : write to
+	r *registry.Root, //  : the Root
+) (
+	err error, //         : an error
+) {
+
+	if r.IsPartial == true {
+		return ErrPartialRoot
+	}
+
+	var bw = bufio.NewWriter(w)
+
+	if _, err = bw.WriteString(ArchiveMagic); err != nil {
+		return
+	}
+
+	err = writeArchiveRecord(bw, encoder.Serialize(archiveRoot{
+		Feed:  r.Pub,
+		Sig:   r.Sig,
+		Value: r.Encode(),
+	}))
+
+	if err != nil {
+		return
+	}
+
+	var written = make(map[cipher.SHA256]struct{})
+
+	err = c.Walk(r, func(hash cipher.SHA256, _ int) (deepper bool, err error) {
+
+		if hash == r.Hash {
+			return // the Root is first record
+		}
+
+		if _, ok := written[hash]; ok == true {
+			return // already written
+		}
+
+		var val []byte
+		if val, _, err = c.Get(hash, 0); err != nil {
+			return
+		}
+
+		if err = writeArchiveRecord(bw, val); err != nil {
+			return
+		}
+
+		written[hash] = struct{}{}
+		return true, nil
+	})
+
+	if err != nil {
+		return
+	}
+
+	return bw.Flush()
+}
+
+// Import reads archive created by the Export and
+// saves the Root with all its objects. The Import
+// adds feed of the Root if the Container doesn't
+// have the feed. The Import keeps objects of the
+// archive in memory until the Root filled. It
+// returns ErrInvalidArchive if the archive is
+// malformed or doesn't contain all objects
+// of the Root
+func (c *Container) Import(
+	rd io.Reader, //      : read from
+) (
+	r *registry.Root, //  : imported Root
+	err error, //         : an error
+) {
+
+	var br = bufio.NewReader(rd)
+
+	var magic [len(ArchiveMagic)]byte
+
+	if _, err = io.ReadFull(br, magic[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrInvalidArchive
+		}
+		return
+	}
+
+	if string(magic[:]) != ArchiveMagic {
+		err = ErrInvalidArchive
+		return
+	}
+
+	var p []byte
+	if p, err = c.readArchiveRecord(br); err != nil {
+		if err == io.EOF {
+			err = ErrInvalidArchive
+		}
+		return
+	}
+
+	var ar archiveRoot
+	if err = encoder.DeserializeRaw(p, &ar); err != nil {
+		return
+	}
+
+	if err = c.AddFeed(ar.Feed); err != nil {
+		return
+	}
+
+	if r, err = c.ReceivedRoot(ar.Feed, ar.Sig, ar.Value); err != nil {
+		return
+	}
+
+	if r.IsFull == true || r.IsPartial == true {
+		return // already have
+	}
+
+	// read objects
+
+	var objs = make(map[cipher.SHA256][]byte)
+
+	for {
+		if p, err = c.readArchiveRecord(br); err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			return
+		}
+		objs[cipher.SumSHA256(p)] = p
+	}
+
+	// fill
+
+	var mp = c.conf.MaxFillingParallel
+
+	if mp <= 0 {
+		mp = 1024 // the Filler can't be used with zero
+	}
+
+	var (
+		rq   = make(chan cipher.SHA256, 1)
+		done = make(chan struct{})
+		exit = make(chan error, 1) // missing object or nil
+		f    = c.Fill(r, rq, mp)
+	)
+
+	go func() {
+		for {
+			select {
+			case key := <-rq:
+				var val, ok = objs[key]
+				if ok == false {
+					exit <- ErrInvalidArchive // missing object
+					f.Close()                 // terminate the Run
+					return
+				}
+				if _, err := c.SetWanted(key, val); err != nil {
+					exit <- err
+					f.Close()
+					return
+				}
+			case <-done:
+				exit <- nil
+				return
+			}
+		}
+	}()
+
+	err = f.Run()
+	close(done)
+
+	if ferr := <-exit; ferr != nil {
+		err = ferr // instead of ErrTerminated
+	}
+
+	return
+}
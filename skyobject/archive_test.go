@@ -0,0 +1,149 @@
+package skyobject
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func testArchiveRoot(t *testing.T, sc *Container) (r *registry.Root) {
+	t.Helper()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var feed = Feed{Head: "Alices' feed"}
+
+	for i := 0; i < 10; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+	}
+
+	r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+	return
+}
+
+func TestContainer_Export(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		r      = testArchiveRoot(t, sc)
+
+		buf bytes.Buffer
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.Export(&buf, r.Pub, r.Nonce))
+
+	var ir, err = rc.Import(bytes.NewReader(buf.Bytes()))
+	assertNil(t, err)
+
+	assertTrue(t, ir.Hash == r.Hash, "wrong Root")
+	assertTrue(t, ir.IsFull == true, "not full")
+
+	var lr *registry.Root
+	lr, err = rc.LastRoot(r.Pub, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "wrong last Root")
+
+	testArchiveWalk(t, sc, rc, r)
+
+	// already have
+	ir, err = rc.Import(bytes.NewReader(buf.Bytes()))
+	assertNil(t, err)
+	assertTrue(t, ir.IsFull == true, "not full")
+
+	testArchiveWalk(t, sc, rc, r)
+
+}
+
+// compare objects and rc of objects of the Root
+func testArchiveWalk(t *testing.T, sc, rc *Container, r *registry.Root) {
+	t.Helper()
+
+	var n int
+
+	assertNil(t, sc.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
+
+		var _, src, err = sc.Get(key, 0)
+		assertNil(t, err)
+
+		var rrc int
+		_, rrc, err = rc.Get(key, 0)
+		assertNil(t, err)
+
+		if src != rrc {
+			t.Error("wrong rc", rrc, src, key.Hex()[:7])
+		}
+
+		n++
+		return true, nil
+	}))
+
+	assertTrue(t, n > 10, "too few objects")
+
+}
+
+func TestContainer_Import(t *testing.T) {
+
+	var (
+		sc = getTestContainer()
+		r  = testArchiveRoot(t, sc)
+
+		buf bytes.Buffer
+	)
+
+	defer sc.Close()
+
+	assertNil(t, sc.Export(&buf, r.Pub, r.Nonce))
+
+	var (
+		archive = buf.Bytes()
+		rootEnd = len(ArchiveMagic) + 4 + int(
+			binary.LittleEndian.Uint32(archive[len(ArchiveMagic):]))
+	)
+
+	for _, tc := range []struct {
+		name    string
+		archive []byte
+	}{
+		{"empty", nil},
+		{"magic", append([]byte("cxoarch0"), archive[len(ArchiveMagic):]...)},
+		{"truncated", archive[:len(archive)-1]},
+		{"root only", archive[:rootEnd]}, // no objects
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var rc = getTestContainer()
+			defer rc.Close()
+
+			var _, err = rc.Import(bytes.NewReader(tc.archive))
+
+			if err != ErrInvalidArchive {
+				t.Error("wrong error:", err)
+			}
+		})
+	}
+
+}
@@ -89,12 +89,6 @@ func (i *item) isFilling() (ok bool) {
 	return len(i.val) == 0
 }
 
-// itemRegistry
-type itemRegistry struct {
-	r           *registry.Registry // the Registry
-	cachePoints                    // LRU or LFU points
-}
-
 // A Cache is internal and used by Container.
 // The Cache can't be created and used outside
 type Cache struct {
@@ -110,7 +104,7 @@ type Cache struct {
 	volumec int // clean down to this (const)
 
 	is map[cipher.SHA256]*item
-	rs map[registry.RegistryRef]*itemRegistry
+	rc *RegistryCache // own or shared
 
 	stat *cxdsStat
 
@@ -135,8 +129,9 @@ func (c *Container) initCache() {
 	c.Cache.volume = 0 // cache volume
 
 	c.Cache.is = make(map[cipher.SHA256]*item, c.conf.CacheMaxAmount)
-	c.Cache.rs = make(map[registry.RegistryRef]*itemRegistry,
-		c.conf.CacheRegistries)
+	if c.Cache.rc = c.conf.RegistryCache; c.Cache.rc == nil {
+		c.Cache.rc = NewRegistryCache(c.conf.CacheRegistries)
+	}
 
 	c.Cache.stat = newCxdsStat(c.conf.RollAvgSamples)
 }
@@ -151,7 +146,7 @@ func (c *Cache) amountVolume() (a, v int) {
 // reset the Cache
 func (c *Cache) reset() {
 	c.is = nil
-	c.rs = nil
+	c.rc = nil
 	c.stat.Close()
 	c.stat = nil
 }
@@ -161,56 +156,10 @@ func (c *Cache) db() data.CXDS {
 	return c.c.db.CXDS()
 }
 
-// call it under lock
-func (c *Cache) addRegistryToCache(r *registry.Registry) {
-
-	// if it already exists
-
-	if ir, ok := c.rs[r.Reference()]; ok == true {
-		ir.touch(c.c.conf.CachePolicy)
-		return
-	}
-
-	// clean if need
-
-	if len(c.rs) == c.c.conf.CacheRegistries {
-
-		var (
-			tormr registry.RegistryRef // reference
-			tormp cachePoints          // points
-		)
-
-		for rr, ir := range c.rs {
-			if ir.cachePoints > tormp {
-				tormr = rr
-				tormp = ir.cachePoints
-			}
-		}
-
-		delete(c.rs, tormr)
-
-	}
-
-	// add
-
-	var ir = &itemRegistry{r: r}
-
-	ir.touch(c.c.conf.CachePolicy)
-	c.rs[r.Reference()] = ir
-}
-
 // AddRegistryToCache adds given registry to Cache
+// (see Config.RegistryCache and Config.CacheRegistries)
 func (c *Cache) AddRegistryToCache(r *registry.Registry) {
-
-	if c.c.conf.CacheRegistries <= 0 {
-		return
-	}
-
-	c.mx.Lock()
-	defer c.mx.Unlock()
-
-	c.addRegistryToCache(r)
-
+	c.rc.Add(r)
 }
 
 // Registry returns Registry by reference. The
@@ -226,11 +175,22 @@ func (c *Cache) Registry(
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
+	return c.registry(rr)
+}
+
+// Registry under lock; the registry resolves external
+// schemas of the Registry (see (*registry.Reg).Import)
+func (c *Cache) registry(
+	rr registry.RegistryRef, // : the reference
+) (
+	r *registry.Registry, //    : registry
+	err error, //               : an error
+) {
+
 	// check out cache first
 
-	if ir, ok := c.rs[rr]; ok == true {
-		r = ir.r
-		ir.touch(c.c.conf.CachePolicy)
+	var ok bool
+	if r, ok = c.rc.Get(rr); ok == true {
 		return
 	}
 
@@ -245,10 +205,12 @@ func (c *Cache) Registry(
 		return
 	}
 
-	if c.c.conf.CacheRegistries >= 0 {
-		c.addRegistryToCache(r)
+	if err = r.Resolve(c.registry); err != nil {
+		return
 	}
 
+	c.rc.Add(r)
+
 	return
 }
 
@@ -1115,6 +1077,26 @@ func (c *Cache) Unwant(
 
 }
 
+// IsWanted returns true if object with given key
+// is wanted by a filler, e.g. Want has been called
+// with positive inc. Thus, the object is going to
+// be received
+func (c *Cache) IsWanted(key cipher.SHA256) (yep bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if it := c.is[key]; it != nil {
+		for _, inc := range it.fwant {
+			if inc > 0 {
+				return true
+			}
+		}
+	}
+
+	return
+}
+
 // SetWanted is like the Set, but is set value
 // only if the value is wanted. The SetWanted
 // never returns "not wanted" error.
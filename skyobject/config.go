@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node/log"
@@ -35,6 +36,11 @@ const (
 
 	MaxFillingParallel int = 10 // ten parallel subtrees
 
+	// verification
+
+	VerifyInterval time.Duration = 0    // disabled
+	VerifySampling float64       = 0.01 // 1% of objects
+
 	// DB related constants
 	CXDS  string = "cxds.db" // default CXDS file name
 	IdxDB string = "idx.db"  // default IdxDB file name
@@ -122,9 +128,15 @@ type Config struct {
 	// field to zero to turn off caching of Registries.
 	// This number should not be too big. Becuse the
 	// CacheCleaning field and strategy doesn't affect
-	// the Registries. If the caceh of the Registries is
-	// full then one removed and one appended
+	// the Registries. The cache of the Registries is
+	// always LRU. The CacheRegistries is ignored if
+	// the RegistryCache is set
 	CacheRegistries int
+	// RegistryCache is cache of Registries that can be
+	// shared between many Containers. If it's nil, then
+	// the Container creates its own RegistryCache using
+	// the CacheRegistries as size
+	RegistryCache *RegistryCache
 	// CacheCleaning is a flaot point number from 0.5 to 0.9.
 	// The number is percent. If the Cache is full, then the
 	// will be cleaned down to this percent of fullness. E.g.
@@ -168,6 +180,39 @@ type Config struct {
 	// to number of connections that used to fill a Root.
 	MaxFillingParallel int
 
+	// HashWorkers is number of goroutines used to encode
+	// and hash values and nodes of a registry.Refs during
+	// publishing. It affects appending many values to a
+	// registry.Refs at once (see (*registry.Refs).AppendValues
+	// and (*registry.Refs).AppendHashes). By default
+	// it's number of CPUs. Set it to 1 to use single
+	// goroutine. The HashWorkers can't be less then 1
+	HashWorkers int
+
+	// VerifyInterval is interval of background
+	// verification of stored data. The verification
+	// checks signatures and hashes of all stored Root
+	// objects and hashes of some objects (see the
+	// VerifySampling), reporting damaged data using
+	// (*Container).CorruptionEvents channel. It's
+	// designed to detect bit rot on long-running
+	// archival nodes. Set it to zero to disable the
+	// verification
+	VerifyInterval time.Duration
+	// VerifySampling is fraction of objects the
+	// verification checks every VerifyInterval,
+	// from 0 to 1
+	VerifySampling float64
+
+	// ValidateFilled turns on validation of filled Root
+	// objects. A Root received from a peer is checked
+	// (see Validate) after all its objects received and
+	// before the Root is saved. A Root with malformed
+	// objects is rejected, and filling fails with the
+	// ValidationError. A node doesn't share a Root
+	// before its validation if the option is true
+	ValidateFilled bool
+
 	// DB configs
 
 	// CheckSizes force Container to check sizes of objects
@@ -178,6 +223,12 @@ type Config struct {
 	// objects alrge then MaxObjectSize limit. But, the
 	// checking stops on first error.
 	CheckSizes bool
+	// IOStat turns on collecting of I/O statistic of
+	// on-drive CXDS: bytes written vs volume of objects,
+	// number of commits and fsync latency histogram. See
+	// the IO field of the Stat. The option is ignored
+	// for DB in memory and for the DB provided by user
+	IOStat bool
 	// InMemoryDB uses database in memory. The option is
 	// usability trick for test. If DB field (see blow) is
 	// nil and this field is treu, then default database in
@@ -220,6 +271,10 @@ func NewConfig() (conf *Config) {
 	conf.CacheMaxItemSize = CacheMaxItemSize
 
 	conf.MaxObjectSize = MaxObjectSize
+	conf.HashWorkers = runtime.NumCPU()
+
+	conf.VerifyInterval = VerifyInterval
+	conf.VerifySampling = VerifySampling
 
 	// data dir
 	conf.DataDir = DataDir()
@@ -242,6 +297,26 @@ func (c *Config) FromFlags() {
 		"db-path",
 		c.DBPath,
 		"path to database")
+	flag.BoolVar(&c.IOStat,
+		"io-stat",
+		c.IOStat,
+		"collect I/O statistic of database")
+	flag.IntVar(&c.HashWorkers,
+		"hash-workers",
+		c.HashWorkers,
+		"number of goroutines to hash objects")
+	flag.DurationVar(&c.VerifyInterval,
+		"verify-interval",
+		c.VerifyInterval,
+		"interval of background verification of DB, zero to disable")
+	flag.Float64Var(&c.VerifySampling,
+		"verify-sampling",
+		c.VerifySampling,
+		"fraction of objects to verify, from 0 to 1")
+	flag.BoolVar(&c.ValidateFilled,
+		"validate-filled",
+		c.ValidateFilled,
+		"validate objects of filled Root objects before saving")
 }
 
 // Validate the Config
@@ -297,5 +372,21 @@ func (c *Config) Validate() error {
 			c.MaxObjectSize)
 	}
 
+	if c.HashWorkers < 1 {
+		return fmt.Errorf("skyobject.Config.HashWorkers is too small: %d",
+			c.HashWorkers)
+	}
+
+	if c.VerifyInterval < 0 {
+		return fmt.Errorf("skyobject.Config.VerifyInterval is negative: %s",
+			c.VerifyInterval)
+	}
+
+	if c.VerifySampling < 0 || c.VerifySampling > 1 {
+		return fmt.Errorf(
+			"skyobject.Config.VerifySampling is out of [0, 1]: %f",
+			c.VerifySampling)
+	}
+
 	return nil
 }
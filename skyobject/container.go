@@ -25,6 +25,10 @@ type Container struct {
 
 	conf *Config // configurations
 
+	v *verifier // background verification or nil
+
+	rh rootHooks // see AddRootHook
+
 	// human readable (used by node for debugging)
 	cxPath, idxPath string
 }
@@ -79,6 +83,8 @@ func NewContainer(conf *Config) (c *Container, err error) {
 		return
 	}
 
+	c.startVerifier()
+
 	return // done
 }
 
@@ -115,7 +121,13 @@ func (c *Container) createDB(conf *Config) (err error) {
 		var cx data.CXDS
 		var idx data.IdxDB
 
-		if cx, err = cxds.NewDriveCXDS(c.cxPath); err != nil {
+		if conf.IOStat == true {
+			cx, err = cxds.NewDriveCXDSWithIOStat(c.cxPath)
+		} else {
+			cx, err = cxds.NewDriveCXDS(c.cxPath)
+		}
+
+		if err != nil {
 			return
 		}
 
@@ -256,7 +268,9 @@ func (c *Container) DB() (db *data.DB) {
 // hash of the Root and Registry (depending on the
 // deepper reply of the WalkFunc).
 //
-// The Walk obtains objects of the Root from DB
+// The Walk obtains objects of the Root from DB. For
+// a partial Root the Walk skips objects filtered out
+// (see registry.Root.IsPartial and Filler.Filter)
 func (c *Container) Walk(
 	r *registry.Root,
 	walkFunc registry.WalkFunc,
@@ -269,7 +283,15 @@ func (c *Container) Walk(
 		return
 	}
 
-	var pack = c.getPack(reg)
+	var pack registry.Pack = c.getPack(reg)
+
+	if r.IsPartial == true {
+		var dr *data.Root
+		if dr, err = c.dataRoot(r.Pub, r.Nonce, r.Seq); err != nil {
+			return
+		}
+		pack = filterPackOf(pack, dr.Filter)
+	}
 
 	return c.walkRoot(pack, r, walkFunc)
 }
@@ -298,6 +320,20 @@ func (c *Container) walkRoot(
 		return
 	}
 
+	// and external registries (ignore deepper)
+
+	var ers = make(map[registry.RegistryRef]*registry.Registry)
+
+	if err = externalRegistries(pack.Registry(), ers); err != nil {
+		return
+	}
+
+	for rr := range ers {
+		if _, err = walkFunc(cipher.SHA256(rr), 0); err != nil {
+			return
+		}
+	}
+
 	return r.Walk(pack, walkFunc)
 }
 
@@ -345,7 +381,7 @@ func (c *Container) RootByHash(
 	}
 
 	r.Sig = dr.Sig
-	r.IsFull = true
+	setFilled(r, dr)
 	return
 }
 
@@ -354,6 +390,8 @@ func (c *Container) RootByHash(
 // with user-provided DB.
 func (c *Container) Close() (err error) {
 
+	c.stopVerifier()
+
 	// the Cache.Close closes CXDS
 	if err = c.Cache.Close(); err == nil {
 		err = c.db.Close()
@@ -0,0 +1,54 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// Delta returns keys of objects of the r the prev doesn't
+// have. Objects with the same hash have the same subtrees,
+// thus the Delta doesn't walk subtrees of objects of the
+// prev. The Root itself is not in the list, but its
+// Registry is, if it's not Registry of the prev. Both
+// Root objects must be full. The Delta used to send only
+// new objects of a Root to subscribers that have the prev
+func (c *Container) Delta(
+	prev *registry.Root, //  : previous Root
+	r *registry.Root, //     : next Root
+) (
+	keys []cipher.SHA256, // : new objects
+	err error, //            : an error
+) {
+
+	var have = make(map[cipher.SHA256]struct{})
+
+	err = c.Walk(prev, func(key cipher.SHA256, _ int) (bool, error) {
+		if _, ok := have[key]; ok == true {
+			return false, nil // already walked
+		}
+		have[key] = struct{}{}
+		return true, nil
+	})
+
+	if err != nil {
+		return
+	}
+
+	err = c.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
+
+		if key == r.Hash {
+			return false, nil // the Root itself
+		}
+
+		if _, ok := have[key]; ok == true {
+			return false, nil // the same subtree
+		}
+
+		have[key] = struct{}{}
+		keys = append(keys, key)
+		return true, nil
+	})
+
+	return
+}
@@ -0,0 +1,75 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_Delta(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var feedRoot = func(posts ...string) (r *registry.Root, dr registry.Dynamic) {
+		var v = testDiffFeed(t, up, "head", posts...)
+		dr = registry.Dynamic{Schema: v.Schema().Reference()}
+		dr.Hash, err = up.Add(v.Encoded())
+		assertNil(t, err)
+		return testValidateRoot(t, c, up, dr), dr
+	}
+
+	var (
+		prev, _ = feedRoot("one", "two")
+		r, dr   = feedRoot("one", "two", "three")
+
+		postHash = func(head string) cipher.SHA256 {
+			return cipher.SumSHA256(encoder.Serialize(Post{Head: head}))
+		}
+	)
+
+	var keys []cipher.SHA256
+	keys, err = c.Delta(prev, r)
+	assertNil(t, err)
+
+	var delta = make(map[cipher.SHA256]struct{})
+	for _, key := range keys {
+		delta[key] = struct{}{}
+	}
+
+	assertTrue(t, len(delta) == len(keys), "duplicate keys")
+
+	for _, key := range []cipher.SHA256{dr.Hash, postHash("three")} {
+		if _, ok := delta[key]; ok == false {
+			t.Error("missing new object", key.Hex()[:7])
+		}
+	}
+
+	for _, key := range []cipher.SHA256{
+		r.Hash,
+		cipher.SHA256(r.Reg),
+		postHash("one"),
+		postHash("two"),
+	} {
+		if _, ok := delta[key]; ok == true {
+			t.Error("unexpected object", key.Hex()[:7])
+		}
+	}
+
+	// the same
+
+	keys, err = c.Delta(r, r)
+	assertNil(t, err)
+	assertTrue(t, len(keys) == 0, "unexpected delta of the same Root")
+
+}
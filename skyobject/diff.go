@@ -0,0 +1,232 @@
+package skyobject
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A ChangeType represents type of a Change
+type ChangeType int
+
+// change types
+const (
+	ChangeAdded    ChangeType = iota // new field, element or object
+	ChangeRemoved                    // removed element or object
+	ChangeModified                   // changed value
+)
+
+// String implements fmt.Stringer interface
+func (c ChangeType) String() string {
+	switch c {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return "ChangeType<" + strconv.Itoa(int(c)) + ">"
+}
+
+// A Change represents difference between two
+// Values found by the DiffValues
+type Change struct {
+	Path string     // path (see registry.Value.ByPath)
+	Type ChangeType // type of the change
+
+	Old registry.Value // old value (zero if added)
+	New registry.Value // new value (zero if removed)
+}
+
+// DiffValues compares two Values and returns changes
+// by path (see registry.Value.ByPath). References are
+// dereferenced and compared recursively, but references
+// with the same hash are not walked. Elements of arrays,
+// slices and Refs are compared by index. Scalar values
+// and values of different types are reported as modified.
+// A nil reference is reported as removed or added. Empty
+// path means the Values themselves
+func DiffValues(old, new registry.Value) (cs []Change, err error) {
+	err = diffValues(&cs, "", old, new)
+	return
+}
+
+// is the Value nil reference or zero Value
+func isNilValue(v registry.Value) bool {
+	return v.Schema() == nil || v.IsNil() == true
+}
+
+func sameType(a, b registry.Schema) bool {
+	return a.Kind() == b.Kind() &&
+		a.ReferenceType() == b.ReferenceType() &&
+		a.Name() == b.Name()
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func diffValues(
+	cs *[]Change, //          : result
+	path string, //           : current path
+	old, new registry.Value, // : values to compare
+) (
+	err error, //             : an error
+) {
+
+	switch on, nn := isNilValue(old), isNilValue(new); {
+	case on == true && nn == true:
+		return
+	case on == true:
+		*cs = append(*cs, Change{Path: path, Type: ChangeAdded, New: new})
+		return
+	case nn == true:
+		*cs = append(*cs, Change{Path: path, Type: ChangeRemoved, Old: old})
+		return
+	}
+
+	if bytes.Equal(old.Encoded(), new.Encoded()) == true &&
+		sameType(old.Schema(), new.Schema()) == true {
+
+		return // the same (the same hash for references)
+	}
+
+	var os, ns = old.Schema(), new.Schema()
+
+	switch os.ReferenceType() {
+
+	case registry.ReferenceTypeSingle, registry.ReferenceTypeDynamic:
+
+		if ns.ReferenceType() != os.ReferenceType() {
+			break // modified
+		}
+
+		if old, err = old.Dereference(); err != nil {
+			return
+		}
+
+		if new, err = new.Dereference(); err != nil {
+			return
+		}
+
+		return diffValues(cs, path, old, new)
+
+	case registry.ReferenceTypeSlice:
+
+		if sameType(os, ns) == false {
+			break // modified
+		}
+
+		return diffElements(cs, path, old, new)
+
+	}
+
+	if sameType(os, ns) == false {
+		*cs = append(*cs, Change{path, ChangeModified, old, new})
+		return
+	}
+
+	switch os.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		return diffElements(cs, path, old, new)
+
+	case reflect.Struct:
+
+		var fs = os.Fields()
+
+		if len(fs) != len(ns.Fields()) {
+			break // modified
+		}
+
+		for i, f := range fs {
+
+			var of, nf registry.Value
+
+			if of, err = old.FieldByIndex(i); err != nil {
+				return
+			}
+
+			if nf, err = new.FieldByIndex(i); err != nil {
+				return
+			}
+
+			if err = diffValues(cs, fieldPath(path, f.Name()), of,
+				nf); err != nil {
+
+				return
+			}
+
+		}
+
+		return
+
+	}
+
+	*cs = append(*cs, Change{path, ChangeModified, old, new})
+	return
+}
+
+// element of array, slice or Refs,
+// zero Value for nil element of Refs
+func elementByIndex(v registry.Value, i int) (ev registry.Value, err error) {
+	if ev, err = v.Index(i); err == registry.ErrRefsElementIsNil {
+		return registry.Value{}, nil
+	}
+	return
+}
+
+// array, slice or Refs
+func diffElements(
+	cs *[]Change, //          : result
+	path string, //           : current path
+	old, new registry.Value, // : values to compare
+) (
+	err error, //             : an error
+) {
+
+	var ol, nl int
+
+	if ol, err = old.Len(); err != nil {
+		return
+	}
+
+	if nl, err = new.Len(); err != nil {
+		return
+	}
+
+	for i := 0; i < ol || i < nl; i++ {
+
+		var oe, ne registry.Value
+
+		if i < ol {
+			if oe, err = elementByIndex(old, i); err != nil {
+				return
+			}
+		}
+
+		if i < nl {
+			if ne, err = elementByIndex(new, i); err != nil {
+				return
+			}
+		}
+
+		if err = diffValues(cs, indexPath(path, i), oe, ne); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
@@ -0,0 +1,109 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func testDiffFeed(
+	t *testing.T,
+	up *Unpack,
+	head string,
+	posts ...string,
+) (
+	v registry.Value,
+) {
+
+	t.Helper()
+
+	var feed = Feed{Head: head}
+
+	for _, p := range posts {
+		assertNil(t, feed.Posts.AppendValues(up, Post{Head: p}))
+	}
+
+	var (
+		dr  = createDynamic(up, testRegistry, "test.Feed", &feed)
+		err error
+	)
+
+	v, err = registry.DynamicValue(up, dr)
+	assertNil(t, err)
+	return
+}
+
+func TestDiffValues(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var _, sk = cipher.GenerateKeyPair()
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var (
+		old = testDiffFeed(t, up, "head", "one", "two", "three")
+		cs  []Change
+	)
+
+	// the same
+
+	cs, err = DiffValues(old, old)
+	assertNil(t, err)
+	assertTrue(t, len(cs) == 0, "unexpected changes")
+
+	// changed
+
+	var new = testDiffFeed(t, up, "new head", "one", "2", "three", "four")
+
+	cs, err = DiffValues(old, new)
+	assertNil(t, err)
+
+	var want = []struct {
+		path string
+		tp   ChangeType
+	}{
+		{"Head", ChangeModified},
+		{"Posts[1].Head", ChangeModified},
+		{"Posts[3]", ChangeAdded},
+	}
+
+	if len(cs) != len(want) {
+		t.Fatal("wrong number of changes", cs)
+	}
+
+	for i, w := range want {
+		if cs[i].Path != w.path || cs[i].Type != w.tp {
+			t.Errorf("wrong change %d: want %s %s, got %s %s", i, w.path,
+				w.tp, cs[i].Path, cs[i].Type)
+		}
+	}
+
+	var head string
+	head, err = cs[0].New.String()
+	assertNil(t, err)
+	assertTrue(t, head == "new head", "wrong new value")
+
+	// removed
+
+	cs, err = DiffValues(new, old)
+	assertNil(t, err)
+	assertTrue(t, len(cs) == 3, fmt.Sprint("wrong number of changes ", cs))
+	assertTrue(t, cs[2].Type == ChangeRemoved, "wrong type of change")
+	assertTrue(t, cs[2].Path == "Posts[3]", "wrong path")
+
+	// nil
+
+	cs, err = DiffValues(registry.Value{}, old)
+	assertNil(t, err)
+	assertTrue(t, len(cs) == 1, "wrong number of changes")
+	assertTrue(t, cs[0].Type == ChangeAdded, "wrong type of change")
+	assertTrue(t, cs[0].Path == "", "wrong path")
+
+}
@@ -2,8 +2,11 @@ package skyobject
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
 )
 
 // common errors
@@ -11,6 +14,15 @@ var (
 	ErrObjectIsTooLarge = errors.New("object is too large (see MaxObjectSize)")
 	ErrTerminated       = errors.New("terminated")
 	ErrBlankRegistryRef = errors.New("blank registry reference")
+	ErrInvalidArchive   = errors.New("invalid archive")
+	ErrCorruptedObject  = errors.New("object doesn't match its hash")
+	ErrInvalidUTF8      = errors.New("invalid UTF-8 string")
+	ErrTrailingBytes    = errors.New("trailing bytes after encoded object")
+	ErrPartialRoot      = errors.New("partial Root")
+
+	// ErrObjectNotFound wraps data.ErrNotFound, thus
+	// errors.Is(err, data.ErrNotFound) is true for it
+	ErrObjectNotFound = fmt.Errorf("object %w", data.ErrNotFound)
 )
 
 // ObjectIsTooLargeError represents error that
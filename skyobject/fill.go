@@ -23,6 +23,8 @@ type Filler struct {
 	incs map[cipher.SHA256]int
 	pre  map[cipher.SHA256]struct{} // prerequested by RC
 
+	filter schemaFilter // schemas to fill, nil for all
+
 	limit chan struct{} // max
 
 	errq chan error
@@ -135,6 +137,13 @@ func (f *Filler) Get(key cipher.SHA256) (val []byte, rc int, err error) {
 	return
 }
 
+// Skip returns true if objects of given Schema
+// are filtered out (see Filter). Objects of not
+// registered schemas are never filtered out
+func (f *Filler) Skip(sch registry.Schema) (skip bool) {
+	return f.filter.skip(sch)
+}
+
 // Fail used to terminate the Filler with
 // provided error
 func (f *Filler) Fail(err error) {
@@ -219,6 +228,22 @@ func (c *Container) Fill(
 	return
 }
 
+// Filter sets names of schemas objects of which
+// the Filler fills. Objects of other registered schemas
+// and their subtrees are not requested. A filtered
+// Root is saved as partial (see registry.Root.IsPartial)
+// with the filter, and walking the Root skips the
+// objects too. Use the same filter for all Root objects
+// of a feed, since a subtree of an object that belongs
+// to another Root never requested. To change the filter
+// delete partial Root objects first (see DelPartialRoots).
+// The filtered Root can't be validated (see
+// Config.ValidateFilled). The Filter must be called
+// before the Run
+func (f *Filler) Filter(schemas ...string) {
+	f.filter = newSchemaFilter(schemas)
+}
+
 func (f *Filler) apply() {
 	for key, inc := range f.incs {
 		if err := f.c.Finc(key, inc); err != nil {
@@ -290,7 +315,7 @@ func (f *Filler) Run() (err error) {
 
 	defer func() {
 		if err != nil {
-			f.r.IsFull = false // reset
+			f.r.IsFull, f.r.IsPartial = false, false // reset
 			f.reject()
 		} else {
 			f.apply()
@@ -320,6 +345,16 @@ func (f *Filler) Run() (err error) {
 	select {
 	case err = <-f.errq:
 	case <-done:
+		if f.filter != nil {
+			f.r.IsFull, f.r.IsPartial = false, true // filtered
+			_, err = f.c.addRootFilter(f.r, f.filter.schemas())
+			break
+		}
+		if f.c.conf.ValidateFilled == true {
+			if err = validateRoot(f.c.getPack(f.reg), f.r); err != nil {
+				break
+			}
+		}
 		f.r.IsFull = true // full!
 		_, err = f.c.AddRoot(f.r)
 	}
@@ -329,6 +364,47 @@ func (f *Filler) Run() (err error) {
 	return
 }
 
+// get registry and its external registries
+// (incrementing); the got map is used to
+// skip already got external registries
+func (f *Filler) getRegistryTree(
+	rr registry.RegistryRef, //               :
+	got map[registry.RegistryRef]struct{}, // : nil for first call
+) (
+	err error, //                             :
+) {
+
+	var val []byte
+	if val, _, err = f.Get(cipher.SHA256(rr)); err != nil {
+		return
+	}
+
+	var reg *registry.Registry
+	if reg, err = registry.DecodeRegistry(val); err != nil {
+		return
+	}
+
+	for _, er := range reg.Externals() {
+
+		if got == nil {
+			got = make(map[registry.RegistryRef]struct{})
+		}
+
+		if _, ok := got[er]; ok == true {
+			continue
+		}
+
+		got[er] = struct{}{}
+
+		if err = f.getRegistryTree(er, got); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
 func (f *Filler) getRegistry() (err error) {
 
 	if f.r.Reg == (registry.RegistryRef{}) {
@@ -336,7 +412,7 @@ func (f *Filler) getRegistry() (err error) {
 	}
 
 	// incrementing
-	if _, _, err = f.Get(cipher.SHA256(f.r.Reg)); err != nil {
+	if err = f.getRegistryTree(f.r.Reg, nil); err != nil {
 		return
 	}
 
@@ -131,6 +131,176 @@ func Test_fillinng(t *testing.T) {
 
 }
 
+// An Article uses test.User of the testRegistry
+type Article struct {
+	Title  string
+	Author registry.Ref `skyobject:"schema=test.User"`
+}
+
+func Test_fillinng_external(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		appRegistry = registry.NewRegistry(func(r *registry.Reg) {
+			r.Import(testRegistry)
+			r.Register("test.Article", Article{})
+		})
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, appRegistry)
+	assertNil(t, err)
+
+	var article = Article{Title: "CXO"}
+
+	assertNil(t, article.Author.SetValue(up, &User{"Alice", 19}))
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, appRegistry, "test.Article", &article),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	// the external registry saved
+	var rc1 int
+	_, rc1, err = sc.Get(cipher.SHA256(testRegistry.Reference()), 0)
+	assertNil(t, err)
+	assertTrue(t, rc1 == 1, fmt.Sprint("wrong rc of external registry ", rc1))
+
+	testFillRoot(t, sc, rc, r)
+	testFillDBs(t, sc, rc)
+
+	// resolved by the receiver
+	var reg *registry.Registry
+	reg, err = rc.Registry(appRegistry.Reference())
+	assertNil(t, err)
+	assertTrue(t, reg.IsResolved(), "not resolved")
+
+}
+
+func Test_fillinng_filter(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var (
+		usr  = User{Name: "Alice", Age: 19}
+		feed = Feed{Head: "Alices' feed", Info: "an average feed"}
+		post = Post{Head: "Head", Body: "Body"}
+	)
+
+	assertNil(t, feed.Posts.AppendValues(up, post))
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &usr),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var (
+		rq = make(chan cipher.SHA256, 10)
+		f  = rc.Fill(r, rq, 10)
+	)
+
+	f.Filter("test.Feed") // without users and posts
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for key := range rq {
+			var val, _, err = sc.Get(key, 0)
+			assertNil(t, err)
+
+			_, err = rc.SetWanted(key, val)
+			assertNil(t, err)
+		}
+
+	}()
+
+	assertNil(t, f.Run())
+
+	close(rq)
+	wg.Wait()
+
+	assertTrue(t, r.IsPartial == true && r.IsFull == false, "not partial")
+
+	for _, tc := range []struct {
+		key  cipher.SHA256
+		name string
+		want bool
+	}{
+		{r.Refs[1].Hash, "feed", true},
+		{r.Refs[0].Hash, "user", false},
+		{feed.Posts.Hash, "posts", false},
+	} {
+		_, _, err = rc.Get(tc.key, 0)
+		if has := (err == nil); has != tc.want {
+			t.Errorf("%s: want %t, got %t", tc.name, tc.want, has)
+		}
+	}
+
+	// saved as partial
+
+	var lr *registry.Root
+	lr, err = rc.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.IsPartial == true && lr.IsFull == false, "not partial")
+
+	lr, err = rc.ReceivedRoot(pk, r.Sig, r.Encode())
+	assertNil(t, err)
+	assertTrue(t, lr.IsPartial == true && lr.IsFull == false, "not partial")
+
+	// the Walk skips filtered out objects
+
+	var walked int
+	assertNil(t, rc.Walk(lr, func(cipher.SHA256, int) (bool, error) {
+		walked++
+		return true, nil
+	}))
+	assertTrue(t, walked == 3, "wrong walk") // Root, Registry, Feed
+
+	// delete to fill again
+
+	var last []*registry.Root
+	last, err = rc.DelPartialRoots(pk)
+	assertNil(t, err)
+	assertTrue(t, len(last) == 1 && last[0].Hash == r.Hash, "wrong last")
+	assertTrue(t, last[0].Sig == r.Sig, "missing signature")
+
+	_, err = rc.LastRoot(pk, r.Nonce)
+	assertTrue(t, err != nil, "not deleted")
+
+	var refs int
+	_, refs, err = rc.Get(r.Refs[1].Hash, 0)
+	assertNil(t, err)
+	assertTrue(t, refs == 0, "object is not released")
+
+}
+
 func testFillRoot(t *testing.T, sc, rc *Container, r *registry.Root) {
 	//t.Helper()
 
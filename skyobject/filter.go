@@ -0,0 +1,84 @@
+package skyobject
+
+import (
+	"sort"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// names of schemas to fill (see Filler.Filter)
+type schemaFilter map[string]struct{}
+
+func newSchemaFilter(schemas []string) (sf schemaFilter) {
+
+	if len(schemas) == 0 {
+		return // nil for all
+	}
+
+	sf = make(schemaFilter, len(schemas))
+
+	for _, name := range schemas {
+		sf[name] = struct{}{}
+	}
+
+	return
+}
+
+// objects of given Schema are filtered out; objects
+// of not registered schemas are never filtered out
+func (s schemaFilter) skip(sch registry.Schema) (skip bool) {
+
+	if s == nil || sch.IsRegistered() == false {
+		return
+	}
+
+	_, ok := s[sch.Name()]
+	return !ok
+}
+
+// sorted names of the schemas, or nil for all
+func (s schemaFilter) schemas() (schemas []string) {
+
+	if s == nil {
+		return
+	}
+
+	schemas = make([]string, 0, len(s))
+
+	for name := range s {
+		schemas = append(schemas, name)
+	}
+
+	sort.Strings(schemas)
+	return
+}
+
+// a Pack of partial Root, the Walk skips objects
+// of filtered out schemas (see registry.Skipper)
+type filterPack struct {
+	registry.Pack
+	filter schemaFilter
+}
+
+// Skip implements registry.Skipper interface
+func (f *filterPack) Skip(sch registry.Schema) (skip bool) {
+	return f.filter.skip(sch)
+}
+
+// wrap given Pack if given filter is not nil
+func filterPackOf(pack registry.Pack, schemas []string) registry.Pack {
+
+	if schemas == nil {
+		return pack // full Root
+	}
+
+	return &filterPack{Pack: pack, filter: newSchemaFilter(schemas)}
+}
+
+// set IsFull or IsPartial of given Root using
+// related data.Root (see data.Root.Filter)
+func setFilled(r *registry.Root, dr *data.Root) {
+	r.IsPartial = dr.Filter != nil
+	r.IsFull = r.IsPartial == false
+}
@@ -34,7 +34,7 @@ func (i *indexHeads) setActive() {
 
 	for nonce, dr := range i.h {
 
-		if i.activet < dr.Time {
+		if dr != nil && i.activet < dr.Time { // skip blank heads
 			i.activet = dr.Time
 			i.activen = nonce
 		}
@@ -313,8 +313,9 @@ func (i *Index) ReceivedRoot(
 		return
 	}
 
-	if _, err = i.findRoot(r.Pub, r.Nonce, r.Seq); err == nil {
-		r.IsFull = true
+	var dr *data.Root
+	if dr, err = i.findRoot(r.Pub, r.Nonce, r.Seq); err == nil {
+		setFilled(r, dr)
 		return
 	} else if err == data.ErrNoSuchHead || err == data.ErrNotFound {
 		err = nil // just not found (e.g. not full)
@@ -324,12 +325,23 @@ func (i *Index) ReceivedRoot(
 	return // data.ErrNoSuchFeed
 }
 
-func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
+func (i *Index) addRoot(
+	r *registry.Root, // : full or partial Root
+	filter []string, //  : schemas of partial Root
+) (
+	alreadyHave bool, // :
+	err error, //        :
+) {
 
-	if r.IsFull == false {
+	if r.IsFull == false && r.IsPartial == false {
 		return false, errors.New("can't add non-full Root: " + r.Short())
 	}
 
+	if r.IsPartial == true && len(filter) == 0 {
+		return false, errors.New("missing filter of partial Root: " +
+			r.Short())
+	}
+
 	if r.Pub == (cipher.PubKey{}) {
 		return false, errors.New("blank public key of Root: " + r.Short())
 	}
@@ -385,6 +397,10 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 		dr.Sig = r.Sig
 		dr.Time = r.Time
 
+		if r.IsPartial == true {
+			dr.Filter = filter
+		}
+
 		return rs.Set(dr)
 	})
 
@@ -447,11 +463,28 @@ func (i *Index) addSavedRoot(r *registry.Root, dr *data.Root) {
 // true. The method never save the Root inside CXDS. E.g. the
 // method adds the Root to index (that is necessary)
 func (i *Index) AddRoot(r *registry.Root) (alreadyHave bool, err error) {
+	return i.addRootFilter(r, nil)
+}
+
+// add full Root or partial Root filled
+// with given filter (see Filler.Filter)
+func (i *Index) addRootFilter(
+	r *registry.Root, // : the Root
+	filter []string, //  : filter of partial Root
+) (
+	alreadyHave bool, // :
+	err error, //        :
+) {
 
 	i.mx.Lock()
-	defer i.mx.Unlock()
+	alreadyHave, err = i.addRoot(r, filter)
+	i.mx.Unlock()
 
-	return i.addRoot(r)
+	if err == nil && alreadyHave == false {
+		i.c.rootAdded(r)
+	}
+
+	return
 }
 
 // ActiveHead returns nonce of head that contains
@@ -558,9 +591,11 @@ func (i *Index) LastRoot(
 		return
 	}
 
-	r, err = i.c.rootByHash(lr.Hash)
+	if r, err = i.c.rootByHash(lr.Hash); err != nil {
+		return
+	}
 
-	r.IsFull = true
+	setFilled(r, lr)
 	r.Sig = lr.Sig
 	return
 }
@@ -569,7 +604,7 @@ func (i *Index) LastRoot(
 func (i *Index) delFeed(
 	pk cipher.PubKey,
 ) (
-	rhs []cipher.SHA256, // hashes of all roots
+	drs []deletedRoot, // all roots
 	err error,
 ) {
 
@@ -598,7 +633,7 @@ func (i *Index) delFeed(
 			}
 
 			err = roots.Ascend(func(dr *data.Root) (err error) {
-				rhs = append(rhs, dr.Hash)
+				drs = append(drs, deletedRootOf(dr))
 				return
 			})
 
@@ -627,7 +662,7 @@ func (i *Index) delFeed(
 func (i *Index) delFeedLock(
 	pk cipher.PubKey,
 ) (
-	rhs []cipher.SHA256,
+	drs []deletedRoot,
 	err error,
 ) {
 
@@ -641,14 +676,14 @@ func (i *Index) delFeedLock(
 func (i *Index) DelFeed(pk cipher.PubKey) (err error) {
 
 	// with lock
-	var rhs []cipher.SHA256
-	if rhs, err = i.delFeedLock(pk); err != nil {
+	var drs []deletedRoot
+	if drs, err = i.delFeedLock(pk); err != nil {
 		return
 	}
 
 	// without lock
-	for _, hash := range rhs {
-		if err = i.delRootRelatedValues(hash); err != nil {
+	for _, dr := range drs {
+		if err = i.delRootRelatedValues(dr); err != nil {
 			return
 		}
 	}
@@ -661,7 +696,7 @@ func (i *Index) delHead(
 	pk cipher.PubKey,
 	nonce uint64,
 ) (
-	rhs []cipher.SHA256, // root hashes
+	drs []deletedRoot, // roots of the head
 	err error,
 ) {
 
@@ -690,7 +725,7 @@ func (i *Index) delHead(
 		}
 
 		err = roots.Ascend(func(dr *data.Root) (err error) {
-			rhs = append(rhs, dr.Hash)
+			drs = append(drs, deletedRootOf(dr))
 			return
 		})
 
@@ -718,7 +753,7 @@ func (i *Index) delHeadLock(
 	pk cipher.PubKey,
 	nonce uint64,
 ) (
-	rhs []cipher.SHA256,
+	drs []deletedRoot,
 	err error,
 ) {
 
@@ -734,15 +769,15 @@ func (i *Index) DelHead(pk cipher.PubKey, nonce uint64) (err error) {
 
 	// with lock
 
-	var rhs []cipher.SHA256
-	if rhs, err = i.delHeadLock(pk, nonce); err != nil {
+	var drs []deletedRoot
+	if drs, err = i.delHeadLock(pk, nonce); err != nil {
 		return
 	}
 
 	// without lock
 
-	for _, hash := range rhs {
-		if err = i.delRootRelatedValues(hash); err != nil {
+	for _, dr := range drs {
+		if err = i.delRootRelatedValues(dr); err != nil {
 			return
 		}
 	}
@@ -760,7 +795,7 @@ func (i *Index) delRoot(
 	nonce uint64, //           : head
 	seq uint64, //             : seq
 ) (
-	rootHash cipher.SHA256, // : hash of the Root
+	del deletedRoot, //        : the Root
 	err error, //              : an error
 ) {
 
@@ -806,7 +841,7 @@ func (i *Index) delRoot(
 		// keep hash of the Root to remove
 		// from CXDS with all related objects
 
-		rootHash = dr.Hash
+		del = deletedRootOf(dr)
 
 		// if found, then the ir is not nil, because the head is not
 		// blank and we keep last Root of every head in the Index
@@ -853,7 +888,7 @@ func (i *Index) delRootLock(
 	nonce uint64, //           : head
 	seq uint64, //             : seq
 ) (
-	rootHash cipher.SHA256, // : hash of the Root
+	del deletedRoot, //        : the Root
 	err error, //              : an error
 ) {
 
@@ -865,6 +900,7 @@ func (i *Index) delRootLock(
 
 func (i *Index) delPackWalkFunc(
 	r *registry.Root, //           : Root
+	filter []string, //            : filter of partial Root
 ) (
 	pack registry.Pack, //         : special Pack for deleting
 	walkFunc registry.WalkFunc, // : walk deleting
@@ -898,6 +934,8 @@ func (i *Index) delPackWalkFunc(
 
 		// keep last if it was deleted
 		if rc == 0 {
+			i.c.objectDeleted(r.Pub, hash, len(val))
+
 			dpack.last = hash
 			dpack.val = val
 
@@ -909,17 +947,31 @@ func (i *Index) delPackWalkFunc(
 
 	}
 
-	pack = dpack
+	pack = filterPackOf(dpack, filter)
 
 	return
 }
 
+// deleted Root to decrement related values
+type deletedRoot struct {
+	hash   cipher.SHA256 // hash of the Root
+	filter []string      // filter of partial Root
+}
+
+func deletedRootOf(dr *data.Root) (del deletedRoot) {
+	del.hash = dr.Hash
+	if dr.Filter != nil {
+		del.filter = append([]string{}, dr.Filter...) // copy
+	}
+	return
+}
+
 // delRootRelatedValues decrements all values related to
 // given Root, including the Root itself and its Registry
-func (i *Index) delRootRelatedValues(rootHash cipher.SHA256) (err error) {
+func (i *Index) delRootRelatedValues(del deletedRoot) (err error) {
 
 	var r *registry.Root
-	if r, err = i.c.rootByHash(rootHash); err != nil {
+	if r, err = i.c.rootByHash(del.hash); err != nil {
 		return
 	}
 
@@ -928,7 +980,7 @@ func (i *Index) delRootRelatedValues(rootHash cipher.SHA256) (err error) {
 		walkFunc registry.WalkFunc
 	)
 
-	if pack, walkFunc, err = i.delPackWalkFunc(r); err != nil {
+	if pack, walkFunc, err = i.delPackWalkFunc(r, del.filter); err != nil {
 		return
 	}
 
@@ -940,13 +992,116 @@ func (i *Index) delRootRelatedValues(rootHash cipher.SHA256) (err error) {
 func (i *Index) DelRoot(pk cipher.PubKey, nonce, seq uint64) (err error) {
 
 	// with lock
-	var rootHash cipher.SHA256
-	if rootHash, err = i.delRootLock(pk, nonce, seq); err != nil {
+	var del deletedRoot
+	if del, err = i.delRootLock(pk, nonce, seq); err != nil {
 		return
 	}
 
 	// without lock
-	return i.delRootRelatedValues(rootHash)
+	return i.delRootRelatedValues(del)
+}
+
+// DelPartialRoots deletes all partial Root objects of given
+// feed (see Filler.Filter) and returns last Root objects of
+// heads among deleted, to fill them again with another
+// filter. Returned Root objects are not full and not partial
+func (i *Index) DelPartialRoots(
+	pk cipher.PubKey, //       : the feed
+) (
+	last []*registry.Root, //  : deleted last Root objects
+	err error, //              : an error
+) {
+
+	// with lock
+	var drs []deletedRoot
+	if last, drs, err = i.delPartialRootsLock(pk); err != nil {
+		return
+	}
+
+	// without lock
+	for _, del := range drs {
+		if err = i.delRootRelatedValues(del); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// with lock
+func (i *Index) delPartialRootsLock(
+	pk cipher.PubKey, //      : the feed
+) (
+	last []*registry.Root, // : deleted last Root objects
+	drs []deletedRoot, //     : deleted Root objects
+	err error, //             : an error
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs, ok = i.feeds[pk]
+
+	if ok == false {
+		return nil, nil, data.ErrNoSuchFeed
+	}
+
+	for nonce, lr := range hs.h {
+
+		if lr == nil {
+			continue // blank head
+		}
+
+		if lr.Filter != nil {
+
+			var r *registry.Root
+			if r, err = i.c.rootByHash(lr.Hash); err != nil {
+				return
+			}
+
+			r.Sig = lr.Sig
+			last = append(last, r)
+		}
+
+		var seqs []uint64
+
+		err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+
+			var heads data.Heads
+			if heads, err = feeds.Heads(pk); err != nil {
+				return
+			}
+
+			var roots data.Roots
+			if roots, err = heads.Roots(nonce); err != nil {
+				return
+			}
+
+			return roots.Ascend(func(dr *data.Root) (_ error) {
+				if dr.Filter != nil {
+					seqs = append(seqs, dr.Seq)
+				}
+				return
+			})
+		})
+
+		if err != nil {
+			return
+		}
+
+		for _, seq := range seqs {
+
+			var del deletedRoot
+			if del, err = i.delRoot(pk, nonce, seq); err != nil {
+				return
+			}
+
+			drs = append(drs, del)
+		}
+
+	}
+
+	return
 }
 
 // Feeds returns list of feeds. For performance
@@ -1126,7 +1281,7 @@ func (i *Index) Root(
 		return
 	}
 
-	r.IsFull = true
+	setFilled(r, dr)
 	r.Sig = dr.Sig
 
 	return
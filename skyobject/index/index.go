@@ -0,0 +1,362 @@
+// Package index implements secondary indices over
+// fields of objects. An Index maps values of declared
+// fields to hashes of objects. For example
+//
+//     idx, err := index.New(c, "index.db",
+//         index.On("cxo.Post", "Author"))
+//
+//     // ...
+//
+//     hashes := idx.Lookup("Author", pk)
+//
+// The Index attaches to a Container and updates itself
+// for every new Root saved or filled by the Container.
+// Only objects (values that have their own hashes) are
+// indexed. E.g. a cxo.Post embedded in another object
+// is not indexed, but a cxo.Post referenced by a Ref,
+// Refs or Dynamic is indexed. Since objects are content
+// addressed, an object is indexed once. The Index never
+// removes entries of removed objects and a hash returned
+// by the Lookup can point to an object that has been
+// removed from the Container
+package index
+
+import (
+	"log"
+	"reflect"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A Field represents indexed field of objects
+// of a type. Use On to create a Field
+type Field struct {
+	Schema string // name of registered Schema
+	Path   string // path of field (see registry.Value.ByPath)
+}
+
+// On creates Field of objects of given type
+// (by name of registered Schema). The path is
+// path of the field (see registry.Value.ByPath)
+func On(schema, path string) (f Field) {
+	return Field{Schema: schema, Path: path}
+}
+
+// unique key of the Field
+func (f Field) key() string {
+	return f.Schema + "|" + f.Path
+}
+
+// An Index represents secondary index over fields
+// of objects of a Container. Use New to create an
+// Index. The Index is safe for concurrent use
+type Index struct {
+	mx sync.Mutex // lock indexing
+
+	c      *skyobject.Container
+	fields map[string][]Field // schema name -> fields
+	paths  map[string][]Field // path -> fields
+
+	s   store
+	del func() // remove the hook
+}
+
+// New creates Index of given fields of objects of
+// given Container. If the fileName is empty, then the
+// Index keeps entries in memory. Otherwise, the Index
+// keeps entries in the file and it's possible to use
+// the Index between restarts. If declared fields of
+// the file changed, then the Index indexes all objects
+// again. The New indexes last Root objects of every
+// head of every feed of the Container, and after that
+// the Index is updated by the Container
+func New(
+	c *skyobject.Container, // : the Container
+	fileName string, //        : file or empty string
+	fields ...Field, //        : indexed fields
+) (
+	i *Index, //               : the Index
+	err error, //              : an error
+) {
+
+	i = new(Index)
+
+	i.c = c
+	i.fields = make(map[string][]Field)
+	i.paths = make(map[string][]Field)
+
+	var keys []string
+
+	for _, f := range fields {
+		i.fields[f.Schema] = append(i.fields[f.Schema], f)
+		i.paths[f.Path] = append(i.paths[f.Path], f)
+		keys = append(keys, f.key())
+	}
+
+	if fileName == "" {
+		i.s = newMemoryStore()
+	} else if i.s, err = newDriveStore(fileName, keys); err != nil {
+		return nil, err
+	}
+
+	i.del = c.AddRootHook(i.rootHook)
+
+	if err = i.indexLastRoots(); err != nil {
+		i.Close()
+		return nil, err
+	}
+
+	return
+}
+
+// index last Root objects of the Container
+func (i *Index) indexLastRoots() (err error) {
+
+	for _, pk := range i.c.Feeds() {
+
+		var heads []uint64
+		if heads, err = i.c.Heads(pk); err != nil {
+			return
+		}
+
+		for _, nonce := range heads {
+
+			var r *registry.Root
+
+			if r, err = i.c.LastRoot(pk, nonce); err == data.ErrNotFound {
+				continue // blank head
+			} else if err != nil {
+				return
+			}
+
+			if err = i.AddRoot(r); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// the RootHook
+func (i *Index) rootHook(r *registry.Root) {
+	if err := i.AddRoot(r); err != nil {
+		log.Printf("[ERR] [index] can't index Root %s: %v", r.Short(), err)
+	}
+}
+
+// AddRoot indexes objects of given Root. The Root
+// should be full. It's not necessary to call the
+// method, since the Index is updated by Container
+// automatically. But it can be used to index old
+// Root objects
+func (i *Index) AddRoot(r *registry.Root) (err error) {
+
+	if len(i.fields) == 0 {
+		return // nothing to index
+	}
+
+	var pack *skyobject.Pack
+	if pack, err = i.c.Pack(r, nil); err != nil {
+		return
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	for _, dr := range r.Refs {
+
+		if dr.IsBlank() == true {
+			continue
+		}
+
+		var v registry.Value
+		if v, err = registry.DynamicValue(pack, dr); err != nil {
+			return
+		}
+
+		if err = i.walkObject(v); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// walk an object
+func (i *Index) walkObject(v registry.Value) (err error) {
+
+	var (
+		hash = cipher.SumSHA256(v.Encoded())
+		ok   bool
+	)
+
+	if ok, err = i.s.indexed(hash); err != nil || ok == true {
+		return // the object and its subtree are indexed
+	}
+
+	if err = i.walk(v); err != nil {
+		return
+	}
+
+	// add after the subtree, thus the object is
+	// indexed again if the walking fails
+
+	var es []entry
+	if es, err = i.entries(v); err != nil {
+		return
+	}
+
+	return i.s.add(hash, es)
+}
+
+// entries of the object
+func (i *Index) entries(v registry.Value) (es []entry, err error) {
+
+	for _, f := range i.fields[v.Schema().Name()] {
+
+		var fv registry.Value
+		if fv, err = v.ByPath(f.Path); err != nil {
+			return
+		}
+
+		es = append(es, entry{f.key(), fv.Encoded()})
+	}
+
+	return
+}
+
+// walk references of a value
+func (i *Index) walk(v registry.Value) (err error) {
+
+	var s = v.Schema()
+
+	if s.HasReferences() == false {
+		return
+	}
+
+	switch s.ReferenceType() {
+
+	case registry.ReferenceTypeSingle, registry.ReferenceTypeDynamic:
+
+		if v.IsNil() == true {
+			return
+		}
+
+		var dv registry.Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return i.walkObject(dv)
+
+	case registry.ReferenceTypeSlice:
+
+		return i.walkElements(v, true)
+
+	}
+
+	switch s.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		return i.walkElements(v, false)
+
+	case reflect.Struct:
+
+		for k := range s.Fields() {
+
+			var fv registry.Value
+			if fv, err = v.FieldByIndex(k); err != nil {
+				return
+			}
+
+			if err = i.walk(fv); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// array, slice or Refs
+func (i *Index) walkElements(v registry.Value, isRefs bool) (err error) {
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	for k := 0; k < ln; k++ {
+
+		var ev registry.Value
+
+		if ev, err = v.Index(k); err == registry.ErrRefsElementIsNil {
+			continue
+		} else if err != nil {
+			return
+		}
+
+		if isRefs == true {
+			err = i.walkObject(ev)
+		} else {
+			err = i.walk(ev)
+		}
+
+		if err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// Lookup returns hashes of objects that have given
+// value of field with given path. The value should have
+// the same Go type as the field, since encoded values
+// are compared. If many types have indexed field with
+// the path, then objects of all the types are looked
+// up. The Lookup returns nil if the field is not
+// indexed or on DB failure
+func (i *Index) Lookup(field string, value interface{}) (hashes []cipher.SHA256) {
+
+	var val = encoder.Serialize(value)
+
+	for _, f := range i.paths[field] {
+
+		var hs, err = i.s.lookup(f.key(), val)
+
+		if err != nil {
+			log.Printf("[ERR] [index] lookup %s: %v", f.key(), err)
+			return nil
+		}
+
+		hashes = append(hashes, hs...)
+	}
+
+	return
+}
+
+// Close the Index detaching it from the Container.
+// The Close doesn't close the Container
+func (i *Index) Close() (err error) {
+	i.del()
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	return i.s.close()
+}
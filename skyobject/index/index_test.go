@@ -0,0 +1,179 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+type Post struct {
+	Author cipher.PubKey
+	Title  string
+}
+
+type Feed struct {
+	Posts registry.Refs `skyobject:"schema=i.Post"`
+}
+
+var testRegistry = registry.NewRegistry(func(r *registry.Reg) {
+	r.Register("i.Post", Post{})
+	r.Register("i.Feed", Feed{})
+})
+
+type testFeed struct {
+	c    *skyobject.Container
+	up   *skyobject.Unpack
+	r    *registry.Root
+	feed Feed
+}
+
+func newTestFeed(t *testing.T) (tf *testFeed) {
+
+	t.Helper()
+
+	var conf = skyobject.NewConfig()
+	conf.InMemoryDB = true
+	conf.DataDir = ""
+
+	var c, err = skyobject.NewContainer(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	if err = c.AddFeed(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	tf = &testFeed{c: c}
+
+	if tf.up, err = c.Unpack(sk, testRegistry); err != nil {
+		t.Fatal(err)
+	}
+
+	tf.r = &registry.Root{Pub: pk, Nonce: 1}
+	return
+}
+
+// add posts and save the Root
+func (tf *testFeed) post(t *testing.T, author cipher.PubKey, n int) {
+
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		var err = tf.feed.Posts.AppendValues(tf.up, Post{
+			Author: author,
+			Title:  fmt.Sprintf("Post #%d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var dr registry.Dynamic
+	if err := dr.SetValue(tf.up, &tf.feed); err != nil {
+		t.Fatal(err)
+	}
+
+	var sch, err = testRegistry.SchemaByName("i.Feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dr.Schema = sch.Reference()
+
+	tf.r.Refs = []registry.Dynamic{dr}
+
+	if err = tf.c.Save(tf.up, tf.r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testLookup(t *testing.T, idx *Index, author cipher.PubKey, want int) {
+
+	t.Helper()
+
+	if got := len(idx.Lookup("Author", author)); got != want {
+		t.Errorf("wrong number of objects: want %d, got %d", want, got)
+	}
+}
+
+func TestIndex_Lookup(t *testing.T) {
+
+	var (
+		tf       = newTestFeed(t)
+		alice, _ = cipher.GenerateKeyPair()
+		bob, _   = cipher.GenerateKeyPair()
+		carol, _ = cipher.GenerateKeyPair()
+	)
+
+	tf.post(t, alice, 3) // before the Index created
+
+	var idx, err = New(tf.c, "", On("i.Post", "Author"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testLookup(t, idx, alice, 3) // last Root indexed by the New
+
+	tf.post(t, bob, 2) // new Root
+
+	testLookup(t, idx, alice, 3)
+	testLookup(t, idx, bob, 2)
+	testLookup(t, idx, carol, 0)
+
+	if len(idx.Lookup("Title", "Post #0")) != 0 {
+		t.Error("not indexed field is looked up")
+	}
+
+	// the Index doesn't follow the Container after Close
+	idx.Close()
+	tf.post(t, carol, 1)
+	testLookup(t, idx, carol, 0)
+
+}
+
+func TestIndex_drive(t *testing.T) {
+
+	var (
+		tf       = newTestFeed(t)
+		alice, _ = cipher.GenerateKeyPair()
+		fileName = filepath.Join(t.TempDir(), "index.db")
+	)
+
+	var idx, err = New(tf.c, fileName, On("i.Post", "Author"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tf.post(t, alice, 2)
+	testLookup(t, idx, alice, 2)
+
+	if err = idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// reopen
+
+	if idx, err = New(tf.c, fileName, On("i.Post", "Author")); err != nil {
+		t.Fatal(err)
+	}
+
+	testLookup(t, idx, alice, 2)
+
+	if err = idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+}
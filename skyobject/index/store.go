@@ -0,0 +1,255 @@
+package index
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// storage of the Index
+type store interface {
+	// has the object been indexed
+	indexed(hash cipher.SHA256) (ok bool, err error)
+	// add entries of an object and mark it as indexed
+	add(hash cipher.SHA256, es []entry) (err error)
+	// hashes of objects by field and encoded value
+	lookup(field string, val []byte) (hashes []cipher.SHA256, err error)
+	close() (err error)
+}
+
+// an entry of the Index
+type entry struct {
+	field string // key of a Field
+	val   []byte // encoded value of the field
+}
+
+// key is encoded value followed by hash of object
+func entryKey(val []byte, hash cipher.SHA256) (key []byte) {
+	key = make([]byte, 0, len(val)+len(hash))
+	key = append(key, val...)
+	return append(key, hash[:]...)
+}
+
+type memoryStore struct {
+	mx   sync.Mutex
+	objs map[cipher.SHA256]struct{}
+	es   map[string]map[string][]cipher.SHA256 // field -> value -> hashes
+}
+
+func newMemoryStore() (m *memoryStore) {
+	m = new(memoryStore)
+	m.objs = make(map[cipher.SHA256]struct{})
+	m.es = make(map[string]map[string][]cipher.SHA256)
+	return
+}
+
+func (m *memoryStore) indexed(hash cipher.SHA256) (ok bool, _ error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	_, ok = m.objs[hash]
+	return
+}
+
+func (m *memoryStore) add(hash cipher.SHA256, es []entry) (_ error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if _, ok := m.objs[hash]; ok == true {
+		return
+	}
+
+	m.objs[hash] = struct{}{}
+
+	for _, e := range es {
+		var vs, ok = m.es[e.field]
+		if ok == false {
+			vs = make(map[string][]cipher.SHA256)
+			m.es[e.field] = vs
+		}
+		vs[string(e.val)] = append(vs[string(e.val)], hash)
+	}
+
+	return
+}
+
+func (m *memoryStore) lookup(
+	field string,
+	val []byte,
+) (
+	hashes []cipher.SHA256,
+	_ error,
+) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	var hs = m.es[field][string(val)]
+
+	if len(hs) == 0 {
+		return
+	}
+
+	hashes = make([]cipher.SHA256, len(hs))
+	copy(hashes, hs)
+	return
+}
+
+func (m *memoryStore) close() (_ error) {
+	return
+}
+
+var (
+	objsBucket = []byte("o")      // indexed objects
+	metaBucket = []byte("m")      // meta information
+	fieldsKey  = []byte("fields") // declared fields
+)
+
+// a bucket per field
+func fieldBucket(field string) []byte {
+	return []byte("f:" + field)
+}
+
+type driveStore struct {
+	b *bolt.DB
+}
+
+// the fields are keys of Field; if the fields
+// changed, then all objects should be indexed again
+func newDriveStore(fileName string, fields []string) (d *driveStore,
+	err error) {
+
+	var b *bolt.DB
+
+	b, err = bolt.Open(fileName, 0644, &bolt.Options{
+		Timeout: time.Millisecond * 500,
+	})
+
+	if err != nil {
+		return
+	}
+
+	var keys = make([]string, len(fields))
+	copy(keys, fields)
+	sort.Strings(keys)
+
+	var declared = []byte(strings.Join(keys, "\n"))
+
+	err = b.Update(func(tx *bolt.Tx) (err error) {
+
+		var meta *bolt.Bucket
+		if meta, err = tx.CreateBucketIfNotExists(metaBucket); err != nil {
+			return
+		}
+
+		if bytes.Equal(meta.Get(fieldsKey), declared) == false {
+
+			// reset indexed objects to index them again
+			if tx.Bucket(objsBucket) != nil {
+				if err = tx.DeleteBucket(objsBucket); err != nil {
+					return
+				}
+			}
+
+			if err = meta.Put(fieldsKey, declared); err != nil {
+				return
+			}
+
+		}
+
+		if _, err = tx.CreateBucketIfNotExists(objsBucket); err != nil {
+			return
+		}
+
+		for _, field := range keys {
+			_, err = tx.CreateBucketIfNotExists(fieldBucket(field))
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	if err != nil {
+		b.Close()
+		return
+	}
+
+	d = &driveStore{b}
+	return
+}
+
+func (d *driveStore) indexed(hash cipher.SHA256) (ok bool, err error) {
+	err = d.b.View(func(tx *bolt.Tx) (_ error) {
+		ok = tx.Bucket(objsBucket).Get(hash[:]) != nil
+		return
+	})
+	return
+}
+
+func (d *driveStore) add(hash cipher.SHA256, es []entry) (err error) {
+	return d.b.Update(func(tx *bolt.Tx) (err error) {
+
+		var objs = tx.Bucket(objsBucket)
+
+		if objs.Get(hash[:]) != nil {
+			return // already indexed
+		}
+
+		for _, e := range es {
+			var fb = tx.Bucket(fieldBucket(e.field))
+			if err = fb.Put(entryKey(e.val, hash), []byte{}); err != nil {
+				return
+			}
+		}
+
+		return objs.Put(hash[:], []byte{})
+	})
+}
+
+func (d *driveStore) lookup(
+	field string,
+	val []byte,
+) (
+	hashes []cipher.SHA256,
+	err error,
+) {
+
+	err = d.b.View(func(tx *bolt.Tx) (_ error) {
+
+		var fb = tx.Bucket(fieldBucket(field))
+
+		if fb == nil {
+			return // not indexed
+		}
+
+		var c = fb.Cursor()
+
+		for k, _ := c.Seek(val); bytes.HasPrefix(k, val); k, _ = c.Next() {
+
+			// the key is the value and the hash, but longer
+			// value can have the val as prefix
+			if len(k) != len(val)+len(cipher.SHA256{}) {
+				continue
+			}
+
+			var hash cipher.SHA256
+			copy(hash[:], k[len(val):])
+			hashes = append(hashes, hash)
+		}
+
+		return
+	})
+
+	return
+}
+
+func (d *driveStore) close() (err error) {
+	return d.b.Close()
+}
@@ -0,0 +1,66 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// Migrate rewrites given Root to given Registry producing
+// new Root. Every branch of the Root (every element of
+// the Refs) converted using the Transform method of the
+// new Registry (see registry.Registry.AddTransform).
+// The new Root has the same Pub, Nonce and Descriptor
+// and it's saved as the last Root of the feed. The sk
+// is owner of the feed. The Registry of the old Root
+// should be available (saved or cached)
+func (c *Container) Migrate(
+	r *registry.Root, //           : old Root (full)
+	reg *registry.Registry, //     : new Registry
+	sk cipher.SecKey, //           : owner
+) (
+	nr *registry.Root, //          : new Root
+	err error, //                  : an error
+) {
+
+	var pack *Pack
+	if pack, err = c.Pack(r, nil); err != nil {
+		return
+	}
+
+	var up *Unpack
+	if up, err = c.Unpack(sk, reg); err != nil {
+		return
+	}
+	defer up.Close()
+
+	nr = &registry.Root{
+		Refs:       make([]registry.Dynamic, len(r.Refs)),
+		Descriptor: r.Descriptor,
+		Pub:        r.Pub,
+		Nonce:      r.Nonce,
+	}
+
+	for i, dr := range r.Refs {
+
+		if dr.IsBlank() == true {
+			continue
+		}
+
+		var v registry.Value
+		if v, err = registry.DynamicValue(pack, dr); err != nil {
+			return nil, err
+		}
+
+		if nr.Refs[i], err = reg.Transform(v, up); err != nil {
+			return nil, err
+		}
+
+	}
+
+	if err = c.Save(up, nr); err != nil {
+		return nil, err
+	}
+
+	return
+}
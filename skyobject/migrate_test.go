@@ -0,0 +1,119 @@
+package skyobject
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// UserV2 is the User with email
+type UserV2 struct {
+	Name  string
+	Age   uint32
+	Email string
+}
+
+func testMigrateRegistry() *registry.Registry {
+	return registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", UserV2{})
+		r.Register("test.Feed", Feed{})
+		r.Register("test.Post", Post{})
+	})
+}
+
+func testUserToV2(
+	v registry.Value,
+	pack registry.Pack,
+) (
+	hash cipher.SHA256,
+	err error,
+) {
+
+	var user User
+	if err = encoder.DeserializeRaw(v.Encoded(), &user); err != nil {
+		return
+	}
+
+	return pack.Add(encoder.Serialize(UserV2{
+		Name:  user.Name,
+		Age:   user.Age,
+		Email: user.Name + "@example.com",
+	}))
+}
+
+func TestContainer_Migrate(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{
+		Pub:   pk,
+		Nonce: 1,
+		Refs: []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+			createDynamic(up, testRegistry, "test.Feed", &Feed{Head: "head"}),
+			{}, // blank
+		},
+		Descriptor: []byte("descriptor"),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	// missing transform
+
+	var reg = testMigrateRegistry()
+
+	_, err = c.Migrate(r, reg, sk)
+	assertTrue(t, errors.Is(err, registry.ErrMissingTransform),
+		"wrong error")
+
+	// migrate
+
+	var from, to registry.Schema
+
+	from, err = testRegistry.SchemaByName("test.User")
+	assertNil(t, err)
+	to, err = reg.SchemaByName("test.User")
+	assertNil(t, err)
+
+	reg.AddTransform(from.Reference(), to.Reference(), testUserToV2)
+
+	var nr *registry.Root
+	nr, err = c.Migrate(r, reg, sk)
+	assertNil(t, err)
+
+	assertTrue(t, nr.Reg == reg.Reference(), "wrong registry")
+	assertTrue(t, nr.Seq == r.Seq+1, "wrong seq")
+	assertTrue(t, string(nr.Descriptor) == "descriptor", "wrong descriptor")
+	assertTrue(t, len(nr.Refs) == 3, "wrong number of refs")
+
+	assertTrue(t, nr.Refs[0].Schema == to.Reference(), "wrong schema")
+	assertTrue(t, nr.Refs[1] == r.Refs[1], "feed changed")
+	assertTrue(t, nr.Refs[2].IsBlank() == true, "blank changed")
+
+	var last *registry.Root
+	last, err = c.LastRoot(pk, nr.Nonce)
+	assertNil(t, err)
+	assertTrue(t, last.Hash == nr.Hash, "not the last Root")
+
+	var pack *Pack
+	pack, err = c.Pack(nr, nil)
+	assertNil(t, err)
+
+	var user UserV2
+	assertNil(t, nr.Refs[0].Value(pack, &user))
+	assertTrue(t, user.Name == "Alice", "wrong name")
+	assertTrue(t, user.Age == 19, "wrong age")
+	assertTrue(t, user.Email == "Alice@example.com", "wrong email")
+
+}
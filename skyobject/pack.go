@@ -1,8 +1,11 @@
 package skyobject
 
 import (
+	"fmt"
+
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -19,9 +22,12 @@ func (p *Pack) Registry() (reg *registry.Registry) {
 	return p.reg
 }
 
-// Get value by hash
+// Get value by hash. It returns error that wraps
+// ErrObjectNotFound if the object doesn't exist
 func (p *Pack) Get(key cipher.SHA256) (val []byte, err error) {
-	val, _, err = p.c.Get(key, 0)
+	if val, _, err = p.c.Get(key, 0); err == data.ErrNotFound {
+		err = fmt.Errorf("%w: %s", ErrObjectNotFound, key.Hex()[:7])
+	}
 	return
 }
 
@@ -71,6 +77,12 @@ func (p *Pack) ClearFlags(flags registry.Flags) {
 	p.flags &^= flags
 }
 
+// HashWorkers returns number of goroutines used to
+// encode and hash values (see Config.HashWorkers)
+func (p *Pack) HashWorkers() int {
+	return p.c.conf.HashWorkers
+}
+
 // Pack returns Pack that obtains values from DB. The
 // Pack implements Add and Set method, but using of the
 // methods creates objects in DB that never be removed.
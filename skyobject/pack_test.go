@@ -0,0 +1,34 @@
+package skyobject
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestPack_Get(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var pack, err = c.Pack(new(registry.Root), testRegistry)
+	assertNil(t, err)
+
+	var key cipher.SHA256
+	key, err = pack.Add([]byte("value"))
+	assertNil(t, err)
+
+	var val []byte
+	val, err = pack.Get(key)
+	assertNil(t, err)
+	assertTrue(t, string(val) == "value", "wrong value")
+
+	_, err = pack.Get(cipher.SumSHA256([]byte("missing")))
+	assertTrue(t, errors.Is(err, ErrObjectNotFound), "not ErrObjectNotFound")
+	assertTrue(t, errors.Is(err, data.ErrNotFound), "not data.ErrNotFound")
+
+}
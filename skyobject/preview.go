@@ -1,6 +1,8 @@
 package skyobject
 
 import (
+	"errors"
+
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/data"
@@ -47,7 +49,9 @@ func (p *Preview) Get(key cipher.SHA256) (val []byte, err error) {
 		return // alrady received
 	}
 
-	if val, err = p.Pack.Get(key); err != nil && err != data.ErrNotFound {
+	if val, err = p.Pack.Get(key); err != nil &&
+		errors.Is(err, data.ErrNotFound) == false {
+
 		return // db failure
 	}
 
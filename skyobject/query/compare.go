@@ -0,0 +1,179 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// can be compared using Lt, Le, Gt and Ge
+func isOrdered(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
+func isInt(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compare signed integer with a number
+func compareInt(i int64, rv reflect.Value) (cmp int, err error) {
+
+	switch k := rv.Kind(); {
+	case isInt(k):
+		cmp = compareInt64(i, rv.Int())
+	case isUint(k):
+		if i < 0 {
+			cmp = -1
+		} else {
+			cmp = compareUint64(uint64(i), rv.Uint())
+		}
+	case isFloat(k):
+		cmp = compareFloat64(float64(i), rv.Float())
+	default:
+		err = ErrInvalidComparison
+	}
+
+	return
+}
+
+// compare unsigned integer with a number
+func compareUint(u uint64, rv reflect.Value) (cmp int, err error) {
+
+	switch k := rv.Kind(); {
+	case isInt(k):
+		if x := rv.Int(); x < 0 {
+			cmp = 1
+		} else {
+			cmp = compareUint64(u, uint64(x))
+		}
+	case isUint(k):
+		cmp = compareUint64(u, rv.Uint())
+	case isFloat(k):
+		cmp = compareFloat64(float64(u), rv.Float())
+	default:
+		err = ErrInvalidComparison
+	}
+
+	return
+}
+
+// compare float with a number
+func compareFloat(f float64, rv reflect.Value) (cmp int, err error) {
+
+	switch k := rv.Kind(); {
+	case isInt(k):
+		cmp = compareFloat64(f, float64(rv.Int()))
+	case isUint(k):
+		cmp = compareFloat64(f, float64(rv.Uint()))
+	case isFloat(k):
+		cmp = compareFloat64(f, rv.Float())
+	default:
+		err = ErrInvalidComparison
+	}
+
+	return
+}
+
+// compare Value of number or string with given value
+func compare(fv registry.Value, rv reflect.Value) (cmp int, err error) {
+
+	switch fv.Kind() {
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		var i int64
+		if i, err = fv.Int(); err != nil {
+			return
+		}
+		return compareInt(i, rv)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		var u uint64
+		if u, err = fv.Uint(); err != nil {
+			return
+		}
+		return compareUint(u, rv)
+
+	case reflect.Float32, reflect.Float64:
+
+		var f float64
+		if f, err = fv.Float(); err != nil {
+			return
+		}
+		return compareFloat(f, rv)
+
+	case reflect.String:
+
+		if rv.Kind() != reflect.String {
+			err = ErrInvalidComparison
+			return
+		}
+
+		var s string
+		if s, err = fv.String(); err != nil {
+			return
+		}
+		return strings.Compare(s, rv.String()), nil
+
+	}
+
+	err = ErrInvalidComparison
+	return
+}
@@ -0,0 +1,353 @@
+// Package query implements simple query engine over
+// objects of a feed. A Query selects objects of given
+// type (by name of registered Schema) walking a Root
+// lazily. For example
+//
+//     posts, err := query.Select("cxo.Post").
+//         Where("Author", query.Eq, pk).
+//         Limit(50).
+//         Run(pack, root)
+//
+// The Run method returns registry.Value of the objects
+// found. The walking stops when the limit reached.
+// Objects are walked depth-first in order of the
+// Root.Refs
+package query
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// common errors
+var (
+	ErrInvalidOp         = errors.New("invalid operator")
+	ErrInvalidComparison = errors.New("can't compare values")
+)
+
+// used to stop walking
+var errStop = errors.New("stop")
+
+// An Op represents comparison operator
+type Op int
+
+// operators
+const (
+	Eq       Op = iota // equal
+	Ne                 // not equal
+	Lt                 // less than
+	Le                 // less than or equal
+	Gt                 // greater than
+	Ge                 // greater than or equal
+	Contains           // string contains substring
+)
+
+// String implements fmt.Stringer interface
+func (o Op) String() string {
+	switch o {
+	case Eq:
+		return "=="
+	case Ne:
+		return "!="
+	case Lt:
+		return "<"
+	case Le:
+		return "<="
+	case Gt:
+		return ">"
+	case Ge:
+		return ">="
+	case Contains:
+		return "contains"
+	}
+	return "Op<" + strconv.Itoa(int(o)) + ">"
+}
+
+// condition of a Query
+type cond struct {
+	path string      // path of a field (see registry.Value.ByPath)
+	op   Op          // operator
+	val  interface{} // value to compare with
+}
+
+// A Query represents query over objects of a feed.
+// Use Select to create a Query
+type Query struct {
+	name  string // name of Schema to select
+	conds []cond // conditions
+	limit int    // limit or zero
+}
+
+// Select creates Query that selects objects
+// of given type (by name of registered Schema)
+func Select(name string) (q *Query) {
+	q = new(Query)
+	q.name = name
+	return
+}
+
+// Where adds condition to the Query. All conditions
+// should be true for an object to be selected. The
+// path is path of a field (see registry.Value.ByPath).
+// The val is a Go value to compare with. Numbers are
+// compared by value (e.g. an int can be compared with
+// an uint32 field). Other types (e.g. cipher.PubKey)
+// can be compared using Eq and Ne only, and the val
+// should have the same Go type as the field
+func (q *Query) Where(path string, op Op, val interface{}) *Query {
+	q.conds = append(q.conds, cond{path, op, val})
+	return q
+}
+
+// Limit number of objects to select. Zero
+// or negative limit means no limit
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+	return q
+}
+
+// Run the Query against given Root using given Pack
+// to get objects. The Root should be full. The Run
+// returns first error. An object met many times (in
+// different branches) is selected once
+func (q *Query) Run(
+	pack registry.Pack, //    : pack to get objects
+	r *registry.Root, //      : the Root to query
+) (
+	vs []registry.Value, //   : selected values
+	err error, //             : an error
+) {
+
+	var w = &walker{
+		q:    q,
+		seen: make(map[cipher.SHA256]struct{}),
+	}
+
+	for _, dr := range r.Refs {
+
+		if dr.IsBlank() == true {
+			continue
+		}
+
+		var v registry.Value
+		if v, err = registry.DynamicValue(pack, dr); err != nil {
+			return
+		}
+
+		if err = w.walk(v); err != nil {
+			break
+		}
+
+	}
+
+	if err == errStop {
+		err = nil
+	}
+
+	return w.vs, err
+}
+
+// walking state
+type walker struct {
+	q    *Query
+	seen map[cipher.SHA256]struct{} // selected
+
+	vs []registry.Value
+}
+
+func (w *walker) walk(v registry.Value) (err error) {
+
+	var s = v.Schema()
+
+	if s.HasReferences() == false && s.Name() != w.q.name {
+		return // nothing to look for
+	}
+
+	switch s.ReferenceType() {
+
+	case registry.ReferenceTypeSingle, registry.ReferenceTypeDynamic:
+
+		if v.IsNil() == true {
+			return
+		}
+
+		var dv registry.Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return w.walk(dv)
+
+	case registry.ReferenceTypeSlice:
+
+		return w.walkElements(v, true)
+
+	}
+
+	if s.Name() == w.q.name {
+		if err = w.selectValue(v); err != nil {
+			return
+		}
+	}
+
+	switch s.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		return w.walkElements(v, false)
+
+	case reflect.Struct:
+
+		for i := range s.Fields() {
+
+			var fv registry.Value
+			if fv, err = v.FieldByIndex(i); err != nil {
+				return
+			}
+
+			if err = w.walk(fv); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// array, slice or Refs
+func (w *walker) walkElements(v registry.Value, isRefs bool) (err error) {
+
+	if isRefs == false && v.Schema().Elem().HasReferences() == false {
+		return // the elements can't be selected
+	}
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	for i := 0; i < ln; i++ {
+
+		var ev registry.Value
+
+		if ev, err = v.Index(i); err == registry.ErrRefsElementIsNil {
+			continue
+		} else if err != nil {
+			return
+		}
+
+		if err = w.walk(ev); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+func (w *walker) selectValue(v registry.Value) (err error) {
+
+	var hash = cipher.SumSHA256(v.Encoded())
+
+	if _, ok := w.seen[hash]; ok == true {
+		return // already selected
+	}
+
+	var ok bool
+	if ok, err = w.q.match(v); err != nil || ok == false {
+		return
+	}
+
+	w.seen[hash] = struct{}{}
+	w.vs = append(w.vs, v)
+
+	if w.q.limit > 0 && len(w.vs) >= w.q.limit {
+		return errStop
+	}
+
+	return
+}
+
+// check conditions
+func (q *Query) match(v registry.Value) (ok bool, err error) {
+
+	for _, c := range q.conds {
+
+		var fv registry.Value
+		if fv, err = v.ByPath(c.path); err != nil {
+			return
+		}
+
+		if ok, err = c.match(fv); err != nil || ok == false {
+			return
+		}
+
+	}
+
+	return true, nil
+}
+
+func (c *cond) match(fv registry.Value) (ok bool, err error) {
+
+	if c.op == Contains {
+
+		var s string
+		if s, err = fv.String(); err != nil {
+			return
+		}
+
+		var sub, isString = c.val.(string)
+		if isString == false {
+			return false, ErrInvalidComparison
+		}
+
+		return strings.Contains(s, sub), nil
+	}
+
+	var cmp int
+
+	if isOrdered(fv.Kind()) == true {
+		if cmp, err = compare(fv, reflect.ValueOf(c.val)); err != nil {
+			return
+		}
+	} else {
+
+		if c.op != Eq && c.op != Ne {
+			return false, ErrInvalidComparison
+		}
+
+		if bytes.Equal(fv.Encoded(), encoder.Serialize(c.val)) == false {
+			cmp = 1 // not equal
+		}
+
+	}
+
+	switch c.op {
+	case Eq:
+		ok = cmp == 0
+	case Ne:
+		ok = cmp != 0
+	case Lt:
+		ok = cmp < 0
+	case Le:
+		ok = cmp <= 0
+	case Gt:
+		ok = cmp > 0
+	case Ge:
+		ok = cmp >= 0
+	default:
+		err = ErrInvalidOp
+	}
+
+	return
+}
@@ -0,0 +1,180 @@
+package query
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+type Post struct {
+	Author cipher.PubKey
+	Title  string
+	Likes  uint32
+}
+
+type Feed struct {
+	Name   string
+	Posts  registry.Refs `skyobject:"schema=q.Post"`
+	Pinned registry.Ref  `skyobject:"schema=q.Post"`
+}
+
+var testRegistry = registry.NewRegistry(func(r *registry.Reg) {
+	r.Register("q.Post", Post{})
+	r.Register("q.Feed", Feed{})
+})
+
+func testRoot(t *testing.T) (pack registry.Pack, r *registry.Root,
+	alice, bob cipher.PubKey) {
+
+	t.Helper()
+
+	var conf = skyobject.NewConfig()
+	conf.InMemoryDB = true
+	conf.DataDir = ""
+
+	var c, err = skyobject.NewContainer(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	var _, sk = cipher.GenerateKeyPair()
+
+	var up *skyobject.Unpack
+	if up, err = c.Unpack(sk, testRegistry); err != nil {
+		t.Fatal(err)
+	}
+
+	alice, _ = cipher.GenerateKeyPair()
+	bob, _ = cipher.GenerateKeyPair()
+
+	var feed = Feed{Name: "the feed"}
+
+	for i := 0; i < 10; i++ {
+
+		var author = alice
+		if i%2 == 1 {
+			author = bob
+		}
+
+		err = feed.Posts.AppendValues(up, Post{
+			Author: author,
+			Title:  fmt.Sprintf("Post #%d", i),
+			Likes:  uint32(i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+	// the same as the first post
+	err = feed.Pinned.SetValue(up, Post{alice, "Post #0", 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dr registry.Dynamic
+	if err = dr.SetValue(up, &feed); err != nil {
+		t.Fatal(err)
+	}
+
+	var sch registry.Schema
+	if sch, err = testRegistry.SchemaByName("q.Feed"); err != nil {
+		t.Fatal(err)
+	}
+	dr.Schema = sch.Reference()
+
+	r = &registry.Root{Refs: []registry.Dynamic{dr}}
+	return up, r, alice, bob
+}
+
+func testTitles(t *testing.T, vs []registry.Value, titles ...string) {
+	t.Helper()
+
+	if len(vs) != len(titles) {
+		t.Fatalf("wrong number of values: want %d, got %d", len(titles),
+			len(vs))
+	}
+
+	for i, v := range vs {
+
+		var tv, err = v.FieldByName("Title")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var title string
+		if title, err = tv.String(); err != nil {
+			t.Fatal(err)
+		}
+
+		if title != titles[i] {
+			t.Errorf("wrong title: want %q, got %q", titles[i], title)
+		}
+
+	}
+
+}
+
+func TestQuery_Run(t *testing.T) {
+
+	var pack, r, alice, bob = testRoot(t)
+
+	var vs, err = Select("q.Post").Run(pack, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTitles(t, vs, "Post #0", "Post #1", "Post #2", "Post #3", "Post #4",
+		"Post #5", "Post #6", "Post #7", "Post #8", "Post #9")
+
+	vs, err = Select("q.Post").Where("Author", Eq, bob).Limit(3).Run(pack, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTitles(t, vs, "Post #1", "Post #3", "Post #5")
+
+	vs, err = Select("q.Post").
+		Where("Author", Eq, alice).
+		Where("Likes", Ge, 4).
+		Run(pack, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTitles(t, vs, "Post #4", "Post #6", "Post #8")
+
+	vs, err = Select("q.Post").Where("Title", Contains, "#7").Run(pack, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTitles(t, vs, "Post #7")
+
+	vs, err = Select("q.Post").Where("Likes", Lt, -1).Run(pack, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTitles(t, vs)
+
+	if vs, err = Select("q.Feed").Run(pack, r); err != nil {
+		t.Fatal(err)
+	} else if len(vs) != 1 {
+		t.Error("wrong number of feeds", len(vs))
+	}
+
+	// errors
+
+	_, err = Select("q.Post").Where("Author", Lt, alice).Run(pack, r)
+	if err != ErrInvalidComparison {
+		t.Error("wrong error", err)
+	}
+
+	_, err = Select("q.Post").Where("Nothing", Eq, 1).Run(pack, r)
+	if err == nil {
+		t.Error("missing error")
+	}
+
+}
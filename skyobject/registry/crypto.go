@@ -0,0 +1,220 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A CryptoType represents type of cipher.PubKey,
+// cipher.SHA256 or cipher.Sig field. Such fields
+// are arrays of bytes, but they have dedicated
+// schemas (see (Schema).CryptoType) to be
+// recognized
+type CryptoType int
+
+// possible crypto types
+const (
+	CryptoTypeNone   CryptoType = iota // not a crypto type
+	CryptoTypePubKey                   // cipher.PubKey
+	CryptoTypeSHA256                   // cipher.SHA256
+	CryptoTypeSig                      // cipher.Sig
+)
+
+// String implements fmt.Stringer interface
+func (c CryptoType) String() string {
+	switch c {
+	case CryptoTypeNone:
+		return "None"
+	case CryptoTypePubKey:
+		return "cipher.PubKey"
+	case CryptoTypeSHA256:
+		return "cipher.SHA256"
+	case CryptoTypeSig:
+		return "cipher.Sig"
+	}
+	return fmt.Sprintf("CryptoType<%d>", c)
+}
+
+// mark of encoded crypto schema (stored as
+// ReferenceType of the encodedSchema), the
+// mark is cryptoSchemaMark + CryptoType
+const cryptoSchemaMark uint32 = 0xf0
+
+// crypto type of given reflect.Type
+func cryptoTypeOf(typ reflect.Type) CryptoType {
+	switch typ {
+	case reflect.TypeOf(cipher.PubKey{}):
+		return CryptoTypePubKey
+	case reflect.TypeOf(cipher.SHA256{}):
+		return CryptoTypeSHA256
+	case reflect.TypeOf(cipher.Sig{}):
+		return CryptoTypeSig
+	}
+	return CryptoTypeNone
+}
+
+// length of array of the crypto type
+func (c CryptoType) length() int {
+	switch c {
+	case CryptoTypePubKey:
+		return len(cipher.PubKey{})
+	case CryptoTypeSHA256:
+		return len(cipher.SHA256{})
+	case CryptoTypeSig:
+		return len(cipher.Sig{})
+	}
+	return 0
+}
+
+// A cryptoSchema is schema of cipher.PubKey, cipher.SHA256
+// or cipher.Sig, that is array of bytes
+type cryptoSchema struct {
+	arraySchema
+	typ CryptoType
+}
+
+func newCryptoSchema(ct CryptoType) (c *cryptoSchema) {
+	c = new(cryptoSchema)
+	c.kind = reflect.Array
+	c.length = ct.length()
+	c.elem = &schema{kind: reflect.Uint8}
+	c.typ = ct
+	return
+}
+
+func (c *cryptoSchema) CryptoType() CryptoType {
+	return c.typ
+}
+
+func (c *cryptoSchema) Reference() SchemaRef {
+	if c.ref == (SchemaRef{}) {
+		c.ref = SchemaRef(cipher.SumSHA256(c.Encode()))
+	}
+	return c.ref
+}
+
+func (c *cryptoSchema) encodedSchema() (x encodedSchema) {
+	x.ReferenceType = cryptoSchemaMark + uint32(c.typ)
+	x.Kind = uint32(reflect.Array)
+	return
+}
+
+func (c *cryptoSchema) Encode() (b []byte) {
+	b = encoder.Serialize(c.encodedSchema())
+	return
+}
+
+func (c *cryptoSchema) String() string {
+	return c.typ.String()
+}
+
+// is given ReferenceType of encodedSchema a crypto mark
+func isCryptoSchemaMark(rt uint32) bool {
+	return rt > cryptoSchemaMark && rt <= cryptoSchemaMark+uint32(CryptoTypeSig)
+}
+
+func decodeCryptoSchema(x *encodedSchema) (s Schema, err error) {
+	if reflect.Kind(x.Kind) != reflect.Array {
+		err = ErrInvalidEncodedSchema
+		return
+	}
+	s = newCryptoSchema(CryptoType(x.ReferenceType - cryptoSchemaMark))
+	return
+}
+
+// VerifySignatures verifies signatures of given encoded
+// value. A signature is a struct that contains one
+// cipher.PubKey field, one cipher.SHA256 field and
+// one cipher.Sig field. The Sig should be signature
+// of the SHA256 signed by secret key of the PubKey.
+// The VerifySignatures walks through all nested
+// structures, arrays and slices of given value, but
+// it doesn't follow references. It returns
+// ErrInvalidSignature if a signature is not valid
+func VerifySignatures(s Schema, p []byte) (err error) {
+	_, err = verifySignatures(s, p)
+	return
+}
+
+func verifySignatures(s Schema, p []byte) (n int, err error) {
+
+	if n, err = s.Size(p); err != nil {
+		return
+	}
+
+	if s.IsReference() == true {
+		return // skip
+	}
+
+	switch s.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		var ln, shift int
+
+		if s.Kind() == reflect.Array {
+			ln = s.Len()
+		} else {
+			if ln, err = getLength(p); err != nil {
+				return
+			}
+			shift = 4
+		}
+
+		var m int
+		for i := 0; i < ln; i++ {
+			if m, err = verifySignatures(s.Elem(), p[shift:]); err != nil {
+				return
+			}
+			shift += m
+		}
+
+	case reflect.Struct:
+
+		var (
+			pks  []cipher.PubKey
+			hs   []cipher.SHA256
+			sigs []cipher.Sig
+
+			shift, m int
+		)
+
+		for _, f := range s.Fields() {
+
+			var fs = f.Schema()
+
+			if m, err = verifySignatures(fs, p[shift:]); err != nil {
+				return
+			}
+
+			var fv = p[shift : shift+m]
+
+			switch fs.CryptoType() {
+			case CryptoTypePubKey:
+				pks = append(pks, cipher.NewPubKey(fv))
+			case CryptoTypeSHA256:
+				var h cipher.SHA256
+				copy(h[:], fv)
+				hs = append(hs, h)
+			case CryptoTypeSig:
+				sigs = append(sigs, cipher.NewSig(fv))
+			}
+
+			shift += m
+		}
+
+		if len(pks) != 1 || len(hs) != 1 || len(sigs) != 1 {
+			return // not a signature
+		}
+
+		if cipher.VerifySignature(pks[0], sigs[0], hs[0]) != nil {
+			err = ErrInvalidSignature
+		}
+
+	}
+
+	return
+}
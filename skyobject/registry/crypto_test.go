@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+type testSigned struct {
+	Pub  cipher.PubKey
+	Hash cipher.SHA256
+	Sig  cipher.Sig
+}
+
+type testDocument struct {
+	Title  string
+	Signed []testSigned
+}
+
+func testCryptoRegistry(t *testing.T) (reg *Registry) {
+
+	reg = NewRegistry(func(r *Reg) {
+		r.Register("test.Signed", testSigned{})
+		r.Register("test.Document", testDocument{})
+	})
+
+	// use decoded to check encoding
+
+	var err error
+	if reg, err = DecodeRegistry(reg.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestSchema_CryptoType(t *testing.T) {
+
+	var (
+		reg    = testCryptoRegistry(t)
+		s, err = reg.SchemaByName("test.Signed")
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, ct := range []CryptoType{
+		CryptoTypePubKey,
+		CryptoTypeSHA256,
+		CryptoTypeSig,
+	} {
+		var fs = s.Fields()[i].Schema()
+		if fs.CryptoType() != ct {
+			t.Errorf("wrong CryptoType %s, want %s", fs.CryptoType(), ct)
+		}
+		if fs.Len() != ct.length() {
+			t.Errorf("wrong length of %s: %d", ct, fs.Len())
+		}
+		if fs.String() != ct.String() {
+			t.Errorf("wrong String of %s: %q", ct, fs.String())
+		}
+	}
+
+}
+
+func TestVerifySignatures(t *testing.T) {
+
+	var (
+		reg    = testCryptoRegistry(t)
+		s, err = reg.SchemaByName("test.Document")
+
+		pk, sk = cipher.GenerateKeyPair()
+		hash   = cipher.SumSHA256([]byte("hello"))
+		doc    = testDocument{
+			Title: "doc",
+			Signed: []testSigned{
+				{pk, hash, cipher.SignHash(hash, sk)},
+			},
+		}
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = VerifySignatures(s, encoder.Serialize(doc)); err != nil {
+		t.Error(err)
+	}
+
+	doc.Signed = append(doc.Signed, testSigned{
+		pk,
+		cipher.SumSHA256([]byte("another")),
+		cipher.SignHash(hash, sk), // wrong
+	})
+
+	err = VerifySignatures(s, encoder.Serialize(doc))
+	if err != ErrInvalidSignature {
+		t.Error("wrong error:", err)
+	}
+
+}
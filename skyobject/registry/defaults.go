@@ -0,0 +1,185 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// TagDefault returns default value of a field from given
+// reflect.StructTag. E.g. it returns "42" and true if tag
+// is `skyobject:"default=42"`. The default value can't
+// contain commas. Only fields of bool, numeric and string
+// kinds can have default values. A default value of a field
+// is part of the schema of the field and it used to decode
+// older (shorter) encoded objects (see ApplyDefaults)
+func TagDefault(tag reflect.StructTag) (def string, ok bool) {
+	for _, part := range strings.Split(tag.Get(Tag), ",") {
+		if strings.HasPrefix(part, "default=") {
+			return strings.TrimPrefix(part, "default="), true
+		}
+	}
+	return
+}
+
+// encode default value of given kind
+func encodeDefault(kind reflect.Kind, def string) (val []byte, err error) {
+
+	var (
+		i int64
+		u uint64
+		f float64
+		b bool
+	)
+
+	switch kind {
+	case reflect.Bool:
+		if b, err = strconv.ParseBool(def); err == nil {
+			val = encoder.Serialize(b)
+		}
+	case reflect.Int8:
+		if i, err = strconv.ParseInt(def, 10, 8); err == nil {
+			val = encoder.Serialize(int8(i))
+		}
+	case reflect.Int16:
+		if i, err = strconv.ParseInt(def, 10, 16); err == nil {
+			val = encoder.Serialize(int16(i))
+		}
+	case reflect.Int32:
+		if i, err = strconv.ParseInt(def, 10, 32); err == nil {
+			val = encoder.Serialize(int32(i))
+		}
+	case reflect.Int64:
+		if i, err = strconv.ParseInt(def, 10, 64); err == nil {
+			val = encoder.Serialize(i)
+		}
+	case reflect.Uint8:
+		if u, err = strconv.ParseUint(def, 10, 8); err == nil {
+			val = encoder.Serialize(uint8(u))
+		}
+	case reflect.Uint16:
+		if u, err = strconv.ParseUint(def, 10, 16); err == nil {
+			val = encoder.Serialize(uint16(u))
+		}
+	case reflect.Uint32:
+		if u, err = strconv.ParseUint(def, 10, 32); err == nil {
+			val = encoder.Serialize(uint32(u))
+		}
+	case reflect.Uint64:
+		if u, err = strconv.ParseUint(def, 10, 64); err == nil {
+			val = encoder.Serialize(u)
+		}
+	case reflect.Float32:
+		if f, err = strconv.ParseFloat(def, 32); err == nil {
+			val = encoder.Serialize(float32(f))
+		}
+	case reflect.Float64:
+		if f, err = strconv.ParseFloat(def, 64); err == nil {
+			val = encoder.Serialize(f)
+		}
+	case reflect.String:
+		val = encoder.Serialize(def)
+	default:
+		err = fmt.Errorf("default value is not allowed for %s", kind)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("invalid default value %q: %v", def, err)
+	}
+
+	return
+}
+
+// default value of a field by tag of the field
+// and schema of the field, nil if not set
+func fieldDefault(tag reflect.StructTag, s Schema) (val []byte, err error) {
+
+	var def, ok = TagDefault(tag)
+
+	if ok == false {
+		return
+	}
+
+	if s.IsReference() == true {
+		err = fmt.Errorf("default value is not allowed for reference")
+		return
+	}
+
+	return encodeDefault(s.Kind(), def)
+}
+
+func mustFieldDefault(tag reflect.StructTag, s Schema) (val []byte) {
+	var err error
+	if val, err = fieldDefault(tag, s); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// has given struct schema fields with default values
+func hasDefaults(s Schema) bool {
+	for _, f := range s.Fields() {
+		if _, ok := f.Default(); ok == true {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyDefaults appends encoded default values of
+// missing fields to given encoded struct. If given
+// encoded struct is complete, then it returned as
+// is. If an encoded object is older than its
+// schema and a field (or fields) is missing, then
+// all missing fields should have default values
+// (see TagDefault). Otherwise, the ApplyDefaults
+// returns ErrMissingDefault. Only trailing fields
+// can be missing. For schemas that are not
+// structures, the ApplyDefaults returns given
+// slice as is
+func ApplyDefaults(s Schema, p []byte) (val []byte, err error) {
+
+	if s.Kind() != reflect.Struct {
+		return p, nil
+	}
+
+	var (
+		fs   = s.Fields()
+		n, m int
+	)
+
+	for i, f := range fs {
+
+		if n == len(p) {
+
+			// end of the encoded struct, but
+			// there are fields, let's append
+			// default values of the fields
+
+			val = append(make([]byte, 0, len(p)), p...)
+
+			for _, mf := range fs[i:] {
+				var def, ok = mf.Default()
+				if ok == false {
+					err = ErrMissingDefault
+					return
+				}
+				val = append(val, def...)
+			}
+
+			return
+		}
+
+		if m, err = f.Schema().Size(p[n:]); err != nil {
+			return
+		}
+
+		n += m
+
+	}
+
+	return p, nil
+}
@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+type testUserV1 struct {
+	Name string
+}
+
+type testUserV2 struct {
+	Name   string
+	Age    uint32 `skyobject:"default=42"`
+	Active bool   `skyobject:"default=true"`
+	Bio    string `skyobject:"default=unknown"`
+}
+
+func TestTagDefault(t *testing.T) {
+
+	for _, tt := range []struct {
+		tag reflect.StructTag
+		def string
+		ok  bool
+	}{
+		{``, "", false},
+		{`skyobject:"schema=test.User"`, "", false},
+		{`skyobject:"default=42"`, "42", true},
+		{`skyobject:"default="`, "", true},
+		{`enc:"-" skyobject:"default=x"`, "x", true},
+	} {
+		if def, ok := TagDefault(tt.tag); def != tt.def || ok != tt.ok {
+			t.Errorf("wrong default of %q: %q, %t", tt.tag, def, ok)
+		}
+	}
+
+}
+
+func TestApplyDefaults(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", testUserV2{})
+	})
+
+	// use decoded registry, to be sure that
+	// default values are encoded with the schema
+
+	var err error
+	if reg, err = DecodeRegistry(reg.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	var s Schema
+	if s, err = reg.SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("shorter", func(t *testing.T) {
+
+		var val []byte
+		val, err = ApplyDefaults(s, encoder.Serialize(testUserV1{"Alice"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var usr testUserV2
+		if err = encoder.DeserializeRaw(val, &usr); err != nil {
+			t.Fatal(err)
+		}
+
+		var want = testUserV2{"Alice", 42, true, "unknown"}
+		if usr != want {
+			t.Errorf("wrong user %v, want %v", usr, want)
+		}
+
+	})
+
+	t.Run("complete", func(t *testing.T) {
+
+		var (
+			usr = testUserV2{"Eva", 21, false, "none"}
+			p   = encoder.Serialize(usr)
+		)
+
+		var val []byte
+		if val, err = ApplyDefaults(s, p); err != nil {
+			t.Fatal(err)
+		}
+
+		if string(val) != string(p) {
+			t.Error("complete object modified")
+		}
+
+	})
+
+	t.Run("missing default", func(t *testing.T) {
+
+		if _, err = ApplyDefaults(s, []byte{}); err != ErrMissingDefault {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+}
+
+func Test_get_defaults(t *testing.T) {
+
+	var pack = testPackReg(NewRegistry(func(r *Reg) {
+		r.Register("test.User", testUserV2{})
+	}))
+
+	var hash, err = pack.Add(encoder.Serialize(testUserV1{"Alice"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var usr testUserV2
+	if err = get(pack, hash, &usr); err != nil {
+		t.Fatal(err)
+	}
+
+	if usr.Age != 42 || usr.Active != true || usr.Bio != "unknown" {
+		t.Error("default values not applied:", usr)
+	}
+
+}
+
+func TestReg_Register_invalidDefault(t *testing.T) {
+
+	type Invalid struct {
+		Age uint8 `skyobject:"default=1024"`
+	}
+
+	defer shouldPanic(t)
+
+	NewRegistry(func(r *Reg) {
+		r.Register("test.Invalid", Invalid{})
+	})
+
+}
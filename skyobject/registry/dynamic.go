@@ -114,6 +114,19 @@ func (d *Dynamic) Walk(
 		return ErrInvalidDynamicReference
 	}
 
+	if _, ok := pack.(Skipper); ok == true && d.IsBlank() == false {
+
+		var sch Schema
+		if sch, err = d.schema(pack); err != nil {
+			return
+		}
+
+		if skipped(pack, sch) == true {
+			return // filtered out
+		}
+
+	}
+
 	var deepper bool
 	if deepper, err = walkFunc(d.Hash, 0); err != nil {
 		if err == ErrStopIteration {
@@ -130,13 +143,8 @@ func (d *Dynamic) Walk(
 		return
 	}
 
-	var reg *Registry
-	if reg = pack.Registry(); reg == nil {
-		return ErrMissingRegistry
-	}
-
 	var sch Schema
-	if sch, err = reg.SchemaByReference(d.Schema); err != nil {
+	if sch, err = d.schema(pack); err != nil {
 		return
 	}
 
@@ -149,6 +157,17 @@ func (d *Dynamic) Walk(
 	return
 }
 
+// Schema of the Dynamic using Registry of given Pack
+func (d *Dynamic) schema(pack Pack) (sch Schema, err error) {
+
+	var reg *Registry
+	if reg = pack.Registry(); reg == nil {
+		return nil, ErrMissingRegistry
+	}
+
+	return reg.SchemaByReference(d.Schema)
+}
+
 // Split used by the node package to fill the Dynamic.
 func (d *Dynamic) Split(s Splitter) {
 
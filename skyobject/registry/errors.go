@@ -29,7 +29,26 @@ var (
 	ErrRefsIterating      = errors.New("Refs is iterating")
 	ErrInvalidDegree      = errors.New("invalid degree")
 
-	ErrNotFound        = errors.New("not found")
-	ErrStopIteration   = errors.New("stop iteration")
-	ErrMissingRegistry = errors.New("missing registry")
+	ErrNotFound         = errors.New("not found")
+	ErrStopIteration    = errors.New("stop iteration")
+	ErrStopRange        = ErrStopIteration // see RangeIndex
+	ErrMissingRegistry  = errors.New("missing registry")
+	ErrMissingSchema    = errors.New("missing schema")
+	ErrMissingTransform = errors.New("missing transform")
+
+	ErrUnresolvedSchema = errors.New("unresolved external schema")
+
+	ErrMissingDefault = errors.New("missing default value of a field")
+
+	ErrDepthLimit  = errors.New("nesting depth limit reached (see Limits)")
+	ErrLengthLimit = errors.New("length limit reached (see Limits)")
+	ErrSizeLimit   = errors.New("size limit reached (see Limits)")
+
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	ErrInvalidValueKind = errors.New("invalid kind of Value")
+	ErrInvalidPath      = errors.New("invalid path")
+	ErrReferenceCycle   = errors.New("reference cycle")
+	ErrInvalidScanType  = errors.New("can't scan Value to given type")
+	ErrNotComparable    = errors.New("values are not comparable")
 )
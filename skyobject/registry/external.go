@@ -0,0 +1,292 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// mark of encoded external schema (stored
+// as ReferenceType of the encodedSchema)
+const externalSchemaMark uint32 = 0xff
+
+// An externalSchema represents schema of another
+// Registry. The schema is (RegistryRef, SchemaRef)
+// pair and the schema should be resolved (see
+// (*Registry).Resolve) to be used. Only registered
+// structures can be external
+type externalSchema struct {
+	name []byte      // name of the schema in the external Registry
+	reg  RegistryRef // the external Registry
+	ref  SchemaRef   // reference of the schema in the Registry
+	s    Schema      // resolved schema or nil
+}
+
+func (e *externalSchema) IsReference() bool {
+	return false
+}
+
+func (e *externalSchema) ReferenceType() ReferenceType {
+	return ReferenceTypeNone // not a reference
+}
+
+// Reference returns reference of the
+// schema in the external Registry
+func (e *externalSchema) Reference() SchemaRef {
+	return e.ref
+}
+
+func (e *externalSchema) HasReferences() bool {
+	if e.s == nil {
+		return true // unknown, thus it can have references
+	}
+	return e.s.HasReferences()
+}
+
+func (e *externalSchema) Kind() reflect.Kind {
+	return reflect.Struct
+}
+
+func (e *externalSchema) CryptoType() CryptoType {
+	return CryptoTypeNone
+}
+
+func (e *externalSchema) Name() string {
+	return string(e.name)
+}
+
+func (e *externalSchema) RawName() []byte {
+	return e.name
+}
+
+// IsRegistered returns false, since the schema
+// registered in another Registry
+func (e *externalSchema) IsRegistered() bool {
+	return false
+}
+
+func (e *externalSchema) Len() int {
+	return 0
+}
+
+func (e *externalSchema) Fields() []Field {
+	if e.s == nil {
+		return nil
+	}
+	return e.s.Fields()
+}
+
+func (e *externalSchema) Elem() Schema {
+	return nil
+}
+
+func (e *externalSchema) Size(p []byte) (n int, err error) {
+	if e.s == nil {
+		err = ErrUnresolvedSchema
+		return
+	}
+	return e.s.Size(p)
+}
+
+func (e *externalSchema) encodedSchema() (x encodedSchema) {
+	x.ReferenceType = externalSchemaMark
+	x.Kind = uint32(reflect.Struct)
+	x.Name = e.name
+	x.Elem = append(append([]byte{}, e.reg[:]...), e.ref[:]...)
+	return
+}
+
+func (e *externalSchema) Encode() (b []byte) {
+	b = encoder.Serialize(e.encodedSchema())
+	return
+}
+
+func (e *externalSchema) String() string {
+	return fmt.Sprintf("%s@%s", e.Name(), e.reg.Short())
+}
+
+func decodeExternalSchema(x *encodedSchema) (s Schema, err error) {
+
+	if len(x.Elem) != len(RegistryRef{})+len(SchemaRef{}) ||
+		reflect.Kind(x.Kind) != reflect.Struct {
+
+		err = ErrInvalidEncodedSchema
+		return
+	}
+
+	var e = new(externalSchema)
+
+	e.name = x.Name
+	copy(e.reg[:], x.Elem)
+	copy(e.ref[:], x.Elem[len(e.reg):])
+
+	s = e
+	return
+}
+
+// find imported schema by name
+func (r *Reg) importedByName(name string) (e *externalSchema) {
+	for _, ir := range r.imp {
+		if s, err := ir.schemaByName(name); err == nil {
+			return newExternalSchema(ir, s)
+		}
+	}
+	return
+}
+
+// find imported schema by type
+func (r *Reg) importedByType(typ reflect.Type) (e *externalSchema) {
+	for _, ir := range r.imp {
+		if name, ok := ir.tn[typ]; ok == true {
+			return r.importedByName(name)
+		}
+	}
+	return
+}
+
+// is given name registered by the Reg
+func (r *Reg) isRegistered(name string) (ok bool) {
+	for _, n := range r.tn {
+		if n == name {
+			return true
+		}
+	}
+	return
+}
+
+func newExternalSchema(reg *Registry, s Schema) (e *externalSchema) {
+	e = new(externalSchema)
+	e.name = s.RawName()
+	e.reg = reg.Reference()
+	e.ref = s.Reference()
+	e.s = s
+	return
+}
+
+// Import schemas of given Registry. Imported schemas
+// can be used by this Registry by name (in tags of
+// references and as types of fields) but they are
+// not copied to this Registry. Instead, this Registry
+// keeps (RegistryRef, SchemaRef) pair for every
+// imported schema used. Types registered by the Reg
+// have priority over imported. If an imported type
+// registered in many imported Registries, then the
+// first imported is used. For example
+//
+//     core := registry.NewRegistry(func(r *registry.Reg) {
+//         r.Register("core.User", User{})
+//     })
+//
+//     app := registry.NewRegistry(func(r *registry.Reg) {
+//         r.Import(core)
+//         r.Register("app.Post", Post{}) // uses core.User
+//     })
+//
+// A Registry decoded from DB should be resolved
+// (see (*Registry).Resolve) to be used. The
+// skyobject.Container does it automatically
+func (r *Reg) Import(reg *Registry) {
+	if reg == nil {
+		panic("import nil Registry")
+	}
+	r.imp = append(r.imp, reg)
+}
+
+// add external schema to the Registry,
+// it's used by the finialize method
+func (r *Registry) addExternal(e *externalSchema) {
+
+	if r.ext == nil {
+		r.ext = make(map[RegistryRef]*Registry)
+	}
+
+	r.exs = append(r.exs, e)
+
+	if _, ok := r.ext[e.reg]; ok == false {
+		r.ext[e.reg] = nil // not resolved yet
+	}
+
+}
+
+// Externals returns references of Registries
+// schemas of which used by this Registry. The
+// result is ordered by hex-representation of the
+// references
+func (r *Registry) Externals() (rrs []RegistryRef) {
+
+	if len(r.ext) == 0 {
+		return
+	}
+
+	rrs = make([]RegistryRef, 0, len(r.ext))
+	for rr := range r.ext {
+		rrs = append(rrs, rr)
+	}
+
+	sort.Slice(rrs, func(i, j int) bool {
+		return rrs[i].String() < rrs[j].String()
+	})
+
+	return
+}
+
+// External returns external Registry by reference.
+// It returns ErrMissingRegistry if this Registry
+// doesn't use the external Registry or if the
+// external Registry is not resolved yet
+func (r *Registry) External(rr RegistryRef) (reg *Registry, err error) {
+	if reg = r.ext[rr]; reg == nil {
+		err = ErrMissingRegistry
+	}
+	return
+}
+
+// IsResolved returns true if all external schemas
+// of this Registry are resolved
+func (r *Registry) IsResolved() bool {
+	for _, e := range r.exs {
+		if e.s == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolve external schemas of the Registry using
+// given function to get external Registries by
+// reference. Only not resolved schemas are
+// resolved. A Registry created by NewRegistry
+// is resolved already. The Resolve is not
+// thread-safe and should be called before
+// the Registry used
+func (r *Registry) Resolve(
+	get func(rr RegistryRef) (reg *Registry, err error), // :
+) (
+	err error, //                                          :
+) {
+
+	for _, e := range r.exs {
+
+		if e.s != nil {
+			continue // already resolved
+		}
+
+		var reg = r.ext[e.reg]
+
+		if reg == nil {
+			if reg, err = get(e.reg); err != nil {
+				return
+			}
+			r.ext[e.reg] = reg
+		}
+
+		if e.s, err = reg.SchemaByReference(e.ref); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
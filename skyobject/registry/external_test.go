@@ -0,0 +1,165 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+type TestPost struct {
+	Title  string
+	Author Ref `skyobject:"schema=test.User"`
+	Editor TestUser
+}
+
+func testExternalRegistries() (core, app *Registry) {
+
+	core = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+	})
+
+	app = NewRegistry(func(r *Reg) {
+		r.Import(core)
+		r.Register("test.Post", TestPost{})
+	})
+
+	return
+}
+
+func TestReg_Import(t *testing.T) {
+
+	var core, app = testExternalRegistries()
+
+	if _, err := app.SchemaByName("test.User"); err == nil {
+		t.Error("imported schema copied")
+	}
+
+	var rrs = app.Externals()
+
+	if len(rrs) != 1 || rrs[0] != core.Reference() {
+		t.Fatal("wrong external registries:", rrs)
+	}
+
+	if er, err := app.External(core.Reference()); err != nil {
+		t.Error(err)
+	} else if er != core {
+		t.Error("wrong external registry")
+	}
+
+	if app.IsResolved() == false {
+		t.Error("not resolved")
+	}
+
+	var s, err = app.SchemaByName("test.Post")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var us Schema
+	if us, err = core.SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	if el := s.Fields()[1].Schema().Elem(); el.Reference() != us.Reference() {
+		t.Error("wrong schema of Ref")
+	}
+
+	if es := s.Fields()[2].Schema(); es.Reference() != us.Reference() {
+		t.Error("wrong schema of field")
+	}
+
+	// SchemaByReference through the external registry
+
+	if _, err = app.SchemaByReference(us.Reference()); err != nil {
+		t.Error(err)
+	}
+
+	// the Dependencies doesn't represent external schemas
+
+	var deps []string
+	if deps, err = app.Dependencies("test.Post"); err != nil {
+		t.Error(err)
+	} else if len(deps) != 0 {
+		t.Error("unexpected dependencies:", deps)
+	}
+
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+
+	var core, app = testExternalRegistries()
+
+	var reg, err = DecodeRegistry(app.Encode())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reg.Reference() != app.Reference() {
+		t.Error("wrong reference of decoded Registry")
+	}
+
+	if reg.IsResolved() == true {
+		t.Error("resolved")
+	}
+
+	if _, err = reg.External(core.Reference()); err != ErrMissingRegistry {
+		t.Error("wrong error:", err)
+	}
+
+	var (
+		s   Schema
+		val = encoder.Serialize(TestPost{
+			Title:  "title",
+			Editor: TestUser{Name: "Alice", Age: 21},
+		})
+	)
+
+	if s, err = reg.SchemaByName("test.Post"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.Size(val); err != ErrUnresolvedSchema {
+		t.Error("wrong error:", err)
+	}
+
+	// resolve
+
+	err = reg.Resolve(func(rr RegistryRef) (*Registry, error) {
+		if rr == core.Reference() {
+			return core, nil
+		}
+		return nil, ErrMissingRegistry
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reg.IsResolved() == false {
+		t.Error("not resolved")
+	}
+
+	var n int
+	if n, err = s.Size(val); err != nil {
+		t.Error(err)
+	} else if n != len(val) {
+		t.Error("wrong size", n, len(val))
+	}
+
+	// unresolvable
+
+	if reg, err = DecodeRegistry(app.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	err = reg.Resolve(func(RegistryRef) (*Registry, error) {
+		return nil, ErrMissingRegistry
+	})
+
+	if err != ErrMissingRegistry {
+		t.Error("wrong error:", err)
+	}
+
+}
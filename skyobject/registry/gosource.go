@@ -0,0 +1,269 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoSource returns Go source code with definitions of
+// all registered types of the Registry and a Registry
+// variable (called Reg) that registers them. The source
+// can be used to get Go types of a decoded Registry.
+// The result is best-effort: names of types are last
+// elements of schema names (e.g. "cxo.Post" is Post) and
+// types of imported Registries (see Externals) are not
+// defined. If the result can't be formatted, then it
+// returned as is. For example
+//
+//     src := root.Registry().GoSource("types")
+//
+func (r *Registry) GoSource(pkgName string) string {
+
+	var (
+		ss  = r.Schemas()
+		g   = newGoPrinter(ss)
+		buf bytes.Buffer
+	)
+
+	// generate types first to collect used packages
+	var types bytes.Buffer
+	for _, s := range ss {
+		fmt.Fprintf(&types, "\n// %s is %q\n", g.typeName(s.Name()),
+			s.Name())
+		types.WriteString(g.decl(s))
+		types.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+
+	buf.WriteString("import (\n")
+	if g.cipher == true {
+		buf.WriteString("\t\"github.com/skycoin/skycoin/src/cipher\"\n\n")
+	}
+	buf.WriteString("\t\"github.com/skycoin/cxo/skyobject/registry\"\n")
+	buf.WriteString(")\n\n")
+
+	fmt.Fprintf(&buf, "// Reg is registry %s\n", r.Reference().Short())
+	for _, rr := range r.Externals() {
+		fmt.Fprintf(&buf, "// (types of imported registry %s are not included)\n",
+			rr.Short())
+	}
+	buf.WriteString("var Reg = registry.NewRegistry(func(r *registry.Reg) {\n")
+	for _, s := range ss {
+		fmt.Fprintf(&buf, "\tr.Register(%q, %s{})\n", s.Name(),
+			g.typeName(s.Name()))
+	}
+	buf.WriteString("})\n")
+
+	buf.Write(types.Bytes())
+
+	var src, err = format.Source(buf.Bytes())
+	if err != nil {
+		return buf.String() // best-effort
+	}
+
+	return string(src)
+}
+
+// Go definition of given Schema, a type
+// declaration for a named struct or type
+// expression for other
+func goString(s Schema) string {
+	var g = newGoPrinter(nil)
+	if s.Kind() == reflect.Struct && s.Name() != "" && isExternal(s) == false {
+		return g.decl(s)
+	}
+	return g.expr(s)
+}
+
+func isExternal(s Schema) (ok bool) {
+	_, ok = s.(*externalSchema)
+	return
+}
+
+// Go printer of schemas
+type goPrinter struct {
+	names map[string]string // schema name -> Go type name
+
+	cipher bool // uses the cipher package
+}
+
+// create goPrinter, names of given schemas
+// are checked for collisions
+func newGoPrinter(ss []Schema) (g *goPrinter) {
+
+	g = new(goPrinter)
+	g.names = make(map[string]string)
+
+	var used = make(map[string]int)
+	for _, s := range ss {
+		used[goTypeName(s.Name())]++
+	}
+
+	for _, s := range ss {
+		if name := goTypeName(s.Name()); used[name] == 1 {
+			g.names[s.Name()] = name
+		} else {
+			g.names[s.Name()] = goFullTypeName(s.Name())
+		}
+	}
+
+	return
+}
+
+func (g *goPrinter) typeName(name string) string {
+	if tn, ok := g.names[name]; ok == true {
+		return tn
+	}
+	return goTypeName(name)
+}
+
+// Go identifier of given name, the name is split
+// by non-identifier characters, and parts are
+// capitalized
+func goIdentParts(name string) (parts []string) {
+	var fs = strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsLetter(r) == false && unicode.IsDigit(r) == false &&
+			r != '_'
+	})
+	for _, f := range fs {
+		parts = append(parts, strings.ToUpper(f[:1])+f[1:])
+	}
+	return
+}
+
+// last element of the name
+func goTypeName(name string) string {
+	var parts = goIdentParts(name)
+	if len(parts) == 0 {
+		return "T"
+	}
+	return goIdent(parts[len(parts)-1])
+}
+
+// all elements of the name
+func goFullTypeName(name string) string {
+	return goIdent(strings.Join(goIdentParts(name), ""))
+}
+
+func goIdent(name string) string {
+	if name == "" || unicode.IsLetter(rune(name[0])) == false {
+		return "T" + name
+	}
+	return name
+}
+
+// type declaration
+func (g *goPrinter) decl(s Schema) string {
+	return "type " + g.typeName(s.Name()) + " " + g.body(s)
+}
+
+// struct body
+func (g *goPrinter) body(s Schema) string {
+
+	var fs = s.Fields()
+	if len(fs) == 0 {
+		return "struct{}"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("struct {\n")
+
+	for _, f := range fs {
+		fmt.Fprintf(&buf, "\t%s %s", f.Name(), g.expr(f.Schema()))
+		if tag := string(f.Tag()); tag != "" {
+			if strings.Contains(tag, "`") == true {
+				fmt.Fprintf(&buf, " %s", strconv.Quote(tag))
+			} else {
+				fmt.Fprintf(&buf, " `%s`", tag)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// type expression
+func (g *goPrinter) expr(s Schema) string {
+
+	if s == nil {
+		return "interface{}"
+	}
+
+	switch s.CryptoType() {
+	case CryptoTypePubKey:
+		g.cipher = true
+		return "cipher.PubKey"
+	case CryptoTypeSHA256:
+		g.cipher = true
+		return "cipher.SHA256"
+	case CryptoTypeSig:
+		g.cipher = true
+		return "cipher.Sig"
+	}
+
+	switch s.ReferenceType() {
+	case ReferenceTypeSingle:
+		return "registry.Ref"
+	case ReferenceTypeSlice:
+		return "registry.Refs"
+	case ReferenceTypeDynamic:
+		return "registry.Dynamic"
+	}
+
+	switch s.Kind() {
+	case reflect.Struct:
+		if s.Name() != "" {
+			return g.typeName(s.Name())
+		}
+		return g.body(s)
+	case reflect.Slice:
+		return "[]" + g.expr(s.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", s.Len(), g.expr(s.Elem()))
+	}
+
+	return s.Kind().String()
+}
+
+// GoString implements fmt.GoStringer interface
+func (s *schema) GoString() string {
+	return goString(s)
+}
+
+// GoString implements fmt.GoStringer interface
+func (r *referenceSchema) GoString() string {
+	return goString(r)
+}
+
+// GoString implements fmt.GoStringer interface
+func (s *sliceSchema) GoString() string {
+	return goString(s)
+}
+
+// GoString implements fmt.GoStringer interface
+func (a *arraySchema) GoString() string {
+	return goString(a)
+}
+
+// GoString implements fmt.GoStringer interface
+func (s *structSchema) GoString() string {
+	return goString(s)
+}
+
+// GoString implements fmt.GoStringer interface
+func (c *cryptoSchema) GoString() string {
+	return goString(c)
+}
+
+// GoString implements fmt.GoStringer interface
+func (e *externalSchema) GoString() string {
+	return goString(e)
+}
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_GoSource(t *testing.T) {
+	// GoSource(pkgName string) string
+
+	var (
+		reg = testRegistry()
+		src = reg.GoSource("types")
+	)
+
+	var fs = token.NewFileSet()
+	if _, err := parser.ParseFile(fs, "types.go", src, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"package types",
+		`r.Register("test.Group", Group{})`,
+		"type Group struct {",
+		"registry.Refs `skyobject:\"schema=test.User\"`",
+		"registry.Ref  `skyobject:\"schema=test.User\"`",
+		"registry.Dynamic",
+		"[5]int32",
+		"type Empty struct{}",
+	} {
+		if strings.Contains(src, want) == false {
+			t.Errorf("missing %q", want)
+		}
+	}
+
+}
+
+func TestSchema_GoString(t *testing.T) {
+	// GoString() string
+
+	var reg = testRegistry()
+
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{"test.User", "type User struct {\n\tName string\n\tAge uint32\n}"},
+		{"test.Man", "type Man struct {\n\tName string\n\tGitHub string\n}"},
+	} {
+		var s, err = reg.SchemaByName(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gs := s.GoString(); gs != tt.want {
+			t.Errorf("wrong GoString of %s: want %q, got %q", tt.name,
+				tt.want, gs)
+		}
+	}
+
+	if gs := newCryptoSchema(CryptoTypePubKey).GoString(); gs != "cipher.PubKey" {
+		t.Error("wrong GoString of cipher.PubKey:", gs)
+	}
+
+	var s = &sliceSchema{schema{kind: reflect.Slice}, &schema{kind: reflect.Uint16}}
+	if gs := s.GoString(); gs != "[]uint16" {
+		t.Error("wrong GoString of []uint16:", gs)
+	}
+
+}
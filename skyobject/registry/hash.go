@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// an encoded value with its hash
+type hashedValue struct {
+	val  []byte        // encoded
+	hash cipher.SHA256 // hash of the val, blank for nil
+}
+
+// encode and hash given values using given number
+// of goroutines; nil values are blank
+func hashValues(
+	workers int, //           : number of goroutines
+	values []interface{}, //  : values to encode and hash
+) (
+	hvs []hashedValue, //     : encoded values with hashes
+) {
+
+	hvs = make([]hashedValue, len(values))
+
+	parallel(workers, len(values), func(i int) {
+		if isNil(values[i]) == true {
+			return // blank
+		}
+		hvs[i].val = encoder.Serialize(values[i])
+		hvs[i].hash = cipher.SumSHA256(hvs[i].val)
+	})
+
+	return
+}
+
+// call given function for every index from 0 to n
+// (excluding the n) using given number of goroutines
+func parallel(
+	workers int, //    : number of goroutines
+	n int, //          : number of indices
+	fn func(i int), // : function to call
+) {
+
+	if workers > n {
+		workers = n
+	}
+
+	if workers < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				fn(i)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"errors"
+	"reflect"
+)
+
+// default limits
+const (
+	MaxDepth  int = 64               // default max nesting depth
+	MaxLength int = 16 * 1024 * 1024 // default max length (16M)
+	MaxSize   int = 64 * 1024 * 1024 // default max size (64M)
+)
+
+// A Limits represents hard caps used to get size of
+// encoded values (see (Schema).Size). Encoded values
+// and Registries can be received from untrusted peers,
+// and the caps make traversing of such values safe
+type Limits struct {
+	// MaxDepth is max nesting depth of a value. For
+	// example, a struct that contains slice of structs
+	// has depth 3. Zero means no limit
+	MaxDepth int
+
+	// MaxLength is max length of an encoded slice or
+	// string. An array with length greater then the
+	// MaxLength is rejected too. Zero means no limit
+	MaxLength int
+
+	// MaxSize is max size of an encoded value.
+	// Zero means no limit
+	MaxSize int
+}
+
+// NewLimits returns Limits filled down with default values
+func NewLimits() (l Limits) {
+	l.MaxDepth = MaxDepth
+	l.MaxLength = MaxLength
+	l.MaxSize = MaxSize
+	return
+}
+
+// Validate the Limits
+func (l *Limits) Validate() (err error) {
+	if l.MaxDepth < 0 {
+		return errors.New("negative MaxDepth")
+	}
+	if l.MaxLength < 0 {
+		return errors.New("negative MaxLength")
+	}
+	if l.MaxSize < 0 {
+		return errors.New("negative MaxSize")
+	}
+	return
+}
+
+// DefaultLimits used by the (Schema).Size method. Change
+// the DefaultLimits before using this package, since it's
+// not safe to change them concurrently
+var DefaultLimits = NewLimits()
+
+// Size returns size of given encoded value using given
+// Schema and the Limits. It returns ErrDepthLimit,
+// ErrLengthLimit or ErrSizeLimit if a limit reached
+func (l *Limits) Size(s Schema, p []byte) (n int, err error) {
+	return l.size(s, p, 1)
+}
+
+func (l *Limits) checkLength(ln int) (err error) {
+	if l.MaxLength > 0 && ln > l.MaxLength {
+		err = ErrLengthLimit
+	}
+	return
+}
+
+// length of length prefixed values (like
+// slice or string) limited by the MaxLength
+func (l *Limits) length(p []byte) (ln int, err error) {
+	if ln, err = getLength(p); err != nil {
+		return
+	}
+	err = l.checkLength(ln)
+	return
+}
+
+func (l *Limits) size(s Schema, p []byte, depth int) (n int, err error) {
+
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		err = ErrDepthLimit
+		return
+	}
+
+	if s.IsReference() == true {
+		return s.Size(p) // fixed size
+	}
+
+	if e, ok := s.(*externalSchema); ok == true {
+		if e.s == nil {
+			err = ErrUnresolvedSchema
+			return
+		}
+		s = e.s
+	}
+
+	switch s.Kind() {
+
+	case reflect.String:
+
+		if n, err = l.length(p); err != nil {
+			return
+		}
+		n += 4 // encoded length (uint32)
+
+	case reflect.Slice:
+
+		var ln int
+		if ln, err = l.length(p); err != nil {
+			return
+		}
+		n, err = l.arraySliceSize(s.Elem(), ln, 4, p, depth)
+
+	case reflect.Array:
+
+		if err = l.checkLength(s.Len()); err != nil {
+			return
+		}
+		n, err = l.arraySliceSize(s.Elem(), s.Len(), 0, p, depth)
+
+	case reflect.Struct:
+
+		var m int
+		for _, sf := range s.Fields() {
+			if n > len(p) {
+				err = ErrInvalidSchemaOrData
+				return
+			}
+			if m, err = l.size(sf.Schema(), p[n:], depth+1); err != nil {
+				return
+			}
+			n += m
+		}
+
+	default:
+
+		if n = fixedSize(s.Kind()); n < 0 {
+			err = ErrInvalidSchemaOrData
+			return
+		}
+
+	}
+
+	if err != nil {
+		return
+	}
+
+	if n > len(p) {
+		err = ErrInvalidSchemaOrData
+	} else if l.MaxSize > 0 && n > l.MaxSize {
+		err = ErrSizeLimit
+	}
+
+	return
+}
+
+// arraySliceSize iterates over encoded elements of array or slice
+// to get size used by them; ln is length of array or slice, shift is
+// shift in p slice from which data begins, el is schema of element
+func (l *Limits) arraySliceSize(
+	el Schema, //  : schema of element
+	ln int, //     : length
+	shift int, //  : shift of first element
+	p []byte, //   : encoded array or slice
+	depth int, //  : depth of the array or slice
+) (
+	n int, //      : size
+	err error, //  : error
+) {
+
+	if el == nil {
+		err = ErrInvalidSchema
+		return
+	}
+
+	n += shift
+
+	if s := fixedSize(el.Kind()); s > 0 {
+		n += ln * s
+		return
+	}
+
+	var m int
+	for i := 0; i < ln; i++ {
+		if n > len(p) {
+			err = ErrInvalidSchemaOrData
+			return
+		}
+		if m, err = l.size(el, p[n:], depth+1); err != nil {
+			return
+		}
+		n += m
+	}
+
+	return
+}
@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func TestLimits_Validate(t *testing.T) {
+
+	var l = NewLimits()
+
+	if err := l.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	l.MaxLength = -1
+
+	if err := l.Validate(); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestLimits_Size(t *testing.T) {
+
+	type Inner struct {
+		Names []string
+	}
+
+	type Outer struct {
+		Inners []Inner
+		Data   []byte
+	}
+
+	var (
+		reg = NewRegistry(func(r *Reg) {
+			r.Register("test.Inner", Inner{})
+			r.Register("test.Outer", Outer{})
+		})
+		s, err = reg.SchemaByName("test.Outer")
+		val    = encoder.Serialize(Outer{
+			Inners: []Inner{{[]string{"a", "bc"}}},
+			Data:   []byte("hello"),
+		})
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		l    Limits
+		err  error
+	}{
+		{"no limits", Limits{}, nil},
+		{"default", NewLimits(), nil},
+		{"depth", Limits{MaxDepth: 4}, ErrDepthLimit},
+		{"length", Limits{MaxLength: 4}, ErrLengthLimit},
+		{"size", Limits{MaxSize: 8}, ErrSizeLimit},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var n, err = tt.l.Size(s, val)
+			if err != tt.err {
+				t.Fatalf("wrong error: %v, want %v", err, tt.err)
+			}
+			if err == nil && n != len(val) {
+				t.Errorf("wrong size %d, want %d", n, len(val))
+			}
+		})
+	}
+
+}
+
+func TestLimits_Size_hugeLength(t *testing.T) {
+
+	type Empty struct{}
+
+	type Huge struct {
+		Empties []Empty
+	}
+
+	var (
+		reg = NewRegistry(func(r *Reg) {
+			r.Register("test.Empty", Empty{})
+			r.Register("test.Huge", Huge{})
+		})
+		s, err = reg.SchemaByName("test.Huge")
+		l      = NewLimits()
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// attacker-supplied length of slice of empty structures,
+	// that is 4G iterations without the limit
+	var val = encoder.Serialize(uint32(0xffffffff))
+
+	if _, err = l.Size(s, val); err != ErrLengthLimit {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestDecodeSchema_depthLimit(t *testing.T) {
+
+	// [][]...[]int32
+	var nested = func(depth int) (b []byte) {
+		b = encoder.Serialize(encodedSchema{Kind: uint32(reflect.Int32)})
+		for i := 1; i < depth; i++ {
+			b = encoder.Serialize(encodedSchema{
+				Kind: uint32(reflect.Slice),
+				Elem: b,
+			})
+		}
+		return
+	}
+
+	if _, err := DecodeSchema(nested(DefaultLimits.MaxDepth)); err != nil {
+		t.Error(err)
+	}
+
+	var _, err = DecodeSchema(nested(DefaultLimits.MaxDepth + 1))
+
+	if err != ErrDepthLimit {
+		t.Error("wrong error:", err)
+	}
+
+}
@@ -114,6 +114,23 @@ type Pack interface {
 	ClearFlags(Flags) // clear given Flags from internal (AND NOT)
 }
 
+// A HashWorkersPack is optional interface of a Pack.
+// If a Pack implements it, then a Refs encodes and
+// hashes values and its nodes using HashWorkers
+// goroutines, appending or slicing. A value less
+// than two means single goroutine
+type HashWorkersPack interface {
+	HashWorkers() int
+}
+
+// number of goroutines to hash with
+func hashWorkers(pack Pack) int {
+	if hwp, ok := pack.(HashWorkersPack); ok == true {
+		return hwp.HashWorkers()
+	}
+	return 1
+}
+
 // get by hash from the Pack and deocde to given pointer (obj)
 func get(
 	pack Pack, //          : pack to get from
@@ -129,6 +146,35 @@ func get(
 		return
 	}
 
+	// the val can be older (shorter) then its schema,
+	// and default values should be applied in this case
+
+	if s := schemaOfObject(pack, obj); s != nil && hasDefaults(s) {
+		if val, err = ApplyDefaults(s, val); err != nil {
+			return
+		}
+	}
+
 	err = encoder.DeserializeRaw(val, obj)
 	return
 }
+
+// schema of given object or nil; the object
+// should be of a registered type
+func schemaOfObject(pack Pack, obj interface{}) (s Schema) {
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return
+	}
+
+	var name, err = reg.Types().SchemaName(obj)
+
+	if err != nil {
+		return
+	}
+
+	s, _ = reg.SchemaByName(name)
+	return
+}
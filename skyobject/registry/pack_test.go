@@ -11,10 +11,11 @@ import (
 // dummy pack
 
 type dummyPack struct {
-	reg    *Registry
-	flags  Flags
-	degree Degree
-	vals   map[cipher.SHA256][]byte
+	reg     *Registry
+	flags   Flags
+	degree  Degree
+	workers int
+	vals    map[cipher.SHA256][]byte
 }
 
 func (d *dummyPack) Registry() *Registry {
@@ -64,6 +65,10 @@ func (d *dummyPack) ClearFlags(flags Flags) {
 	d.flags &^= flags
 }
 
+func (d *dummyPack) HashWorkers() int {
+	return d.workers
+}
+
 func (d *dummyPack) Has(key cipher.SHA256) (ok bool) {
 	_, ok = d.vals[key]
 	return
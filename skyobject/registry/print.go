@@ -0,0 +1,164 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// String returns human readable representation of the
+// Registry: all registered types as Go structures
+// ordered by name. Imported Registries listed first
+func (r *Registry) String() string {
+
+	var (
+		buf bytes.Buffer
+		tw  = tabwriter.NewWriter(&buf, 0, 8, 1, ' ', 0)
+	)
+
+	fmt.Fprintf(&buf, "registry %s\n", r.ref.Short())
+
+	for _, rr := range r.Externals() {
+		fmt.Fprintf(&buf, "import %s\n", rr.Short())
+	}
+
+	for _, s := range r.Schemas() {
+
+		fmt.Fprintf(&buf, "\ntype %s struct { // %s\n", s.Name(),
+			s.Reference().Short())
+
+		for _, f := range s.Fields() {
+			fmt.Fprintf(tw, "    %s\t%s", f.Name(), f.Schema().String())
+			if tag := f.Tag(); tag != "" {
+				fmt.Fprintf(tw, "\t`%s`", tag)
+			}
+			fmt.Fprintln(tw)
+		}
+
+		tw.Flush()
+		buf.WriteString("}\n")
+	}
+
+	return buf.String()
+}
+
+// call given function for every registered (or external)
+// schema the s uses; the ref is true if the schema used
+// by a reference, and it's false if it's used by value
+func schemaEdges(s Schema, ref bool, edgeFunc func(s Schema, ref bool)) {
+
+	if s == nil {
+		return
+	}
+
+	if e, ok := s.(*externalSchema); ok == true {
+		edgeFunc(e, ref)
+		return
+	}
+
+	if s.IsReference() == true {
+		if s.ReferenceType() != ReferenceTypeDynamic {
+			schemaEdges(s.Elem(), true, edgeFunc)
+		}
+		return
+	}
+
+	if s.IsRegistered() == true {
+		edgeFunc(s, ref)
+		return
+	}
+
+	switch s.Kind() {
+	case reflect.Struct:
+		for _, f := range s.Fields() {
+			schemaEdges(f.Schema(), ref, edgeFunc)
+		}
+	case reflect.Array, reflect.Slice:
+		schemaEdges(s.Elem(), ref, edgeFunc)
+	}
+
+}
+
+// escape string for label of DOT record
+func dotEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		`{`, `\{`,
+		`}`, `\}`,
+		`|`, `\|`,
+		`<`, `\<`,
+		`>`, `\>`,
+	).Replace(s)
+}
+
+// ExportDOT returns Graphviz (DOT) graph of the Registry.
+// Every registered type is a node (record with fields).
+// Solid edges are references (Ref, Refs) and dashed
+// edges are types used by value. Schemas of external
+// Registries are dashed nodes. Dynamic references have
+// no edges, since schema of a Dynamic is not known
+//
+//     dot -Tsvg reg.dot > reg.svg
+//
+func (r *Registry) ExportDOT() []byte {
+
+	var (
+		buf bytes.Buffer
+		ext = make(map[string]struct{}) // external nodes
+	)
+
+	fmt.Fprintf(&buf, "digraph \"registry %s\" {\n", r.ref.Short())
+	buf.WriteString("\tnode [shape=record];\n")
+
+	for _, s := range r.Schemas() {
+
+		var fields []string
+		for _, f := range s.Fields() {
+			fields = append(fields, dotEscape(
+				f.Name()+": "+f.Schema().String(),
+			)+`\l`)
+		}
+
+		fmt.Fprintf(&buf, "\t%q [label=\"{%s|%s}\"];\n",
+			s.Name(),
+			dotEscape(s.Name()),
+			strings.Join(fields, ""))
+
+	}
+
+	for _, s := range r.Schemas() {
+
+		for _, f := range s.Fields() {
+
+			schemaEdges(f.Schema(), false, func(es Schema, ref bool) {
+
+				var to = es.Name()
+
+				if e, ok := es.(*externalSchema); ok == true {
+					to = e.String()
+					if _, ok = ext[to]; ok == false {
+						ext[to] = struct{}{}
+						fmt.Fprintf(&buf, "\t%q [style=dashed];\n", to)
+					}
+				}
+
+				var style string
+				if ref == false {
+					style = ", style=dashed"
+				}
+
+				fmt.Fprintf(&buf, "\t%q -> %q [label=%q%s];\n",
+					s.Name(), to, f.Name(), style)
+
+			})
+
+		}
+
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
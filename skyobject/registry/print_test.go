@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_String(t *testing.T) {
+
+	var (
+		reg = testRegistry()
+		str = reg.String()
+	)
+
+	for _, want := range []string{
+		"registry " + reg.Reference().Short(),
+		"type test.User struct {",
+		"type test.Group struct {",
+		"Name",
+	} {
+		if strings.Contains(str, want) == false {
+			t.Errorf("missing %q in:\n%s", want, str)
+		}
+	}
+
+}
+
+func TestRegistry_ExportDOT(t *testing.T) {
+
+	var (
+		reg = testRegistry()
+		dot = string(reg.ExportDOT())
+	)
+
+	if strings.HasPrefix(dot, "digraph ") == false ||
+		strings.HasSuffix(dot, "}\n") == false {
+
+		t.Errorf("malformed graph:\n%s", dot)
+	}
+
+	for _, want := range []string{
+		`"test.User" [label="{test.User|`,
+		`"test.Group" -> "test.User" [label="Curator"];`,
+		`"test.Group" -> "test.User" [label="Members"];`,
+	} {
+		if strings.Contains(dot, want) == false {
+			t.Errorf("missing %q in:\n%s", want, dot)
+		}
+	}
+
+}
@@ -102,6 +102,10 @@ func (r *Ref) Walk(
 	err error,
 ) {
 
+	if skipped(pack, sch) == true {
+		return // filtered out
+	}
+
 	var deepper bool
 	if deepper, err = walkFunc(r.Hash, 0); err != nil || deepper == false {
 		return
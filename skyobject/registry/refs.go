@@ -660,6 +660,64 @@ func (r *Refs) SetValueByIndex(
 	return r.SetHashByIndex(pack, i, hash)
 }
 
+// ReplaceByHash replaces hash of all elements with given old hash
+// with given new hash. Only hashes of branches of the changed
+// elements are updated. The method returns ErrNotFound if the Refs
+// doesn't contain elements with the old hash. Use SetHashByIndex
+// to change one element by index
+//
+// The big O of the call is O(m * depth), where m is number of elements with
+// given hash, if HashTableIndex flag has been set. If the flag is not used
+// by the Refs, then the big O is O(n), where n is real length of the Refs
+func (r *Refs) ReplaceByHash(
+	pack Pack, //          : pack to load and save
+	old cipher.SHA256, //  : hash to replace
+	hash cipher.SHA256, // : new hash
+) (
+	err error, //          : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if r.flags&HashTableIndex != 0 {
+
+		var els, ok = r.refsIndex[old]
+
+		if ok == false {
+			return ErrNotFound
+		}
+
+		// the setElementHash changes the index
+		els = append([]*refsElement{}, els...)
+
+		for _, el := range els {
+			if err = r.setElementHash(pack, el, hash); err != nil {
+				return
+			}
+		}
+
+		return
+	}
+
+	var replaced bool // at least one
+
+	err = r.Ascend(pack, func(i int, elHash cipher.SHA256) (err error) {
+		if elHash == old {
+			replaced = true
+			return r.SetHashByIndex(pack, i, hash) // continue
+		}
+		return // continue
+	})
+
+	if err == nil && replaced == false {
+		err = ErrNotFound
+	}
+
+	return
+}
+
 //
 // delete
 //
@@ -1175,7 +1233,7 @@ func (r *Refs) walkUpdatingSlice(
 	err error, //    : error if any
 ) {
 
-	err = r.walkUpdatingSliceNode(pack, r.refsNode, r.depth)
+	err = r.walkUpdatingSliceNodes(pack, hashWorkers(pack))
 
 	if err != nil {
 		return
@@ -1479,7 +1537,10 @@ func (r *Refs) Append(
 
 // AppendValues to this Refs. The values msut
 // be schema of the Refs. There are no internal
-// checks for the schema. Use nil for blank hash
+// checks for the schema. Use nil for blank hash.
+// The values encoded and hashed using
+// HashWorkers goroutines if the Pack is
+// HashWorkersPack
 func (r *Refs) AppendValues(
 	pack Pack, //             : pack to load and save
 	values ...interface{}, // : values to append
@@ -1492,25 +1553,19 @@ func (r *Refs) AppendValues(
 	}
 
 	var (
+		hvs    = hashValues(hashWorkers(pack), values) //
 		hashes = make([]cipher.SHA256, 0, len(values)) //
-		hash   cipher.SHA256                           // current
 	)
 
-	for _, val := range values {
+	for _, hv := range hvs {
 
-		if isNil(val) == true {
-
-			hash = cipher.SHA256{}
-
-		} else {
-
-			if hash, err = pack.Add(encoder.Serialize(val)); err != nil {
+		if hv.hash != (cipher.SHA256{}) {
+			if err = pack.Set(hv.hash, hv.val); err != nil {
 				return
 			}
-
 		}
 
-		hashes = append(hashes, hash)
+		hashes = append(hashes, hv.hash)
 
 	}
 
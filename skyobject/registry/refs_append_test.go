@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
 // clear Refs, and set provided degree to the Refs
@@ -37,9 +38,95 @@ func TestRefs_Append(t *testing.T) {
 func TestRefs_AppendValues(t *testing.T) {
 	// AppendValues(pack Pack, values ...interface{}) (err error)
 
-	// TODO (kostyarin): the AppendValues method based on the AppendHashes
-	//                   method and this test case is not important a lot,
-	//                   thus I mark it as low priority
+	var (
+		pack = getTestPack()
+		refs Refs
+
+		values = []interface{}{
+			&TestUser{Name: "Alice"},
+			nil,
+			TestUser{Name: "Eva"},
+			(*TestUser)(nil),
+			&TestUser{Name: "Ammy"},
+		}
+
+		hashes = []cipher.SHA256{
+			cipher.SumSHA256(encoder.Serialize(TestUser{Name: "Alice"})),
+			{},
+			cipher.SumSHA256(encoder.Serialize(TestUser{Name: "Eva"})),
+			{},
+			cipher.SumSHA256(encoder.Serialize(TestUser{Name: "Ammy"})),
+		}
+
+		err error
+	)
+
+	for _, workers := range []int{0, 1, 2, 10} {
+
+		t.Run(fmt.Sprintf("workers %d", workers), func(t *testing.T) {
+
+			pack.workers = workers
+			clearRefs(t, &refs, pack, pack.Degree())
+
+			if err = refs.AppendValues(pack, values...); err != nil {
+				t.Fatal(err)
+			}
+
+			testRefsAppendHashesCheck(t, &refs, pack, 0, hashes)
+
+			for _, hash := range hashes {
+				if hash == (cipher.SHA256{}) {
+					continue
+				}
+				if pack.Has(hash) == false {
+					t.Error("missing value in pack:", hash.Hex()[:7])
+				}
+			}
+
+		})
+
+	}
+
+}
+
+func TestRefs_AppendHashes_workers(t *testing.T) {
+
+	var hashes = make([]cipher.SHA256, 0, 100)
+
+	for i := 0; i < cap(hashes); i++ {
+		hashes = append(hashes,
+			cipher.SumSHA256(encoder.Serialize(TestUser{Age: uint32(i)})))
+	}
+
+	var want cipher.SHA256
+
+	for _, workers := range []int{1, 4} {
+
+		t.Run(fmt.Sprintf("workers %d", workers), func(t *testing.T) {
+
+			var (
+				pack = getTestPack()
+				refs Refs
+			)
+
+			pack.workers = workers
+			clearRefs(t, &refs, pack, 2)
+
+			if err := refs.AppendHashes(pack, hashes...); err != nil {
+				t.Fatal(err)
+			}
+
+			testRefsAppendHashesCheck(t, &refs, pack, 0, hashes)
+
+			if workers == 1 {
+				want = refs.Hash
+			} else if refs.Hash != want {
+				t.Error("wrong hash of the Refs")
+			}
+
+		})
+
+	}
 
 }
 
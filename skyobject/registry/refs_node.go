@@ -966,43 +966,76 @@ func (r *Refs) appendCreatingSliceNode(
 // walk updating slice
 //
 
-// walkUpdatingSliceNode walks from given node
-// through subtree setting actual length and hash
-// fields and setting loadedMod flag; the method
-// used after creating new Refs
-func (r *Refs) walkUpdatingSliceNode(
+// walkUpdatingSliceNodes walks through nodes of
+// the Refs from leafs to root setting actual length
+// and hash fields and setting loadedMod flag; the
+// method used after creating new Refs; nodes of the
+// same depth are encoded and hashed using given
+// number of goroutines
+func (r *Refs) walkUpdatingSliceNodes(
 	pack Pack, //    : pack to save
-	rn *refsNode, // : the node to walk from
-	depth int, //    : depth of the node
+	workers int, //  : number of goroutines to hash
 ) (
 	err error, //    : error if any
 ) {
 
-	if depth == 0 {
-
-		rn.length = len(rn.leafs) // it's just length of the array
+	// nodes by depth, levels[r.depth] is the root
+	var levels = make([][]*refsNode, r.depth+1)
 
-	} else { // depth > 0
+	levels[r.depth] = []*refsNode{r.refsNode}
+	for depth := r.depth; depth > 0; depth-- {
+		for _, rn := range levels[depth] {
+			levels[depth-1] = append(levels[depth-1], rn.branches...)
+		}
+	}
 
-		var length int // use local variable
+	for depth, rns := range levels {
 
-		for _, br := range rn.branches {
+		for _, rn := range rns {
 
-			if err = r.walkUpdatingSliceNode(pack, br, depth-1); err != nil {
-				return // some error
+			if depth == 0 {
+				rn.length = len(rn.leafs) // it's just length of the array
+			} else {
+				rn.length = 0
+				for _, br := range rn.branches {
+					rn.length += br.length // already actual
+				}
 			}
 
-			length += br.length
+			rn.mods |= loadedMod // mark as loaded
 
 		}
 
-		rn.length = length // set actual length
+		var hvs = make([]hashedValue, len(rns))
 
-	}
+		parallel(workers, len(rns), func(i int) {
+			if rns[i].upper == nil {
+				return // root is saved with the Refs
+			}
+			hvs[i].val = rns[i].encode(depth)
+			hvs[i].hash = cipher.SumSHA256(hvs[i].val)
+		})
+
+		// see (*refsNode).updateHash
+		for i, rn := range rns {
 
-	rn.mods |= loadedMod // mark as loaded
+			if rn.upper != nil && hvs[i].hash != rn.hash {
 
-	return rn.updateHash(pack, depth) // update hash
+				if err = pack.Set(hvs[i].hash, hvs[i].val); err != nil {
+					return
+				}
+
+				rn.hash = hvs[i].hash
+
+			}
+
+			rn.mods &^= contentMod
+
+		}
+
+	}
+
+	return
 }
 
 //
@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A RefsSnapshot represents read-only view of a Refs
+// taken at some point in time. Since the Refs tree is
+// Merkle-tree, then the snapshot is just the Refs
+// loaded by its current hash. Thus changes of the
+// original Refs don't affect the snapshot and the
+// snapshot can be iterated while the original Refs
+// is modified (e.g. while a publisher keeps appending
+// new elements). The RefsSnapshot requires saved
+// Refs tree in DB, e.g. it loads its branches by
+// needs using given Pack, and the branches should
+// not be removed from DB until the snapshot used.
+// The RefsSnapshot is not thread safe
+type RefsSnapshot struct {
+	refs Refs
+}
+
+// Snapshot of the Refs. If the Refs has unsaved changes
+// (see LazyUpdatingFlag), then they will be saved. But
+// it's impossible to save the changes inside an iterator,
+// and the Snapshot returns ErrRefsIterating in this case
+func (r *Refs) Snapshot(
+	pack Pack, //          : pack to load and save
+) (
+	rs RefsSnapshot, //    : the snapshot
+	err error, //          : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if r.mods&contentMod != 0 {
+
+		if len(r.iterators) > 0 {
+			err = ErrRefsIterating
+			return
+		}
+
+		if err = r.walkUpdating(pack); err != nil {
+			return
+		}
+
+	}
+
+	rs.refs.Hash = r.Hash
+	err = rs.refs.initialize(pack)
+	return
+}
+
+// Hash of the Refs the snapshot taken from
+func (r *RefsSnapshot) Hash() cipher.SHA256 {
+	return r.refs.Hash
+}
+
+// Len returns length of the snapshot
+func (r *RefsSnapshot) Len(pack Pack) (ln int, err error) {
+	return r.refs.Len(pack)
+}
+
+// HashByIndex returns hash by index
+// (see (*Refs).HashByIndex)
+func (r *RefsSnapshot) HashByIndex(
+	pack Pack, //          : pack to load
+	i int, //              : index to find
+) (
+	hash cipher.SHA256, // : hash of the element if found
+	err error, //          : error if any
+) {
+	return r.refs.HashByIndex(pack, i)
+}
+
+// ValueByIndex returns value by index
+// (see (*Refs).ValueByIndex)
+func (r *RefsSnapshot) ValueByIndex(
+	pack Pack, //          : pack to load
+	i int, //              : index to find
+	obj interface{}, //    : pointer to object to decode
+) (
+	hash cipher.SHA256, // : hash of the element
+	err error, //          : error if any
+) {
+	return r.refs.ValueByIndex(pack, i, obj)
+}
+
+// Ascend iterates over all values of the snapshot
+// ascending order (see (*Refs).Ascend)
+func (r *RefsSnapshot) Ascend(
+	pack Pack, //              : pack to load
+	ascendFunc IterateFunc, // : the function
+) (
+	err error, //              : error if any
+) {
+	return r.refs.Ascend(pack, ascendFunc)
+}
+
+// AscendFrom iterates over values of the snapshot ascending
+// order starting from given index (see (*Refs).AscendFrom)
+func (r *RefsSnapshot) AscendFrom(
+	pack Pack, //              : pack to load
+	from int, //               : starting index
+	ascendFunc IterateFunc, // : the function
+) (
+	err error, //              : error if any
+) {
+	return r.refs.AscendFrom(pack, from, ascendFunc)
+}
+
+// Descend iterates over all values of the snapshot
+// descending order (see (*Refs).Descend)
+func (r *RefsSnapshot) Descend(
+	pack Pack, //               : pack to load
+	descendFunc IterateFunc, // : the function
+) (
+	err error, //               : error if any
+) {
+	return r.refs.Descend(pack, descendFunc)
+}
+
+// DescendFrom iterates over values of the snapshot descending
+// order starting from given index (see (*Refs).DescendFrom)
+func (r *RefsSnapshot) DescendFrom(
+	pack Pack, //               : pack to load
+	from int, //                : starting index
+	descendFunc IterateFunc, // : the function
+) (
+	err error, //               : error if any
+) {
+	return r.refs.DescendFrom(pack, from, descendFunc)
+}
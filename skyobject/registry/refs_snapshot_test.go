@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestRefs_Snapshot(t *testing.T) {
+	// Snapshot(pack Pack) (rs RefsSnapshot, err error)
+
+	var pack = getTestPack()
+
+	for _, flags := range []Flags{0, LazyUpdating, HashTableIndex} {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		var (
+			refs  Refs
+			users = testFillRefsWithUsers(t, &refs, pack, 20)
+			has   = getHashList(users)
+
+			rs  RefsSnapshot
+			err error
+		)
+
+		if rs, err = refs.Snapshot(pack); err != nil {
+			t.Fatal(err)
+		}
+
+		// modify the Refs while iterating the snapshot
+
+		err = rs.Ascend(pack, func(i int, hash cipher.SHA256) (err error) {
+			if hash != has[i] {
+				t.Errorf("wrong hash of %d", i)
+			}
+			if i%2 == 0 {
+				err = refs.DeleteByIndex(pack, 0)
+			}
+			if err == nil {
+				err = refs.AppendValues(pack, getTestUsers(3)...)
+			}
+			return
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ln int
+		if ln, err = rs.Len(pack); err != nil {
+			t.Fatal(err)
+		} else if ln != len(has) {
+			t.Errorf("wrong length of snapshot %d, want %d", ln, len(has))
+		}
+
+		if ln, err = refs.Len(pack); err != nil {
+			t.Fatal(err)
+		} else if ln != 20-10+20*3 {
+			t.Errorf("wrong length of Refs %d", ln)
+		}
+
+		var called int
+		err = rs.Descend(pack, func(i int, hash cipher.SHA256) (_ error) {
+			if hash != has[i] {
+				t.Errorf("wrong hash of %d", i)
+			}
+			called++
+			return
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if called != len(has) {
+			t.Errorf("wrong times called %d", called)
+		}
+
+	}
+
+}
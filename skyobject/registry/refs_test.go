@@ -2013,6 +2013,88 @@ func TestRefs_SetHashByIndex(t *testing.T) {
 
 }
 
+func TestRefs_ReplaceByHash(t *testing.T) {
+	// ReplaceByHash(pack Pack, old, hash cipher.SHA256) (err error)
+
+	var (
+		pack = getTestPack()
+
+		dup  = hashByNumber(1)
+		repl = hashByNumber(2)
+
+		r, e Refs
+		err  error
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		t.Logf("flags %08b", flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			t.Log("degree", degree)
+
+			for _, length := range testRefsLengths(degree) {
+
+				t.Log("length", length)
+
+				var hashes, want []cipher.SHA256
+
+				for i, h := range getHashList(getTestUsers(length)) {
+					if i%3 == 0 {
+						hashes, want = append(hashes, dup), append(want, repl)
+					} else {
+						hashes, want = append(hashes, h), append(want, h)
+					}
+				}
+
+				clearRefs(t, &r, pack, degree)
+				clearRefs(t, &e, pack, degree)
+
+				if err = r.AppendHashes(pack, hashes...); err != nil {
+					t.Fatal(err)
+				}
+
+				if err = e.AppendHashes(pack, want...); err != nil {
+					t.Fatal(err)
+				}
+
+				r.Reset() // make it unloaded
+
+				if err = r.ReplaceByHash(pack, dup, repl); err != nil {
+					t.Fatal(err)
+				}
+
+				if err = r.ReplaceByHash(pack, dup, repl); err != ErrNotFound {
+					t.Error("wrong error", err)
+				}
+
+				if testRefsHashByIndex(t, &r, pack, want); t.Failed() {
+					t.FailNow()
+				}
+
+				if err = r.Rebuild(pack); err != nil {
+					t.Fatal(err)
+				} else if err = e.Rebuild(pack); err != nil {
+					t.Fatal(err)
+				}
+
+				if r.Hash != e.Hash {
+					logRefsTree(t, &r, pack, false)
+					t.Fatal("wrong hash of the Refs")
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
 func TestRefs_SetValueByIndex(t *testing.T) {
 	// SetValueByIndex(pack Pack, i int, obj interface{}) (err error)
 
@@ -97,6 +97,10 @@ func (r *Refs) Walk(
 		panic("walkFunc is nil") // for developers
 	}
 
+	if skipped(pack, sch) == true {
+		return // filtered out
+	}
+
 	var resetRequired bool
 
 	defer func() {
@@ -6,12 +6,15 @@ import (
 
 // A Reg creates new Registry
 type Reg struct {
-	tn map[reflect.Type]string // type -> registered name
+	tn  map[reflect.Type]string   // type -> registered name
+	imp []*Registry               // imported registries
+	inp map[reflect.Type]struct{} // structures in progress (getSchema)
 }
 
 func newReg() *Reg {
 	return &Reg{
-		tn: make(map[reflect.Type]string),
+		tn:  make(map[reflect.Type]string),
+		inp: make(map[reflect.Type]struct{}),
 	}
 }
 
@@ -39,6 +42,27 @@ func (r *Reg) Register(name string, val interface{}) {
 	r.tn[typ] = name
 }
 
+// Deregister type registered with given name. It
+// does nothing if there is not a type with the name.
+// The Deregister and the Replace methods allows to
+// build a Registry from overridable defaults
+func (r *Reg) Deregister(name string) {
+	for typ, n := range r.tn {
+		if n == name {
+			delete(r.tn, typ)
+			return
+		}
+	}
+}
+
+// Replace registers type of given value with given name
+// even if the name already registered. It's the same as
+// Deregister + Register
+func (r *Reg) Replace(name string, val interface{}) {
+	r.Deregister(name)
+	r.Register(name, val)
+}
+
 // use (reflect.Type).Name() or name provided to Register;
 // if there aren't, then return nil
 func (r *Reg) typeName(typ reflect.Type) []byte {
@@ -67,6 +91,10 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 		panic("Ref or Refs are not allowed in arrays and slices")
 	}
 
+	if ct := cryptoTypeOf(typ); ct != CryptoTypeNone {
+		return newCryptoSchema(ct) // cipher.PubKey, SHA256 or Sig
+	}
+
 	switch typ.Kind() {
 
 	case reflect.Bool, reflect.Int8, reflect.Uint8,
@@ -116,18 +144,50 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 
 	case reflect.Struct:
 
+		// imported
+
+		if _, ok := r.tn[typ]; ok == false {
+			if e := r.importedByType(typ); e != nil {
+				return e
+			}
+		}
+
+		// a structure that contains itself (using a slice,
+		// directly or through other structures) can't be
+		// described by a Schema, a Ref or Refs should be
+		// used instead
+
+		if _, ok := r.inp[typ]; ok == true {
+			panic("recursive type " + typ.String() + ", use Ref or Refs" +
+				" (skyobject:\"schema=...\") to refer to the type")
+		}
+
+		r.inp[typ] = struct{}{}
+		defer delete(r.inp, typ)
+
 		// get schemas of fields
 
 		ss := new(structSchema)
 		ss.kind, ss.name = typ.Kind(), r.typeName(typ)
 
+		var names = make(map[string]struct{}) // encoded names of fields
+
 		for i, nf := 0, typ.NumField(); i < nf; i++ {
 
 			sf := typ.Field(i)
 			if sf.Tag.Get("enc") == "-" || sf.PkgPath != "" || sf.Name == "_" {
 				continue
 			}
-			ss.fields = append(ss.fields, r.getField(sf))
+
+			f := r.getField(sf)
+
+			if _, ok := names[f.Name()]; ok == true {
+				panic("duplicate field name " + f.Name() + " of " +
+					typ.String())
+			}
+			names[f.Name()] = struct{}{}
+
+			ss.fields = append(ss.fields, f)
 
 		}
 
@@ -140,12 +200,26 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 
 }
 
+// schema of element of Ref or Refs by name, the schema
+// is placeholder (if the name is not imported) that
+// will be replaced with real schema by Registry
+func (r *Reg) elemByName(name string) Schema {
+	if r.isRegistered(name) == false {
+		if e := r.importedByName(name); e != nil {
+			return e
+		}
+	}
+	return &schema{kind: reflect.Struct, name: []byte(name)}
+}
+
 func (r *Reg) getField(sf reflect.StructField) Field {
 
 	f := new(field)
 
-	f.name = []byte(sf.Name)
-	f.tag = []byte(sf.Tag)
+	var name, tag = fieldNameTag(sf)
+
+	f.name = []byte(name)
+	f.tag = []byte(tag)
 
 	t := sf.Type // reflect.Type
 
@@ -158,7 +232,7 @@ func (r *Reg) getField(sf reflect.StructField) Field {
 				kind: reflect.Ptr, // Ref is pointer
 			},
 			typ:  ReferenceTypeSingle,
-			elem: &schema{kind: reflect.Struct, name: []byte(tagRef)},
+			elem: r.elemByName(tagRef),
 		}
 		return f
 	case typeOfRefs: // references
@@ -169,7 +243,7 @@ func (r *Reg) getField(sf reflect.StructField) Field {
 				kind: reflect.Ptr, // Refs is pointer (actually []*T)
 			},
 			typ:  ReferenceTypeSlice,
-			elem: &schema{kind: reflect.Struct, name: []byte(tagRef)},
+			elem: r.elemByName(tagRef),
 		}
 		return f
 	case typeOfDynamic: // dynamic reference
@@ -190,6 +264,8 @@ func (r *Reg) getField(sf reflect.StructField) Field {
 		f.schema = s
 	}
 
+	f.def = mustFieldDefault(sf.Tag, f.schema)
+
 	return f
 
 }
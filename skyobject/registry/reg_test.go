@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"testing"
+)
+
+func TestReg_Deregister(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.Man", TestMan{})
+		r.Deregister("test.Man")
+		r.Deregister("test.Nothing")       // does nothing
+		r.Register("test.Man", TestUser{}) // register again
+	})
+
+	if _, err := reg.SchemaByName("test.User"); err == nil {
+		t.Error("missing error") // TestUser registered as test.Man
+	}
+
+	if s, err := reg.SchemaByName("test.Man"); err != nil {
+		t.Error(err)
+	} else if len(s.Fields()) != 2 || s.Fields()[1].Name() != "Age" {
+		t.Error("wrong schema:", s)
+	}
+
+}
+
+func TestReg_Replace(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Replace("test.User", TestMan{})
+		r.Replace("test.Group", TestGroup{}) // not registered yet
+	})
+
+	if s, err := reg.SchemaByName("test.User"); err != nil {
+		t.Error(err)
+	} else if len(s.Fields()) != 2 || s.Fields()[1].Name() != "GitHub" {
+		t.Error("wrong schema:", s)
+	}
+
+	if _, err := reg.SchemaByName("test.Group"); err != nil {
+		t.Error(err)
+	}
+
+	t.Run("reference", func(t *testing.T) {
+		defer shouldPanic(t)
+
+		NewRegistry(func(r *Reg) {
+			r.Replace("test.Ref", Ref{})
+		})
+	})
+
+}
+
+// a TestTree contains itself
+type TestTree struct {
+	Name     string
+	Children []TestTree
+}
+
+// a TestForest contains itself through TestGrove
+type TestForest struct {
+	Groves []TestGrove
+}
+
+// a TestGrove contains TestForest
+type TestGrove struct {
+	Forests []TestForest
+}
+
+// a TestRefTree refers to itself using Refs
+type TestRefTree struct {
+	Name     string
+	Children Refs `skyobject:"schema=test.RefTree"`
+}
+
+func TestReg_recursive(t *testing.T) {
+
+	t.Run("self", func(t *testing.T) {
+		defer shouldPanic(t)
+
+		NewRegistry(func(r *Reg) {
+			r.Register("test.Tree", TestTree{})
+		})
+	})
+
+	t.Run("mutual", func(t *testing.T) {
+		defer shouldPanic(t)
+
+		NewRegistry(func(r *Reg) {
+			r.Register("test.Forest", TestForest{})
+			r.Register("test.Grove", TestGrove{})
+		})
+	})
+
+	t.Run("refs", func(t *testing.T) {
+		defer shouldNotPanic(t)
+
+		NewRegistry(func(r *Reg) {
+			r.Register("test.RefTree", TestRefTree{})
+		})
+	})
+
+	t.Run("twice", func(t *testing.T) {
+		defer shouldNotPanic(t)
+
+		// the same type used twice is not a recursion
+		NewRegistry(func(r *Reg) {
+			r.Register("test.User", TestUser{})
+			r.Register("test.Pair", struct {
+				First  TestUser
+				Second []TestUser
+			}{})
+		})
+	})
+
+}
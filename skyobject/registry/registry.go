@@ -23,9 +23,16 @@ type Registry struct {
 	reg map[string]Schema    // by name
 	srf map[SchemaRef]Schema // by reference (for Dynamic references)
 
+	// external schemas and registries (nil if not resolved)
+	exs []*externalSchema
+	ext map[RegistryRef]*Registry
+
 	// local (inversed tn of Reg for unpacking directly to reflect.Type)
 	nt map[string]reflect.Type // registered name -> reflect.Type
 	tn map[reflect.Type]string // reflect.Type -> regitered name
+
+	// schema upgrade transformers (see AddTransform)
+	tr map[SchemaRef]transform
 }
 
 // create registry without nt map
@@ -51,7 +58,9 @@ func DecodeRegistry(b []byte) (r *Registry, err error) {
 	r = newRegistry()
 
 	for _, re := range res {
-		s, err = decodeSchema(re.Schema)
+		if s, err = decodeSchema(re.Schema, 1); err != nil {
+			return nil, err
+		}
 		r.reg[re.Name] = s
 		r.srf[s.Reference()] = s
 	}
@@ -80,6 +89,13 @@ func NewRegistry(cl func(t *Reg)) (r *Registry) {
 	r.register(reg)
 	r.finialize()
 
+	// set imported registries
+	for _, ir := range reg.imp {
+		if _, ok := r.ext[ir.Reference()]; ok == true {
+			r.ext[ir.Reference()] = ir
+		}
+	}
+
 	return
 }
 
@@ -106,15 +122,28 @@ func (r *Registry) Reference() RegistryRef {
 }
 
 // SchemaByReference returns Schema by SchemaRef that is obvious.
+// If this Registry doesn't have the Schema, then resolved
+// external Registries are used to find it. It returns
+// error that wraps ErrMissingSchema if the Schema not found
 func (r *Registry) SchemaByReference(sr SchemaRef) (s Schema, err error) {
 	var ok bool
-	if s, ok = r.srf[sr]; !ok {
-		err = fmt.Errorf("missng schema %q", sr.String())
+	if s, ok = r.srf[sr]; ok == true {
+		return
+	}
+	for _, er := range r.ext {
+		if er == nil {
+			continue // not resolved
+		}
+		if s, err = er.SchemaByReference(sr); err == nil {
+			return
+		}
 	}
+	err = fmt.Errorf("%w %q", ErrMissingSchema, sr.String())
 	return
 }
 
-// SchemaByName returns schema by name or "missing schema" error
+// SchemaByName returns schema by name or
+// error that wraps ErrMissingSchema
 func (r *Registry) SchemaByName(name string) (Schema, error) {
 	return r.schemaByName(name)
 }
@@ -131,6 +160,104 @@ func (r *Registry) Types() (ts *Types) {
 	return
 }
 
+// Schemas returns all registered schemas ordered by name
+func (r *Registry) Schemas() (ss []Schema) {
+
+	if len(r.reg) == 0 {
+		return
+	}
+
+	var names = make([]string, 0, len(r.reg))
+	for name := range r.reg {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	ss = make([]Schema, 0, len(names))
+	for _, name := range names {
+		ss = append(ss, r.reg[name])
+	}
+
+	return
+}
+
+// Dependencies returns names of registered schemas the schema with
+// given name refers to. It walks through Ref, Refs, arrays, slices
+// and nested structures. The Dynamic references are not represented,
+// since schema of a Dynamic is not known before the Dynamic is set.
+// Schemas of external Registries (see Externals) are not represented
+// too.
+// Result is ordered by name and contains every name once. The
+// Dependencies returns "missing schema" error if there is not
+// a schema with given name
+func (r *Registry) Dependencies(name string) (deps []string, err error) {
+
+	var s Schema
+	if s, err = r.schemaByName(name); err != nil {
+		return
+	}
+
+	var dm = make(map[string]struct{})
+
+	switch s.Kind() {
+	case reflect.Struct:
+		for _, f := range s.Fields() {
+			collectDependencies(f.Schema(), dm)
+		}
+	case reflect.Array, reflect.Slice:
+		collectDependencies(s.Elem(), dm)
+	}
+
+	if len(dm) == 0 {
+		return
+	}
+
+	deps = make([]string, 0, len(dm))
+	for dep := range dm {
+		deps = append(deps, dep)
+	}
+
+	sort.Strings(deps)
+	return
+}
+
+// collect names of registered schemas; it doesn't
+// walk through registered schemas, thus recursive
+// types never break it
+func collectDependencies(s Schema, dm map[string]struct{}) {
+
+	if s == nil {
+		return
+	}
+
+	if _, ok := s.(*externalSchema); ok == true {
+		return // schema of another Registry
+	}
+
+	if s.IsReference() == true {
+		if s.ReferenceType() != ReferenceTypeDynamic {
+			collectDependencies(s.Elem(), dm)
+		}
+		return
+	}
+
+	if s.IsRegistered() == true {
+		dm[s.Name()] = struct{}{}
+		return
+	}
+
+	switch s.Kind() {
+	case reflect.Struct:
+		for _, f := range s.Fields() {
+			collectDependencies(f.Schema(), dm)
+		}
+	case reflect.Array, reflect.Slice:
+		collectDependencies(s.Elem(), dm)
+	}
+
+}
+
 // range over registered types, and create schemas
 func (r *Registry) register(reg *Reg) {
 
@@ -155,14 +282,23 @@ func (r *Registry) fillSchema(s Schema, filled map[Schema]struct{}) {
 		return // already
 	}
 	filled[s] = struct{}{} // filling
+	if e, ok := s.(*externalSchema); ok == true {
+		r.addExternal(e)
+		return
+	}
+	if _, ok := s.(*cryptoSchema); ok == true {
+		return // nothing to fill
+	}
 	var err error
 	if s.IsReference() {
 		switch s.ReferenceType() {
 		case ReferenceTypeSingle, ReferenceTypeSlice:
 			x := s.(*referenceSchema)
-			x.elem, err = r.schemaByName(x.elem.Name())
-			if err != nil {
-				panic(err)
+			if _, ok := x.elem.(*externalSchema); ok == false {
+				x.elem, err = r.schemaByName(x.elem.Name())
+				if err != nil {
+					panic(err)
+				}
 			}
 			r.fillSchema(x.elem, filled)
 		case ReferenceTypeDynamic:
@@ -209,7 +345,7 @@ func (r *Registry) fillSchema(s Schema, filled map[Schema]struct{}) {
 func (r *Registry) schemaByName(name string) (s Schema, err error) {
 	var ok bool
 	if s, ok = r.reg[name]; !ok {
-		err = fmt.Errorf("missing schema %q", name)
+		err = fmt.Errorf("%w %q", ErrMissingSchema, name)
 	}
 	return
 }
@@ -272,7 +408,17 @@ func typeOf(i interface{}) reflect.Type {
 
 // decode schema
 
-func decodeSchema(b []byte) (s Schema, err error) {
+// DecodeSchema decodes encoded Schema (see (Schema).Encode).
+// Registered schemas the Schema uses (types of fields and
+// elements of references) encoded by name only, and a
+// decoded Schema keeps them as is
+func DecodeSchema(b []byte) (s Schema, err error) {
+	return decodeSchema(b, 1)
+}
+
+// the depth is nesting depth of the Schema, that
+// is limited by the DefaultLimits.MaxDepth
+func decodeSchema(b []byte, depth int) (s Schema, err error) {
 	// type encodedSchema struct {
 	// 	ReferenceType uint32
 	// 	Kind   uint32
@@ -288,6 +434,11 @@ func decodeSchema(b []byte) (s Schema, err error) {
 	// 	Schema []byte
 	// }
 
+	if max := DefaultLimits.MaxDepth; max > 0 && depth > max {
+		err = ErrDepthLimit
+		return
+	}
+
 	var x encodedSchema
 	if err = encoder.DeserializeRaw(b, &x); err != nil {
 		return
@@ -300,7 +451,7 @@ func decodeSchema(b []byte) (s Schema, err error) {
 		rs.kind = reflect.Kind(x.Kind)
 		rs.typ = ReferenceType(x.ReferenceType)
 		if rs.typ != ReferenceTypeDynamic {
-			if rs.elem, err = decodeSchema(x.Elem); err != nil {
+			if rs.elem, err = decodeSchema(x.Elem, depth+1); err != nil {
 				return
 			}
 		}
@@ -308,6 +459,12 @@ func decodeSchema(b []byte) (s Schema, err error) {
 		return
 	case ReferenceTypeNone: // not a reference
 	default:
+		if x.ReferenceType == externalSchemaMark {
+			return decodeExternalSchema(&x)
+		}
+		if isCryptoSchemaMark(x.ReferenceType) == true {
+			return decodeCryptoSchema(&x)
+		}
 		err = ErrInvalidEncodedSchema
 		return
 	}
@@ -321,7 +478,7 @@ func decodeSchema(b []byte) (s Schema, err error) {
 	case reflect.Slice:
 		ss := sliceSchema{}
 		ss.schema = sc
-		if ss.elem, err = decodeSchema(x.Elem); err != nil {
+		if ss.elem, err = decodeSchema(x.Elem, depth+1); err != nil {
 			return
 		}
 		s = &ss
@@ -329,7 +486,10 @@ func decodeSchema(b []byte) (s Schema, err error) {
 		as := arraySchema{}
 		as.schema = sc
 		as.length = int(x.Len)
-		if as.elem, err = decodeSchema(x.Elem); err != nil {
+		if err = DefaultLimits.checkLength(as.length); err != nil {
+			return
+		}
+		if as.elem, err = decodeSchema(x.Elem, depth+1); err != nil {
 			return
 		}
 		s = &as
@@ -338,7 +498,7 @@ func decodeSchema(b []byte) (s Schema, err error) {
 		ss.schema = sc
 		var f Field
 		for _, ef := range x.Fields {
-			if f, err = decodeField(ef); err != nil {
+			if f, err = decodeField(ef, depth+1); err != nil {
 				return
 			}
 			ss.fields = append(ss.fields, f)
@@ -351,7 +511,7 @@ func decodeSchema(b []byte) (s Schema, err error) {
 	return
 }
 
-func decodeField(b []byte) (f Field, err error) {
+func decodeField(b []byte, depth int) (f Field, err error) {
 	var ef encodedField
 	if err = encoder.DeserializeRaw(b, &ef); err != nil {
 		return
@@ -359,7 +519,10 @@ func decodeField(b []byte) (f Field, err error) {
 	ff := field{}
 	ff.name = ef.Name
 	ff.tag = ef.Tag
-	if ff.schema, err = decodeSchema(ef.Schema); err != nil {
+	if ff.schema, err = decodeSchema(ef.Schema, depth); err != nil {
+		return
+	}
+	if ff.def, err = fieldDefault(ff.Tag(), ff.schema); err != nil {
 		return
 	}
 	f = &ff
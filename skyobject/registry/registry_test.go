@@ -2,6 +2,7 @@ package registry
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -293,6 +294,8 @@ func TestRegistry_SchemaByName(t *testing.T) {
 
 	if _, err = reg.SchemaByName("nothing"); err == nil {
 		t.Error("missing error")
+	} else if errors.Is(err, ErrMissingSchema) == false {
+		t.Error("unexpected error:", err)
 	}
 
 	if g, err = reg.SchemaByName("test.Group"); err != nil {
@@ -334,6 +337,12 @@ func TestRegistry_SchemaByReference(t *testing.T) {
 
 	}
 
+	if _, err = reg.SchemaByReference(SchemaRef{1, 2, 3}); err == nil {
+		t.Error("missing error")
+	} else if errors.Is(err, ErrMissingSchema) == false {
+		t.Error("unexpected error:", err)
+	}
+
 }
 
 func TestRegistry_Encode(t *testing.T) {
@@ -416,3 +425,73 @@ func TestRegistry_userProvidedName(t *testing.T) {
 	}
 
 }
+
+func TestRegistry_Schemas(t *testing.T) {
+
+	var reg = testRegistry()
+
+	var ss = reg.Schemas()
+
+	if len(ss) != len(testTypes()) {
+		t.Fatal("wrong number of schemas", len(ss))
+	}
+
+	for i := 1; i < len(ss); i++ {
+		if ss[i-1].Name() >= ss[i].Name() {
+			t.Error("wrong order:", ss[i-1].Name(), ss[i].Name())
+		}
+	}
+
+	if ss = NewRegistry(func(*Reg) {}).Schemas(); len(ss) != 0 {
+		t.Error("schemas of empty registry")
+	}
+
+}
+
+func TestRegistry_Dependencies(t *testing.T) {
+
+	var (
+		reg  = testRegistry()
+		deps []string
+		err  error
+	)
+
+	if _, err = reg.Dependencies("nothing"); err == nil {
+		t.Error("missing error")
+	}
+
+	// Refs, Ref and Dynamic
+	if deps, err = reg.Dependencies("test.Group"); err != nil {
+		t.Error(err)
+	} else if len(deps) != 1 || deps[0] != "test.User" {
+		t.Error("wrong dependencies:", deps)
+	}
+
+	// arrays and slices of structures
+	if deps, err = reg.Dependencies("test.Arrays"); err != nil {
+		t.Error(err)
+	} else if len(deps) != 2 ||
+		deps[0] != "test.Empty" ||
+		deps[1] != "test.String" {
+		t.Error("wrong dependencies:", deps)
+	}
+
+	// no dependencies
+	if deps, err = reg.Dependencies("test.User"); err != nil {
+		t.Error(err)
+	} else if len(deps) != 0 {
+		t.Error("unexpected dependencies:", deps)
+	}
+
+	// decoded registry
+	if reg, err = DecodeRegistry(reg.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	if deps, err = reg.Dependencies("test.Group"); err != nil {
+		t.Error(err)
+	} else if len(deps) != 1 || deps[0] != "test.User" {
+		t.Error("wrong dependencies:", deps)
+	}
+
+}
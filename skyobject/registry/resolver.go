@@ -0,0 +1,234 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A Resolver dereferences Ref, Refs and Dynamic
+// values using given Pack. The Resolver memoizes
+// objects by hash, thus every object is got from
+// the Pack once. Values returned by the Resolver
+// use the Resolver to dereference their references.
+// The Resolver is not thread safe and it is designed
+// to be used for short time, since the memoized
+// objects are never released
+type Resolver struct {
+	Pack // underlying Pack
+
+	m     map[cipher.SHA256][]byte   // memoized objects
+	stack map[cipher.SHA256]struct{} // objects being walked
+}
+
+// NewResolver creates Resolver using given Pack
+func NewResolver(pack Pack) (r *Resolver) {
+	r = new(Resolver)
+	r.Pack = pack
+	r.m = make(map[cipher.SHA256][]byte)
+	r.stack = make(map[cipher.SHA256]struct{})
+	return
+}
+
+// Get object by hash. The Get implements
+// Pack interface and memoizes objects
+func (r *Resolver) Get(key cipher.SHA256) (val []byte, err error) {
+
+	var ok bool
+	if val, ok = r.m[key]; ok == true {
+		return
+	}
+
+	if val, err = r.Pack.Get(key); err != nil {
+		return
+	}
+
+	r.m[key] = val
+	return
+}
+
+// Value returns copy of given Value
+// that uses the Resolver
+func (r *Resolver) Value(v Value) Value {
+	v.pack = r
+	return v
+}
+
+// Dereference given Ref or Dynamic
+// (see Value.Dereference)
+func (r *Resolver) Dereference(v Value) (dv Value, err error) {
+	return r.Value(v).Dereference()
+}
+
+// Elements returns dereferenced elements of given Refs.
+// A nil element of the Refs is represented as zero Value
+func (r *Resolver) Elements(v Value) (evs []Value, err error) {
+
+	if v.s == nil || v.s.ReferenceType() != ReferenceTypeSlice {
+		err = ErrInvalidValueKind
+		return
+	}
+
+	v = r.Value(v)
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	evs = make([]Value, ln)
+
+	for i := range evs {
+		if evs[i], err = v.Index(i); err == ErrRefsElementIsNil {
+			err = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// A ResolveFunc used by the Walk method of the Resolver.
+// The path is path of the Value (see Value.ByPath). Return
+// false to don't walk through the Value
+type ResolveFunc func(path string, v Value) (deeper bool, err error)
+
+// Walk given Value depth-first calling given function
+// for every value, including fields of structures and
+// elements of arrays and slices. References are resolved
+// and objects they refer to are passed to the function
+// with path of the reference. Nil references are not
+// resolved. The Walk returns ErrReferenceCycle if an
+// object refers to itself directly or indirectly
+func (r *Resolver) Walk(v Value, walkFunc ResolveFunc) (err error) {
+	return r.walk("", r.Value(v), walkFunc)
+}
+
+func (r *Resolver) walk(
+	path string, //          : path of the Value
+	v Value, //              : the Value
+	walkFunc ResolveFunc, // : the function
+) (
+	err error, //            : an error
+) {
+
+	var deeper bool
+	if deeper, err = walkFunc(path, v); err != nil || deeper == false {
+		return
+	}
+
+	if v.s == nil || v.IsNil() == true {
+		return
+	}
+
+	switch v.s.ReferenceType() {
+
+	case ReferenceTypeSingle, ReferenceTypeDynamic:
+
+		var dv Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return r.walkObject(hashOf(v), path, dv, walkFunc)
+
+	case ReferenceTypeSlice:
+
+		var refs Refs
+		if refs, err = v.refs(); err != nil {
+			return
+		}
+
+		return refs.Ascend(r, func(i int, hash cipher.SHA256) (err error) {
+
+			var ep = path + pathElem{index: i}.String()
+
+			if hash == (cipher.SHA256{}) {
+				return // nil element
+			}
+
+			var ev Value
+			if ev, err = valueByHash(r, v.s.Elem(), hash); err != nil {
+				return
+			}
+
+			return r.walkObject(hash, ep, ev, walkFunc)
+		})
+
+	}
+
+	switch v.s.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		var ln int
+		if ln, err = v.Len(); err != nil {
+			return
+		}
+
+		for i := 0; i < ln; i++ {
+
+			var ev Value
+			if ev, err = v.Index(i); err != nil {
+				return
+			}
+
+			var ep = path + pathElem{index: i}.String()
+
+			if err = r.walk(ep, ev, walkFunc); err != nil {
+				return
+			}
+
+		}
+
+	case reflect.Struct:
+
+		for i, f := range v.s.Fields() {
+
+			var fv Value
+			if fv, err = v.FieldByIndex(i); err != nil {
+				return
+			}
+
+			var fp = f.Name()
+			if path != "" {
+				fp = path + "." + fp
+			}
+
+			if err = r.walk(fp, fv, walkFunc); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// walk object referred by given hash
+func (r *Resolver) walkObject(
+	hash cipher.SHA256, //   : hash of the object
+	path string, //          : path of the reference
+	v Value, //              : the object
+	walkFunc ResolveFunc, // : the function
+) (
+	err error, //            : an error
+) {
+
+	if _, ok := r.stack[hash]; ok == true {
+		return ErrReferenceCycle
+	}
+
+	r.stack[hash] = struct{}{}
+	defer delete(r.stack, hash)
+
+	return r.walk(path, v, walkFunc)
+}
+
+// hash of Ref or Dynamic
+func hashOf(v Value) (hash cipher.SHA256) {
+	copy(hash[:], v.p)
+	return
+}
@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// counts Get calls
+type countingPack struct {
+	*dummyPack
+	gets int
+}
+
+func (c *countingPack) Get(key cipher.SHA256) ([]byte, error) {
+	c.gets++
+	return c.dummyPack.Get(key)
+}
+
+func TestResolver_Get(t *testing.T) {
+
+	var dp, v = testValueGroup(t)
+
+	var (
+		cp = &countingPack{dummyPack: dp}
+		r  = NewResolver(cp)
+	)
+
+	var c, err = v.FieldByName("Curator")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = r.Dereference(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if cp.gets != 1 {
+		t.Error("not memoized", cp.gets)
+	}
+
+	var ms Value
+	if ms, err = v.FieldByName("Members"); err != nil {
+		t.Fatal(err)
+	}
+
+	var evs []Value
+	if evs, err = r.Elements(ms); err != nil {
+		t.Fatal(err)
+	} else if len(evs) != 3 {
+		t.Fatal("wrong number of elements", len(evs))
+	}
+
+	var name Value
+	if name, err = evs[1].FieldByName("Name"); err != nil {
+		t.Fatal(err)
+	}
+	testValueString(t, name, "", "Eva")
+
+	if _, err = r.Elements(c); err != ErrInvalidValueKind {
+		t.Error("wrong error", err)
+	}
+
+}
+
+func TestResolver_Walk(t *testing.T) {
+
+	var dp, v = testValueGroup(t)
+
+	var (
+		r     = NewResolver(dp)
+		paths = make(map[string]int)
+	)
+
+	var err = r.Walk(v, func(path string, v Value) (bool, error) {
+		paths[path]++
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, n := range map[string]int{
+		"":                1,
+		"Name":            1,
+		"Members":         1,
+		"Members[2]":      1,
+		"Members[2].Name": 1,
+		"Curator":         2, // Ref and User
+		"Curator.Age":     1,
+		"Developer":       2, // Dynamic and Man
+		"Developer.Name":  1,
+	} {
+		if paths[path] != n {
+			t.Errorf("wrong times of %q: want %d, got %d", path, n,
+				paths[path])
+		}
+	}
+
+	// don't go deeper
+
+	paths = make(map[string]int)
+	err = r.Walk(v, func(path string, v Value) (bool, error) {
+		paths[path]++
+		return path == "", nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	} else if paths["Curator"] != 1 || paths["Curator.Name"] != 0 {
+		t.Error("walks deeper")
+	}
+
+}
+
+func TestResolver_Walk_cycle(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+		hash = cipher.SumSHA256([]byte("fake"))
+	)
+
+	var sch, err = pack.Registry().SchemaByName("test.Group")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a group that refers to itself using
+	// fake hash (broken Pack)
+
+	var group = TestGroup{Name: "loop"}
+	group.Developer = Dynamic{Hash: hash, Schema: sch.Reference()}
+
+	pack.Set(hash, encoder.Serialize(group))
+
+	var v Value
+	if v, err = DynamicValue(pack, group.Developer); err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewResolver(pack).Walk(v, func(string, Value) (bool, error) {
+		return true, nil
+	})
+
+	if err != ErrReferenceCycle {
+		t.Error("wrong error", err)
+	}
+
+}
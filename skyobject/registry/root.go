@@ -39,6 +39,12 @@ type Root struct {
 	// of a Root, and this field is machine
 	// local
 	IsFull bool `enc:"-"`
+	// IsPartial means that this Root object
+	// has been collected with objects of some
+	// schemas only (see skyobject.Filler.Filter).
+	// A partial Root is not full. The field is
+	// machine local too
+	IsPartial bool `enc:"-"`
 }
 
 // Encode the Root
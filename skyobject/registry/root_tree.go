@@ -373,6 +373,19 @@ func rootTreeSlice(pack Pack, sch Schema, val []byte) (it gotree.GTStructure) {
 		return
 	}
 
+	// special case for cipher.PubKey, cipher.SHA256 and cipher.Sig
+	if ct := sch.CryptoType(); ct != CryptoTypeNone {
+
+		if len(val) < sch.Len() {
+			it.Name = "(err) " + ErrInvalidSchemaOrData.Error()
+			return
+		}
+
+		it.Name = fmt.Sprintf("(%s) %s", ct.String(),
+			hex.EncodeToString(val[:sch.Len()]))
+		return
+	}
+
 	// special case for []byte
 	if sch.Kind() == reflect.Slice && el.Kind() == reflect.Uint8 {
 
@@ -410,7 +423,7 @@ func rootTreeSlice(pack Pack, sch Schema, val []byte) (it gotree.GTStructure) {
 			it.Name = fmt.Sprintf("[]%s", el.String())
 		}
 
-		if ln, err = getLength(val); err != nil {
+		if ln, err = DefaultLimits.length(val); err != nil {
 			it.Name += " (err) " + err.Error()
 			return
 		}
@@ -47,6 +47,11 @@ type Schema interface {
 	// element is not specified by schema)
 	Elem() (s Schema)
 
+	// CryptoType of the Schema if the Schema is schema of
+	// cipher.PubKey, cipher.SHA256 or cipher.Sig. Such
+	// schemas are arrays of bytes
+	CryptoType() CryptoType
+
 	RawName() []byte    // raw name if named
 	IsRegistered() bool // is registered or not
 
@@ -56,6 +61,11 @@ type Schema interface {
 	Size(p []byte) (n int, err error)
 
 	fmt.Stringer // String() string
+
+	// GoString returns Go definition of the Schema:
+	// type declaration for a registered struct and
+	// type expression for other (best-effort)
+	fmt.GoStringer // GoString() string
 }
 
 var nilSchema Schema = &schema{kind: reflect.Invalid}
@@ -92,6 +102,10 @@ func (s *schema) Kind() reflect.Kind {
 	return s.kind
 }
 
+func (s *schema) CryptoType() CryptoType {
+	return CryptoTypeNone
+}
+
 func (s *schema) Name() string {
 	return string(s.name)
 }
@@ -117,28 +131,7 @@ func (s *schema) Elem() Schema {
 }
 
 func (s *schema) Size(p []byte) (n int, err error) {
-	switch s.kind {
-	case reflect.Bool, reflect.Int8, reflect.Uint8:
-		n = 1
-	case reflect.Int16, reflect.Uint16:
-		n = 2
-	case reflect.Int32, reflect.Uint32, reflect.Float32:
-		n = 4
-	case reflect.Int64, reflect.Uint64, reflect.Float64:
-		n = 8
-	case reflect.String:
-		if n, err = getLength(p); err != nil {
-			return
-		}
-		n += 4 // encoded length (uint32)
-	default:
-		err = ErrInvalidSchemaOrData
-		return
-	}
-	if n > len(p) {
-		err = ErrInvalidSchemaOrData
-	}
-	return
+	return DefaultLimits.Size(s, p)
 }
 
 func (s *schema) encodedSchema() (x encodedSchema) {
@@ -220,7 +213,10 @@ func (r *referenceSchema) encodedSchema() (x encodedSchema) {
 	x.Kind = uint32(r.kind)
 	x.ReferenceType = uint32(r.typ)
 	// the schema of the Elem is registered allways
-	if r.typ != ReferenceTypeDynamic {
+	// (here or in an external Registry)
+	if e, ok := r.elem.(*externalSchema); ok == true {
+		x.Elem = e.Encode()
+	} else if r.typ != ReferenceTypeDynamic {
 		x.Elem = (&schema{
 			SchemaRef{},
 			r.elem.Kind(),
@@ -273,15 +269,7 @@ func (s *sliceSchema) Elem() Schema {
 }
 
 func (s *sliceSchema) Size(p []byte) (n int, err error) {
-	var l int
-	if l, err = getLength(p); err != nil {
-		return
-	}
-	n, err = schemaArraySliceSize(s.Elem(), l, 4, p)
-	if err == nil && n > len(p) {
-		err = ErrInvalidSchemaOrData
-	}
-	return
+	return DefaultLimits.Size(s, p)
 }
 
 func (s *sliceSchema) encodedSchema() (x encodedSchema) {
@@ -328,11 +316,7 @@ func (a *arraySchema) Reference() SchemaRef {
 }
 
 func (a *arraySchema) Size(p []byte) (n int, err error) {
-	n, err = schemaArraySliceSize(a.Elem(), a.Len(), 0, p)
-	if err == nil && n > len(p) {
-		err = ErrInvalidSchemaOrData
-	}
-	return
+	return DefaultLimits.Size(a, p)
 }
 
 func (a *arraySchema) encodedSchema() (x encodedSchema) {
@@ -382,21 +366,7 @@ func (s *structSchema) Fields() []Field {
 }
 
 func (s *structSchema) Size(p []byte) (n int, err error) {
-	var m int
-	for _, sf := range s.Fields() {
-		if n > len(p) {
-			err = ErrInvalidSchemaOrData
-			return
-		}
-		if m, err = sf.Schema().Size(p[n:]); err != nil {
-			return
-		}
-		n += m
-	}
-	if n > len(p) {
-		err = ErrInvalidSchemaOrData
-	}
-	return
+	return DefaultLimits.Size(s, p)
 }
 
 func (s *structSchema) encodedSchema() (x encodedSchema) {
@@ -425,14 +395,18 @@ type Field interface {
 	Schema() Schema     // Schema of the Field
 	Kind() reflect.Kind // kind of the Field (short hand)
 
-	Name() string    // Name of the Filed
+	Name() string    // Name of the Filed (see TagName)
 	RawName() []byte // raw name of the Filed
 
-	Tag() reflect.StructTag // Tag of the Filed
+	Tag() reflect.StructTag // Tag of the Filed (without name=)
 	RawTag() []byte         // raw tag of the Field
 
 	Encode() (b []byte) // Encode field
 
+	// Default returns encoded default value of
+	// the Field if it's set (see TagDefault)
+	Default() (val []byte, ok bool)
+
 	fmt.Stringer // String() string
 }
 
@@ -442,6 +416,7 @@ type field struct {
 	name   []byte
 	tag    []byte
 	schema Schema
+	def    []byte // encoded default value (by tag) or nil
 }
 
 func (f *field) Name() string {
@@ -468,6 +443,10 @@ func (f *field) Kind() reflect.Kind {
 	return f.schema.Kind()
 }
 
+func (f *field) Default() (val []byte, ok bool) {
+	return f.def, f.def != nil
+}
+
 func (f *field) encodedField() (x encodedField) {
 	x.Name = f.name
 	x.Tag = f.tag
@@ -21,32 +21,6 @@ func init() {
 	}
 }
 
-// schemaArraySliceSize iterates over encoded elements of array or slice
-// to get size used by them; l is length of array or slice, shift is
-// shift in p slice from which data begins, el is schema of element
-func schemaArraySliceSize(el Schema, l, shift int, p []byte) (n int,
-	err error) {
-
-	n += shift
-
-	if s := fixedSize(el.Kind()); s > 0 {
-		n += l * s
-	} else {
-		var m int
-		for i := 0; i < l; i++ {
-			if n > len(p) {
-				err = ErrInvalidSchemaOrData
-				return
-			}
-			if m, err = el.Size(p[n:]); err != nil {
-				return
-			}
-			n += m
-		}
-	}
-	return
-}
-
 // getLength of length prefixed values
 // (like slice of string)
 func getLength(p []byte) (l int, err error) {
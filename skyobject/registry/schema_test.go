@@ -584,6 +584,74 @@ func TestSchema_Size(t *testing.T) {
 
 }
 
+type TestSizeElem struct {
+	Name  string
+	Flags []uint16
+	Users Refs `skyobject:"schema=test.User"`
+}
+
+type TestSizeNested struct {
+	Elems   []TestSizeElem
+	Matrix  [][]string
+	Pair    [2]TestSizeElem
+	Any     Dynamic
+	Curator Ref `skyobject:"schema=test.User"`
+	Bytes   [][]byte
+}
+
+func TestSchema_Size_nested(t *testing.T) {
+	// Size(p []byte) (n int, err error)
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.SizeElem", TestSizeElem{})
+		r.Register("test.SizeNested", TestSizeNested{})
+	})
+
+	var s, err = reg.SchemaByName("test.SizeNested")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var elem = TestSizeElem{Name: "elem", Flags: []uint16{1, 2, 3}}
+
+	for _, val := range []TestSizeNested{
+		{}, // blank
+		{
+			Elems:  []TestSizeElem{elem, {}, elem},
+			Matrix: [][]string{{"a", "bc"}, {}, {"def"}},
+			Pair:   [2]TestSizeElem{{}, elem},
+			Bytes:  [][]byte{[]byte("hey"), nil, []byte("ho")},
+		},
+	} {
+
+		var (
+			data = encoder.Serialize(val)
+			ss   int
+		)
+
+		if ss, err = s.Size(data); err != nil {
+			t.Error(err)
+		} else if ss != len(data) {
+			t.Errorf("wrong Size: want %d, got %d", len(data), ss)
+		}
+
+		// with tail
+		if ss, err = s.Size(append(data, 1, 2, 3)); err != nil {
+			t.Error(err)
+		} else if ss != len(data) {
+			t.Errorf("wrong Size with tail: want %d, got %d", len(data), ss)
+		}
+
+		// truncated
+		if _, err = s.Size(data[:len(data)-1]); err == nil {
+			t.Error("missing error")
+		}
+
+	}
+
+}
+
 func TestSchema_String(t *testing.T) {
 	// String() string
 
@@ -48,6 +48,10 @@ type Splitter interface {
 	// Fail the splitting
 	Fail(err error)
 
+	// Skip returns true if objects of given Schema
+	// are filtered out and should not be requested
+	Skip(sch Schema) (skip bool)
+
 	//
 	// goroutines limit and waiting
 	//
@@ -76,6 +80,10 @@ func splitSchemaHash(
 		return // nothing to split
 	}
 
+	if s.Skip(sch) == true {
+		return // filtered out
+	}
+
 	var (
 		rc  int
 		val []byte
@@ -221,7 +229,7 @@ func splitSlice(
 		err error
 	)
 
-	if ln, err = getLength(val); err != nil {
+	if ln, err = DefaultLimits.length(val); err != nil {
 		s.Fail(err)
 		return
 	}
@@ -65,6 +65,10 @@ func (r *Refs) Split(s Splitter, el Schema) {
 		return // done
 	}
 
+	if s.Skip(el) == true {
+		return // filtered out, don't load the Refs
+	}
+
 	// first of all, check the Refs.Hash
 
 	if r.splitHash(&fp, r.Hash) == false {
@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TagName returns encoded name of a field from given
+// reflect.StructTag. E.g. it returns "user_name" and true
+// if tag is `skyobject:"name=user_name"`. The encoded
+// name used by schema of the field instead of the Go
+// name of the field. Thus, the Go name can be changed
+// without changing the schema (and RegistryRef)
+func TagName(tag reflect.StructTag) (name string, ok bool) {
+	for _, part := range strings.Split(tag.Get(Tag), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name="), true
+		}
+	}
+	return
+}
+
+// fieldNameTag returns name and tag of a field that
+// used by schema of the field. The name is Go name of
+// the field or name from the tag (see TagName). The
+// tag is the same as given, but without the name=
+// part, since the part is not a part of the schema
+func fieldNameTag(sf reflect.StructField) (name, tag string) {
+
+	var tn, ok = TagName(sf.Tag)
+
+	if ok == false {
+		return sf.Name, string(sf.Tag)
+	}
+
+	if tn == "" {
+		panic("empty name in tag of field " + sf.Name)
+	}
+
+	var (
+		sky   = sf.Tag.Get(Tag)
+		parts []string
+	)
+
+	for _, part := range strings.Split(sky, ",") {
+		if strings.HasPrefix(part, "name=") == false {
+			parts = append(parts, part)
+		}
+	}
+
+	var (
+		old = Tag + ":" + strconv.Quote(sky)
+		rpl string
+	)
+
+	if len(parts) > 0 {
+		rpl = Tag + ":" + strconv.Quote(strings.Join(parts, ","))
+	}
+
+	tag = strings.TrimSpace(strings.Replace(string(sf.Tag), old, rpl, 1))
+	tag = strings.Join(strings.Fields(tag), " ") // remove double spaces
+
+	return tn, tag
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagName(t *testing.T) {
+
+	for _, tt := range []struct {
+		tag  reflect.StructTag
+		name string
+		ok   bool
+	}{
+		{``, "", false},
+		{`skyobject:"schema=test.User"`, "", false},
+		{`skyobject:"name=user_name"`, "user_name", true},
+		{`skyobject:"schema=test.User,name=users"`, "users", true},
+	} {
+		if name, ok := TagName(tt.tag); name != tt.name || ok != tt.ok {
+			t.Errorf("wrong name of %q: %q, %t", tt.tag, name, ok)
+		}
+	}
+
+}
+
+func TestReg_Register_renaming(t *testing.T) {
+
+	type UserV1 struct {
+		UserName string
+		Age      uint32 `json:"age" skyobject:"default=1"`
+		Friends  Refs   `skyobject:"schema=test.User"`
+	}
+
+	// renamed Go fields
+	type UserV2 struct {
+		Name    string `skyobject:"name=UserName"`
+		Years   uint32 `json:"age" skyobject:"default=1,name=Age"`
+		Friends Refs   `skyobject:"name=Friends,schema=test.User"`
+	}
+
+	var (
+		r1 = NewRegistry(func(r *Reg) { r.Register("test.User", UserV1{}) })
+		r2 = NewRegistry(func(r *Reg) { r.Register("test.User", UserV2{}) })
+	)
+
+	if r1.Reference() != r2.Reference() {
+		t.Error("RegistryRef changed")
+	}
+
+	var s, err = r2.SchemaByName("test.User")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := s.Fields()[0].Name(); name != "UserName" {
+		t.Errorf("wrong field name %q", name)
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+
+		type Dup struct {
+			A string
+			B string `skyobject:"name=A"`
+		}
+
+		defer shouldPanic(t)
+
+		NewRegistry(func(r *Reg) { r.Register("test.Dup", Dup{}) })
+
+	})
+
+}
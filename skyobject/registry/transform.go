@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A TransformFunc converts given Value of an old Schema
+// to new Schema. The Value uses Pack of an old Registry.
+// The function should save converted object (and all new
+// objects it refers to) to given Pack that uses new
+// Registry and return hash of the converted object
+type TransformFunc func(v Value, pack Pack) (hash cipher.SHA256, err error)
+
+// a registered transformer
+type transform struct {
+	to SchemaRef     // new Schema
+	fn TransformFunc // converter
+}
+
+// AddTransform registers transformer from old Schema
+// (of another Registry) to Schema of this Registry.
+// The AddTransform is not thread safe and should be
+// called before the Registry used. It panics if the fn
+// is nil or the Registry doesn't have Schema of the to
+// reference. See also Transform
+func (r *Registry) AddTransform(from, to SchemaRef, fn TransformFunc) {
+
+	if fn == nil {
+		panic("nil TransformFunc")
+	}
+
+	if _, ok := r.srf[to]; ok == false {
+		panic(fmt.Sprintf("AddTransform to missing schema %q", to.String()))
+	}
+
+	if r.tr == nil {
+		r.tr = make(map[SchemaRef]transform)
+	}
+
+	r.tr[from] = transform{to, fn}
+}
+
+// Transform given Value of old Schema to this Registry
+// saving result to given Pack. The pack should use this
+// Registry. If a transformer registered for Schema of the
+// Value, then it's used. Otherwise, if this Registry has
+// the same Schema, then the Value saved as is. Otherwise
+// error that wraps ErrMissingTransform returned. The
+// Transform converts given Value only, objects it refers
+// to are up to transformer registered
+func (r *Registry) Transform(
+	v Value, //       : value of old Schema
+	pack Pack, //     : pack to save result
+) (
+	dr Dynamic, //    : reference to result
+	err error, //     : an error
+) {
+
+	var sr = v.Schema().Reference()
+
+	if tr, ok := r.tr[sr]; ok == true {
+		if dr.Hash, err = tr.fn(v, pack); err != nil {
+			return
+		}
+		dr.Schema = tr.to
+		return
+	}
+
+	if _, ok := r.srf[sr]; ok == false {
+		err = fmt.Errorf("%w from %q", ErrMissingTransform, v.Schema().Name())
+		return
+	}
+
+	if dr.Hash, err = pack.Add(v.Encoded()); err != nil {
+		return
+	}
+
+	dr.Schema = sr
+	return
+}
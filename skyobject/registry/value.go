@@ -0,0 +1,665 @@
+package registry
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A Value represents encoded value with its Schema.
+// The Value is used to explore objects without Go
+// types (e.g. by a generic viewer or frontend). The
+// Pack is used to get objects the references of the
+// Value refer to. Since the Value is a view of encoded
+// data, it can't be used to change the data
+type Value struct {
+	pack Pack          // to dereference
+	s    Schema        // schema of the value
+	p    []byte        // encoded value
+	fo   *fieldOffsets // offsets of fields of struct or nil
+}
+
+// offsets of fields of struct Value, built once
+// by first access to a field and shared between
+// copies of the Value
+type fieldOffsets struct {
+	once sync.Once
+	offs []int // offset of every field
+	err  error // malformed data
+}
+
+// NewValue creates Value by given Schema and encoded
+// data. The Pack is used to dereference references
+func NewValue(
+	pack Pack, //    : pack to get objects
+	s Schema, //     : schema of the value
+	p []byte, //     : encoded value
+) (
+	v Value, //      : the Value
+	err error, //    : invalid schema or data
+) {
+
+	if s == nil {
+		err = ErrInvalidSchema
+		return
+	}
+
+	var n int
+	if n, err = s.Size(p); err != nil {
+		return
+	}
+
+	v.pack, v.s, v.p = pack, s, p[:n]
+
+	if s.Kind() == reflect.Struct {
+		v.fo = new(fieldOffsets)
+	}
+
+	return
+}
+
+// DynamicValue returns Value of object
+// given Dynamic refers to
+func DynamicValue(
+	pack Pack, //    : pack to get objects
+	dr Dynamic, //   : the Dynamic
+) (
+	v Value, //      : Value of the object
+	err error, //    : an error
+) {
+
+	if dr.IsValid() == false {
+		err = ErrInvalidDynamicReference
+		return
+	}
+
+	if dr.IsBlank() == true || dr.Hash == (cipher.SHA256{}) {
+		err = ErrReferenceRepresentsNil
+		return
+	}
+
+	var s Schema
+	if s, err = pack.Registry().SchemaByReference(dr.Schema); err != nil {
+		return
+	}
+
+	return valueByHash(pack, s, dr.Hash)
+}
+
+// get object by hash, the val can be older then its
+// schema, and default values are applied in this case
+func valueByHash(
+	pack Pack, //          :
+	s Schema, //           :
+	hash cipher.SHA256, // :
+) (
+	v Value, //            :
+	err error, //          :
+) {
+
+	var val []byte
+	if val, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	if hasDefaults(s) == true {
+		if val, err = ApplyDefaults(s, val); err != nil {
+			return
+		}
+	}
+
+	return NewValue(pack, s, val)
+}
+
+// Schema of the Value
+func (v Value) Schema() Schema {
+	return v.s
+}
+
+// Kind of the Value. It's reflect.Ptr for Ref and
+// Refs, and reflect.Interface for Dynamic
+func (v Value) Kind() reflect.Kind {
+	if v.s == nil {
+		return reflect.Invalid
+	}
+	return v.s.Kind()
+}
+
+// Encoded returns encoded value
+func (v Value) Encoded() []byte {
+	return v.p
+}
+
+// IsNil returns true if the Value is a reference
+// (Ref, Refs or Dynamic) that represents nil
+func (v Value) IsNil() bool {
+	if v.s == nil || v.s.IsReference() == false {
+		return false
+	}
+	for _, b := range v.p[:len(cipher.SHA256{})] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// check kind of the Value
+func (v Value) kind(kinds ...reflect.Kind) (err error) {
+	if v.s == nil || v.s.IsReference() == true {
+		return ErrInvalidValueKind
+	}
+	for _, k := range kinds {
+		if v.s.Kind() == k {
+			return
+		}
+	}
+	return ErrInvalidValueKind
+}
+
+// Bool returns value of bool
+func (v Value) Bool() (b bool, err error) {
+	if err = v.kind(reflect.Bool); err != nil {
+		return
+	}
+	return v.p[0] != 0, nil
+}
+
+// Int returns value of int8, int16,
+// int32 or int64 as int64
+func (v Value) Int() (i int64, err error) {
+
+	if err = v.kind(reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64); err != nil {
+
+		return
+	}
+
+	switch v.s.Kind() {
+	case reflect.Int8:
+		i = int64(int8(v.p[0]))
+	case reflect.Int16:
+		i = int64(int16(binary.LittleEndian.Uint16(v.p)))
+	case reflect.Int32:
+		i = int64(int32(binary.LittleEndian.Uint32(v.p)))
+	case reflect.Int64:
+		i = int64(binary.LittleEndian.Uint64(v.p))
+	}
+
+	return
+}
+
+// Uint returns value of uint8, uint16,
+// uint32 or uint64 as uint64
+func (v Value) Uint() (u uint64, err error) {
+
+	if err = v.kind(reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64); err != nil {
+
+		return
+	}
+
+	switch v.s.Kind() {
+	case reflect.Uint8:
+		u = uint64(v.p[0])
+	case reflect.Uint16:
+		u = uint64(binary.LittleEndian.Uint16(v.p))
+	case reflect.Uint32:
+		u = uint64(binary.LittleEndian.Uint32(v.p))
+	case reflect.Uint64:
+		u = binary.LittleEndian.Uint64(v.p)
+	}
+
+	return
+}
+
+// Float returns value of float32
+// or float64 as float64
+func (v Value) Float() (f float64, err error) {
+
+	if err = v.kind(reflect.Float32, reflect.Float64); err != nil {
+		return
+	}
+
+	if v.s.Kind() == reflect.Float32 {
+		f = float64(math.Float32frombits(binary.LittleEndian.Uint32(v.p)))
+		return
+	}
+
+	f = math.Float64frombits(binary.LittleEndian.Uint64(v.p))
+	return
+}
+
+// String returns value of string
+func (v Value) String() (s string, err error) {
+	if err = v.kind(reflect.String); err != nil {
+		return
+	}
+	return string(v.p[4:]), nil // skip encoded length
+}
+
+// Len returns length of array, slice, string or Refs
+func (v Value) Len() (ln int, err error) {
+
+	if v.s != nil && v.s.ReferenceType() == ReferenceTypeSlice {
+		var refs Refs
+		if refs, err = v.refs(); err != nil {
+			return
+		}
+		return refs.Len(v.pack)
+	}
+
+	if err = v.kind(reflect.Array, reflect.Slice, reflect.String); err != nil {
+		return
+	}
+
+	if v.s.Kind() == reflect.Array {
+		return v.s.Len(), nil
+	}
+
+	return DefaultLimits.length(v.p)
+}
+
+func (v Value) refs() (refs Refs, err error) {
+	err = encoder.DeserializeRaw(v.p, &refs)
+	return
+}
+
+// Index returns element of array, slice or Refs by index.
+// An element of Refs is dereferenced
+func (v Value) Index(i int) (ev Value, err error) {
+
+	if v.s != nil && v.s.ReferenceType() == ReferenceTypeSlice {
+
+		var refs Refs
+		if refs, err = v.refs(); err != nil {
+			return
+		}
+
+		var hash cipher.SHA256
+		if hash, err = refs.HashByIndex(v.pack, i); err != nil {
+			return
+		}
+
+		if hash == (cipher.SHA256{}) {
+			err = ErrRefsElementIsNil
+			return
+		}
+
+		return valueByHash(v.pack, v.s.Elem(), hash)
+	}
+
+	var shift int
+	if shift, err = v.elemShift(i); err != nil {
+		return
+	}
+
+	return NewValue(v.pack, v.s.Elem(), v.p[shift:])
+}
+
+// shift of element of array or slice
+func (v Value) elemShift(i int) (shift int, err error) {
+
+	if err = v.kind(reflect.Array, reflect.Slice); err != nil {
+		return
+	}
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	if i < 0 || i >= ln {
+		err = ErrIndexOutOfRange
+		return
+	}
+
+	if v.s.Kind() == reflect.Slice {
+		shift = 4 // encoded length
+	}
+
+	var el = v.s.Elem()
+
+	if s := fixedSize(el.Kind()); s > 0 {
+		shift += s * i
+		return
+	}
+
+	var m int
+	for k := 0; k < i; k++ {
+		if m, err = el.Size(v.p[shift:]); err != nil {
+			return
+		}
+		shift += m
+	}
+
+	return
+}
+
+// FieldByIndex returns field of struct by index
+func (v Value) FieldByIndex(i int) (fv Value, err error) {
+
+	var shift int
+	if shift, err = v.fieldShift(i); err != nil {
+		return
+	}
+
+	return NewValue(v.pack, v.s.Fields()[i].Schema(), v.p[shift:])
+}
+
+// shift of field of struct
+func (v Value) fieldShift(i int) (shift int, err error) {
+
+	if err = v.kind(reflect.Struct); err != nil {
+		return
+	}
+
+	var fs = v.s.Fields()
+
+	if i < 0 || i >= len(fs) {
+		err = ErrIndexOutOfRange
+		return
+	}
+
+	if v.fo == nil {
+		return fieldShift(fs, v.p, i) // not cached
+	}
+
+	v.fo.once.Do(func() {
+		v.fo.offs, v.fo.err = fieldOffsetsOf(fs, v.p)
+	})
+
+	if v.fo.err != nil {
+		return 0, v.fo.err
+	}
+
+	return v.fo.offs[i], nil
+}
+
+// shift of i-th field of encoded struct
+func fieldShift(fs []Field, p []byte, i int) (shift int, err error) {
+
+	var m int
+
+	for k := 0; k < i; k++ {
+		if m, err = fs[k].Schema().Size(p[shift:]); err != nil {
+			return
+		}
+		shift += m
+	}
+
+	return
+}
+
+// offsets of all fields of encoded struct
+func fieldOffsetsOf(fs []Field, p []byte) (offs []int, err error) {
+
+	offs = make([]int, len(fs))
+
+	var shift, m int
+
+	for k, f := range fs {
+
+		offs[k] = shift
+
+		if k == len(fs)-1 {
+			break // size of the last field is not needed
+		}
+
+		if m, err = f.Schema().Size(p[shift:]); err != nil {
+			return nil, err
+		}
+		shift += m
+	}
+
+	return
+}
+
+// DecodeField returns encoded field with given name of
+// encoded struct of given Schema. Use it to read a field
+// once, without creating a Value. Use NewValue with the
+// Schema of the field to explore the field, or decode
+// it using encoder.DeserializeRaw
+func DecodeField(
+	p []byte, //         : encoded struct
+	s Schema, //         : schema of the struct
+	fieldName string, // : name of field
+) (
+	fp []byte, //        : encoded field
+	err error, //        : an error
+) {
+
+	if s == nil {
+		return nil, ErrInvalidSchema
+	}
+
+	if s.Kind() != reflect.Struct {
+		return nil, ErrInvalidValueKind
+	}
+
+	var fs = s.Fields()
+
+	for i, f := range fs {
+
+		if f.Name() != fieldName {
+			continue
+		}
+
+		var shift int
+		if shift, err = fieldShift(fs, p, i); err != nil {
+			return
+		}
+
+		var n int
+		if n, err = f.Schema().Size(p[shift:]); err != nil {
+			return
+		}
+
+		return p[shift : shift+n], nil
+	}
+
+	return nil, ErrNoSuchField
+}
+
+// FieldByName returns field of struct by name
+func (v Value) FieldByName(name string) (fv Value, err error) {
+
+	if err = v.kind(reflect.Struct); err != nil {
+		return
+	}
+
+	for i, f := range v.s.Fields() {
+		if f.Name() == name {
+			return v.FieldByIndex(i)
+		}
+	}
+
+	err = ErrNoSuchField
+	return
+}
+
+// Dereference returns Value the Ref or Dynamic refers
+// to. It returns ErrReferenceRepresentsNil if the Ref
+// or Dynamic is blank. Use Index to get elements of Refs.
+// Use Resolver to memoize objects and to walk references
+// with cycle detection
+func (v Value) Dereference() (dv Value, err error) {
+
+	if v.s == nil {
+		err = ErrInvalidValueKind
+		return
+	}
+
+	switch v.s.ReferenceType() {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if err = encoder.DeserializeRaw(v.p, &ref); err != nil {
+			return
+		}
+
+		if ref.IsBlank() == true {
+			err = ErrReferenceRepresentsNil
+			return
+		}
+
+		return valueByHash(v.pack, v.s.Elem(), ref.Hash)
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if err = encoder.DeserializeRaw(v.p, &dr); err != nil {
+			return
+		}
+
+		return DynamicValue(v.pack, dr)
+
+	}
+
+	err = ErrInvalidValueKind
+	return
+}
+
+// dereference Ref and Dynamic (not Refs), while
+// the Value is Ref or Dynamic
+func (v Value) dereferenceAll() (dv Value, err error) {
+
+	for dv = v; dv.isRefOrDynamic() == true; {
+		if dv, err = dv.Dereference(); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (v Value) isRefOrDynamic() bool {
+	if v.s == nil {
+		return false
+	}
+	var rt = v.s.ReferenceType()
+	return rt == ReferenceTypeSingle || rt == ReferenceTypeDynamic
+}
+
+// A PathError returned by the ByPath method of
+// Value and describes failed element of a path
+type PathError struct {
+	Path string // the path
+	Elem string // failed element of the path
+	Err  error  // the reason
+}
+
+// Error implements error interface
+func (p *PathError) Error() string {
+	return "path " + strconv.Quote(p.Path) + ": " + p.Elem + ": " +
+		p.Err.Error()
+}
+
+// an element of a path, name of field or index
+type pathElem struct {
+	name  string // name of field or empty if it's index
+	index int    // index
+}
+
+func (p pathElem) String() string {
+	if p.name != "" {
+		return "." + p.name
+	}
+	return "[" + strconv.Itoa(p.index) + "]"
+}
+
+// parse path, the path is fields separated by dots,
+// every field can be followed by indices; the path
+// can start with an index; for example
+//
+//     Board.Threads[3].Posts[0][1].Title
+//     [2].Name
+//
+func parsePath(path string) (es []pathElem, err error) {
+
+	for k, part := range strings.Split(path, ".") {
+
+		var name = part
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, part = part[:i], part[i:]
+		} else {
+			part = ""
+		}
+
+		if name != "" {
+			es = append(es, pathElem{name: name})
+		} else if k > 0 || part == "" {
+			return nil, ErrInvalidPath // empty field name
+		}
+
+		for part != "" {
+
+			var j = strings.IndexByte(part, ']')
+
+			if part[0] != '[' || j < 0 {
+				return nil, ErrInvalidPath
+			}
+
+			var index int
+			if index, err = strconv.Atoi(part[1:j]); err != nil || index < 0 {
+				return nil, ErrInvalidPath
+			}
+
+			es = append(es, pathElem{index: index})
+			part = part[j+1:]
+		}
+
+	}
+
+	return
+}
+
+// ByPath returns Value by given path. The path is
+// names of fields separated by dots. A name can be
+// followed by indices in square brackets. Ref and
+// Dynamic references are dereferenced automatically.
+// Elements of Refs are dereferenced too. For example
+//
+//     thread, err := board.ByPath("Threads[3]")
+//     title, err := board.ByPath("Threads[3].Posts[0].Title")
+//
+// Empty path means the Value itself. The ByPath
+// returns *PathError
+func (v Value) ByPath(path string) (pv Value, err error) {
+
+	if path == "" {
+		return v, nil
+	}
+
+	var es []pathElem
+	if es, err = parsePath(path); err != nil {
+		return pv, &PathError{path, path, err}
+	}
+
+	pv = v
+
+	for _, e := range es {
+
+		if pv, err = pv.dereferenceAll(); err == nil {
+			if e.name != "" {
+				pv, err = pv.FieldByName(e.name)
+			} else {
+				pv, err = pv.Index(e.index)
+			}
+		}
+
+		if err != nil {
+			return Value{}, &PathError{path, e.String(), err}
+		}
+
+	}
+
+	return
+}
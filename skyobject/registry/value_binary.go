@@ -0,0 +1,302 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A binaryWriter represents self-describing binary
+// format (CBOR or MessagePack) used to export values
+type binaryWriter interface {
+	writeNil()
+	writeBool(b bool)
+	writeInt(i int64)
+	writeUint(u uint64)
+	writeFloat32(f float32)
+	writeFloat64(f float64)
+	writeString(s string)
+	writeBytes(p []byte)
+	writeArrayHeader(n int)
+	writeMapHeader(n int)
+
+	// reference that is not inlined, the p is
+	// hash for Ref and Refs, and encoded Dynamic
+	// (hash of object and reference to schema)
+	writeReference(rt ReferenceType, p []byte)
+}
+
+// write given Value, the depth is the same as
+// for the ValueToJSON
+func writeBinary(w binaryWriter, v Value, depth int) (err error) {
+
+	if v.s == nil {
+		return ErrInvalidValueKind
+	}
+
+	switch v.s.ReferenceType() {
+	case ReferenceTypeSingle, ReferenceTypeDynamic:
+		return writeBinaryReference(w, v, depth)
+	case ReferenceTypeSlice:
+		return writeBinaryRefs(w, v, depth)
+	}
+
+	if v.s.CryptoType() != CryptoTypeNone {
+		w.writeBytes(v.p)
+		return
+	}
+
+	switch v.s.Kind() {
+
+	case reflect.Bool:
+
+		var b bool
+		if b, err = v.Bool(); err == nil {
+			w.writeBool(b)
+		}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		var i int64
+		if i, err = v.Int(); err == nil {
+			w.writeInt(i)
+		}
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		var u uint64
+		if u, err = v.Uint(); err == nil {
+			w.writeUint(u)
+		}
+
+	case reflect.Float32:
+
+		var f float64
+		if f, err = v.Float(); err == nil {
+			w.writeFloat32(float32(f))
+		}
+
+	case reflect.Float64:
+
+		var f float64
+		if f, err = v.Float(); err == nil {
+			w.writeFloat64(f)
+		}
+
+	case reflect.String:
+
+		var s string
+		if s, err = v.String(); err == nil {
+			w.writeString(s)
+		}
+
+	case reflect.Array, reflect.Slice:
+
+		err = writeBinaryArray(w, v, depth)
+
+	case reflect.Struct:
+
+		err = writeBinaryStruct(w, v, depth)
+
+	default:
+
+		err = ErrInvalidValueKind
+
+	}
+
+	return
+}
+
+func writeBinaryArray(w binaryWriter, v Value, depth int) (err error) {
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	var shift int
+	if v.s.Kind() == reflect.Slice {
+		shift = 4 // encoded length
+	}
+
+	var el = v.s.Elem()
+
+	// []byte and [n]byte
+	if el.Kind() == reflect.Uint8 {
+		w.writeBytes(v.p[shift : shift+ln])
+		return
+	}
+
+	w.writeArrayHeader(ln)
+
+	var ev Value
+
+	for i := 0; i < ln; i++ {
+
+		if ev, err = NewValue(v.pack, el, v.p[shift:]); err != nil {
+			return
+		}
+
+		if err = writeBinary(w, ev, depth); err != nil {
+			return
+		}
+
+		shift += len(ev.p)
+	}
+
+	return
+}
+
+func writeBinaryStruct(w binaryWriter, v Value, depth int) (err error) {
+
+	var (
+		fs    = v.s.Fields()
+		shift int
+		fv    Value
+	)
+
+	w.writeMapHeader(len(fs))
+
+	for _, f := range fs {
+
+		w.writeString(f.Name())
+
+		if fv, err = NewValue(v.pack, f.Schema(), v.p[shift:]); err != nil {
+			return
+		}
+
+		if err = writeBinary(w, fv, depth); err != nil {
+			return
+		}
+
+		shift += len(fv.p)
+	}
+
+	return
+}
+
+// Ref or Dynamic
+func writeBinaryReference(w binaryWriter, v Value, depth int) (err error) {
+
+	if v.IsNil() == true {
+		w.writeNil()
+		return
+	}
+
+	if depth != 0 {
+
+		var dv Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return writeBinary(w, dv, depth-1)
+	}
+
+	var rt = v.s.ReferenceType()
+
+	if rt == ReferenceTypeSingle {
+		w.writeReference(rt, v.p[:len(cipher.SHA256{})])
+		return
+	}
+
+	w.writeReference(rt, v.p[:len(cipher.SHA256{})+len(SchemaRef{})])
+	return
+}
+
+func writeBinaryRefs(w binaryWriter, v Value, depth int) (err error) {
+
+	if depth == 0 {
+
+		if v.IsNil() == true {
+			w.writeNil()
+			return
+		}
+
+		w.writeReference(ReferenceTypeSlice, v.p[:len(cipher.SHA256{})])
+		return
+	}
+
+	var refs Refs
+	if refs, err = v.refs(); err != nil {
+		return
+	}
+
+	var ln int
+	if ln, err = refs.Len(v.pack); err != nil {
+		return
+	}
+
+	w.writeArrayHeader(ln)
+
+	return refs.Ascend(v.pack, func(i int, hash cipher.SHA256) (err error) {
+
+		if hash == (cipher.SHA256{}) {
+			w.writeNil()
+			return
+		}
+
+		var ev Value
+		if ev, err = valueByHash(v.pack, v.s.Elem(), hash); err != nil {
+			return
+		}
+
+		return writeBinary(w, ev, depth-1)
+	})
+}
+
+// schema of Dynamic
+var dynamicSchema Schema = &referenceSchema{
+	schema: schema{kind: reflect.Interface},
+	typ:    ReferenceTypeDynamic,
+}
+
+// write given Root as map, the Refs of the Root
+// are written as Dynamic references
+func writeBinaryRoot(
+	w binaryWriter, // : the writer
+	pack Pack, //      : pack to get objects
+	r *Root, //        : the Root
+	depth int, //      : depth of references to inline
+) (
+	err error, //      : an error
+) {
+
+	w.writeMapHeader(10)
+
+	w.writeString("Refs")
+	w.writeArrayHeader(len(r.Refs))
+
+	for _, dr := range r.Refs {
+
+		var v Value
+		v.pack, v.s, v.p = pack, dynamicSchema, encoder.Serialize(dr)
+
+		if err = writeBinary(w, v, depth); err != nil {
+			return
+		}
+
+	}
+
+	w.writeString("Descriptor")
+	w.writeBytes(r.Descriptor)
+	w.writeString("Reg")
+	w.writeBytes(r.Reg[:])
+	w.writeString("Pub")
+	w.writeBytes(r.Pub[:])
+	w.writeString("Nonce")
+	w.writeUint(r.Nonce)
+	w.writeString("Seq")
+	w.writeUint(r.Seq)
+	w.writeString("Time")
+	w.writeInt(r.Time)
+	w.writeString("Sig")
+	w.writeBytes(r.Sig[:])
+	w.writeString("Hash")
+	w.writeBytes(r.Hash[:])
+	w.writeString("Prev")
+	w.writeBytes(r.Prev[:])
+
+	return
+}
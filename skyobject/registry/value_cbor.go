@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// CBOR tags of references that are not inlined
+// (see ValueToCBOR). The tags are from "first come
+// first served" range and are not registered
+const (
+	CBORTagRef     uint64 = 40401 // hash of Ref
+	CBORTagRefs    uint64 = 40402 // hash of Refs
+	CBORTagDynamic uint64 = 40403 // encoded Dynamic
+)
+
+// ValueToCBOR encodes given Value to CBOR (RFC 7049).
+// It allows to consume CXO objects by tools that don't
+// implement the skycoin encoder. Mapping is
+//
+//     bool                 -> true or false
+//     intX                 -> integer
+//     uintX                -> unsigned integer
+//     float32, float64     -> float of the same size
+//     string               -> text string
+//     []byte and [n]byte   -> byte string
+//     cipher.PubKey        -> byte string (33 bytes)
+//     cipher.SHA256        -> byte string (32 bytes)
+//     cipher.Sig           -> byte string (65 bytes)
+//     arrays and slices    -> array
+//     structures           -> map of fields in order of Schema
+//
+// References are inlined up to given depth. Use zero
+// depth to don't inline references and negative depth
+// to inline all references. An inlined Refs is array of
+// its elements. A reference that represents nil is null.
+// A reference that is not inlined is tagged byte string
+//
+//     Ref     -> tag CBORTagRef, 32 bytes of the hash
+//     Refs    -> tag CBORTagRefs, 32 bytes of the hash
+//     Dynamic -> tag CBORTagDynamic, 64 bytes: hash of
+//                object and reference to schema
+//
+func ValueToCBOR(v Value, depth int) (p []byte, err error) {
+
+	var w cborWriter
+
+	if err = writeBinary(&w, v, depth); err != nil {
+		return
+	}
+
+	return w.Bytes(), nil
+}
+
+// RootToCBOR encodes given Root to CBOR map with
+// fields of the Root as keys. The Refs is array of
+// Dynamic references. Other fields are integers and
+// byte strings. Use given pack to get objects if
+// the depth is not zero. See ValueToCBOR for details
+func RootToCBOR(pack Pack, r *Root, depth int) (p []byte, err error) {
+
+	var w cborWriter
+
+	if err = writeBinaryRoot(&w, pack, r, depth); err != nil {
+		return
+	}
+
+	return w.Bytes(), nil
+}
+
+// CBOR major types
+const (
+	cborUint   byte = 0 << 5
+	cborNegInt byte = 1 << 5
+	cborBytes  byte = 2 << 5
+	cborString byte = 3 << 5
+	cborArray  byte = 4 << 5
+	cborMap    byte = 5 << 5
+	cborTag    byte = 6 << 5
+	cborSimple byte = 7 << 5
+)
+
+type cborWriter struct {
+	bytes.Buffer
+}
+
+func (c *cborWriter) writeHead(major byte, n uint64) {
+
+	var p [9]byte
+
+	switch {
+	case n < 24:
+		c.WriteByte(major | byte(n))
+		return
+	case n <= math.MaxUint8:
+		p[0], p[1] = major|24, byte(n)
+		c.Write(p[:2])
+	case n <= math.MaxUint16:
+		p[0] = major | 25
+		binary.BigEndian.PutUint16(p[1:], uint16(n))
+		c.Write(p[:3])
+	case n <= math.MaxUint32:
+		p[0] = major | 26
+		binary.BigEndian.PutUint32(p[1:], uint32(n))
+		c.Write(p[:5])
+	default:
+		p[0] = major | 27
+		binary.BigEndian.PutUint64(p[1:], n)
+		c.Write(p[:9])
+	}
+
+}
+
+func (c *cborWriter) writeNil() {
+	c.WriteByte(cborSimple | 22)
+}
+
+func (c *cborWriter) writeBool(b bool) {
+	if b == true {
+		c.WriteByte(cborSimple | 21)
+		return
+	}
+	c.WriteByte(cborSimple | 20)
+}
+
+func (c *cborWriter) writeInt(i int64) {
+	if i >= 0 {
+		c.writeHead(cborUint, uint64(i))
+		return
+	}
+	c.writeHead(cborNegInt, uint64(-(i + 1)))
+}
+
+func (c *cborWriter) writeUint(u uint64) {
+	c.writeHead(cborUint, u)
+}
+
+func (c *cborWriter) writeFloat32(f float32) {
+	var p [5]byte
+	p[0] = cborSimple | 26
+	binary.BigEndian.PutUint32(p[1:], math.Float32bits(f))
+	c.Write(p[:])
+}
+
+func (c *cborWriter) writeFloat64(f float64) {
+	var p [9]byte
+	p[0] = cborSimple | 27
+	binary.BigEndian.PutUint64(p[1:], math.Float64bits(f))
+	c.Write(p[:])
+}
+
+func (c *cborWriter) writeString(s string) {
+	c.writeHead(cborString, uint64(len(s)))
+	c.WriteString(s)
+}
+
+func (c *cborWriter) writeBytes(p []byte) {
+	c.writeHead(cborBytes, uint64(len(p)))
+	c.Write(p)
+}
+
+func (c *cborWriter) writeArrayHeader(n int) {
+	c.writeHead(cborArray, uint64(n))
+}
+
+func (c *cborWriter) writeMapHeader(n int) {
+	c.writeHead(cborMap, uint64(n))
+}
+
+func (c *cborWriter) writeReference(rt ReferenceType, p []byte) {
+
+	switch rt {
+	case ReferenceTypeSingle:
+		c.writeHead(cborTag, CBORTagRef)
+	case ReferenceTypeSlice:
+		c.writeHead(cborTag, CBORTagRefs)
+	case ReferenceTypeDynamic:
+		c.writeHead(cborTag, CBORTagDynamic)
+	}
+
+	c.writeBytes(p)
+}
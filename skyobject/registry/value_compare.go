@@ -0,0 +1,257 @@
+package registry
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Equal returns true if the Value and given one have the
+// same Schema and the same content. References are equal
+// if they have the same hash (and the same Schema for
+// Dynamic). See Compare for details. It returns false
+// if the Values have different Schemas
+func (v Value) Equal(x Value) (eq bool, err error) {
+
+	var cmp int
+	if cmp, err = v.Compare(x); err == ErrNotComparable {
+		return false, nil
+	} else if err != nil {
+		return
+	}
+
+	return cmp == 0, nil
+}
+
+// Compare the Value with given one. The Compare returns
+// 0 if the Values are equal, -1 if the Value is less and
+// +1 if the Value is greater. The Values should have the
+// same Schema, otherwise ErrNotComparable returned. Values
+// are compared by decoded content. Numbers are compared
+// numerically, and a NaN is equal to NaN and less than any
+// other float. Strings are compared lexicographically.
+// Arrays and slices compared element by element, and a
+// shorter one is less if it's a prefix of longer one.
+// Structures compared field by field in order of the
+// Schema. References are compared by hash (and by Schema
+// for Dynamic), without dereferencing
+func (v Value) Compare(x Value) (cmp int, err error) {
+
+	if v.s == nil || x.s == nil {
+		return 0, ErrInvalidValueKind
+	}
+
+	if sameSchema(v.s, x.s) == false {
+		return 0, ErrNotComparable
+	}
+
+	if v.s.IsReference() == true {
+		return bytes.Compare(v.p, x.p), nil // Hash first
+	}
+
+	if v.s.CryptoType() != CryptoTypeNone {
+		return bytes.Compare(v.p, x.p), nil // byte arrays
+	}
+
+	switch v.s.Kind() {
+
+	case reflect.Bool:
+
+		var a, b bool
+		if a, err = v.Bool(); err != nil {
+			return
+		}
+		if b, err = x.Bool(); err != nil {
+			return
+		}
+
+		switch {
+		case a == b:
+		case a == false:
+			cmp = -1
+		default:
+			cmp = 1
+		}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		var a, b int64
+		if a, err = v.Int(); err != nil {
+			return
+		}
+		if b, err = x.Int(); err != nil {
+			return
+		}
+
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		}
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		var a, b uint64
+		if a, err = v.Uint(); err != nil {
+			return
+		}
+		if b, err = x.Uint(); err != nil {
+			return
+		}
+
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		}
+
+	case reflect.Float32, reflect.Float64:
+
+		var a, b float64
+		if a, err = v.Float(); err != nil {
+			return
+		}
+		if b, err = x.Float(); err != nil {
+			return
+		}
+
+		cmp = compareFloats(a, b)
+
+	case reflect.String:
+
+		var a, b string
+		if a, err = v.String(); err != nil {
+			return
+		}
+		if b, err = x.String(); err != nil {
+			return
+		}
+
+		cmp = strings.Compare(a, b)
+
+	case reflect.Array, reflect.Slice:
+
+		cmp, err = v.compareElements(x)
+
+	case reflect.Struct:
+
+		cmp, err = v.compareFields(x)
+
+	default:
+
+		err = ErrInvalidValueKind
+
+	}
+
+	return
+}
+
+// NaN is equal to NaN and less than any other float
+func compareFloats(a, b float64) int {
+
+	switch an, bn := math.IsNaN(a), math.IsNaN(b); {
+	case an == true && bn == true:
+		return 0
+	case an == true:
+		return -1
+	case bn == true:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0 // including -0 == +0
+}
+
+func (v Value) compareElements(x Value) (cmp int, err error) {
+
+	var vl, xl int
+
+	if vl, err = v.Len(); err != nil {
+		return
+	}
+
+	if xl, err = x.Len(); err != nil {
+		return
+	}
+
+	var (
+		shift int
+		ve    Value
+		xe    Value
+	)
+
+	if v.s.Kind() == reflect.Slice {
+		shift = 4 // encoded length
+	}
+
+	var vs, xs = shift, shift
+
+	for i := 0; i < vl && i < xl; i++ {
+
+		if ve, err = NewValue(v.pack, v.s.Elem(), v.p[vs:]); err != nil {
+			return
+		}
+
+		if xe, err = NewValue(x.pack, x.s.Elem(), x.p[xs:]); err != nil {
+			return
+		}
+
+		if cmp, err = ve.Compare(xe); err != nil || cmp != 0 {
+			return
+		}
+
+		vs, xs = vs+len(ve.p), xs+len(xe.p)
+	}
+
+	switch {
+	case vl < xl:
+		cmp = -1
+	case vl > xl:
+		cmp = 1
+	}
+
+	return
+}
+
+func (v Value) compareFields(x Value) (cmp int, err error) {
+
+	var vf, xf = v.s.Fields(), x.s.Fields()
+
+	if len(vf) != len(xf) {
+		return 0, ErrNotComparable
+	}
+
+	var (
+		vs, xs int
+		fv, fx Value
+	)
+
+	for i, f := range vf {
+
+		if f.Name() != xf[i].Name() {
+			return 0, ErrNotComparable
+		}
+
+		if fv, err = NewValue(v.pack, f.Schema(), v.p[vs:]); err != nil {
+			return
+		}
+
+		if fx, err = NewValue(x.pack, xf[i].Schema(), x.p[xs:]); err != nil {
+			return
+		}
+
+		if cmp, err = fv.Compare(fx); err != nil || cmp != 0 {
+			return
+		}
+
+		vs, xs = vs+len(fv.p), xs+len(fx.p)
+	}
+
+	return
+}
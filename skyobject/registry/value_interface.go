@@ -0,0 +1,238 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// Interface returns the Value as native Go value.
+// References are not followed. See InterfaceDepth
+// for details
+func (v Value) Interface() (i interface{}, err error) {
+	return v.InterfaceDepth(0)
+}
+
+// InterfaceDepth returns the Value as native Go value
+// chosen by kind of its Schema
+//
+//     bool                       -> bool
+//     intX                       -> int64
+//     uintX                      -> uint64
+//     float32, float64           -> float64
+//     string                     -> string
+//     []byte, [n]byte            -> []byte
+//     cipher.PubKey, SHA256, Sig -> the same type
+//     arrays and slices          -> []interface{}
+//     structures                 -> map[string]interface{}
+//
+// References are followed up to given depth. A followed
+// Ref or Dynamic is the object, and a followed Refs is
+// []interface{} of its elements. Beyond the depth a Ref
+// and Refs are hashes (cipher.SHA256) and a Dynamic is
+// map[string]interface{} with "schema" (SchemaRef) and
+// "hash" (cipher.SHA256) keys. A reference that represents
+// nil is nil. Use zero depth to don't follow references
+// and negative depth to follow all references
+func (v Value) InterfaceDepth(depth int) (i interface{}, err error) {
+
+	if v.s == nil {
+		return nil, ErrInvalidValueKind
+	}
+
+	switch v.s.ReferenceType() {
+	case ReferenceTypeSingle, ReferenceTypeDynamic:
+		return v.referenceInterface(depth)
+	case ReferenceTypeSlice:
+		return v.refsInterface(depth)
+	}
+
+	switch v.s.CryptoType() {
+	case CryptoTypePubKey:
+		var pk cipher.PubKey
+		copy(pk[:], v.p)
+		return pk, nil
+	case CryptoTypeSHA256:
+		var hash cipher.SHA256
+		copy(hash[:], v.p)
+		return hash, nil
+	case CryptoTypeSig:
+		var sig cipher.Sig
+		copy(sig[:], v.p)
+		return sig, nil
+	}
+
+	switch v.s.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Array, reflect.Slice:
+		return v.arrayInterface(depth)
+	case reflect.Struct:
+		return v.structInterface(depth)
+	}
+
+	return nil, ErrInvalidValueKind
+}
+
+func (v Value) arrayInterface(depth int) (i interface{}, err error) {
+
+	var shift int
+	if v.s.Kind() == reflect.Slice {
+		shift = 4 // encoded length
+	}
+
+	var el = v.s.Elem()
+
+	if el.Kind() == reflect.Uint8 && el.IsRegistered() == false {
+		return append([]byte{}, v.p[shift:]...), nil
+	}
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	var (
+		is = make([]interface{}, 0, ln)
+		ev Value
+		ei interface{}
+	)
+
+	for j := 0; j < ln; j++ {
+
+		if ev, err = NewValue(v.pack, el, v.p[shift:]); err != nil {
+			return
+		}
+
+		if ei, err = ev.InterfaceDepth(depth); err != nil {
+			return
+		}
+
+		is = append(is, ei)
+		shift += len(ev.p)
+	}
+
+	return is, nil
+}
+
+func (v Value) structInterface(depth int) (i interface{}, err error) {
+
+	var (
+		fs    = v.s.Fields()
+		m     = make(map[string]interface{}, len(fs))
+		shift int
+		fv    Value
+	)
+
+	for _, f := range fs {
+
+		if fv, err = NewValue(v.pack, f.Schema(), v.p[shift:]); err != nil {
+			return
+		}
+
+		if m[f.Name()], err = fv.InterfaceDepth(depth); err != nil {
+			return
+		}
+
+		shift += len(fv.p)
+	}
+
+	return m, nil
+}
+
+// Ref or Dynamic
+func (v Value) referenceInterface(depth int) (i interface{}, err error) {
+
+	if v.IsNil() == true {
+		return
+	}
+
+	if depth != 0 {
+
+		var dv Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return dv.InterfaceDepth(depth - 1)
+	}
+
+	if v.s.ReferenceType() == ReferenceTypeSingle {
+		var hash cipher.SHA256
+		copy(hash[:], v.p)
+		return hash, nil
+	}
+
+	var dr Dynamic
+	if err = encoder.DeserializeRaw(v.p, &dr); err != nil {
+		return
+	}
+
+	return map[string]interface{}{
+		"schema": dr.Schema,
+		"hash":   dr.Hash,
+	}, nil
+}
+
+func (v Value) refsInterface(depth int) (i interface{}, err error) {
+
+	if v.IsNil() == true {
+		return
+	}
+
+	if depth == 0 {
+		var hash cipher.SHA256
+		copy(hash[:], v.p)
+		return hash, nil
+	}
+
+	var refs Refs
+	if refs, err = v.refs(); err != nil {
+		return
+	}
+
+	var is []interface{}
+
+	err = refs.Ascend(v.pack, func(_ int, hash cipher.SHA256) (err error) {
+
+		if hash == (cipher.SHA256{}) {
+			is = append(is, nil)
+			return
+		}
+
+		var (
+			ev Value
+			ei interface{}
+		)
+
+		if ev, err = valueByHash(v.pack, v.s.Elem(), hash); err != nil {
+			return
+		}
+
+		if ei, err = ev.InterfaceDepth(depth - 1); err != nil {
+			return
+		}
+
+		is = append(is, ei)
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	if is == nil {
+		is = []interface{}{} // blank, but not nil
+	}
+
+	return is, nil
+}
@@ -0,0 +1,295 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// MarshalJSON implements json.Marshaler interface.
+// References are rendered as hashes. See ValueToJSON
+// for details
+func (v Value) MarshalJSON() ([]byte, error) {
+	return ValueToJSON(v, 0)
+}
+
+// ValueToJSON renders given Value as JSON. Scalar
+// values are rendered directly, structures are rendered
+// as objects with fields in order of the Schema, arrays
+// and slices are rendered as arrays. The cipher.PubKey,
+// cipher.SHA256 and cipher.Sig are rendered as hex
+// strings. References are inlined up to given depth.
+// Beyond the depth a Ref and Refs are rendered as hex
+// encoded hashes and a Dynamic is rendered as object
+// {"schema": "...", "hash": "..."}. A reference that
+// represents nil is rendered as null. Use zero depth
+// to don't inline references and negative depth to
+// inline all references
+func ValueToJSON(v Value, depth int) (p []byte, err error) {
+
+	var buf bytes.Buffer
+
+	if err = v.writeJSON(&buf, depth); err != nil {
+		return
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) (err error) {
+
+	var p []byte
+	if p, err = json.Marshal(s); err != nil {
+		return
+	}
+
+	buf.Write(p)
+	return
+}
+
+func (v Value) writeJSON(buf *bytes.Buffer, depth int) (err error) {
+
+	if v.s == nil {
+		return ErrInvalidValueKind
+	}
+
+	switch v.s.ReferenceType() {
+	case ReferenceTypeSingle, ReferenceTypeDynamic:
+		return v.writeReferenceJSON(buf, depth)
+	case ReferenceTypeSlice:
+		return v.writeRefsJSON(buf, depth)
+	}
+
+	if v.s.CryptoType() != CryptoTypeNone {
+		return writeJSONString(buf, hex.EncodeToString(v.p))
+	}
+
+	switch v.s.Kind() {
+
+	case reflect.Bool:
+
+		var b bool
+		if b, err = v.Bool(); err == nil {
+			buf.WriteString(strconv.FormatBool(b))
+		}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		var i int64
+		if i, err = v.Int(); err == nil {
+			buf.WriteString(strconv.FormatInt(i, 10))
+		}
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		var u uint64
+		if u, err = v.Uint(); err == nil {
+			buf.WriteString(strconv.FormatUint(u, 10))
+		}
+
+	case reflect.Float32, reflect.Float64:
+
+		var (
+			f float64
+			p []byte
+		)
+
+		if f, err = v.Float(); err != nil {
+			return
+		}
+
+		if p, err = json.Marshal(f); err == nil { // NaN or Inf
+			buf.Write(p)
+		}
+
+	case reflect.String:
+
+		var s string
+		if s, err = v.String(); err == nil {
+			err = writeJSONString(buf, s)
+		}
+
+	case reflect.Array, reflect.Slice:
+
+		err = v.writeArrayJSON(buf, depth)
+
+	case reflect.Struct:
+
+		err = v.writeStructJSON(buf, depth)
+
+	default:
+
+		err = ErrInvalidValueKind
+
+	}
+
+	return
+}
+
+func (v Value) writeArrayJSON(buf *bytes.Buffer, depth int) (err error) {
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	var shift int
+	if v.s.Kind() == reflect.Slice {
+		shift = 4 // encoded length
+	}
+
+	var (
+		el = v.s.Elem()
+		ev Value
+	)
+
+	buf.WriteByte('[')
+
+	for i := 0; i < ln; i++ {
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if ev, err = NewValue(v.pack, el, v.p[shift:]); err != nil {
+			return
+		}
+
+		if err = ev.writeJSON(buf, depth); err != nil {
+			return
+		}
+
+		shift += len(ev.p)
+	}
+
+	buf.WriteByte(']')
+	return
+}
+
+func (v Value) writeStructJSON(buf *bytes.Buffer, depth int) (err error) {
+
+	var (
+		shift int
+		fv    Value
+	)
+
+	buf.WriteByte('{')
+
+	for i, f := range v.s.Fields() {
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if err = writeJSONString(buf, f.Name()); err != nil {
+			return
+		}
+
+		buf.WriteByte(':')
+
+		if fv, err = NewValue(v.pack, f.Schema(), v.p[shift:]); err != nil {
+			return
+		}
+
+		if err = fv.writeJSON(buf, depth); err != nil {
+			return
+		}
+
+		shift += len(fv.p)
+	}
+
+	buf.WriteByte('}')
+	return
+}
+
+// hash of Ref or Refs
+func (v Value) writeHashJSON(buf *bytes.Buffer) error {
+	return writeJSONString(buf, hex.EncodeToString(v.p[:len(cipher.SHA256{})]))
+}
+
+// Ref or Dynamic
+func (v Value) writeReferenceJSON(buf *bytes.Buffer, depth int) (err error) {
+
+	if v.IsNil() == true {
+		buf.WriteString("null")
+		return
+	}
+
+	if depth != 0 {
+
+		var dv Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return dv.writeJSON(buf, depth-1)
+	}
+
+	if v.s.ReferenceType() == ReferenceTypeSingle {
+		return v.writeHashJSON(buf)
+	}
+
+	var dr Dynamic
+	if err = encoder.DeserializeRaw(v.p, &dr); err != nil {
+		return
+	}
+
+	buf.WriteString(`{"schema":`)
+	writeJSONString(buf, dr.Schema.String())
+	buf.WriteString(`,"hash":`)
+	writeJSONString(buf, dr.Hash.Hex())
+	buf.WriteByte('}')
+
+	return
+}
+
+func (v Value) writeRefsJSON(buf *bytes.Buffer, depth int) (err error) {
+
+	if depth == 0 {
+
+		if v.IsNil() == true {
+			buf.WriteString("null")
+			return
+		}
+
+		return v.writeHashJSON(buf)
+	}
+
+	var refs Refs
+	if refs, err = v.refs(); err != nil {
+		return
+	}
+
+	buf.WriteByte('[')
+
+	err = refs.Ascend(v.pack, func(i int, hash cipher.SHA256) (err error) {
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if hash == (cipher.SHA256{}) {
+			buf.WriteString("null")
+			return
+		}
+
+		var ev Value
+		if ev, err = valueByHash(v.pack, v.s.Elem(), hash); err != nil {
+			return
+		}
+
+		return ev.writeJSON(buf, depth-1)
+	})
+
+	if err != nil {
+		return
+	}
+
+	buf.WriteByte(']')
+	return
+}
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// MessagePack extension types of references
+// that are not inlined (see ValueToMsgPack)
+const (
+	MsgPackExtRef     int8 = 1 // hash of Ref
+	MsgPackExtRefs    int8 = 2 // hash of Refs
+	MsgPackExtDynamic int8 = 3 // encoded Dynamic
+)
+
+// ValueToMsgPack encodes given Value to MessagePack.
+// The mapping is the same as for the ValueToCBOR, but
+// byte strings are bin, text strings are str, and a
+// reference that is not inlined is extension
+//
+//     Ref     -> ext MsgPackExtRef, 32 bytes of the hash
+//     Refs    -> ext MsgPackExtRefs, 32 bytes of the hash
+//     Dynamic -> ext MsgPackExtDynamic, 64 bytes: hash of
+//                object and reference to schema
+//
+func ValueToMsgPack(v Value, depth int) (p []byte, err error) {
+
+	var w msgpackWriter
+
+	if err = writeBinary(&w, v, depth); err != nil {
+		return
+	}
+
+	return w.Bytes(), nil
+}
+
+// RootToMsgPack encodes given Root to MessagePack.
+// See RootToCBOR and ValueToMsgPack for details
+func RootToMsgPack(pack Pack, r *Root, depth int) (p []byte, err error) {
+
+	var w msgpackWriter
+
+	if err = writeBinaryRoot(&w, pack, r, depth); err != nil {
+		return
+	}
+
+	return w.Bytes(), nil
+}
+
+type msgpackWriter struct {
+	bytes.Buffer
+}
+
+// write the code and big-endian n of given size
+func (m *msgpackWriter) writeCode(code byte, n uint64, size int) {
+
+	var p [9]byte
+	p[0] = code
+
+	switch size {
+	case 1:
+		p[1] = byte(n)
+	case 2:
+		binary.BigEndian.PutUint16(p[1:], uint16(n))
+	case 4:
+		binary.BigEndian.PutUint32(p[1:], uint32(n))
+	case 8:
+		binary.BigEndian.PutUint64(p[1:], n)
+	}
+
+	m.Write(p[:1+size])
+}
+
+// write header of str, bin, array or map
+func (m *msgpackWriter) writeHead(codes [3]byte, n int) {
+	switch {
+	case n <= math.MaxUint8 && codes[0] != 0:
+		m.writeCode(codes[0], uint64(n), 1)
+	case n <= math.MaxUint16:
+		m.writeCode(codes[1], uint64(n), 2)
+	default:
+		m.writeCode(codes[2], uint64(n), 4)
+	}
+}
+
+func (m *msgpackWriter) writeNil() {
+	m.WriteByte(0xc0)
+}
+
+func (m *msgpackWriter) writeBool(b bool) {
+	if b == true {
+		m.WriteByte(0xc3)
+		return
+	}
+	m.WriteByte(0xc2)
+}
+
+func (m *msgpackWriter) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		m.writeUint(uint64(i))
+	case i >= -32:
+		m.WriteByte(byte(int8(i))) // negative fixint
+	case i >= math.MinInt8:
+		m.writeCode(0xd0, uint64(i), 1)
+	case i >= math.MinInt16:
+		m.writeCode(0xd1, uint64(i), 2)
+	case i >= math.MinInt32:
+		m.writeCode(0xd2, uint64(i), 4)
+	default:
+		m.writeCode(0xd3, uint64(i), 8)
+	}
+}
+
+func (m *msgpackWriter) writeUint(u uint64) {
+	switch {
+	case u < 128:
+		m.WriteByte(byte(u)) // positive fixint
+	case u <= math.MaxUint8:
+		m.writeCode(0xcc, u, 1)
+	case u <= math.MaxUint16:
+		m.writeCode(0xcd, u, 2)
+	case u <= math.MaxUint32:
+		m.writeCode(0xce, u, 4)
+	default:
+		m.writeCode(0xcf, u, 8)
+	}
+}
+
+func (m *msgpackWriter) writeFloat32(f float32) {
+	m.writeCode(0xca, uint64(math.Float32bits(f)), 4)
+}
+
+func (m *msgpackWriter) writeFloat64(f float64) {
+	m.writeCode(0xcb, math.Float64bits(f), 8)
+}
+
+func (m *msgpackWriter) writeString(s string) {
+	if len(s) < 32 {
+		m.WriteByte(0xa0 | byte(len(s))) // fixstr
+	} else {
+		m.writeHead([3]byte{0xd9, 0xda, 0xdb}, len(s))
+	}
+	m.WriteString(s)
+}
+
+func (m *msgpackWriter) writeBytes(p []byte) {
+	m.writeHead([3]byte{0xc4, 0xc5, 0xc6}, len(p))
+	m.Write(p)
+}
+
+func (m *msgpackWriter) writeArrayHeader(n int) {
+	if n < 16 {
+		m.WriteByte(0x90 | byte(n)) // fixarray
+		return
+	}
+	m.writeHead([3]byte{0, 0xdc, 0xdd}, n)
+}
+
+func (m *msgpackWriter) writeMapHeader(n int) {
+	if n < 16 {
+		m.WriteByte(0x80 | byte(n)) // fixmap
+		return
+	}
+	m.writeHead([3]byte{0, 0xde, 0xdf}, n)
+}
+
+func (m *msgpackWriter) writeReference(rt ReferenceType, p []byte) {
+
+	var typ int8
+
+	switch rt {
+	case ReferenceTypeSingle:
+		typ = MsgPackExtRef
+	case ReferenceTypeSlice:
+		typ = MsgPackExtRefs
+	case ReferenceTypeDynamic:
+		typ = MsgPackExtDynamic
+	}
+
+	// ext 8, the p is 32 or 64 bytes long
+	m.writeCode(0xc7, uint64(len(p)), 1)
+	m.WriteByte(byte(typ))
+	m.Write(p)
+}
@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A RangeIndexFunc used to iterate over elements of array,
+// slice or Refs. The i is index of the element, and the ev
+// is the element. Return ErrStopRange to stop the iteration.
+// It's not an error, and it is not returned by the
+// RangeIndex methods. Any other error stops the iteration
+// and is returned
+type RangeIndexFunc func(i int, ev Value) (err error)
+
+// RangeIndex iterates over all elements of array, slice
+// or Refs ascending. Elements of Refs are dereferenced,
+// and nil elements of Refs are skipped
+func (v Value) RangeIndex(rangeFunc RangeIndexFunc) (err error) {
+	return v.RangeIndexFrom(0, 0, rangeFunc)
+}
+
+// RangeIndexFrom iterates over count elements of array,
+// slice or Refs ascending starting from element with
+// given index. Zero or negative count means all elements
+// to the end. If the start is out of range, then there
+// is nothing to iterate. Negative start is an error.
+// The RangeIndexFrom is fast way to get a page of Refs,
+// because it doesn't walk elements before the start.
+// See also RangeIndex
+func (v Value) RangeIndexFrom(
+	start int, //                : index of first element
+	count int, //                : max number of elements
+	rangeFunc RangeIndexFunc, // : the function
+) (
+	err error, //                : an error
+) {
+
+	if start < 0 {
+		return ErrIndexOutOfRange
+	}
+
+	if v.s != nil && v.s.ReferenceType() == ReferenceTypeSlice {
+		return v.rangeRefs(start, count, false, rangeFunc)
+	}
+
+	var ln int
+	if ln, err = v.arrayLen(); err != nil {
+		return
+	}
+
+	if start >= ln {
+		return // nothing to iterate
+	}
+
+	if count > 0 && start+count < ln {
+		ln = start + count
+	}
+
+	var shift int
+	if shift, err = v.elemShift(start); err != nil {
+		return
+	}
+
+	var (
+		el = v.s.Elem()
+		ev Value
+	)
+
+	for i := start; i < ln; i++ {
+
+		if ev, err = NewValue(v.pack, el, v.p[shift:]); err != nil {
+			return
+		}
+
+		if err = rangeFunc(i, ev); err == ErrStopRange {
+			return nil
+		} else if err != nil {
+			return
+		}
+
+		shift += len(ev.p)
+	}
+
+	return
+}
+
+// RangeIndexReverse iterates over all elements of array,
+// slice or Refs descending. See also RangeIndex
+func (v Value) RangeIndexReverse(rangeFunc RangeIndexFunc) (err error) {
+
+	if v.s != nil && v.s.ReferenceType() == ReferenceTypeSlice {
+		return v.rangeRefs(0, 0, true, rangeFunc)
+	}
+
+	var ln int
+	if ln, err = v.arrayLen(); err != nil {
+		return
+	}
+
+	// elements can have different sizes, thus
+	// we have to find all of them first
+
+	var (
+		el    = v.s.Elem()
+		evs   = make([]Value, 0, ln)
+		shift int
+		ev    Value
+	)
+
+	if v.s.Kind() == reflect.Slice {
+		shift = 4 // encoded length
+	}
+
+	for i := 0; i < ln; i++ {
+
+		if ev, err = NewValue(v.pack, el, v.p[shift:]); err != nil {
+			return
+		}
+
+		evs = append(evs, ev)
+		shift += len(ev.p)
+	}
+
+	for i := ln - 1; i >= 0; i-- {
+		if err = rangeFunc(i, evs[i]); err == ErrStopRange {
+			return nil
+		} else if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// length of array or slice
+func (v Value) arrayLen() (ln int, err error) {
+	if err = v.kind(reflect.Array, reflect.Slice); err != nil {
+		return
+	}
+	return v.Len()
+}
+
+func (v Value) rangeRefs(
+	start int, //                : index of first element
+	count int, //                : max number of elements
+	reverse bool, //             : descending
+	rangeFunc RangeIndexFunc, // : the function
+) (
+	err error, //                : an error
+) {
+
+	var refs Refs
+	if refs, err = v.refs(); err != nil {
+		return
+	}
+
+	var ln int
+	if ln, err = refs.Len(v.pack); err != nil {
+		return
+	}
+
+	if start >= ln {
+		return // nothing to iterate
+	}
+
+	var (
+		el = v.s.Elem()
+		n  int // iterated
+	)
+
+	var iterate = func(i int, hash cipher.SHA256) (err error) {
+
+		if count > 0 && n == count {
+			return ErrStopIteration
+		}
+		n++
+
+		if hash == (cipher.SHA256{}) {
+			return // skip nil
+		}
+
+		var ev Value
+		if ev, err = valueByHash(v.pack, el, hash); err != nil {
+			return
+		}
+
+		return rangeFunc(i, ev)
+	}
+
+	if reverse == true {
+		return refs.Descend(v.pack, iterate)
+	}
+
+	return refs.AscendFrom(v.pack, start, iterate)
+}
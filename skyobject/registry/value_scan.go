@@ -0,0 +1,328 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// Scan the Value to given Go value. The dst must
+// be a non-nil pointer. The Scan follows all
+// references. See ScanDepth for details
+func (v Value) Scan(dst interface{}) (err error) {
+	return v.ScanDepth(dst, -1)
+}
+
+// ScanDepth scans the Value to given Go value. The
+// dst must be a non-nil pointer. If the dst points
+// to type registered in Registry of the Pack of the
+// Value with the same name (see Registry.Types), then
+// the Value decoded directly. Otherwise fields of
+// structures are matched by name, and fields missing
+// in the dst are skipped. Numbers can be scanned to
+// a number of any size of the same kind (signed,
+// unsigned or float). A Ref, Refs or Dynamic can be
+// scanned to the same type, or it can be followed.
+// A Ref can be followed to a struct or a pointer, a
+// Refs to a slice, and a Dynamic to a pointer or to
+// an interface{} (using type registered in the
+// Registry). References are followed up to given
+// depth, references beyond the depth are left zero.
+// Negative depth means no limit. For example
+//
+//     type GroupView struct {
+//         Name    string
+//         Members []User      // Refs
+//         Curator *User       // Ref
+//         Any     interface{} // Dynamic
+//     }
+//
+//     var gv GroupView
+//     if err := group.Scan(&gv); err != nil {
+//         // [...]
+//     }
+//
+func (v Value) ScanDepth(dst interface{}, depth int) (err error) {
+
+	var rv = reflect.ValueOf(dst)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() == true {
+		return ErrInvalidScanType
+	}
+
+	return v.scan(rv.Elem(), depth)
+}
+
+// is given type registered with name of the Schema
+func (v Value) isRegisteredType(typ reflect.Type) bool {
+
+	if v.pack == nil || v.s.IsReference() == true {
+		return false
+	}
+
+	var reg = v.pack.Registry()
+
+	if reg == nil || reg.tn == nil {
+		return false
+	}
+
+	if name, ok := reg.tn[typ]; ok == true && name == v.s.Name() {
+		return true
+	}
+
+	return false
+}
+
+func (v Value) scan(rv reflect.Value, depth int) (err error) {
+
+	if v.s == nil {
+		return ErrInvalidValueKind
+	}
+
+	switch v.s.ReferenceType() {
+	case ReferenceTypeSingle, ReferenceTypeDynamic:
+		return v.scanReference(rv, depth)
+	case ReferenceTypeSlice:
+		return v.scanRefs(rv, depth)
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() == true {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return v.scan(rv.Elem(), depth)
+	}
+
+	if v.isRegisteredType(rv.Type()) == true {
+		return encoder.DeserializeRaw(v.p, rv.Addr().Interface())
+	}
+
+	switch v.s.Kind() {
+
+	case reflect.Bool:
+
+		if rv.Kind() != reflect.Bool {
+			return ErrInvalidScanType
+		}
+
+		var b bool
+		if b, err = v.Bool(); err == nil {
+			rv.SetBool(b)
+		}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+		default:
+			return ErrInvalidScanType
+		}
+
+		var i int64
+		if i, err = v.Int(); err != nil {
+			return
+		}
+
+		if rv.OverflowInt(i) == true {
+			return ErrInvalidScanType
+		}
+
+		rv.SetInt(i)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		switch rv.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+		default:
+			return ErrInvalidScanType
+		}
+
+		var u uint64
+		if u, err = v.Uint(); err != nil {
+			return
+		}
+
+		if rv.OverflowUint(u) == true {
+			return ErrInvalidScanType
+		}
+
+		rv.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+		default:
+			return ErrInvalidScanType
+		}
+
+		var f float64
+		if f, err = v.Float(); err == nil {
+			rv.SetFloat(f)
+		}
+
+	case reflect.String:
+
+		if rv.Kind() != reflect.String {
+			return ErrInvalidScanType
+		}
+
+		var s string
+		if s, err = v.String(); err == nil {
+			rv.SetString(s)
+		}
+
+	case reflect.Array, reflect.Slice:
+
+		err = v.scanElements(rv, depth)
+
+	case reflect.Struct:
+
+		err = v.scanStruct(rv, depth)
+
+	default:
+
+		err = ErrInvalidValueKind
+
+	}
+
+	return
+}
+
+func (v Value) scanElements(rv reflect.Value, depth int) (err error) {
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		rv.Set(reflect.MakeSlice(rv.Type(), ln, ln))
+	case reflect.Array:
+		if rv.Len() != ln {
+			return ErrInvalidScanType
+		}
+	default:
+		return ErrInvalidScanType
+	}
+
+	for i := 0; i < ln; i++ {
+
+		var ev Value
+
+		if ev, err = v.Index(i); err == ErrRefsElementIsNil {
+			err = nil
+			continue // leave zero
+		} else if err != nil {
+			return
+		}
+
+		if err = ev.scan(rv.Index(i), depth); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+func (v Value) scanStruct(rv reflect.Value, depth int) (err error) {
+
+	if rv.Kind() != reflect.Struct {
+		return ErrInvalidScanType
+	}
+
+	for i, f := range v.s.Fields() {
+
+		var df = rv.FieldByName(f.Name())
+
+		if df.IsValid() == false || df.CanSet() == false {
+			continue // missing or unexported
+		}
+
+		var fv Value
+		if fv, err = v.FieldByIndex(i); err != nil {
+			return
+		}
+
+		if err = fv.scan(df, depth); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// Ref or Dynamic
+func (v Value) scanReference(rv reflect.Value, depth int) (err error) {
+
+	switch rv.Type() {
+	case typeOfRef, typeOfDynamic:
+
+		var isDynamic = v.s.ReferenceType() == ReferenceTypeDynamic
+
+		if (rv.Type() == typeOfDynamic) != isDynamic {
+			return ErrInvalidScanType
+		}
+
+		return encoder.DeserializeRaw(v.p, rv.Addr().Interface())
+	}
+
+	if depth == 0 || v.IsNil() == true {
+		return // leave zero
+	}
+
+	var dv Value
+	if dv, err = v.Dereference(); err != nil {
+		return
+	}
+
+	if rv.Kind() != reflect.Interface {
+		return dv.scan(rv, depth-1)
+	}
+
+	// interface{}, use registered type
+
+	var typ, ok = v.pack.Registry().nt[dv.s.Name()]
+
+	if ok == false {
+		return ErrInvalidScanType
+	}
+
+	var nv = reflect.New(typ)
+
+	if err = dv.scan(nv.Elem(), depth-1); err != nil {
+		return
+	}
+
+	if nv.Type().AssignableTo(rv.Type()) == true {
+		rv.Set(nv) // pointer
+	} else if typ.AssignableTo(rv.Type()) == true {
+		rv.Set(nv.Elem())
+	} else {
+		return ErrInvalidScanType
+	}
+
+	return
+}
+
+func (v Value) scanRefs(rv reflect.Value, depth int) (err error) {
+
+	if rv.Type() == typeOfRefs {
+		return encoder.DeserializeRaw(v.p, rv.Addr().Interface())
+	}
+
+	if rv.Kind() != reflect.Slice {
+		return ErrInvalidScanType
+	}
+
+	if depth == 0 {
+		return // leave zero
+	}
+
+	return v.scanElements(rv, depth-1)
+}
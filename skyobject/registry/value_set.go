@@ -0,0 +1,191 @@
+package registry
+
+import (
+	"encoding/binary"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// are Schemas describe the same type
+func sameSchema(a, b Schema) bool {
+	return a.Kind() == b.Kind() &&
+		a.ReferenceType() == b.ReferenceType() &&
+		a.CryptoType() == b.CryptoType() &&
+		a.Name() == b.Name()
+}
+
+// encode given value using given Schema
+func encodeBySchema(s Schema, val interface{}) (p []byte, err error) {
+
+	if x, ok := val.(Value); ok == true {
+
+		if x.s == nil || sameSchema(x.s, s) == false {
+			err = ErrInvalidSchemaOrData
+			return
+		}
+
+		return x.p, nil
+	}
+
+	p = encoder.Serialize(val)
+
+	var n int
+	if n, err = s.Size(p); err != nil {
+		return
+	}
+
+	if n != len(p) {
+		err = ErrInvalidSchemaOrData
+	}
+
+	return
+}
+
+// replace n bytes of the Value starting from shift with given p
+func (v Value) replace(shift, n int, p []byte) (nv Value) {
+
+	var np = make([]byte, 0, len(v.p)-n+len(p))
+
+	np = append(np, v.p[:shift]...)
+	np = append(np, p...)
+	np = append(np, v.p[shift+n:]...)
+
+	nv.pack, nv.s, nv.p = v.pack, v.s, np
+	return
+}
+
+// SetField returns copy of the Value with given field of
+// the struct replaced with given value. The SetField, the
+// SetIndex and the Append never change the Value, they
+// return new Value with re-encoded payload. A value to set
+// can be a Value or any Go value that can be encoded using
+// the encoder package and that fits Schema of the field or
+// element. Since a Ref is just a hash, a Ref can be set
+// using Save of the Value it should refer to. For example
+//
+//     user, err := group.ByPath("Curator")
+//     // [...]
+//     if user, err = user.SetField("Age", uint32(22)); err != nil {
+//         // [...]
+//     }
+//     var hash cipher.SHA256
+//     if hash, err = user.Save(); err != nil {
+//         // [...]
+//     }
+//     group, err = group.SetField("Curator", registry.Ref{Hash: hash})
+//     // [...]
+//     hash, err = group.Save()
+//
+// Elements of Refs can't be changed this way, use methods
+// of the Refs instead
+func (v Value) SetField(name string, val interface{}) (nv Value, err error) {
+
+	if err = v.kind(reflect.Struct); err != nil {
+		return
+	}
+
+	for i, f := range v.s.Fields() {
+		if f.Name() == name {
+			return v.setField(i, f.Schema(), val)
+		}
+	}
+
+	err = ErrNoSuchField
+	return
+}
+
+func (v Value) setField(
+	i int, //               : index of the field
+	s Schema, //            : schema of the field
+	val interface{}, //     : value to set
+) (
+	nv Value, //            : new Value
+	err error, //           : an error
+) {
+
+	var p []byte
+	if p, err = encodeBySchema(s, val); err != nil {
+		return
+	}
+
+	var shift, n int
+	if shift, err = v.fieldShift(i); err != nil {
+		return
+	}
+
+	if n, err = s.Size(v.p[shift:]); err != nil {
+		return
+	}
+
+	return v.replace(shift, n, p), nil
+}
+
+// SetIndex returns copy of the Value with element of
+// the array or slice replaced with given value
+func (v Value) SetIndex(i int, val interface{}) (nv Value, err error) {
+
+	var shift int
+	if shift, err = v.elemShift(i); err != nil {
+		return
+	}
+
+	var (
+		el = v.s.Elem()
+		p  []byte
+		n  int
+	)
+
+	if p, err = encodeBySchema(el, val); err != nil {
+		return
+	}
+
+	if n, err = el.Size(v.p[shift:]); err != nil {
+		return
+	}
+
+	return v.replace(shift, n, p), nil
+}
+
+// Append returns copy of the Value with given
+// values appended. The Value must be a slice
+func (v Value) Append(vals ...interface{}) (nv Value, err error) {
+
+	if err = v.kind(reflect.Slice); err != nil {
+		return
+	}
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	if err = DefaultLimits.checkLength(ln + len(vals)); err != nil {
+		return
+	}
+
+	var (
+		el = v.s.Elem()
+		np = append([]byte{}, v.p...)
+		p  []byte
+	)
+
+	for _, val := range vals {
+		if p, err = encodeBySchema(el, val); err != nil {
+			return
+		}
+		np = append(np, p...)
+	}
+
+	binary.LittleEndian.PutUint32(np, uint32(ln+len(vals)))
+
+	nv.pack, nv.s, nv.p = v.pack, v.s, np
+	return
+}
+
+// Save encoded Value to the Pack returning its hash.
+// The hash can be used to refer to the Value
+func (v Value) Save() (hash cipher.SHA256, err error) {
+	return v.pack.Add(v.p)
+}
@@ -0,0 +1,248 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A ValueStats represents statistic of subtree
+// of a Value: the Value and all objects reachable
+// from it by references. Every object is counted
+// once, even if it's referred many times
+type ValueStats struct {
+	Volume int // total encoded bytes of all objects
+	Amount int // number of objects (including Refs nodes)
+	Nodes  int // number of internal Refs nodes
+
+	// MaxDepth is max number of references from the
+	// Value to an object. It's zero for a Value without
+	// references. Internal nodes of Refs are not
+	// counted
+	MaxDepth int
+
+	// Schemas is number of objects per name of Schema
+	// (or per Schema.String for unnamed schemas). Internal
+	// nodes of Refs are not represented
+	Schemas map[string]int
+}
+
+func (v *ValueStats) add(s Schema, vol, depth int) {
+	v.Volume += vol
+	v.Amount++
+	if depth > v.MaxDepth {
+		v.MaxDepth = depth
+	}
+	v.Schemas[s.String()]++
+}
+
+// Stats returns statistic of subtree of the Value using
+// given Pack to get objects. The Value is counted as an
+// object too. The Stats can be used to find out how much
+// storage a feed requires. Blank references are skipped
+func (v Value) Stats(pack Pack) (vs ValueStats, err error) {
+
+	if v.s == nil {
+		err = ErrInvalidSchema
+		return
+	}
+
+	var s = valueStats{
+		pack: pack,
+		seen: make(map[cipher.SHA256]struct{}),
+	}
+
+	s.vs.Schemas = make(map[string]int)
+
+	s.seen[cipher.SumSHA256(v.p)] = struct{}{}
+	s.vs.add(v.s, len(v.p), 0)
+
+	v.pack = pack
+	if err = s.walk(v, 0); err != nil {
+		return
+	}
+
+	return s.vs, nil
+}
+
+// state of the Stats
+type valueStats struct {
+	pack Pack
+	seen map[cipher.SHA256]struct{}
+	vs   ValueStats
+}
+
+// count object by hash and walk from it
+func (s *valueStats) object(
+	sch Schema, //         : schema of the object
+	hash cipher.SHA256, // : hash of the object
+	depth int, //          : depth of the object
+) (
+	err error, //          : an error
+) {
+
+	if _, ok := s.seen[hash]; ok == true {
+		return // already counted
+	}
+	s.seen[hash] = struct{}{}
+
+	var val []byte
+	if val, err = s.pack.Get(hash); err != nil {
+		return
+	}
+
+	s.vs.add(sch, len(val), depth)
+
+	if sch.HasReferences() == false {
+		return
+	}
+
+	if hasDefaults(sch) == true {
+		if val, err = ApplyDefaults(sch, val); err != nil {
+			return
+		}
+	}
+
+	var v Value
+	if v, err = NewValue(s.pack, sch, val); err != nil {
+		return
+	}
+
+	return s.walk(v, depth)
+}
+
+// walk references of the Value
+func (s *valueStats) walk(v Value, depth int) (err error) {
+
+	if v.s.HasReferences() == false {
+		return
+	}
+
+	switch v.s.ReferenceType() {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if err = encoder.DeserializeRaw(v.p, &ref); err != nil {
+			return
+		}
+
+		if ref.IsBlank() == true {
+			return
+		}
+
+		return s.object(v.s.Elem(), ref.Hash, depth+1)
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if err = encoder.DeserializeRaw(v.p, &dr); err != nil {
+			return
+		}
+
+		if dr.IsBlank() == true || dr.Hash == (cipher.SHA256{}) {
+			return
+		}
+
+		var sch Schema
+		if sch, err = s.pack.Registry().SchemaByReference(dr.Schema); err != nil {
+			return
+		}
+
+		return s.object(sch, dr.Hash, depth+1)
+
+	case ReferenceTypeSlice:
+
+		return s.walkRefs(v, depth)
+
+	}
+
+	switch v.s.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		var ln int
+		if ln, err = v.Len(); err != nil {
+			return
+		}
+
+		for i := 0; i < ln; i++ {
+
+			var ev Value
+			if ev, err = v.Index(i); err != nil {
+				return
+			}
+
+			if err = s.walk(ev, depth); err != nil {
+				return
+			}
+
+		}
+
+	case reflect.Struct:
+
+		for i := range v.s.Fields() {
+
+			var fv Value
+			if fv, err = v.FieldByIndex(i); err != nil {
+				return
+			}
+
+			if err = s.walk(fv, depth); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// internal nodes and elements of Refs
+func (s *valueStats) walkRefs(v Value, depth int) (err error) {
+
+	var refs Refs
+	if refs, err = v.refs(); err != nil {
+		return
+	}
+
+	var el = v.s.Elem()
+
+	err = refs.Walk(s.pack, el, func(
+		hash cipher.SHA256,
+		rd int,
+	) (
+		deepper bool,
+		err error,
+	) {
+
+		if hash == (cipher.SHA256{}) {
+			return // blank
+		}
+
+		if rd == 0 {
+			// element, don't go deepper using the Walk
+			return false, s.object(el, hash, depth+1)
+		}
+
+		if _, ok := s.seen[hash]; ok == true {
+			return // the same subtree already counted
+		}
+		s.seen[hash] = struct{}{}
+
+		var val []byte
+		if val, err = s.pack.Get(hash); err != nil {
+			return
+		}
+
+		s.vs.Volume += len(val)
+		s.vs.Amount++
+		s.vs.Nodes++
+
+		return true, nil
+	})
+
+	return
+}
@@ -0,0 +1,1084 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func testValueGroup(t *testing.T) (pack *dummyPack, v Value) {
+	t.Helper()
+
+	pack = getTestPack()
+
+	var group = TestGroup{Name: "the CXO"}
+
+	var err error
+
+	for _, name := range []string{"Alice", "Eva", "Ammy"} {
+		err = group.Members.AppendValues(pack, TestUser{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = group.Curator.SetValue(pack, TestUser{"Bob", 21, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = group.Developer.SetValue(pack, TestMan{"kostyarin",
+		"logrusorgru"}); err != nil {
+
+		t.Fatal(err)
+	}
+
+	var sch Schema
+	if sch, err = pack.Registry().SchemaByName("test.Man"); err != nil {
+		t.Fatal(err)
+	}
+	group.Developer.Schema = sch.Reference()
+
+	if sch, err = pack.Registry().SchemaByName("test.Group"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err = NewValue(pack, sch, encoder.Serialize(group)); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func testValueString(t *testing.T, v Value, path, want string) {
+	t.Helper()
+
+	var pv, err = v.ByPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s string
+	if s, err = pv.String(); err != nil {
+		t.Fatal(err)
+	} else if s != want {
+		t.Errorf("%s: want %q, got %q", path, want, s)
+	}
+}
+
+func TestValue_ByPath(t *testing.T) {
+
+	var _, v = testValueGroup(t)
+
+	testValueString(t, v, "Name", "the CXO")
+	testValueString(t, v, "Members[0].Name", "Alice")
+	testValueString(t, v, "Members[2].Name", "Ammy")
+	testValueString(t, v, "Curator.Name", "Bob")
+	testValueString(t, v, "Developer.GitHub", "logrusorgru")
+
+	var age, err = v.ByPath("Curator.Age")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var u uint64
+	if u, err = age.Uint(); err != nil {
+		t.Fatal(err)
+	} else if u != 21 {
+		t.Error("wrong age", u)
+	}
+
+	var (
+		members Value
+		ln      int
+	)
+
+	if members, err = v.FieldByName("Members"); err != nil {
+		t.Fatal(err)
+	} else if ln, err = members.Len(); err != nil {
+		t.Fatal(err)
+	} else if ln != 3 {
+		t.Error("wrong length", ln)
+	}
+
+	if pv, err := v.ByPath(""); err != nil {
+		t.Fatal(err)
+	} else if pv.Schema() != v.Schema() {
+		t.Error("wrong Value")
+	}
+
+	for path, want := range map[string]error{
+		"Members[3].Name": ErrIndexOutOfRange,
+		"Name[0]":         ErrInvalidValueKind,
+		"Curator.Nothing": ErrNoSuchField,
+		"Members.Name":    ErrInvalidValueKind,
+		"Members[x]":      ErrInvalidPath,
+		"Name.":           ErrInvalidPath,
+		"Members[0":       ErrInvalidPath,
+	} {
+		var _, err = v.ByPath(path)
+
+		if pe, ok := err.(*PathError); ok == false {
+			t.Errorf("%s: unexpected error %v", path, err)
+		} else if pe.Err != want {
+			t.Errorf("%s: want %v, got %v", path, want, pe.Err)
+		}
+	}
+
+}
+
+func TestValue_Index(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+		reg  = pack.Registry()
+	)
+
+	var sch, err = reg.SchemaByName("test.Slices")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v Value
+	v, err = NewValue(pack, sch, encoder.Serialize(TestSliceStruct{
+		Int8:   []int8{1, -2, 3},
+		String: []string{"one", "two"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var i int64
+	if ev, err := v.ByPath("Int8[1]"); err != nil {
+		t.Fatal(err)
+	} else if i, err = ev.Int(); err != nil {
+		t.Fatal(err)
+	} else if i != -2 {
+		t.Error("wrong value", i)
+	}
+
+	testValueString(t, v, "String[1]", "two")
+
+}
+
+func TestValue_RangeIndex(t *testing.T) {
+
+	// collect names of elements
+	var collect = func(names *[]string) RangeIndexFunc {
+		return func(i int, ev Value) (err error) {
+			var s string
+			if s, err = ev.String(); err != nil {
+				var fv Value
+				if fv, err = ev.FieldByName("Name"); err != nil {
+					return
+				}
+				s, err = fv.String()
+			}
+			*names = append(*names, strconv.Itoa(i)+":"+s)
+			return
+		}
+	}
+
+	var pack = getTestPack()
+
+	var sch, err = pack.Registry().SchemaByName("test.Slices")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var slices Value
+	slices, err = NewValue(pack, sch, encoder.Serialize(TestSliceStruct{
+		String: []string{"one", "two", "three"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var strs Value
+	if strs, err = slices.FieldByName("String"); err != nil {
+		t.Fatal(err)
+	}
+
+	var _, group = testValueGroup(t)
+
+	var members Value
+	if members, err = group.FieldByName("Members"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		v       Value
+		all     string
+		reverse string
+		page    string // from 1, count 1
+	}{
+		{strs, "0:one 1:two 2:three", "2:three 1:two 0:one", "1:two"},
+		{members, "0:Alice 1:Eva 2:Ammy", "2:Ammy 1:Eva 0:Alice", "1:Eva"},
+	} {
+
+		var names []string
+
+		if err = tc.v.RangeIndex(collect(&names)); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(names, " "); got != tc.all {
+			t.Errorf("wrong RangeIndex: want %q, got %q", tc.all, got)
+		}
+
+		names = names[:0]
+		if err = tc.v.RangeIndexReverse(collect(&names)); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(names, " "); got != tc.reverse {
+			t.Errorf("wrong RangeIndexReverse: want %q, got %q",
+				tc.reverse, got)
+		}
+
+		names = names[:0]
+		if err = tc.v.RangeIndexFrom(1, 1, collect(&names)); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(names, " "); got != tc.page {
+			t.Errorf("wrong RangeIndexFrom: want %q, got %q", tc.page, got)
+		}
+
+		// out of range
+
+		names = names[:0]
+		if err = tc.v.RangeIndexFrom(3, 1, collect(&names)); err != nil {
+			t.Fatal(err)
+		}
+		if len(names) != 0 {
+			t.Error("unexpected elements:", names)
+		}
+
+		// stop
+
+		var n int
+		err = tc.v.RangeIndex(func(int, Value) (_ error) {
+			if n++; n == 2 {
+				return ErrStopRange
+			}
+			return
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Error("ErrStopRange doesn't stop:", n)
+		}
+
+	}
+
+}
+
+func TestValue_FieldByIndex(t *testing.T) {
+
+	var _, v = testUserValue(t)
+
+	// many times (the offsets are cached)
+	for k := 0; k < 2; k++ {
+
+		var fv, err = v.FieldByIndex(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var age uint64
+		if age, err = fv.Uint(); err != nil {
+			t.Fatal(err)
+		} else if age != 21 {
+			t.Error("wrong age", age)
+		}
+
+	}
+
+	testValueString(t, v, "Name", "Bob")
+
+	if _, err := v.FieldByIndex(2); err != ErrIndexOutOfRange {
+		t.Error("wrong error", err)
+	}
+
+}
+
+func TestDecodeField(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+		p    = encoder.Serialize(TestUser{"Bob", 21, nil})
+	)
+
+	var sch, err = pack.Registry().SchemaByName("test.User")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fp []byte
+	if fp, err = DecodeField(p, sch, "Age"); err != nil {
+		t.Fatal(err)
+	}
+
+	var age uint32
+	if err = encoder.DeserializeRaw(fp, &age); err != nil {
+		t.Fatal(err)
+	} else if age != 21 {
+		t.Error("wrong age", age)
+	}
+
+	if _, err = DecodeField(p, sch, "Email"); err != ErrNoSuchField {
+		t.Error("wrong error", err)
+	}
+
+	if _, err = DecodeField(p[:3], sch, "Age"); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestValue_Dereference(t *testing.T) {
+
+	var _, v = testValueGroup(t)
+
+	var c, err = v.FieldByName("Curator")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.IsNil() == true {
+		t.Error("nil")
+	}
+
+	var dv Value
+	if dv, err = c.Dereference(); err != nil {
+		t.Fatal(err)
+	} else if dv.Schema().Name() != "test.User" {
+		t.Error("wrong schema", dv.Schema())
+	}
+
+	if _, err = dv.Dereference(); err != ErrInvalidValueKind {
+		t.Error("wrong error", err)
+	}
+
+}
+
+func TestValueToJSON(t *testing.T) {
+
+	var _, v = testValueGroup(t)
+
+	var p, err = ValueToJSON(v, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `{"Name":"the CXO","Members":[{"Name":"Alice","Age":0},` +
+		`{"Name":"Eva","Age":0},{"Name":"Ammy","Age":0}],` +
+		`"Curator":{"Name":"Bob","Age":21},` +
+		`"Developer":{"Name":"kostyarin","GitHub":"logrusorgru"}}`
+
+	if string(p) != want {
+		t.Errorf("wrong JSON:\nwant %s\ngot  %s", want, p)
+	}
+
+	// references as hashes
+
+	if p, err = json.Marshal(v); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		curator Value
+		group   struct {
+			Name      string
+			Members   string
+			Curator   string
+			Developer struct{ Schema, Hash string }
+		}
+	)
+
+	if err = json.Unmarshal(p, &group); err != nil {
+		t.Fatal(err)
+	}
+
+	if curator, err = v.FieldByName("Curator"); err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Curator != hex.EncodeToString(curator.Encoded()) {
+		t.Error("wrong hash of Curator", group.Curator)
+	}
+
+	if group.Members == "" || group.Developer.Hash == "" ||
+		group.Developer.Schema == "" {
+
+		t.Errorf("missing hashes: %s", p)
+	}
+
+}
+
+func TestValue_Interface(t *testing.T) {
+
+	var _, v = testValueGroup(t)
+
+	var i, err = v.InterfaceDepth(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var user = func(name string, age uint64) map[string]interface{} {
+		return map[string]interface{}{"Name": name, "Age": age}
+	}
+
+	var want = map[string]interface{}{
+		"Name": "the CXO",
+		"Members": []interface{}{
+			user("Alice", 0),
+			user("Eva", 0),
+			user("Ammy", 0),
+		},
+		"Curator": user("Bob", 21),
+		"Developer": map[string]interface{}{
+			"Name":   "kostyarin",
+			"GitHub": "logrusorgru",
+		},
+	}
+
+	if reflect.DeepEqual(i, want) == false {
+		t.Errorf("wrong value:\nwant %#v\ngot  %#v", want, i)
+	}
+
+	// references are not followed
+
+	if i, err = v.Interface(); err != nil {
+		t.Fatal(err)
+	}
+
+	var group = i.(map[string]interface{})
+
+	if _, ok := group["Members"].(cipher.SHA256); ok == false {
+		t.Errorf("Refs is not a hash: %T", group["Members"])
+	}
+
+	if _, ok := group["Curator"].(cipher.SHA256); ok == false {
+		t.Errorf("Ref is not a hash: %T", group["Curator"])
+	}
+
+	var dr, ok = group["Developer"].(map[string]interface{})
+	if ok == false {
+		t.Fatalf("wrong type of Dynamic: %T", group["Developer"])
+	}
+
+	if _, ok = dr["schema"].(SchemaRef); ok == false {
+		t.Errorf("wrong schema of Dynamic: %T", dr["schema"])
+	}
+
+}
+
+func TestValue_TreeHash(t *testing.T) {
+
+	var pack, v = testValueGroup(t)
+
+	var th, err = v.TreeHash(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same group with Refs of another degree
+
+	var group TestGroup
+	if err = encoder.DeserializeRaw(v.Encoded(), &group); err != nil {
+		t.Fatal(err)
+	}
+
+	var members = group.Members.Hash
+
+	if err = group.Members.SetDegree(pack, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Members.Hash == members {
+		t.Fatal("the same hash of Refs with another degree")
+	}
+
+	var same Value
+	if same, err = NewValue(pack, v.Schema(), encoder.Serialize(group)); err != nil {
+		t.Fatal(err)
+	}
+
+	var sth cipher.SHA256
+	if sth, err = same.TreeHash(nil); err != nil {
+		t.Fatal(err)
+	} else if sth != th {
+		t.Error("different TreeHash of logically identical values")
+	}
+
+	// changed
+
+	group.Name = "another"
+
+	var changed Value
+	if changed, err = NewValue(pack, v.Schema(),
+		encoder.Serialize(group)); err != nil {
+
+		t.Fatal(err)
+	}
+
+	if sth, err = changed.TreeHash(pack); err != nil {
+		t.Fatal(err)
+	} else if sth == th {
+		t.Error("the same TreeHash of changed value")
+	}
+
+	// no references
+
+	var _, user = testUserValue(t)
+
+	if sth, err = user.TreeHash(nil); err != nil {
+		t.Fatal(err)
+	} else if sth != cipher.SumSHA256(user.Encoded()) {
+		t.Error("wrong TreeHash of value without references")
+	}
+
+}
+
+func TestValue_SetField(t *testing.T) {
+
+	var _, v = testValueGroup(t)
+
+	var (
+		curator Value
+		hash    cipher.SHA256
+		err     error
+	)
+
+	if curator, err = v.ByPath("Curator"); err != nil {
+		t.Fatal(err)
+	} else if curator, err = curator.Dereference(); err != nil {
+		t.Fatal(err)
+	}
+
+	if curator, err = curator.SetField("Name", "Alex"); err != nil {
+		t.Fatal(err)
+	} else if curator, err = curator.SetField("Age", uint32(22)); err != nil {
+		t.Fatal(err)
+	}
+
+	testValueString(t, curator, "Name", "Alex")
+
+	if hash, err = curator.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var nv Value
+	if nv, err = v.SetField("Curator", Ref{Hash: hash}); err != nil {
+		t.Fatal(err)
+	}
+
+	testValueString(t, nv, "Curator.Name", "Alex")
+	testValueString(t, v, "Curator.Name", "Bob") // not changed
+
+	if nv, err = nv.SetField("Name", "the Skycoin"); err != nil {
+		t.Fatal(err)
+	}
+
+	testValueString(t, nv, "Name", "the Skycoin")
+	testValueString(t, nv, "Curator.Name", "Alex")
+	testValueString(t, nv, "Developer.GitHub", "logrusorgru")
+
+	if _, err = nv.SetField("Name", uint32(1)); err != ErrInvalidSchemaOrData {
+		t.Error("wrong error", err)
+	}
+
+	if _, err = nv.SetField("Nothing", "x"); err != ErrNoSuchField {
+		t.Error("wrong error", err)
+	}
+
+	var name Value
+	if name, err = v.FieldByName("Name"); err != nil {
+		t.Fatal(err)
+	} else if _, err = curator.SetField("Name", name); err != nil {
+		t.Fatal(err)
+	} else if _, err = curator.SetField("Age", name); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestValue_SetIndex(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+		reg  = pack.Registry()
+	)
+
+	var sch, err = reg.SchemaByName("test.Slices")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v Value
+	v, err = NewValue(pack, sch, encoder.Serialize(TestSliceStruct{
+		Int8:   []int8{1, -2, 3},
+		String: []string{"one", "two"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var strs Value
+	if strs, err = v.FieldByName("String"); err != nil {
+		t.Fatal(err)
+	}
+
+	if strs, err = strs.SetIndex(0, "zero"); err != nil {
+		t.Fatal(err)
+	}
+
+	if strs, err = strs.Append("three", "four"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = strs.SetIndex(4, "five"); err != ErrIndexOutOfRange {
+		t.Error("wrong error", err)
+	}
+
+	if v, err = v.SetField("String", strs); err != nil {
+		t.Fatal(err)
+	}
+
+	var ts TestSliceStruct
+	if err = encoder.DeserializeRaw(v.Encoded(), &ts); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = []string{"zero", "two", "three", "four"}
+
+	if len(ts.String) != len(want) {
+		t.Fatal("wrong length", ts.String)
+	}
+
+	for i, s := range want {
+		if ts.String[i] != s {
+			t.Error("wrong element", i, ts.String[i])
+		}
+	}
+
+	if len(ts.Int8) != 3 || ts.Int8[1] != -2 {
+		t.Error("wrong Int8", ts.Int8)
+	}
+
+}
+
+func TestValue_Scan(t *testing.T) {
+
+	var _, v = testValueGroup(t)
+
+	type UserView struct {
+		Name string
+		Age  uint64 // uint32 in the Schema
+	}
+
+	type GroupView struct {
+		Name      string
+		Members   []UserView
+		Curator   *UserView
+		Developer interface{}
+	}
+
+	var gv GroupView
+	if err := v.Scan(&gv); err != nil {
+		t.Fatal(err)
+	}
+
+	if gv.Name != "the CXO" {
+		t.Error("wrong name", gv.Name)
+	}
+
+	if len(gv.Members) != 3 || gv.Members[1].Name != "Eva" {
+		t.Error("wrong members", gv.Members)
+	}
+
+	if gv.Curator == nil || *gv.Curator != (UserView{"Bob", 21}) {
+		t.Error("wrong curator", gv.Curator)
+	}
+
+	if dev, ok := gv.Developer.(*TestMan); ok == false {
+		t.Errorf("wrong type of developer %T", gv.Developer)
+	} else if dev.GitHub != "logrusorgru" {
+		t.Error("wrong developer", dev)
+	}
+
+	// depth
+
+	gv = GroupView{}
+	if err := v.ScanDepth(&gv, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if gv.Name != "the CXO" || gv.Members != nil || gv.Curator != nil ||
+		gv.Developer != nil {
+
+		t.Error("references followed", gv)
+	}
+
+	// registered type
+
+	var group TestGroup
+	if err := v.Scan(&group); err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Name != "the CXO" || group.Curator.IsBlank() == true {
+		t.Error("wrong group", group)
+	}
+
+	// errors
+
+	var wrong struct{ Name int }
+	if err := v.Scan(&wrong); err != ErrInvalidScanType {
+		t.Error("wrong error", err)
+	}
+
+	if err := v.Scan(gv); err != ErrInvalidScanType {
+		t.Error("wrong error", err)
+	}
+
+}
+
+func testValueOf(t *testing.T, pack Pack, name string, obj interface{}) Value {
+	t.Helper()
+
+	var sch, err = pack.Registry().SchemaByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v Value
+	if v, err = NewValue(pack, sch, encoder.Serialize(obj)); err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func TestValue_Compare(t *testing.T) {
+
+	var pack, group = testValueGroup(t)
+
+	var (
+		alice19 = testValueOf(t, pack, "test.User", TestUser{"Alice", 19, nil})
+		alice20 = testValueOf(t, pack, "test.User", TestUser{"Alice", 20, nil})
+		bob     = testValueOf(t, pack, "test.User", TestUser{"Bob", 1, nil})
+
+		nan = testValueOf(t, pack, "test.Floats",
+			TestFloatsStruct{float32(math.NaN()), 0})
+		one = testValueOf(t, pack, "test.Floats", TestFloatsStruct{1, 0})
+	)
+
+	for _, tc := range []struct {
+		a, b Value
+		cmp  int
+	}{
+		{alice19, alice19, 0},
+		{alice19, alice20, -1},
+		{alice20, alice19, 1},
+		{alice20, bob, -1}, // by name first
+		{nan, nan, 0},
+		{nan, one, -1},
+		{one, nan, 1},
+	} {
+
+		var cmp, err = tc.a.Compare(tc.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cmp != tc.cmp {
+			t.Errorf("wrong result: want %d, got %d", tc.cmp, cmp)
+		}
+
+		var eq bool
+		if eq, err = tc.a.Equal(tc.b); err != nil {
+			t.Fatal(err)
+		} else if eq != (tc.cmp == 0) {
+			t.Error("wrong equality", eq)
+		}
+
+	}
+
+	// references by hash
+
+	var c1, c2 Value
+	var err error
+
+	if c1, err = group.FieldByName("Curator"); err != nil {
+		t.Fatal(err)
+	}
+
+	if c2, err = group.FieldByName("Members"); err != nil {
+		t.Fatal(err)
+	}
+
+	if eq, err := c1.Equal(c1); err != nil || eq == false {
+		t.Error("reference is not equal to itself", err)
+	}
+
+	// different schemas
+
+	if _, err = alice19.Compare(group); err != ErrNotComparable {
+		t.Error("wrong error", err)
+	}
+
+	if _, err = c1.Compare(c2); err != ErrNotComparable {
+		t.Error("wrong error", err)
+	}
+
+	if eq, err := alice19.Equal(group); err != nil || eq == true {
+		t.Error("different schemas are equal", err)
+	}
+
+}
+
+func TestValue_Stats(t *testing.T) {
+
+	var pack, v = testValueGroup(t)
+
+	var members, err = v.FieldByName("Members")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var refs Refs
+	if refs, err = members.refs(); err != nil {
+		t.Fatal(err)
+	}
+
+	var node []byte
+	if node, err = pack.Get(refs.Hash); err != nil {
+		t.Fatal(err)
+	}
+
+	var volume = len(v.Encoded()) + len(node) +
+		len(encoder.Serialize(TestMan{"kostyarin", "logrusorgru"})) +
+		len(encoder.Serialize(TestUser{"Bob", 21, nil}))
+
+	for _, name := range []string{"Alice", "Eva", "Ammy"} {
+		volume += len(encoder.Serialize(TestUser{Name: name}))
+	}
+
+	var vs ValueStats
+	if vs, err = v.Stats(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if vs.Volume != volume {
+		t.Errorf("wrong volume: want %d, got %d", volume, vs.Volume)
+	}
+
+	if vs.Amount != 7 || vs.Nodes != 1 || vs.MaxDepth != 1 {
+		t.Errorf("wrong stats: %+v", vs)
+	}
+
+	if vs.Schemas["test.Group"] != 1 || vs.Schemas["test.User"] != 4 ||
+		vs.Schemas["test.Man"] != 1 {
+
+		t.Error("wrong schemas:", vs.Schemas)
+	}
+
+	// the same object counted once
+
+	var alice = cipher.SumSHA256(encoder.Serialize(TestUser{Name: "Alice"}))
+
+	if v, err = v.SetField("Curator", Ref{Hash: alice}); err != nil {
+		t.Fatal(err)
+	}
+
+	if vs, err = v.Stats(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if vs.Amount != 6 || vs.Schemas["test.User"] != 3 {
+		t.Errorf("wrong stats: %+v", vs)
+	}
+
+}
+
+func testUserValue(t *testing.T) (pack *dummyPack, v Value) {
+	t.Helper()
+
+	pack = getTestPack()
+
+	var sch, err = pack.Registry().SchemaByName("test.User")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = NewValue(pack, sch, encoder.Serialize(TestUser{"Bob", 21, nil}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestValueToCBOR(t *testing.T) {
+
+	var _, v = testUserValue(t)
+
+	var p, err = ValueToCBOR(v, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = "a2" + "644e616d65" + "63426f62" + "63416765" + "15"
+	if got := hex.EncodeToString(p); got != want {
+		t.Errorf("wrong CBOR: want %s, got %s", want, got)
+	}
+
+	var _, group = testValueGroup(t)
+
+	// not inlined
+	if p, err = ValueToCBOR(group, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var curator Value
+	if curator, err = group.FieldByName("Curator"); err != nil {
+		t.Fatal(err)
+	}
+
+	var ref = append([]byte{0xd9, 0x9d, 0xd1, 0x58, 0x20}, curator.Encoded()...)
+	if bytes.Contains(p, ref) == false {
+		t.Error("missing tagged Ref")
+	}
+
+	// inlined
+	if p, err = ValueToCBOR(group, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"Alice", "Eva", "Ammy", "Bob", "logrusorgru"} {
+		if bytes.Contains(p, []byte(s)) == false {
+			t.Error("missing inlined value", s)
+		}
+	}
+
+	for _, tt := range []struct {
+		write func(c *cborWriter)
+		want  string
+	}{
+		{func(c *cborWriter) { c.writeInt(-1) }, "20"},
+		{func(c *cborWriter) { c.writeInt(-500) }, "3901f3"},
+		{func(c *cborWriter) { c.writeUint(1000000) }, "1a000f4240"},
+		{func(c *cborWriter) { c.writeFloat64(1.1) }, "fb3ff199999999999a"},
+		{func(c *cborWriter) { c.writeBool(true) }, "f5"},
+		{func(c *cborWriter) { c.writeNil() }, "f6"},
+	} {
+		var c cborWriter
+		tt.write(&c)
+		if got := hex.EncodeToString(c.Bytes()); got != tt.want {
+			t.Errorf("wrong CBOR: want %s, got %s", tt.want, got)
+		}
+	}
+
+}
+
+func TestValueToMsgPack(t *testing.T) {
+
+	var _, v = testUserValue(t)
+
+	var p, err = ValueToMsgPack(v, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = "82" + "a44e616d65" + "a3426f62" + "a3416765" + "15"
+	if got := hex.EncodeToString(p); got != want {
+		t.Errorf("wrong MessagePack: want %s, got %s", want, got)
+	}
+
+	var _, group = testValueGroup(t)
+
+	if p, err = ValueToMsgPack(group, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var curator Value
+	if curator, err = group.FieldByName("Curator"); err != nil {
+		t.Fatal(err)
+	}
+
+	var ref = append([]byte{0xc7, 0x20, byte(MsgPackExtRef)},
+		curator.Encoded()...)
+	if bytes.Contains(p, ref) == false {
+		t.Error("missing Ref extension")
+	}
+
+	for _, tt := range []struct {
+		write func(m *msgpackWriter)
+		want  string
+	}{
+		{func(m *msgpackWriter) { m.writeInt(-1) }, "ff"},
+		{func(m *msgpackWriter) { m.writeInt(-33) }, "d0df"},
+		{func(m *msgpackWriter) { m.writeInt(-500) }, "d1fe0c"},
+		{func(m *msgpackWriter) { m.writeUint(200) }, "ccc8"},
+		{func(m *msgpackWriter) { m.writeUint(70000) }, "ce00011170"},
+		{func(m *msgpackWriter) { m.writeFloat32(1.5) }, "ca3fc00000"},
+		{func(m *msgpackWriter) { m.writeArrayHeader(20) }, "dc0014"},
+		{func(m *msgpackWriter) { m.writeBytes([]byte{1}) }, "c40101"},
+	} {
+		var m msgpackWriter
+		tt.write(&m)
+		if got := hex.EncodeToString(m.Bytes()); got != tt.want {
+			t.Errorf("wrong MessagePack: want %s, got %s", tt.want, got)
+		}
+	}
+
+}
+
+func TestRootToCBOR(t *testing.T) {
+
+	var pack, group = testValueGroup(t)
+
+	var dr Dynamic
+	dr.Schema = group.Schema().Reference()
+
+	var err error
+	if dr.Hash, err = group.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = &Root{Refs: []Dynamic{dr, {}}, Seq: 1}
+
+	var p []byte
+	if p, err = RootToCBOR(pack, r, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	// map of 10 fields, "Refs", array of 2 elements
+	if bytes.HasPrefix(p, []byte{0xaa, 0x64, 'R', 'e', 'f', 's', 0x82}) == false {
+		t.Error("wrong CBOR of Root")
+	}
+
+	if bytes.Contains(p, []byte("the CXO")) == false {
+		t.Error("missing inlined value")
+	}
+
+	if p, err = RootToMsgPack(pack, r, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var ref = append([]byte{0xc7, 0x40, byte(MsgPackExtDynamic)},
+		encoder.Serialize(dr)...)
+	if bytes.Contains(p, ref) == false {
+		t.Error("missing Dynamic extension")
+	}
+
+}
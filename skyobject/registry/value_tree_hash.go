@@ -0,0 +1,255 @@
+package registry
+
+import (
+	"encoding/binary"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// TreeHash returns canonical hash of fully dereferenced
+// subtree of the Value. Unlike hash of an encoded object,
+// the TreeHash doesn't depend on intermediate hashes. For
+// example, two Refs with the same elements but different
+// degree have different hashes, but the same TreeHash.
+// Thus, the TreeHash can be used to detect changes and to
+// find logically identical structures.
+//
+// The TreeHash is SHA256 of encoded Value where every
+// reference is replaced with TreeHash of the object it
+// refers to. A nil Ref is blank hash, a Dynamic is its
+// SchemaRef and the TreeHash (or blank hash), and a Refs
+// is SHA256 of number of elements (uint32) followed by
+// their TreeHash values (blank for nil). Thus, TreeHash
+// of a Value without references is hash of its encoded
+// data. Given Pack used to get objects, if it's nil, then
+// Pack of the Value is used
+func (v Value) TreeHash(pack Pack) (hash cipher.SHA256, err error) {
+
+	if v.s == nil {
+		err = ErrInvalidSchema
+		return
+	}
+
+	if pack == nil {
+		pack = v.pack
+	}
+
+	var t = treeHasher{
+		pack: pack,
+		done: make(map[treeHashKey]cipher.SHA256),
+	}
+
+	var p []byte
+	if p, err = t.encode(nil, v); err != nil {
+		return
+	}
+
+	return cipher.SumSHA256(p), nil
+}
+
+type treeHashKey struct {
+	hash cipher.SHA256
+	sr   SchemaRef
+}
+
+type treeHasher struct {
+	pack Pack
+	done map[treeHashKey]cipher.SHA256 // objects already hashed
+}
+
+// append canonical encoding of given Value to the p
+func (t *treeHasher) encode(p []byte, v Value) (_ []byte, err error) {
+
+	var s = v.s
+
+	if s.HasReferences() == false {
+		return append(p, v.p...), nil
+	}
+
+	var hash cipher.SHA256
+
+	switch s.ReferenceType() {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if err = encoder.DeserializeRaw(v.p, &ref); err != nil {
+			return
+		}
+
+		if hash, err = t.object(s.Elem(), ref.Hash); err != nil {
+			return
+		}
+
+		return append(p, hash[:]...), nil
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if err = encoder.DeserializeRaw(v.p, &dr); err != nil {
+			return
+		}
+
+		if dr.IsValid() == false {
+			err = ErrInvalidDynamicReference
+			return
+		}
+
+		if dr.IsBlank() == false && dr.Hash != (cipher.SHA256{}) {
+
+			var ds Schema
+			if ds, err = t.pack.Registry().SchemaByReference(
+				dr.Schema); err != nil {
+
+				return
+			}
+
+			if hash, err = t.object(ds, dr.Hash); err != nil {
+				return
+			}
+
+		}
+
+		p = append(p, dr.Schema[:]...)
+		return append(p, hash[:]...), nil
+
+	case ReferenceTypeSlice:
+
+		if hash, err = t.refs(s.Elem(), v); err != nil {
+			return
+		}
+
+		return append(p, hash[:]...), nil
+
+	}
+
+	switch s.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		var shift int
+
+		if s.Kind() == reflect.Slice {
+			shift = 4 // encoded length
+			p = append(p, v.p[:shift]...)
+		}
+
+		var ln int
+		if ln, err = v.Len(); err != nil {
+			return
+		}
+
+		var ev Value
+
+		for i := 0; i < ln; i++ {
+
+			if ev, err = NewValue(t.pack, s.Elem(), v.p[shift:]); err != nil {
+				return
+			}
+
+			if p, err = t.encode(p, ev); err != nil {
+				return
+			}
+
+			shift += len(ev.p)
+		}
+
+	case reflect.Struct:
+
+		var (
+			shift int
+			fv    Value
+		)
+
+		for _, f := range s.Fields() {
+
+			if fv, err = NewValue(t.pack, f.Schema(), v.p[shift:]); err != nil {
+				return
+			}
+
+			if p, err = t.encode(p, fv); err != nil {
+				return
+			}
+
+			shift += len(fv.p)
+		}
+
+	default:
+
+		err = ErrInvalidValueKind
+
+	}
+
+	return p, err
+}
+
+// TreeHash of object, blank for nil
+func (t *treeHasher) object(
+	s Schema, //           : schema of the object
+	hash cipher.SHA256, // : hash of the object
+) (
+	th cipher.SHA256, //   : the TreeHash
+	err error, //          : an error
+) {
+
+	if hash == (cipher.SHA256{}) {
+		return // nil
+	}
+
+	var key = treeHashKey{hash, s.Reference()}
+
+	var ok bool
+	if th, ok = t.done[key]; ok == true {
+		return
+	}
+
+	var v Value
+	if v, err = valueByHash(t.pack, s, hash); err != nil {
+		return
+	}
+
+	var p []byte
+	if p, err = t.encode(nil, v); err != nil {
+		return
+	}
+
+	th = cipher.SumSHA256(p)
+	t.done[key] = th
+	return
+}
+
+// TreeHash of elements of Refs
+func (t *treeHasher) refs(el Schema, v Value) (th cipher.SHA256, err error) {
+
+	var refs Refs
+	if refs, err = v.refs(); err != nil {
+		return
+	}
+
+	var ln int
+	if ln, err = refs.Len(t.pack); err != nil {
+		return
+	}
+
+	var p = make([]byte, 4, 4+ln*len(cipher.SHA256{}))
+	binary.LittleEndian.PutUint32(p, uint32(ln))
+
+	err = refs.Ascend(t.pack, func(_ int, hash cipher.SHA256) (err error) {
+
+		var eh cipher.SHA256
+		if eh, err = t.object(el, hash); err != nil {
+			return
+		}
+
+		p = append(p, eh[:]...)
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	return cipher.SumSHA256(p), nil
+}
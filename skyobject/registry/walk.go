@@ -8,6 +8,22 @@ import (
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
+// A Skipper is a Pack that filters out objects of
+// some schemas (see Splitter.Skip). Walking through
+// the Pack doesn't call WalkFunc for such objects
+// and doesn't go deepper
+type Skipper interface {
+	Skip(sch Schema) (skip bool)
+}
+
+// is object of given Schema filtered out by the Pack
+func skipped(pack Pack, sch Schema) (skip bool) {
+	if s, ok := pack.(Skipper); ok == true {
+		skip = s.Skip(sch)
+	}
+	return
+}
+
 // walkSchemaHash walks usng given Schema and
 // hash of the object (the Schema is Schema of the
 // object the hash points to); the WalkFunc is
@@ -160,7 +176,7 @@ func walkSlice(
 ) {
 
 	var ln int // length of the slice
-	if ln, err = getLength(val); err != nil {
+	if ln, err = DefaultLimits.length(val); err != nil {
 		return
 	}
 
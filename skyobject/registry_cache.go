@@ -0,0 +1,104 @@
+package skyobject
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A RegistryCacheStat represents statistic of a RegistryCache
+type RegistryCacheStat struct {
+	Size      int   // max number of Registries
+	Len       int   // number of cached Registries
+	Hits      int64 // found in the cache
+	Misses    int64 // not found in the cache
+	Evictions int64 // removed from the cache to free up space
+}
+
+// A RegistryCache is LRU cache of decoded Registries.
+// Decoding of a Registry is slow, and a node that tracks
+// many feeds decodes the same Registries again and again.
+// The RegistryCache can be shared between many Containers
+// (see Config.RegistryCache). The RegistryCache is thread
+// safe
+type RegistryCache struct {
+	mx sync.Mutex
+
+	size int                                    // max size
+	ll   *list.List                             // LRU order (front is recent)
+	rs   map[registry.RegistryRef]*list.Element // cached
+	stat RegistryCacheStat                      // metrics
+}
+
+// NewRegistryCache creates RegistryCache with given max
+// number of Registries. If the size is zero or less, then
+// the RegistryCache keeps nothing
+func NewRegistryCache(size int) (r *RegistryCache) {
+	r = new(RegistryCache)
+	r.size = size
+	r.ll = list.New()
+	r.rs = make(map[registry.RegistryRef]*list.Element)
+	return
+}
+
+// Get Registry by reference
+func (r *RegistryCache) Get(
+	rr registry.RegistryRef, // : the reference
+) (
+	reg *registry.Registry, //  : the Registry
+	ok bool, //                 : found or not
+) {
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	var el *list.Element
+	if el, ok = r.rs[rr]; ok == false {
+		r.stat.Misses++
+		return
+	}
+
+	r.stat.Hits++
+	r.ll.MoveToFront(el)
+
+	return el.Value.(*registry.Registry), true
+}
+
+// Add Registry to the RegistryCache. The Registry
+// should be resolved (see (*registry.Registry).Resolve)
+func (r *RegistryCache) Add(reg *registry.Registry) {
+
+	if r.size <= 0 {
+		return
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if el, ok := r.rs[reg.Reference()]; ok == true {
+		r.ll.MoveToFront(el)
+		return
+	}
+
+	for r.ll.Len() >= r.size {
+		var el = r.ll.Back()
+		r.ll.Remove(el)
+		delete(r.rs, el.Value.(*registry.Registry).Reference())
+		r.stat.Evictions++
+	}
+
+	r.rs[reg.Reference()] = r.ll.PushFront(reg)
+}
+
+// Stat returns statistic of the RegistryCache
+func (r *RegistryCache) Stat() (s RegistryCacheStat) {
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	s = r.stat
+	s.Size = r.size
+	s.Len = r.ll.Len()
+	return
+}
@@ -0,0 +1,94 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func testRegistryN(n int) *registry.Registry {
+	return registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", User{})
+		for i := 0; i < n; i++ {
+			r.Register("test.Post"+string(rune('a'+i)), Post{})
+		}
+	})
+}
+
+func TestRegistryCache_Get(t *testing.T) {
+
+	var (
+		rc = NewRegistryCache(2)
+
+		r1 = testRegistryN(1)
+		r2 = testRegistryN(2)
+		r3 = testRegistryN(3)
+	)
+
+	rc.Add(r1)
+	rc.Add(r2)
+
+	if r, ok := rc.Get(r1.Reference()); ok == false || r != r1 {
+		t.Fatal("missing r1")
+	}
+
+	rc.Add(r3) // evicts r2 (least recently used)
+
+	if _, ok := rc.Get(r2.Reference()); ok == true {
+		t.Error("r2 not evicted")
+	}
+
+	for _, r := range []*registry.Registry{r1, r3} {
+		if _, ok := rc.Get(r.Reference()); ok == false {
+			t.Error("missing", r.Reference().Short())
+		}
+	}
+
+	var s = rc.Stat()
+
+	if s.Size != 2 || s.Len != 2 {
+		t.Error("wrong size or length", s.Size, s.Len)
+	}
+
+	if s.Hits != 3 || s.Misses != 1 || s.Evictions != 1 {
+		t.Error("wrong metrics", s.Hits, s.Misses, s.Evictions)
+	}
+
+}
+
+func TestRegistryCache_zero(t *testing.T) {
+
+	var rc = NewRegistryCache(0)
+
+	rc.Add(testRegistry)
+
+	if _, ok := rc.Get(testRegistry.Reference()); ok == true {
+		t.Error("cached")
+	}
+
+}
+
+func TestRegistryCache_shared(t *testing.T) {
+
+	var (
+		rc   = NewRegistryCache(5)
+		conf = getTestConfig()
+	)
+
+	conf.RegistryCache = rc
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	c.AddRegistryToCache(testRegistry)
+
+	if _, ok := rc.Get(testRegistry.Reference()); ok == false {
+		t.Error("not shared")
+	}
+
+	if c.Stat().Registries.Len != 1 {
+		t.Error("wrong stat")
+	}
+
+}
@@ -0,0 +1,124 @@
+package skyobject
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A RootHook called by Container for every new full
+// Root. The Root and all its objects are saved in DB
+// when the hook is called. The Root is read-only. The
+// hook is called synchronously by goroutine that saves
+// or fills the Root, and it should not block for a
+// long time
+type RootHook func(r *registry.Root)
+
+// A DelHook called by Container for every object
+// removed from DB deleting Root objects of given feed
+// (see (*Index).DelRoot, DelHead and DelFeed). The
+// size is length of the removed object. The hook is
+// called synchronously and it should not block
+type DelHook func(feed cipher.PubKey, key cipher.SHA256, size int)
+
+// hooks of the Container
+type rootHooks struct {
+	mx    sync.Mutex
+	next  int
+	hooks map[int]RootHook
+	dels  map[int]DelHook
+}
+
+// AddRootHook adds given hook, that will be called for
+// every new Root saved (see Save) or filled (see Fill)
+// by the Container. It returns function that removes
+// the hook. The function can be called many times
+func (c *Container) AddRootHook(hook RootHook) (del func()) {
+
+	c.rh.mx.Lock()
+	defer c.rh.mx.Unlock()
+
+	if c.rh.hooks == nil {
+		c.rh.hooks = make(map[int]RootHook)
+	}
+
+	var id = c.rh.next
+	c.rh.next++
+
+	c.rh.hooks[id] = hook
+
+	return func() {
+		c.rh.mx.Lock()
+		defer c.rh.mx.Unlock()
+
+		delete(c.rh.hooks, id)
+	}
+}
+
+// call all hooks
+func (c *Container) rootAdded(r *registry.Root) {
+
+	c.rh.mx.Lock()
+
+	var hooks = make([]RootHook, 0, len(c.rh.hooks))
+	for _, hook := range c.rh.hooks {
+		hooks = append(hooks, hook)
+	}
+
+	c.rh.mx.Unlock()
+
+	for _, hook := range hooks {
+		hook(r)
+	}
+
+}
+
+// AddDelHook adds given hook, that will be called for
+// every object removed from DB deleting Root objects.
+// It returns function that removes the hook. The
+// function can be called many times
+func (c *Container) AddDelHook(hook DelHook) (del func()) {
+
+	c.rh.mx.Lock()
+	defer c.rh.mx.Unlock()
+
+	if c.rh.dels == nil {
+		c.rh.dels = make(map[int]DelHook)
+	}
+
+	var id = c.rh.next
+	c.rh.next++
+
+	c.rh.dels[id] = hook
+
+	return func() {
+		c.rh.mx.Lock()
+		defer c.rh.mx.Unlock()
+
+		delete(c.rh.dels, id)
+	}
+}
+
+// call all del hooks
+func (c *Container) objectDeleted(
+	feed cipher.PubKey, // :
+	key cipher.SHA256, //  :
+	size int, //           :
+) {
+
+	c.rh.mx.Lock()
+
+	var hooks = make([]DelHook, 0, len(c.rh.dels))
+	for _, hook := range c.rh.dels {
+		hooks = append(hooks, hook)
+	}
+
+	c.rh.mx.Unlock()
+
+	for _, hook := range hooks {
+		hook(feed, key, size)
+	}
+
+}
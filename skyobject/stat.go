@@ -24,6 +24,9 @@ type Stat struct {
 	// for cleaning
 	CacheCleaning time.Duration
 
+	// Registries is statistic of cache of Registries
+	Registries RegistryCacheStat
+
 	CacheObjects ObjectsStat // cached objects
 	AllObjects   ObjectsStat // all objects
 	UsedObjects  ObjectsStat // used objects
@@ -34,6 +37,11 @@ type Stat struct {
 
 	// Feeds contains statistic of feeds
 	Feeds map[cipher.PubKey]FeedStat
+
+	// IO is I/O statistic of CXDS. It's nil
+	// if the IOStat option is not set or the
+	// CXDS doesn't collect the statistic
+	IO *data.IOStat
 }
 
 // An ObjectsStat represents
@@ -89,6 +97,8 @@ func (c *Container) Stat() (s *Stat) {
 
 	s.CacheCleaning = c.Cache.stat.cacheCleaning()
 
+	s.Registries = c.Cache.rc.Stat()
+
 	var amount, volume = c.amountVolume() // of cache
 
 	s.CacheObjects.Amount = statutil.Amount(amount)
@@ -108,6 +118,12 @@ func (c *Container) Stat() (s *Stat) {
 
 	s.Feeds = c.Index.feedsStat()
 
+	if ios, ok := c.db.CXDS().(data.IOStater); ok == true {
+		if is, ok := ios.IOStat(); ok == true {
+			s.IO = &is
+		}
+	}
+
 	return
 }
 
@@ -0,0 +1,258 @@
+package skyobject
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A TemplateValue represents structure for html/template
+// and text/template. Keys are names of fields. Thus, a
+// registry.Value can be passed to a template without
+// decoding to Go structure. For example
+//
+//     tv, err := skyobject.NewTemplateValue(post, 1)
+//
+//     // {{.Title}} by {{.Author.Hex}}
+//     // {{range .Comments}}{{.Text}}{{end}}
+//
+//     err = tmpl.Execute(w, tv)
+//
+// Values of fields are
+//
+//     bool                       -> bool
+//     intX                       -> int64
+//     uintX                      -> uint64
+//     float32, float64           -> float64
+//     string                     -> string
+//     []byte, [n]byte            -> []byte
+//     cipher.PubKey, SHA256, Sig -> the same type
+//     arrays and slices          -> []interface{}
+//     structures                 -> TemplateValue
+//
+// References are inlined up to given depth (see
+// NewTemplateValue). An inlined Ref or Dynamic is the
+// object, and an inlined Refs is []interface{} of its
+// elements. Beyond the depth a Ref and Refs are hashes
+// (cipher.SHA256) and a Dynamic is TemplateValue with
+// Schema (registry.SchemaRef) and Hash (cipher.SHA256)
+// keys. A reference that represents nil is nil
+type TemplateValue map[string]interface{}
+
+// NewTemplateValue creates TemplateValue of given Value.
+// The Value should be structure or reference to structure.
+// References are inlined up to given depth. Use zero depth
+// to don't inline references and negative depth to inline
+// all references. The Value itself is dereferenced anyway
+func NewTemplateValue(v registry.Value, depth int) (
+	tv TemplateValue, err error) {
+
+	for isReference(v) == true {
+		if v, err = v.Dereference(); err != nil {
+			return
+		}
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, registry.ErrInvalidValueKind
+	}
+
+	return templateStruct(v, depth)
+}
+
+// is the Value Ref or Dynamic
+func isReference(v registry.Value) bool {
+	if s := v.Schema(); s != nil {
+		var rt = s.ReferenceType()
+		return rt == registry.ReferenceTypeSingle ||
+			rt == registry.ReferenceTypeDynamic
+	}
+	return false
+}
+
+func templateStruct(v registry.Value, depth int) (tv TemplateValue,
+	err error) {
+
+	var fs = v.Schema().Fields()
+
+	tv = make(TemplateValue, len(fs))
+
+	for i, f := range fs {
+
+		var fv registry.Value
+		if fv, err = v.FieldByIndex(i); err != nil {
+			return
+		}
+
+		if tv[f.Name()], err = templateOf(fv, depth); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+func templateOf(v registry.Value, depth int) (ti interface{}, err error) {
+
+	var s = v.Schema()
+
+	switch s.ReferenceType() {
+	case registry.ReferenceTypeSingle, registry.ReferenceTypeDynamic:
+		return templateReference(v, depth)
+	case registry.ReferenceTypeSlice:
+		return templateRefs(v, depth)
+	}
+
+	switch s.CryptoType() {
+	case registry.CryptoTypePubKey:
+		var pk cipher.PubKey
+		copy(pk[:], v.Encoded())
+		return pk, nil
+	case registry.CryptoTypeSHA256:
+		var hash cipher.SHA256
+		copy(hash[:], v.Encoded())
+		return hash, nil
+	case registry.CryptoTypeSig:
+		var sig cipher.Sig
+		copy(sig[:], v.Encoded())
+		return sig, nil
+	}
+
+	switch s.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Array, reflect.Slice:
+		return templateArray(v, depth)
+	case reflect.Struct:
+		return templateStruct(v, depth)
+	}
+
+	return nil, registry.ErrInvalidValueKind
+}
+
+// array or slice
+func templateArray(v registry.Value, depth int) (ti interface{}, err error) {
+
+	var s = v.Schema()
+
+	if s.Elem().Kind() == reflect.Uint8 && s.Elem().IsRegistered() == false {
+
+		var p = v.Encoded()
+
+		if s.Kind() == reflect.Slice {
+			p = p[4:] // encoded length
+		}
+
+		return append([]byte{}, p...), nil
+	}
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	var ts = make([]interface{}, 0, ln)
+
+	for i := 0; i < ln; i++ {
+
+		var ev registry.Value
+		if ev, err = v.Index(i); err != nil {
+			return
+		}
+
+		var et interface{}
+		if et, err = templateOf(ev, depth); err != nil {
+			return
+		}
+
+		ts = append(ts, et)
+	}
+
+	return ts, nil
+}
+
+// Ref or Dynamic
+func templateReference(v registry.Value, depth int) (ti interface{},
+	err error) {
+
+	if v.IsNil() == true {
+		return
+	}
+
+	if depth != 0 {
+
+		var dv registry.Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return templateOf(dv, depth-1)
+	}
+
+	if v.Schema().ReferenceType() == registry.ReferenceTypeSingle {
+		var ref registry.Ref
+		err = encoder.DeserializeRaw(v.Encoded(), &ref)
+		return ref.Hash, err
+	}
+
+	var dr registry.Dynamic
+	if err = encoder.DeserializeRaw(v.Encoded(), &dr); err != nil {
+		return
+	}
+
+	return TemplateValue{"Schema": dr.Schema, "Hash": dr.Hash}, nil
+}
+
+// Refs
+func templateRefs(v registry.Value, depth int) (ti interface{}, err error) {
+
+	if v.IsNil() == true {
+		return
+	}
+
+	if depth == 0 {
+		var hash cipher.SHA256
+		copy(hash[:], v.Encoded())
+		return hash, nil
+	}
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	var ts = make([]interface{}, 0, ln)
+
+	for i := 0; i < ln; i++ {
+
+		var ev registry.Value
+
+		if ev, err = v.Index(i); err == registry.ErrRefsElementIsNil {
+			ts = append(ts, nil)
+			continue
+		} else if err != nil {
+			return
+		}
+
+		var et interface{}
+		if et, err = templateOf(ev, depth-1); err != nil {
+			return
+		}
+
+		ts = append(ts, et)
+	}
+
+	return ts, nil
+}
@@ -0,0 +1,49 @@
+package skyobject
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestNewTemplateValue(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var _, sk = cipher.GenerateKeyPair()
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var v = testDiffFeed(t, up, "head", "one", "two")
+
+	var tmpl = template.Must(template.New("feed").Parse(
+		`{{.Head}}:{{range .Posts}} {{.Head}}{{end}}`))
+
+	// inline posts
+
+	var tv TemplateValue
+	tv, err = NewTemplateValue(v, 1)
+	assertNil(t, err)
+
+	var buf bytes.Buffer
+	assertNil(t, tmpl.Execute(&buf, tv))
+
+	if got := buf.String(); got != "head: one two" {
+		t.Errorf("wrong output: %q", got)
+	}
+
+	// don't inline
+
+	tv, err = NewTemplateValue(v, 0)
+	assertNil(t, err)
+
+	if _, ok := tv["Posts"].(cipher.SHA256); ok == false {
+		t.Errorf("Refs is not a hash: %T", tv["Posts"])
+	}
+
+}
@@ -217,12 +217,28 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 		return
 	}
 
+	// save external registries
+
+	var ers = make(map[registry.RegistryRef]*registry.Registry)
+
+	if err = externalRegistries(up.Registry(), ers); err != nil {
+		return
+	}
+
+	for rr, er := range ers {
+		if err = up.Set(cipher.SHA256(rr), er.Encode()); err != nil {
+			return
+		}
+	}
+
 	// make rc of related objects actual
 
 	for key, ui := range up.m {
 
 		if key == r.Hash || key == cipher.SHA256(r.Reg) {
 			ui.dec++
+		} else if _, ok := ers[registry.RegistryRef(key)]; ok == true {
+			ui.dec++
 		}
 
 		var inc = ui.dec - ui.inc
@@ -238,6 +254,39 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 
 	}
 
+	c.rootAdded(r)
+	return
+}
+
+// collect external registries of given Registry
+// and external registries of the external
+// registries and so on
+func externalRegistries(
+	reg *registry.Registry, //                          :
+	ers map[registry.RegistryRef]*registry.Registry, // : result
+) (
+	err error, //                                       :
+) {
+
+	for _, rr := range reg.Externals() {
+
+		if _, ok := ers[rr]; ok == true {
+			continue // already
+		}
+
+		var er *registry.Registry
+		if er, err = reg.External(rr); err != nil {
+			return
+		}
+
+		ers[rr] = er
+
+		if err = externalRegistries(er, ers); err != nil {
+			return
+		}
+
+	}
+
 	return
 }
 
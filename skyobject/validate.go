@@ -0,0 +1,275 @@
+package skyobject
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A ValidationError represents malformed object
+// found by Validate. The Path is path of malformed
+// value inside the object (see registry.Value.ByPath)
+type ValidationError struct {
+	Hash cipher.SHA256 // hash of the object
+	Path string        // path of the value in the object
+	Err  error         // reason
+}
+
+// Error implements error interface
+func (v *ValidationError) Error() string {
+	if v.Path == "" {
+		return fmt.Sprintf("malformed object %s: %v", v.Hash.Hex()[:7], v.Err)
+	}
+	return fmt.Sprintf("malformed object %s at %q: %v", v.Hash.Hex()[:7],
+		v.Path, v.Err)
+}
+
+// Unwrap returns the reason
+func (v *ValidationError) Unwrap() error {
+	return v.Err
+}
+
+// Validate walks Root with given hash and checks that every
+// object of the Root decodes cleanly against its schema. The
+// Validate checks lengths of slices and strings and depth and
+// size of objects (see registry.DefaultLimits), that strings
+// are valid UTF-8 and that there are not trailing bytes after
+// encoded objects. Objects older then their schemas are
+// checked with default values applied. The Pack should have
+// Registry of the Root. The Validate returns ValidationError
+// for malformed object, or any other error (e.g. missing
+// object). An object referred many times is checked once.
+// See also Config.ValidateFilled
+func Validate(pack registry.Pack, rootHash cipher.SHA256) (err error) {
+
+	var val []byte
+	if val, err = pack.Get(rootHash); err != nil {
+		return
+	}
+
+	var r *registry.Root
+	if r, err = registry.DecodeRoot(val); err != nil {
+		return
+	}
+	r.Hash = rootHash
+
+	return validateRoot(pack, r)
+}
+
+func validateRoot(pack registry.Pack, r *registry.Root) (err error) {
+
+	var reg = pack.Registry()
+
+	if reg == nil || reg.Reference() != r.Reg {
+		return registry.ErrMissingRegistry
+	}
+
+	var v = validator{
+		pack: pack,
+		done: make(map[validated]struct{}),
+	}
+
+	for i, dr := range r.Refs {
+		if err = v.dynamic(r.Hash, fmt.Sprintf("[%d]", i), dr); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+type validator struct {
+	pack registry.Pack
+	done map[validated]struct{} // already checked objects
+}
+
+// the same encoded object can be checked against
+// different schemas, thus the schema is part of key
+type validated struct {
+	hash cipher.SHA256
+	sr   registry.SchemaRef
+}
+
+// check object with given hash
+func (v *validator) object(s registry.Schema, hash cipher.SHA256) (err error) {
+
+	var key = validated{hash, s.Reference()}
+
+	if _, ok := v.done[key]; ok == true {
+		return
+	}
+	v.done[key] = struct{}{}
+
+	var val []byte
+	if val, err = v.pack.Get(hash); err != nil {
+		return
+	}
+
+	var malformed = func(path string, err error) error {
+		return &ValidationError{Hash: hash, Path: path, Err: err}
+	}
+
+	if val, err = registry.ApplyDefaults(s, val); err != nil {
+		return malformed("", err)
+	}
+
+	var n int
+	if n, err = s.Size(val); err != nil {
+		return malformed("", err)
+	}
+
+	if n != len(val) {
+		return malformed("", ErrTrailingBytes)
+	}
+
+	var value registry.Value
+	if value, err = registry.NewValue(v.pack, s, val); err != nil {
+		return malformed("", err)
+	}
+
+	return v.value(hash, "", value)
+}
+
+// check value of object with given hash
+func (v *validator) value(
+	hash cipher.SHA256, //   : hash of the object
+	path string, //          : path of the value
+	value registry.Value, // : the value
+) (
+	err error, //            : first error
+) {
+
+	var s = value.Schema()
+
+	var malformed = func(err error) error {
+		return &ValidationError{Hash: hash, Path: path, Err: err}
+	}
+
+	switch s.ReferenceType() {
+
+	case registry.ReferenceTypeSingle:
+
+		var ref registry.Ref
+		if err = encoder.DeserializeRaw(value.Encoded(), &ref); err != nil {
+			return malformed(err)
+		}
+
+		if ref.Hash == (cipher.SHA256{}) {
+			return // nil
+		}
+
+		return v.object(s.Elem(), ref.Hash)
+
+	case registry.ReferenceTypeDynamic:
+
+		var dr registry.Dynamic
+		if err = encoder.DeserializeRaw(value.Encoded(), &dr); err != nil {
+			return malformed(err)
+		}
+
+		return v.dynamic(hash, path, dr)
+
+	case registry.ReferenceTypeSlice:
+
+		var refs registry.Refs
+		if err = encoder.DeserializeRaw(value.Encoded(), &refs); err != nil {
+			return malformed(err)
+		}
+
+		return refs.Ascend(v.pack, func(_ int, eh cipher.SHA256) (err error) {
+			if eh == (cipher.SHA256{}) {
+				return // nil
+			}
+			return v.object(s.Elem(), eh)
+		})
+
+	}
+
+	switch s.Kind() {
+
+	case reflect.String:
+
+		if utf8.Valid(value.Encoded()[4:]) == false {
+			return malformed(ErrInvalidUTF8)
+		}
+
+	case reflect.Array, reflect.Slice:
+
+		var el = s.Elem()
+
+		if el.Kind() == reflect.Uint8 && el.IsRegistered() == false {
+			return // []byte or [n]byte
+		}
+
+		var ln int
+		if ln, err = value.Len(); err != nil {
+			return malformed(err)
+		}
+
+		for i := 0; i < ln; i++ {
+
+			var ev registry.Value
+			if ev, err = value.Index(i); err != nil {
+				return malformed(err)
+			}
+
+			if err = v.value(hash, indexPath(path, i), ev); err != nil {
+				return
+			}
+
+		}
+
+	case reflect.Struct:
+
+		for i, f := range s.Fields() {
+
+			var fv registry.Value
+			if fv, err = value.FieldByIndex(i); err != nil {
+				return malformed(err)
+			}
+
+			err = v.value(hash, fieldPath(path, f.Name()), fv)
+			if err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// check object a Dynamic refers to
+func (v *validator) dynamic(
+	hash cipher.SHA256, //  : hash of object (or Root) with the Dynamic
+	path string, //         : path of the Dynamic
+	dr registry.Dynamic, // : the Dynamic
+) (
+	err error, //           : first error
+) {
+
+	if dr.IsValid() == false {
+		return &ValidationError{
+			Hash: hash,
+			Path: path,
+			Err:  registry.ErrInvalidDynamicReference,
+		}
+	}
+
+	if dr.IsBlank() == true || dr.Hash == (cipher.SHA256{}) {
+		return // nil
+	}
+
+	var s registry.Schema
+	if s, err = v.pack.Registry().SchemaByReference(dr.Schema); err != nil {
+		return &ValidationError{Hash: hash, Path: path, Err: err}
+	}
+
+	return v.object(s, dr.Hash)
+}
@@ -0,0 +1,148 @@
+package skyobject
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// save Root with given Dynamic references
+func testValidateRoot(
+	t *testing.T,
+	c *Container,
+	up *Unpack,
+	drs ...registry.Dynamic,
+) (
+	r *registry.Root,
+) {
+
+	t.Helper()
+
+	r = &registry.Root{
+		Pub:   cipher.PubKeyFromSecKey(up.sk),
+		Nonce: 1,
+		Refs:  drs,
+	}
+
+	assertNil(t, c.Save(up, r))
+	return
+}
+
+func TestValidate(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	// valid
+
+	var (
+		v  = testDiffFeed(t, up, "head", "one", "two")
+		dr = registry.Dynamic{Schema: v.Schema().Reference()}
+	)
+
+	dr.Hash, err = up.Add(v.Encoded())
+	assertNil(t, err)
+
+	var r = testValidateRoot(t, c, up, dr, registry.Dynamic{})
+	assertNil(t, Validate(up, r.Hash))
+
+	// invalid UTF-8
+
+	dr = createDynamic(up, testRegistry, "test.Feed", &Feed{Head: "\xff"})
+	r = testValidateRoot(t, c, up, dr)
+
+	err = Validate(up, r.Hash)
+
+	var ve *ValidationError
+	if errors.As(err, &ve) == false {
+		t.Fatal("missing ValidationError:", err)
+	}
+	if ve.Hash != dr.Hash || ve.Path != "Head" {
+		t.Errorf("wrong ValidationError: %s, %q", ve.Hash.Hex()[:7], ve.Path)
+	}
+	if errors.Is(err, ErrInvalidUTF8) == false {
+		t.Error("wrong error:", err)
+	}
+
+	// trailing bytes
+
+	var post registry.Schema
+	post, err = testRegistry.SchemaByName("test.Post")
+	assertNil(t, err)
+
+	dr.Schema = post.Reference()
+	dr.Hash, err = up.Add(append(encoder.Serialize(Post{Head: "x"}), 0, 0))
+	assertNil(t, err)
+
+	r = testValidateRoot(t, c, up, dr)
+
+	if err = Validate(up, r.Hash); errors.Is(err, ErrTrailingBytes) == false {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestFiller_validateFilled(t *testing.T) {
+
+	var (
+		sc     = getTestContainer()
+		conf   = getTestConfig()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+	defer sc.Close()
+
+	conf.ValidateFilled = true
+
+	var rc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up *Unpack
+	up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = testValidateRoot(t, sc, up,
+		createDynamic(up, testRegistry, "test.User", &User{"\xff", 19}))
+
+	var (
+		rq = make(chan cipher.SHA256, 10)
+		f  = rc.Fill(r, rq, 10)
+	)
+
+	go func() {
+		for key := range rq {
+			var val, _, err = sc.Get(key, 0)
+			if err == nil {
+				_, err = rc.SetWanted(key, val)
+			}
+			if err != nil {
+				f.Fail(err)
+			}
+		}
+	}()
+	defer close(rq)
+
+	if err = f.Run(); errors.Is(err, ErrInvalidUTF8) == false {
+		t.Fatal("wrong error:", err)
+	}
+
+	if _, err = rc.LastRoot(pk, r.Nonce); err == nil {
+		t.Error("malformed Root saved")
+	}
+
+}
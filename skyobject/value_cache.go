@@ -0,0 +1,237 @@
+package skyobject
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A ValueCacheStat represents statistic of a ValueCache
+type ValueCacheStat struct {
+	Size      int   // max number of Values
+	Len       int   // number of cached Values
+	Pinned    int   // number of pinned Values
+	Hits      int64 // found in the cache
+	Misses    int64 // not found in the cache
+	Evictions int64 // removed from the cache to free up space
+}
+
+// A ValueCache is LRU cache of decoded registry.Value
+// objects keyed by hash and schema of an object. A web
+// frontend or similar reads the same hot objects again
+// and again, and the ValueCache keeps them ready, with
+// offsets of fields (see registry.Value) too. The
+// ValueCache is thread safe and can be shared between
+// goroutines serving reads.
+//
+// A Value can be pinned to keep it in the cache. The
+// pinning is weak. A pinned Value is evicted only if
+// all Values of the full cache are pinned. Thus, the
+// pinning never makes the cache larger.
+//
+// A cached Value keeps Pack used to load it. The Pack
+// used to dereference references of the Value. Thus,
+// use one ValueCache per Registry
+type ValueCache struct {
+	mx sync.Mutex
+
+	size int                        // max size
+	ll   *list.List                 // LRU order (front is recent)
+	vs   map[valueKey]*list.Element // cached
+	stat ValueCacheStat             // metrics
+}
+
+type valueKey struct {
+	hash cipher.SHA256
+	sr   registry.SchemaRef
+}
+
+type cachedValue struct {
+	key    valueKey
+	val    registry.Value
+	pinned int // pins
+}
+
+// NewValueCache creates ValueCache with given max number
+// of Values. If the size is zero or less, then the
+// ValueCache keeps nothing
+func NewValueCache(size int) (v *ValueCache) {
+	v = new(ValueCache)
+	v.size = size
+	v.ll = list.New()
+	v.vs = make(map[valueKey]*list.Element)
+	return
+}
+
+// Value returns Value of object with given hash and Schema.
+// If the Value is not cached, then the object is loaded from
+// given Pack and cached. Objects older then their schemas
+// are loaded with default values (see registry.ApplyDefaults)
+func (v *ValueCache) Value(
+	pack registry.Pack, // : pack to load from
+	s registry.Schema, //  : schema of the object
+	hash cipher.SHA256, // : hash of the object
+) (
+	val registry.Value, // : the Value
+	err error, //          : loading or decoding error
+) {
+
+	if s == nil {
+		err = registry.ErrInvalidSchema
+		return
+	}
+
+	var ok bool
+	if val, ok = v.Get(s.Reference(), hash); ok == true {
+		return
+	}
+
+	var p []byte
+	if p, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	if p, err = registry.ApplyDefaults(s, p); err != nil {
+		return
+	}
+
+	if val, err = registry.NewValue(pack, s, p); err != nil {
+		return
+	}
+
+	v.Add(hash, val)
+	return
+}
+
+// Get cached Value by reference to its Schema and hash
+func (v *ValueCache) Get(
+	sr registry.SchemaRef, // : schema of the Value
+	hash cipher.SHA256, //     : hash of the object
+) (
+	val registry.Value, //     : the Value
+	ok bool, //                : found or not
+) {
+
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	var el *list.Element
+	if el, ok = v.vs[valueKey{hash, sr}]; ok == false {
+		v.stat.Misses++
+		return
+	}
+
+	v.stat.Hits++
+	v.ll.MoveToFront(el)
+
+	return el.Value.(*cachedValue).val, true
+}
+
+// Add Value of object with given hash to the ValueCache
+func (v *ValueCache) Add(hash cipher.SHA256, val registry.Value) {
+
+	if v.size <= 0 || val.Schema() == nil {
+		return
+	}
+
+	var key = valueKey{hash, val.Schema().Reference()}
+
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	if el, ok := v.vs[key]; ok == true {
+		v.ll.MoveToFront(el)
+		return
+	}
+
+	for v.ll.Len() >= v.size {
+		v.evict()
+	}
+
+	v.vs[key] = v.ll.PushFront(&cachedValue{key: key, val: val})
+}
+
+// remove least recently used not pinned Value,
+// or least recently used Value if all pinned
+func (v *ValueCache) evict() {
+
+	var el = v.ll.Back()
+
+	for e := el; e != nil; e = e.Prev() {
+		if e.Value.(*cachedValue).pinned == 0 {
+			el = e
+			break
+		}
+	}
+
+	var cv = el.Value.(*cachedValue)
+
+	if cv.pinned > 0 {
+		v.stat.Pinned--
+	}
+
+	v.ll.Remove(el)
+	delete(v.vs, cv.key)
+	v.stat.Evictions++
+}
+
+// Pin cached Value to keep it in the ValueCache. The
+// Pin returns false if the Value is not cached. Every
+// Pin should be followed by Unpin
+func (v *ValueCache) Pin(sr registry.SchemaRef, hash cipher.SHA256) (ok bool) {
+
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	var el *list.Element
+	if el, ok = v.vs[valueKey{hash, sr}]; ok == false {
+		return
+	}
+
+	var cv = el.Value.(*cachedValue)
+
+	if cv.pinned == 0 {
+		v.stat.Pinned++
+	}
+
+	cv.pinned++
+	return
+}
+
+// Unpin Value pinned by the Pin. It's safe to
+// unpin evicted Value
+func (v *ValueCache) Unpin(sr registry.SchemaRef, hash cipher.SHA256) {
+
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	var el, ok = v.vs[valueKey{hash, sr}]
+	if ok == false {
+		return
+	}
+
+	var cv = el.Value.(*cachedValue)
+
+	if cv.pinned == 0 {
+		return
+	}
+
+	if cv.pinned--; cv.pinned == 0 {
+		v.stat.Pinned--
+	}
+}
+
+// Stat returns statistic of the ValueCache
+func (v *ValueCache) Stat() (s ValueCacheStat) {
+
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	s = v.stat
+	s.Size = v.size
+	s.Len = v.ll.Len()
+	return
+}
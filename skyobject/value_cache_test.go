@@ -0,0 +1,94 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestValueCache_Value(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var _, sk = cipher.GenerateKeyPair()
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var user registry.Schema
+	user, err = testRegistry.SchemaByName("test.User")
+	assertNil(t, err)
+
+	var hs []cipher.SHA256
+	for _, name := range []string{"Alice", "Bob", "Eva"} {
+		var dr = createDynamic(up, testRegistry, "test.User", &User{name, 19})
+		hs = append(hs, dr.Hash)
+	}
+
+	var (
+		vc  = NewValueCache(2)
+		val registry.Value
+	)
+
+	for _, hash := range hs[:2] {
+		if val, err = vc.Value(up, user, hash); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var name registry.Value
+	name, err = val.FieldByName("Name")
+	assertNil(t, err)
+
+	if s, _ := name.String(); s != "Bob" {
+		t.Error("wrong name:", s)
+	}
+
+	// pin Alice (least recently used), Eva evicts Bob
+
+	assertTrue(t, vc.Pin(user.Reference(), hs[0]), "can't pin")
+
+	_, err = vc.Value(up, user, hs[2])
+	assertNil(t, err)
+
+	if _, ok := vc.Get(user.Reference(), hs[1]); ok == true {
+		t.Error("Bob not evicted")
+	}
+
+	if _, ok := vc.Get(user.Reference(), hs[0]); ok == false {
+		t.Error("pinned Alice evicted")
+	}
+
+	var s = vc.Stat()
+
+	if s.Size != 2 || s.Len != 2 || s.Pinned != 1 {
+		t.Error("wrong size, length or pinned", s.Size, s.Len, s.Pinned)
+	}
+
+	if s.Hits != 1 || s.Misses != 4 || s.Evictions != 1 {
+		t.Error("wrong metrics", s.Hits, s.Misses, s.Evictions)
+	}
+
+	// weak pinning
+
+	assertTrue(t, vc.Pin(user.Reference(), hs[2]), "can't pin")
+
+	_, err = vc.Value(up, user, hs[1])
+	assertNil(t, err)
+
+	if s = vc.Stat(); s.Len != 2 || s.Pinned != 1 {
+		t.Error("wrong length or pinned", s.Len, s.Pinned)
+	}
+
+	vc.Unpin(user.Reference(), hs[0]) // evicted, no-op
+	vc.Unpin(user.Reference(), hs[2])
+
+	if s = vc.Stat(); s.Pinned != 0 {
+		t.Error("wrong pinned", s.Pinned)
+	}
+
+}
@@ -0,0 +1,243 @@
+package skyobject
+
+import (
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// size of buffer of the CorruptionEvents channel
+const corruptionEventsBuffer = 128
+
+// A CorruptionEvent represents damaged data found
+// by verification of stored Root objects and objects
+// (see Config.VerifyInterval and (*Container).Verify)
+type CorruptionEvent struct {
+	Feed  cipher.PubKey // feed of the Root (blank for objects)
+	Nonce uint64        // head of the Root
+	Seq   uint64        // seq number of the Root
+	Key   cipher.SHA256 // hash of the Root or the object
+
+	// Err is ErrCorruptedObject, registry.ErrInvalidSignature
+	// or ErrObjectNotFound (missing encoded Root)
+	Err error
+}
+
+// A CorruptionFunc used by the Verify method to
+// report damaged data
+type CorruptionFunc func(ce CorruptionEvent)
+
+// background verification
+type verifier struct {
+	evq    chan CorruptionEvent
+	closeq chan struct{}
+	closeo sync.Once
+	await  sync.WaitGroup
+}
+
+func (c *Container) startVerifier() {
+
+	if c.conf.VerifyInterval <= 0 {
+		return // disabled
+	}
+
+	var v = new(verifier)
+
+	v.evq = make(chan CorruptionEvent, corruptionEventsBuffer)
+	v.closeq = make(chan struct{})
+
+	c.v = v
+
+	v.await.Add(1)
+	go c.verifying()
+}
+
+func (c *Container) stopVerifier() {
+
+	if c.v == nil {
+		return
+	}
+
+	c.v.closeo.Do(func() {
+		close(c.v.closeq)
+	})
+
+	c.v.await.Wait()
+}
+
+func (c *Container) verifying() {
+	defer c.v.await.Done()
+
+	var tk = time.NewTicker(c.conf.VerifyInterval)
+	defer tk.Stop()
+
+	var corrupted = func(ce CorruptionEvent) {
+		select {
+		case c.v.evq <- ce:
+		default: // drop, never block
+		}
+	}
+
+	for {
+		select {
+		case <-tk.C:
+		case <-c.v.closeq:
+			return
+		}
+
+		var err = c.verify(c.conf.VerifySampling, corrupted, c.v.closeq)
+
+		if err != nil && err != ErrTerminated {
+			log.Print("[ERR] [skyobject] verification: ", err)
+		}
+	}
+}
+
+// CorruptionEvents returns channel of damaged data
+// found by background verification. The channel is
+// nil if the verification disabled (see Config.
+// VerifyInterval). The verification never blocks
+// sending events, and if the channel is full, then
+// new events are dropped
+func (c *Container) CorruptionEvents() <-chan CorruptionEvent {
+	if c.v == nil {
+		return nil
+	}
+	return c.v.evq
+}
+
+// Verify stored Root objects and objects. The Verify
+// checks signatures and hashes of all stored Root
+// objects and hashes of given fraction of all objects
+// (the sampling is from 0 to 1). Damaged data reported
+// using given function. The Verify reads data from DB
+// directly, ignoring the Cache. The Verify returns DB
+// errors only. See also Config.VerifyInterval
+func (c *Container) Verify(
+	sampling float64, //             : fraction of objects to check
+	corruptionFunc CorruptionFunc, // : report
+) (
+	err error, //                      : DB failure
+) {
+	return c.verify(sampling, corruptionFunc, nil)
+}
+
+// stored Root
+type verifyRoot struct {
+	pk    cipher.PubKey
+	nonce uint64
+	seq   uint64
+	hash  cipher.SHA256
+	sig   cipher.Sig
+}
+
+func (c *Container) storedRoots() (rs []verifyRoot, err error) {
+
+	err = c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+
+		return feeds.Iterate(func(pk cipher.PubKey) (err error) {
+
+			var hs data.Heads
+			if hs, err = feeds.Heads(pk); err != nil {
+				return
+			}
+
+			return hs.Iterate(func(nonce uint64) (err error) {
+
+				var roots data.Roots
+				if roots, err = hs.Roots(nonce); err != nil {
+					return
+				}
+
+				return roots.Ascend(func(dr *data.Root) (_ error) {
+					rs = append(rs, verifyRoot{pk, nonce, dr.Seq, dr.Hash,
+						dr.Sig})
+					return
+				})
+
+			})
+
+		})
+
+	})
+
+	return
+}
+
+// the stopq can be nil
+func (c *Container) verify(
+	sampling float64, //             :
+	corruptionFunc CorruptionFunc, // :
+	stopq <-chan struct{}, //        :
+) (
+	err error, //                      :
+) {
+
+	var rs []verifyRoot
+	if rs, err = c.storedRoots(); err != nil {
+		return
+	}
+
+	var cx = c.db.CXDS()
+
+	for _, vr := range rs {
+
+		select {
+		case <-stopq:
+			return ErrTerminated
+		default:
+		}
+
+		var val []byte
+		if val, _, err = cx.Get(vr.hash, 0); err == data.ErrNotFound {
+			err = ErrObjectNotFound
+		} else if err != nil {
+			return // DB failure
+		} else if cipher.SumSHA256(val) != vr.hash {
+			err = ErrCorruptedObject
+		} else if cipher.VerifySignature(vr.pk, vr.sig, vr.hash) != nil {
+			err = registry.ErrInvalidSignature
+		}
+
+		if err != nil {
+			corruptionFunc(CorruptionEvent{vr.pk, vr.nonce, vr.seq, vr.hash,
+				err})
+			err = nil
+		}
+
+	}
+
+	if sampling <= 0 {
+		return
+	}
+
+	err = cx.Iterate(func(key cipher.SHA256, _ uint32, val []byte) (_ error) {
+
+		select {
+		case <-stopq:
+			return ErrTerminated
+		default:
+		}
+
+		if sampling < 1 && rand.Float64() >= sampling {
+			return // skip
+		}
+
+		if cipher.SumSHA256(val) != key {
+			corruptionFunc(CorruptionEvent{
+				Key: key,
+				Err: ErrCorruptedObject,
+			})
+		}
+
+		return
+	})
+
+	return
+}
@@ -0,0 +1,104 @@
+package skyobject
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func testVerify(t *testing.T, c *Container) (ces []CorruptionEvent) {
+	t.Helper()
+
+	assertNil(t, c.Verify(1.0, func(ce CorruptionEvent) {
+		ces = append(ces, ce)
+	}))
+
+	return
+}
+
+// replace value in DB
+func testCorrupt(t *testing.T, c *Container, key cipher.SHA256) {
+	t.Helper()
+
+	var cx = c.db.CXDS()
+
+	assertNil(t, cx.Del(key))
+	var _, err = cx.Set(key, []byte("bit rot"), 1)
+	assertNil(t, err)
+}
+
+func TestContainer_Verify(t *testing.T) {
+
+	var (
+		c = getTestContainer()
+		r = testArchiveRoot(t, c)
+	)
+
+	defer c.Close()
+
+	var ces = testVerify(t, c)
+	assertTrue(t, len(ces) == 0, "unexpected corruption events")
+
+	// object
+
+	var key = cipher.SHA256(r.Reg)
+	testCorrupt(t, c, key)
+
+	ces = testVerify(t, c)
+	assertTrue(t, len(ces) == 1, "wrong number of events")
+	assertTrue(t, ces[0].Key == key, "wrong key")
+	assertTrue(t, ces[0].Feed == (cipher.PubKey{}), "unexpected feed")
+	assertTrue(t, ces[0].Err == ErrCorruptedObject, "wrong error")
+
+	// don't check objects
+	ces = nil
+	assertNil(t, c.Verify(0, func(ce CorruptionEvent) {
+		ces = append(ces, ce)
+	}))
+	assertTrue(t, len(ces) == 0, "unexpected corruption events")
+
+	// Root
+
+	testCorrupt(t, c, r.Hash)
+
+	ces = testVerify(t, c)
+	// the Root, encoded Root (as object) and the Registry
+	assertTrue(t, len(ces) == 3, "wrong number of events")
+
+	var root = ces[0] // roots first
+	assertTrue(t, root.Key == r.Hash, "wrong key")
+	assertTrue(t, root.Feed == r.Pub, "wrong feed")
+	assertTrue(t, root.Nonce == r.Nonce, "wrong nonce")
+	assertTrue(t, root.Seq == r.Seq, "wrong seq")
+	assertTrue(t, root.Err == ErrCorruptedObject, "wrong error")
+
+}
+
+func TestContainer_CorruptionEvents(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.VerifyInterval = 50 * time.Millisecond
+	conf.VerifySampling = 1.0
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var r = testArchiveRoot(t, c)
+	testCorrupt(t, c, cipher.SHA256(r.Reg))
+
+	select {
+	case ce := <-c.CorruptionEvents():
+		assertTrue(t, ce.Key == cipher.SHA256(r.Reg), "wrong key")
+	case <-time.After(time.Second):
+		t.Fatal("slow")
+	}
+
+	// disabled
+	var dc = getTestContainer()
+	defer dc.Close()
+
+	assertTrue(t, dc.CorruptionEvents() == nil, "unexpected channel")
+
+}
@@ -0,0 +1,174 @@
+package skyobject
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// ErrSkipSubtree is used as a return value from Enter
+// function of a Visitor to skip subtree of the Value.
+// It is not returned as an error by the Walk
+var ErrSkipSubtree = errors.New("skip subtree")
+
+// A Visitor represents callbacks of the Walk. The
+// path is path of a Value (see registry.Value.ByPath).
+// Any of the callbacks can be nil
+type Visitor struct {
+	// Enter called before walking through a Value.
+	// Return ErrSkipSubtree to don't walk through
+	// the Value. Other errors stop the Walk
+	Enter func(path string, v registry.Value) (err error)
+	// Leave called after walking through a Value,
+	// if the Enter doesn't return an error
+	Leave func(path string, v registry.Value) (err error)
+
+	// Schemas is filter by name of Schema. If it's
+	// not empty, then the callbacks are called only
+	// for values of the Schemas. The filter doesn't
+	// affect walking
+	Schemas []string
+}
+
+// Walk object graph of given Value depth-first using
+// given Pack to get objects. The Walk calls callbacks of
+// given Visitor for the Value, for fields of structures,
+// for elements of arrays and slices and for references.
+// An object a reference refers to is visited with path
+// of the reference after the reference. Nil references
+// are not walked. An object is visited every time it is
+// referred. The Walk returns first error of the Visitor
+func Walk(pack registry.Pack, root registry.Value, visitor Visitor) (err error) {
+
+	if root, err = registry.NewValue(pack, root.Schema(),
+		root.Encoded()); err != nil {
+
+		return
+	}
+
+	var w = graphWalker{v: visitor}
+
+	if len(visitor.Schemas) > 0 {
+		w.filter = make(map[string]struct{}, len(visitor.Schemas))
+		for _, name := range visitor.Schemas {
+			w.filter[name] = struct{}{}
+		}
+	}
+
+	return w.walk("", root)
+}
+
+type graphWalker struct {
+	v      Visitor
+	filter map[string]struct{} // nil if not used
+}
+
+func (g *graphWalker) match(v registry.Value) (ok bool) {
+	if g.filter == nil {
+		return true
+	}
+	_, ok = g.filter[v.Schema().Name()]
+	return
+}
+
+func (g *graphWalker) walk(path string, v registry.Value) (err error) {
+
+	var match = g.match(v)
+
+	if match == true && g.v.Enter != nil {
+		if err = g.v.Enter(path, v); err == ErrSkipSubtree {
+			return nil
+		} else if err != nil {
+			return
+		}
+	}
+
+	if err = g.walkSubtree(path, v); err != nil {
+		return
+	}
+
+	if match == true && g.v.Leave != nil {
+		err = g.v.Leave(path, v)
+	}
+
+	return
+}
+
+func (g *graphWalker) walkSubtree(path string, v registry.Value) (err error) {
+
+	var s = v.Schema()
+
+	switch s.ReferenceType() {
+
+	case registry.ReferenceTypeSingle, registry.ReferenceTypeDynamic:
+
+		if v.IsNil() == true {
+			return
+		}
+
+		var dv registry.Value
+		if dv, err = v.Dereference(); err != nil {
+			return
+		}
+
+		return g.walk(path, dv)
+
+	case registry.ReferenceTypeSlice:
+
+		return g.walkElements(path, v)
+
+	}
+
+	switch s.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		return g.walkElements(path, v)
+
+	case reflect.Struct:
+
+		for i, f := range s.Fields() {
+
+			var fv registry.Value
+			if fv, err = v.FieldByIndex(i); err != nil {
+				return
+			}
+
+			if err = g.walk(fieldPath(path, f.Name()), fv); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// array, slice or Refs
+func (g *graphWalker) walkElements(path string, v registry.Value) (err error) {
+
+	var ln int
+	if ln, err = v.Len(); err != nil {
+		return
+	}
+
+	for i := 0; i < ln; i++ {
+
+		var ev registry.Value
+
+		if ev, err = v.Index(i); err == registry.ErrRefsElementIsNil {
+			continue
+		} else if err != nil {
+			return
+		}
+
+		if err = g.walk(indexPath(path, i), ev); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
@@ -0,0 +1,84 @@
+package skyobject
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestWalk(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var _, sk = cipher.GenerateKeyPair()
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var feed = testDiffFeed(t, up, "head", "one", "two", "three")
+
+	// enter and leave
+
+	var events []string
+
+	err = Walk(up, feed, Visitor{
+		Enter: func(path string, v registry.Value) error {
+			events = append(events, "enter "+path)
+			return nil
+		},
+		Leave: func(path string, v registry.Value) error {
+			events = append(events, "leave "+path)
+			return nil
+		},
+		Schemas: []string{"test.Post"},
+	})
+	assertNil(t, err)
+
+	var want = []string{
+		"enter Posts[0]", "leave Posts[0]",
+		"enter Posts[1]", "leave Posts[1]",
+		"enter Posts[2]", "leave Posts[2]",
+	}
+
+	assertTrue(t, len(events) == len(want), "wrong number of events")
+	for i, e := range want {
+		assertTrue(t, events[i] == e, "wrong event "+events[i])
+	}
+
+	// skip subtree
+
+	var paths []string
+
+	err = Walk(up, feed, Visitor{
+		Enter: func(path string, v registry.Value) error {
+			paths = append(paths, path)
+			if path == "Posts" {
+				return ErrSkipSubtree
+			}
+			return nil
+		},
+	})
+	assertNil(t, err)
+
+	want = []string{"", "Head", "Info", "Posts"}
+
+	assertTrue(t, len(paths) == len(want), "wrong number of paths")
+	for i, p := range want {
+		assertTrue(t, paths[i] == p, "wrong path "+paths[i])
+	}
+
+	// error
+
+	var errTest = errors.New("test error")
+
+	err = Walk(up, feed, Visitor{
+		Leave: func(string, registry.Value) error { return errTest },
+	})
+	assertTrue(t, err == errTest, "wrong error")
+
+}